@@ -0,0 +1,277 @@
+package abuse
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/audit"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// Errors matching the non-allow decisions, see Result.Err
+var (
+	ErrDenied = apperror.New("abuse_denied", apperror.KindRateLimited, "too many attempts, please try again later").
+			WithKey("abuse.denied")
+	ErrChallengeRequired = apperror.New("abuse_challenge_required", apperror.KindForbidden, "additional verification is required").
+				WithKey("abuse.challenge_required")
+)
+
+// Audit actions recorded when a rule trips
+const (
+	ActionDenied     audit.Action = "abuse.denied"
+	ActionChallenged audit.Action = "abuse.challenged"
+)
+
+// Config holds the guard settings
+type Config struct {
+	Redis  *redis.Client
+	Prefix string // key prefix, defaults to "abuse:"
+	Rules  []Rule // defaults to DefaultRules
+	// Audit records an entry each time a rule trips (optional)
+	Audit *audit.Recorder
+	// FailClosed denies requests when Redis is unavailable; by default they are allowed so an
+	// outage does not lock everyone out of logging in
+	FailClosed bool
+}
+
+// Result is the outcome of Check or Hit
+type Result struct {
+	Decision Decision `json:"decision"`
+	// Rule is the strictest rule that tripped
+	Rule       string        `json:"rule,omitempty"`
+	Count      int64         `json:"count,omitempty"`
+	Limit      int           `json:"limit,omitempty"`
+	RetryAfter time.Duration `json:"retry_after,omitempty"`
+}
+
+// Allowed reports whether the request may go on without a challenge
+func (r *Result) Allowed() bool {
+	return r.Decision == Allow
+}
+
+// Err returns ErrDenied or ErrChallengeRequired for the decision, nil when allowed
+func (r *Result) Err() error {
+	switch r.Decision {
+	case Deny:
+		return ErrDenied
+	case Challenge:
+		return ErrChallengeRequired
+	default:
+		return nil
+	}
+}
+
+// Guard evaluates velocity rules against counters shared by every replica through Redis
+type Guard struct {
+	cfg     *Config
+	log     *zap.Logger
+	byEvent map[string][]Rule
+}
+
+// hitScript counts an event and starts the window on the first hit only, so a steady stream
+// of attempts cannot keep extending it
+var hitScript = redis.NewScript(`
+local n = redis.call('INCR', KEYS[1])
+if n == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return {n, redis.call('PTTL', KEYS[1])}
+`)
+
+// NewGuard creates a guard
+func NewGuard(cfg *Config) (*Guard, error) {
+	if cfg.Redis == nil {
+		return nil, fmt.Errorf("abuse requires a redis client")
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = "abuse:"
+	}
+	if cfg.Rules == nil {
+		cfg.Rules = DefaultRules
+	}
+
+	g := &Guard{cfg: cfg, log: logger.Module("abuse"), byEvent: make(map[string][]Rule)}
+	seen := make(map[string]bool)
+	for _, r := range cfg.Rules {
+		if r.Name == "" || r.Event == "" || r.Dimension == "" || r.Limit <= 0 || r.Window <= 0 {
+			return nil, fmt.Errorf("abuse rule %q is incomplete", r.Name)
+		}
+		if seen[r.Name] {
+			return nil, fmt.Errorf("duplicate abuse rule %q", r.Name)
+		}
+		seen[r.Name] = true
+		if r.Action == "" {
+			r.Action = Deny
+		}
+		g.byEvent[r.Event] = append(g.byEvent[r.Event], r)
+	}
+	return g, nil
+}
+
+// Check evaluates the event's rules without counting, e.g. before verifying a password
+func (g *Guard) Check(ctx context.Context, event string, subject Subject) (*Result, error) {
+	rules := g.applicable(event, subject)
+	if len(rules) == 0 {
+		return &Result{Decision: Allow}, nil
+	}
+
+	pipe := g.cfg.Redis.Pipeline()
+	counts := make([]*redis.StringCmd, len(rules))
+	ttls := make([]*redis.DurationCmd, len(rules))
+	blocks := make([]*redis.DurationCmd, len(rules))
+	for i, r := range rules {
+		value := normalize(r.Dimension, subject[r.Dimension])
+		counts[i] = pipe.Get(ctx, g.countKey(r, value))
+		ttls[i] = pipe.PTTL(ctx, g.countKey(r, value))
+		blocks[i] = pipe.PTTL(ctx, g.blockKey(r, value))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return g.unavailable(event, err)
+	}
+
+	result := &Result{Decision: Allow}
+	for i, r := range rules {
+		if block := blocks[i].Val(); block > 0 {
+			result.merge(r, int64(r.Limit)+1, block)
+			continue
+		}
+		count, _ := counts[i].Int64()
+		if count > int64(r.Limit) {
+			result.merge(r, count, ttls[i].Val())
+		}
+	}
+	return result, nil
+}
+
+// Hit counts an event for the subject and evaluates the rules including it, e.g. after a failed
+// login or before sending an OTP. Tripping a rule with Block starts the block and is audited.
+func (g *Guard) Hit(ctx context.Context, event string, subject Subject) (*Result, error) {
+	rules := g.applicable(event, subject)
+	if len(rules) == 0 {
+		return &Result{Decision: Allow}, nil
+	}
+
+	pipe := g.cfg.Redis.Pipeline()
+	hits := make([]*redis.Cmd, len(rules))
+	blocks := make([]*redis.DurationCmd, len(rules))
+	for i, r := range rules {
+		value := normalize(r.Dimension, subject[r.Dimension])
+		// Eval rather than Run: a pipeline cannot fall back from EVALSHA when the script is not loaded
+		hits[i] = hitScript.Eval(ctx, pipe, []string{g.countKey(r, value)}, r.Window.Milliseconds())
+		blocks[i] = pipe.PTTL(ctx, g.blockKey(r, value))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return g.unavailable(event, err)
+	}
+
+	result := &Result{Decision: Allow}
+	for i, r := range rules {
+		if block := blocks[i].Val(); block > 0 {
+			result.merge(r, int64(r.Limit)+1, block)
+			continue
+		}
+		res, err := hits[i].Slice()
+		if err != nil || len(res) < 2 {
+			return g.unavailable(event, fmt.Errorf("unexpected abuse counter reply: %v", err))
+		}
+		count, _ := res[0].(int64)
+		ttl, _ := res[1].(int64)
+		if count <= int64(r.Limit) {
+			continue
+		}
+		retry := time.Duration(ttl) * time.Millisecond
+		if r.Block > 0 {
+			g.cfg.Redis.Set(ctx, g.blockKey(r, normalize(r.Dimension, subject[r.Dimension])), 1, r.Block)
+			retry = r.Block
+		}
+		result.merge(r, count, retry)
+		// Audit only the hit that trips the rule, not every refused attempt after it
+		if count == int64(r.Limit)+1 {
+			g.record(ctx, r, subject, count)
+		}
+	}
+	return result, nil
+}
+
+// Reset clears the event's counters and blocks for the subject, e.g. the failed-login counters
+// of an account after a successful login or a password reset
+func (g *Guard) Reset(ctx context.Context, event string, subject Subject) error {
+	var keys []string
+	for _, r := range g.applicable(event, subject) {
+		value := normalize(r.Dimension, subject[r.Dimension])
+		keys = append(keys, g.countKey(r, value), g.blockKey(r, value))
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := g.cfg.Redis.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to reset abuse counters: %w", err)
+	}
+	return nil
+}
+
+// applicable returns the event's rules on dimensions the subject has
+func (g *Guard) applicable(event string, subject Subject) []Rule {
+	var out []Rule
+	for _, r := range g.byEvent[event] {
+		if normalize(r.Dimension, subject[r.Dimension]) != "" {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func (g *Guard) unavailable(event string, err error) (*Result, error) {
+	g.log.Warn("abuse counters unavailable", zap.String("event", event), zap.Bool("fail_closed", g.cfg.FailClosed), zap.Error(err))
+	if g.cfg.FailClosed {
+		return &Result{Decision: Deny}, fmt.Errorf("failed to evaluate abuse rules: %w", err)
+	}
+	return &Result{Decision: Allow}, nil
+}
+
+func (g *Guard) record(ctx context.Context, r Rule, subject Subject, count int64) {
+	g.log.Warn("abuse rule tripped",
+		zap.String("rule", r.Name),
+		zap.String("event", r.Event),
+		zap.String("dimension", string(r.Dimension)),
+		zap.Int64("count", count),
+	)
+	if g.cfg.Audit == nil {
+		return
+	}
+	action := ActionDenied
+	if r.Action == Challenge {
+		action = ActionChallenged
+	}
+	err := g.cfg.Audit.Record(ctx, action, audit.Resource{Type: "abuse_rule", ID: r.Name}, nil,
+		audit.WithMetadata("event", r.Event),
+		audit.WithMetadata("dimension", string(r.Dimension)),
+		audit.WithMetadata("value", subject[r.Dimension]),
+		audit.WithMetadata("count", count),
+		audit.WithMetadata("limit", r.Limit),
+	)
+	if err != nil {
+		g.log.Warn("failed to audit abuse decision", zap.String("rule", r.Name), zap.Error(err))
+	}
+}
+
+func (g *Guard) countKey(r Rule, value string) string {
+	return g.cfg.Prefix + "count:" + r.Name + ":" + value
+}
+
+func (g *Guard) blockKey(r Rule, value string) string {
+	return g.cfg.Prefix + "block:" + r.Name + ":" + value
+}
+
+// merge keeps the strictest decision, and the longest wait among equally strict rules
+func (r *Result) merge(rule Rule, count int64, retry time.Duration) {
+	w := rule.Action.weight()
+	if w < r.Decision.weight() || (w == r.Decision.weight() && retry <= r.RetryAfter) {
+		return
+	}
+	r.Decision, r.Rule, r.Count, r.Limit, r.RetryAfter = rule.Action, rule.Name, count, rule.Limit, retry
+}
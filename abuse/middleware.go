@@ -0,0 +1,64 @@
+package abuse
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ResultKey is the gin key under which Middleware stores the Result for the handler
+const ResultKey = "abuse_result"
+
+// SubjectFunc extracts the identifiers of the caller from a request
+type SubjectFunc func(c *gin.Context) Subject
+
+// RequestSubject returns the client IP and, when authenticated, the user ID
+func RequestSubject(c *gin.Context) Subject {
+	s := Subject{DimensionIP: c.ClientIP()}
+	if id, ok := ctxutil.UserID(c); ok {
+		s[DimensionUser] = strconv.FormatUint(id, 10)
+	}
+	return s
+}
+
+// Middleware refuses requests already denied for event with 429 and a Retry-After header.
+// Challenge decisions are passed on, so the handler reads FromContext and asks for a CAPTCHA
+// or OTP before going on; handlers still call Hit on failures. subject defaults to RequestSubject.
+func (g *Guard) Middleware(event string, subject SubjectFunc) gin.HandlerFunc {
+	if subject == nil {
+		subject = RequestSubject
+	}
+	return func(c *gin.Context) {
+		// Redis errors are logged by the guard and already folded into the decision
+		result, _ := g.Check(c.Request.Context(), event, subject(c))
+		c.Set(ResultKey, result)
+
+		if result.Decision == Deny {
+			SetRetryAfter(c, result)
+			response.HandleError(c, ErrDenied)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// FromContext returns the Result stored by Middleware, or an allow result when there is none
+func FromContext(c *gin.Context) *Result {
+	if v, ok := c.Get(ResultKey); ok {
+		if r, ok := v.(*Result); ok {
+			return r
+		}
+	}
+	return &Result{Decision: Allow}
+}
+
+// SetRetryAfter sets the Retry-After header, in whole seconds, for a refused result
+func SetRetryAfter(c *gin.Context, r *Result) {
+	if r.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(r.RetryAfter.Seconds()))))
+	}
+}
@@ -0,0 +1,89 @@
+package abuse
+
+import (
+	"strings"
+	"time"
+)
+
+// Decision is the outcome of evaluating the rules for a request
+type Decision string
+
+const (
+	Allow Decision = "allow"
+	// Challenge asks the caller to prove it is human (CAPTCHA, OTP) before going on
+	Challenge Decision = "challenge"
+	Deny      Decision = "deny"
+)
+
+// weight orders decisions so the strictest one wins
+func (d Decision) weight() int {
+	switch d {
+	case Deny:
+		return 2
+	case Challenge:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Dimension is what a rule counts by
+type Dimension string
+
+const (
+	DimensionIP     Dimension = "ip"
+	DimensionUser   Dimension = "user"
+	DimensionPhone  Dimension = "phone"
+	DimensionEmail  Dimension = "email"
+	DimensionDevice Dimension = "device"
+)
+
+// Subject holds the identifiers of the caller known to the endpoint; rules on a dimension the
+// subject does not have are skipped
+type Subject map[Dimension]string
+
+// Common events; services may define their own, e.g. "coupon_redeemed"
+const (
+	EventLoginFailed   = "login_failed"
+	EventOTPRequested  = "otp_requested"
+	EventOTPFailed     = "otp_failed"
+	EventPasswordReset = "password_reset"
+	EventSignup        = "signup"
+)
+
+// Rule trips when more than Limit events are counted for the same dimension value within Window
+type Rule struct {
+	// Name identifies the rule in counters, results and audit entries; it must be unique
+	Name      string
+	Event     string
+	Dimension Dimension
+	Limit     int
+	Window    time.Duration
+	// Action is the decision once the limit is exceeded, defaults to Deny
+	Action Decision
+	// Block keeps denying the dimension value for this long after the rule trips, even once
+	// the window has passed (0 relies on the window alone)
+	Block time.Duration
+}
+
+// DefaultRules cover the usual authentication endpoints: failed logins per account and per IP,
+// OTP requests per phone and per IP, and password reset requests per email
+var DefaultRules = []Rule{
+	{Name: "login_failed_user_challenge", Event: EventLoginFailed, Dimension: DimensionUser, Limit: 3, Window: 10 * time.Minute, Action: Challenge},
+	{Name: "login_failed_user", Event: EventLoginFailed, Dimension: DimensionUser, Limit: 10, Window: 10 * time.Minute, Block: 15 * time.Minute},
+	{Name: "login_failed_ip", Event: EventLoginFailed, Dimension: DimensionIP, Limit: 50, Window: 10 * time.Minute, Block: 30 * time.Minute},
+	{Name: "otp_requested_phone", Event: EventOTPRequested, Dimension: DimensionPhone, Limit: 5, Window: time.Hour},
+	{Name: "otp_requested_ip", Event: EventOTPRequested, Dimension: DimensionIP, Limit: 30, Window: time.Hour},
+	{Name: "otp_failed_phone", Event: EventOTPFailed, Dimension: DimensionPhone, Limit: 10, Window: time.Hour, Block: time.Hour},
+	{Name: "password_reset_email", Event: EventPasswordReset, Dimension: DimensionEmail, Limit: 3, Window: time.Hour},
+}
+
+// normalize lowercases identifiers that are case-insensitive so "A@x.com" and "a@x.com" share
+// a counter
+func normalize(dim Dimension, value string) string {
+	value = strings.TrimSpace(value)
+	if dim == DimensionEmail {
+		return strings.ToLower(value)
+	}
+	return value
+}
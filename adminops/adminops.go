@@ -0,0 +1,191 @@
+// Package adminops mounts the operational endpoints every service exposes the same way:
+// cache purge, feature flags, log levels, maintenance mode and a redacted config dump. The group
+// only answers internal services (service secret), from trusted IPs, on behalf of a user holding
+// the admin permission, e.g.
+//
+//	ops := adminops.New(adminops.Config{
+//		TrustedIPs:  cfg.AdminIPs,
+//		EnvPrefixes: []string{"APP_", "PORT"},
+//		Caches:      []adminops.Cache{usersCache, adminops.CacheFunc("permissions", purgePermissions)},
+//		Flags:       adminops.NewRedisFlags(rdb, "", map[string]bool{"new-checkout": false}),
+//	})
+//	router.Use(ops.MaintenanceMiddleware())
+//	ops.Register(router)
+package adminops
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masharah-Advisory/common/audit"
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/i18n"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/middleware"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/Masharah-Advisory/common/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// MaintenanceFlag is the flag holding maintenance mode
+const MaintenanceFlag = "maintenance"
+
+// Audit actions recorded for admin operations
+const (
+	ActionCachePurged        audit.Action = "adminops.cache_purged"
+	ActionFlagChanged        audit.Action = "adminops.flag_changed"
+	ActionLogLevelChanged    audit.Action = "adminops.log_level_changed"
+	ActionMaintenanceChanged audit.Action = "adminops.maintenance_changed"
+)
+
+// Cache is a cache the admin endpoints can purge; *cache.Cache[T] implements it
+type Cache interface {
+	Name() string
+	Clear(ctx context.Context) error
+}
+
+type cacheFunc struct {
+	name  string
+	clear func(ctx context.Context) error
+}
+
+func (c cacheFunc) Name() string                    { return c.name }
+func (c cacheFunc) Clear(ctx context.Context) error { return c.clear(ctx) }
+
+// CacheFunc adapts other caches, e.g. a localcache.Cache, to Cache
+func CacheFunc(name string, clear func(ctx context.Context) error) Cache {
+	return cacheFunc{name: name, clear: clear}
+}
+
+// Config configures the admin endpoints
+type Config struct {
+	// Path is where Register mounts the group, defaults to "/internal/admin"
+	Path string
+	// TrustedIPs may call the endpoints; leave it empty only behind a gateway that filters them
+	TrustedIPs []string
+	// Permission is required from the user the calling service acts for, defaults to "admin.ops"
+	Permission string
+	Caches     []Cache
+	// Flags defaults to NewMemoryFlags(nil); maintenance mode is stored there as MaintenanceFlag
+	Flags Flags
+	// MaintenanceAllow are path prefixes still served in maintenance, defaults to Path,
+	// "/health", "/ready" and "/metrics"
+	MaintenanceAllow []string
+	// MaintenanceRefresh is how long MaintenanceMiddleware trusts the flag it read, defaults to 5s
+	MaintenanceRefresh time.Duration
+	// EnvPrefixes are the names or prefixes of the environment variables shown in the config
+	// dump, e.g. "APP_" or "PORT"; none when empty. Secret-looking ones are redacted anyway.
+	EnvPrefixes []string
+	// Dump returns the service configuration for the config dump; redact secrets in it
+	Dump func() interface{}
+}
+
+// Ops serves the admin endpoints
+type Ops struct {
+	cfg    Config
+	log    *zap.Logger
+	levels gin.HandlerFunc
+
+	mu          sync.Mutex
+	maintenance bool
+	checkedAt   time.Time
+}
+
+// New creates the admin endpoints
+func New(cfg Config) *Ops {
+	if cfg.Path == "" {
+		cfg.Path = "/internal/admin"
+	}
+	if cfg.Permission == "" {
+		cfg.Permission = "admin.ops"
+	}
+	if cfg.Flags == nil {
+		cfg.Flags = NewMemoryFlags(nil)
+	}
+	if cfg.MaintenanceAllow == nil {
+		cfg.MaintenanceAllow = []string{cfg.Path, "/health", "/ready", "/metrics"}
+	}
+	if cfg.MaintenanceRefresh <= 0 {
+		cfg.MaintenanceRefresh = 5 * time.Second
+	}
+	return &Ops{cfg: cfg, log: logger.Module("adminops"), levels: logger.LevelHandler()}
+}
+
+// Register mounts the protected group on r and returns it, e.g. for service-specific operations
+func (o *Ops) Register(r gin.IRouter) *gin.RouterGroup {
+	rg := r.Group(o.cfg.Path,
+		middleware.ServiceAuthMiddleware(),
+		middleware.TrustedIPMiddleware(o.cfg.TrustedIPs),
+		operator,
+		middleware.RequirePermission(o.cfg.Permission),
+	)
+	rg.GET("/caches", o.Caches)
+	rg.POST("/caches/purge", o.PurgeCaches)
+	rg.GET("/flags", o.ListFlags)
+	rg.PUT("/flags/:name", o.SetFlag)
+	rg.GET("/log-level", o.levels)
+	rg.PUT("/log-level", o.SetLogLevel)
+	rg.GET("/maintenance", o.GetMaintenance)
+	rg.PUT("/maintenance", o.SetMaintenance)
+	rg.GET("/config", o.Config)
+	return rg
+}
+
+// operator takes the user the calling service acts for from X-User-ID; only authenticated
+// services get here, so the header is trusted
+func operator(c *gin.Context) {
+	if id, err := strconv.ParseUint(c.GetHeader(utils.XUserIDHeader), 10, 64); err == nil && id != 0 {
+		ctxutil.WithUserID(c, id)
+	}
+	c.Next()
+}
+
+// MaintenanceMiddleware answers 503 while maintenance mode is on, except on the allowed paths;
+// install it on the engine before the routes
+func (o *Ops) MaintenanceMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !o.inMaintenance(c.Request.Context()) || o.allowed(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		c.Header("Retry-After", "60")
+		response.Error(c, http.StatusServiceUnavailable, i18n.T(c, "adminops.maintenance"))
+		c.Abort()
+	}
+}
+
+// inMaintenance reads the maintenance flag at most once per refresh interval
+func (o *Ops) inMaintenance(ctx context.Context) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if time.Since(o.checkedAt) >= o.cfg.MaintenanceRefresh {
+		o.maintenance = o.cfg.Flags.Enabled(ctx, MaintenanceFlag)
+		o.checkedAt = time.Now()
+	}
+	return o.maintenance
+}
+
+func (o *Ops) allowed(path string) bool {
+	for _, prefix := range o.cfg.MaintenanceAllow {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// record audits an operation; a failure is only logged
+func (o *Ops) record(ctx context.Context, action audit.Action, id string, meta map[string]interface{}) {
+	opts := make([]audit.Option, 0, len(meta))
+	for k, v := range meta {
+		opts = append(opts, audit.WithMetadata(k, v))
+	}
+	if err := audit.Record(ctx, action, audit.Resource{Type: "adminops", ID: id}, nil, opts...); err != nil {
+		o.log.Warn("failed to audit admin operation", zap.String("action", string(action)), zap.Error(err))
+	}
+}
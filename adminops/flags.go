@@ -0,0 +1,128 @@
+package adminops
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Flags stores the feature flags toggled through the admin endpoints
+type Flags interface {
+	Enabled(ctx context.Context, name string) bool
+	// All returns every known flag with its state
+	All(ctx context.Context) (map[string]bool, error)
+	Set(ctx context.Context, name string, enabled bool) error
+}
+
+// MemoryFlags keeps flags in the process, so each replica toggles on its own; use RedisFlags
+// to share them
+type MemoryFlags struct {
+	mu    sync.RWMutex
+	flags map[string]bool
+}
+
+// NewMemoryFlags creates flags starting from defaults
+func NewMemoryFlags(defaults map[string]bool) *MemoryFlags {
+	f := &MemoryFlags{flags: make(map[string]bool, len(defaults))}
+	for name, on := range defaults {
+		f.flags[name] = on
+	}
+	return f
+}
+
+// Enabled reports whether the flag is on; unknown flags are off
+func (f *MemoryFlags) Enabled(_ context.Context, name string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.flags[name]
+}
+
+// All implements Flags
+func (f *MemoryFlags) All(_ context.Context) (map[string]bool, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	out := make(map[string]bool, len(f.flags))
+	for name, on := range f.flags {
+		out[name] = on
+	}
+	return out, nil
+}
+
+// Set implements Flags
+func (f *MemoryFlags) Set(_ context.Context, name string, enabled bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flags[name] = enabled
+	return nil
+}
+
+// RedisFlags keeps flags in a Redis hash shared by every replica; flags never set there take
+// their default
+type RedisFlags struct {
+	rdb      *redis.Client
+	key      string
+	defaults map[string]bool
+}
+
+// NewRedisFlags creates flags stored under key, defaulting to "adminops:flags"
+func NewRedisFlags(rdb *redis.Client, key string, defaults map[string]bool) *RedisFlags {
+	if key == "" {
+		key = "adminops:flags"
+	}
+	return &RedisFlags{rdb: rdb, key: key, defaults: defaults}
+}
+
+// Enabled reports whether the flag is on, falling back to its default when Redis fails
+func (f *RedisFlags) Enabled(ctx context.Context, name string) bool {
+	v, err := f.rdb.HGet(ctx, f.key, name).Result()
+	if err != nil {
+		return f.defaults[name]
+	}
+	on, _ := strconv.ParseBool(v)
+	return on
+}
+
+// All implements Flags
+func (f *RedisFlags) All(ctx context.Context) (map[string]bool, error) {
+	values, err := f.rdb.HGetAll(ctx, f.key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read flags: %w", err)
+	}
+	out := make(map[string]bool, len(f.defaults)+len(values))
+	for name, on := range f.defaults {
+		out[name] = on
+	}
+	for name, v := range values {
+		out[name], _ = strconv.ParseBool(v)
+	}
+	return out, nil
+}
+
+// Set implements Flags
+func (f *RedisFlags) Set(ctx context.Context, name string, enabled bool) error {
+	if err := f.rdb.HSet(ctx, f.key, name, strconv.FormatBool(enabled)).Err(); err != nil {
+		return fmt.Errorf("failed to set flag: %w", err)
+	}
+	return nil
+}
+
+// flagList is the JSON form of flags, sorted by name
+type flagList []flagState
+
+type flagState struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+func sortedFlags(flags map[string]bool) flagList {
+	out := make(flagList, 0, len(flags))
+	for name, on := range flags {
+		out = append(out, flagState{Name: name, Enabled: on})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
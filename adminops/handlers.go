@@ -0,0 +1,199 @@
+package adminops
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/audit"
+	"github.com/Masharah-Advisory/common/buildinfo"
+	"github.com/Masharah-Advisory/common/config"
+	"github.com/Masharah-Advisory/common/i18n"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ErrUnknownCache is returned when a purge names a cache that is not registered
+var ErrUnknownCache = apperror.New("adminops_unknown_cache", apperror.KindNotFound, "unknown cache").
+	WithKey("adminops.unknown_cache")
+
+// PurgeRequest is the body of PurgeCaches; no names purges every cache
+type PurgeRequest struct {
+	Names []string `json:"names"`
+}
+
+// ToggleRequest is the body of SetFlag and SetMaintenance
+type ToggleRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// Caches lists the purgeable caches
+func (o *Ops) Caches(c *gin.Context) {
+	names := make([]string, 0, len(o.cfg.Caches))
+	for _, cache := range o.cfg.Caches {
+		names = append(names, cache.Name())
+	}
+	response.OK(c, names)
+}
+
+// PurgeCaches clears the named caches, or all of them, and returns the names purged
+func (o *Ops) PurgeCaches(c *gin.Context) {
+	var req PurgeRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, i18n.T(c, "adminops.invalid_request"), response.ProcessBindingError(c, err))
+			return
+		}
+	}
+	targets := o.cfg.Caches
+	if len(req.Names) > 0 {
+		byName := make(map[string]Cache, len(o.cfg.Caches))
+		for _, cache := range o.cfg.Caches {
+			byName[cache.Name()] = cache
+		}
+		targets = make([]Cache, 0, len(req.Names))
+		for _, name := range req.Names {
+			cache, ok := byName[name]
+			if !ok {
+				response.HandleError(c, ErrUnknownCache)
+				return
+			}
+			targets = append(targets, cache)
+		}
+	}
+
+	purged := make([]string, 0, len(targets))
+	for _, cache := range targets {
+		if err := cache.Clear(c.Request.Context()); err != nil {
+			response.HandleError(c, err)
+			return
+		}
+		purged = append(purged, cache.Name())
+		o.record(c, ActionCachePurged, cache.Name(), nil)
+	}
+	logger.FromContext(c).Info("caches purged via admin endpoint", zap.Strings("caches", purged))
+	response.OK(c, purged)
+}
+
+// ListFlags returns the feature flags sorted by name
+func (o *Ops) ListFlags(c *gin.Context) {
+	flags, err := o.cfg.Flags.All(c.Request.Context())
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, sortedFlags(flags))
+}
+
+// SetFlag turns a feature flag on or off
+func (o *Ops) SetFlag(c *gin.Context) {
+	o.toggle(c, c.Param("name"), ActionFlagChanged)
+}
+
+// GetMaintenance reports whether maintenance mode is on
+func (o *Ops) GetMaintenance(c *gin.Context) {
+	response.OK(c, flagState{Name: MaintenanceFlag, Enabled: o.cfg.Flags.Enabled(c.Request.Context(), MaintenanceFlag)})
+}
+
+// SetMaintenance turns maintenance mode on or off; other replicas follow within
+// MaintenanceRefresh
+func (o *Ops) SetMaintenance(c *gin.Context) {
+	o.toggle(c, MaintenanceFlag, ActionMaintenanceChanged)
+}
+
+func (o *Ops) toggle(c *gin.Context, name string, action audit.Action) {
+	var req ToggleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, i18n.T(c, "adminops.invalid_request"), response.ProcessBindingError(c, err))
+		return
+	}
+	if err := o.cfg.Flags.Set(c.Request.Context(), name, *req.Enabled); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if name == MaintenanceFlag {
+		o.mu.Lock()
+		o.maintenance, o.checkedAt = *req.Enabled, time.Now()
+		o.mu.Unlock()
+	}
+	o.record(c, action, name, map[string]interface{}{"enabled": *req.Enabled})
+	logger.FromContext(c).Info("flag changed via admin endpoint", zap.String("flag", name), zap.Bool("enabled", *req.Enabled))
+	response.OK(c, flagState{Name: name, Enabled: *req.Enabled})
+}
+
+// SetLogLevel changes log levels like logger.LevelHandler and audits the change
+func (o *Ops) SetLogLevel(c *gin.Context) {
+	o.levels(c)
+	if c.Writer.Status() == http.StatusOK {
+		o.record(c, ActionLogLevelChanged, "log-level", map[string]interface{}{"level": logger.GetLevel(), "modules": logger.ModuleLevels()})
+	}
+}
+
+// ConfigDump is the body of Config
+type ConfigDump struct {
+	Env     config.Environment `json:"env"`
+	Build   buildinfo.Info     `json:"build"`
+	Vars    map[string]string  `json:"vars"`
+	Service interface{}        `json:"service,omitempty"`
+}
+
+// Config dumps the environment, build and service configuration with secrets redacted
+func (o *Ops) Config(c *gin.Context) {
+	dump := ConfigDump{Env: config.Env(), Build: buildinfo.Get(), Vars: o.envVars()}
+	if o.cfg.Dump != nil {
+		dump.Service = o.cfg.Dump()
+	}
+	response.OK(c, dump)
+}
+
+// secretNames mark environment variables whose values are never shown, even under an allowed
+// prefix, e.g. DB_PASS, REDIS_PASS or OTEL_EXPORTER_OTLP_HEADERS
+var secretNames = []string{"SECRET", "PASS", "TOKEN", "KEY", "CREDENTIAL", "PRIVATE", "DSN", "AUTH", "HEADERS", "COOKIE"}
+
+// envVars returns the environment variables under the configured prefixes, redacted; none
+// unless prefixes are configured
+func (o *Ops) envVars() map[string]string {
+	vars := make(map[string]string)
+	env := os.Environ()
+	sort.Strings(env)
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		if !o.dumped(name) {
+			continue
+		}
+		vars[name] = redact(name, value)
+	}
+	return vars
+}
+
+func (o *Ops) dumped(name string) bool {
+	for _, prefix := range o.cfg.EnvPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact hides secret variables and the passwords of URLs, e.g. DATABASE_URL
+func redact(name, value string) string {
+	upper := strings.ToUpper(name)
+	for _, s := range secretNames {
+		if strings.Contains(upper, s) {
+			return "[redacted]"
+		}
+	}
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), "redacted")
+			return u.String()
+		}
+	}
+	return value
+}
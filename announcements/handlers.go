@@ -0,0 +1,165 @@
+package announcements
+
+import (
+	"strconv"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/dto"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// RolesResolver returns the roles of the current user for audience targeting
+type RolesResolver func(c *gin.Context) []string
+
+// Handlers exposes the current user's announcements and their management, scoped to the
+// tenant_id set on the context
+type Handlers struct {
+	store *Store
+	roles RolesResolver
+}
+
+// NewHandlers creates the handlers; roles may be nil when no announcement targets roles
+func NewHandlers(store *Store, roles RolesResolver) *Handlers {
+	if roles == nil {
+		roles = func(c *gin.Context) []string { return c.GetStringSlice("roles") }
+	}
+	return &Handlers{store: store, roles: roles}
+}
+
+// Register mounts the user endpoints on a router group, e.g. /announcements
+func (h *Handlers) Register(rg *gin.RouterGroup) {
+	rg.GET("", h.Active)
+	rg.POST("/:id/dismiss", h.Dismiss)
+}
+
+// RegisterAdmin mounts the management endpoints; protect the group with a permission
+func (h *Handlers) RegisterAdmin(rg *gin.RouterGroup) {
+	rg.GET("", h.List)
+	rg.POST("", h.Create)
+	rg.GET("/:id", h.Get)
+	rg.PUT("/:id", h.Update)
+	rg.DELETE("/:id", h.Delete)
+}
+
+// Active returns the announcements the current user should see now
+func (h *Handlers) Active(c *gin.Context) {
+	userID, _ := ctxutil.UserID(c)
+	items, err := h.store.Active(c.Request.Context(), ctxutil.TenantID(c), userID, i18n.Lang(c), h.roles(c))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if items == nil {
+		items = []Announcement{}
+	}
+	c.Header("Cache-Control", "private, max-age=60")
+	response.OK(c, items)
+}
+
+// Dismiss hides a dismissible announcement from the current user
+func (h *Handlers) Dismiss(c *gin.Context) {
+	userID, ok := ctxutil.UserID(c)
+	if !ok {
+		response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
+		return
+	}
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	if err := h.store.Dismiss(c.Request.Context(), ctxutil.TenantID(c), userID, id); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+// List pages through the tenant's announcements
+func (h *Handlers) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	items, total, err := h.store.List(c.Request.Context(), ctxutil.TenantID(c), page, limit)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, dto.BuildPaginatedResponse(items, total, page, limit))
+}
+
+// Create creates an announcement
+func (h *Handlers) Create(c *gin.Context) {
+	var in Input
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.BadRequest(c, i18n.T(c, "announcements.invalid_request"), response.ProcessBindingError(c, err))
+		return
+	}
+	a, err := h.store.Create(c.Request.Context(), ctxutil.TenantID(c), in)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.Created(c, a)
+}
+
+// Get returns one announcement
+func (h *Handlers) Get(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	a, err := h.store.Get(c.Request.Context(), ctxutil.TenantID(c), id)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, a)
+}
+
+// Update replaces the editable fields of an announcement
+func (h *Handlers) Update(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var in Input
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.BadRequest(c, i18n.T(c, "announcements.invalid_request"), response.ProcessBindingError(c, err))
+		return
+	}
+	a, err := h.store.Update(c.Request.Context(), ctxutil.TenantID(c), id, in)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, a)
+}
+
+// Delete removes an announcement
+func (h *Handlers) Delete(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	if err := h.store.Delete(c.Request.Context(), ctxutil.TenantID(c), id); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func paramID(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, i18n.T(c, "announcements.invalid_request"))
+		return 0, false
+	}
+	return id, true
+}
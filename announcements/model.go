@@ -0,0 +1,73 @@
+package announcements
+
+import (
+	"time"
+
+	"github.com/Masharah-Advisory/common/model"
+)
+
+// Level controls how prominently front-ends show an announcement
+type Level string
+
+const (
+	LevelInfo     Level = "info"
+	LevelWarning  Level = "warning"
+	LevelCritical Level = "critical"
+)
+
+// Announcement is a time-boxed banner shown to an audience; empty TenantID, Locale and Roles
+// match everyone
+type Announcement struct {
+	model.Base
+	TenantID    string     `json:"tenant_id,omitempty" gorm:"index;size:64"`
+	Title       string     `json:"title" gorm:"size:255;not null"`
+	Body        string     `json:"body" gorm:"type:text"`
+	Level       Level      `json:"level" gorm:"size:16;default:info"`
+	Link        string     `json:"link,omitempty" gorm:"size:1024"`
+	Locale      string     `json:"locale,omitempty" gorm:"size:8"`
+	Roles       []string   `json:"roles,omitempty" gorm:"serializer:json;size:512"`
+	Priority    int        `json:"priority"`
+	Dismissible bool       `json:"dismissible"`
+	StartsAt    time.Time  `json:"starts_at" gorm:"index"`
+	EndsAt      *time.Time `json:"ends_at,omitempty" gorm:"index"`
+}
+
+// TableName overrides the table name
+func (Announcement) TableName() string {
+	return "announcements"
+}
+
+// Active reports whether the announcement is showing at t
+func (a *Announcement) Active(t time.Time) bool {
+	return !t.Before(a.StartsAt) && (a.EndsAt == nil || t.Before(*a.EndsAt))
+}
+
+// Matches reports whether a user with the given locale and roles is in the audience
+func (a *Announcement) Matches(locale string, roles []string) bool {
+	if a.Locale != "" && a.Locale != locale {
+		return false
+	}
+	if len(a.Roles) == 0 {
+		return true
+	}
+	for _, want := range a.Roles {
+		for _, have := range roles {
+			if want == have {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Dismissal records that a user closed a dismissible announcement
+type Dismissal struct {
+	UserID         uint64    `json:"user_id" gorm:"primaryKey"`
+	AnnouncementID uint64    `json:"announcement_id" gorm:"primaryKey"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (Dismissal) TableName() string {
+	return "announcement_dismissals"
+}
@@ -0,0 +1,253 @@
+package announcements
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/cache"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Errors returned by the store
+var (
+	ErrNotFound = apperror.New("announcement_not_found", apperror.KindNotFound, "announcement not found").
+			WithKey("announcements.not_found")
+	ErrInvalidWindow = apperror.New("announcement_invalid_window", apperror.KindBadRequest, "the announcement must end after it starts").
+				WithKey("announcements.invalid_window")
+)
+
+// Input is the editable part of an announcement
+type Input struct {
+	Title       string     `json:"title" binding:"required,max=255"`
+	Body        string     `json:"body"`
+	Level       Level      `json:"level" binding:"omitempty,oneof=info warning critical"`
+	Link        string     `json:"link" binding:"omitempty,url,max=1024"`
+	Locale      string     `json:"locale" binding:"omitempty,oneof=en ar"`
+	Roles       []string   `json:"roles"`
+	Priority    int        `json:"priority"`
+	Dismissible bool       `json:"dismissible"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+}
+
+// Config configures a Store
+type Config struct {
+	DB *gorm.DB
+	// Cache holds each tenant's current announcements (optional); give it a short TTL such as
+	// cache.Config{Name: "announcements", L1TTL: 30 * time.Second, TTL: time.Minute}
+	Cache *cache.Cache[[]Announcement]
+}
+
+// Store manages announcements and answers which ones a user should see
+type Store struct {
+	cfg *Config
+	now func() time.Time
+}
+
+// NewStore creates a store; migrate Announcement and Dismissal first
+func NewStore(cfg *Config) *Store {
+	return &Store{cfg: cfg, now: time.Now}
+}
+
+// Create stores an announcement for tenantID, or for every tenant when it is empty
+func (s *Store) Create(ctx context.Context, tenantID string, in Input) (*Announcement, error) {
+	a := &Announcement{TenantID: tenantID}
+	if err := s.apply(a, in); err != nil {
+		return nil, err
+	}
+	if err := s.cfg.DB.WithContext(ctx).Create(a).Error; err != nil {
+		return nil, fmt.Errorf("failed to create announcement: %w", err)
+	}
+	s.invalidate(ctx, tenantID)
+	return a, nil
+}
+
+// Update replaces the editable fields of an announcement
+func (s *Store) Update(ctx context.Context, tenantID string, id uint64, in Input) (*Announcement, error) {
+	a, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(a, in); err != nil {
+		return nil, err
+	}
+	if err := s.cfg.DB.WithContext(ctx).Save(a).Error; err != nil {
+		return nil, fmt.Errorf("failed to update announcement: %w", err)
+	}
+	s.invalidate(ctx, tenantID)
+	return a, nil
+}
+
+// Delete removes an announcement
+func (s *Store) Delete(ctx context.Context, tenantID string, id uint64) error {
+	res := s.cfg.DB.WithContext(ctx).Model(&Announcement{}).
+		Where("id = ? AND tenant_id = ? AND deleted_at IS NULL", id, tenantID).
+		Update("deleted_at", s.now())
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete announcement: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	s.invalidate(ctx, tenantID)
+	return nil
+}
+
+// Get returns one announcement of tenantID
+func (s *Store) Get(ctx context.Context, tenantID string, id uint64) (*Announcement, error) {
+	var a Announcement
+	err := s.cfg.DB.WithContext(ctx).
+		Where("id = ? AND tenant_id = ? AND deleted_at IS NULL", id, tenantID).
+		First(&a).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load announcement: %w", err)
+	}
+	return &a, nil
+}
+
+// List pages through tenantID's announcements, newest first, including expired ones
+func (s *Store) List(ctx context.Context, tenantID string, page, limit int) ([]Announcement, int64, error) {
+	q := s.cfg.DB.WithContext(ctx).Model(&Announcement{}).Where("tenant_id = ? AND deleted_at IS NULL", tenantID)
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count announcements: %w", err)
+	}
+	var items []Announcement
+	if err := q.Order("starts_at DESC, id DESC").Offset((page - 1) * limit).Limit(limit).Find(&items).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list announcements: %w", err)
+	}
+	return items, total, nil
+}
+
+// Active returns the announcements a user sees now: current, in their tenant or global,
+// matching their locale and roles and not dismissed, highest priority first
+func (s *Store) Active(ctx context.Context, tenantID string, userID uint64, locale string, roles []string) ([]Announcement, error) {
+	candidates, err := s.current(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	var out []Announcement
+	var dismissible []uint64
+	for _, a := range candidates {
+		if !a.Active(now) || !a.Matches(locale, roles) {
+			continue
+		}
+		out = append(out, a)
+		if a.Dismissible {
+			dismissible = append(dismissible, a.ID)
+		}
+	}
+
+	if userID != 0 && len(dismissible) > 0 {
+		var dismissed []uint64
+		err := s.cfg.DB.WithContext(ctx).Model(&Dismissal{}).
+			Where("user_id = ? AND announcement_id IN ?", userID, dismissible).
+			Pluck("announcement_id", &dismissed).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to load dismissed announcements: %w", err)
+		}
+		out = without(out, dismissed)
+	}
+	return out, nil
+}
+
+// Dismiss hides a dismissible announcement from the user
+func (s *Store) Dismiss(ctx context.Context, tenantID string, userID, id uint64) error {
+	var a Announcement
+	err := s.cfg.DB.WithContext(ctx).
+		Where("id = ? AND tenant_id IN ? AND deleted_at IS NULL AND dismissible = ?", id, []string{tenantID, ""}, true).
+		First(&a).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load announcement: %w", err)
+	}
+	err = s.cfg.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&Dismissal{UserID: userID, AnnouncementID: id}).Error
+	if err != nil {
+		return fmt.Errorf("failed to dismiss announcement: %w", err)
+	}
+	return nil
+}
+
+// current loads the tenant's and global announcements that have not ended, through the cache
+func (s *Store) current(ctx context.Context, tenantID string) ([]Announcement, error) {
+	load := func(ctx context.Context) ([]Announcement, error) {
+		var items []Announcement
+		err := s.cfg.DB.WithContext(ctx).
+			Where("tenant_id IN ? AND deleted_at IS NULL AND (ends_at IS NULL OR ends_at > ?)", []string{tenantID, ""}, s.now()).
+			Find(&items).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to load announcements: %w", err)
+		}
+		sort.SliceStable(items, func(i, j int) bool {
+			if items[i].Priority != items[j].Priority {
+				return items[i].Priority > items[j].Priority
+			}
+			return items[i].StartsAt.After(items[j].StartsAt)
+		})
+		return items, nil
+	}
+	if s.cfg.Cache == nil {
+		return load(ctx)
+	}
+	return s.cfg.Cache.GetOrLoad(ctx, "tenant:"+tenantID, load)
+}
+
+// invalidate drops cached lists after a change; global announcements affect every tenant
+func (s *Store) invalidate(ctx context.Context, tenantID string) {
+	if s.cfg.Cache == nil {
+		return
+	}
+	if tenantID == "" {
+		_ = s.cfg.Cache.Clear(ctx)
+		return
+	}
+	_ = s.cfg.Cache.Delete(ctx, "tenant:"+tenantID)
+}
+
+func (s *Store) apply(a *Announcement, in Input) error {
+	a.Title, a.Body, a.Link, a.Locale = in.Title, in.Body, in.Link, in.Locale
+	a.Roles, a.Priority, a.Dismissible = in.Roles, in.Priority, in.Dismissible
+	a.Level = in.Level
+	if a.Level == "" {
+		a.Level = LevelInfo
+	}
+	a.StartsAt = s.now()
+	if in.StartsAt != nil {
+		a.StartsAt = *in.StartsAt
+	}
+	a.EndsAt = in.EndsAt
+	if a.EndsAt != nil && !a.EndsAt.After(a.StartsAt) {
+		return ErrInvalidWindow
+	}
+	return nil
+}
+
+func without(items []Announcement, ids []uint64) []Announcement {
+	if len(ids) == 0 {
+		return items
+	}
+	skip := make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		skip[id] = true
+	}
+	out := items[:0]
+	for _, a := range items {
+		if !skip[a.ID] {
+			out = append(out, a)
+		}
+	}
+	return out
+}
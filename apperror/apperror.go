@@ -0,0 +1,257 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// Kind classifies an error and determines its HTTP status
+type Kind string
+
+const (
+	KindBadRequest   Kind = "bad_request"
+	KindValidation   Kind = "validation"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindTooLarge     Kind = "too_large"
+	KindRateLimited  Kind = "rate_limited"
+	KindInternal     Kind = "internal"
+	KindUnavailable  Kind = "unavailable"
+	KindTimeout      Kind = "timeout"
+)
+
+// statusByKind is the single mapping table between error kinds and HTTP statuses
+var statusByKind = map[Kind]int{
+	KindBadRequest:   http.StatusBadRequest,
+	KindValidation:   http.StatusUnprocessableEntity,
+	KindUnauthorized: http.StatusUnauthorized,
+	KindForbidden:    http.StatusForbidden,
+	KindNotFound:     http.StatusNotFound,
+	KindConflict:     http.StatusConflict,
+	KindTooLarge:     http.StatusRequestEntityTooLarge,
+	KindRateLimited:  http.StatusTooManyRequests,
+	KindInternal:     http.StatusInternalServerError,
+	KindUnavailable:  http.StatusServiceUnavailable,
+	KindTimeout:      http.StatusGatewayTimeout,
+}
+
+// HTTPStatus returns the HTTP status for a kind, defaulting to 500
+func HTTPStatus(kind Kind) int {
+	if status, ok := statusByKind[kind]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// KindFromStatus maps an HTTP status back to a kind (used when decoding downstream errors)
+func KindFromStatus(status int) Kind {
+	for kind, s := range statusByKind {
+		if s == status {
+			return kind
+		}
+	}
+
+	switch {
+	case status == http.StatusBadGateway:
+		return KindUnavailable
+	case status == http.StatusRequestTimeout:
+		return KindTimeout
+	case status >= 500:
+		return KindInternal
+	default:
+		return KindBadRequest
+	}
+}
+
+// Error is the application error shared by all services
+type Error struct {
+	Code       string
+	Kind       Kind
+	Message    string
+	MessageKey string
+	Meta       map[string]interface{}
+	Err        error
+	stack      []uintptr
+}
+
+// New creates an error with a machine-readable code, e.g. New("user_not_found", KindNotFound, "user not found")
+func New(code string, kind Kind, message string) *Error {
+	return &Error{
+		Code:    code,
+		Kind:    kind,
+		Message: message,
+		stack:   callers(),
+	}
+}
+
+// Newf creates an error with a formatted message
+func Newf(code string, kind Kind, format string, args ...interface{}) *Error {
+	e := New(code, kind, fmt.Sprintf(format, args...))
+	e.stack = callers()
+	return e
+}
+
+// Wrap wraps err with a code, kind and message; it returns nil when err is nil
+func Wrap(err error, code string, kind Kind, message string) *Error {
+	if err == nil {
+		return nil
+	}
+	e := New(code, kind, message)
+	e.Err = err
+	e.stack = callers()
+	return e
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = e.Code
+	}
+	if e.Err != nil {
+		return msg + ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the wrapped error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches another *Error by code, so errors.Is(err, ErrNotFound) works with sentinel errors
+func (e *Error) Is(target error) bool {
+	var t *Error
+	if !errors.As(target, &t) {
+		return false
+	}
+	return t.Code != "" && t.Code == e.Code
+}
+
+// HTTPStatus returns the HTTP status derived from the error kind
+func (e *Error) HTTPStatus() int {
+	return HTTPStatus(e.Kind)
+}
+
+// Clone returns a copy of e with its own metadata and a fresh stack, so package sentinels can
+// be decorated per call, e.g. ErrNotFound.Clone().WithMeta("ID", id), without mutating them
+func (e *Error) Clone() *Error {
+	c := *e
+	c.Meta = make(map[string]interface{}, len(e.Meta))
+	for k, v := range e.Meta {
+		c.Meta[k] = v
+	}
+	c.stack = callers()
+	return &c
+}
+
+// WithKey sets the i18n message key used when rendering the error to clients
+func (e *Error) WithKey(key string) *Error {
+	e.MessageKey = key
+	return e
+}
+
+// WithMeta attaches a metadata entry (exposed to logs, and to i18n templates)
+func (e *Error) WithMeta(key string, value interface{}) *Error {
+	if e.Meta == nil {
+		e.Meta = make(map[string]interface{})
+	}
+	e.Meta[key] = value
+	return e
+}
+
+// WithCause sets the wrapped error
+func (e *Error) WithCause(err error) *Error {
+	e.Err = err
+	return e
+}
+
+// Stack returns the call stack captured when the error was created
+func (e *Error) Stack() string {
+	if len(e.stack) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// As returns the first *Error in err's chain
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// KindOf returns the kind of err, or KindInternal for errors that aren't application errors
+func KindOf(err error) Kind {
+	if e, ok := As(err); ok {
+		return e.Kind
+	}
+	return KindInternal
+}
+
+// IsKind reports whether err is an application error of the given kind
+func IsKind(err error, kind Kind) bool {
+	e, ok := As(err)
+	return ok && e.Kind == kind
+}
+
+// Convenience constructors for the most common kinds
+
+// NotFound creates a KindNotFound error
+func NotFound(code, message string) *Error {
+	e := New(code, KindNotFound, message)
+	e.stack = callers()
+	return e
+}
+
+// Conflict creates a KindConflict error
+func Conflict(code, message string) *Error {
+	e := New(code, KindConflict, message)
+	e.stack = callers()
+	return e
+}
+
+// Forbidden creates a KindForbidden error
+func Forbidden(code, message string) *Error {
+	e := New(code, KindForbidden, message)
+	e.stack = callers()
+	return e
+}
+
+// BadRequest creates a KindBadRequest error
+func BadRequest(code, message string) *Error {
+	e := New(code, KindBadRequest, message)
+	e.stack = callers()
+	return e
+}
+
+// Internal wraps err as a KindInternal error
+func Internal(err error, message string) *Error {
+	e := &Error{Code: "internal_error", Kind: KindInternal, Message: message, Err: err}
+	e.stack = callers()
+	return e
+}
+
+// callers captures the stack of the function that created the error
+func callers() []uintptr {
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:])
+	return pcs[:n]
+}
@@ -0,0 +1,225 @@
+package approvals
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/dto"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ApproverResolver returns the identity of the current user for deciding requests
+type ApproverResolver func(c *gin.Context) Approver
+
+// Handlers exposes the approval inbox, scoped to the tenant_id set on the context
+type Handlers struct {
+	store    *Store
+	approver ApproverResolver
+}
+
+// NewHandlers creates the handlers; approver may be nil to read the "roles" and "permissions"
+// string slices set on the gin context by the auth middleware
+func NewHandlers(store *Store, approver ApproverResolver) *Handlers {
+	if approver == nil {
+		approver = func(c *gin.Context) Approver {
+			userID, _ := ctxutil.UserID(c)
+			return Approver{UserID: userID, Roles: c.GetStringSlice("roles"), Permissions: c.GetStringSlice("permissions")}
+		}
+	}
+	return &Handlers{store: store, approver: approver}
+}
+
+// Register mounts the endpoints on a router group, e.g. /approvals
+func (h *Handlers) Register(rg *gin.RouterGroup) {
+	rg.GET("", h.Pending)
+	rg.GET("/count", h.Count)
+	rg.GET("/mine", h.Mine)
+	rg.POST("", h.Create)
+	rg.GET("/:id", h.Get)
+	rg.POST("/:id/approve", h.Approve)
+	rg.POST("/:id/reject", h.Reject)
+	rg.POST("/:id/cancel", h.Cancel)
+}
+
+// Decision is the body of approve and reject
+type Decision struct {
+	Note string `json:"note" binding:"max=5000"`
+}
+
+// Pending pages through the requests waiting for the current user
+func (h *Handlers) Pending(c *gin.Context) {
+	approver, ok := h.currentApprover(c)
+	if !ok {
+		return
+	}
+	page, limit := pagination(c)
+	items, total, err := h.store.Pending(c.Request.Context(), ctxutil.TenantID(c), approver, page, limit)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if items == nil {
+		items = []Request{}
+	}
+	response.OK(c, dto.BuildPaginatedResponse(items, total, page, limit))
+}
+
+// Count returns how many requests wait for the current user
+func (h *Handlers) Count(c *gin.Context) {
+	approver, ok := h.currentApprover(c)
+	if !ok {
+		return
+	}
+	n, err := h.store.CountPending(c.Request.Context(), ctxutil.TenantID(c), approver)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, gin.H{"pending": n})
+}
+
+// Mine pages through the current user's requests, optionally filtered by ?status=
+func (h *Handlers) Mine(c *gin.Context) {
+	userID, ok := ctxutil.UserID(c)
+	if !ok {
+		response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
+		return
+	}
+	status := Status(c.Query("status"))
+	switch status {
+	case "", StatusPending, StatusApproved, StatusRejected, StatusCancelled, StatusExpired:
+	default:
+		response.BadRequest(c, i18n.T(c, "approvals.invalid_request"))
+		return
+	}
+	page, limit := pagination(c)
+	items, total, err := h.store.Mine(c.Request.Context(), ctxutil.TenantID(c), userID, status, page, limit)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if items == nil {
+		items = []Request{}
+	}
+	response.OK(c, dto.BuildPaginatedResponse(items, total, page, limit))
+}
+
+// Create opens a request as the current user
+func (h *Handlers) Create(c *gin.Context) {
+	userID, ok := ctxutil.UserID(c)
+	if !ok {
+		response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
+		return
+	}
+	var in Input
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.BadRequest(c, i18n.T(c, "approvals.invalid_request"), response.ProcessBindingError(c, err))
+		return
+	}
+	r, err := h.store.Create(c.Request.Context(), ctxutil.TenantID(c), userID, in)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.Created(c, r)
+}
+
+// Get returns one request
+func (h *Handlers) Get(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	r, err := h.store.Get(c.Request.Context(), ctxutil.TenantID(c), id)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, r)
+}
+
+// Approve approves a request as the current user
+func (h *Handlers) Approve(c *gin.Context) {
+	h.decide(c, h.store.Approve)
+}
+
+// Reject rejects a request as the current user; the body must carry a note
+func (h *Handlers) Reject(c *gin.Context) {
+	h.decide(c, h.store.Reject)
+}
+
+func (h *Handlers) decide(c *gin.Context, fn func(ctx context.Context, tenantID string, id uint64, approver Approver, note string) (*Request, error)) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	approver, ok := h.currentApprover(c)
+	if !ok {
+		return
+	}
+	var in Decision
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&in); err != nil {
+			response.BadRequest(c, i18n.T(c, "approvals.invalid_request"), response.ProcessBindingError(c, err))
+			return
+		}
+	}
+	r, err := fn(c.Request.Context(), ctxutil.TenantID(c), id, approver, in.Note)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, r)
+}
+
+// Cancel withdraws one of the current user's pending requests
+func (h *Handlers) Cancel(c *gin.Context) {
+	userID, ok := ctxutil.UserID(c)
+	if !ok {
+		response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
+		return
+	}
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	r, err := h.store.Cancel(c.Request.Context(), ctxutil.TenantID(c), id, userID)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, r)
+}
+
+func (h *Handlers) currentApprover(c *gin.Context) (Approver, bool) {
+	approver := h.approver(c)
+	if approver.UserID == 0 {
+		response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
+		return approver, false
+	}
+	return approver, true
+}
+
+func pagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return page, limit
+}
+
+func paramID(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, i18n.T(c, "approvals.invalid_request"))
+		return 0, false
+	}
+	return id, true
+}
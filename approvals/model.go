@@ -0,0 +1,75 @@
+package approvals
+
+import (
+	"time"
+
+	"github.com/Masharah-Advisory/common/model"
+)
+
+// Status is the state of an approval request
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusApproved  Status = "approved"
+	StatusRejected  Status = "rejected"
+	StatusCancelled Status = "cancelled"
+	StatusExpired   Status = "expired"
+)
+
+// Request asks an approver to allow Action on a resource, e.g. a refund above a limit. Who may
+// decide is any of: the user ApproverID, holders of ApproverRole, holders of ApproverPermission.
+type Request struct {
+	model.Base
+	TenantID     string `json:"tenant_id,omitempty" gorm:"index;size:64"`
+	Action       string `json:"action" gorm:"index;size:128;not null"`
+	ResourceType string `json:"resource_type" gorm:"index:idx_approval_resource;size:64;not null"`
+	ResourceID   string `json:"resource_id" gorm:"index:idx_approval_resource;size:128;not null"`
+	Title        string `json:"title" gorm:"size:255;not null"`
+	Reason       string `json:"reason,omitempty" gorm:"type:text"`
+	// Payload carries what the decision hook needs to carry out the action, e.g. the amount
+	Payload map[string]interface{} `json:"payload,omitempty" gorm:"serializer:json"`
+
+	RequestedBy        uint64  `json:"requested_by" gorm:"index;not null"`
+	ApproverID         *uint64 `json:"approver_id,omitempty" gorm:"index"`
+	ApproverRole       string  `json:"approver_role,omitempty" gorm:"index;size:128"`
+	ApproverPermission string  `json:"approver_permission,omitempty" gorm:"index;size:128"`
+
+	Status       Status     `json:"status" gorm:"index;size:16;not null;default:pending"`
+	DecidedBy    *uint64    `json:"decided_by,omitempty"`
+	DecidedAt    *time.Time `json:"decided_at,omitempty"`
+	DecisionNote string     `json:"decision_note,omitempty" gorm:"type:text"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty" gorm:"index"`
+}
+
+// TableName overrides the table name
+func (Request) TableName() string {
+	return "approval_requests"
+}
+
+// Approver is the identity deciding a request
+type Approver struct {
+	UserID      uint64
+	Roles       []string
+	Permissions []string
+}
+
+// Eligible reports whether the approver matches one of the request's approver fields
+func (a Approver) Eligible(r *Request) bool {
+	if r.ApproverID != nil && *r.ApproverID == a.UserID {
+		return true
+	}
+	if r.ApproverRole != "" && contains(a.Roles, r.ApproverRole) {
+		return true
+	}
+	return r.ApproverPermission != "" && contains(a.Permissions, r.ApproverPermission)
+}
+
+func contains(values []string, v string) bool {
+	for _, have := range values {
+		if have == v {
+			return true
+		}
+	}
+	return false
+}
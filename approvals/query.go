@@ -0,0 +1,95 @@
+package approvals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Get returns one request of the tenant
+func (s *Store) Get(ctx context.Context, tenantID string, id uint64) (*Request, error) {
+	var r Request
+	err := s.cfg.DB.WithContext(ctx).Where("tenant_id = ? AND id = ?", tenantID, id).First(&r).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to load approval request: %w", err)
+	}
+	return &r, nil
+}
+
+// Pending pages through the unexpired pending requests the approver can decide, oldest first
+func (s *Store) Pending(ctx context.Context, tenantID string, approver Approver, page, limit int) ([]Request, int64, error) {
+	q := s.pendingFor(ctx, tenantID, approver)
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count pending approvals: %w", err)
+	}
+	var items []Request
+	if err := q.Order("created_at, id").Offset((page - 1) * limit).Limit(limit).Find(&items).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list pending approvals: %w", err)
+	}
+	return items, total, nil
+}
+
+// CountPending counts the requests waiting for the approver, e.g. for an inbox badge
+func (s *Store) CountPending(ctx context.Context, tenantID string, approver Approver) (int64, error) {
+	var total int64
+	if err := s.pendingFor(ctx, tenantID, approver).Count(&total).Error; err != nil {
+		return 0, fmt.Errorf("failed to count pending approvals: %w", err)
+	}
+	return total, nil
+}
+
+// Mine pages through the requests made by userID, newest first; an empty status matches all
+func (s *Store) Mine(ctx context.Context, tenantID string, userID uint64, status Status, page, limit int) ([]Request, int64, error) {
+	q := s.cfg.DB.WithContext(ctx).Model(&Request{}).Where("tenant_id = ? AND requested_by = ?", tenantID, userID)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count approval requests: %w", err)
+	}
+	var items []Request
+	if err := q.Order("created_at DESC, id DESC").Offset((page - 1) * limit).Limit(limit).Find(&items).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list approval requests: %w", err)
+	}
+	return items, total, nil
+}
+
+// ForResource returns every request made for a resource, newest first
+func (s *Store) ForResource(ctx context.Context, tenantID, resourceType, resourceID string) ([]Request, error) {
+	var items []Request
+	err := s.cfg.DB.WithContext(ctx).
+		Where("tenant_id = ? AND resource_type = ? AND resource_id = ?", tenantID, resourceType, resourceID).
+		Order("created_at DESC, id DESC").
+		Find(&items).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approval requests: %w", err)
+	}
+	return items, nil
+}
+
+// pendingFor selects the requests the approver may decide now
+func (s *Store) pendingFor(ctx context.Context, tenantID string, approver Approver) *gorm.DB {
+	match := s.cfg.DB.Where("approver_id = ?", approver.UserID)
+	if len(approver.Roles) > 0 {
+		match = match.Or("approver_role IN ?", approver.Roles)
+	}
+	if len(approver.Permissions) > 0 {
+		match = match.Or("approver_permission IN ?", approver.Permissions)
+	}
+
+	q := s.cfg.DB.WithContext(ctx).Model(&Request{}).
+		Where("tenant_id = ? AND status = ?", tenantID, StatusPending).
+		Where("expires_at IS NULL OR expires_at > ?", s.now()).
+		Where(match)
+	if !s.cfg.AllowSelfApproval {
+		q = q.Where("requested_by <> ?", approver.UserID)
+	}
+	return q
+}
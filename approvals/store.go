@@ -0,0 +1,330 @@
+package approvals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/audit"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/notify"
+	"github.com/Masharah-Advisory/common/worker"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Errors returned by the store
+var (
+	ErrNotFound = apperror.New("approval_not_found", apperror.KindNotFound, "approval request not found").
+			WithKey("approvals.not_found")
+	ErrNoApprover = apperror.New("approval_no_approver", apperror.KindValidation, "an approver user, role or permission is required").
+			WithKey("approvals.no_approver")
+	ErrAlreadyPending = apperror.New("approval_already_pending", apperror.KindConflict, "an approval for this action is already pending").
+				WithKey("approvals.already_pending")
+	ErrAlreadyDecided = apperror.New("approval_already_decided", apperror.KindConflict, "the approval request is no longer pending").
+				WithKey("approvals.already_decided")
+	ErrExpired = apperror.New("approval_expired", apperror.KindConflict, "the approval request has expired").
+			WithKey("approvals.expired")
+	ErrNotAllowed = apperror.New("approval_not_allowed", apperror.KindForbidden, "you are not an approver of this request").
+			WithKey("approvals.not_allowed")
+	ErrSelfApproval = apperror.New("approval_self", apperror.KindForbidden, "you cannot decide your own request").
+			WithKey("approvals.self_approval")
+	ErrNoteRequired = apperror.New("approval_note_required", apperror.KindValidation, "a note is required to reject a request").
+			WithKey("approvals.note_required")
+)
+
+// Audit actions recorded on the requested resource
+const (
+	ActionRequested audit.Action = "approval.requested"
+	ActionApproved  audit.Action = "approval.approved"
+	ActionRejected  audit.Action = "approval.rejected"
+	ActionCancelled audit.Action = "approval.cancelled"
+)
+
+// Notification events; register them on the dispatcher. Their data carries approval_id, action,
+// resource_type, resource_id, title and, for decisions, note.
+const (
+	EventRequested = "approval.requested"
+	EventApproved  = "approval.approved"
+	EventRejected  = "approval.rejected"
+)
+
+// ExpireJob is the worker job registered when Config.Worker is set
+const ExpireJob = "approvals.expire"
+
+// Input creates an approval request
+type Input struct {
+	Action             string                 `json:"action" binding:"required,max=128"`
+	ResourceType       string                 `json:"resource_type" binding:"required,max=64"`
+	ResourceID         string                 `json:"resource_id" binding:"required,max=128"`
+	Title              string                 `json:"title" binding:"required,max=255"`
+	Reason             string                 `json:"reason" binding:"max=5000"`
+	Payload            map[string]interface{} `json:"payload"`
+	ApproverID         *uint64                `json:"approver_id"`
+	ApproverRole       string                 `json:"approver_role" binding:"max=128"`
+	ApproverPermission string                 `json:"approver_permission" binding:"max=128"`
+	ExpiresAt          *time.Time             `json:"expires_at"`
+}
+
+// DecisionHook carries out a decided request inside the decision's transaction; returning an
+// error rolls the decision back. Check r.Status to tell approvals from rejections.
+type DecisionHook func(ctx context.Context, tx *gorm.DB, r *Request) error
+
+// Config configures a Store
+type Config struct {
+	DB *gorm.DB
+	// Notifier sends EventRequested to the approvers and the decision events to the requester
+	// (optional)
+	Notifier *notify.Dispatcher
+	// Approvers returns the users to notify of a new request, e.g. the holders of its role
+	// looked up in the auth service; by default only ApproverID is notified
+	Approvers func(ctx context.Context, r *Request) ([]uint64, error)
+	// AllowSelfApproval lets requesters decide their own requests
+	AllowSelfApproval bool
+	// Worker expires requests past ExpiresAt every ExpireInterval (optional)
+	Worker         *worker.Manager
+	ExpireInterval time.Duration // defaults to 15m
+}
+
+// Store manages approval requests
+type Store struct {
+	cfg *Config
+	log *zap.Logger
+	now func() time.Time
+
+	mu    sync.RWMutex
+	hooks map[string][]DecisionHook
+}
+
+// NewStore creates a store; migrate Request first
+func NewStore(cfg *Config) *Store {
+	if cfg.ExpireInterval <= 0 {
+		cfg.ExpireInterval = 15 * time.Minute
+	}
+	s := &Store{cfg: cfg, log: logger.Module("approvals"), now: time.Now, hooks: make(map[string][]DecisionHook)}
+	if cfg.Worker != nil {
+		cfg.Worker.Every(ExpireJob, cfg.ExpireInterval, func(ctx context.Context) error {
+			_, err := s.Expire(ctx)
+			return err
+		}, worker.JobOptions{Timeout: 5 * time.Minute})
+	}
+	return s
+}
+
+// OnDecision registers a hook run when a request for action is approved or rejected
+func (s *Store) OnDecision(action string, hook DecisionHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks[action] = append(s.hooks[action], hook)
+}
+
+// Create opens a request by requestedBy; only one request per action and resource can be pending
+func (s *Store) Create(ctx context.Context, tenantID string, requestedBy uint64, in Input) (*Request, error) {
+	if in.ApproverID == nil && in.ApproverRole == "" && in.ApproverPermission == "" {
+		return nil, ErrNoApprover
+	}
+	r := &Request{
+		TenantID:           tenantID,
+		Action:             in.Action,
+		ResourceType:       in.ResourceType,
+		ResourceID:         in.ResourceID,
+		Title:              in.Title,
+		Reason:             in.Reason,
+		Payload:            in.Payload,
+		RequestedBy:        requestedBy,
+		ApproverID:         in.ApproverID,
+		ApproverRole:       in.ApproverRole,
+		ApproverPermission: in.ApproverPermission,
+		Status:             StatusPending,
+		ExpiresAt:          in.ExpiresAt,
+	}
+	r.CreatedBy = &requestedBy
+
+	err := s.cfg.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var pending int64
+		err := tx.Model(&Request{}).
+			Where("tenant_id = ? AND action = ? AND resource_type = ? AND resource_id = ? AND status = ?",
+				tenantID, in.Action, in.ResourceType, in.ResourceID, StatusPending).
+			Count(&pending).Error
+		if err != nil {
+			return fmt.Errorf("failed to check pending approvals: %w", err)
+		}
+		if pending > 0 {
+			return ErrAlreadyPending
+		}
+		if err := tx.Create(r).Error; err != nil {
+			return fmt.Errorf("failed to create approval request: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, ActionRequested, r)
+	s.notifyApprovers(ctx, r)
+	return r, nil
+}
+
+// Approve approves a pending request as approver
+func (s *Store) Approve(ctx context.Context, tenantID string, id uint64, approver Approver, note string) (*Request, error) {
+	return s.decide(ctx, tenantID, id, approver, StatusApproved, note)
+}
+
+// Reject rejects a pending request as approver; a note explaining why is required
+func (s *Store) Reject(ctx context.Context, tenantID string, id uint64, approver Approver, note string) (*Request, error) {
+	if note == "" {
+		return nil, ErrNoteRequired
+	}
+	return s.decide(ctx, tenantID, id, approver, StatusRejected, note)
+}
+
+func (s *Store) decide(ctx context.Context, tenantID string, id uint64, approver Approver, status Status, note string) (*Request, error) {
+	var r Request
+	err := s.cfg.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("tenant_id = ? AND id = ?", tenantID, id).
+			First(&r).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return fmt.Errorf("failed to load approval request: %w", err)
+		}
+		now := s.now()
+		switch {
+		case r.Status != StatusPending:
+			return ErrAlreadyDecided
+		case r.ExpiresAt != nil && !now.Before(*r.ExpiresAt):
+			return ErrExpired
+		case !approver.Eligible(&r):
+			return ErrNotAllowed
+		case r.RequestedBy == approver.UserID && !s.cfg.AllowSelfApproval:
+			return ErrSelfApproval
+		}
+
+		r.Status = status
+		r.DecidedBy = &approver.UserID
+		r.DecidedAt = &now
+		r.DecisionNote = note
+		r.UpdatedBy = &approver.UserID
+		if err := tx.Save(&r).Error; err != nil {
+			return fmt.Errorf("failed to save approval decision: %w", err)
+		}
+
+		s.mu.RLock()
+		hooks := s.hooks[r.Action]
+		s.mu.RUnlock()
+		for _, hook := range hooks {
+			if err := hook(ctx, tx, &r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	action, event := ActionApproved, EventApproved
+	if status == StatusRejected {
+		action, event = ActionRejected, EventRejected
+	}
+	s.audit(ctx, action, &r, audit.WithMetadata("note", note))
+	s.notify(ctx, []uint64{r.RequestedBy}, event, &r)
+	return &r, nil
+}
+
+// Cancel withdraws a pending request; only its requester can cancel it
+func (s *Store) Cancel(ctx context.Context, tenantID string, id, userID uint64) (*Request, error) {
+	r, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.RequestedBy != userID {
+		return nil, ErrNotAllowed
+	}
+	res := s.cfg.DB.WithContext(ctx).Model(&Request{}).
+		Where("id = ? AND status = ?", id, StatusPending).
+		Updates(map[string]interface{}{"status": StatusCancelled, "updated_by": userID})
+	if res.Error != nil {
+		return nil, fmt.Errorf("failed to cancel approval request: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrAlreadyDecided
+	}
+	r.Status = StatusCancelled
+	s.audit(ctx, ActionCancelled, r)
+	return r, nil
+}
+
+// Expire marks pending requests past their expiry as expired and returns how many changed
+func (s *Store) Expire(ctx context.Context) (int64, error) {
+	res := s.cfg.DB.WithContext(ctx).Model(&Request{}).
+		Where("status = ? AND expires_at <= ?", StatusPending, s.now()).
+		Update("status", StatusExpired)
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to expire approval requests: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// audit records action on the requested resource
+func (s *Store) audit(ctx context.Context, action audit.Action, r *Request, opts ...audit.Option) {
+	opts = append(opts,
+		audit.WithMetadata("approval_id", r.ID),
+		audit.WithMetadata("action", r.Action),
+	)
+	if err := audit.Record(ctx, action, audit.Resource{Type: r.ResourceType, ID: r.ResourceID}, nil, opts...); err != nil {
+		s.log.Warn("failed to audit approval", zap.Uint64("approval_id", r.ID), zap.Error(err))
+	}
+}
+
+// notifyApprovers tells the approvers of a new request
+func (s *Store) notifyApprovers(ctx context.Context, r *Request) {
+	var userIDs []uint64
+	if s.cfg.Approvers != nil {
+		ids, err := s.cfg.Approvers(ctx, r)
+		if err != nil {
+			s.log.Warn("failed to resolve approvers", zap.Uint64("approval_id", r.ID), zap.Error(err))
+		}
+		userIDs = ids
+	}
+	if r.ApproverID != nil {
+		userIDs = append(userIDs, *r.ApproverID)
+	}
+
+	seen := make(map[uint64]bool, len(userIDs))
+	recipients := userIDs[:0]
+	for _, id := range userIDs {
+		if !seen[id] && (id != r.RequestedBy || s.cfg.AllowSelfApproval) {
+			seen[id] = true
+			recipients = append(recipients, id)
+		}
+	}
+	s.notify(ctx, recipients, EventRequested, r)
+}
+
+func (s *Store) notify(ctx context.Context, userIDs []uint64, event string, r *Request) {
+	if s.cfg.Notifier == nil || len(userIDs) == 0 {
+		return
+	}
+	data := map[string]interface{}{
+		"approval_id":   strconv.FormatUint(r.ID, 10),
+		"action":        r.Action,
+		"resource_type": r.ResourceType,
+		"resource_id":   r.ResourceID,
+		"title":         r.Title,
+	}
+	if r.DecisionNote != "" {
+		data["note"] = r.DecisionNote
+	}
+	if err := s.cfg.Notifier.NotifyMany(ctx, userIDs, event, data); err != nil {
+		s.log.Warn("failed to send approval notification", zap.String("event", event), zap.Uint64("approval_id", r.ID), zap.Error(err))
+	}
+}
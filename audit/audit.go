@@ -0,0 +1,237 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Sink stores audit entries
+type Sink interface {
+	Write(ctx context.Context, entries []*Entry) error
+}
+
+// Config holds the recorder settings
+type Config struct {
+	Sinks []Sink
+	// Async buffers entries and writes them in batches in the background; Record then only
+	// fails when the buffer is full
+	Async         bool
+	Buffer        int           // defaults to 1024
+	BatchSize     int           // defaults to 100
+	FlushInterval time.Duration // defaults to 1s
+}
+
+// Option adjusts a single entry
+type Option func(e *Entry)
+
+// WithMetadata attaches free-form context, e.g. the reason given for a change
+func WithMetadata(key string, value interface{}) Option {
+	return func(e *Entry) {
+		if e.Metadata == nil {
+			e.Metadata = make(map[string]interface{})
+		}
+		e.Metadata[key] = value
+	}
+}
+
+// WithActor overrides the actor taken from the context
+func WithActor(actorType string, actorID uint64) Option {
+	return func(e *Entry) {
+		e.ActorType = actorType
+		e.ActorID = &actorID
+	}
+}
+
+// WithTenant overrides the tenant taken from the context
+func WithTenant(tenantID string) Option {
+	return func(e *Entry) {
+		e.TenantID = tenantID
+	}
+}
+
+// ErrBufferFull is returned by Record in async mode when the sinks cannot keep up
+var ErrBufferFull = errors.New("audit buffer full")
+
+// Recorder builds audit entries from the request context and hands them to the sinks
+type Recorder struct {
+	cfg    *Config
+	log    *zap.Logger
+	queue  chan *Entry
+	wg     sync.WaitGroup
+	closed chan struct{}
+	once   sync.Once
+}
+
+// NewRecorder creates a recorder; in async mode call Close on shutdown to flush
+func NewRecorder(cfg *Config) *Recorder {
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 1024
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+
+	r := &Recorder{cfg: cfg, log: logger.Module("audit"), closed: make(chan struct{})}
+	if cfg.Async {
+		r.queue = make(chan *Entry, cfg.Buffer)
+		r.wg.Add(1)
+		go r.run()
+	}
+	return r
+}
+
+// Record stores an audit entry for action on resource. Actor, tenant, request and trace IDs,
+// IP and user agent are taken from ctx (Gin or standard context).
+func (r *Recorder) Record(ctx context.Context, action Action, resource Resource, changes Changes, opts ...Option) error {
+	e := newEntry(ctx, action, resource, changes)
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if !r.cfg.Async {
+		return r.write(ctx, []*Entry{e})
+	}
+	select {
+	case <-r.closed:
+		return r.write(ctx, []*Entry{e})
+	default:
+	}
+	select {
+	case r.queue <- e:
+		return nil
+	default:
+		r.log.Error("audit buffer full, entry dropped", zap.String("action", string(action)), zap.String("resource", resource.Type+":"+resource.ID))
+		return ErrBufferFull
+	}
+}
+
+// Close flushes buffered entries until ctx is done
+func (r *Recorder) Close(ctx context.Context) error {
+	if !r.cfg.Async {
+		return nil
+	}
+	r.once.Do(func() { close(r.closed) })
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("audit flush timed out: %w", ctx.Err())
+	}
+}
+
+// run batches queued entries until Close
+func (r *Recorder) run() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Entry, 0, r.cfg.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		_ = r.write(context.Background(), batch)
+		batch = make([]*Entry, 0, r.cfg.BatchSize)
+	}
+
+	for {
+		select {
+		case e := <-r.queue:
+			batch = append(batch, e)
+			if len(batch) >= r.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-r.closed:
+			for {
+				select {
+				case e := <-r.queue:
+					batch = append(batch, e)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// write sends entries to every sink; a failing sink does not stop the others
+func (r *Recorder) write(ctx context.Context, entries []*Entry) error {
+	var errs []error
+	for _, sink := range r.cfg.Sinks {
+		if err := sink.Write(ctx, entries); err != nil {
+			r.log.Error("failed to write audit entries", zap.Int("count", len(entries)), zap.Error(err))
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// newEntry fills an entry from the context values set by the standard middleware
+func newEntry(ctx context.Context, action Action, resource Resource, changes Changes) *Entry {
+	e := &Entry{
+		CreatedAt:    time.Now().UTC(),
+		ActorType:    ActorSystem,
+		Service:      utils.ServiceID,
+		Action:       action,
+		ResourceType: resource.Type,
+		ResourceID:   resource.ID,
+		Changes:      changes,
+	}
+	if ctx == nil {
+		return e
+	}
+
+	if c, ok := ctx.(*gin.Context); ok {
+		e.IP = c.ClientIP()
+		e.UserAgent = c.Request.UserAgent()
+		if c.GetHeader(utils.XServiceIDHeader) != "" && c.GetHeader(utils.XUserIDHeader) == "" {
+			e.ActorType = ActorService
+			e.Metadata = map[string]interface{}{"caller_service": c.GetHeader(utils.XServiceIDHeader)}
+		}
+	}
+
+	if id, ok := ctxutil.UserID(ctx); ok {
+		e.ActorID = &id
+		e.ActorType = ActorUser
+	}
+	e.TenantID = ctxutil.TenantID(ctx)
+	e.RequestID = ctxutil.RequestID(ctx)
+	e.TraceID = ctxutil.TraceID(ctx)
+	return e
+}
+
+var defaultRecorder *Recorder
+
+// SetDefault installs the recorder used by the package-level Record
+func SetDefault(r *Recorder) {
+	defaultRecorder = r
+}
+
+// Record records with the default recorder; it is a no-op until SetDefault is called
+func Record(ctx context.Context, action Action, resource Resource, changes Changes, opts ...Option) error {
+	if defaultRecorder == nil {
+		return nil
+	}
+	return defaultRecorder.Record(ctx, action, resource, changes, opts...)
+}
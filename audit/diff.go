@@ -0,0 +1,115 @@
+package audit
+
+import (
+	"reflect"
+	"strings"
+)
+
+// redacted replaces the values of sensitive fields in a diff
+const redacted = "[REDACTED]"
+
+// Diff compares two values of the same struct type field by field (using JSON names) and returns
+// what changed. Fields tagged `audit:"-"` are skipped and `audit:"redact"` records that the field
+// changed without its values. Pass nil as before for creations and nil as after for deletions.
+func Diff(before, after interface{}) Changes {
+	bv, av := indirect(reflect.ValueOf(before)), indirect(reflect.ValueOf(after))
+	if !bv.IsValid() && !av.IsValid() {
+		return nil
+	}
+	t := av.Type()
+	if !av.IsValid() {
+		t = bv.Type()
+	}
+	if bv.IsValid() && av.IsValid() && bv.Type() != av.Type() {
+		return nil
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	changes := make(Changes)
+	collect(changes, t, bv, av)
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+func collect(changes Changes, t reflect.Type, bv, av reflect.Value) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("audit")
+		if tag == "-" {
+			continue
+		}
+
+		var bf, af reflect.Value
+		if bv.IsValid() {
+			bf = bv.Field(i)
+		}
+		if av.IsValid() {
+			af = av.Field(i)
+		}
+
+		// Flatten embedded structs such as model.Base
+		if f.Anonymous && indirectType(f.Type).Kind() == reflect.Struct && f.Tag.Get("json") == "" {
+			collect(changes, indirectType(f.Type), indirect(bf), indirect(af))
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		// On creation or deletion only record fields that hold a value
+		if (!bf.IsValid() && af.IsZero()) || (!af.IsValid() && bf.IsZero()) {
+			continue
+		}
+
+		from, to := value(bf), value(af)
+		if reflect.DeepEqual(from, to) {
+			continue
+		}
+		if tag == "redact" {
+			from, to = redacted, redacted
+		}
+		changes[name] = Change{From: from, To: to}
+	}
+}
+
+func value(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface()
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
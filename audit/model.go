@@ -0,0 +1,65 @@
+package audit
+
+import "time"
+
+// Action is what happened to a resource
+type Action string
+
+// Common actions; services may define their own, e.g. Action("case.assigned")
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+	ActionView   Action = "view"
+	ActionExport Action = "export"
+	ActionLogin  Action = "login"
+	ActionLogout Action = "logout"
+	ActionGrant  Action = "grant"
+	ActionRevoke Action = "revoke"
+)
+
+// Actor types
+const (
+	ActorUser    = "user"
+	ActorService = "service"
+	ActorSystem  = "system"
+)
+
+// Resource identifies the object an action applied to
+type Resource struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// Change is the before and after value of one field
+type Change struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// Changes maps field names to their change
+type Changes map[string]Change
+
+// Entry is an immutable audit record
+type Entry struct {
+	ID           uint64                 `json:"id" gorm:"primaryKey;autoIncrement"`
+	CreatedAt    time.Time              `json:"created_at" gorm:"index;not null"`
+	TenantID     string                 `json:"tenant_id,omitempty" gorm:"index:idx_audit_tenant_created;size:64"`
+	ActorID      *uint64                `json:"actor_id,omitempty" gorm:"index"`
+	ActorType    string                 `json:"actor_type" gorm:"size:16;not null"`
+	Service      string                 `json:"service,omitempty" gorm:"size:64"`
+	Action       Action                 `json:"action" gorm:"index;size:64;not null"`
+	ResourceType string                 `json:"resource_type" gorm:"index:idx_audit_resource;size:64;not null"`
+	ResourceID   string                 `json:"resource_id" gorm:"index:idx_audit_resource;size:128"`
+	Changes      Changes                `json:"changes,omitempty" gorm:"serializer:json"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty" gorm:"serializer:json"`
+	RequestID    string                 `json:"request_id,omitempty" gorm:"size:128"`
+	TraceID      string                 `json:"trace_id,omitempty" gorm:"size:64"`
+	IP           string                 `json:"ip,omitempty" gorm:"size:64"`
+	UserAgent    string                 `json:"user_agent,omitempty" gorm:"size:512"`
+}
+
+// TableName overrides the table name
+func (Entry) TableName() string {
+	return "audit_logs"
+}
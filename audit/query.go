@@ -0,0 +1,141 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/dto"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Query filters audit entries; zero values are ignored
+type Query struct {
+	TenantID     string    `form:"-"`
+	ActorID      uint64    `form:"actor_id"`
+	Action       Action    `form:"action"`
+	ResourceType string    `form:"resource_type"`
+	ResourceID   string    `form:"resource_id"`
+	From         time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To           time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page         int       `form:"page"`
+	Limit        int       `form:"limit"`
+}
+
+// Store reads audit entries from the audit_logs table
+type Store struct {
+	db *gorm.DB
+}
+
+// NewStore creates an audit store
+func NewStore(db *gorm.DB) *Store {
+	return &Store{db: db}
+}
+
+// List returns a page of entries matching q, newest first
+func (s *Store) List(ctx context.Context, q Query) ([]Entry, int64, error) {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Limit < 1 || q.Limit > 100 {
+		q.Limit = 20
+	}
+
+	tx := s.db.WithContext(ctx).Model(&Entry{})
+	if q.TenantID != "" {
+		tx = tx.Where("tenant_id = ?", q.TenantID)
+	}
+	if q.ActorID != 0 {
+		tx = tx.Where("actor_id = ?", q.ActorID)
+	}
+	if q.Action != "" {
+		tx = tx.Where("action = ?", q.Action)
+	}
+	if q.ResourceType != "" {
+		tx = tx.Where("resource_type = ?", q.ResourceType)
+	}
+	if q.ResourceID != "" {
+		tx = tx.Where("resource_id = ?", q.ResourceID)
+	}
+	if !q.From.IsZero() {
+		tx = tx.Where("created_at >= ?", q.From)
+	}
+	if !q.To.IsZero() {
+		tx = tx.Where("created_at < ?", q.To)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+
+	var entries []Entry
+	if err := tx.Order("id DESC").Offset((q.Page - 1) * q.Limit).Limit(q.Limit).Find(&entries).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	return entries, total, nil
+}
+
+// History returns the entries of one resource, newest first, for "activity" tabs
+func (s *Store) History(ctx context.Context, resource Resource, page, limit int) ([]Entry, int64, error) {
+	return s.List(ctx, Query{ResourceType: resource.Type, ResourceID: resource.ID, Page: page, Limit: limit})
+}
+
+// Handler serves a paginated activity log filtered by the query parameters and scoped to the
+// caller's tenant; protect it with RequirePermission
+func (s *Store) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var q Query
+		if err := c.ShouldBindQuery(&q); err != nil {
+			response.BadRequest(c, i18n.T(c, "audit.invalid_query"), response.ProcessBindingError(c, err))
+			return
+		}
+		q.TenantID = ctxutil.TenantID(c)
+		if q.Page < 1 {
+			q.Page = 1
+		}
+		if q.Limit < 1 || q.Limit > 100 {
+			q.Limit = 20
+		}
+
+		entries, total, err := s.List(c.Request.Context(), q)
+		if err != nil {
+			response.InternalErrorWithCause(c, err)
+			return
+		}
+		response.OK(c, dto.BuildPaginatedResponse(entries, total, q.Page, q.Limit))
+	}
+}
+
+// ResourceHandler serves the history of the resource identified by the :id path parameter
+func (s *Store) ResourceHandler(resourceType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if page < 1 {
+			page = 1
+		}
+		if limit < 1 || limit > 100 {
+			limit = 20
+		}
+
+		q := Query{
+			TenantID:     ctxutil.TenantID(c),
+			ResourceType: resourceType,
+			ResourceID:   c.Param("id"),
+			Page:         page,
+			Limit:        limit,
+		}
+		entries, total, err := s.List(c.Request.Context(), q)
+		if err != nil {
+			response.InternalErrorWithCause(c, err)
+			return
+		}
+		response.OK(c, dto.BuildPaginatedResponse(entries, total, page, limit))
+	}
+}
@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/worker"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RetentionPolicy keeps entries of Action (all actions when empty) for Keep
+type RetentionPolicy struct {
+	Action Action
+	Keep   time.Duration
+}
+
+// purgeBatch bounds each DELETE so retention never holds long locks on the table
+const purgeBatch = 5000
+
+// Purge deletes entries older than the policies allow and returns how many were removed.
+// Action-specific policies apply first; the catch-all policy skips actions that have their own.
+func Purge(ctx context.Context, db *gorm.DB, policies ...RetentionPolicy) (int64, error) {
+	var specific []Action
+	for _, p := range policies {
+		if p.Action != "" {
+			specific = append(specific, p.Action)
+		}
+	}
+
+	var total int64
+	now := time.Now().UTC()
+	for _, p := range policies {
+		if p.Keep <= 0 {
+			continue
+		}
+		cutoff := now.Add(-p.Keep)
+		for {
+			sub := db.WithContext(ctx).Model(&Entry{}).Select("id").Where("created_at < ?", cutoff)
+			if p.Action != "" {
+				sub = sub.Where("action = ?", p.Action)
+			} else if len(specific) > 0 {
+				sub = sub.Where("action NOT IN ?", specific)
+			}
+			res := db.WithContext(ctx).Where("id IN (?)", sub.Limit(purgeBatch)).Delete(&Entry{})
+			if res.Error != nil {
+				return total, fmt.Errorf("failed to purge audit entries: %w", res.Error)
+			}
+			total += res.RowsAffected
+			if res.RowsAffected < purgeBatch || ctx.Err() != nil {
+				break
+			}
+		}
+	}
+	return total, nil
+}
+
+// ScheduleRetention runs Purge daily on the worker manager
+func ScheduleRetention(m *worker.Manager, db *gorm.DB, policies ...RetentionPolicy) {
+	m.Every("audit.retention", 24*time.Hour, func(ctx context.Context) error {
+		n, err := Purge(ctx, db, policies...)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			logger.Module("audit").Info("purged audit entries", zap.Int64("count", n))
+		}
+		return nil
+	}, worker.JobOptions{Timeout: time.Hour})
+}
@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masharah-Advisory/common/events"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// DBSink writes entries to the audit_logs table; run db.Migrate with &Entry{} first
+type DBSink struct {
+	DB *gorm.DB
+}
+
+// Write implements Sink
+func (s *DBSink) Write(ctx context.Context, entries []*Entry) error {
+	if err := s.DB.WithContext(ctx).CreateInBatches(entries, 100).Error; err != nil {
+		return fmt.Errorf("failed to store audit entries: %w", err)
+	}
+	return nil
+}
+
+// EventType is the type of the events published by EventSink
+const EventType = "audit.recorded"
+
+// EventSink publishes entries to a broker (Kafka, NATS, RabbitMQ) for a central audit service
+// or SIEM; the partition key is the tenant so per-tenant order is kept
+type EventSink struct {
+	Publisher events.Publisher
+	Topic     string // defaults to "audit"
+}
+
+// Write implements Sink
+func (s *EventSink) Write(ctx context.Context, entries []*Entry) error {
+	topic := s.Topic
+	if topic == "" {
+		topic = "audit"
+	}
+
+	batch := make([]*events.Event, 0, len(entries))
+	for _, e := range entries {
+		event, err := events.NewEvent(ctx, EventType, e)
+		if err != nil {
+			return err
+		}
+		event.TenantID = e.TenantID
+		event.Key = e.TenantID
+		batch = append(batch, event)
+	}
+	if err := s.Publisher.Publish(ctx, topic, batch...); err != nil {
+		return fmt.Errorf("failed to publish audit entries: %w", err)
+	}
+	return nil
+}
+
+// LogSink writes entries to the structured log, useful in development or as a last-resort copy
+type LogSink struct{}
+
+// Write implements Sink
+func (LogSink) Write(ctx context.Context, entries []*Entry) error {
+	log := logger.Module("audit")
+	for _, e := range entries {
+		log.Info("audit",
+			zap.String("action", string(e.Action)),
+			zap.String("resource_type", e.ResourceType),
+			zap.String("resource_id", e.ResourceID),
+			zap.String("actor_type", e.ActorType),
+			zap.Uint64p("actor_id", e.ActorID),
+			zap.String("tenant_id", e.TenantID),
+			zap.Any("changes", e.Changes),
+		)
+	}
+	return nil
+}
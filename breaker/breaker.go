@@ -0,0 +1,274 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/retry"
+)
+
+// State is the state of a circuit breaker
+type State int
+
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+// String returns the state name used in logs and metrics
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+var (
+	// ErrOpen is returned while the breaker rejects calls
+	ErrOpen = apperror.New("circuit_open", apperror.KindUnavailable, "circuit breaker is open").
+		WithKey("breaker.open")
+	// ErrTooManyRequests is returned when the half-open probe quota is used up
+	ErrTooManyRequests = apperror.New("circuit_half_open", apperror.KindUnavailable, "circuit breaker is probing, try again shortly").
+				WithKey("breaker.open")
+)
+
+// Counts are the request counters of the current generation; they reset on every state change
+// and every Interval while closed
+type Counts struct {
+	Requests             uint32
+	TotalSuccesses       uint32
+	TotalFailures        uint32
+	ConsecutiveSuccesses uint32
+	ConsecutiveFailures  uint32
+}
+
+// Settings configure a breaker; zero values get defaults
+type Settings struct {
+	Name string
+	// MaxRequests is how many probe calls are let through while half-open, defaults to 1
+	MaxRequests uint32
+	// Interval resets the counts while closed, 0 never resets them
+	Interval time.Duration
+	// Timeout is how long the breaker stays open before probing, defaults to 60s
+	Timeout time.Duration
+	// ReadyToTrip decides whether to open after a failure, defaults to more than 5 consecutive failures
+	ReadyToTrip func(counts Counts) bool
+	// IsSuccessful classifies call results, defaults to IsSuccessful
+	IsSuccessful func(err error) bool
+	// OnStateChange is called on every transition, after the metrics are updated
+	OnStateChange func(name string, from, to State)
+}
+
+// IsSuccessful is the default classification: only errors worth retrying (network errors, 5xx,
+// timeouts) count as failures, so a burst of 404s or validation errors never opens the circuit
+func IsSuccessful(err error) bool {
+	return err == nil || !retry.IsRetryable(err) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// Breaker is a circuit breaker in the style of sony/gobreaker
+type Breaker struct {
+	name     string
+	settings Settings
+
+	mu         sync.Mutex
+	state      State
+	generation uint64
+	counts     Counts
+	expiry     time.Time
+}
+
+// New creates a breaker; most callers should use Get so breakers are shared by name
+func New(st Settings) *Breaker {
+	if st.MaxRequests == 0 {
+		st.MaxRequests = 1
+	}
+	if st.Timeout <= 0 {
+		st.Timeout = 60 * time.Second
+	}
+	if st.ReadyToTrip == nil {
+		st.ReadyToTrip = func(counts Counts) bool { return counts.ConsecutiveFailures > 5 }
+	}
+	if st.IsSuccessful == nil {
+		st.IsSuccessful = IsSuccessful
+	}
+
+	b := &Breaker{name: st.Name, settings: st}
+	b.toNewGeneration(time.Now())
+	stateGauge.WithLabelValues(b.name).Set(float64(StateClosed))
+	return b
+}
+
+// Name returns the breaker name
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State returns the current state
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, _ := b.currentState(time.Now())
+	return state
+}
+
+// Counts returns the counters of the current generation
+func (b *Breaker) Counts() Counts {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.counts
+}
+
+// Do runs fn unless the circuit is open and records its result
+func (b *Breaker) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			done(false)
+			panic(r)
+		}
+	}()
+
+	err = fn(ctx)
+	done(b.settings.IsSuccessful(err))
+	return err
+}
+
+// Call is Do for functions that return a value
+func Call[T any](ctx context.Context, b *Breaker, fn func(ctx context.Context) (T, error)) (T, error) {
+	var result T
+	err := b.Do(ctx, func(ctx context.Context) error {
+		v, err := fn(ctx)
+		result = v
+		return err
+	})
+	return result, err
+}
+
+// Allow is the two-step form of Do for callers that report the outcome themselves (e.g. an
+// http.RoundTripper); done must be called exactly once
+func (b *Breaker) Allow() (done func(success bool), err error) {
+	generation, err := b.beforeRequest()
+	if err != nil {
+		requestsTotal.WithLabelValues(b.name, "rejected").Inc()
+		return nil, err
+	}
+	return func(success bool) {
+		b.afterRequest(generation, success)
+	}, nil
+}
+
+func (b *Breaker) beforeRequest() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	state, generation := b.currentState(now)
+	if state == StateOpen {
+		return generation, ErrOpen
+	}
+	if state == StateHalfOpen && b.counts.Requests >= b.settings.MaxRequests {
+		return generation, ErrTooManyRequests
+	}
+	b.counts.Requests++
+	return generation, nil
+}
+
+func (b *Breaker) afterRequest(before uint64, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	state, generation := b.currentState(now)
+	if generation != before {
+		// The result belongs to a previous state, e.g. a slow call finishing after the trip
+		return
+	}
+
+	if success {
+		requestsTotal.WithLabelValues(b.name, "success").Inc()
+		b.counts.TotalSuccesses++
+		b.counts.ConsecutiveSuccesses++
+		b.counts.ConsecutiveFailures = 0
+		if state == StateHalfOpen && b.counts.ConsecutiveSuccesses >= b.settings.MaxRequests {
+			b.setState(StateClosed, now)
+		}
+		return
+	}
+
+	requestsTotal.WithLabelValues(b.name, "failure").Inc()
+	b.counts.TotalFailures++
+	b.counts.ConsecutiveFailures++
+	b.counts.ConsecutiveSuccesses = 0
+	switch state {
+	case StateClosed:
+		if b.settings.ReadyToTrip(b.counts) {
+			b.setState(StateOpen, now)
+		}
+	case StateHalfOpen:
+		b.setState(StateOpen, now)
+	}
+}
+
+// currentState advances time-based transitions (open to half-open, interval resets)
+func (b *Breaker) currentState(now time.Time) (State, uint64) {
+	switch b.state {
+	case StateClosed:
+		if !b.expiry.IsZero() && b.expiry.Before(now) {
+			b.toNewGeneration(now)
+		}
+	case StateOpen:
+		if b.expiry.Before(now) {
+			b.setState(StateHalfOpen, now)
+		}
+	}
+	return b.state, b.generation
+}
+
+func (b *Breaker) setState(state State, now time.Time) {
+	if b.state == state {
+		return
+	}
+	prev := b.state
+	b.state = state
+	b.toNewGeneration(now)
+
+	stateGauge.WithLabelValues(b.name).Set(float64(state))
+	transitionsTotal.WithLabelValues(b.name, prev.String(), state.String()).Inc()
+	if b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(b.name, prev, state)
+	}
+}
+
+func (b *Breaker) toNewGeneration(now time.Time) {
+	b.generation++
+	b.counts = Counts{}
+
+	var zero time.Time
+	switch b.state {
+	case StateClosed:
+		if b.settings.Interval == 0 {
+			b.expiry = zero
+		} else {
+			b.expiry = now.Add(b.settings.Interval)
+		}
+	case StateOpen:
+		b.expiry = now.Add(b.settings.Timeout)
+	default:
+		b.expiry = zero
+	}
+}
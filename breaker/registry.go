@@ -0,0 +1,79 @@
+package breaker
+
+import (
+	"sync"
+
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"go.uber.org/zap"
+)
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]*Breaker)
+	defaults   = Settings{OnStateChange: logStateChange}
+)
+
+// SetDefaults changes the settings used by Get for breakers that were not configured with
+// Configure; it does not affect breakers that already exist
+func SetDefaults(st Settings) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if st.OnStateChange == nil {
+		st.OnStateChange = logStateChange
+	}
+	defaults = st
+}
+
+// Configure creates or replaces the named breaker with specific settings
+func Configure(st Settings) *Breaker {
+	if st.OnStateChange == nil {
+		st.OnStateChange = logStateChange
+	}
+	b := New(st)
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[st.Name] = b
+	return b
+}
+
+// Get returns the shared breaker for name, creating it with the default settings
+func Get(name string) *Breaker {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if b, ok := registry[name]; ok {
+		return b
+	}
+	st := defaults
+	st.Name = name
+	b := New(st)
+	registry[name] = b
+	return b
+}
+
+// States returns the state of every registered breaker, for ops endpoints
+func States() map[string]string {
+	registryMu.Lock()
+	breakers := make([]*Breaker, 0, len(registry))
+	for _, b := range registry {
+		breakers = append(breakers, b)
+	}
+	registryMu.Unlock()
+
+	states := make(map[string]string, len(breakers))
+	for _, b := range breakers {
+		states[b.name] = b.State().String()
+	}
+	return states
+}
+
+func logStateChange(name string, from, to State) {
+	log := logger.Module("breaker")
+	fields := []zap.Field{zap.String("breaker", name), zap.String("from", from.String()), zap.String("to", to.String())}
+	if to == StateOpen {
+		log.Warn("circuit breaker opened", fields...)
+		return
+	}
+	log.Info("circuit breaker state changed", fields...)
+}
@@ -0,0 +1,144 @@
+package buildinfo
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Build metadata, populated at link time:
+//
+//	go build -ldflags "-X github.com/Masharah-Advisory/common/buildinfo.Version=1.4.0 \
+//	  -X github.com/Masharah-Advisory/common/buildinfo.Commit=$(git rev-parse --short HEAD) \
+//	  -X github.com/Masharah-Advisory/common/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+var startedAt = time.Now()
+
+// Info describes the running binary
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	GoVersion string `json:"go_version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+}
+
+// RuntimeInfo describes the current state of the process
+type RuntimeInfo struct {
+	StartedAt    time.Time `json:"started_at"`
+	Uptime       string    `json:"uptime"`
+	NumGoroutine int       `json:"num_goroutine"`
+	NumCPU       int       `json:"num_cpu"`
+	HeapAllocMB  float64   `json:"heap_alloc_mb"`
+	SysMB        float64   `json:"sys_mb"`
+	NumGC        uint32    `json:"num_gc"`
+	GOMAXPROCS   int       `json:"gomaxprocs"`
+}
+
+// Get returns the build info, falling back to VCS data embedded by the Go toolchain
+// when the ldflags were not set
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+
+	if info.Commit == "" || info.BuildTime == "" {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			for _, s := range bi.Settings {
+				switch s.Key {
+				case "vcs.revision":
+					if info.Commit == "" {
+						info.Commit = shortCommit(s.Value)
+					}
+				case "vcs.time":
+					if info.BuildTime == "" {
+						info.BuildTime = s.Value
+					}
+				}
+			}
+		}
+	}
+
+	return info
+}
+
+// Runtime returns a snapshot of process runtime statistics
+func Runtime() RuntimeInfo {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	return RuntimeInfo{
+		StartedAt:    startedAt,
+		Uptime:       time.Since(startedAt).Round(time.Second).String(),
+		NumGoroutine: runtime.NumGoroutine(),
+		NumCPU:       runtime.NumCPU(),
+		HeapAllocMB:  bytesToMB(m.HeapAlloc),
+		SysMB:        bytesToMB(m.Sys),
+		NumGC:        m.NumGC,
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+	}
+}
+
+// UserAgent builds the User-Agent used for outgoing requests, e.g. "user-service/1.4.0 (a1b2c3d)"
+func UserAgent(serviceID string) string {
+	if serviceID == "" {
+		serviceID = "masharah-service"
+	}
+
+	ua := serviceID + "/" + Version
+	if commit := Get().Commit; commit != "" {
+		ua += " (" + commit + ")"
+	}
+	return ua
+}
+
+// Handler serves the build info, e.g. router.GET("/version", buildinfo.Handler())
+func Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data":    Get(),
+			"message": "Success",
+		})
+	}
+}
+
+// RuntimeHandler serves the build info together with runtime statistics
+func RuntimeHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data": gin.H{
+				"build":   Get(),
+				"runtime": Runtime(),
+			},
+			"message": "Success",
+		})
+	}
+}
+
+// shortCommit trims a full VCS revision to the conventional 7 characters
+func shortCommit(rev string) string {
+	if len(rev) > 7 {
+		return rev[:7]
+	}
+	return rev
+}
+
+func bytesToMB(b uint64) float64 {
+	return float64(b) / 1024 / 1024
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// invalidation is the message exchanged on the bus
+type invalidation struct {
+	Origin  string   `json:"origin"`
+	Cache   string   `json:"cache"`
+	Keys    []string `json:"keys,omitempty"`
+	Pattern string   `json:"pattern,omitempty"`
+}
+
+// localInvalidator is implemented by every Cache[T]
+type localInvalidator interface {
+	invalidateLocal(keys []string, pattern string)
+}
+
+// Bus propagates invalidations between replicas over Redis pub/sub, so the in-memory tier of
+// every replica drops entries deleted anywhere. Share one Bus between all caches of a service.
+type Bus struct {
+	rdb     *redis.Client
+	channel string
+	origin  string
+	log     *zap.Logger
+
+	mu     sync.RWMutex
+	caches map[string]localInvalidator
+}
+
+// NewBus creates an invalidation bus; channel defaults to "cache:invalidate". Call Run to
+// receive invalidations from other replicas.
+func NewBus(rdb *redis.Client, channel string) *Bus {
+	if channel == "" {
+		channel = "cache:invalidate"
+	}
+	return &Bus{
+		rdb:     rdb,
+		channel: channel,
+		origin:  uuid.NewString(),
+		log:     logger.Module("cache"),
+		caches:  make(map[string]localInvalidator),
+	}
+}
+
+// register attaches a cache so invalidations naming it reach its in-memory tier
+func (b *Bus) register(name string, c localInvalidator) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.caches[name] = c
+}
+
+// publish sends an invalidation to the other replicas
+func (b *Bus) publish(ctx context.Context, msg *invalidation) error {
+	msg.Origin = b.origin
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache invalidation: %w", err)
+	}
+	if err := b.rdb.Publish(ctx, b.channel, data).Err(); err != nil {
+		return fmt.Errorf("failed to publish cache invalidation: %w", err)
+	}
+	return nil
+}
+
+// Run applies invalidations published by other replicas until ctx is done
+func (b *Bus) Run(ctx context.Context) error {
+	sub := b.rdb.Subscribe(ctx, b.channel)
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", b.channel, err)
+	}
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case m, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			var msg invalidation
+			if err := json.Unmarshal([]byte(m.Payload), &msg); err != nil {
+				b.log.Warn("invalid cache invalidation", zap.Error(err))
+				continue
+			}
+			if msg.Origin == b.origin {
+				continue
+			}
+
+			b.mu.RLock()
+			c, ok := b.caches[msg.Cache]
+			b.mu.RUnlock()
+			if !ok {
+				continue
+			}
+			c.invalidateLocal(msg.Keys, msg.Pattern)
+			invalidationsTotal.WithLabelValues(msg.Cache, "bus").Inc()
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrNotFound is returned for keys cached as missing; loaders may return it, or any
+// apperror of KindNotFound, to have the miss cached for NegativeTTL
+var ErrNotFound = apperror.New("not_found", apperror.KindNotFound, "not found").WithKey("error.not_found")
+
+// Config configures a cache
+type Config struct {
+	// Name identifies the cache in metrics, Redis keys and invalidation messages, e.g. "users"
+	Name string
+	// Redis is the shared second tier; nil keeps the cache in memory only
+	Redis *redis.Client
+	// Bus propagates invalidations to the in-memory tier of other replicas (optional)
+	Bus *Bus
+
+	L1Size      int           // in-memory entries, defaults to 10000; negative disables the tier
+	L1TTL       time.Duration // in-memory lifetime, defaults to 1m and never exceeds TTL
+	TTL         time.Duration // Redis lifetime, defaults to 10m
+	NegativeTTL time.Duration // lifetime of cached misses, defaults to 30s; negative disables them
+}
+
+// Loader produces the value for a key on a miss
+type Loader[T any] func(ctx context.Context) (T, error)
+
+// Cache is a two-tier cache: an in-memory LRU in front of Redis
+type Cache[T any] struct {
+	cfg   *Config
+	l1    *lru
+	group singleflight.Group
+	log   *zap.Logger
+}
+
+// redisEntry is the stored form of a value; N marks a cached miss
+type redisEntry[T any] struct {
+	V T    `json:"v,omitempty"`
+	N bool `json:"n,omitempty"`
+}
+
+// New creates a cache and registers it on cfg.Bus
+func New[T any](cfg *Config) *Cache[T] {
+	if cfg.Name == "" {
+		panic("cache: Config.Name is required")
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 10 * time.Minute
+	}
+	if cfg.L1TTL <= 0 {
+		cfg.L1TTL = time.Minute
+	}
+	if cfg.L1TTL > cfg.TTL {
+		cfg.L1TTL = cfg.TTL
+	}
+	if cfg.NegativeTTL == 0 {
+		cfg.NegativeTTL = 30 * time.Second
+	}
+	if cfg.L1Size == 0 {
+		cfg.L1Size = 10000
+	}
+
+	c := &Cache[T]{cfg: cfg, log: logger.Module("cache").With(zap.String("cache", cfg.Name))}
+	if cfg.L1Size > 0 {
+		c.l1 = newLRU(cfg.L1Size)
+	}
+	if cfg.Bus != nil {
+		cfg.Bus.register(cfg.Name, c)
+	}
+	return c
+}
+
+// Name returns the cache name
+func (c *Cache[T]) Name() string {
+	return c.cfg.Name
+}
+
+// Get returns the cached value. Redis errors are logged and reported as misses so an
+// unavailable Redis degrades to the loader instead of failing reads.
+func (c *Cache[T]) Get(ctx context.Context, key string) (T, bool, error) {
+	value, found, negative := c.lookup(ctx, key)
+	if negative {
+		found = false
+	}
+	return value, found, nil
+}
+
+// GetOrLoad returns the cached value or calls load once per key across concurrent callers,
+// caching its result. Cached misses return ErrNotFound without calling load.
+func (c *Cache[T]) GetOrLoad(ctx context.Context, key string, load Loader[T]) (T, error) {
+	value, found, negative := c.lookup(ctx, key)
+	if negative {
+		return value, ErrNotFound
+	}
+	if found {
+		return value, nil
+	}
+
+	result, err, _ := c.group.Do(key, func() (interface{}, error) {
+		start := time.Now()
+		v, err := load(ctx)
+		loadDuration.WithLabelValues(c.cfg.Name).Observe(time.Since(start).Seconds())
+
+		switch {
+		case err == nil:
+			loadsTotal.WithLabelValues(c.cfg.Name, "success").Inc()
+			c.store(ctx, key, v, false, c.cfg.TTL)
+		case IsNotFound(err):
+			loadsTotal.WithLabelValues(c.cfg.Name, "not_found").Inc()
+			if c.cfg.NegativeTTL > 0 {
+				var zero T
+				c.store(ctx, key, zero, true, c.cfg.NegativeTTL)
+			}
+		default:
+			loadsTotal.WithLabelValues(c.cfg.Name, "error").Inc()
+		}
+		return v, err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return result.(T), nil
+}
+
+// Set stores a value for the default TTL, or ttl when given
+func (c *Cache[T]) Set(ctx context.Context, key string, value T, ttl ...time.Duration) error {
+	d := c.cfg.TTL
+	if len(ttl) > 0 && ttl[0] > 0 {
+		d = ttl[0]
+	}
+	return c.store(ctx, key, value, false, d)
+}
+
+// Delete removes keys from both tiers here and from the in-memory tier of every replica
+func (c *Cache[T]) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	c.invalidateLocal(keys, "")
+	invalidationsTotal.WithLabelValues(c.cfg.Name, "local").Inc()
+
+	if c.cfg.Redis != nil {
+		redisKeys := make([]string, len(keys))
+		for i, k := range keys {
+			redisKeys[i] = c.redisKey(k)
+		}
+		if err := c.cfg.Redis.Del(ctx, redisKeys...).Err(); err != nil {
+			return fmt.Errorf("failed to delete cache keys: %w", err)
+		}
+	}
+	return c.broadcast(ctx, &invalidation{Keys: keys})
+}
+
+// InvalidatePattern removes every key matching a glob pattern, e.g. "tenant:42:*"
+func (c *Cache[T]) InvalidatePattern(ctx context.Context, pattern string) error {
+	c.invalidateLocal(nil, pattern)
+	invalidationsTotal.WithLabelValues(c.cfg.Name, "local").Inc()
+
+	if c.cfg.Redis != nil {
+		iter := c.cfg.Redis.Scan(ctx, 0, c.redisKey(pattern), 500).Iterator()
+		batch := make([]string, 0, 500)
+		for iter.Next(ctx) {
+			batch = append(batch, iter.Val())
+			if len(batch) == cap(batch) {
+				if err := c.cfg.Redis.Del(ctx, batch...).Err(); err != nil {
+					return fmt.Errorf("failed to delete cache keys: %w", err)
+				}
+				batch = batch[:0]
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return fmt.Errorf("failed to scan cache keys: %w", err)
+		}
+		if len(batch) > 0 {
+			if err := c.cfg.Redis.Del(ctx, batch...).Err(); err != nil {
+				return fmt.Errorf("failed to delete cache keys: %w", err)
+			}
+		}
+	}
+	return c.broadcast(ctx, &invalidation{Pattern: pattern})
+}
+
+// Clear removes every key of the cache
+func (c *Cache[T]) Clear(ctx context.Context) error {
+	return c.InvalidatePattern(ctx, "*")
+}
+
+// IsNotFound reports whether err means the key has no value
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound) || apperror.IsKind(err, apperror.KindNotFound)
+}
+
+// lookup checks the in-memory tier, then Redis, promoting Redis hits to memory
+func (c *Cache[T]) lookup(ctx context.Context, key string) (value T, found, negative bool) {
+	if c.l1 != nil {
+		if entry, ok := c.l1.get(key); ok {
+			if entry.negative {
+				requestsTotal.WithLabelValues(c.cfg.Name, "l1", "negative_hit").Inc()
+				return value, true, true
+			}
+			requestsTotal.WithLabelValues(c.cfg.Name, "l1", "hit").Inc()
+			return entry.value.(T), true, false
+		}
+		requestsTotal.WithLabelValues(c.cfg.Name, "l1", "miss").Inc()
+	}
+
+	if c.cfg.Redis == nil {
+		return value, false, false
+	}
+	data, err := c.cfg.Redis.Get(ctx, c.redisKey(key)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			c.log.Warn("cache read failed", zap.String("key", key), zap.Error(err))
+		}
+		requestsTotal.WithLabelValues(c.cfg.Name, "l2", "miss").Inc()
+		return value, false, false
+	}
+
+	var entry redisEntry[T]
+	if err := json.Unmarshal(data, &entry); err != nil {
+		c.log.Warn("invalid cache entry", zap.String("key", key), zap.Error(err))
+		requestsTotal.WithLabelValues(c.cfg.Name, "l2", "miss").Inc()
+		return value, false, false
+	}
+	if entry.N {
+		requestsTotal.WithLabelValues(c.cfg.Name, "l2", "negative_hit").Inc()
+		c.setL1(key, value, true, c.cfg.NegativeTTL)
+		return value, true, true
+	}
+	requestsTotal.WithLabelValues(c.cfg.Name, "l2", "hit").Inc()
+	c.setL1(key, entry.V, false, c.cfg.L1TTL)
+	return entry.V, true, false
+}
+
+// store writes both tiers; Redis failures are logged and returned
+func (c *Cache[T]) store(ctx context.Context, key string, value T, negative bool, ttl time.Duration) error {
+	c.setL1(key, value, negative, ttl)
+	if c.cfg.Redis == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(redisEntry[T]{V: value, N: negative})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	if err := c.cfg.Redis.Set(ctx, c.redisKey(key), data, ttl).Err(); err != nil {
+		c.log.Warn("cache write failed", zap.String("key", key), zap.Error(err))
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// setL1 writes the in-memory tier, never keeping entries longer than L1TTL
+func (c *Cache[T]) setL1(key string, value T, negative bool, ttl time.Duration) {
+	if c.l1 == nil {
+		return
+	}
+	if ttl > c.cfg.L1TTL {
+		ttl = c.cfg.L1TTL
+	}
+	c.l1.set(key, value, negative, ttl)
+	l1Entries.WithLabelValues(c.cfg.Name).Set(float64(c.l1.len()))
+}
+
+// invalidateLocal drops keys or a pattern from the in-memory tier
+func (c *Cache[T]) invalidateLocal(keys []string, pattern string) {
+	if c.l1 == nil {
+		return
+	}
+	c.l1.delete(keys...)
+	if pattern != "" {
+		c.l1.deleteMatching(pattern)
+	}
+	l1Entries.WithLabelValues(c.cfg.Name).Set(float64(c.l1.len()))
+}
+
+// broadcast tells other replicas to drop their in-memory copies
+func (c *Cache[T]) broadcast(ctx context.Context, msg *invalidation) error {
+	if c.cfg.Bus == nil || c.l1 == nil {
+		return nil
+	}
+	msg.Cache = c.cfg.Name
+	return c.cfg.Bus.publish(ctx, msg)
+}
+
+// redisKey namespaces a key, e.g. "cache:users:42"
+func (c *Cache[T]) redisKey(key string) string {
+	return "cache:" + c.cfg.Name + ":" + key
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"container/list"
+	"path"
+	"sync"
+	"time"
+)
+
+// lru is a size-bounded in-memory map with per-entry expiry
+type lru struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruEntry struct {
+	key       string
+	value     interface{}
+	negative  bool
+	expiresAt time.Time
+}
+
+func newLRU(size int) *lru {
+	return &lru{size: size, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+// get returns a live entry and marks it recently used
+func (l *lru) get(key string) (*lruEntry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	el, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*lruEntry)
+	if time.Now().After(entry.expiresAt) {
+		l.removeElement(el)
+		return nil, false
+	}
+	l.ll.MoveToFront(el)
+	return entry, true
+}
+
+// set stores an entry, evicting the least recently used one when full
+func (l *lru) set(key string, value interface{}, negative bool, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry := &lruEntry{key: key, value: value, negative: negative, expiresAt: time.Now().Add(ttl)}
+	if el, ok := l.items[key]; ok {
+		el.Value = entry
+		l.ll.MoveToFront(el)
+		return
+	}
+	l.items[key] = l.ll.PushFront(entry)
+	for l.ll.Len() > l.size {
+		l.removeElement(l.ll.Back())
+	}
+}
+
+// delete removes keys
+func (l *lru) delete(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		if el, ok := l.items[key]; ok {
+			l.removeElement(el)
+		}
+	}
+}
+
+// deleteMatching removes keys matching a glob pattern (the syntax of Redis SCAN MATCH)
+func (l *lru) deleteMatching(pattern string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := 0
+	for key, el := range l.items {
+		if ok, _ := path.Match(pattern, key); ok {
+			l.removeElement(el)
+			n++
+		}
+	}
+	return n
+}
+
+// clear removes everything
+func (l *lru) clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ll.Init()
+	l.items = make(map[string]*list.Element)
+}
+
+// len returns the number of entries, including expired ones not yet evicted
+func (l *lru) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.ll.Len()
+}
+
+func (l *lru) removeElement(el *list.Element) {
+	l.ll.Remove(el)
+	delete(l.items, el.Value.(*lruEntry).key)
+}
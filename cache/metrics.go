@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Hits by tier, loader runs and their duration, invalidations and L1 size, by cache name
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_requests_total",
+		Help: "Cache lookups by tier (l1, l2) and result (hit, negative_hit, miss).",
+	}, []string{"cache", "tier", "result"})
+
+	loadsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_loads_total",
+		Help: "Read-through loader calls by result (success, not_found, error).",
+	}, []string{"cache", "result"})
+
+	loadDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cache_load_duration_seconds",
+		Help:    "Duration of read-through loader calls.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"cache"})
+
+	invalidationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_invalidations_total",
+		Help: "Invalidations by source (local, bus).",
+	}, []string{"cache", "source"})
+
+	l1Entries = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cache_l1_entries",
+		Help: "Entries held in the in-memory tier.",
+	}, []string{"cache"})
+)
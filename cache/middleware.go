@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/gin-gonic/gin"
+)
+
+// Response is an HTTP response stored by Middleware
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// MiddlewareConfig configures response caching
+type MiddlewareConfig struct {
+	// TTL overrides the cache TTL for responses
+	TTL time.Duration
+	// Key builds the cache key, defaulting to tenant + path + query (+ user with VaryByUser)
+	Key func(c *gin.Context) string
+	// VaryByUser caches per user; required for responses that depend on who is asking
+	VaryByUser bool
+}
+
+// Middleware serves successful GET responses from c, marking them with X-Cache: HIT or MISS.
+// Invalidate with c.InvalidatePattern, e.g. "t:42:/api/v1/products*" after a product write.
+func Middleware(c *Cache[Response], cfg MiddlewareConfig) gin.HandlerFunc {
+	if cfg.Key == nil {
+		cfg.Key = func(ctx *gin.Context) string {
+			key := "t:" + ctxutil.TenantID(ctx) + ":" + ctx.Request.URL.RequestURI()
+			if cfg.VaryByUser {
+				if userID, ok := ctxutil.UserID(ctx); ok {
+					key += ":u:" + strconv.FormatUint(userID, 10)
+				}
+			}
+			return key
+		}
+	}
+
+	return func(ctx *gin.Context) {
+		if ctx.Request.Method != http.MethodGet || ctx.GetHeader("Cache-Control") == "no-cache" {
+			ctx.Next()
+			return
+		}
+
+		key := cfg.Key(ctx)
+		if cached, ok, _ := c.Get(ctx, key); ok {
+			for name, values := range cached.Header {
+				for _, v := range values {
+					ctx.Writer.Header().Add(name, v)
+				}
+			}
+			ctx.Header("X-Cache", "HIT")
+			ctx.Data(cached.Status, cached.Header.Get("Content-Type"), cached.Body)
+			ctx.Abort()
+			return
+		}
+
+		w := &recordingWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = w
+		ctx.Header("X-Cache", "MISS")
+		ctx.Next()
+
+		if w.Status() != http.StatusOK || ctx.Writer.Header().Get("Set-Cookie") != "" {
+			return
+		}
+		header := ctx.Writer.Header().Clone()
+		header.Del("X-Cache")
+		header.Del("X-Request-ID")
+		_ = c.Set(ctx, key, Response{Status: w.Status(), Header: header, Body: w.body.Bytes()}, cfg.TTL)
+	}
+}
+
+// recordingWriter copies the response body while writing it
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,200 @@
+package calendar
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"go.uber.org/zap"
+)
+
+// Location is Saudi time (UTC+3, no daylight saving); business days are judged in it
+var Location = time.FixedZone("Asia/Riyadh", 3*60*60)
+
+// Config configures a Calendar
+type Config struct {
+	// Weekend defaults to Friday and Saturday
+	Weekend []time.Weekday
+	// Source provides holidays (default SaudiHolidays{}, preceded by FileSource(CALENDAR_HOLIDAYS_FILE)
+	// when that variable is set)
+	Source HolidaySource
+}
+
+// Calendar answers business-day questions for SLA timers and scheduling
+type Calendar struct {
+	weekend [7]bool
+	source  HolidaySource
+
+	mu    sync.RWMutex
+	years map[int][]Holiday
+}
+
+// New creates a calendar
+func New(cfg *Config) *Calendar {
+	c := &Calendar{source: cfg.Source, years: make(map[int][]Holiday)}
+	weekend := cfg.Weekend
+	if weekend == nil {
+		weekend = []time.Weekday{time.Friday, time.Saturday}
+	}
+	for _, d := range weekend {
+		c.weekend[d] = true
+	}
+	if c.source == nil {
+		c.source = SaudiHolidays{}
+		if path := os.Getenv("CALENDAR_HOLIDAYS_FILE"); path != "" {
+			c.source = FirstOf(FileSource(path), c.source)
+		}
+	}
+	return c
+}
+
+// Load fetches the holidays of the given years, replacing cached ones; call it at startup to
+// surface source errors, and again after holidays are announced
+func (c *Calendar) Load(ctx context.Context, years ...int) error {
+	for _, year := range years {
+		holidays, err := c.source.Holidays(ctx, year)
+		if err != nil {
+			return err
+		}
+		c.mu.Lock()
+		c.years[year] = holidays
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+// holidays returns the cached holidays of a year, loading them on first use; a failing source
+// is logged and treated as having no holidays until the next Load
+func (c *Calendar) holidays(year int) []Holiday {
+	c.mu.RLock()
+	holidays, ok := c.years[year]
+	c.mu.RUnlock()
+	if ok {
+		return holidays
+	}
+
+	holidays, err := c.source.Holidays(context.Background(), year)
+	if err != nil {
+		logger.Module("calendar").Error("failed to load holidays", zap.Int("year", year), zap.Error(err))
+	}
+	c.mu.Lock()
+	c.years[year] = holidays
+	c.mu.Unlock()
+	return holidays
+}
+
+// IsWeekend reports whether t falls on a weekend day in Location
+func (c *Calendar) IsWeekend(t time.Time) bool {
+	return c.weekend[t.In(Location).Weekday()]
+}
+
+// Holiday returns the holiday t falls on
+func (c *Calendar) Holiday(t time.Time) (Holiday, bool) {
+	year := t.In(Location).Year()
+	// Holidays late in December can run into the next year
+	for _, y := range []int{year, year - 1} {
+		for _, h := range c.holidays(y) {
+			if h.Contains(t) {
+				return h, true
+			}
+		}
+	}
+	return Holiday{}, false
+}
+
+// IsHoliday reports whether t falls on a holiday
+func (c *Calendar) IsHoliday(t time.Time) bool {
+	_, ok := c.Holiday(t)
+	return ok
+}
+
+// IsBusinessDay reports whether t is neither a weekend nor a holiday
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	return !c.IsWeekend(t) && !c.IsHoliday(t)
+}
+
+// NextBusinessDay returns the first business day after t, keeping t's time of day
+func (c *Calendar) NextBusinessDay(t time.Time) time.Time {
+	return c.BusinessDayOnOrAfter(t.AddDate(0, 0, 1))
+}
+
+// PreviousBusinessDay returns the last business day before t, keeping t's time of day
+func (c *Calendar) PreviousBusinessDay(t time.Time) time.Time {
+	return c.BusinessDayOnOrBefore(t.AddDate(0, 0, -1))
+}
+
+// BusinessDayOnOrAfter returns t when it is a business day, otherwise the next one
+func (c *Calendar) BusinessDayOnOrAfter(t time.Time) time.Time {
+	for !c.IsBusinessDay(t) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// BusinessDayOnOrBefore returns t when it is a business day, otherwise the previous one
+func (c *Calendar) BusinessDayOnOrBefore(t time.Time) time.Time {
+	for !c.IsBusinessDay(t) {
+		t = t.AddDate(0, 0, -1)
+	}
+	return t
+}
+
+// AddBusinessDays moves t by n business days, backwards when n is negative, keeping the time
+// of day; a start on a non-working day counts from the adjacent business day
+func (c *Calendar) AddBusinessDays(t time.Time, n int) time.Time {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for ; n > 0; n-- {
+		t = t.AddDate(0, 0, step)
+		for !c.IsBusinessDay(t) {
+			t = t.AddDate(0, 0, step)
+		}
+	}
+	return t
+}
+
+// BusinessDaysBetween counts the business days after from up to and including to; it is
+// negative when to is before from
+func (c *Calendar) BusinessDaysBetween(from, to time.Time) int {
+	sign := 1
+	if julianDay(to) < julianDay(from) {
+		from, to, sign = to, from, -1
+	}
+	n := 0
+	for day := julianDay(from) + 1; day <= julianDay(to); day++ {
+		if c.IsBusinessDay(fromJulianDay(day)) {
+			n++
+		}
+	}
+	return sign * n
+}
+
+var (
+	defaultOnce     sync.Once
+	defaultCalendar *Calendar
+)
+
+// Default returns the shared calendar with Saudi weekends and holidays
+func Default() *Calendar {
+	defaultOnce.Do(func() { defaultCalendar = New(&Config{}) })
+	return defaultCalendar
+}
+
+// IsBusinessDay reports whether t is a business day in the default calendar
+func IsBusinessDay(t time.Time) bool {
+	return Default().IsBusinessDay(t)
+}
+
+// NextBusinessDay returns the first business day after t in the default calendar
+func NextBusinessDay(t time.Time) time.Time {
+	return Default().NextBusinessDay(t)
+}
+
+// AddBusinessDays moves t by n business days in the default calendar
+func AddBusinessDays(t time.Time, n int) time.Time {
+	return Default().AddBusinessDays(t, n)
+}
@@ -0,0 +1,119 @@
+package calendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Hijri months
+const (
+	Muharram = iota + 1
+	Safar
+	RabiAlAwwal
+	RabiAlThani
+	JumadaAlUla
+	JumadaAlAkhirah
+	Rajab
+	Shaban
+	Ramadan
+	Shawwal
+	DhuAlQadah
+	DhuAlHijjah
+)
+
+// julianDayUnixEpoch is the Julian Day Number of 1970-01-01
+const julianDayUnixEpoch = 2440588
+
+// islamicEpoch is the Julian Day Number before 1 Muharram 1 AH (civil epoch)
+const islamicEpoch = 1948439
+
+// HijriDate is a date in the tabular Islamic calendar. It can differ from the official Umm
+// al-Qura calendar by a day; holidays announced by sighting should come from a HolidaySource.
+type HijriDate struct {
+	Year  int `json:"year"`
+	Month int `json:"month"`
+	Day   int `json:"day"`
+}
+
+// ToHijri converts the calendar day of t in Location to its Hijri date
+func ToHijri(t time.Time) HijriDate {
+	return hijriFromJDN(julianDay(t))
+}
+
+// Time returns the start of the Hijri day in Location
+func (h HijriDate) Time() time.Time {
+	return fromJulianDay(hijriToJDN(h.Year, h.Month, h.Day))
+}
+
+// String formats the date as YYYY-MM-DD, the format accepted by validation's hijri_date tag
+func (h HijriDate) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", h.Year, h.Month, h.Day)
+}
+
+// ParseHijri parses a YYYY-MM-DD (or YYYY/MM/DD) Hijri date
+func ParseHijri(s string) (HijriDate, error) {
+	parts := strings.FieldsFunc(strings.TrimSpace(s), func(r rune) bool { return r == '-' || r == '/' })
+	if len(parts) != 3 {
+		return HijriDate{}, fmt.Errorf("invalid hijri date %q", s)
+	}
+	var n [3]int
+	for i, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			return HijriDate{}, fmt.Errorf("invalid hijri date %q", s)
+		}
+		n[i] = v
+	}
+	h := HijriDate{Year: n[0], Month: n[1], Day: n[2]}
+	if h.Year < 1 || h.Month < 1 || h.Month > 12 || h.Day < 1 || h.Day > HijriMonthDays(h.Year, h.Month) {
+		return HijriDate{}, fmt.Errorf("invalid hijri date %q", s)
+	}
+	return h, nil
+}
+
+// HijriMonthDays returns the number of days in a Hijri month
+func HijriMonthDays(year, month int) int {
+	if month%2 == 1 || (month == DhuAlHijjah && isHijriLeap(year)) {
+		return 30
+	}
+	return 29
+}
+
+// isHijriLeap reports whether year has 355 days in the 30-year cycle
+func isHijriLeap(year int) bool {
+	return (14+11*year)%30 < 11
+}
+
+func hijriToJDN(year, month, day int) int {
+	return day + (59*(month-1)+1)/2 + (year-1)*354 + floorDiv(3+11*year, 30) + islamicEpoch
+}
+
+func hijriFromJDN(jdn int) HijriDate {
+	year := floorDiv(30*(jdn-islamicEpoch-1)+10646, 10631)
+	month := 1
+	for month < 12 && jdn >= hijriToJDN(year, month+1, 1) {
+		month++
+	}
+	return HijriDate{Year: year, Month: month, Day: jdn - hijriToJDN(year, month, 1) + 1}
+}
+
+// julianDay returns the Julian Day Number of t's calendar day in Location
+func julianDay(t time.Time) int {
+	y, m, d := t.In(Location).Date()
+	return julianDayUnixEpoch + int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix()/86400)
+}
+
+func fromJulianDay(jdn int) time.Time {
+	u := time.Unix(int64(jdn-julianDayUnixEpoch)*86400, 0).UTC()
+	return time.Date(u.Year(), u.Month(), u.Day(), 0, 0, 0, 0, Location)
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,153 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Holiday is a named run of non-working days; From and To are inclusive calendar dates
+type Holiday struct {
+	Name   string    `json:"name"`
+	NameAr string    `json:"name_ar,omitempty"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+}
+
+// Contains reports whether t's calendar day falls within the holiday
+func (h Holiday) Contains(t time.Time) bool {
+	day := julianDay(t)
+	return day >= julianDay(h.From) && day <= julianDay(h.To)
+}
+
+// HolidaySource provides the holidays of a Gregorian year; an empty result means the source
+// has no data for that year
+type HolidaySource interface {
+	Holidays(ctx context.Context, year int) ([]Holiday, error)
+}
+
+// SourceFunc adapts a function to HolidaySource
+type SourceFunc func(ctx context.Context, year int) ([]Holiday, error)
+
+// Holidays implements HolidaySource
+func (f SourceFunc) Holidays(ctx context.Context, year int) ([]Holiday, error) {
+	return f(ctx, year)
+}
+
+// Static is a fixed list of holidays, e.g. the dates announced for the current year
+type Static []Holiday
+
+// Holidays implements HolidaySource
+func (s Static) Holidays(_ context.Context, year int) ([]Holiday, error) {
+	var out []Holiday
+	for _, h := range s {
+		if h.From.In(Location).Year() <= year && h.To.In(Location).Year() >= year {
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
+
+// FirstOf uses the first source with holidays for a year, so announced dates can override the
+// computed ones: FirstOf(FileSource(path), SaudiHolidays{})
+func FirstOf(sources ...HolidaySource) HolidaySource {
+	return SourceFunc(func(ctx context.Context, year int) ([]Holiday, error) {
+		for _, src := range sources {
+			holidays, err := src.Holidays(ctx, year)
+			if err != nil {
+				return nil, err
+			}
+			if len(holidays) > 0 {
+				return holidays, nil
+			}
+		}
+		return nil, nil
+	})
+}
+
+// FileSource reads holidays from a JSON file of [{"name", "from": "2025-03-30", "to": ...}];
+// the file is read on every call, and Calendar caches the result per year
+func FileSource(path string) HolidaySource {
+	return SourceFunc(func(ctx context.Context, year int) ([]Holiday, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read holidays file: %w", err)
+		}
+		var entries []struct {
+			Name   string `json:"name"`
+			NameAr string `json:"name_ar"`
+			From   string `json:"from"`
+			To     string `json:"to"`
+		}
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse holidays file: %w", err)
+		}
+
+		holidays := make(Static, 0, len(entries))
+		for _, e := range entries {
+			from, err := time.ParseInLocation(time.DateOnly, e.From, Location)
+			if err != nil {
+				return nil, fmt.Errorf("invalid holiday %q: %w", e.Name, err)
+			}
+			to := from
+			if e.To != "" {
+				if to, err = time.ParseInLocation(time.DateOnly, e.To, Location); err != nil {
+					return nil, fmt.Errorf("invalid holiday %q: %w", e.Name, err)
+				}
+			}
+			holidays = append(holidays, Holiday{Name: e.Name, NameAr: e.NameAr, From: from, To: to})
+		}
+		return holidays.Holidays(ctx, year)
+	})
+}
+
+// SaudiHolidays computes the official Saudi holidays: Founding Day (22 Feb), National Day
+// (23 Sep), Eid al-Fitr from 1 Shawwal and Eid al-Adha from the Day of Arafah. Eid dates use
+// the tabular Hijri calendar and may be a day off the sighted dates.
+type SaudiHolidays struct {
+	// EidAlFitrDays is the length of the Eid al-Fitr holiday (default 4, the private sector)
+	EidAlFitrDays int
+	// EidAlAdhaDays is the length of the Eid al-Adha holiday including Arafah (default 4)
+	EidAlAdhaDays int
+}
+
+// Holidays implements HolidaySource
+func (s SaudiHolidays) Holidays(_ context.Context, year int) ([]Holiday, error) {
+	fitrDays, adhaDays := s.EidAlFitrDays, s.EidAlAdhaDays
+	if fitrDays <= 0 {
+		fitrDays = 4
+	}
+	if adhaDays <= 0 {
+		adhaDays = 4
+	}
+
+	holidays := Static{
+		{Name: "Founding Day", NameAr: "يوم التأسيس", From: date(year, time.February, 22), To: date(year, time.February, 22)},
+		{Name: "National Day", NameAr: "اليوم الوطني", From: date(year, time.September, 23), To: date(year, time.September, 23)},
+	}
+	// A Gregorian year overlaps two Hijri years; keep the Eids that start in it
+	first := ToHijri(date(year, time.January, 1)).Year
+	for hy := first; hy <= first+1; hy++ {
+		fitr := HijriDate{Year: hy, Month: Shawwal, Day: 1}.Time()
+		adha := HijriDate{Year: hy, Month: DhuAlHijjah, Day: 9}.Time()
+		holidays = append(holidays,
+			Holiday{Name: "Eid al-Fitr", NameAr: "عيد الفطر", From: fitr, To: fitr.AddDate(0, 0, fitrDays-1)},
+			Holiday{Name: "Eid al-Adha", NameAr: "عيد الأضحى", From: adha, To: adha.AddDate(0, 0, adhaDays-1)},
+		)
+	}
+
+	var out []Holiday
+	for _, h := range holidays {
+		if h.From.Year() == year {
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
+
+// date returns the start of a Gregorian day in Location
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, Location)
+}
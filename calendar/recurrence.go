@@ -0,0 +1,182 @@
+package calendar
+
+import "time"
+
+// Recurrence yields the dates of a repeating schedule
+type Recurrence interface {
+	// Next returns the start (in Location) of the first occurrence on a day after t's day
+	Next(t time.Time) time.Time
+}
+
+// Roll moves occurrences that land on non-business days
+type Roll int
+
+const (
+	// NoRoll keeps occurrences on non-business days
+	NoRoll Roll = iota
+	// Following moves them to the next business day
+	Following
+	// Preceding moves them to the previous business day
+	Preceding
+)
+
+// Daily recurs every n days counted from start
+func Daily(start time.Time, n int) Recurrence {
+	if n < 1 {
+		n = 1
+	}
+	return daily{start: julianDay(start), n: n}
+}
+
+type daily struct{ start, n int }
+
+func (r daily) Next(t time.Time) time.Time {
+	day := julianDay(t) + 1
+	if day <= r.start {
+		return fromJulianDay(r.start)
+	}
+	if rem := (day - r.start) % r.n; rem != 0 {
+		day += r.n - rem
+	}
+	return fromJulianDay(day)
+}
+
+// Weekly recurs on the given weekdays
+func Weekly(days ...time.Weekday) Recurrence {
+	var r weekly
+	for _, d := range days {
+		r[d] = true
+	}
+	return r
+}
+
+type weekly [7]bool
+
+func (r weekly) Next(t time.Time) time.Time {
+	day := fromJulianDay(julianDay(t) + 1)
+	for i := 0; i < 7; i++ {
+		if r[day.Weekday()] {
+			return day
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}
+}
+
+// Monthly recurs on a Gregorian day of month, on the last day of shorter months
+func Monthly(day int) Recurrence {
+	return monthly{day: day}
+}
+
+type monthly struct{ day int }
+
+func (r monthly) Next(t time.Time) time.Time {
+	after := julianDay(t)
+	y, m, _ := t.In(Location).Date()
+	for {
+		last := date(y, m+1, 0).Day()
+		d := date(y, m, min(r.day, last))
+		if julianDay(d) > after {
+			return d
+		}
+		y, m, _ = date(y, m+1, 1).Date()
+	}
+}
+
+// Yearly recurs on a Gregorian month and day; 29 February falls on 28 February in common years
+func Yearly(month time.Month, day int) Recurrence {
+	return yearly{month: month, day: day}
+}
+
+type yearly struct {
+	month time.Month
+	day   int
+}
+
+func (r yearly) Next(t time.Time) time.Time {
+	after := julianDay(t)
+	for y := t.In(Location).Year(); ; y++ {
+		d := date(y, r.month, min(r.day, date(y, r.month+1, 0).Day()))
+		if julianDay(d) > after {
+			return d
+		}
+	}
+}
+
+// HijriMonthly recurs on a Hijri day of month, e.g. 1 for the start of every Hijri month; day 30
+// falls on the 29th of short months
+func HijriMonthly(day int) Recurrence {
+	return hijriMonthly{day: day}
+}
+
+type hijriMonthly struct{ day int }
+
+func (r hijriMonthly) Next(t time.Time) time.Time {
+	after := julianDay(t)
+	h := ToHijri(t)
+	y, m := h.Year, h.Month
+	for {
+		d := hijriToJDN(y, m, min(r.day, HijriMonthDays(y, m)))
+		if d > after {
+			return fromJulianDay(d)
+		}
+		if m++; m > 12 {
+			y, m = y+1, 1
+		}
+	}
+}
+
+// HijriYearly recurs on a Hijri month and day, e.g. HijriYearly(Ramadan, 1); Hijri years are
+// about 11 days shorter, so the Gregorian date moves every year
+func HijriYearly(month, day int) Recurrence {
+	return hijriYearly{month: month, day: day}
+}
+
+type hijriYearly struct{ month, day int }
+
+func (r hijriYearly) Next(t time.Time) time.Time {
+	after := julianDay(t)
+	for y := ToHijri(t).Year; ; y++ {
+		d := hijriToJDN(y, r.month, min(r.day, HijriMonthDays(y, r.month)))
+		if d > after {
+			return fromJulianDay(d)
+		}
+	}
+}
+
+// Next returns the next occurrence of r after t, rolled off non-business days
+func (c *Calendar) Next(r Recurrence, t time.Time, roll Roll) time.Time {
+	next := r.Next(t)
+	switch roll {
+	case Following:
+		return c.BusinessDayOnOrAfter(next)
+	case Preceding:
+		if d := c.BusinessDayOnOrBefore(next); julianDay(d) > julianDay(t) {
+			return d
+		}
+		// Rolling back would land on or before t; take the following occurrence
+		return c.Next(r, next, roll)
+	}
+	return next
+}
+
+// Occurrences returns the occurrences of r after from up to and including to, rolled off
+// non-business days
+func (c *Calendar) Occurrences(r Recurrence, from, to time.Time, roll Roll) []time.Time {
+	var out []time.Time
+	for t := from; ; {
+		next := r.Next(t)
+		if next.IsZero() || julianDay(next) > julianDay(to) {
+			return out
+		}
+		d := next
+		switch roll {
+		case Following:
+			d = c.BusinessDayOnOrAfter(next)
+		case Preceding:
+			d = c.BusinessDayOnOrBefore(next)
+		}
+		out = append(out, d)
+		t = next
+	}
+}
@@ -0,0 +1,327 @@
+package capture
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/crypto"
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/idgen"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/privacy"
+	"github.com/Masharah-Advisory/common/storage"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Headers used by capture mode
+const (
+	// TokenHeader must carry Config.Token for a request to be captured
+	TokenHeader = "X-Capture-Token"
+	// IDHeader is set on captured responses with the record ID to pass to the replayer
+	IDHeader = "X-Capture-ID"
+)
+
+// ErrNotFound is returned by Load for unknown records
+var ErrNotFound = apperror.New("capture_not_found", apperror.KindNotFound, "capture not found").
+	WithKey("capture.not_found")
+
+// Config configures capture mode
+type Config struct {
+	// Enabled turns capture mode on; keep it off unless a bug is being chased
+	Enabled bool
+	Storage storage.Storage
+	// Prefix is the storage key prefix, defaults to "captures"
+	Prefix string
+	// Routes limits capture to these gin route patterns, e.g. "/api/v1/orders/:id"; empty
+	// captures every route
+	Routes []string
+	// TrustedIPs lists the client IPs or CIDRs allowed to request a capture
+	TrustedIPs []string
+	// Token is the secret a request must send in X-Capture-Token
+	Token string
+	// MaxBody caps each stored body, defaults to 64 KiB; larger bodies are truncated
+	MaxBody int64
+	// RedactHeaders and RedactFields extend DefaultRedactHeaders and DefaultRedactFields
+	RedactHeaders []string
+	RedactFields  map[string]privacy.Masker
+	// SaveTimeout bounds the background upload of a record, defaults to 30s
+	SaveTimeout time.Duration
+}
+
+// Message is one side of a captured exchange. Bodies that are neither JSON, form nor text are
+// omitted so binary uploads never reach storage.
+type Message struct {
+	Method    string      `json:"method,omitempty"`
+	Path      string      `json:"path,omitempty"`
+	Query     string      `json:"query,omitempty"`
+	Status    int         `json:"status,omitempty"`
+	Header    http.Header `json:"header"`
+	Body      string      `json:"body,omitempty"`
+	Truncated bool        `json:"truncated,omitempty"`
+	Omitted   bool        `json:"omitted,omitempty"`
+}
+
+// Record is a captured request/response pair with personal data redacted
+type Record struct {
+	ID         string    `json:"id"`
+	CapturedAt time.Time `json:"captured_at"`
+	DurationMS int64     `json:"duration_ms"`
+	Route      string    `json:"route"`
+	RequestID  string    `json:"request_id,omitempty"`
+	TenantID   string    `json:"tenant_id,omitempty"`
+	UserID     uint64    `json:"user_id,omitempty"`
+	Request    Message   `json:"request"`
+	Response   Message   `json:"response"`
+}
+
+// Recorder captures selected requests into storage
+type Recorder struct {
+	cfg      *Config
+	routes   map[string]bool
+	networks []*net.IPNet
+	redactor *redactor
+	log      *zap.Logger
+}
+
+// New creates a recorder; it validates the config only when capture mode is enabled
+func New(cfg *Config) (*Recorder, error) {
+	if cfg.Prefix == "" {
+		cfg.Prefix = "captures"
+	}
+	if cfg.MaxBody <= 0 {
+		cfg.MaxBody = 64 << 10
+	}
+	if cfg.SaveTimeout <= 0 {
+		cfg.SaveTimeout = 30 * time.Second
+	}
+	r := &Recorder{
+		cfg:      cfg,
+		routes:   make(map[string]bool, len(cfg.Routes)),
+		redactor: newRedactor(cfg.RedactHeaders, cfg.RedactFields),
+		log:      logger.Module("capture"),
+	}
+	for _, route := range cfg.Routes {
+		r.routes[route] = true
+	}
+	if !cfg.Enabled {
+		return r, nil
+	}
+
+	if cfg.Storage == nil {
+		return nil, fmt.Errorf("capture storage is required")
+	}
+	if len(cfg.Token) < 16 {
+		return nil, fmt.Errorf("capture token must be at least 16 characters")
+	}
+	if len(cfg.TrustedIPs) == 0 {
+		return nil, fmt.Errorf("capture requires at least one trusted IP")
+	}
+	for _, ip := range cfg.TrustedIPs {
+		if !strings.Contains(ip, "/") {
+			if strings.Contains(ip, ":") {
+				ip += "/128"
+			} else {
+				ip += "/32"
+			}
+		}
+		_, network, err := net.ParseCIDR(ip)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted IP %q: %w", ip, err)
+		}
+		r.networks = append(r.networks, network)
+	}
+	return r, nil
+}
+
+// Middleware records the requests that pass every gate: capture mode on, a selected route, a
+// trusted client IP and the capture token. Other requests are untouched.
+func (r *Recorder) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !r.selected(c) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		reqBody, truncated := r.readBody(c)
+		w := &recordingWriter{ResponseWriter: c.Writer, max: r.cfg.MaxBody}
+		c.Writer = w
+
+		id := idgen.NewULIDString()
+		c.Header(IDHeader, id)
+		c.Next()
+
+		rec := &Record{
+			ID:         id,
+			CapturedAt: start.UTC(),
+			DurationMS: time.Since(start).Milliseconds(),
+			Route:      c.FullPath(),
+			RequestID:  ctxutil.RequestID(c),
+			TenantID:   ctxutil.TenantID(c),
+			Request: Message{
+				Method:    c.Request.Method,
+				Path:      c.Request.URL.Path,
+				Query:     r.redactor.query(c.Request.URL.RawQuery),
+				Header:    r.redactor.header(c.Request.Header),
+				Truncated: truncated,
+			},
+			Response: Message{
+				Status:    w.Status(),
+				Header:    r.redactor.header(w.Header()),
+				Truncated: w.truncated,
+			},
+		}
+		if userID, ok := ctxutil.UserID(c); ok {
+			rec.UserID = userID
+		}
+		rec.Request.Body, rec.Request.Omitted = r.body(c.Request.Header.Get("Content-Type"), reqBody, truncated)
+		rec.Response.Body, rec.Response.Omitted = r.body(w.Header().Get("Content-Type"), w.body.Bytes(), w.truncated)
+
+		go r.save(rec)
+	}
+}
+
+// Load reads a record by ID
+func (r *Recorder) Load(ctx context.Context, id string) (*Record, error) {
+	return Load(ctx, r.cfg.Storage, r.cfg.Prefix, id)
+}
+
+// Load reads a record by ID from store, e.g. from a replay tool without a Recorder
+func Load(ctx context.Context, store storage.Storage, prefix, id string) (*Record, error) {
+	if _, err := idgen.ParseULID(id); err != nil {
+		return nil, ErrNotFound
+	}
+	rc, _, err := store.Get(ctx, key(prefix, id))
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to load capture %s: %w", id, err)
+	}
+	defer rc.Close()
+	var rec Record
+	if err := json.NewDecoder(rc).Decode(&rec); err != nil {
+		return nil, fmt.Errorf("failed to decode capture %s: %w", id, err)
+	}
+	return &rec, nil
+}
+
+func (r *Recorder) selected(c *gin.Context) bool {
+	if !r.cfg.Enabled {
+		return false
+	}
+	token := c.GetHeader(TokenHeader)
+	if token == "" || !crypto.Equal(token, r.cfg.Token) {
+		return false
+	}
+	if len(r.routes) > 0 && !r.routes[c.FullPath()] {
+		return false
+	}
+	ip := net.ParseIP(c.ClientIP())
+	if ip == nil {
+		return false
+	}
+	for _, network := range r.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// readBody keeps up to MaxBody bytes of the request body while leaving the full body readable
+// by the handler
+func (r *Recorder) readBody(c *gin.Context) ([]byte, bool) {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return nil, false
+	}
+	head, err := io.ReadAll(io.LimitReader(c.Request.Body, r.cfg.MaxBody+1))
+	if err != nil {
+		r.log.Warn("failed to read request body for capture", zap.Error(err))
+	}
+	c.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), c.Request.Body), c.Request.Body}
+	if int64(len(head)) > r.cfg.MaxBody {
+		return head[:r.cfg.MaxBody], true
+	}
+	return head, false
+}
+
+// body redacts a captured body; truncated JSON cannot be parsed and is omitted
+func (r *Recorder) body(contentType string, body []byte, truncated bool) (string, bool) {
+	if len(body) == 0 {
+		return "", false
+	}
+	if truncated && !strings.HasPrefix(contentType, "text/") {
+		return "", true
+	}
+	out, ok := r.redactor.body(strings.ToLower(contentType), body)
+	if !ok {
+		return "", true
+	}
+	return string(out), false
+}
+
+func (r *Recorder) save(rec *Record) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.SaveTimeout)
+	defer cancel()
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		r.log.Warn("failed to encode capture", zap.String("id", rec.ID), zap.Error(err))
+		return
+	}
+	_, err = r.cfg.Storage.Put(ctx, key(r.cfg.Prefix, rec.ID), bytes.NewReader(data), int64(len(data)),
+		storage.PutOptions{ContentType: "application/json"})
+	if err != nil {
+		r.log.Warn("failed to store capture", zap.String("id", rec.ID), zap.Error(err))
+		return
+	}
+	r.log.Info("request captured",
+		zap.String("id", rec.ID),
+		zap.String("route", rec.Route),
+		zap.Int("status", rec.Response.Status),
+	)
+}
+
+func key(prefix, id string) string {
+	return path.Join(prefix, id+".json")
+}
+
+// recordingWriter copies up to max bytes of the response body while writing it
+type recordingWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	max       int64
+	truncated bool
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.record(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.record([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *recordingWriter) record(b []byte) {
+	room := w.max - int64(w.body.Len())
+	if int64(len(b)) > room {
+		b, w.truncated = b[:room], true
+	}
+	w.body.Write(b)
+}
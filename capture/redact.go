@@ -0,0 +1,145 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Masharah-Advisory/common/privacy"
+)
+
+// DefaultRedactHeaders are replaced with privacy.Redacted in every record
+var DefaultRedactHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"Proxy-Authorization",
+	"X-Api-Key",
+	"X-Service-Secret",
+	"X-Capture-Token",
+}
+
+// DefaultRedactFields are JSON fields and query parameters masked in every record, matched
+// case-insensitively; email and phone fields keep their shape through the privacy maskers
+var DefaultRedactFields = map[string]privacy.Masker{
+	"password":         redact,
+	"password_confirm": redact,
+	"current_password": redact,
+	"new_password":     redact,
+	"token":            redact,
+	"access_token":     redact,
+	"refresh_token":    redact,
+	"secret":           redact,
+	"otp":              redact,
+	"code":             redact,
+	"pin":              redact,
+	"cvv":              redact,
+	"card_number":      privacy.MaskLast4,
+	"iban":             privacy.MaskIBAN,
+	"national_id":      privacy.MaskLast4,
+	"email":            privacy.MaskEmail,
+	"phone":            privacy.MaskPhone,
+}
+
+func redact(string) string {
+	return privacy.Redacted
+}
+
+// redactor masks sensitive headers, query parameters and JSON body fields
+type redactor struct {
+	headers map[string]bool
+	fields  map[string]privacy.Masker
+}
+
+func newRedactor(headers []string, fields map[string]privacy.Masker) *redactor {
+	r := &redactor{headers: make(map[string]bool), fields: make(map[string]privacy.Masker)}
+	for _, h := range append(append([]string{}, DefaultRedactHeaders...), headers...) {
+		r.headers[http.CanonicalHeaderKey(h)] = true
+	}
+	for name, fn := range DefaultRedactFields {
+		r.fields[strings.ToLower(name)] = fn
+	}
+	for name, fn := range fields {
+		r.fields[strings.ToLower(name)] = fn
+	}
+	return r
+}
+
+func (r *redactor) header(h http.Header) http.Header {
+	out := h.Clone()
+	for name := range out {
+		if r.headers[http.CanonicalHeaderKey(name)] {
+			out[name] = []string{privacy.Redacted}
+		}
+	}
+	return out
+}
+
+func (r *redactor) query(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return raw
+	}
+	for name, vs := range values {
+		if fn, ok := r.fields[strings.ToLower(name)]; ok {
+			for i := range vs {
+				vs[i] = fn(vs[i])
+			}
+		}
+	}
+	return values.Encode()
+}
+
+// body masks JSON and form bodies; other content types are dropped unless they are text
+func (r *redactor) body(contentType string, body []byte) ([]byte, bool) {
+	if len(body) == 0 {
+		return body, true
+	}
+	switch {
+	case strings.Contains(contentType, "json"):
+		var v interface{}
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			return nil, false
+		}
+		out, err := json.Marshal(r.value(v))
+		if err != nil {
+			return nil, false
+		}
+		return out, true
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		return []byte(r.query(string(body))), true
+	case strings.HasPrefix(contentType, "text/"):
+		return body, true
+	default:
+		return nil, false
+	}
+}
+
+func (r *redactor) value(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if fn, ok := r.fields[strings.ToLower(k)]; ok {
+				if s, isString := child.(string); isString {
+					t[k] = fn(s)
+				} else if child != nil {
+					t[k] = privacy.Redacted
+				}
+				continue
+			}
+			t[k] = r.value(child)
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = r.value(t[i])
+		}
+	}
+	return v
+}
@@ -0,0 +1,92 @@
+package capture
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Masharah-Advisory/common/httpclient"
+	"github.com/Masharah-Advisory/common/privacy"
+)
+
+// hopHeaders are not replayed: they describe the original connection or are set by the client
+var hopHeaders = []string{
+	"Connection", "Content-Length", "Host", "Keep-Alive", "Transfer-Encoding", "Upgrade",
+	"User-Agent", "X-Forwarded-For", "X-Forwarded-Host", "X-Forwarded-Proto", "X-Real-Ip",
+	"X-Service-Id", "X-Service-Secret", IDHeader,
+}
+
+// Replayer re-issues captured requests against another environment, normally staging
+type Replayer struct {
+	client *httpclient.ServiceClient
+	header http.Header
+}
+
+// NewReplayer creates a replayer sending through client, whose service hosts should point at
+// staging. Redacted headers are dropped from replayed requests; header supplies replacements,
+// e.g. an Authorization for a staging test user.
+func NewReplayer(client *httpclient.ServiceClient, header http.Header) *Replayer {
+	return &Replayer{client: client, header: header}
+}
+
+// Result compares the replayed response with the captured one
+type Result struct {
+	Status         int           `json:"status"`
+	Header         http.Header   `json:"header"`
+	Body           string        `json:"body"`
+	Duration       time.Duration `json:"duration"`
+	CapturedStatus int           `json:"captured_status"`
+	StatusMatches  bool          `json:"status_matches"`
+}
+
+// Replay sends rec's request again and returns the new response. Bodies that were omitted or
+// truncated when captured cannot be replayed faithfully and are refused.
+func (r *Replayer) Replay(ctx context.Context, rec *Record) (*Result, error) {
+	if rec.Request.Omitted || rec.Request.Truncated {
+		return nil, fmt.Errorf("capture %s has an incomplete request body and cannot be replayed", rec.ID)
+	}
+
+	header := rec.Request.Header.Clone()
+	for _, name := range hopHeaders {
+		header.Del(name)
+	}
+	for name, values := range header {
+		for _, v := range values {
+			if v == privacy.Redacted {
+				header.Del(name)
+				break
+			}
+		}
+	}
+	for name, values := range r.header {
+		header[http.CanonicalHeaderKey(name)] = values
+	}
+	header.Set("X-Replay-Of", rec.ID)
+
+	route := rec.Request.Path
+	if rec.Request.Query != "" {
+		route += "?" + rec.Request.Query
+	}
+
+	start := time.Now()
+	resp, err := r.client.Raw(ctx, rec.Request.Method, route, []byte(rec.Request.Body), header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replayed response: %w", err)
+	}
+
+	return &Result{
+		Status:         resp.StatusCode,
+		Header:         resp.Header,
+		Body:           string(body),
+		Duration:       time.Since(start),
+		CapturedStatus: rec.Response.Status,
+		StatusMatches:  resp.StatusCode == rec.Response.Status,
+	}, nil
+}
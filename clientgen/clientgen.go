@@ -0,0 +1,374 @@
+// Package clientgen generates typed clients for internal services from the openapi.Route table
+// the service registers its endpoints with, so callers use methods like
+// authclient.CheckAccess(ctx, req) instead of route strings and hand-written DTOs that drift
+// from the server. The table holds Go types, so generation runs as a small program:
+//
+//	// authapi/routes.go, shared by the server and the generator
+//	var Routes = []openapi.Route{
+//		{Method: "POST", Path: "/api/v1/auth/access", OperationID: "CheckAccess",
+//			Request: CheckAccessRequest{}, Response: AccessData{}},
+//		{Method: "GET", Path: "/api/v1/auth/users/:id", OperationID: "GetUser", Response: User{}},
+//	}
+//
+//	// authclient/gen/main.go
+//	func main() {
+//		clientgen.Main(clientgen.Config{Package: "authclient", Output: "client_gen.go",
+//			ParamTypes: map[string]string{"id": "uint64"}}, authapi.Routes)
+//	}
+//
+//	// authclient/doc.go
+//	//go:generate go run ./gen
+//
+// The generated client wraps any httpclient.MethodCaller, usually the service's ServiceClient,
+// so retries, breakers, header propagation and error decoding stay the same.
+package clientgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Masharah-Advisory/common/openapi"
+)
+
+// Config configures a generated client
+type Config struct {
+	// Package is the package name of the generated file, e.g. "authclient"
+	Package string
+	// ImportPath is the import path of that package, so types declared in it are not imported
+	ImportPath string
+	// Client is the name of the client type, defaults to "Client"
+	Client string
+	// Prefix is prepended to the route paths when they are relative to a router group, e.g.
+	// "/api/v1/auth"
+	Prefix string
+	// ParamTypes are the Go types of path parameters by name, e.g. {"id": "uint64"}; others
+	// are strings
+	ParamTypes map[string]string
+	// Output is the file Main writes, relative to the directory of the go:generate directive
+	Output string
+}
+
+var pathParam = regexp.MustCompile(`^[:*](\w+)$`)
+
+// Main generates the client and writes it to cfg.Output, exiting with status 1 on failure; call
+// it from the main function of a go:generate program
+func Main(cfg Config, routes []openapi.Route) {
+	if err := Write(cfg, routes); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// Write generates the client and writes it to cfg.Output
+func Write(cfg Config, routes []openapi.Route) error {
+	if cfg.Output == "" {
+		return fmt.Errorf("clientgen: no output file")
+	}
+	src, err := Generate(cfg, routes)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(cfg.Output, src, 0o644)
+}
+
+// Generate returns the formatted source of the client for routes
+func Generate(cfg Config, routes []openapi.Route) ([]byte, error) {
+	if cfg.Package == "" {
+		return nil, fmt.Errorf("clientgen: no package name")
+	}
+	if cfg.Client == "" {
+		cfg.Client = "Client"
+	}
+	g := &generator{cfg: cfg, imports: newImports()}
+
+	methods := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		name := methodName(route)
+		if methods[name] {
+			return nil, fmt.Errorf("clientgen: two routes generate method %s, set OperationID", name)
+		}
+		methods[name] = true
+		if err := g.method(name, route); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by clientgen. DO NOT EDIT.\n\npackage %s\n\n", cfg.Package)
+	out.WriteString(g.imports.block())
+	fmt.Fprintf(&out, `
+// %[1]s is the typed client of the service
+type %[1]s struct {
+	caller httpclient.MethodCaller
+}
+
+// New%[1]s wraps caller, usually a *httpclient.ServiceClient
+func New%[1]s(caller httpclient.MethodCaller) *%[1]s {
+	return &%[1]s{caller: caller}
+}
+`, cfg.Client)
+	out.Write(g.body.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("clientgen: generated invalid source: %w", err)
+	}
+	return src, nil
+}
+
+type generator struct {
+	cfg     Config
+	imports *imports
+	body    bytes.Buffer
+}
+
+// method writes the client method of route
+func (g *generator) method(name string, route openapi.Route) error {
+	method := strings.ToUpper(route.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	path := joinPath(g.cfg.Prefix, route.Path)
+
+	params := []string{"ctx context.Context"}
+	var options []string
+	taken := map[string]bool{"ctx": true, "req": true, "query": true, "opts": true, "c": true}
+	for _, seg := range strings.Split(path, "/") {
+		m := pathParam.FindStringSubmatch(seg)
+		if m == nil {
+			continue
+		}
+		if seg[0] == '*' {
+			return fmt.Errorf("clientgen: %s %s: wildcard parameters are not supported", method, path)
+		}
+		arg := paramName(m[1])
+		for taken[arg] {
+			arg += "Param"
+		}
+		taken[arg] = true
+		typ := g.cfg.ParamTypes[m[1]]
+		if typ == "" {
+			typ = "string"
+		}
+		params = append(params, arg+" "+typ)
+		options = append(options, fmt.Sprintf("httpclient.WithPathParam(%q, %s)", m[1], arg))
+	}
+	payload := "nil"
+	if route.Request != nil {
+		typ, err := g.typeExpr(reflect.TypeOf(route.Request))
+		if err != nil {
+			return fmt.Errorf("clientgen: %s %s request: %w", method, path, err)
+		}
+		params = append(params, "req "+typ)
+		payload = "req"
+	}
+	if route.Query != nil {
+		typ, err := g.typeExpr(reflect.TypeOf(route.Query))
+		if err != nil {
+			return fmt.Errorf("clientgen: %s %s query: %w", method, path, err)
+		}
+		params = append(params, "query "+typ)
+		options = append(options, "httpclient.WithQueryStruct(query)")
+	}
+	params = append(params, "opts ...httpclient.RequestOption")
+
+	result := ""
+	if route.Response != nil {
+		typ, err := g.typeExpr(reflect.TypeOf(route.Response))
+		if err != nil {
+			return fmt.Errorf("clientgen: %s %s response: %w", method, path, err)
+		}
+		if route.Paginated {
+			typ = g.imports.alias("github.com/Masharah-Advisory/common/dto") + ".PaginatedResponse[" + typ + "]"
+		}
+		result = typ
+	}
+
+	b := &g.body
+	fmt.Fprintf(b, "\n// %s calls %s %s", name, method, path)
+	if route.Summary != "" {
+		fmt.Fprintf(b, ": %s", oneLine(route.Summary))
+	}
+	b.WriteString("\n")
+	if route.Deprecated {
+		b.WriteString("//\n// Deprecated: the endpoint is deprecated.\n")
+	}
+	ret := "error"
+	if result != "" {
+		ret = "(" + result + ", error)"
+	}
+	fmt.Fprintf(b, "func (c *%s) %s(%s) %s {\n", g.cfg.Client, name, strings.Join(params, ", "), ret)
+	if len(options) > 0 {
+		// Caller options come last so they can override the generated ones
+		fmt.Fprintf(b, "\topts = append([]httpclient.RequestOption{%s}, opts...)\n", strings.Join(options, ", "))
+	}
+	if result != "" {
+		fmt.Fprintf(b, "\treturn httpclient.DoAs[%s](ctx, c.caller, %q, %q, %s, opts...)\n}\n", result, method, path, payload)
+		return nil
+	}
+	fmt.Fprintf(b, "\tresp, err := c.caller.Do(ctx, %q, %q, %s, opts...)\n", method, path, payload)
+	b.WriteString("\tif err != nil {\n\t\treturn err\n\t}\n\treturn httpclient.DecodeStandardResponse(resp, nil)\n}\n")
+	return nil
+}
+
+// typeExpr spells t in the generated file, importing the packages of named types
+func (g *generator) typeExpr(t reflect.Type) (string, error) {
+	if t.Name() != "" {
+		if strings.Contains(t.Name(), "[") {
+			return "", fmt.Errorf("generic type %s is not supported", t)
+		}
+		if t.PkgPath() == "" || t.PkgPath() == g.cfg.ImportPath {
+			return t.Name(), nil
+		}
+		return g.imports.alias(t.PkgPath()) + "." + t.Name(), nil
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		elem, err := g.typeExpr(t.Elem())
+		return "*" + elem, err
+	case reflect.Slice:
+		elem, err := g.typeExpr(t.Elem())
+		return "[]" + elem, err
+	case reflect.Array:
+		elem, err := g.typeExpr(t.Elem())
+		return "[" + strconv.Itoa(t.Len()) + "]" + elem, err
+	case reflect.Map:
+		key, err := g.typeExpr(t.Key())
+		if err != nil {
+			return "", err
+		}
+		elem, err := g.typeExpr(t.Elem())
+		return "map[" + key + "]" + elem, err
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "interface{}", nil
+		}
+	}
+	return "", fmt.Errorf("type %s is not supported, declare a named type", t)
+}
+
+// imports assigns the packages used by the generated file unique names
+type imports struct {
+	byPath  map[string]string
+	aliases map[string]bool
+}
+
+func newImports() *imports {
+	im := &imports{byPath: make(map[string]string), aliases: make(map[string]bool)}
+	im.alias("context")
+	im.alias("github.com/Masharah-Advisory/common/httpclient")
+	return im
+}
+
+func (im *imports) alias(path string) string {
+	if a, ok := im.byPath[path]; ok {
+		return a
+	}
+	base := path[strings.LastIndex(path, "/")+1:]
+	base = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, base)
+	a := base
+	for i := 2; im.aliases[a]; i++ {
+		a = base + strconv.Itoa(i)
+	}
+	im.aliases[a] = true
+	im.byPath[path] = a
+	return a
+}
+
+func (im *imports) block() string {
+	paths := make([]string, 0, len(im.byPath))
+	for p := range im.byPath {
+		paths = append(paths, p)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		si, sj := !strings.Contains(paths[i], "."), !strings.Contains(paths[j], ".")
+		if si != sj {
+			return si
+		}
+		return paths[i] < paths[j]
+	})
+	var b strings.Builder
+	b.WriteString("import (\n")
+	for i, p := range paths {
+		// Standard library first, as goimports groups them
+		if i > 0 && !strings.Contains(paths[i-1], ".") && strings.Contains(p, ".") {
+			b.WriteString("\n")
+		}
+		if a := im.byPath[p]; a != p[strings.LastIndex(p, "/")+1:] {
+			fmt.Fprintf(&b, "\t%s %q\n", a, p)
+			continue
+		}
+		fmt.Fprintf(&b, "\t%q\n", p)
+	}
+	b.WriteString(")\n")
+	return b.String()
+}
+
+var servicePrefix = regexp.MustCompile(`^/?api/v\d+/[^/]+`)
+
+// methodName is the exported form of the operation ID, or of the method and path without one,
+// e.g. GET /api/v1/cases/cases/:id becomes GetCasesID
+func methodName(route openapi.Route) string {
+	id := route.OperationID
+	if id == "" {
+		id = strings.ToLower(route.Method) + "_" + servicePrefix.ReplaceAllString(route.Path, "")
+	}
+	return camel(id, true)
+}
+
+// paramName turns a path parameter into an argument name, e.g. user_id becomes userID
+func paramName(name string) string {
+	return camel(name, false)
+}
+
+var initialisms = map[string]string{
+	"id": "ID", "ids": "IDs", "url": "URL", "uri": "URI", "api": "API", "http": "HTTP",
+	"json": "JSON", "uuid": "UUID", "ip": "IP", "sms": "SMS", "otp": "OTP",
+}
+
+// camel joins the words of s, split on anything but letters and digits, in camel case;
+// words already in mixed case keep it
+func camel(s string, exported bool) string {
+	words := strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	var b strings.Builder
+	for i, w := range words {
+		switch {
+		case i == 0 && !exported:
+			b.WriteString(strings.ToLower(w[:1]) + w[1:])
+		case initialisms[strings.ToLower(w)] != "":
+			b.WriteString(initialisms[strings.ToLower(w)])
+		default:
+			b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+		}
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "Call" + name
+	}
+	return name
+}
+
+func joinPath(base, path string) string {
+	if base == "" || base == "/" {
+		return "/" + strings.TrimPrefix(path, "/")
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
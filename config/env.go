@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// Environment is the deployment environment a service is running in
+type Environment string
+
+const (
+	Development Environment = "development"
+	Staging     Environment = "staging"
+	Production  Environment = "production"
+)
+
+var (
+	env     Environment
+	envOnce sync.Once
+	envMu   sync.RWMutex
+)
+
+// Env returns the current environment read from APP_ENV (or ENV).
+// Unknown or missing values resolve to Production so that nothing verbose
+// or lax is enabled by accident.
+func Env() Environment {
+	envOnce.Do(func() {
+		value := os.Getenv("APP_ENV")
+		if value == "" {
+			value = os.Getenv("ENV")
+		}
+		envMu.Lock()
+		env = ParseEnv(value)
+		envMu.Unlock()
+	})
+
+	envMu.RLock()
+	defer envMu.RUnlock()
+	return env
+}
+
+// SetEnv overrides the detected environment (useful for tests and tooling)
+func SetEnv(e Environment) {
+	envOnce.Do(func() {})
+	envMu.Lock()
+	env = e
+	envMu.Unlock()
+}
+
+// ParseEnv normalizes an environment name, e.g. "dev", "local" -> Development
+func ParseEnv(value string) Environment {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "development", "dev", "local":
+		return Development
+	case "staging", "stage", "uat", "test":
+		return Staging
+	default:
+		return Production
+	}
+}
+
+// String returns the environment name
+func (e Environment) String() string {
+	return string(e)
+}
+
+// IsProduction reports whether the service runs in production
+func IsProduction() bool {
+	return Env() == Production
+}
+
+// IsStaging reports whether the service runs in staging
+func IsStaging() bool {
+	return Env() == Staging
+}
+
+// IsDevelopment reports whether the service runs in development
+func IsDevelopment() bool {
+	return Env() == Development
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ValidatorFunc checks one piece of configuration or one dependency
+type ValidatorFunc func(ctx context.Context) error
+
+// CheckResult is the outcome of a single validator
+type CheckResult struct {
+	Name     string        `json:"name"`
+	OK       bool          `json:"ok"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Report aggregates the outcome of all registered validators
+type Report struct {
+	OK      bool          `json:"ok"`
+	Env     Environment   `json:"env"`
+	Results []CheckResult `json:"results"`
+}
+
+var (
+	validators   = make(map[string]ValidatorFunc)
+	validatorsMu sync.RWMutex
+)
+
+// RegisterValidator registers a named validator run by Validate; registering the same name twice replaces it
+func RegisterValidator(name string, fn ValidatorFunc) {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	validators[name] = fn
+}
+
+// Validate runs all registered validators concurrently and returns one aggregated report.
+// The returned error joins every failure and is nil when all checks pass.
+func Validate(ctx context.Context) (*Report, error) {
+	validatorsMu.RLock()
+	names := make([]string, 0, len(validators))
+	for name := range validators {
+		names = append(names, name)
+	}
+	fns := make(map[string]ValidatorFunc, len(validators))
+	for name, fn := range validators {
+		fns[name] = fn
+	}
+	validatorsMu.RUnlock()
+
+	sort.Strings(names)
+
+	results := make([]CheckResult, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			results[i] = runValidator(ctx, name, fns[name])
+		}(i, name)
+	}
+	wg.Wait()
+
+	report := &Report{OK: true, Env: Env(), Results: results}
+	var errs []error
+	for _, r := range results {
+		if !r.OK {
+			report.OK = false
+			errs = append(errs, fmt.Errorf("%s: %s", r.Name, r.Error))
+		}
+	}
+
+	return report, errors.Join(errs...)
+}
+
+// runValidator executes a validator, converting panics into failures
+func runValidator(ctx context.Context, name string, fn ValidatorFunc) (result CheckResult) {
+	start := time.Now()
+	result.Name = name
+
+	defer func() {
+		if r := recover(); r != nil {
+			result.OK = false
+			result.Error = fmt.Sprintf("panic: %v", r)
+		}
+		result.Duration = time.Since(start)
+	}()
+
+	if err := fn(ctx); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.OK = true
+	return result
+}
+
+// String renders the report as a human readable multi-line summary
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "configuration report (env=%s): ", r.Env)
+	if r.OK {
+		b.WriteString("OK")
+	} else {
+		b.WriteString("FAILED")
+	}
+	for _, res := range r.Results {
+		status := "ok"
+		if !res.OK {
+			status = "FAIL: " + res.Error
+		}
+		fmt.Fprintf(&b, "\n  - %s [%s] %s", res.Name, res.Duration.Round(time.Millisecond), status)
+	}
+	return b.String()
+}
+
+// RequireEnv returns a validator that fails when any of the given environment variables is empty
+func RequireEnv(names ...string) ValidatorFunc {
+	return func(ctx context.Context) error {
+		var missing []string
+		for _, name := range names {
+			if os.Getenv(name) == "" {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("missing required environment variables: %s", strings.Join(missing, ", "))
+		}
+		return nil
+	}
+}
+
+// RequireURLs returns a validator that fails when any of the given values is not an absolute http(s) URL
+func RequireURLs(urls map[string]string) ValidatorFunc {
+	return func(ctx context.Context) error {
+		var errs []error
+		for name, raw := range urls {
+			if err := checkURL(raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// checkURL validates a single base URL
+func checkURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %w", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid URL %q: scheme must be http or https", raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid URL %q: missing host", raw)
+	}
+	return nil
+}
+
+// ReadinessHandler runs Validate and responds 200 when every check passes, 503 otherwise.
+// Error details are hidden in production.
+func ReadinessHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := Validate(c.Request.Context())
+
+		if IsProduction() {
+			for i := range report.Results {
+				if report.Results[i].Error != "" {
+					report.Results[i].Error = "check failed"
+				}
+			}
+		}
+
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"success": false,
+				"data":    report,
+				"message": "Service not ready",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data":    report,
+			"message": "Service ready",
+		})
+	}
+}
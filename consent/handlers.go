@@ -0,0 +1,224 @@
+package consent
+
+import (
+	"strconv"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/dto"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Handlers exposes the current documents and the current user's acceptances, plus compliance
+// queries for administrators
+type Handlers struct {
+	store *Store
+}
+
+// NewHandlers creates the handlers
+func NewHandlers(store *Store) *Handlers {
+	return &Handlers{store: store}
+}
+
+// Register mounts the user endpoints on a router group, e.g. /consent
+func (h *Handlers) Register(rg *gin.RouterGroup) {
+	rg.GET("/documents", h.Current)
+	rg.GET("/documents/:ref", h.Document)
+	rg.GET("/pending", h.Pending)
+	rg.GET("/me", h.Mine)
+	rg.POST("/documents/:ref/accept", h.Accept)
+	rg.POST("/documents/:ref/withdraw", h.Withdraw)
+}
+
+// RegisterAdmin mounts the publishing and compliance endpoints; protect the group with a permission
+func (h *Handlers) RegisterAdmin(rg *gin.RouterGroup) {
+	rg.POST("/documents", h.Publish)
+	rg.GET("/acceptances", h.List)
+	rg.GET("/acceptances/export", h.Export)
+	rg.GET("/users/:user_id", h.UserHistory)
+}
+
+// Current returns the latest version of every document
+func (h *Handlers) Current(c *gin.Context) {
+	docs, err := h.store.Current(c.Request.Context())
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if docs == nil {
+		docs = []Document{}
+	}
+	response.OK(c, docs)
+}
+
+// Document returns one document version, e.g. /documents/terms_v3
+func (h *Handlers) Document(c *gin.Context) {
+	d, err := h.store.Document(c.Request.Context(), c.Param("ref"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, d)
+}
+
+// Pending returns the required documents the current user still has to accept
+func (h *Handlers) Pending(c *gin.Context) {
+	userID, ok := ctxutil.UserID(c)
+	if !ok {
+		response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
+		return
+	}
+	docs, err := h.store.Pending(c.Request.Context(), userID)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if docs == nil {
+		docs = []Document{}
+	}
+	response.OK(c, docs)
+}
+
+// Mine returns the current user's acceptance history
+func (h *Handlers) Mine(c *gin.Context) {
+	userID, ok := ctxutil.UserID(c)
+	if !ok {
+		response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
+		return
+	}
+	items, err := h.store.History(c.Request.Context(), userID)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if items == nil {
+		items = []Acceptance{}
+	}
+	response.OK(c, items)
+}
+
+// Accept records that the current user accepted a document, with their IP and user agent
+func (h *Handlers) Accept(c *gin.Context) {
+	userID, ok := ctxutil.UserID(c)
+	if !ok {
+		response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
+		return
+	}
+	a, err := h.store.Accept(c.Request.Context(), AcceptInput{
+		TenantID:  ctxutil.TenantID(c),
+		UserID:    userID,
+		Ref:       c.Param("ref"),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.Created(c, a)
+}
+
+// Withdraw withdraws the current user's acceptance of a document
+func (h *Handlers) Withdraw(c *gin.Context) {
+	userID, ok := ctxutil.UserID(c)
+	if !ok {
+		response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
+		return
+	}
+	if err := h.store.Withdraw(c.Request.Context(), userID, c.Param("ref")); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+// UserHistory returns one user's acceptance history, including withdrawn acceptances
+func (h *Handlers) UserHistory(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	items, err := h.store.History(c.Request.Context(), userID)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if items == nil {
+		items = []Acceptance{}
+	}
+	response.OK(c, items)
+}
+
+// Publish publishes a new document version
+func (h *Handlers) Publish(c *gin.Context) {
+	var in DocumentInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.BadRequest(c, i18n.T(c, "consent.invalid_request"), response.ProcessBindingError(c, err))
+		return
+	}
+	d, err := h.store.Publish(c.Request.Context(), in)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.Created(c, d)
+}
+
+// List pages through the tenant's acceptances filtered by the query parameters
+func (h *Handlers) List(c *gin.Context) {
+	q, ok := bindQuery(c)
+	if !ok {
+		return
+	}
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Limit < 1 || q.Limit > 100 {
+		q.Limit = 20
+	}
+	items, total, err := h.store.List(c.Request.Context(), q)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, dto.BuildPaginatedResponse(items, total, q.Page, q.Limit))
+}
+
+// Export streams the tenant's acceptances matching the query parameters as CSV
+func (h *Handlers) Export(c *gin.Context) {
+	q, ok := bindQuery(c)
+	if !ok {
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="consent-acceptances.csv"`)
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	if err := h.store.WriteCSV(c.Request.Context(), c.Writer, q); err != nil {
+		// Headers may already be sent; record the error for the logger middleware
+		_ = c.Error(err)
+		if !c.Writer.Written() {
+			response.HandleError(c, err)
+		}
+	}
+}
+
+func bindQuery(c *gin.Context) (Query, bool) {
+	var q Query
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.BadRequest(c, i18n.T(c, "consent.invalid_request"), response.ProcessBindingError(c, err))
+		return q, false
+	}
+	q.TenantID = ctxutil.TenantID(c)
+	return q, true
+}
+
+// userIDParam parses the :user_id path parameter
+func userIDParam(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, i18n.T(c, "consent.invalid_request"))
+		return 0, false
+	}
+	return id, true
+}
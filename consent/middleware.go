@@ -0,0 +1,47 @@
+package consent
+
+import (
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// RequiredHeader names the documents a request was refused for, so front-ends can show them
+// and call the accept endpoint before retrying
+const RequiredHeader = "X-Consent-Required"
+
+// RequireConsent refuses requests with 403 consent_required until the authenticated user has
+// accepted every listed document, e.g. RequireConsent("terms_v3"); bumping the reference to
+// "terms_v4" forces everyone to accept again. Place it after the auth middleware.
+func (s *Store) RequireConsent(refs ...string) gin.HandlerFunc {
+	for _, ref := range refs {
+		if _, _, ok := ParseRef(ref); !ok {
+			panic("consent: invalid document reference " + ref)
+		}
+	}
+	return func(c *gin.Context) {
+		userID, ok := ctxutil.UserID(c)
+		if !ok {
+			response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
+			c.Abort()
+			return
+		}
+
+		for _, ref := range refs {
+			accepted, err := s.HasAccepted(c.Request.Context(), userID, ref)
+			if err != nil {
+				response.HandleError(c, err)
+				c.Abort()
+				return
+			}
+			if !accepted {
+				c.Header(RequiredHeader, ref)
+				response.HandleError(c, ErrConsentRequired.Clone().WithMeta("Document", ref))
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,76 @@
+package consent
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/model"
+)
+
+// Common document kinds; services may define their own, e.g. "marketing"
+const (
+	KindTerms   = "terms"
+	KindPrivacy = "privacy"
+)
+
+// Document is one published version of a legal text users accept, e.g. terms v3. Its Ref
+// ("terms_v3") is what RequireConsent and the accept endpoint take.
+type Document struct {
+	model.Base
+	Kind        string     `json:"kind" gorm:"uniqueIndex:idx_consent_document;size:64;not null"`
+	Version     string     `json:"version" gorm:"uniqueIndex:idx_consent_document;size:32;not null"`
+	Title       string     `json:"title" gorm:"size:255;not null"`
+	Body        string     `json:"body,omitempty" gorm:"type:text"`
+	URL         string     `json:"url,omitempty" gorm:"size:1024"`
+	Required    bool       `json:"required"`
+	PublishedAt *time.Time `json:"published_at,omitempty" gorm:"index"`
+}
+
+// TableName overrides the table name
+func (Document) TableName() string {
+	return "consent_documents"
+}
+
+// Ref returns the document reference, e.g. "terms_v3"
+func (d *Document) Ref() string {
+	return Ref(d.Kind, d.Version)
+}
+
+// Acceptance records that a user accepted a document version, with where and when for audits.
+// A withdrawn acceptance is kept so exports still show the full history.
+type Acceptance struct {
+	ID          uint64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID    string     `json:"tenant_id,omitempty" gorm:"index;size:64"`
+	UserID      uint64     `json:"user_id" gorm:"index:idx_consent_acceptance;not null"`
+	Kind        string     `json:"kind" gorm:"index:idx_consent_acceptance;size:64;not null"`
+	Version     string     `json:"version" gorm:"index:idx_consent_acceptance;size:32;not null"`
+	AcceptedAt  time.Time  `json:"accepted_at" gorm:"index;not null"`
+	IP          string     `json:"ip,omitempty" gorm:"size:64"`
+	UserAgent   string     `json:"user_agent,omitempty" gorm:"size:512"`
+	WithdrawnAt *time.Time `json:"withdrawn_at,omitempty"`
+}
+
+// TableName overrides the table name
+func (Acceptance) TableName() string {
+	return "consent_acceptances"
+}
+
+// Ref returns the reference of the accepted document
+func (a *Acceptance) Ref() string {
+	return Ref(a.Kind, a.Version)
+}
+
+// Ref builds a document reference from its kind and version, e.g. Ref("terms", "v3") is "terms_v3"
+func Ref(kind, version string) string {
+	return kind + "_" + version
+}
+
+// ParseRef splits a reference into its kind and version at the last underscore, so kinds may
+// contain underscores ("data_sharing_v2")
+func ParseRef(ref string) (kind, version string, ok bool) {
+	i := strings.LastIndexByte(ref, '_')
+	if i <= 0 || i == len(ref)-1 {
+		return "", "", false
+	}
+	return ref[:i], ref[i+1:], true
+}
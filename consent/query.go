@@ -0,0 +1,136 @@
+package consent
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Query filters acceptances for compliance reviews; zero values are ignored
+type Query struct {
+	TenantID string    `form:"-"`
+	UserID   uint64    `form:"user_id"`
+	Kind     string    `form:"kind"`
+	Version  string    `form:"version"`
+	From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	// Withdrawn includes withdrawn acceptances
+	Withdrawn bool `form:"withdrawn"`
+	Page      int  `form:"page"`
+	Limit     int  `form:"limit"`
+}
+
+// List returns a page of acceptances matching q, newest first
+func (s *Store) List(ctx context.Context, q Query) ([]Acceptance, int64, error) {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Limit < 1 || q.Limit > 100 {
+		q.Limit = 20
+	}
+
+	tx := s.filter(ctx, q)
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count consent acceptances: %w", err)
+	}
+	var items []Acceptance
+	if err := tx.Order("accepted_at DESC, id DESC").Offset((q.Page - 1) * q.Limit).Limit(q.Limit).Find(&items).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list consent acceptances: %w", err)
+	}
+	return items, total, nil
+}
+
+// History returns every acceptance of one user, including withdrawn ones, oldest first, e.g. for
+// a data-subject export
+func (s *Store) History(ctx context.Context, userID uint64) ([]Acceptance, error) {
+	var items []Acceptance
+	err := s.cfg.DB.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("accepted_at, id").
+		Find(&items).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load consent history: %w", err)
+	}
+	return items, nil
+}
+
+// Each calls fn for every acceptance matching q in batches, ignoring paging, for exports too
+// large to hold in memory
+func (s *Store) Each(ctx context.Context, q Query, fn func(Acceptance) error) error {
+	var batch []Acceptance
+	res := s.filter(ctx, q).Order("id").FindInBatches(&batch, 500, func(_ *gorm.DB, _ int) error {
+		for _, a := range batch {
+			if err := fn(a); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if res.Error != nil {
+		return fmt.Errorf("failed to export consent acceptances: %w", res.Error)
+	}
+	return nil
+}
+
+// WriteCSV writes every acceptance matching q as CSV for regulators and auditors
+func (s *Store) WriteCSV(ctx context.Context, w io.Writer, q Query) error {
+	// The BOM makes Excel open UTF-8 CSV files correctly
+	if _, err := w.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"id", "tenant_id", "user_id", "document", "accepted_at", "ip", "user_agent", "withdrawn_at"})
+	err := s.Each(ctx, q, func(a Acceptance) error {
+		withdrawn := ""
+		if a.WithdrawnAt != nil {
+			withdrawn = a.WithdrawnAt.UTC().Format(time.RFC3339)
+		}
+		return cw.Write([]string{
+			strconv.FormatUint(a.ID, 10),
+			a.TenantID,
+			strconv.FormatUint(a.UserID, 10),
+			a.Ref(),
+			a.AcceptedAt.UTC().Format(time.RFC3339),
+			a.IP,
+			a.UserAgent,
+			withdrawn,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (s *Store) filter(ctx context.Context, q Query) *gorm.DB {
+	tx := s.cfg.DB.WithContext(ctx).Model(&Acceptance{})
+	if q.TenantID != "" {
+		tx = tx.Where("tenant_id = ?", q.TenantID)
+	}
+	if q.UserID != 0 {
+		tx = tx.Where("user_id = ?", q.UserID)
+	}
+	if q.Kind != "" {
+		tx = tx.Where("kind = ?", q.Kind)
+	}
+	if q.Version != "" {
+		tx = tx.Where("version = ?", q.Version)
+	}
+	if !q.From.IsZero() {
+		tx = tx.Where("accepted_at >= ?", q.From)
+	}
+	if !q.To.IsZero() {
+		tx = tx.Where("accepted_at < ?", q.To)
+	}
+	if !q.Withdrawn {
+		tx = tx.Where("withdrawn_at IS NULL")
+	}
+	return tx
+}
@@ -0,0 +1,259 @@
+package consent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/cache"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Errors returned by the store
+var (
+	ErrDocumentNotFound = apperror.New("consent_document_not_found", apperror.KindNotFound, "consent document not found").
+				WithKey("consent.document_not_found")
+	ErrVersionExists = apperror.New("consent_version_exists", apperror.KindConflict, "this document version is already published").
+				WithKey("consent.version_exists")
+	ErrInvalidRef = apperror.New("consent_invalid_ref", apperror.KindBadRequest, "invalid document reference").
+			WithKey("consent.invalid_ref")
+	ErrConsentRequired = apperror.New("consent_required", apperror.KindForbidden, "the document must be accepted first").
+				WithKey("consent.required")
+)
+
+// DocumentInput is a new document version
+type DocumentInput struct {
+	Kind     string `json:"kind" binding:"required,max=64"`
+	Version  string `json:"version" binding:"required,max=32"`
+	Title    string `json:"title" binding:"required,max=255"`
+	Body     string `json:"body"`
+	URL      string `json:"url" binding:"omitempty,url,max=1024"`
+	Required bool   `json:"required"`
+}
+
+// AcceptInput describes one acceptance; IP and UserAgent come from the request
+type AcceptInput struct {
+	TenantID  string
+	UserID    uint64
+	Ref       string
+	IP        string
+	UserAgent string
+}
+
+// Config configures a Store
+type Config struct {
+	DB *gorm.DB
+	// Cache remembers which documents each user accepted so RequireConsent does not query on
+	// every request (optional), e.g. cache.Config{Name: "consent", L1TTL: time.Minute, TTL: time.Hour}
+	Cache *cache.Cache[bool]
+}
+
+// Store manages document versions and users' acceptances
+type Store struct {
+	cfg *Config
+	now func() time.Time
+}
+
+// NewStore creates a store; migrate Document and Acceptance first
+func NewStore(cfg *Config) *Store {
+	return &Store{cfg: cfg, now: time.Now}
+}
+
+// Publish stores a new document version; versions are immutable once published
+func (s *Store) Publish(ctx context.Context, in DocumentInput) (*Document, error) {
+	if _, _, ok := ParseRef(Ref(in.Kind, in.Version)); !ok {
+		return nil, ErrInvalidRef
+	}
+	now := s.now()
+	d := &Document{
+		Kind:        in.Kind,
+		Version:     in.Version,
+		Title:       in.Title,
+		Body:        in.Body,
+		URL:         in.URL,
+		Required:    in.Required,
+		PublishedAt: &now,
+	}
+	res := s.cfg.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(d)
+	if res.Error != nil {
+		return nil, fmt.Errorf("failed to publish consent document: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrVersionExists
+	}
+	return d, nil
+}
+
+// Document returns the document identified by ref, e.g. "terms_v3"
+func (s *Store) Document(ctx context.Context, ref string) (*Document, error) {
+	kind, version, ok := ParseRef(ref)
+	if !ok {
+		return nil, ErrInvalidRef
+	}
+	var d Document
+	err := s.cfg.DB.WithContext(ctx).
+		Where("kind = ? AND version = ? AND deleted_at IS NULL", kind, version).
+		First(&d).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDocumentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load consent document: %w", err)
+	}
+	return &d, nil
+}
+
+// Latest returns the most recently published version of a kind
+func (s *Store) Latest(ctx context.Context, kind string) (*Document, error) {
+	var d Document
+	err := s.cfg.DB.WithContext(ctx).
+		Where("kind = ? AND deleted_at IS NULL AND published_at IS NOT NULL", kind).
+		Order("published_at DESC, id DESC").
+		First(&d).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDocumentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load consent document: %w", err)
+	}
+	return &d, nil
+}
+
+// Current returns the latest published version of every kind
+func (s *Store) Current(ctx context.Context) ([]Document, error) {
+	var docs []Document
+	err := s.cfg.DB.WithContext(ctx).
+		Where("deleted_at IS NULL AND published_at IS NOT NULL").
+		Order("kind, published_at DESC, id DESC").
+		Find(&docs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load consent documents: %w", err)
+	}
+	out := docs[:0]
+	for _, d := range docs {
+		if len(out) == 0 || out[len(out)-1].Kind != d.Kind {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+// Accept records that a user accepted a document; accepting the same version again keeps the
+// original record
+func (s *Store) Accept(ctx context.Context, in AcceptInput) (*Acceptance, error) {
+	d, err := s.Document(ctx, in.Ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing Acceptance
+	err = s.cfg.DB.WithContext(ctx).
+		Where("user_id = ? AND kind = ? AND version = ? AND withdrawn_at IS NULL", in.UserID, d.Kind, d.Version).
+		First(&existing).Error
+	if err == nil {
+		return &existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load consent acceptance: %w", err)
+	}
+
+	a := &Acceptance{
+		TenantID:   in.TenantID,
+		UserID:     in.UserID,
+		Kind:       d.Kind,
+		Version:    d.Version,
+		AcceptedAt: s.now().UTC(),
+		IP:         in.IP,
+		UserAgent:  truncate(in.UserAgent, 512),
+	}
+	if err := s.cfg.DB.WithContext(ctx).Create(a).Error; err != nil {
+		return nil, fmt.Errorf("failed to record consent acceptance: %w", err)
+	}
+	s.invalidate(ctx, in.UserID, d.Ref())
+	return a, nil
+}
+
+// Withdraw marks the user's acceptance of ref as withdrawn, e.g. when they opt out of
+// marketing; the record itself is kept
+func (s *Store) Withdraw(ctx context.Context, userID uint64, ref string) error {
+	kind, version, ok := ParseRef(ref)
+	if !ok {
+		return ErrInvalidRef
+	}
+	res := s.cfg.DB.WithContext(ctx).Model(&Acceptance{}).
+		Where("user_id = ? AND kind = ? AND version = ? AND withdrawn_at IS NULL", userID, kind, version).
+		Update("withdrawn_at", s.now().UTC())
+	if res.Error != nil {
+		return fmt.Errorf("failed to withdraw consent: %w", res.Error)
+	}
+	s.invalidate(ctx, userID, ref)
+	return nil
+}
+
+// HasAccepted reports whether the user currently accepts ref
+func (s *Store) HasAccepted(ctx context.Context, userID uint64, ref string) (bool, error) {
+	kind, version, ok := ParseRef(ref)
+	if !ok {
+		return false, ErrInvalidRef
+	}
+	load := func(ctx context.Context) (bool, error) {
+		var count int64
+		err := s.cfg.DB.WithContext(ctx).Model(&Acceptance{}).
+			Where("user_id = ? AND kind = ? AND version = ? AND withdrawn_at IS NULL", userID, kind, version).
+			Count(&count).Error
+		if err != nil {
+			return false, fmt.Errorf("failed to check consent: %w", err)
+		}
+		return count > 0, nil
+	}
+	if s.cfg.Cache == nil {
+		return load(ctx)
+	}
+	return s.cfg.Cache.GetOrLoad(ctx, cacheKey(userID, ref), load)
+}
+
+// Pending returns the current required documents the user has not accepted, for prompting
+// after login
+func (s *Store) Pending(ctx context.Context, userID uint64) ([]Document, error) {
+	docs, err := s.Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []Document
+	for _, d := range docs {
+		if !d.Required {
+			continue
+		}
+		ok, err := s.HasAccepted(ctx, userID, d.Ref())
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+// invalidate drops the cached answer after an acceptance changes
+func (s *Store) invalidate(ctx context.Context, userID uint64, ref string) {
+	if s.cfg.Cache == nil {
+		return
+	}
+	_ = s.cfg.Cache.Delete(ctx, cacheKey(userID, ref))
+}
+
+func cacheKey(userID uint64, ref string) string {
+	return "user:" + strconv.FormatUint(userID, 10) + ":" + ref
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
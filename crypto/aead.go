@@ -0,0 +1,199 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrUnknownKey is returned when a ciphertext references a key version the keyring does not hold
+	ErrUnknownKey = errors.New("unknown encryption key version")
+	// ErrDecrypt is returned when a ciphertext is malformed or fails authentication
+	ErrDecrypt = errors.New("failed to decrypt")
+)
+
+// versionSize is the big-endian key version prefixed to every ciphertext
+const versionSize = 4
+
+// Keyring holds versioned AES-256-GCM keys. New data is always sealed with the primary key;
+// older keys stay available for decryption so keys can be rotated without re-encrypting everything at once.
+type Keyring struct {
+	mu      sync.RWMutex
+	primary uint32
+	aeads   map[uint32]cipher.AEAD
+}
+
+// NewKeyring creates a keyring from 32-byte keys indexed by version
+func NewKeyring(primary uint32, keys map[uint32][]byte) (*Keyring, error) {
+	k := &Keyring{aeads: make(map[uint32]cipher.AEAD, len(keys))}
+	for version, key := range keys {
+		if err := k.Add(version, key); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := k.aeads[primary]; !ok {
+		return nil, fmt.Errorf("%w: primary %d", ErrUnknownKey, primary)
+	}
+	k.primary = primary
+	return k, nil
+}
+
+// KeyringFromEnv reads ENCRYPTION_KEYS ("1:<base64 key>,2:<base64 key>") and ENCRYPTION_PRIMARY_KEY,
+// which defaults to the highest version
+func KeyringFromEnv() (*Keyring, error) {
+	raw := os.Getenv("ENCRYPTION_KEYS")
+	if raw == "" {
+		return nil, fmt.Errorf("ENCRYPTION_KEYS is not set")
+	}
+
+	keys := make(map[uint32][]byte)
+	var highest uint32
+	for _, entry := range strings.Split(raw, ",") {
+		versionStr, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid ENCRYPTION_KEYS entry, expected version:key")
+		}
+		version, err := strconv.ParseUint(strings.TrimPrefix(versionStr, "v"), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ENCRYPTION_KEYS version %q: %w", versionStr, err)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ENCRYPTION_KEYS key for version %d: %w", version, err)
+		}
+		keys[uint32(version)] = key
+		if uint32(version) > highest {
+			highest = uint32(version)
+		}
+	}
+
+	primary := highest
+	if v := os.Getenv("ENCRYPTION_PRIMARY_KEY"); v != "" {
+		p, err := strconv.ParseUint(strings.TrimPrefix(v, "v"), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ENCRYPTION_PRIMARY_KEY: %w", err)
+		}
+		primary = uint32(p)
+	}
+	return NewKeyring(primary, keys)
+}
+
+// Add registers a key version; it does not change the primary key
+func (k *Keyring) Add(version uint32, key []byte) error {
+	if len(key) != 32 {
+		return fmt.Errorf("encryption key %d must be 32 bytes, got %d", version, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.aeads[version] = aead
+	return nil
+}
+
+// SetPrimary switches the key used for new ciphertexts
+func (k *Keyring) SetPrimary(version uint32) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.aeads[version]; !ok {
+		return fmt.Errorf("%w: %d", ErrUnknownKey, version)
+	}
+	k.primary = version
+	return nil
+}
+
+// Primary returns the version of the key used for new ciphertexts
+func (k *Keyring) Primary() uint32 {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.primary
+}
+
+// Encrypt seals plaintext with the primary key. aad is authenticated but not encrypted; pass
+// e.g. the row ID to bind a ciphertext to its record. Output is version | nonce | ciphertext.
+func (k *Keyring) Encrypt(plaintext, aad []byte) ([]byte, error) {
+	k.mu.RLock()
+	version := k.primary
+	aead := k.aeads[version]
+	k.mu.RUnlock()
+
+	nonce, err := RandomBytes(aead.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, versionSize, versionSize+len(nonce)+len(plaintext)+aead.Overhead())
+	binary.BigEndian.PutUint32(out, version)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, aad), nil
+}
+
+// Decrypt opens a ciphertext produced by Encrypt with whichever key version sealed it
+func (k *Keyring) Decrypt(ciphertext, aad []byte) ([]byte, error) {
+	if len(ciphertext) < versionSize {
+		return nil, ErrDecrypt
+	}
+	version := binary.BigEndian.Uint32(ciphertext)
+
+	k.mu.RLock()
+	aead, ok := k.aeads[version]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownKey, version)
+	}
+
+	body := ciphertext[versionSize:]
+	if len(body) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrDecrypt
+	}
+	plaintext, err := aead.Open(nil, body[:aead.NonceSize()], body[aead.NonceSize():], aad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// EncryptString is Encrypt for text columns, returning URL-safe base64
+func (k *Keyring) EncryptString(plaintext string) (string, error) {
+	out, err := k.Encrypt([]byte(plaintext), nil)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(out), nil
+}
+
+// DecryptString reverses EncryptString
+func (k *Keyring) DecryptString(ciphertext string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	out, err := k.Decrypt(raw, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// NeedsReencrypt reports whether ciphertext was sealed with a key other than the primary one
+func (k *Keyring) NeedsReencrypt(ciphertext []byte) bool {
+	if len(ciphertext) < versionSize {
+		return false
+	}
+	return binary.BigEndian.Uint32(ciphertext) != k.Primary()
+}
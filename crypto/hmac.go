@@ -0,0 +1,46 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+// HMAC returns the HMAC-SHA256 of data under key
+func HMAC(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// HMACHex returns the hex-encoded HMAC-SHA256 of data under key
+func HMACHex(key, data []byte) string {
+	return hex.EncodeToString(HMAC(key, data))
+}
+
+// VerifyHMAC reports in constant time whether mac is the HMAC-SHA256 of data under key
+func VerifyHMAC(key, data, mac []byte) bool {
+	return hmac.Equal(HMAC(key, data), mac)
+}
+
+// VerifyHMACHex is VerifyHMAC for a hex-encoded mac
+func VerifyHMACHex(key, data []byte, macHex string) bool {
+	mac, err := hex.DecodeString(macHex)
+	if err != nil {
+		return false
+	}
+	return VerifyHMAC(key, data, mac)
+}
+
+// Equal compares two secrets (API keys, tokens, OTPs) in constant time. Only the length of
+// the inputs may leak.
+func Equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// SHA256Hex returns the hex-encoded SHA-256 of data, e.g. to store lookup hashes of tokens
+func SHA256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,217 @@
+package crypto
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algorithm is a password hashing algorithm
+type Algorithm string
+
+const (
+	Argon2id Algorithm = "argon2id"
+	Bcrypt   Algorithm = "bcrypt"
+)
+
+var (
+	// ErrInvalidHash is returned when a stored hash cannot be parsed
+	ErrInvalidHash = errors.New("invalid password hash")
+	// ErrUnsupportedHash is returned for hashes produced by an unknown algorithm or version
+	ErrUnsupportedHash = errors.New("unsupported password hash")
+)
+
+// PasswordConfig holds the hashing parameters; PasswordConfigFromEnv fills it from PASSWORD_* variables
+type PasswordConfig struct {
+	Algorithm Algorithm
+
+	// argon2id parameters, memory in KiB
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+
+	BcryptCost int
+}
+
+// DefaultPasswordConfig returns argon2id with the OWASP recommended parameters (64 MiB, t=3, p=2)
+func DefaultPasswordConfig() PasswordConfig {
+	return PasswordConfig{
+		Algorithm:   Argon2id,
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 2,
+		SaltLength:  16,
+		KeyLength:   32,
+		BcryptCost:  12,
+	}
+}
+
+// PasswordConfigFromEnv reads PASSWORD_HASH_ALGORITHM, PASSWORD_ARGON2_MEMORY_KB,
+// PASSWORD_ARGON2_ITERATIONS, PASSWORD_ARGON2_PARALLELISM and PASSWORD_BCRYPT_COST over the defaults
+func PasswordConfigFromEnv() PasswordConfig {
+	cfg := DefaultPasswordConfig()
+	if v := os.Getenv("PASSWORD_HASH_ALGORITHM"); v != "" {
+		cfg.Algorithm = Algorithm(strings.ToLower(v))
+	}
+	if v, err := strconv.ParseUint(os.Getenv("PASSWORD_ARGON2_MEMORY_KB"), 10, 32); err == nil {
+		cfg.Memory = uint32(v)
+	}
+	if v, err := strconv.ParseUint(os.Getenv("PASSWORD_ARGON2_ITERATIONS"), 10, 32); err == nil {
+		cfg.Iterations = uint32(v)
+	}
+	if v, err := strconv.ParseUint(os.Getenv("PASSWORD_ARGON2_PARALLELISM"), 10, 8); err == nil {
+		cfg.Parallelism = uint8(v)
+	}
+	if v, err := strconv.Atoi(os.Getenv("PASSWORD_BCRYPT_COST")); err == nil {
+		cfg.BcryptCost = v
+	}
+	return cfg
+}
+
+// Hasher hashes and verifies passwords. Verify accepts both argon2id and bcrypt hashes so
+// services can migrate while NeedsRehash flags hashes to upgrade on the next login.
+type Hasher struct {
+	cfg PasswordConfig
+}
+
+// NewHasher creates a password hasher, nil uses DefaultPasswordConfig
+func NewHasher(cfg *PasswordConfig) *Hasher {
+	c := DefaultPasswordConfig()
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.Algorithm == "" {
+		c.Algorithm = Argon2id
+	}
+	if c.SaltLength == 0 {
+		c.SaltLength = 16
+	}
+	if c.KeyLength == 0 {
+		c.KeyLength = 32
+	}
+	if c.Parallelism == 0 {
+		c.Parallelism = 1
+	}
+	if c.BcryptCost == 0 {
+		c.BcryptCost = bcrypt.DefaultCost
+	}
+	return &Hasher{cfg: c}
+}
+
+// Hash returns the encoded hash of password: PHC format for argon2id, modular crypt for bcrypt
+func (h *Hasher) Hash(password string) (string, error) {
+	switch h.cfg.Algorithm {
+	case Argon2id:
+		salt, err := RandomBytes(int(h.cfg.SaltLength))
+		if err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, h.cfg.Iterations, h.cfg.Memory, h.cfg.Parallelism, h.cfg.KeyLength)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+			argon2.Version, h.cfg.Memory, h.cfg.Iterations, h.cfg.Parallelism,
+			base64.RawStdEncoding.EncodeToString(salt),
+			base64.RawStdEncoding.EncodeToString(key),
+		), nil
+	case Bcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cfg.BcryptCost)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash password: %w", err)
+		}
+		return string(hash), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedHash, h.cfg.Algorithm)
+	}
+}
+
+// Verify reports whether password matches the encoded hash
+func (h *Hasher) Verify(password, encoded string) (bool, error) {
+	if isBcrypt(encoded) {
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+		}
+		return true, nil
+	}
+
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// NeedsRehash reports whether encoded was produced with another algorithm or weaker parameters
+// than the current config
+func (h *Hasher) NeedsRehash(encoded string) bool {
+	if isBcrypt(encoded) {
+		if h.cfg.Algorithm != Bcrypt {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(encoded))
+		return err != nil || cost < h.cfg.BcryptCost
+	}
+
+	if h.cfg.Algorithm != Argon2id {
+		return true
+	}
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return params.Memory < h.cfg.Memory ||
+		params.Iterations < h.cfg.Iterations ||
+		params.Parallelism < h.cfg.Parallelism ||
+		uint32(len(salt)) < h.cfg.SaltLength ||
+		uint32(len(key)) < h.cfg.KeyLength
+}
+
+func isBcrypt(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+// decodeArgon2id parses $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+func decodeArgon2id(encoded string) (PasswordConfig, []byte, []byte, error) {
+	var params PasswordConfig
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" {
+		return params, nil, nil, ErrInvalidHash
+	}
+	if parts[1] != string(Argon2id) {
+		return params, nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedHash, parts[1])
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("%w: argon2 version %d", ErrUnsupportedHash, version)
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrInvalidHash
+	}
+	params.Algorithm = Argon2id
+	return params, salt, key, nil
+}
@@ -0,0 +1,69 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+)
+
+// Alphabets for RandomString
+const (
+	AlphaNumeric = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	// Unambiguous leaves out 0/O, 1/I/L so codes can be read out or typed from paper
+	Unambiguous = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+)
+
+// RandomBytes returns n bytes from the system CSPRNG
+func RandomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return b, nil
+}
+
+// Token returns a URL-safe base64 token with n bytes of entropy (32 is a good default)
+func Token(n int) (string, error) {
+	b, err := RandomBytes(n)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// TokenHex returns a hex token with n bytes of entropy
+func TokenHex(n int) (string, error) {
+	b, err := RandomBytes(n)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// OTP returns a numeric one-time code of the given length, uniformly distributed and keeping
+// leading zeros
+func OTP(digits int) (string, error) {
+	if digits <= 0 || digits > 18 {
+		return "", fmt.Errorf("invalid OTP length %d", digits)
+	}
+	return RandomString(digits, "0123456789")
+}
+
+// RandomString returns n characters drawn uniformly from alphabet
+func RandomString(n int, alphabet string) (string, error) {
+	if len(alphabet) == 0 {
+		return "", fmt.Errorf("empty alphabet")
+	}
+	max := big.NewInt(int64(len(alphabet)))
+	out := make([]byte, n)
+	for i := range out {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate random string: %w", err)
+		}
+		out[i] = alphabet[idx.Int64()]
+	}
+	return string(out), nil
+}
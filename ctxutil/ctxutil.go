@@ -0,0 +1,221 @@
+package ctxutil
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Keys used on gin contexts; they keep the historical names so c.Get("user_id") in existing
+// handlers still works, but new code should use the typed helpers below
+const (
+	UserIDKey    = "user_id"
+	TenantIDKey  = "tenant_id"
+	RequestIDKey = "request_id"
+	LangKey      = "lang"
+	TraceIDKey   = "trace_id"
+	HeadersKey   = "request_headers"
+	DeadlineKey  = "request_deadline"
+)
+
+// DeadlineHeader carries the caller's deadline between services, in Unix milliseconds
+const DeadlineHeader = "X-Request-Deadline"
+
+// key is the type of the keys used on standard contexts, so other packages cannot collide
+type key int
+
+const (
+	userIDKey key = iota
+	tenantIDKey
+	requestIDKey
+	langKey
+	traceIDKey
+	headersKey
+	deadlineKey
+)
+
+// names maps typed keys to their gin names
+var names = map[key]string{
+	userIDKey:    UserIDKey,
+	tenantIDKey:  TenantIDKey,
+	requestIDKey: RequestIDKey,
+	langKey:      LangKey,
+	traceIDKey:   TraceIDKey,
+	headersKey:   HeadersKey,
+	deadlineKey:  DeadlineKey,
+}
+
+// WithUserID returns ctx carrying the authenticated user's ID. On a *gin.Context the value is
+// set on the gin keys and on c.Request's context, so c.Request.Context() passed to
+// repositories carries it too; the same *gin.Context is returned.
+func WithUserID(ctx context.Context, id uint64) context.Context {
+	return with(ctx, userIDKey, id)
+}
+
+// UserID returns the authenticated user's ID, accepting the uint, int and string forms older
+// code stored
+func UserID(ctx context.Context) (uint64, bool) {
+	switch v := lookup(ctx, userIDKey).(type) {
+	case uint64:
+		return v, v != 0
+	case uint:
+		return uint64(v), v != 0
+	case uint32:
+		return uint64(v), v != 0
+	case int:
+		return uint64(v), v > 0
+	case int64:
+		return uint64(v), v > 0
+	case string:
+		id, err := strconv.ParseUint(v, 10, 64)
+		return id, err == nil && id != 0
+	}
+	return 0, false
+}
+
+// WithTenantID returns ctx carrying the tenant ID
+func WithTenantID(ctx context.Context, id string) context.Context {
+	return with(ctx, tenantIDKey, id)
+}
+
+// TenantID returns the tenant ID, or an empty string
+func TenantID(ctx context.Context) string {
+	return lookupString(ctx, tenantIDKey)
+}
+
+// WithRequestID returns ctx carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return with(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request ID, or an empty string
+func RequestID(ctx context.Context) string {
+	return lookupString(ctx, requestIDKey)
+}
+
+// WithLang returns ctx carrying the language negotiated for the request
+func WithLang(ctx context.Context, lang string) context.Context {
+	return with(ctx, langKey, lang)
+}
+
+// Lang returns the request language, or an empty string
+func Lang(ctx context.Context) string {
+	return lookupString(ctx, langKey)
+}
+
+// WithTraceID returns ctx carrying the trace ID
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return with(ctx, traceIDKey, id)
+}
+
+// TraceID returns the trace ID, or an empty string
+func TraceID(ctx context.Context) string {
+	return lookupString(ctx, traceIDKey)
+}
+
+// WithHeaders returns ctx carrying the headers of the incoming request, e.g. for a consumer
+// handling a message on behalf of a request; service clients pass them on by their propagation
+// policy
+func WithHeaders(ctx context.Context, h http.Header) context.Context {
+	return with(ctx, headersKey, h)
+}
+
+// Headers returns the incoming request headers: those set with WithHeaders, else those of the
+// request of a *gin.Context, also when it was wrapped
+func Headers(ctx context.Context) http.Header {
+	if h, ok := lookup(ctx, headersKey).(http.Header); ok {
+		return h
+	}
+	if ctx == nil {
+		return nil
+	}
+	if c, ok := ctx.Value(gin.ContextKey).(*gin.Context); ok && c.Request != nil {
+		return c.Request.Header
+	}
+	return nil
+}
+
+// WithDeadline returns ctx carrying the time by which the caller needs the answer; service
+// clients shorten their timeouts to it and pass it on. It does not cancel ctx.
+func WithDeadline(ctx context.Context, deadline time.Time) context.Context {
+	return with(ctx, deadlineKey, deadline)
+}
+
+// Deadline returns the caller's deadline set with WithDeadline
+func Deadline(ctx context.Context) (time.Time, bool) {
+	d, ok := lookup(ctx, deadlineKey).(time.Time)
+	return d, ok && !d.IsZero()
+}
+
+// Detach returns a context.Background() carrying the request-scoped values of ctx, for work
+// that outlives the request (goroutines, async jobs); the request deadline is left behind
+func Detach(ctx context.Context) context.Context {
+	out := context.Background()
+	for k := range names {
+		if k == deadlineKey {
+			continue
+		}
+		if v := lookup(ctx, k); v != nil {
+			out = context.WithValue(out, k, v)
+		}
+	}
+	// The headers of a gin request live on the request rather than in a value
+	if lookup(ctx, headersKey) == nil {
+		if h := Headers(ctx); h != nil {
+			out = context.WithValue(out, headersKey, h.Clone())
+		}
+	}
+	return out
+}
+
+// with stores value on a gin context (gin keys and request context) or wraps a standard one
+func with(ctx context.Context, k key, value interface{}) context.Context {
+	if c, ok := ctx.(*gin.Context); ok {
+		c.Set(names[k], value)
+		if c.Request != nil {
+			c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), k, value))
+		}
+		return c
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, k, value)
+}
+
+// lookup finds a value by typed key, then by the legacy string key set with c.Set or
+// context.WithValue(ctx, "user_id", ...)
+func lookup(ctx context.Context, k key) interface{} {
+	if ctx == nil {
+		return nil
+	}
+	if c, ok := ctx.(*gin.Context); ok {
+		if v, exists := c.Get(names[k]); exists {
+			return v
+		}
+		if c.Request != nil {
+			return lookup(c.Request.Context(), k)
+		}
+		return nil
+	}
+	if v := ctx.Value(k); v != nil {
+		return v
+	}
+	return ctx.Value(names[k])
+}
+
+// lookupString returns a value as a string
+func lookupString(ctx context.Context, k key) string {
+	switch v := lookup(ctx, k).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"reflect"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"gorm.io/gorm"
+)
+
+// ActorPlugin fills CreatedBy and UpdatedBy from the user in the statement context; pass the
+// request context with db.WithContext(c) for it to take effect
+type ActorPlugin struct{}
+
+// Name implements gorm.Plugin
+func (ActorPlugin) Name() string { return "actor" }
+
+// Initialize implements gorm.Plugin
+func (ActorPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("actor:create", func(tx *gorm.DB) {
+		setActor(tx, true, "CreatedBy", "UpdatedBy")
+	}); err != nil {
+		return err
+	}
+	return cb.Update().Before("gorm:update").Register("actor:update", func(tx *gorm.DB) {
+		setActor(tx, false, "UpdatedBy")
+	})
+}
+
+// setActor sets the named fields on models that have them; with keep, values already set on a
+// single struct are left alone
+func setActor(tx *gorm.DB, keep bool, fields ...string) {
+	if tx.Statement.Schema == nil || tx.Statement.Context == nil {
+		return
+	}
+	userID, ok := ctxutil.UserID(tx.Statement.Context)
+	if !ok {
+		return
+	}
+	for _, name := range fields {
+		field := tx.Statement.Schema.LookUpField(name)
+		if field == nil {
+			continue
+		}
+		if keep && tx.Statement.ReflectValue.Kind() == reflect.Struct {
+			if _, zero := field.ValueOf(tx.Statement.Context, tx.Statement.ReflectValue); !zero {
+				continue
+			}
+		}
+		tx.Statement.SetColumn(name, &userID, true)
+	}
+}
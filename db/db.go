@@ -1,9 +1,14 @@
 package db
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/Masharah-Advisory/common/config"
+	"github.com/Masharah-Advisory/common/retry"
+	"github.com/Masharah-Advisory/common/telemetry"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -17,6 +22,8 @@ type Config struct {
 	DBPass string
 	DBName string
 	DBSSL  string
+	// Retry controls connection attempts at startup, defaults to 5 attempts backing off up to 10s
+	Retry *retry.Policy
 }
 
 func Connect(cfg *Config) *gorm.DB {
@@ -25,8 +32,14 @@ func Connect(cfg *Config) *gorm.DB {
 		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPass, cfg.DBName, cfg.DBSSL,
 	)
 
+	// Fall back to the process environment when the config doesn't pin one
+	env := config.Env()
+	if cfg.Env != "" {
+		env = config.ParseEnv(cfg.Env)
+	}
+
 	var gormConfig *gorm.Config
-	if cfg.Env == "development" {
+	if env == config.Development {
 		gormConfig = &gorm.Config{
 			Logger: logger.Default.LogMode(logger.Info),
 		}
@@ -36,11 +49,48 @@ func Connect(cfg *Config) *gorm.DB {
 		}
 	}
 
-	db, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	policy := retry.Policy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: 10 * time.Second, Jitter: 0.2}
+	if cfg.Retry != nil {
+		policy = *cfg.Retry
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = func(err error) bool { return true }
+	}
+	if policy.OnRetry == nil {
+		policy.OnRetry = func(attempt int, err error, wait time.Duration) {
+			log.Printf("[COMMON] Database connection attempt %d failed, retrying in %s: %v", attempt, wait, err)
+		}
+	}
+
+	db, err := retry.DoValue(context.Background(), policy, func(ctx context.Context) (*gorm.DB, error) {
+		return gorm.Open(postgres.Open(dsn), gormConfig)
+	})
 	if err != nil {
 		log.Fatalf("Failed to connect database: %v", err)
 	}
 
+	if err := db.Use(telemetry.GORMPlugin{}); err != nil {
+		log.Printf("[COMMON] Failed to register database tracing: %v", err)
+	}
+	if err := db.Use(ActorPlugin{}); err != nil {
+		log.Printf("[COMMON] Failed to register actor tracking: %v", err)
+	}
+
 	log.Println("[COMMON] Database connected")
+	config.RegisterValidator("db", Validator(db))
 	return db
 }
+
+// Validator returns a config validator that checks the database is reachable
+func Validator(db *gorm.DB) config.ValidatorFunc {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return fmt.Errorf("failed to get database handle: %w", err)
+		}
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return fmt.Errorf("database unreachable: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,91 @@
+package dedupe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Store records processed keys (event IDs, idempotency keys, webhook IDs) so work runs at most once
+type Store interface {
+	// Claim marks key as in progress/processed; it returns false when the key was already claimed
+	Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Complete marks a claimed key as processed and keeps it for ttl
+	Complete(ctx context.Context, key string, ttl time.Duration) error
+	// Release forgets a claim so the work can be retried (e.g. after a handler failure)
+	Release(ctx context.Context, key string) error
+	// Seen reports whether key has been claimed
+	Seen(ctx context.Context, key string) (bool, error)
+	// Completed reports whether key has been completed, as opposed to claimed and still in progress
+	Completed(ctx context.Context, key string) (bool, error)
+}
+
+// Values stored under a key; claims made before Complete existed hold a timestamp and count as
+// completed
+const (
+	pending   = "pending"
+	completed = "done"
+)
+
+// RedisStore is a Store backed by Redis SETNX with expiry
+type RedisStore struct {
+	rdb    *redis.Client
+	prefix string
+}
+
+// NewRedisStore creates a Redis-backed dedupe store; prefix namespaces the keys (defaults to "dedupe:")
+func NewRedisStore(rdb *redis.Client, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = "dedupe:"
+	}
+	return &RedisStore{rdb: rdb, prefix: prefix}
+}
+
+// Claim implements Store
+func (s *RedisStore) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	ok, err := s.rdb.SetNX(ctx, s.prefix+key, pending, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim dedupe key %s: %w", key, err)
+	}
+	return ok, nil
+}
+
+// Complete implements Store
+func (s *RedisStore) Complete(ctx context.Context, key string, ttl time.Duration) error {
+	if err := s.rdb.Set(ctx, s.prefix+key, completed, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to complete dedupe key %s: %w", key, err)
+	}
+	return nil
+}
+
+// Release implements Store
+func (s *RedisStore) Release(ctx context.Context, key string) error {
+	if err := s.rdb.Del(ctx, s.prefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to release dedupe key %s: %w", key, err)
+	}
+	return nil
+}
+
+// Seen implements Store
+func (s *RedisStore) Seen(ctx context.Context, key string) (bool, error) {
+	n, err := s.rdb.Exists(ctx, s.prefix+key).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check dedupe key %s: %w", key, err)
+	}
+	return n > 0, nil
+}
+
+// Completed implements Store
+func (s *RedisStore) Completed(ctx context.Context, key string) (bool, error) {
+	v, err := s.rdb.Get(ctx, s.prefix+key).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check dedupe key %s: %w", key, err)
+	}
+	return v != pending, nil
+}
@@ -0,0 +1,40 @@
+package dedupe
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestRedisStoreClaimAndComplete(t *testing.T) {
+	mr := miniredis.RunT(t)
+	s := NewRedisStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}), "")
+	ctx := context.Background()
+
+	if ok, err := s.Claim(ctx, "k", time.Minute); err != nil || !ok {
+		t.Fatalf("first Claim() = %v, %v, want true", ok, err)
+	}
+	if ok, _ := s.Claim(ctx, "k", time.Minute); ok {
+		t.Fatal("second Claim() succeeded while the key is held")
+	}
+	if done, _ := s.Completed(ctx, "k"); done {
+		t.Fatal("Completed() = true for a claim still in progress")
+	}
+
+	if err := s.Complete(ctx, "k", time.Hour); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if done, _ := s.Completed(ctx, "k"); !done {
+		t.Fatal("Completed() = false after Complete")
+	}
+	if ttl := mr.TTL("dedupe:k"); ttl != time.Hour {
+		t.Errorf("TTL after Complete = %v, want the completion ttl", ttl)
+	}
+
+	if done, _ := s.Completed(ctx, "missing"); done {
+		t.Error("Completed() = true for an unknown key")
+	}
+}
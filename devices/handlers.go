@@ -0,0 +1,137 @@
+package devices
+
+import (
+	"strconv"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// InfoKey is the gin key under which Middleware stores the request's Info
+const InfoKey = "device_info"
+
+// Middleware parses the device of every request once and stores it for FromContext; it also
+// asks browsers to send the client hints FromRequest reads
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Accept-CH", "Sec-CH-UA-Platform, Sec-CH-UA-Mobile, Sec-CH-UA-Model")
+		c.Set(InfoKey, FromRequest(c.Request, c.ClientIP()))
+		c.Next()
+	}
+}
+
+// FromContext returns the device stored by Middleware, parsing the request when there is none
+func FromContext(c *gin.Context) Info {
+	if v, ok := c.Get(InfoKey); ok {
+		if info, ok := v.(Info); ok {
+			return info
+		}
+	}
+	return FromRequest(c.Request, c.ClientIP())
+}
+
+// RequireTrusted refuses requests with 403 device_not_trusted unless they come from one of the
+// user's trusted devices; place it after the auth middleware on sensitive routes
+func (s *Store) RequireTrusted() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := ctxutil.UserID(c)
+		if !ok {
+			response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
+			c.Abort()
+			return
+		}
+		trusted, err := s.IsTrusted(c.Request.Context(), userID, FromContext(c))
+		if err != nil {
+			response.HandleError(c, err)
+			c.Abort()
+			return
+		}
+		if !trusted {
+			response.HandleError(c, ErrNotTrusted)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// Handlers lets the current user review and remove their devices
+type Handlers struct {
+	store *Store
+}
+
+// NewHandlers creates the handlers
+func NewHandlers(store *Store) *Handlers {
+	return &Handlers{store: store}
+}
+
+// Register mounts the endpoints on a router group, e.g. /devices
+func (h *Handlers) Register(rg *gin.RouterGroup) {
+	rg.GET("", h.List)
+	rg.DELETE("/:id", h.Forget)
+	rg.DELETE("/:id/trust", h.Untrust)
+}
+
+// List returns the current user's devices, flagging the one making the request
+func (h *Handlers) List(c *gin.Context) {
+	userID, ok := ctxutil.UserID(c)
+	if !ok {
+		response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
+		return
+	}
+	items, err := h.store.List(c.Request.Context(), userID)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	current := FromContext(c).Fingerprint()
+	for i := range items {
+		items[i].Current = items[i].Fingerprint == current
+	}
+	if items == nil {
+		items = []Device{}
+	}
+	response.OK(c, items)
+}
+
+// Forget removes one of the current user's devices
+func (h *Handlers) Forget(c *gin.Context) {
+	userID, id, ok := userAndID(c)
+	if !ok {
+		return
+	}
+	if err := h.store.Forget(c.Request.Context(), userID, id); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+// Untrust removes the trust from one of the current user's devices
+func (h *Handlers) Untrust(c *gin.Context) {
+	userID, id, ok := userAndID(c)
+	if !ok {
+		return
+	}
+	if err := h.store.Untrust(c.Request.Context(), userID, id); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func userAndID(c *gin.Context) (uint64, uint64, bool) {
+	userID, ok := ctxutil.UserID(c)
+	if !ok {
+		response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
+		return 0, 0, false
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, i18n.T(c, "devices.invalid_request"))
+		return 0, 0, false
+	}
+	return userID, id, true
+}
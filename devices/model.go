@@ -0,0 +1,35 @@
+package devices
+
+import (
+	"time"
+)
+
+// Device is a device a user has signed in from
+type Device struct {
+	ID           uint64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID     string     `json:"tenant_id,omitempty" gorm:"index;size:64"`
+	UserID       uint64     `json:"user_id" gorm:"uniqueIndex:idx_device_user_fingerprint;not null"`
+	Fingerprint  string     `json:"-" gorm:"uniqueIndex:idx_device_user_fingerprint;size:64;not null"`
+	Name         string     `json:"name" gorm:"size:128"`
+	Browser      string     `json:"browser,omitempty" gorm:"size:64"`
+	OS           string     `json:"os,omitempty" gorm:"size:64"`
+	Model        string     `json:"model,omitempty" gorm:"size:128"`
+	Type         Type       `json:"type" gorm:"size:16"`
+	UserAgent    string     `json:"-" gorm:"size:512"`
+	LastIP       string     `json:"last_ip,omitempty" gorm:"size:64"`
+	FirstSeenAt  time.Time  `json:"first_seen_at"`
+	LastSeenAt   time.Time  `json:"last_seen_at" gorm:"index"`
+	TrustedUntil *time.Time `json:"trusted_until,omitempty"`
+	// Current is set by the handlers for the device making the request
+	Current bool `json:"current" gorm:"-"`
+}
+
+// TableName overrides the table name
+func (Device) TableName() string {
+	return "user_devices"
+}
+
+// Trusted reports whether the device is trusted at t
+func (d *Device) Trusted(t time.Time) bool {
+	return d.TrustedUntil != nil && t.Before(*d.TrustedUntil)
+}
@@ -0,0 +1,185 @@
+package devices
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Masharah-Advisory/common/crypto"
+)
+
+// Type is the form factor of a device
+type Type string
+
+const (
+	TypeDesktop Type = "desktop"
+	TypeMobile  Type = "mobile"
+	TypeTablet  Type = "tablet"
+	TypeBot     Type = "bot"
+	TypeUnknown Type = "unknown"
+)
+
+// IDHeader carries a stable device ID generated by mobile apps (e.g. identifierForVendor); when
+// present it identifies the device instead of the user agent
+const IDHeader = "X-Device-ID"
+
+// Info describes the device a request comes from
+type Info struct {
+	DeviceID  string `json:"device_id,omitempty"`
+	Browser   string `json:"browser,omitempty"`
+	OS        string `json:"os,omitempty"`
+	Model     string `json:"model,omitempty"`
+	Type      Type   `json:"type"`
+	UserAgent string `json:"user_agent,omitempty"`
+	IP        string `json:"ip,omitempty"`
+}
+
+// Name is a human-readable label for device lists and notifications, e.g. "Chrome on Windows"
+func (i Info) Name() string {
+	switch {
+	case i.Model != "" && i.Browser != "":
+		return i.Browser + " on " + i.Model
+	case i.Browser != "" && i.OS != "":
+		return i.Browser + " on " + i.OS
+	case i.Model != "":
+		return i.Model
+	case i.OS != "":
+		return i.OS
+	case i.Browser != "":
+		return i.Browser
+	default:
+		return "Unknown device"
+	}
+}
+
+// Fingerprint identifies the device across logins: the app's device ID when there is one,
+// otherwise the browser, OS, model and type. Versions and the IP are left out so updates and
+// network changes do not look like a new device.
+func (i Info) Fingerprint() string {
+	if i.DeviceID != "" {
+		return crypto.SHA256Hex([]byte("id:" + i.DeviceID))
+	}
+	return crypto.SHA256Hex([]byte(strings.Join([]string{"ua", i.Browser, i.OS, i.Model, string(i.Type)}, "|")))
+}
+
+// FromRequest reads the device from the user agent, preferring User-Agent Client Hints
+// (Sec-CH-UA*) which browsers send when the service asks for them with Accept-CH
+func FromRequest(r *http.Request, ip string) Info {
+	info := Parse(r.UserAgent())
+	info.IP = ip
+	info.DeviceID = strings.TrimSpace(r.Header.Get(IDHeader))
+	if len(info.DeviceID) > 128 {
+		info.DeviceID = info.DeviceID[:128]
+	}
+
+	if brand := hintBrand(r.Header.Get("Sec-CH-UA")); brand != "" {
+		info.Browser = brand
+	}
+	if platform := unquote(r.Header.Get("Sec-CH-UA-Platform")); platform != "" {
+		info.OS = platform
+	}
+	if model := unquote(r.Header.Get("Sec-CH-UA-Model")); model != "" {
+		info.Model = model
+	}
+	if r.Header.Get("Sec-CH-UA-Mobile") == "?1" && info.Type != TypeTablet {
+		info.Type = TypeMobile
+	}
+	return info
+}
+
+var (
+	botPattern     = regexp.MustCompile(`(?i)bot|crawler|spider|curl|wget|python-requests|go-http-client|okhttp`)
+	androidModel   = regexp.MustCompile(`Android [\d.]+; (?:[a-z]{2}[-_][a-z]{2}; )?([^;)]+?)(?: Build/|\))`)
+	appPattern     = regexp.MustCompile(`^([A-Za-z][\w.-]*)/[\d.]+ \((iPhone|iPad|Android)`)
+	browserMatches = []struct {
+		name    string
+		pattern *regexp.Regexp
+	}{
+		// Order matters: Edge and Opera also claim to be Chrome, Chrome claims to be Safari
+		{"Edge", regexp.MustCompile(`Edg(?:e|A|iOS)?/`)},
+		{"Opera", regexp.MustCompile(`OPR/|Opera`)},
+		{"Samsung Internet", regexp.MustCompile(`SamsungBrowser/`)},
+		{"Firefox", regexp.MustCompile(`Firefox/|FxiOS/`)},
+		{"Chrome", regexp.MustCompile(`Chrome/|CriOS/`)},
+		{"Safari", regexp.MustCompile(`Version/[\d.]+.*Safari/`)},
+	}
+)
+
+// Parse extracts browser, OS, model and type from a User-Agent string
+func Parse(ua string) Info {
+	info := Info{UserAgent: ua, Type: TypeUnknown}
+	if len(info.UserAgent) > 512 {
+		info.UserAgent = info.UserAgent[:512]
+	}
+	if ua == "" {
+		return info
+	}
+	if botPattern.MatchString(ua) {
+		info.Type = TypeBot
+		return info
+	}
+
+	switch {
+	case strings.Contains(ua, "iPad"):
+		info.OS, info.Model, info.Type = "iPadOS", "iPad", TypeTablet
+	case strings.Contains(ua, "iPhone"):
+		info.OS, info.Model, info.Type = "iOS", "iPhone", TypeMobile
+	case strings.Contains(ua, "Android"):
+		info.OS, info.Type = "Android", TypeTablet
+		if strings.Contains(ua, "Mobile") {
+			info.Type = TypeMobile
+		}
+		if m := androidModel.FindStringSubmatch(ua); m != nil && m[1] != "K" {
+			info.Model = strings.TrimSpace(m[1])
+		}
+	case strings.Contains(ua, "Windows"):
+		info.OS, info.Type = "Windows", TypeDesktop
+	case strings.Contains(ua, "Mac OS X"), strings.Contains(ua, "Macintosh"):
+		info.OS, info.Type = "macOS", TypeDesktop
+	case strings.Contains(ua, "CrOS"):
+		info.OS, info.Type = "ChromeOS", TypeDesktop
+	case strings.Contains(ua, "Linux"):
+		info.OS, info.Type = "Linux", TypeDesktop
+	}
+
+	for _, b := range browserMatches {
+		if b.pattern.MatchString(ua) {
+			info.Browser = b.name
+			break
+		}
+	}
+	// Native apps send "AppName/1.2 (iPhone; ...)" rather than a browser token
+	if info.Browser == "" {
+		if m := appPattern.FindStringSubmatch(ua); m != nil {
+			info.Browser = m[1]
+		}
+	}
+	return info
+}
+
+// hintBrand picks the real brand from Sec-CH-UA, skipping the generic "Chromium" entry and
+// the GREASE entries ("Not A;Brand") browsers add
+func hintBrand(header string) string {
+	var fallback string
+	for _, part := range strings.Split(header, ",") {
+		brand, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		brand = unquote(brand)
+		switch {
+		case brand == "" || strings.Contains(brand, "Not"):
+			continue
+		case brand == "Chromium":
+			fallback = "Chrome"
+		case brand == "Google Chrome":
+			return "Chrome"
+		case brand == "Microsoft Edge":
+			return "Edge"
+		default:
+			return brand
+		}
+	}
+	return fallback
+}
+
+func unquote(s string) string {
+	return strings.Trim(strings.TrimSpace(s), `"`)
+}
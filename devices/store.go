@@ -0,0 +1,212 @@
+package devices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/notify"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// NewDeviceEvent is the default notification sent on a sign-in from a new device; its data
+// carries device, browser, os, ip and time. Register it on the dispatcher as a critical event.
+const NewDeviceEvent = "security.new_device"
+
+// Errors returned by the store
+var (
+	ErrNotFound = apperror.New("device_not_found", apperror.KindNotFound, "device not found").
+			WithKey("devices.not_found")
+	ErrNotTrusted = apperror.New("device_not_trusted", apperror.KindForbidden, "this action requires a trusted device").
+			WithKey("devices.not_trusted")
+)
+
+// Config configures a Store
+type Config struct {
+	DB *gorm.DB
+	// Notifier receives Event when a user signs in from a new device (optional)
+	Notifier *notify.Dispatcher
+	Event    string        // defaults to NewDeviceEvent
+	TrustTTL time.Duration // how long Trust lasts when no duration is given, defaults to 30 days
+}
+
+// Store keeps each user's known devices
+type Store struct {
+	cfg *Config
+	log *zap.Logger
+	now func() time.Time
+}
+
+// NewStore creates a store; migrate Device first
+func NewStore(cfg *Config) *Store {
+	if cfg.Event == "" {
+		cfg.Event = NewDeviceEvent
+	}
+	if cfg.TrustTTL <= 0 {
+		cfg.TrustTTL = 30 * 24 * time.Hour
+	}
+	return &Store{cfg: cfg, log: logger.Module("devices"), now: time.Now}
+}
+
+// Seen records a sign-in from the device and reports whether it was new to the user. The
+// user is notified about new devices, except for their very first one.
+func (s *Store) Seen(ctx context.Context, tenantID string, userID uint64, info Info) (*Device, bool, error) {
+	now := s.now()
+	fp := info.Fingerprint()
+
+	var d Device
+	err := s.cfg.DB.WithContext(ctx).Where("user_id = ? AND fingerprint = ?", userID, fp).First(&d).Error
+	if err == nil {
+		err = s.cfg.DB.WithContext(ctx).Model(&d).Updates(map[string]interface{}{
+			"last_seen_at": now,
+			"last_ip":      info.IP,
+			"user_agent":   info.UserAgent,
+		}).Error
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to update device: %w", err)
+		}
+		return &d, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, fmt.Errorf("failed to load device: %w", err)
+	}
+
+	var known int64
+	if err := s.cfg.DB.WithContext(ctx).Model(&Device{}).Where("user_id = ?", userID).Count(&known).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to count devices: %w", err)
+	}
+
+	d = Device{
+		TenantID:    tenantID,
+		UserID:      userID,
+		Fingerprint: fp,
+		Name:        info.Name(),
+		Browser:     info.Browser,
+		OS:          info.OS,
+		Model:       info.Model,
+		Type:        info.Type,
+		UserAgent:   info.UserAgent,
+		LastIP:      info.IP,
+		FirstSeenAt: now,
+		LastSeenAt:  now,
+	}
+	res := s.cfg.DB.WithContext(ctx).Where("user_id = ? AND fingerprint = ?", userID, fp).FirstOrCreate(&d)
+	if res.Error != nil {
+		return nil, false, fmt.Errorf("failed to create device: %w", res.Error)
+	}
+	// A concurrent sign-in may have created it first; only the creator notifies
+	isNew := res.RowsAffected > 0
+	if isNew && known > 0 {
+		s.notify(ctx, &d)
+	}
+	return &d, isNew, nil
+}
+
+// List returns the user's devices, most recently used first
+func (s *Store) List(ctx context.Context, userID uint64) ([]Device, error) {
+	var items []Device
+	err := s.cfg.DB.WithContext(ctx).Where("user_id = ?", userID).Order("last_seen_at DESC").Find(&items).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+	return items, nil
+}
+
+// Get returns one of the user's devices
+func (s *Store) Get(ctx context.Context, userID, id uint64) (*Device, error) {
+	var d Device
+	err := s.cfg.DB.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&d).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load device: %w", err)
+	}
+	return &d, nil
+}
+
+// Find returns the user's device matching info, or ErrNotFound
+func (s *Store) Find(ctx context.Context, userID uint64, info Info) (*Device, error) {
+	var d Device
+	err := s.cfg.DB.WithContext(ctx).Where("user_id = ? AND fingerprint = ?", userID, info.Fingerprint()).First(&d).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load device: %w", err)
+	}
+	return &d, nil
+}
+
+// Forget removes a device, so the next sign-in from it counts as new again; revoke its
+// sessions separately
+func (s *Store) Forget(ctx context.Context, userID, id uint64) error {
+	res := s.cfg.DB.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&Device{})
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete device: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Trust marks a device trusted for ttl (0 uses TrustTTL), typically after the user passed an
+// OTP on it
+func (s *Store) Trust(ctx context.Context, userID, id uint64, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = s.cfg.TrustTTL
+	}
+	return s.setTrust(ctx, userID, id, s.now().Add(ttl))
+}
+
+// Untrust removes the trust from a device
+func (s *Store) Untrust(ctx context.Context, userID, id uint64) error {
+	return s.setTrust(ctx, userID, id, nil)
+}
+
+// IsTrusted reports whether the device in info is a trusted device of the user, for sensitive
+// flows such as changing the password or adding a payout account
+func (s *Store) IsTrusted(ctx context.Context, userID uint64, info Info) (bool, error) {
+	d, err := s.Find(ctx, userID, info)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return d.Trusted(s.now()), nil
+}
+
+func (s *Store) setTrust(ctx context.Context, userID, id uint64, until interface{}) error {
+	res := s.cfg.DB.WithContext(ctx).Model(&Device{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("trusted_until", until)
+	if res.Error != nil {
+		return fmt.Errorf("failed to update device trust: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) notify(ctx context.Context, d *Device) {
+	if s.cfg.Notifier == nil {
+		return
+	}
+	data := map[string]interface{}{
+		"device":  d.Name,
+		"browser": d.Browser,
+		"os":      d.OS,
+		"ip":      d.LastIP,
+		"time":    d.FirstSeenAt.UTC().Format(time.RFC3339),
+	}
+	if _, err := s.cfg.Notifier.Notify(ctx, d.UserID, s.cfg.Event, data); err != nil {
+		s.log.Warn("failed to notify new device", zap.Uint64("user_id", d.UserID), zap.Error(err))
+	}
+}
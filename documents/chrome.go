@@ -0,0 +1,144 @@
+package documents
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// ChromeConfig holds the headless Chrome settings
+type ChromeConfig struct {
+	// RemoteURL connects to a running browser (e.g. a chromedp/headless-shell sidecar at
+	// ws://chrome:9222); when empty a local browser is started
+	RemoteURL string
+	ExecPath  string        // local browser binary, found on PATH when empty
+	Timeout   time.Duration // per document, defaults to 30s
+}
+
+// Chrome renders PDFs with headless Chrome; one browser is shared and each document gets a tab
+type Chrome struct {
+	cfg      *ChromeConfig
+	allocCtx context.Context
+	cancel   context.CancelFunc
+}
+
+// NewChrome creates the engine; the browser starts lazily with the first document
+func NewChrome(cfg *ChromeConfig) *Chrome {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+
+	var allocCtx context.Context
+	var cancel context.CancelFunc
+	if cfg.RemoteURL != "" {
+		allocCtx, cancel = chromedp.NewRemoteAllocator(context.Background(), cfg.RemoteURL)
+	} else {
+		opts := append(chromedp.DefaultExecAllocatorOptions[:],
+			chromedp.DisableGPU,
+			chromedp.NoSandbox,
+			chromedp.Flag("font-render-hinting", "none"),
+		)
+		if cfg.ExecPath != "" {
+			opts = append(opts, chromedp.ExecPath(cfg.ExecPath))
+		}
+		allocCtx, cancel = chromedp.NewExecAllocator(context.Background(), opts...)
+	}
+
+	return &Chrome{cfg: cfg, allocCtx: allocCtx, cancel: cancel}
+}
+
+// Render implements Engine
+func (e *Chrome) Render(ctx context.Context, w io.Writer, html []byte, p PageOptions) error {
+	p = p.withDefaults()
+	size := paperSizes[p.Size]
+
+	tabCtx, cancelTab := chromedp.NewContext(e.allocCtx)
+	defer cancelTab()
+	tabCtx, cancelTimeout := context.WithTimeout(tabCtx, e.cfg.Timeout)
+	defer cancelTimeout()
+	stop := context.AfterFunc(ctx, cancelTab)
+	defer stop()
+
+	var pdf []byte
+	err := chromedp.Run(tabCtx,
+		chromedp.Navigate("about:blank"),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			tree, err := page.GetFrameTree().Do(ctx)
+			if err != nil {
+				return err
+			}
+			return page.SetDocumentContent(tree.Frame.ID, string(html)).Do(ctx)
+		}),
+		chromedp.WaitReady("body"),
+		// Embedded fonts load asynchronously; printing earlier falls back to system fonts
+		chromedp.Evaluate(`document.fonts.ready.then(() => true)`, nil, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+			return p.WithAwaitPromise(true)
+		}),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			params := page.PrintToPDF().
+				WithPrintBackground(true).
+				WithPaperWidth(size[0]).
+				WithPaperHeight(size[1]).
+				WithLandscape(p.Landscape).
+				WithMarginTop(mmToInch(p.Margins.Top)).
+				WithMarginRight(mmToInch(p.Margins.Right)).
+				WithMarginBottom(mmToInch(p.Margins.Bottom)).
+				WithMarginLeft(mmToInch(p.Margins.Left))
+			if p.Header != "" || p.Footer != "" {
+				params = params.
+					WithDisplayHeaderFooter(true).
+					WithHeaderTemplate(chromeTemplate(p.Header, p.Lang)).
+					WithFooterTemplate(chromeTemplate(p.Footer, p.Lang))
+			}
+			data, _, err := params.Do(ctx)
+			pdf = data
+			return err
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to render PDF: %w", err)
+	}
+
+	if _, err := w.Write(pdf); err != nil {
+		return fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return nil
+}
+
+// Close shuts the browser down
+func (e *Chrome) Close() error {
+	e.cancel()
+	return nil
+}
+
+// chromeTemplate adapts a header or footer fragment to Chrome, which needs explicit styles and
+// its own page number classes
+func chromeTemplate(fragment, lang string) string {
+	if fragment == "" {
+		return "<span></span>"
+	}
+	fragment = strings.NewReplacer(
+		`class="page"`, `class="pageNumber"`,
+		`class="pages"`, `class="totalPages"`,
+	).Replace(fragment)
+	return fmt.Sprintf(`<div dir="%s" style="font-size:9px;width:100%%;padding:0 10mm;text-align:center;font-family:%s">%s</div>`,
+		direction(lang), DefaultFontFamily, fragment)
+}
+
+func direction(lang string) string {
+	if i18n.IsRTL(lang) {
+		return "rtl"
+	}
+	return "ltr"
+}
+
+func mmToInch(mm float64) float64 {
+	return mm / 25.4
+}
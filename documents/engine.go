@@ -0,0 +1,78 @@
+package documents
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Engine converts a complete HTML document into a PDF
+type Engine interface {
+	Render(ctx context.Context, w io.Writer, html []byte, page PageOptions) error
+	Close() error
+}
+
+// Margins are page margins in millimetres
+type Margins struct {
+	Top, Right, Bottom, Left float64
+}
+
+// PageOptions describe the printed page. Header and Footer are HTML fragments in which
+// <span class="page"></span> and <span class="pages"></span> are replaced by the current page
+// and the page count.
+type PageOptions struct {
+	Size      string // A3, A4 (default), A5, Letter or Legal
+	Landscape bool
+	Margins   *Margins // defaults to 20mm on every side
+	Header    string
+	Footer    string
+	// PageNumbers adds a centred "page / pages" footer when Footer is empty
+	PageNumbers bool
+	// Lang sets the direction of the header and footer; Document fills it in
+	Lang string
+}
+
+// PageNumberFooter is the footer used by PageNumbers
+const PageNumberFooter = `<span class="page"></span> / <span class="pages"></span>`
+
+// paperSizes are width and height in inches
+var paperSizes = map[string][2]float64{
+	"A3":     {11.69, 16.54},
+	"A4":     {8.27, 11.69},
+	"A5":     {5.83, 8.27},
+	"LETTER": {8.5, 11},
+	"LEGAL":  {8.5, 14},
+}
+
+func (p PageOptions) withDefaults() PageOptions {
+	p.Size = strings.ToUpper(p.Size)
+	if _, ok := paperSizes[p.Size]; !ok {
+		p.Size = "A4"
+	}
+	if p.Margins == nil {
+		p.Margins = &Margins{Top: 20, Right: 20, Bottom: 20, Left: 20}
+	}
+	if p.Footer == "" && p.PageNumbers {
+		p.Footer = PageNumberFooter
+	}
+	return p
+}
+
+// EngineFromEnv builds the engine selected by PDF_ENGINE: "chrome" (default, using CHROME_URL
+// for a remote headless Chrome or CHROME_PATH for a local binary) or "wkhtmltopdf" (using
+// WKHTMLTOPDF_PATH)
+func EngineFromEnv() (Engine, error) {
+	switch engine := strings.ToLower(os.Getenv("PDF_ENGINE")); engine {
+	case "", "chrome":
+		return NewChrome(&ChromeConfig{
+			RemoteURL: os.Getenv("CHROME_URL"),
+			ExecPath:  os.Getenv("CHROME_PATH"),
+		}), nil
+	case "wkhtmltopdf":
+		return NewWKHTMLToPDF(&WKHTMLToPDFConfig{Binary: os.Getenv("WKHTMLTOPDF_PATH")}), nil
+	default:
+		return nil, fmt.Errorf("unsupported PDF engine: %s", engine)
+	}
+}
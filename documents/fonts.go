@@ -0,0 +1,70 @@
+package documents
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// DefaultFontFamily prefers fonts with full Arabic shaping and falls back to common system fonts
+const DefaultFontFamily = `'Noto Naskh Arabic', 'Noto Sans Arabic', 'Amiri', 'Noto Sans', 'DejaVu Sans', Tahoma, sans-serif`
+
+// Font is a font file embedded into every document so output does not depend on the fonts
+// installed where the engine runs
+type Font struct {
+	Family string
+	Weight string // e.g. "400", "700", defaults to "normal"
+	Style  string // "normal" or "italic", defaults to "normal"
+	Data   []byte
+	Format string // truetype, opentype, woff or woff2; detected from the file name by LoadFont
+}
+
+// LoadFont reads a font file from fsys, e.g. an embed.FS holding NotoNaskhArabic-Regular.ttf
+func LoadFont(fsys fs.FS, file, family, weight string) (Font, error) {
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return Font{}, fmt.Errorf("failed to read font %s: %w", file, err)
+	}
+
+	format := "truetype"
+	switch strings.ToLower(path.Ext(file)) {
+	case ".otf":
+		format = "opentype"
+	case ".woff":
+		format = "woff"
+	case ".woff2":
+		format = "woff2"
+	}
+	return Font{Family: family, Weight: weight, Data: data, Format: format}, nil
+}
+
+var fontMIME = map[string]string{
+	"truetype": "font/ttf",
+	"opentype": "font/otf",
+	"woff":     "font/woff",
+	"woff2":    "font/woff2",
+}
+
+// fontFaceCSS renders @font-face rules with the fonts inlined as data URIs
+func fontFaceCSS(fonts []Font) template.CSS {
+	var b strings.Builder
+	for _, f := range fonts {
+		weight, style := f.Weight, f.Style
+		if weight == "" {
+			weight = "normal"
+		}
+		if style == "" {
+			style = "normal"
+		}
+		mime, ok := fontMIME[f.Format]
+		if !ok {
+			mime, f.Format = "font/ttf", "truetype"
+		}
+		fmt.Fprintf(&b, "@font-face{font-family:'%s';font-weight:%s;font-style:%s;src:url(data:%s;base64,%s) format('%s');}\n",
+			strings.ReplaceAll(f.Family, "'", ""), weight, style, mime, base64.StdEncoding.EncodeToString(f.Data), f.Format)
+	}
+	return template.CSS(b.String())
+}
@@ -0,0 +1,252 @@
+package documents
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed layout.html
+var layoutHTML string
+
+var layout = template.Must(template.New("layout").Parse(layoutHTML))
+
+// Config holds the renderer settings
+type Config struct {
+	// Templates holds the document bodies, usually an embed.FS
+	Templates fs.FS
+	Pattern   string // glob of template files, defaults to "*.html"
+	Engine    Engine
+	Fonts     []Font
+	// FontFamily is the CSS font stack, defaults to the embedded fonts followed by DefaultFontFamily
+	FontFamily string
+	// CSS is appended to the base stylesheet of every document
+	CSS string
+	// Funcs are extra template functions
+	Funcs template.FuncMap
+}
+
+// Document is one document to render
+type Document struct {
+	Template string // template name, e.g. "statement.html"
+	Lang     string // selects translations and direction, defaults to "en"
+	Title    string
+	Data     interface{}
+	Page     PageOptions
+	// Watermark is drawn diagonally behind the content, e.g. "DRAFT" or "نسخة"
+	Watermark        string
+	WatermarkOpacity float64 // defaults to 0.08
+}
+
+// Renderer turns templates into HTML and PDF
+type Renderer struct {
+	cfg       *Config
+	templates *template.Template
+	fontCSS   template.CSS
+}
+
+// NewRenderer parses the templates. Besides Funcs, templates can use:
+//
+//	t "key" [data]      translation in the document language
+//	dir / rtl / lang    direction helpers
+//	date t "layout"     formats a time.Time, defaults to 2006-01-02
+//	number v [places]   thousands separators, e.g. 1,234,567.50
+//	money v "SAR"       number with two places and the currency
+func NewRenderer(cfg *Config) (*Renderer, error) {
+	if cfg.Pattern == "" {
+		cfg.Pattern = "*.html"
+	}
+	if cfg.FontFamily == "" {
+		families := make([]string, 0, len(cfg.Fonts)+1)
+		seen := make(map[string]bool)
+		for _, f := range cfg.Fonts {
+			if !seen[f.Family] {
+				seen[f.Family] = true
+				families = append(families, "'"+strings.ReplaceAll(f.Family, "'", "")+"'")
+			}
+		}
+		cfg.FontFamily = strings.Join(append(families, DefaultFontFamily), ", ")
+	}
+
+	tmpl := template.New("documents").Funcs(baseFuncs("en")).Funcs(cfg.Funcs)
+	if cfg.Templates != nil {
+		var err error
+		tmpl, err = tmpl.ParseFS(cfg.Templates, cfg.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse document templates: %w", err)
+		}
+	}
+
+	return &Renderer{cfg: cfg, templates: tmpl, fontCSS: fontFaceCSS(cfg.Fonts)}, nil
+}
+
+// HTML renders the complete HTML document, useful for previews and for debugging layouts
+func (r *Renderer) HTML(doc *Document) ([]byte, error) {
+	lang := doc.Lang
+	if lang == "" {
+		lang = "en"
+	}
+
+	tmpl, err := r.templates.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone templates: %w", err)
+	}
+	tmpl.Funcs(baseFuncs(lang))
+
+	var body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&body, doc.Template, doc.Data); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", doc.Template, err)
+	}
+
+	opacity := doc.WatermarkOpacity
+	if opacity <= 0 {
+		opacity = 0.08
+	}
+
+	var out bytes.Buffer
+	err = layout.Execute(&out, map[string]interface{}{
+		"Lang":             lang,
+		"Dir":              direction(lang),
+		"Title":            doc.Title,
+		"FontCSS":          r.fontCSS,
+		"FontFamily":       template.CSS(r.cfg.FontFamily),
+		"CSS":              template.CSS(r.cfg.CSS),
+		"Watermark":        doc.Watermark,
+		"WatermarkOpacity": template.CSS(strconv.FormatFloat(opacity, 'f', 2, 64)),
+		"Body":             template.HTML(body.String()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render document layout: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// PDF renders the document and writes the PDF to w
+func (r *Renderer) PDF(ctx context.Context, w io.Writer, doc *Document) error {
+	if r.cfg.Engine == nil {
+		return fmt.Errorf("no PDF engine configured")
+	}
+	html, err := r.HTML(doc)
+	if err != nil {
+		return err
+	}
+	page := doc.Page
+	if page.Lang == "" {
+		page.Lang = doc.Lang
+	}
+	return r.cfg.Engine.Render(ctx, w, html, page)
+}
+
+// Serve renders the document in the request language (unless doc.Lang is set) and sends it with
+// response.File; ?inline=true displays it in the browser instead of downloading it
+func (r *Renderer) Serve(c *gin.Context, filename string, doc *Document) {
+	if doc.Lang == "" {
+		doc.Lang = i18n.Lang(c)
+	}
+
+	var buf bytes.Buffer
+	if err := r.PDF(c.Request.Context(), &buf, doc); err != nil {
+		response.InternalErrorWithCause(c, err)
+		return
+	}
+	response.File(c, filename, "application/pdf", &buf, response.FileOptions{
+		Inline: c.Query("inline") == "true",
+		Size:   int64(buf.Len()),
+	})
+}
+
+// Close releases the engine
+func (r *Renderer) Close() error {
+	if r.cfg.Engine == nil {
+		return nil
+	}
+	return r.cfg.Engine.Close()
+}
+
+// baseFuncs are the template helpers bound to the document language
+func baseFuncs(lang string) template.FuncMap {
+	return template.FuncMap{
+		"t": func(key string, data ...map[string]interface{}) string {
+			return i18n.TLang(lang, key, data...)
+		},
+		"lang": func() string { return lang },
+		"dir":  func() string { return direction(lang) },
+		"rtl":  func() bool { return i18n.IsRTL(lang) },
+		"date": func(t time.Time, layout ...string) string {
+			if t.IsZero() {
+				return ""
+			}
+			if len(layout) > 0 {
+				return t.Format(layout[0])
+			}
+			return t.Format("2006-01-02")
+		},
+		"number": func(v interface{}, places ...int) string {
+			p := 0
+			if len(places) > 0 {
+				p = places[0]
+			}
+			return formatNumber(toFloat(v), p)
+		},
+		"money": func(v interface{}, currency string) string {
+			if i18n.IsRTL(lang) {
+				return formatNumber(toFloat(v), 2) + " " + currency
+			}
+			return currency + " " + formatNumber(toFloat(v), 2)
+		},
+	}
+}
+
+// formatNumber formats v with thousands separators and fixed decimal places
+func formatNumber(v float64, places int) string {
+	s := strconv.FormatFloat(v, 'f', places, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	if frac != "" {
+		return sign + b.String() + "." + frac
+	}
+	return sign + b.String()
+}
+
+// toFloat accepts the numeric types templates are usually given
+func toFloat(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case float32:
+		return float64(n)
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	case string:
+		f, _ := strconv.ParseFloat(n, 64)
+		return f
+	default:
+		return 0
+	}
+}
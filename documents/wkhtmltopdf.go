@@ -0,0 +1,128 @@
+package documents
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WKHTMLToPDFConfig holds the wkhtmltopdf settings
+type WKHTMLToPDFConfig struct {
+	Binary  string        // defaults to wkhtmltopdf on PATH
+	Timeout time.Duration // per document, defaults to 60s
+}
+
+// WKHTMLToPDF renders PDFs with the wkhtmltopdf binary
+type WKHTMLToPDF struct {
+	cfg *WKHTMLToPDFConfig
+}
+
+// NewWKHTMLToPDF creates the engine
+func NewWKHTMLToPDF(cfg *WKHTMLToPDFConfig) *WKHTMLToPDF {
+	if cfg.Binary == "" {
+		cfg.Binary = "wkhtmltopdf"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 60 * time.Second
+	}
+	return &WKHTMLToPDF{cfg: cfg}
+}
+
+// Render implements Engine
+func (e *WKHTMLToPDF) Render(ctx context.Context, w io.Writer, html []byte, p PageOptions) error {
+	p = p.withDefaults()
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.Timeout)
+	defer cancel()
+
+	args := []string{
+		"--quiet",
+		"--encoding", "utf-8",
+		"--print-media-type",
+		"--page-size", pageSizeName(p.Size),
+		"--margin-top", mm(p.Margins.Top),
+		"--margin-right", mm(p.Margins.Right),
+		"--margin-bottom", mm(p.Margins.Bottom),
+		"--margin-left", mm(p.Margins.Left),
+	}
+	if p.Landscape {
+		args = append(args, "--orientation", "Landscape")
+	}
+
+	if p.Header != "" || p.Footer != "" {
+		dir, err := os.MkdirTemp("", "wkhtmltopdf-")
+		if err != nil {
+			return fmt.Errorf("failed to create temp dir: %w", err)
+		}
+		defer os.RemoveAll(dir)
+
+		for _, part := range []struct{ flag, name, html string }{
+			{"--header-html", "header.html", p.Header},
+			{"--footer-html", "footer.html", p.Footer},
+		} {
+			if part.html == "" {
+				continue
+			}
+			path := filepath.Join(dir, part.name)
+			if err := os.WriteFile(path, []byte(wkTemplate(part.html, p.Lang)), 0o600); err != nil {
+				return fmt.Errorf("failed to write %s: %w", part.name, err)
+			}
+			args = append(args, part.flag, path)
+		}
+	}
+	args = append(args, "-", "-")
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.cfg.Binary, args...)
+	cmd.Stdin = bytes.NewReader(html)
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to render PDF: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// Close implements Engine
+func (e *WKHTMLToPDF) Close() error {
+	return nil
+}
+
+// wkTemplate wraps a header or footer fragment in the page wkhtmltopdf loads with the page
+// numbers in its query string
+func wkTemplate(fragment, lang string) string {
+	return `<!DOCTYPE html><html dir="` + direction(lang) + `"><head><meta charset="utf-8"><script>
+function subst() {
+  var q = {};
+  document.location.search.substring(1).split('&').forEach(function (kv) {
+    var p = kv.split('=', 2); q[p[0]] = decodeURIComponent(p[1] || '');
+  });
+  [['page', 'page'], ['pages', 'topage']].forEach(function (m) {
+    var els = document.getElementsByClassName(m[0]);
+    for (var i = 0; i < els.length; i++) els[i].textContent = q[m[1]];
+  });
+}
+</script></head><body style="margin:0;font-size:9px;text-align:center;font-family:` + DefaultFontFamily + `" onload="subst()">` +
+		fragment + `</body></html>`
+}
+
+func pageSizeName(size string) string {
+	switch size {
+	case "LETTER":
+		return "Letter"
+	case "LEGAL":
+		return "Legal"
+	default:
+		return size
+	}
+}
+
+func mm(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64) + "mm"
+}
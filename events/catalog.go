@@ -0,0 +1,259 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/Masharah-Advisory/common/validation"
+	"github.com/go-playground/validator/v10"
+)
+
+// Errors returned by the catalog
+var (
+	ErrUnknownType    = errors.New("unknown event type")
+	ErrUnknownVersion = errors.New("unknown event version")
+	ErrInvalidPayload = errors.New("invalid event payload")
+)
+
+// Upcaster converts a payload of one version into the next version
+type Upcaster func(payload json.RawMessage) (json.RawMessage, error)
+
+// TypeInfo describes a registered event type
+type TypeInfo struct {
+	Type     string `json:"type"`
+	Latest   int    `json:"latest"`
+	Versions []int  `json:"versions"`
+}
+
+type catalogEntry struct {
+	schemas   map[int]reflect.Type
+	upcasters map[int]Upcaster
+	latest    int
+}
+
+// Catalog is the registry of event types, the Go struct of each version and the upcasters
+// between versions. Producers validate against it before publishing and consumers decode
+// through it, so a contract change is a new version rather than silent drift.
+type Catalog struct {
+	mu       sync.RWMutex
+	entries  map[string]*catalogEntry
+	validate *validator.Validate
+	// Strict rejects events of unregistered types in Validate; otherwise they pass unchecked
+	Strict bool
+}
+
+// NewCatalog creates an empty catalog; payload structs are validated with `validate` tags,
+// including the shared tags from the validation package
+func NewCatalog() *Catalog {
+	v := validator.New()
+	_ = validation.Register(v)
+	return &Catalog{entries: make(map[string]*catalogEntry), validate: v}
+}
+
+// Register adds payload struct T as version of eventType, e.g.
+// events.Register[OrderPlacedV2](catalog, "order.placed", 2). Versions start at 1.
+func Register[T any](c *Catalog, eventType string, version int) {
+	if version < 1 {
+		panic(fmt.Sprintf("events: version of %s must be at least 1", eventType))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[eventType]
+	if !ok {
+		e = &catalogEntry{schemas: make(map[int]reflect.Type), upcasters: make(map[int]Upcaster)}
+		c.entries[eventType] = e
+	}
+	if _, dup := e.schemas[version]; dup {
+		panic(fmt.Sprintf("events: %s v%d registered twice", eventType, version))
+	}
+	e.schemas[version] = reflect.TypeOf((*T)(nil)).Elem()
+	if version > e.latest {
+		e.latest = version
+	}
+}
+
+// Upcast registers fn to convert eventType payloads from version from to from+1; consumers
+// chain them to read old events as the latest version
+func (c *Catalog) Upcast(eventType string, from int, fn Upcaster) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[eventType]
+	if !ok {
+		panic(fmt.Sprintf("events: register %s before its upcasters", eventType))
+	}
+	e.upcasters[from] = fn
+}
+
+// Types lists the registered event types, e.g. for a catalog endpoint
+func (c *Catalog) Types() []TypeInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]TypeInfo, 0, len(c.entries))
+	for name, e := range c.entries {
+		info := TypeInfo{Type: name, Latest: e.latest}
+		for v := range e.schemas {
+			info.Versions = append(info.Versions, v)
+		}
+		sort.Ints(info.Versions)
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Type < out[j].Type })
+	return out
+}
+
+// Validate checks that the event's payload matches its registered version: no unknown fields
+// and every `validate` tag satisfied
+func (c *Catalog) Validate(event *Event) error {
+	c.mu.RLock()
+	e, ok := c.entries[event.Type]
+	c.mu.RUnlock()
+	if !ok {
+		if c.Strict {
+			return fmt.Errorf("%w: %s", ErrUnknownType, event.Type)
+		}
+		return nil
+	}
+	schema, ok := e.schemas[versionOf(event)]
+	if !ok {
+		return fmt.Errorf("%w: %s v%d", ErrUnknownVersion, event.Type, versionOf(event))
+	}
+	_, err := c.decode(event.Type, event.Payload, schema)
+	return err
+}
+
+// NewTypedEvent builds an event like NewEvent, setting the version registered for T and
+// validating the payload, so invalid events fail at the producer
+func NewTypedEvent[T any](ctx context.Context, c *Catalog, eventType string, payload T) (*Event, error) {
+	version, err := c.versionFor(eventType, reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	event, err := NewEvent(ctx, eventType, payload)
+	if err != nil {
+		return nil, err
+	}
+	event.Version = version
+	if err := c.Validate(event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// Decode upcasts the event's payload to the latest version and decodes it into T, which must
+// be the struct registered for that version
+func Decode[T any](c *Catalog, event *Event) (T, error) {
+	var zero T
+	c.mu.RLock()
+	e, ok := c.entries[event.Type]
+	c.mu.RUnlock()
+	if !ok {
+		return zero, fmt.Errorf("%w: %s", ErrUnknownType, event.Type)
+	}
+	want := reflect.TypeOf((*T)(nil)).Elem()
+	if e.schemas[e.latest] != want {
+		return zero, fmt.Errorf("events: %s v%d is %s, not %s", event.Type, e.latest, e.schemas[e.latest], want)
+	}
+
+	payload := event.Payload
+	for v := versionOf(event); v < e.latest; v++ {
+		fn, ok := e.upcasters[v]
+		if !ok {
+			return zero, fmt.Errorf("%w: no upcaster for %s v%d", ErrUnknownVersion, event.Type, v)
+		}
+		var err error
+		if payload, err = fn(payload); err != nil {
+			return zero, fmt.Errorf("failed to upcast %s v%d: %w", event.Type, v, err)
+		}
+	}
+
+	v, err := c.decode(event.Type, payload, want)
+	if err != nil {
+		return zero, err
+	}
+	return *v.(*T), nil
+}
+
+// Handle adapts a typed handler: the payload is decoded with Decode, and events that cannot
+// be decoded are dead-lettered without retries
+func Handle[T any](c *Catalog, fn func(ctx context.Context, event *Event, payload T) error) Handler {
+	return func(ctx context.Context, event *Event) error {
+		payload, err := Decode[T](c, event)
+		if err != nil {
+			return Permanent(err)
+		}
+		return fn(ctx, event, payload)
+	}
+}
+
+// ValidatingPublisher validates every event against the catalog before handing it to the
+// wrapped publisher; nothing is published when one event is invalid
+func (c *Catalog) ValidatingPublisher(p Publisher) Publisher {
+	return &validatingPublisher{Publisher: p, catalog: c}
+}
+
+type validatingPublisher struct {
+	Publisher
+	catalog *Catalog
+}
+
+// Publish implements Publisher
+func (p *validatingPublisher) Publish(ctx context.Context, topic string, events ...*Event) error {
+	for _, e := range events {
+		if err := p.catalog.Validate(e); err != nil {
+			return err
+		}
+	}
+	return p.Publisher.Publish(ctx, topic, events...)
+}
+
+// decode strictly unmarshals payload into a new value of schema and validates it
+func (c *Catalog) decode(eventType string, payload json.RawMessage, schema reflect.Type) (interface{}, error) {
+	v := reflect.New(schema).Interface()
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidPayload, eventType, err)
+	}
+	if reflect.Indirect(reflect.ValueOf(v)).Kind() == reflect.Struct {
+		if err := c.validate.Struct(v); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidPayload, eventType, err)
+		}
+	}
+	return v, nil
+}
+
+func (c *Catalog) versionFor(eventType string, t reflect.Type) (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	e, ok := c.entries[eventType]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownType, eventType)
+	}
+	for v, schema := range e.schemas {
+		if schema == t {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s has no version for %s", ErrUnknownVersion, eventType, t)
+}
+
+// versionOf treats events published before versioning as version 1
+func versionOf(e *Event) int {
+	if e.Version < 1 {
+		return 1
+	}
+	return e.Version
+}
+
+var defaultCatalog = NewCatalog()
+
+// DefaultCatalog returns the process-wide catalog
+func DefaultCatalog() *Catalog {
+	return defaultCatalog
+}
@@ -0,0 +1,84 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Supported backends
+const (
+	BackendKafka    = "kafka"
+	BackendRabbitMQ = "rabbitmq"
+	BackendNATS     = "nats"
+)
+
+// Config selects and configures the events backend
+type Config struct {
+	Backend  string
+	Kafka    *KafkaConfig
+	RabbitMQ *RabbitMQConfig
+	NATS     *NATSConfig
+}
+
+// NewPublisher creates the publisher for the configured backend
+func NewPublisher(cfg *Config) (Publisher, error) {
+	switch strings.ToLower(cfg.Backend) {
+	case BackendKafka:
+		if cfg.Kafka == nil {
+			return nil, fmt.Errorf("events backend %q selected but not configured", cfg.Backend)
+		}
+		return NewKafkaPublisher(cfg.Kafka), nil
+	case BackendRabbitMQ:
+		if cfg.RabbitMQ == nil {
+			return nil, fmt.Errorf("events backend %q selected but not configured", cfg.Backend)
+		}
+		p, err := NewRabbitMQPublisher(cfg.RabbitMQ)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	case BackendNATS:
+		if cfg.NATS == nil {
+			return nil, fmt.Errorf("events backend %q selected but not configured", cfg.Backend)
+		}
+		p, err := NewNATSPublisher(context.Background(), cfg.NATS)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	default:
+		return nil, fmt.Errorf("unsupported events backend: %q", cfg.Backend)
+	}
+}
+
+// NewSubscriber creates the subscriber for the configured backend
+func NewSubscriber(cfg *Config) (Subscriber, error) {
+	switch strings.ToLower(cfg.Backend) {
+	case BackendKafka:
+		if cfg.Kafka == nil {
+			return nil, fmt.Errorf("events backend %q selected but not configured", cfg.Backend)
+		}
+		return NewKafkaSubscriber(cfg.Kafka), nil
+	case BackendRabbitMQ:
+		if cfg.RabbitMQ == nil {
+			return nil, fmt.Errorf("events backend %q selected but not configured", cfg.Backend)
+		}
+		s, err := NewRabbitMQSubscriber(cfg.RabbitMQ)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case BackendNATS:
+		if cfg.NATS == nil {
+			return nil, fmt.Errorf("events backend %q selected but not configured", cfg.Backend)
+		}
+		s, err := NewNATSSubscriber(context.Background(), cfg.NATS)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unsupported events backend: %q", cfg.Backend)
+	}
+}
@@ -0,0 +1,142 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/retry"
+	"github.com/Masharah-Advisory/common/utils"
+	"github.com/google/uuid"
+)
+
+// Event is the envelope shared by every domain event, regardless of the transport
+type Event struct {
+	ID           string            `json:"id"`
+	Type         string            `json:"type"`
+	Version      int               `json:"version,omitempty"`
+	Source       string            `json:"source"`
+	TenantID     string            `json:"tenant_id,omitempty"`
+	Key          string            `json:"key,omitempty"`
+	OccurredAt   time.Time         `json:"occurred_at"`
+	Payload      json.RawMessage   `json:"payload"`
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+}
+
+// Handler processes a single event; returning an error triggers a retry (see Permanent)
+type Handler func(ctx context.Context, event *Event) error
+
+// Publisher emits events to a topic
+type Publisher interface {
+	Publish(ctx context.Context, topic string, events ...*Event) error
+	Close() error
+}
+
+// Subscriber consumes events from a topic; Subscribe blocks until ctx is cancelled or Close is called
+type Subscriber interface {
+	Subscribe(ctx context.Context, topic string, handler Handler) error
+	Close() error
+}
+
+// Trace context keys carried on events
+const (
+	TraceRequestID = "request_id"
+	TraceTraceID   = "trace_id"
+	TraceUserID    = "user_id"
+)
+
+// NewEvent builds an event of the given type, marshalling payload and copying tenant and
+// trace information from ctx (Gin or standard context)
+func NewEvent(ctx context.Context, eventType string, payload interface{}) (*Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event payload: %w", err)
+	}
+
+	event := &Event{
+		ID:           uuid.New().String(),
+		Type:         eventType,
+		Source:       utils.ServiceID,
+		OccurredAt:   time.Now().UTC(),
+		Payload:      data,
+		TraceContext: make(map[string]string),
+	}
+
+	for _, key := range []string{TraceRequestID, TraceTraceID, TraceUserID, "tenant_id"} {
+		value := contextString(ctx, key)
+		if value == "" {
+			continue
+		}
+		if key == "tenant_id" {
+			event.TenantID = value
+			continue
+		}
+		event.TraceContext[key] = value
+	}
+
+	return event, nil
+}
+
+// Decode unmarshals the event payload into v
+func (e *Event) Decode(v interface{}) error {
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("failed to decode %s payload: %w", e.Type, err)
+	}
+	return nil
+}
+
+// PartitionKey returns the key used for ordering, defaulting to the tenant and then the event ID
+func (e *Event) PartitionKey() string {
+	switch {
+	case e.Key != "":
+		return e.Key
+	case e.TenantID != "":
+		return e.TenantID
+	default:
+		return e.ID
+	}
+}
+
+// contextString reads a request-scoped value from a Gin or standard context as a string
+func contextString(ctx context.Context, key string) string {
+	switch key {
+	case TraceRequestID:
+		return ctxutil.RequestID(ctx)
+	case TraceTraceID:
+		return ctxutil.TraceID(ctx)
+	case TraceUserID:
+		if id, ok := ctxutil.UserID(ctx); ok {
+			return strconv.FormatUint(id, 10)
+		}
+		return ""
+	case ctxutil.TenantIDKey:
+		return ctxutil.TenantID(ctx)
+	}
+	return ""
+}
+
+type eventCtxKey struct{}
+
+// ContextWithEvent returns a context carrying the event being handled
+func ContextWithEvent(ctx context.Context, event *Event) context.Context {
+	return context.WithValue(ctx, eventCtxKey{}, event)
+}
+
+// FromContext returns the event being handled, if any
+func FromContext(ctx context.Context) (*Event, bool) {
+	event, ok := ctx.Value(eventCtxKey{}).(*Event)
+	return event, ok
+}
+
+// Permanent wraps err so the subscriber skips retries and dead-letters the event immediately
+func Permanent(err error) error {
+	return retry.Permanent(err)
+}
+
+// IsPermanent reports whether err was marked with Permanent
+func IsPermanent(err error) bool {
+	return retry.IsPermanent(err)
+}
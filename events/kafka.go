@@ -0,0 +1,288 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// KafkaConfig holds Kafka connection and consumer settings
+type KafkaConfig struct {
+	Brokers      []string
+	GroupID      string
+	DLQSuffix    string // appended to the topic name for dead letters, defaults to ".dlq"
+	BatchTimeout time.Duration
+	Retry        RetryConfig
+	// ShutdownTimeout is how long an in-flight event may keep running once the subscription
+	// stops, defaults to 30s; the event is then redelivered to the group
+	ShutdownTimeout time.Duration
+}
+
+func (cfg *KafkaConfig) dlqTopic(topic string) string {
+	suffix := cfg.DLQSuffix
+	if suffix == "" {
+		suffix = ".dlq"
+	}
+	return topic + suffix
+}
+
+// KafkaPublisher publishes events to Kafka topics
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaPublisher creates a publisher; the topic is chosen per Publish call
+func NewKafkaPublisher(cfg *KafkaConfig) *KafkaPublisher {
+	batchTimeout := cfg.BatchTimeout
+	if batchTimeout <= 0 {
+		batchTimeout = 10 * time.Millisecond
+	}
+
+	return &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(cfg.Brokers...),
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			BatchTimeout:           batchTimeout,
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+// Publish writes the events to topic, keyed by their partition key so related events stay ordered
+func (p *KafkaPublisher) Publish(ctx context.Context, topic string, events ...*Event) error {
+	messages := make([]kafka.Message, 0, len(events))
+	for _, event := range events {
+		msg, err := toKafkaMessage(topic, event)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, msg)
+	}
+
+	if err := p.writer.WriteMessages(ctx, messages...); err != nil {
+		return fmt.Errorf("failed to publish to kafka topic %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Close flushes pending messages and closes the writer
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// toKafkaMessage encodes an event, mirroring type and trace info into headers for tooling
+func toKafkaMessage(topic string, event *Event) (kafka.Message, error) {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return kafka.Message{}, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	headers := []kafka.Header{
+		{Key: "event_id", Value: []byte(event.ID)},
+		{Key: "event_type", Value: []byte(event.Type)},
+	}
+	for key, val := range event.TraceContext {
+		headers = append(headers, kafka.Header{Key: key, Value: []byte(val)})
+	}
+
+	return kafka.Message{
+		Topic:   topic,
+		Key:     []byte(event.PartitionKey()),
+		Value:   value,
+		Headers: headers,
+		Time:    event.OccurredAt,
+	}, nil
+}
+
+// kafkaReader is the part of *kafka.Reader a subscription uses
+type kafkaReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// kafkaWriter is the part of *kafka.Writer dead-lettering uses
+type kafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// KafkaSubscriber consumes topics as part of a consumer group
+type KafkaSubscriber struct {
+	cfg       *KafkaConfig
+	dlq       *KafkaPublisher
+	dlqWriter kafkaWriter
+	mu        sync.Mutex
+	readers   []*kafka.Reader
+	wg        sync.WaitGroup
+	closed    bool
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewKafkaSubscriber creates a consumer-group subscriber; failed events go to "<topic><DLQSuffix>"
+func NewKafkaSubscriber(cfg *KafkaConfig) *KafkaSubscriber {
+	dlq := NewKafkaPublisher(cfg)
+	return &KafkaSubscriber{
+		cfg:       cfg,
+		dlq:       dlq,
+		dlqWriter: dlq.writer,
+		done:      make(chan struct{}),
+	}
+}
+
+// Subscribe consumes topic until ctx is cancelled or Close is called. Offsets are committed only
+// after the handler succeeded or the event was dead-lettered, so nothing is lost on shutdown.
+// When an event can be neither handled nor dead-lettered, Subscribe stops with the error rather
+// than move past it; the group redelivers it once a subscriber is back.
+func (s *KafkaSubscriber) Subscribe(ctx context.Context, topic string, handler Handler) error {
+	if s.cfg.GroupID == "" {
+		return errors.New("kafka subscriber requires a consumer GroupID")
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return errors.New("kafka subscriber is closed")
+	}
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:  s.cfg.Brokers,
+		GroupID:  s.cfg.GroupID,
+		Topic:    topic,
+		MinBytes: 1,
+		MaxBytes: 10e6,
+	})
+	s.readers = append(s.readers, reader)
+	s.wg.Add(1)
+	s.mu.Unlock()
+	defer s.wg.Done()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-s.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return s.consume(ctx, reader, topic, handler)
+}
+
+// consume handles the messages of reader in order until ctx is done or one is neither handled
+// nor dead-lettered
+func (s *KafkaSubscriber) consume(ctx context.Context, reader kafkaReader, topic string, handler Handler) error {
+	log := logger.Module("events").With(zap.String("topic", topic), zap.String("group", s.cfg.GroupID))
+	log.Info("kafka subscriber started")
+
+	grace := s.cfg.ShutdownTimeout
+	if grace <= 0 {
+		grace = 30 * time.Second
+	}
+	for {
+		msg, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				log.Info("kafka subscriber stopped")
+				return nil
+			}
+			return fmt.Errorf("failed to fetch kafka message: %w", err)
+		}
+
+		// An in-flight event may finish during graceful shutdown, within the grace period
+		msgCtx, cancel := detach(ctx, grace)
+		err = s.process(msgCtx, topic, msg, handler)
+		if err == nil {
+			if err := reader.CommitMessages(msgCtx, msg); err != nil {
+				log.Error("failed to commit kafka offset", zap.Int64("offset", msg.Offset), zap.Error(err))
+			}
+		}
+		cancel()
+		if err != nil {
+			// Committing a later offset would skip this one, so stop here
+			if ctx.Err() != nil {
+				log.Info("kafka subscriber stopped, in-flight event left for redelivery", zap.Int64("offset", msg.Offset))
+				return nil
+			}
+			return fmt.Errorf("failed to process kafka message at offset %d: %w", msg.Offset, err)
+		}
+	}
+}
+
+// process decodes and handles a message, dead-lettering it when it cannot be handled. An event
+// cut short by shutdown is not dead-lettered.
+func (s *KafkaSubscriber) process(ctx context.Context, topic string, msg kafka.Message, handler Handler) error {
+	var event Event
+	if err := json.Unmarshal(msg.Value, &event); err != nil {
+		return s.deadLetter(ctx, topic, msg, fmt.Errorf("invalid event envelope: %w", err))
+	}
+
+	if err := handleWithRetry(ctx, s.cfg.Retry, handler, &event); err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		return s.deadLetter(ctx, topic, msg, err)
+	}
+	return nil
+}
+
+// deadLetter forwards the original message to the DLQ topic with the failure reason
+func (s *KafkaSubscriber) deadLetter(ctx context.Context, topic string, msg kafka.Message, cause error) error {
+	headers := append([]kafka.Header{}, msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: "dlq_error", Value: []byte(cause.Error())},
+		kafka.Header{Key: "dlq_original_topic", Value: []byte(topic)},
+		kafka.Header{Key: "dlq_failed_at", Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+	)
+
+	dlqTopic := s.cfg.dlqTopic(topic)
+	err := s.dlqWriter.WriteMessages(ctx, kafka.Message{
+		Topic:   dlqTopic,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to dead-letter message to %s: %w", dlqTopic, err)
+	}
+
+	logger.Module("events").Warn("event dead-lettered",
+		zap.String("topic", topic),
+		zap.String("dlq_topic", dlqTopic),
+		zap.Error(cause),
+	)
+	return nil
+}
+
+// Close stops all subscriptions, waits for in-flight events and closes the readers
+func (s *KafkaSubscriber) Close() error {
+	var errs []error
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		close(s.done)
+		s.mu.Unlock()
+
+		s.wg.Wait()
+
+		s.mu.Lock()
+		for _, reader := range s.readers {
+			if err := reader.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		s.mu.Unlock()
+
+		if err := s.dlq.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	})
+	return errors.Join(errs...)
+}
@@ -0,0 +1,121 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// fakeReader serves msgs in order, then blocks until ctx is done
+type fakeReader struct {
+	msgs      []kafka.Message
+	committed []int64
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	if len(r.msgs) == 0 {
+		<-ctx.Done()
+		return kafka.Message{}, ctx.Err()
+	}
+	msg := r.msgs[0]
+	r.msgs = r.msgs[1:]
+	return msg, nil
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	for _, msg := range msgs {
+		r.committed = append(r.committed, msg.Offset)
+	}
+	return nil
+}
+
+type fakeWriter struct {
+	err     error
+	written int
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.written += len(msgs)
+	return nil
+}
+
+func TestKafkaConsumeCommits(t *testing.T) {
+	message := func(offset int64, eventType string) kafka.Message {
+		value, _ := json.Marshal(&Event{ID: "e", Type: eventType})
+		return kafka.Message{Offset: offset, Value: value}
+	}
+	handler := func(ctx context.Context, event *Event) error {
+		if event.Type == "bad" {
+			return Permanent(errors.New("cannot handle"))
+		}
+		return nil
+	}
+	tests := []struct {
+		name          string
+		dlqErr        error
+		wantErr       bool
+		wantCommitted []int64
+		wantDead      int
+	}{
+		{"failed event dead-lettered", nil, false, []int64{1, 2, 3}, 1},
+		{"dead-lettering fails", errors.New("broker down"), true, []int64{1}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &fakeReader{msgs: []kafka.Message{message(1, "ok"), message(2, "bad"), message(3, "ok")}}
+			writer := &fakeWriter{err: tt.dlqErr}
+			s := &KafkaSubscriber{cfg: &KafkaConfig{GroupID: "g"}, dlqWriter: writer}
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+
+			err := s.consume(ctx, reader, "orders", handler)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("consume = %v, want error %v", err, tt.wantErr)
+			}
+			if len(reader.committed) != len(tt.wantCommitted) {
+				t.Fatalf("committed %v, want %v", reader.committed, tt.wantCommitted)
+			}
+			for i, offset := range tt.wantCommitted {
+				if reader.committed[i] != offset {
+					t.Fatalf("committed %v, want %v", reader.committed, tt.wantCommitted)
+				}
+			}
+			if writer.written != tt.wantDead {
+				t.Errorf("dead-lettered %d, want %d", writer.written, tt.wantDead)
+			}
+		})
+	}
+}
+
+func TestKafkaConsumeShutdownGrace(t *testing.T) {
+	reader := &fakeReader{msgs: []kafka.Message{{Offset: 1, Value: []byte(`{"id":"e","type":"slow"}`)}}}
+	writer := &fakeWriter{}
+	s := &KafkaSubscriber{
+		cfg:       &KafkaConfig{GroupID: "g", ShutdownTimeout: 50 * time.Millisecond, Retry: RetryConfig{MaxRetries: 100}},
+		dlqWriter: writer,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := func(ctx context.Context, event *Event) error {
+		cancel()
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	start := time.Now()
+	if err := s.consume(ctx, reader, "orders", handler); err != nil {
+		t.Fatalf("consume = %v, want nil on shutdown", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("shutdown took %v, want about the 50ms grace", elapsed)
+	}
+	if len(reader.committed) != 0 || writer.written != 0 {
+		t.Errorf("committed %v and dead-lettered %d, want the event left for redelivery", reader.committed, writer.written)
+	}
+}
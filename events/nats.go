@@ -0,0 +1,307 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masharah-Advisory/common/dedupe"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/zap"
+)
+
+// NATSConfig holds NATS JetStream connection, stream and consumer settings
+type NATSConfig struct {
+	URL        string
+	Stream     string   // stream name, e.g. "EVENTS"
+	Subjects   []string // subjects captured by the stream, e.g. "events.>"
+	Durable    string   // durable consumer prefix, usually the service ID
+	MaxAge     time.Duration
+	MaxDeliver int
+	AckWait    time.Duration
+	Retry      RetryConfig
+
+	// Dedupe skips events that were already handled by this consumer (optional); a duplicate
+	// that arrives while the first delivery is still being handled is redelivered later
+	Dedupe    dedupe.Store
+	DedupeTTL time.Duration
+}
+
+// NATSPublisher publishes events to JetStream subjects
+type NATSPublisher struct {
+	nc *nats.Conn
+	js jetstream.JetStream
+}
+
+// NewNATSPublisher connects to NATS and provisions the configured stream
+func NewNATSPublisher(ctx context.Context, cfg *NATSConfig) (*NATSPublisher, error) {
+	nc, js, err := connectJetStream(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Stream != "" && len(cfg.Subjects) > 0 {
+		if _, err := EnsureStream(ctx, js, cfg); err != nil {
+			nc.Close()
+			return nil, err
+		}
+	}
+
+	return &NATSPublisher{nc: nc, js: js}, nil
+}
+
+// Publish sends the events to subject topic; the event ID doubles as the JetStream message ID
+// so broker-side deduplication drops publisher retries
+func (p *NATSPublisher) Publish(ctx context.Context, topic string, events ...*Event) error {
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+
+		msg := nats.NewMsg(topic)
+		msg.Data = data
+		msg.Header.Set("event_type", event.Type)
+		for key, val := range event.TraceContext {
+			msg.Header.Set(key, val)
+		}
+
+		if _, err := p.js.PublishMsg(ctx, msg, jetstream.WithMsgID(event.ID)); err != nil {
+			return fmt.Errorf("failed to publish to nats subject %s: %w", topic, err)
+		}
+	}
+	return nil
+}
+
+// Close drains and closes the connection
+func (p *NATSPublisher) Close() error {
+	return p.nc.Drain()
+}
+
+// NATSSubscriber consumes subjects through durable JetStream consumers
+type NATSSubscriber struct {
+	cfg       *NATSConfig
+	nc        *nats.Conn
+	js        jetstream.JetStream
+	wg        sync.WaitGroup
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewNATSSubscriber connects to NATS and provisions the configured stream
+func NewNATSSubscriber(ctx context.Context, cfg *NATSConfig) (*NATSSubscriber, error) {
+	if cfg.Stream == "" || cfg.Durable == "" {
+		return nil, errors.New("nats subscriber requires Stream and Durable")
+	}
+
+	nc, js, err := connectJetStream(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Subjects) > 0 {
+		if _, err := EnsureStream(ctx, js, cfg); err != nil {
+			nc.Close()
+			return nil, err
+		}
+	}
+
+	return &NATSSubscriber{cfg: cfg, nc: nc, js: js, done: make(chan struct{})}, nil
+}
+
+// Subscribe consumes topic until ctx is cancelled or Close is called
+func (s *NATSSubscriber) Subscribe(ctx context.Context, topic string, handler Handler) error {
+	consumer, err := EnsureConsumer(ctx, s.js, s.cfg, topic)
+	if err != nil {
+		return err
+	}
+
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	log := logger.Module("events").With(zap.String("subject", topic), zap.String("stream", s.cfg.Stream))
+
+	var inflight sync.WaitGroup
+	consumeCtx, err := consumer.Consume(func(msg jetstream.Msg) {
+		inflight.Add(1)
+		defer inflight.Done()
+		s.handleMsg(context.WithoutCancel(ctx), msg, handler, log)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start nats consumer: %w", err)
+	}
+	log.Info("nats subscriber started")
+
+	select {
+	case <-ctx.Done():
+	case <-s.done:
+	}
+
+	consumeCtx.Stop()
+	inflight.Wait()
+	log.Info("nats subscriber stopped")
+	return nil
+}
+
+// handleMsg dedupes, handles and acknowledges a single message
+func (s *NATSSubscriber) handleMsg(ctx context.Context, msg jetstream.Msg, handler Handler, log *zap.Logger) {
+	var event Event
+	if err := json.Unmarshal(msg.Data(), &event); err != nil {
+		log.Error("invalid event envelope, terminating delivery", zap.Error(err))
+		_ = msg.Term()
+		return
+	}
+
+	dedupeKey := "events:" + s.cfg.Durable + ":" + event.ID
+	if s.cfg.Dedupe != nil {
+		// The claim lasts as long as JetStream waits for the ack; past that the delivery is
+		// considered lost and a redelivery may take over
+		claimed, err := s.cfg.Dedupe.Claim(ctx, dedupeKey, s.cfg.ackWait())
+		if err != nil {
+			log.Warn("dedupe store unavailable, redelivering", zap.String("event_id", event.ID), zap.Error(err))
+			_ = msg.Nak()
+			return
+		}
+		if !claimed {
+			s.skipDuplicate(ctx, msg, dedupeKey, event.ID, log)
+			return
+		}
+	}
+
+	if err := handleWithRetry(ctx, s.cfg.Retry, handler, &event); err != nil {
+		if s.cfg.Dedupe != nil {
+			_ = s.cfg.Dedupe.Release(ctx, dedupeKey)
+		}
+
+		log.Warn("event handler failed",
+			zap.String("event_id", event.ID),
+			zap.String("event_type", event.Type),
+			zap.Error(err),
+		)
+
+		if IsPermanent(err) {
+			_ = msg.Term()
+			return
+		}
+		_ = msg.NakWithDelay(s.cfg.Retry.withDefaults().RetryBackoff)
+		return
+	}
+
+	if s.cfg.Dedupe != nil {
+		ttl := s.cfg.DedupeTTL
+		if ttl <= 0 {
+			ttl = 24 * time.Hour
+		}
+		if err := s.cfg.Dedupe.Complete(ctx, dedupeKey, ttl); err != nil {
+			log.Warn("failed to record handled event", zap.String("event_id", event.ID), zap.Error(err))
+		}
+	}
+	if err := msg.Ack(); err != nil {
+		log.Error("failed to ack nats message", zap.String("event_id", event.ID), zap.Error(err))
+	}
+}
+
+// skipDuplicate acks an event another delivery already handled, and redelivers one that is still
+// being handled so it isn't lost if that delivery fails
+func (s *NATSSubscriber) skipDuplicate(ctx context.Context, msg jetstream.Msg, key, eventID string, log *zap.Logger) {
+	done, err := s.cfg.Dedupe.Completed(ctx, key)
+	if err != nil {
+		log.Warn("dedupe store unavailable, redelivering", zap.String("event_id", eventID), zap.Error(err))
+		_ = msg.Nak()
+		return
+	}
+	if !done {
+		log.Debug("event still in flight, redelivering later", zap.String("event_id", eventID))
+		_ = msg.NakWithDelay(s.cfg.Retry.withDefaults().RetryBackoff)
+		return
+	}
+	log.Debug("duplicate event skipped", zap.String("event_id", eventID))
+	_ = msg.Ack()
+}
+
+// Close stops all subscriptions, waits for in-flight events and drains the connection
+func (s *NATSSubscriber) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.wg.Wait()
+		err = s.nc.Drain()
+	})
+	return err
+}
+
+// EnsureStream creates or updates the stream described by cfg
+func EnsureStream(ctx context.Context, js jetstream.JetStream, cfg *NATSConfig) (jetstream.Stream, error) {
+	maxAge := cfg.MaxAge
+	if maxAge <= 0 {
+		maxAge = 7 * 24 * time.Hour
+	}
+
+	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:       cfg.Stream,
+		Subjects:   cfg.Subjects,
+		Retention:  jetstream.LimitsPolicy,
+		Storage:    jetstream.FileStorage,
+		MaxAge:     maxAge,
+		Duplicates: 2 * time.Minute,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to provision nats stream %s: %w", cfg.Stream, err)
+	}
+	return stream, nil
+}
+
+// EnsureConsumer creates or updates the durable consumer for subject
+func EnsureConsumer(ctx context.Context, js jetstream.JetStream, cfg *NATSConfig, subject string) (jetstream.Consumer, error) {
+	maxDeliver := cfg.MaxDeliver
+	if maxDeliver <= 0 {
+		maxDeliver = 5
+	}
+	consumer, err := js.CreateOrUpdateConsumer(ctx, cfg.Stream, jetstream.ConsumerConfig{
+		Durable:       consumerName(cfg.Durable, subject),
+		FilterSubject: subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+		MaxDeliver:    maxDeliver,
+		AckWait:       cfg.ackWait(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to provision nats consumer for %s: %w", subject, err)
+	}
+	return consumer, nil
+}
+
+// ackWait returns the configured ack wait, defaulting to 30s
+func (cfg *NATSConfig) ackWait() time.Duration {
+	if cfg.AckWait <= 0 {
+		return 30 * time.Second
+	}
+	return cfg.AckWait
+}
+
+// consumerName builds a valid durable name (no dots or wildcards) from the prefix and subject
+func consumerName(prefix, subject string) string {
+	replacer := strings.NewReplacer(".", "_", "*", "any", ">", "all")
+	return prefix + "_" + replacer.Replace(subject)
+}
+
+// connectJetStream opens the NATS connection and JetStream context
+func connectJetStream(cfg *NATSConfig) (*nats.Conn, jetstream.JetStream, error) {
+	nc, err := nats.Connect(cfg.URL, nats.Name(cfg.Durable), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return nil, nil, fmt.Errorf("failed to create jetstream context: %w", err)
+	}
+	return nc, js, nil
+}
@@ -0,0 +1,72 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Masharah-Advisory/common/dedupe"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/zap"
+)
+
+// fakeMsg records how a delivery was settled
+type fakeMsg struct {
+	jetstream.Msg
+	data    []byte
+	settled string
+}
+
+func (m *fakeMsg) Data() []byte                     { return m.data }
+func (m *fakeMsg) Ack() error                       { m.settled = "ack"; return nil }
+func (m *fakeMsg) Nak() error                       { m.settled = "nak"; return nil }
+func (m *fakeMsg) NakWithDelay(time.Duration) error { m.settled = "nak"; return nil }
+func (m *fakeMsg) Term() error                      { m.settled = "term"; return nil }
+
+// TestNATSDuplicateWhileInFlight checks that a redelivery arriving while the first delivery is
+// still being handled is redelivered later instead of acked, and acked once the first completes
+func TestNATSDuplicateWhileInFlight(t *testing.T) {
+	mr := miniredis.RunT(t)
+	s := &NATSSubscriber{cfg: &NATSConfig{
+		Durable: "test",
+		Dedupe:  dedupe.NewRedisStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}), ""),
+	}}
+	data, _ := json.Marshal(&Event{ID: "evt-1", Type: "user.created"})
+	delivery := func() *fakeMsg { return &fakeMsg{data: data} }
+
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := func(ctx context.Context, event *Event) error {
+		close(started)
+		<-release
+		return nil
+	}
+
+	first := delivery()
+	done := make(chan struct{})
+	go func() {
+		s.handleMsg(context.Background(), first, handler, zap.NewNop())
+		close(done)
+	}()
+	<-started
+
+	second := delivery()
+	s.handleMsg(context.Background(), second, handler, zap.NewNop())
+	if second.settled != "nak" {
+		t.Fatalf("in-flight duplicate settled with %q, want nak", second.settled)
+	}
+
+	close(release)
+	<-done
+	if first.settled != "ack" {
+		t.Fatalf("first delivery settled with %q, want ack", first.settled)
+	}
+
+	third := delivery()
+	s.handleMsg(context.Background(), third, handler, zap.NewNop())
+	if third.settled != "ack" {
+		t.Fatalf("duplicate of a handled event settled with %q, want ack", third.settled)
+	}
+}
@@ -0,0 +1,328 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	logger "github.com/Masharah-Advisory/common/loggers"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// FailurePolicy decides what happens to a message whose handler failed after all retries
+type FailurePolicy string
+
+const (
+	// DeadLetter routes the message to the queue's dead-letter exchange (default)
+	DeadLetter FailurePolicy = "dead_letter"
+	// Requeue puts the message back on the queue for redelivery
+	Requeue FailurePolicy = "requeue"
+	// Drop acknowledges and discards the message
+	Drop FailurePolicy = "drop"
+)
+
+// RabbitMQConfig holds AMQP connection, topology and consumer settings
+type RabbitMQConfig struct {
+	URL       string
+	Exchange  string // topic exchange events are published to, defaults to "events"
+	QueueName string // consumer queue prefix, usually the service ID; the topic is appended
+	Prefetch  int
+	OnFailure FailurePolicy
+	Retry     RetryConfig
+}
+
+func (cfg *RabbitMQConfig) exchange() string {
+	if cfg.Exchange == "" {
+		return "events"
+	}
+	return cfg.Exchange
+}
+
+func (cfg *RabbitMQConfig) deadLetterExchange() string {
+	return cfg.exchange() + ".dlx"
+}
+
+// ErrUnroutable is returned by Publish when the broker had no queue bound for an event
+var ErrUnroutable = errors.New("event not routed to any queue")
+
+// RabbitMQPublisher publishes events to a topic exchange with publisher confirms
+type RabbitMQPublisher struct {
+	cfg     *RabbitMQConfig
+	conn    *amqp.Connection
+	ch      *amqp.Channel
+	returns chan amqp.Return
+	mu      sync.Mutex
+}
+
+// NewRabbitMQPublisher connects, declares the exchange and enables publisher confirms
+func NewRabbitMQPublisher(cfg *RabbitMQConfig) (*RabbitMQPublisher, error) {
+	conn, err := amqp.Dial(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to rabbitmq: %w", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open rabbitmq channel: %w", err)
+	}
+
+	if err := ch.ExchangeDeclare(cfg.exchange(), amqp.ExchangeTopic, true, false, false, false, nil); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to declare exchange %s: %w", cfg.exchange(), err)
+	}
+
+	if err := ch.Confirm(false); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+
+	// Events are published as mandatory, so the broker hands back those no queue is bound for
+	returns := ch.NotifyReturn(make(chan amqp.Return, 64))
+
+	return &RabbitMQPublisher{cfg: cfg, conn: conn, ch: ch, returns: returns}, nil
+}
+
+// Publish sends the events with the topic as routing key and waits for the broker to confirm each
+// one; events no queue is bound for fail with ErrUnroutable
+func (p *RabbitMQPublisher) Publish(ctx context.Context, topic string, events ...*Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ids := make(map[string]bool, len(events))
+	confirms := make([]*amqp.DeferredConfirmation, 0, len(events))
+	for _, event := range events {
+		ids[event.ID] = true
+		body, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+
+		headers := amqp.Table{}
+		for key, val := range event.TraceContext {
+			headers[key] = val
+		}
+
+		confirm, err := p.ch.PublishWithDeferredConfirmWithContext(ctx, p.cfg.exchange(), topic, true, false, amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			MessageId:    event.ID,
+			Type:         event.Type,
+			AppId:        event.Source,
+			Timestamp:    event.OccurredAt,
+			Headers:      headers,
+			Body:         body,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to publish to rabbitmq topic %s: %w", topic, err)
+		}
+		confirms = append(confirms, confirm)
+	}
+
+	// The broker sends a return before the confirm of the same message, so once every confirm
+	// is in, all returns for this batch are too
+	var unroutable []string
+	collect := func(r amqp.Return, ok bool) {
+		if !ok {
+			p.returns = nil
+			return
+		}
+		if ids[r.MessageId] {
+			unroutable = append(unroutable, r.MessageId)
+		}
+	}
+	for _, confirm := range confirms {
+	wait:
+		for {
+			select {
+			case r, ok := <-p.returns:
+				collect(r, ok)
+			case <-confirm.Done():
+				break wait
+			case <-ctx.Done():
+				return fmt.Errorf("failed waiting for publisher confirm: %w", ctx.Err())
+			}
+		}
+		if !confirm.Acked() {
+			return fmt.Errorf("rabbitmq rejected message on topic %s", topic)
+		}
+	}
+	for drained := false; !drained; {
+		select {
+		case r, ok := <-p.returns:
+			collect(r, ok)
+		default:
+			drained = true
+		}
+	}
+	if len(unroutable) > 0 {
+		return fmt.Errorf("%w: topic %s, events %s", ErrUnroutable, topic, strings.Join(unroutable, ", "))
+	}
+	return nil
+}
+
+// Close closes the channel and connection
+func (p *RabbitMQPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return errors.Join(p.ch.Close(), p.conn.Close())
+}
+
+// RabbitMQSubscriber consumes topics through durable queues with dead-lettering
+type RabbitMQSubscriber struct {
+	cfg       *RabbitMQConfig
+	conn      *amqp.Connection
+	wg        sync.WaitGroup
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewRabbitMQSubscriber connects to the broker; queues are declared per Subscribe call
+func NewRabbitMQSubscriber(cfg *RabbitMQConfig) (*RabbitMQSubscriber, error) {
+	if cfg.QueueName == "" {
+		return nil, errors.New("rabbitmq subscriber requires a QueueName")
+	}
+
+	conn, err := amqp.Dial(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to rabbitmq: %w", err)
+	}
+
+	return &RabbitMQSubscriber{cfg: cfg, conn: conn, done: make(chan struct{})}, nil
+}
+
+// declareTopology declares the exchanges, the durable queue bound to topic and its dead-letter queue
+func (s *RabbitMQSubscriber) declareTopology(ch *amqp.Channel, topic string) (string, error) {
+	queue := s.cfg.QueueName + "." + topic
+	dlx := s.cfg.deadLetterExchange()
+
+	if err := ch.ExchangeDeclare(s.cfg.exchange(), amqp.ExchangeTopic, true, false, false, false, nil); err != nil {
+		return "", fmt.Errorf("failed to declare exchange: %w", err)
+	}
+	if err := ch.ExchangeDeclare(dlx, amqp.ExchangeDirect, true, false, false, false, nil); err != nil {
+		return "", fmt.Errorf("failed to declare dead-letter exchange: %w", err)
+	}
+
+	if _, err := ch.QueueDeclare(queue+".dlq", true, false, false, false, nil); err != nil {
+		return "", fmt.Errorf("failed to declare dead-letter queue: %w", err)
+	}
+	if err := ch.QueueBind(queue+".dlq", queue, dlx, false, nil); err != nil {
+		return "", fmt.Errorf("failed to bind dead-letter queue: %w", err)
+	}
+
+	args := amqp.Table{
+		"x-dead-letter-exchange":    dlx,
+		"x-dead-letter-routing-key": queue,
+	}
+	if _, err := ch.QueueDeclare(queue, true, false, false, false, args); err != nil {
+		return "", fmt.Errorf("failed to declare queue %s: %w", queue, err)
+	}
+	if err := ch.QueueBind(queue, topic, s.cfg.exchange(), false, nil); err != nil {
+		return "", fmt.Errorf("failed to bind queue %s: %w", queue, err)
+	}
+
+	return queue, nil
+}
+
+// Subscribe consumes topic until ctx is cancelled or Close is called
+func (s *RabbitMQSubscriber) Subscribe(ctx context.Context, topic string, handler Handler) error {
+	ch, err := s.conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open rabbitmq channel: %w", err)
+	}
+	defer ch.Close()
+
+	queue, err := s.declareTopology(ch, topic)
+	if err != nil {
+		return err
+	}
+
+	prefetch := s.cfg.Prefetch
+	if prefetch <= 0 {
+		prefetch = 10
+	}
+	if err := ch.Qos(prefetch, 0, false); err != nil {
+		return fmt.Errorf("failed to set prefetch: %w", err)
+	}
+
+	deliveries, err := ch.Consume(queue, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to consume queue %s: %w", queue, err)
+	}
+
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	log := logger.Module("events").With(zap.String("topic", topic), zap.String("queue", queue))
+	log.Info("rabbitmq subscriber started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("rabbitmq subscriber stopped")
+			return nil
+		case <-s.done:
+			log.Info("rabbitmq subscriber stopped")
+			return nil
+		case d, ok := <-deliveries:
+			if !ok {
+				return errors.New("rabbitmq delivery channel closed")
+			}
+			s.handleDelivery(context.WithoutCancel(ctx), d, handler, log)
+		}
+	}
+}
+
+// handleDelivery runs the handler and settles the delivery according to the failure policy
+func (s *RabbitMQSubscriber) handleDelivery(ctx context.Context, d amqp.Delivery, handler Handler, log *zap.Logger) {
+	var event Event
+	if err := json.Unmarshal(d.Body, &event); err != nil {
+		log.Error("invalid event envelope, dead-lettering", zap.Error(err))
+		_ = d.Nack(false, false)
+		return
+	}
+
+	err := handleWithRetry(ctx, s.cfg.Retry, handler, &event)
+	if err == nil {
+		if ackErr := d.Ack(false); ackErr != nil {
+			log.Error("failed to ack delivery", zap.String("event_id", event.ID), zap.Error(ackErr))
+		}
+		return
+	}
+
+	policy := s.cfg.OnFailure
+	if IsPermanent(err) && policy == Requeue {
+		policy = DeadLetter
+	}
+
+	log.Warn("event handler failed",
+		zap.String("event_id", event.ID),
+		zap.String("event_type", event.Type),
+		zap.String("policy", string(policy)),
+		zap.Error(err),
+	)
+
+	switch policy {
+	case Requeue:
+		_ = d.Nack(false, true)
+	case Drop:
+		_ = d.Ack(false)
+	default:
+		_ = d.Nack(false, false)
+	}
+}
+
+// Close stops all subscriptions, waits for in-flight events and closes the connection
+func (s *RabbitMQSubscriber) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.wg.Wait()
+		err = s.conn.Close()
+	})
+	return err
+}
@@ -0,0 +1,86 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/retry"
+	"go.uber.org/zap"
+)
+
+// RetryConfig controls how subscribers retry failing handlers before dead-lettering
+type RetryConfig struct {
+	MaxRetries   int
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+}
+
+func (r RetryConfig) withDefaults() RetryConfig {
+	if r.MaxRetries < 0 {
+		r.MaxRetries = 0
+	}
+	if r.RetryBackoff <= 0 {
+		r.RetryBackoff = 500 * time.Millisecond
+	}
+	if r.MaxBackoff <= 0 {
+		r.MaxBackoff = 30 * time.Second
+	}
+	return r
+}
+
+// handleWithRetry runs the handler with exponential backoff; it returns the last error when
+// all attempts failed, or immediately for permanent errors and cancelled contexts
+func handleWithRetry(ctx context.Context, cfg RetryConfig, handler Handler, event *Event) error {
+	cfg = cfg.withDefaults()
+	ctx = ContextWithEvent(ctx, event)
+	log := logger.Module("events")
+
+	policy := retry.Policy{
+		MaxAttempts:    cfg.MaxRetries + 1,
+		InitialBackoff: cfg.RetryBackoff,
+		MaxBackoff:     cfg.MaxBackoff,
+		Retryable:      func(err error) bool { return !IsPermanent(err) },
+		OnRetry: func(attempt int, err error, wait time.Duration) {
+			log.Warn("event handler failed, retrying",
+				zap.String("event_id", event.ID),
+				zap.String("event_type", event.Type),
+				zap.Int("attempt", attempt),
+				zap.Error(err),
+			)
+		},
+	}
+	return retry.Do(ctx, policy, func(ctx context.Context) error {
+		return safeHandle(ctx, handler, event)
+	})
+}
+
+// safeHandle converts handler panics into errors
+func safeHandle(ctx context.Context, handler Handler, event *Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("event handler panicked: %v", r)
+		}
+	}()
+	return handler(ctx, event)
+}
+
+// detach returns a context that outlives ctx by grace, so an in-flight event can finish during
+// a graceful shutdown without holding it up for its whole retry budget
+func detach(ctx context.Context, grace time.Duration) (context.Context, context.CancelFunc) {
+	detached, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			cancel()
+		case <-detached.Done():
+		}
+	})
+	return detached, func() {
+		stop()
+		cancel()
+	}
+}
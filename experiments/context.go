@@ -0,0 +1,108 @@
+package experiments
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ContextKey is the gin key under which Middleware stores the request's assignments
+const ContextKey = "experiments"
+
+// requestState holds the assignments of one request and the exposures already recorded
+type requestState struct {
+	x           *Experiments
+	assignments map[string]Assignment
+
+	mu      sync.Mutex
+	exposed map[string]bool
+}
+
+type stateKey struct{}
+
+// Middleware assigns the request's user and tenant to every experiment, stores the assignments
+// for Variant and reports them in the response Meta. Place it after the auth middleware so the
+// user is known; exposures are only recorded when a handler reads a variant.
+func (x *Experiments) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		st := &requestState{x: x, assignments: make(map[string]Assignment, len(x.order)), exposed: make(map[string]bool)}
+		variants := make(map[string]string, len(x.order))
+		for _, key := range x.order {
+			a, _ := x.Assign(key, unitID(c, x.experiments[key].Unit))
+			st.assignments[key] = a
+			variants[key] = a.Variant
+		}
+
+		c.Set(ContextKey, st)
+		c.Set(response.ExperimentsKey, variants)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), stateKey{}, st))
+		c.Next()
+	}
+}
+
+// Variant returns the request's variant of the experiment and records the exposure once per
+// request; it returns "" without Middleware or for unknown experiments
+func Variant(ctx context.Context, key string) string {
+	st := stateFrom(ctx)
+	if st == nil {
+		return ""
+	}
+	a, ok := st.assignments[key]
+	if !ok {
+		return ""
+	}
+	st.mu.Lock()
+	first := !st.exposed[key]
+	st.exposed[key] = true
+	st.mu.Unlock()
+	if first {
+		st.x.expose(ctx, a)
+	}
+	return a.Variant
+}
+
+// Variant is the package Variant for code running outside requests, e.g. workers: it assigns
+// from the user or tenant on ctx when Middleware did not run, recording the exposure each call
+func (x *Experiments) Variant(ctx context.Context, key string) string {
+	if stateFrom(ctx) != nil {
+		return Variant(ctx, key)
+	}
+	exp, ok := x.experiments[key]
+	if !ok {
+		return ""
+	}
+	a, _ := x.Assign(key, unitID(ctx, exp.Unit))
+	x.expose(ctx, a)
+	return a.Variant
+}
+
+// Assignments returns the assignments Middleware made for the request, without recording
+// exposures
+func Assignments(ctx context.Context) []Assignment {
+	st := stateFrom(ctx)
+	if st == nil {
+		return nil
+	}
+	out := make([]Assignment, 0, len(st.x.order))
+	for _, key := range st.x.order {
+		out = append(out, st.assignments[key])
+	}
+	return out
+}
+
+func stateFrom(ctx context.Context) *requestState {
+	if c, ok := ctx.(*gin.Context); ok {
+		if v, ok := c.Get(ContextKey); ok {
+			st, _ := v.(*requestState)
+			return st
+		}
+		if c.Request == nil {
+			return nil
+		}
+		ctx = c.Request.Context()
+	}
+	st, _ := ctx.Value(stateKey{}).(*requestState)
+	return st
+}
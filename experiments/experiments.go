@@ -0,0 +1,220 @@
+// Package experiments assigns users or tenants to experiment variants deterministically, so
+// the same unit always sees the same variant on every replica, and records exposures on the
+// events bus for analysis.
+package experiments
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/events"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"go.uber.org/zap"
+)
+
+// Unit is what an experiment randomizes over
+type Unit string
+
+const (
+	UnitUser   Unit = "user"
+	UnitTenant Unit = "tenant"
+)
+
+// buckets is the resolution of the traffic split, 0.01%
+const buckets = 10000
+
+// ExposureEventType is the type of the events published when a unit sees a variant
+const ExposureEventType = "experiment.exposure"
+
+// Arm is one variant of an experiment
+type Arm struct {
+	Name string
+	// Weight is the relative share of units assigned to the variant
+	Weight int
+}
+
+// Experiment defines a split of users or tenants into variants
+type Experiment struct {
+	Key string
+	// Variants are assigned in proportion to their weights; the first one is the control,
+	// served when the experiment is paused or the unit is unknown
+	Variants []Arm
+	// Unit defaults to UnitUser
+	Unit Unit
+	// Salt defaults to Key; changing it reshuffles every unit
+	Salt string
+	// Overrides pins unit IDs (e.g. QA accounts) to a variant
+	Overrides map[string]string
+	// Paused serves the control to everyone and records no exposures
+	Paused bool
+}
+
+// Assignment is the variant a unit got in an experiment
+type Assignment struct {
+	Experiment string `json:"experiment"`
+	Variant    string `json:"variant"`
+	Unit       Unit   `json:"unit"`
+	UnitID     string `json:"unit_id"`
+	Override   bool   `json:"override,omitempty"`
+}
+
+// Exposure is the payload of exposure events
+type Exposure struct {
+	Assignment
+	ExposedAt time.Time `json:"exposed_at"`
+}
+
+// Config configures the experiments
+type Config struct {
+	Experiments []Experiment
+	// Publisher records exposures; nil only counts them in metrics
+	Publisher events.Publisher
+	// Topic defaults to "experiments.exposures"
+	Topic string
+}
+
+// Experiments assigns variants and records exposures
+type Experiments struct {
+	cfg         Config
+	experiments map[string]*Experiment
+	order       []string
+	log         *zap.Logger
+}
+
+// New validates the experiments and returns the assigner
+func New(cfg Config) (*Experiments, error) {
+	if cfg.Topic == "" {
+		cfg.Topic = "experiments.exposures"
+	}
+	x := &Experiments{cfg: cfg, experiments: make(map[string]*Experiment, len(cfg.Experiments)), log: logger.Module("experiments")}
+	for i := range cfg.Experiments {
+		exp := cfg.Experiments[i]
+		if err := validate(&exp); err != nil {
+			return nil, err
+		}
+		if _, dup := x.experiments[exp.Key]; dup {
+			return nil, fmt.Errorf("experiment %q defined twice", exp.Key)
+		}
+		x.experiments[exp.Key] = &exp
+		x.order = append(x.order, exp.Key)
+	}
+	return x, nil
+}
+
+func validate(exp *Experiment) error {
+	if exp.Key == "" {
+		return errors.New("experiment key is required")
+	}
+	if len(exp.Variants) == 0 {
+		return fmt.Errorf("experiment %q has no variants", exp.Key)
+	}
+	names := make(map[string]bool, len(exp.Variants))
+	total := 0
+	for _, v := range exp.Variants {
+		if v.Name == "" || v.Weight < 0 {
+			return fmt.Errorf("experiment %q has an unnamed or negatively weighted variant", exp.Key)
+		}
+		names[v.Name] = true
+		total += v.Weight
+	}
+	if total == 0 {
+		return fmt.Errorf("experiment %q has no weighted variant", exp.Key)
+	}
+	for unitID, variant := range exp.Overrides {
+		if !names[variant] {
+			return fmt.Errorf("experiment %q overrides %s to unknown variant %q", exp.Key, unitID, variant)
+		}
+	}
+	if exp.Unit == "" {
+		exp.Unit = UnitUser
+	}
+	if exp.Salt == "" {
+		exp.Salt = exp.Key
+	}
+	return nil
+}
+
+// Assign returns the variant of unitID in the experiment; false when the experiment is unknown
+func (x *Experiments) Assign(key, unitID string) (Assignment, bool) {
+	exp, ok := x.experiments[key]
+	if !ok {
+		return Assignment{}, false
+	}
+	a := Assignment{Experiment: key, Unit: exp.Unit, UnitID: unitID, Variant: exp.Variants[0].Name}
+	switch {
+	case exp.Paused || unitID == "":
+	case exp.Overrides[unitID] != "":
+		a.Variant, a.Override = exp.Overrides[unitID], true
+	default:
+		a.Variant = pick(exp.Variants, Bucket(exp.Salt, unitID))
+	}
+	return a, true
+}
+
+// Bucket maps a unit to one of 10000 buckets, stable across processes and releases
+func Bucket(salt, unitID string) int {
+	sum := sha256.Sum256([]byte(salt + ":" + unitID))
+	return int(binary.BigEndian.Uint64(sum[:8]) % buckets)
+}
+
+// pick returns the variant covering bucket; adjacent buckets share a variant, so raising a
+// weight only moves the units next to the boundary
+func pick(variants []Arm, bucket int) string {
+	total := 0
+	for _, v := range variants {
+		total += v.Weight
+	}
+	cumulative := 0
+	for _, v := range variants {
+		cumulative += v.Weight
+		if bucket*total < cumulative*buckets {
+			return v.Name
+		}
+	}
+	return variants[len(variants)-1].Name
+}
+
+// unitID returns the ID of the unit an experiment randomizes over from ctx
+func unitID(ctx context.Context, unit Unit) string {
+	switch unit {
+	case UnitTenant:
+		return ctxutil.TenantID(ctx)
+	default:
+		if id, ok := ctxutil.UserID(ctx); ok {
+			return strconv.FormatUint(id, 10)
+		}
+		return ""
+	}
+}
+
+// expose records that a unit saw its variant
+func (x *Experiments) expose(ctx context.Context, a Assignment) {
+	if x.experiments[a.Experiment].Paused || a.UnitID == "" {
+		return
+	}
+	exposuresTotal.WithLabelValues(a.Experiment, a.Variant).Inc()
+	if x.cfg.Publisher == nil {
+		return
+	}
+	event, err := events.NewEvent(ctx, ExposureEventType, Exposure{Assignment: a, ExposedAt: time.Now().UTC()})
+	if err != nil {
+		x.log.Warn("failed to build exposure event", zap.String("experiment", a.Experiment), zap.Error(err))
+		return
+	}
+	event.Key = a.UnitID
+	// Published in the background so the request never waits on the bus; the event already
+	// carries the request and trace IDs, and ctx may be a gin context recycled after the request
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := x.cfg.Publisher.Publish(ctx, x.cfg.Topic, event); err != nil {
+			x.log.Warn("failed to publish exposure", zap.String("experiment", a.Experiment), zap.Error(err))
+		}
+	}()
+}
@@ -0,0 +1,12 @@
+package experiments
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// exposuresTotal counts exposures, whether or not a Publisher records them
+var exposuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "experiment_exposures_total",
+	Help: "Units exposed to an experiment variant, by experiment and variant.",
+}, []string{"experiment", "variant"})
@@ -0,0 +1,280 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/utils"
+)
+
+// Dataset describes one registered source in the archive manifest
+type Dataset struct {
+	Name    string   `json:"name"`
+	Records int      `json:"records"`
+	Files   []string `json:"files"`
+}
+
+// Manifest is written to manifest.json at the root of every archive
+type Manifest struct {
+	Subject     Subject   `json:"subject"`
+	Service     string    `json:"service"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Datasets    []Dataset `json:"datasets"`
+}
+
+// Build writes a ZIP archive of the subject's data to w: for every source a JSON array and a
+// CSV sheet with one column per field, plus a manifest
+func (r *Registry) Build(ctx context.Context, subject Subject, w io.Writer) (*Manifest, error) {
+	manifest := &Manifest{Subject: subject, Service: utils.ServiceID, GeneratedAt: time.Now().UTC()}
+	zw := zip.NewWriter(w)
+
+	for _, s := range r.list() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		ds, err := writeDataset(ctx, zw, s, subject, manifest.GeneratedAt)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Datasets = append(manifest.Datasets, ds)
+	}
+
+	f, err := create(zw, "manifest.json", manifest.GeneratedAt)
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return nil, fmt.Errorf("failed to encode manifest.json: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish export archive: %w", err)
+	}
+	return manifest, nil
+}
+
+// writeDataset streams the JSON file while buffering the CSV, since a ZIP entry must be complete
+// before the next one starts
+func writeDataset(ctx context.Context, zw *zip.Writer, s namedSource, subject Subject, modified time.Time) (Dataset, error) {
+	ds := Dataset{Name: s.name, Files: []string{s.name + ".json", s.name + ".csv"}}
+
+	f, err := create(zw, ds.Files[0], modified)
+	if err != nil {
+		return ds, err
+	}
+	t := newTable(f)
+	if _, err := io.WriteString(f, "["); err != nil {
+		return ds, err
+	}
+	if err := s.source(ctx, subject, t); err != nil {
+		return ds, sourceError(s.name, err)
+	}
+	if _, err := io.WriteString(f, "\n]\n"); err != nil {
+		return ds, err
+	}
+
+	f, err = create(zw, ds.Files[1], modified)
+	if err != nil {
+		return ds, err
+	}
+	if err := t.flushCSV(f); err != nil {
+		return ds, sourceError(s.name, err)
+	}
+	ds.Records = t.count
+	return ds, nil
+}
+
+func create(zw *zip.Writer, name string, modified time.Time) (io.Writer, error) {
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add %s to export archive: %w", name, err)
+	}
+	return f, nil
+}
+
+// Table receives the records of one dataset; all records should have the same type, the first
+// one decides the CSV columns
+type Table struct {
+	json  io.Writer
+	csv   bytes.Buffer
+	cw    *csv.Writer
+	cols  []column
+	keys  []string
+	count int
+}
+
+type column struct {
+	name  string
+	index []int
+}
+
+func newTable(w io.Writer) *Table {
+	t := &Table{json: w}
+	t.cw = csv.NewWriter(&t.csv)
+	return t
+}
+
+// Add appends a record: a struct (or pointer to one) gets a column per exported JSON field,
+// a string-keyed map a column per key, anything else a single value column
+func (t *Table) Add(record interface{}) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode record: %w", err)
+	}
+	sep := ",\n  "
+	if t.count == 0 {
+		sep = "\n  "
+	}
+	if _, err := io.WriteString(t.json, sep); err != nil {
+		return err
+	}
+	if _, err := t.json.Write(data); err != nil {
+		return err
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(record))
+	if t.count == 0 {
+		t.header(rv)
+	}
+	t.count++
+	return t.cw.Write(t.row(rv))
+}
+
+func (t *Table) header(rv reflect.Value) {
+	switch rv.Kind() {
+	case reflect.Struct:
+		t.cols = structColumns(rv.Type(), nil)
+		names := make([]string, len(t.cols))
+		for i, c := range t.cols {
+			names[i] = c.name
+		}
+		_ = t.cw.Write(names)
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			_ = t.cw.Write([]string{"value"})
+			return
+		}
+		for _, k := range rv.MapKeys() {
+			t.keys = append(t.keys, fmt.Sprint(k.Interface()))
+		}
+		sort.Strings(t.keys)
+		_ = t.cw.Write(t.keys)
+	default:
+		_ = t.cw.Write([]string{"value"})
+	}
+}
+
+func (t *Table) row(rv reflect.Value) []string {
+	switch {
+	case t.cols != nil && rv.Kind() == reflect.Struct:
+		out := make([]string, len(t.cols))
+		for i, c := range t.cols {
+			if f, err := rv.FieldByIndexErr(c.index); err == nil {
+				out[i] = cell(f)
+			}
+		}
+		return out
+	case t.keys != nil && rv.Kind() == reflect.Map:
+		out := make([]string, len(t.keys))
+		for i, k := range t.keys {
+			if v := rv.MapIndex(reflect.ValueOf(k).Convert(rv.Type().Key())); v.IsValid() {
+				out[i] = cell(v)
+			}
+		}
+		return out
+	default:
+		return []string{cell(rv)}
+	}
+}
+
+func (t *Table) flushCSV(w io.Writer) error {
+	t.cw.Flush()
+	if err := t.cw.Error(); err != nil {
+		return err
+	}
+	// The BOM makes Excel open UTF-8 (Arabic) CSV files correctly
+	if _, err := w.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+		return err
+	}
+	_, err := io.Copy(w, &t.csv)
+	return err
+}
+
+// structColumns lists the JSON-visible fields of typ, flattening embedded structs such as model.Base
+func structColumns(typ reflect.Type, parent []int) []column {
+	var cols []column
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		index := append(append([]int(nil), parent...), i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				cols = append(cols, structColumns(ft, index)...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		cols = append(cols, column{name: name, index: index})
+	}
+	return cols
+}
+
+// cell formats a value for CSV: scalars as text, times as RFC 3339, nested values as JSON
+func cell(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || !v.CanInterface() {
+		return ""
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	}
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,344 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/notify"
+	"github.com/Masharah-Advisory/common/storage"
+	"github.com/Masharah-Advisory/common/worker"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Worker job names
+const (
+	BuildJob   = "export.build"
+	CleanupJob = "export.cleanup"
+)
+
+// ReadyEvent is the default notification sent when an archive is ready; its data carries
+// archive_id, url and expires_at. Register it on the dispatcher with a Link of "url".
+const ReadyEvent = "export.ready"
+
+// Errors returned by the exporter
+var (
+	ErrNotFound = apperror.New("export_not_found", apperror.KindNotFound, "export not found").
+			WithKey("export.not_found")
+	ErrNotReady = apperror.New("export_not_ready", apperror.KindConflict, "the export is still being prepared").
+			WithKey("export.not_ready")
+	ErrExpired = apperror.New("export_expired", apperror.KindNotFound, "the export has expired, please request a new one").
+			WithKey("export.expired")
+)
+
+// Config holds the exporter settings
+type Config struct {
+	DB       *gorm.DB
+	Storage  storage.Storage
+	Registry *Registry          // defaults to the default registry
+	Worker   *worker.Manager    // optional; without it archives are built inline by Request
+	Notifier *notify.Dispatcher // optional
+
+	Event           string        // notification event, defaults to ReadyEvent
+	Prefix          string        // storage key prefix, defaults to "exports/"
+	Retention       time.Duration // how long archives are kept, defaults to 7 days
+	LinkExpiry      time.Duration // presigned link lifetime, defaults to 24h
+	CleanupInterval time.Duration // how often expired archives are deleted, defaults to 1h
+}
+
+// Exporter builds data archives in the background and hands out download links
+type Exporter struct {
+	cfg *Config
+	log *zap.Logger
+	now func() time.Time
+}
+
+type buildPayload struct {
+	ArchiveID uint64 `json:"archive_id"`
+}
+
+// NewExporter creates an exporter and registers its jobs on cfg.Worker when set; migrate
+// Archive first
+func NewExporter(cfg *Config) *Exporter {
+	if cfg.Registry == nil {
+		cfg.Registry = Default()
+	}
+	if cfg.Event == "" {
+		cfg.Event = ReadyEvent
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = "exports/"
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = 7 * 24 * time.Hour
+	}
+	if cfg.LinkExpiry <= 0 {
+		cfg.LinkExpiry = 24 * time.Hour
+	}
+	if cfg.CleanupInterval <= 0 {
+		cfg.CleanupInterval = time.Hour
+	}
+
+	e := &Exporter{cfg: cfg, log: logger.Module("export"), now: time.Now}
+
+	if cfg.Worker != nil {
+		cfg.Worker.Register(BuildJob, e.handleTask, worker.JobOptions{MaxRetries: 2, RetryBackoff: 30 * time.Second, Timeout: 30 * time.Minute})
+		cfg.Worker.Every(CleanupJob, cfg.CleanupInterval, func(ctx context.Context) error {
+			_, err := e.Cleanup(ctx)
+			return err
+		}, worker.JobOptions{})
+	}
+	return e
+}
+
+// Request queues an archive of the subject's data; while one is already pending or processing
+// that one is returned instead of starting another
+func (e *Exporter) Request(ctx context.Context, subject Subject) (*Archive, error) {
+	var existing Archive
+	err := e.cfg.DB.WithContext(ctx).
+		Where("user_id = ? AND tenant_id = ? AND status IN ? AND deleted_at IS NULL",
+			subject.UserID, subject.TenantID, []Status{StatusPending, StatusProcessing}).
+		Order("id DESC").
+		First(&existing).Error
+	if err == nil {
+		return &existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load export: %w", err)
+	}
+
+	a := &Archive{TenantID: subject.TenantID, UserID: subject.UserID, Status: StatusPending}
+	if err := e.cfg.DB.WithContext(ctx).Create(a).Error; err != nil {
+		return nil, fmt.Errorf("failed to create export: %w", err)
+	}
+
+	if e.cfg.Worker == nil {
+		if err := e.Process(ctx, a.ID); err != nil {
+			return nil, err
+		}
+		return e.load(ctx, a.ID)
+	}
+	if _, err := e.cfg.Worker.Enqueue(ctx, BuildJob, buildPayload{ArchiveID: a.ID}); err != nil {
+		e.fail(ctx, a, err)
+		return nil, fmt.Errorf("failed to queue export: %w", err)
+	}
+	return a, nil
+}
+
+// Process builds and uploads an archive, then notifies its owner with a download link; failed
+// archives may be processed again
+func (e *Exporter) Process(ctx context.Context, id uint64) error {
+	a, err := e.load(ctx, id)
+	if err != nil {
+		return err
+	}
+	if a.Status == StatusReady || a.Status == StatusExpired {
+		return nil
+	}
+
+	a.Status, a.Error = StatusProcessing, ""
+	if err := e.cfg.DB.WithContext(ctx).Save(a).Error; err != nil {
+		return fmt.Errorf("failed to update export: %w", err)
+	}
+
+	if err := e.build(ctx, a); err != nil {
+		e.fail(ctx, a, err)
+		return err
+	}
+
+	url, err := e.cfg.Storage.PresignGet(ctx, a.Key, e.linkExpiry(a))
+	if err != nil {
+		// The archive is ready; the user can still fetch a link from the download endpoint
+		e.log.Warn("failed to presign export", zap.Uint64("archive_id", a.ID), zap.Error(err))
+		return nil
+	}
+	e.notify(ctx, a, url)
+	return nil
+}
+
+// Get returns one of the subject's archives
+func (e *Exporter) Get(ctx context.Context, subject Subject, id uint64) (*Archive, error) {
+	var a Archive
+	err := e.cfg.DB.WithContext(ctx).
+		Where("id = ? AND user_id = ? AND tenant_id = ? AND deleted_at IS NULL", id, subject.UserID, subject.TenantID).
+		First(&a).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load export: %w", err)
+	}
+	return &a, nil
+}
+
+// List returns the subject's most recent archives
+func (e *Exporter) List(ctx context.Context, subject Subject, limit int) ([]Archive, error) {
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	var items []Archive
+	err := e.cfg.DB.WithContext(ctx).
+		Where("user_id = ? AND tenant_id = ? AND deleted_at IS NULL", subject.UserID, subject.TenantID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&items).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exports: %w", err)
+	}
+	return items, nil
+}
+
+// DownloadURL returns a fresh presigned link to one of the subject's ready archives
+func (e *Exporter) DownloadURL(ctx context.Context, subject Subject, id uint64) (string, error) {
+	a, err := e.Get(ctx, subject, id)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case a.Status == StatusExpired, a.Status == StatusReady && !a.Downloadable(e.now()):
+		return "", ErrExpired
+	case !a.Downloadable(e.now()):
+		return "", ErrNotReady
+	}
+	return e.cfg.Storage.PresignGet(ctx, a.Key, e.linkExpiry(a))
+}
+
+// Cleanup deletes the files of expired archives and returns how many were removed
+func (e *Exporter) Cleanup(ctx context.Context) (int, error) {
+	var items []Archive
+	err := e.cfg.DB.WithContext(ctx).
+		Where("status = ? AND expires_at < ?", StatusReady, e.now()).
+		Limit(500).
+		Find(&items).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load expired exports: %w", err)
+	}
+
+	removed := 0
+	for i := range items {
+		a := &items[i]
+		if err := e.cfg.Storage.Delete(ctx, a.Key); err != nil && !storage.IsNotFound(err) {
+			e.log.Warn("failed to delete expired export", zap.Uint64("archive_id", a.ID), zap.Error(err))
+			continue
+		}
+		err := e.cfg.DB.WithContext(ctx).Model(a).
+			Updates(map[string]interface{}{"status": StatusExpired, "key": ""}).Error
+		if err != nil {
+			return removed, fmt.Errorf("failed to update export: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// build writes the archive to a temporary file, so the upload has a known size, then stores it
+func (e *Exporter) build(ctx context.Context, a *Archive) error {
+	tmp, err := os.CreateTemp("", "export-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	manifest, err := e.cfg.Registry.Build(ctx, Subject{UserID: a.UserID, TenantID: a.TenantID}, tmp)
+	if err != nil {
+		return err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to read export file: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read export file: %w", err)
+	}
+
+	key := e.cfg.Prefix + strconv.FormatUint(a.UserID, 10) + "/" + strconv.FormatUint(a.ID, 10) + ".zip"
+	name := fmt.Sprintf("%s-data-%s.zip", manifest.Service, manifest.GeneratedAt.Format("20060102"))
+	_, err = e.cfg.Storage.Put(ctx, key, tmp, size, storage.PutOptions{
+		ContentType:        "application/zip",
+		ContentDisposition: fmt.Sprintf(`attachment; filename="%s"`, name),
+		CacheControl:       "private, no-store",
+	})
+	if err != nil {
+		return err
+	}
+
+	now := e.now()
+	expires := now.Add(e.cfg.Retention)
+	records := 0
+	for _, ds := range manifest.Datasets {
+		records += ds.Records
+	}
+	a.Status, a.Key, a.Size, a.Records = StatusReady, key, size, records
+	a.CompletedAt, a.ExpiresAt = &now, &expires
+	if err := e.cfg.DB.WithContext(ctx).Save(a).Error; err != nil {
+		return fmt.Errorf("failed to update export: %w", err)
+	}
+	return nil
+}
+
+func (e *Exporter) fail(ctx context.Context, a *Archive, cause error) {
+	msg := cause.Error()
+	if len(msg) > 1024 {
+		msg = msg[:1024]
+	}
+	err := e.cfg.DB.WithContext(ctx).Model(a).
+		Updates(map[string]interface{}{"status": StatusFailed, "error": msg}).Error
+	if err != nil {
+		e.log.Error("failed to mark export as failed", zap.Uint64("archive_id", a.ID), zap.Error(err))
+	}
+}
+
+func (e *Exporter) notify(ctx context.Context, a *Archive, url string) {
+	if e.cfg.Notifier == nil {
+		return
+	}
+	data := map[string]interface{}{
+		"archive_id": a.ID,
+		"url":        url,
+	}
+	if a.ExpiresAt != nil {
+		data["expires_at"] = a.ExpiresAt.Format(time.RFC3339)
+	}
+	if _, err := e.cfg.Notifier.Notify(ctx, a.UserID, e.cfg.Event, data); err != nil {
+		e.log.Warn("failed to notify export owner", zap.Uint64("archive_id", a.ID), zap.Error(err))
+	}
+}
+
+// linkExpiry never lets a link outlive the archive
+func (e *Exporter) linkExpiry(a *Archive) time.Duration {
+	expiry := e.cfg.LinkExpiry
+	if a.ExpiresAt != nil {
+		if left := a.ExpiresAt.Sub(e.now()); left < expiry {
+			expiry = left
+		}
+	}
+	return expiry
+}
+
+func (e *Exporter) load(ctx context.Context, id uint64) (*Archive, error) {
+	var a Archive
+	err := e.cfg.DB.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&a).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load export: %w", err)
+	}
+	return &a, nil
+}
+
+func (e *Exporter) handleTask(ctx context.Context, task *worker.Task) error {
+	var p buildPayload
+	if err := task.Decode(&p); err != nil {
+		return err
+	}
+	return e.Process(ctx, p.ArchiveID)
+}
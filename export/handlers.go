@@ -0,0 +1,120 @@
+package export
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Handlers lets the current user request archives of their data and download them
+type Handlers struct {
+	exporter *Exporter
+}
+
+// NewHandlers creates the handlers
+func NewHandlers(exporter *Exporter) *Handlers {
+	return &Handlers{exporter: exporter}
+}
+
+// Register mounts the endpoints on a router group, e.g. /exports
+func (h *Handlers) Register(rg *gin.RouterGroup) {
+	rg.GET("", h.List)
+	rg.POST("", h.Request)
+	rg.GET("/:id", h.Get)
+	rg.GET("/:id/download", h.Download)
+}
+
+// Request queues a new archive and answers 202 with its status
+func (h *Handlers) Request(c *gin.Context) {
+	subject, ok := subjectFrom(c)
+	if !ok {
+		return
+	}
+	a, err := h.exporter.Request(c.Request.Context(), subject)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.Accepted(c, a)
+}
+
+// List returns the current user's recent archives
+func (h *Handlers) List(c *gin.Context) {
+	subject, ok := subjectFrom(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	items, err := h.exporter.List(c.Request.Context(), subject, limit)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if items == nil {
+		items = []Archive{}
+	}
+	response.OK(c, items)
+}
+
+// Get returns the status of one archive
+func (h *Handlers) Get(c *gin.Context) {
+	subject, ok := subjectFrom(c)
+	if !ok {
+		return
+	}
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	a, err := h.exporter.Get(c.Request.Context(), subject, id)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, a)
+}
+
+// Download redirects to a fresh presigned link, or returns it as {"url": ...} with ?redirect=false
+func (h *Handlers) Download(c *gin.Context) {
+	subject, ok := subjectFrom(c)
+	if !ok {
+		return
+	}
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	url, err := h.exporter.DownloadURL(c.Request.Context(), subject, id)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if c.Query("redirect") == "false" {
+		response.OK(c, gin.H{"url": url})
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.Redirect(http.StatusFound, url)
+}
+
+func subjectFrom(c *gin.Context) (Subject, bool) {
+	userID, ok := ctxutil.UserID(c)
+	if !ok {
+		response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
+		return Subject{}, false
+	}
+	return Subject{UserID: userID, TenantID: ctxutil.TenantID(c)}, true
+}
+
+func paramID(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, i18n.T(c, "export.invalid_request"))
+		return 0, false
+	}
+	return id, true
+}
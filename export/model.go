@@ -0,0 +1,43 @@
+package export
+
+import (
+	"time"
+
+	"github.com/Masharah-Advisory/common/model"
+)
+
+// Status is the lifecycle state of an archive
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusProcessing Status = "processing"
+	StatusReady      Status = "ready"
+	StatusFailed     Status = "failed"
+	StatusExpired    Status = "expired"
+)
+
+// Archive tracks one export request from queueing to download; the file itself lives in
+// object storage under Key until ExpiresAt
+type Archive struct {
+	model.Base
+	TenantID    string     `json:"tenant_id,omitempty" gorm:"index;size:64"`
+	UserID      uint64     `json:"user_id" gorm:"index;not null"`
+	Status      Status     `json:"status" gorm:"index;size:16;not null"`
+	Key         string     `json:"-" gorm:"size:512"`
+	Size        int64      `json:"size,omitempty"`
+	Records     int        `json:"records,omitempty"`
+	Error       string     `json:"error,omitempty" gorm:"size:1024"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" gorm:"index"`
+}
+
+// TableName overrides the table name
+func (Archive) TableName() string {
+	return "export_archives"
+}
+
+// Downloadable reports whether the archive can be downloaded at t
+func (a *Archive) Downloadable(t time.Time) bool {
+	return a.Status == StatusReady && a.Key != "" && (a.ExpiresAt == nil || t.Before(*a.ExpiresAt))
+}
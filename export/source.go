@@ -0,0 +1,117 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Subject identifies whose data an archive contains
+type Subject struct {
+	UserID   uint64 `json:"user_id"`
+	TenantID string `json:"tenant_id,omitempty"`
+}
+
+// Source writes one dataset of the subject's records to t
+type Source func(ctx context.Context, subject Subject, t *Table) error
+
+// ModelOptions controls how Model selects a table's rows
+type ModelOptions struct {
+	// UserColumn holds the owner's ID, defaults to "user_id"
+	UserColumn string
+	// TenantColumn scopes rows to the subject's tenant when set and the subject has one
+	TenantColumn string
+	// Scope adds conditions, e.g. excluding soft-deleted rows
+	Scope func(tx *gorm.DB) *gorm.DB
+	// BatchSize defaults to 500
+	BatchSize int
+}
+
+// Model exports every T row owned by the subject in batches, so large tables are never held
+// in memory
+func Model[T any](db *gorm.DB, opts ModelOptions) Source {
+	if opts.UserColumn == "" {
+		opts.UserColumn = "user_id"
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	return func(ctx context.Context, subject Subject, t *Table) error {
+		tx := db.WithContext(ctx).Model(new(T)).Where(opts.UserColumn+" = ?", subject.UserID)
+		if opts.TenantColumn != "" && subject.TenantID != "" {
+			tx = tx.Where(opts.TenantColumn+" = ?", subject.TenantID)
+		}
+		if opts.Scope != nil {
+			tx = opts.Scope(tx)
+		}
+		var batch []T
+		res := tx.FindInBatches(&batch, opts.BatchSize, func(_ *gorm.DB, _ int) error {
+			for i := range batch {
+				if err := t.Add(&batch[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return res.Error
+	}
+}
+
+type namedSource struct {
+	name   string
+	source Source
+}
+
+// Registry is the list of datasets included in an archive
+type Registry struct {
+	mu      sync.RWMutex
+	sources []namedSource
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a dataset; name becomes the file names in the archive (name.json, name.csv)
+func (r *Registry) Register(name string, source Source) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, s := range r.sources {
+		if s.name == name {
+			r.sources[i].source = source
+			return
+		}
+	}
+	r.sources = append(r.sources, namedSource{name: name, source: source})
+}
+
+func (r *Registry) list() []namedSource {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]namedSource(nil), r.sources...)
+}
+
+var defaultRegistry = NewRegistry()
+
+// Register adds a dataset to the default registry
+func Register(name string, source Source) {
+	defaultRegistry.Register(name, source)
+}
+
+// RegisterModel adds a table to the default registry, e.g.
+// export.RegisterModel[Order]("orders", db, export.ModelOptions{TenantColumn: "tenant_id"})
+func RegisterModel[T any](name string, db *gorm.DB, opts ModelOptions) {
+	defaultRegistry.Register(name, Model[T](db, opts))
+}
+
+// Default returns the default registry
+func Default() *Registry {
+	return defaultRegistry
+}
+
+func sourceError(name string, err error) error {
+	return fmt.Errorf("failed to export %s: %w", name, err)
+}
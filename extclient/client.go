@@ -0,0 +1,321 @@
+package extclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/breaker"
+	"github.com/Masharah-Advisory/common/buildinfo"
+	"github.com/Masharah-Advisory/common/config"
+	"github.com/Masharah-Advisory/common/retry"
+	"github.com/Masharah-Advisory/common/telemetry"
+	"github.com/Masharah-Advisory/common/utils"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"golang.org/x/time/rate"
+)
+
+// Client calls one third-party provider; unlike httpclient.ServiceClient it never forwards
+// internal headers (service credentials, user IDs) to the remote side
+type Client struct {
+	provider Provider
+	http     *http.Client
+	limiter  *rate.Limiter
+	breaker  *breaker.Breaker
+
+	mu     sync.Mutex
+	tokens oauth2.TokenSource
+}
+
+// New creates a client for p
+func New(p *Provider) (*Client, error) {
+	if p.Name == "" || p.BaseURL == "" {
+		return nil, fmt.Errorf("extclient: provider name and base URL are required")
+	}
+	cp := *p
+	cp.BaseURL = strings.TrimSuffix(cp.BaseURL, "/")
+	if cp.Timeout <= 0 {
+		cp.Timeout = 30 * time.Second
+	}
+
+	c := &Client{
+		provider: cp,
+		http:     &http.Client{Timeout: cp.Timeout, Transport: telemetry.Transport(cp.Transport)},
+	}
+	if cp.RateLimit > 0 {
+		burst := cp.Burst
+		if burst <= 0 {
+			burst = 1
+		}
+		c.limiter = rate.NewLimiter(rate.Limit(cp.RateLimit), burst)
+	}
+	if cp.Breaker {
+		c.breaker = breaker.Get("extclient:" + cp.Name)
+	}
+	if cp.OAuth2 != nil {
+		c.tokens = c.newTokenSource()
+	}
+
+	urls := map[string]string{cp.Name: cp.BaseURL}
+	if cp.OAuth2 != nil {
+		urls[cp.Name+" token"] = cp.OAuth2.TokenURL
+	}
+	config.RegisterValidator("extclient."+cp.Name, config.RequireURLs(urls))
+	return c, nil
+}
+
+// Name returns the provider name
+func (c *Client) Name() string {
+	return c.provider.Name
+}
+
+// Request is a call to the provider
+type Request struct {
+	Method string
+	// Path is appended to the provider base URL; absolute URLs are used as-is
+	Path    string
+	Query   map[string]string
+	Headers map[string]string
+	// Body is sent as JSON unless it is a []byte or io.Reader (set Content-Type in Headers)
+	Body interface{}
+}
+
+// Get performs a GET request
+func (c *Client) Get(ctx context.Context, path string) (*http.Response, error) {
+	return c.Do(ctx, &Request{Method: http.MethodGet, Path: path})
+}
+
+// Post performs a POST request with a JSON body
+func (c *Client) Post(ctx context.Context, path string, body interface{}) (*http.Response, error) {
+	return c.Do(ctx, &Request{Method: http.MethodPost, Path: path, Body: body})
+}
+
+// Put performs a PUT request with a JSON body
+func (c *Client) Put(ctx context.Context, path string, body interface{}) (*http.Response, error) {
+	return c.Do(ctx, &Request{Method: http.MethodPut, Path: path, Body: body})
+}
+
+// Delete performs a DELETE request
+func (c *Client) Delete(ctx context.Context, path string) (*http.Response, error) {
+	return c.Do(ctx, &Request{Method: http.MethodDelete, Path: path})
+}
+
+// GetJSON performs a GET request and decodes the JSON response into out
+func (c *Client) GetJSON(ctx context.Context, path string, out interface{}) error {
+	return c.DoJSON(ctx, &Request{Method: http.MethodGet, Path: path}, out)
+}
+
+// PostJSON performs a POST request and decodes the JSON response into out
+func (c *Client) PostJSON(ctx context.Context, path string, body, out interface{}) error {
+	return c.DoJSON(ctx, &Request{Method: http.MethodPost, Path: path, Body: body}, out)
+}
+
+// DoJSON performs req and decodes the JSON response into out (which may be nil)
+func (c *Client) DoJSON(ctx context.Context, req *Request, out interface{}) error {
+	resp, err := c.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", c.provider.Name, err)
+	}
+	return nil
+}
+
+// Do performs req through the rate limiter, breaker and retry policy. Responses with status
+// >= 400 are returned as *apperror.Error with the status and body in Meta.
+func (c *Client) Do(ctx context.Context, req *Request) (*http.Response, error) {
+	body, contentType, err := encodeBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt := func(ctx context.Context) (*http.Response, error) {
+		return c.attempt(ctx, req, body, contentType)
+	}
+	do := attempt
+	if c.provider.Retry != nil && c.retryable(req) {
+		do = func(ctx context.Context) (*http.Response, error) {
+			return retry.DoValue(ctx, *c.provider.Retry, attempt)
+		}
+	}
+	if c.breaker == nil {
+		return do(ctx)
+	}
+	return breaker.Call(ctx, c.breaker, do)
+}
+
+// retryable reports whether req is safe to repeat
+func (c *Client) retryable(req *Request) bool {
+	switch strings.ToUpper(req.Method) {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	for k := range req.Headers {
+		if strings.EqualFold(k, "Idempotency-Key") {
+			return true
+		}
+	}
+	return false
+}
+
+// attempt sends req once; a 401 with OAuth2 enabled refreshes the token and tries once more
+func (c *Client) attempt(ctx context.Context, req *Request, body []byte, contentType string) (*http.Response, error) {
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := c.send(ctx, req, body, contentType)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized && c.tokens != nil {
+		drain(resp)
+		c.resetToken()
+		resp, err = c.send(ctx, req, body, contentType)
+	}
+	if _, ok := apperror.As(err); ok {
+		return nil, err
+	}
+	if err != nil {
+		return nil, apperror.Wrap(err, c.provider.Name+"_unavailable", apperror.KindUnavailable,
+			fmt.Sprintf("%s request failed", c.provider.Name))
+	}
+
+	if resp.StatusCode >= 400 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+		return nil, apperror.Newf(c.provider.Name+"_error", apperror.KindFromStatus(resp.StatusCode),
+			"%s returned error [%d]", c.provider.Name, resp.StatusCode).
+			WithMeta("provider", c.provider.Name).
+			WithMeta("status", resp.StatusCode).
+			WithMeta("body", string(data))
+	}
+	return resp, nil
+}
+
+// send builds and executes a single HTTP request
+func (c *Client) send(ctx context.Context, req *Request, body []byte, contentType string) (*http.Response, error) {
+	target := req.Path
+	if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+		target = c.provider.BaseURL + "/" + strings.TrimPrefix(target, "/")
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, target, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if len(req.Query) > 0 {
+		q := httpReq.URL.Query()
+		for k, v := range req.Query {
+			q.Set(k, v)
+		}
+		httpReq.URL.RawQuery = q.Encode()
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("User-Agent", buildinfo.UserAgent(utils.ServiceID))
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	for k, v := range c.provider.Headers {
+		httpReq.Header.Set(k, v)
+	}
+	for k, v := range req.Headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	if c.tokens != nil {
+		token, err := c.token()
+		if err != nil {
+			return nil, err
+		}
+		token.SetAuthHeader(httpReq)
+	}
+
+	return c.http.Do(httpReq)
+}
+
+// encodeBody marshals a request body, returning its content type
+func encodeBody(body interface{}) ([]byte, string, error) {
+	switch b := body.(type) {
+	case nil:
+		return nil, "", nil
+	case []byte:
+		return b, "", nil
+	case io.Reader:
+		data, err := io.ReadAll(b)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read request body: %w", err)
+		}
+		return data, "", nil
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		return data, "application/json", nil
+	}
+}
+
+// drain discards and closes a response body so the connection can be reused
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
+
+// newTokenSource returns a caching client-credentials token source
+func (c *Client) newTokenSource() oauth2.TokenSource {
+	o := c.provider.OAuth2
+	cc := &clientcredentials.Config{
+		ClientID:       o.ClientID,
+		ClientSecret:   o.ClientSecret,
+		TokenURL:       o.TokenURL,
+		Scopes:         o.Scopes,
+		EndpointParams: o.Params,
+		AuthStyle:      oauth2.AuthStyleInParams,
+	}
+	if o.InHeader {
+		cc.AuthStyle = oauth2.AuthStyleInHeader
+	}
+	// Token requests share the traced transport (and a test Recorder) with API calls
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, c.http)
+	return cc.TokenSource(ctx)
+}
+
+// token returns a valid access token, fetching a new one shortly before expiry
+func (c *Client) token() (*oauth2.Token, error) {
+	c.mu.Lock()
+	ts := c.tokens
+	c.mu.Unlock()
+
+	token, err := ts.Token()
+	if err != nil {
+		return nil, apperror.Wrap(err, c.provider.Name+"_auth_failed", apperror.KindUnavailable,
+			fmt.Sprintf("failed to obtain %s access token", c.provider.Name))
+	}
+	return token, nil
+}
+
+// resetToken drops the cached token, e.g. after the provider revoked it early
+func (c *Client) resetToken() {
+	c.mu.Lock()
+	c.tokens = c.newTokenSource()
+	c.mu.Unlock()
+}
@@ -0,0 +1,198 @@
+package extclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+// Mode selects what a Recorder does with requests
+type Mode int
+
+const (
+	// ModeReplay answers from the fixture file and fails on unknown requests
+	ModeReplay Mode = iota
+	// ModeRecord calls the real provider and saves every exchange on Save
+	ModeRecord
+)
+
+// ModeFromEnv returns ModeRecord when EXTCLIENT_RECORD=1, so fixtures are refreshed with
+// `EXTCLIENT_RECORD=1 go test ./...` against sandbox credentials
+func ModeFromEnv() Mode {
+	if os.Getenv("EXTCLIENT_RECORD") == "1" {
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+// Interaction is one recorded request and response
+type Interaction struct {
+	Method   string      `json:"method"`
+	URL      string      `json:"url"`
+	Body     string      `json:"body,omitempty"`
+	Status   int         `json:"status"`
+	Headers  http.Header `json:"headers,omitempty"`
+	Response string      `json:"response"`
+}
+
+// redactedHeaders never reach fixture files
+var redactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// secretFields are masked in recorded bodies (token responses, credential forms)
+var secretFields = regexp.MustCompile(`("(?:access_token|refresh_token|id_token|client_secret)"\s*:\s*")[^"]*(")|((?:client_secret|password)=)[^&]*`)
+
+// Recorder is an http.RoundTripper that records provider traffic to a JSON fixture file and
+// replays it in tests; set it as Provider.Transport. Interactions are matched by method, URL
+// and body, in recorded order.
+type Recorder struct {
+	path string
+	mode Mode
+	base http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewRecorder loads the fixture file at path in replay mode; base is used in record mode and
+// defaults to http.DefaultTransport
+func NewRecorder(path string, mode Mode, base http.RoundTripper) (*Recorder, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	r := &Recorder{path: path, mode: mode, base: base}
+	if mode == ModeRecord {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &r.interactions); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+	r.used = make([]bool, len(r.interactions))
+	return r, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	reqBody := redact(string(body))
+
+	if r.mode == ModeRecord {
+		return r.record(req, reqBody)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.interactions {
+		if r.used[i] || in.Method != req.Method || in.URL != req.URL.String() || in.Body != reqBody {
+			continue
+		}
+		r.used[i] = true
+		return in.response(req), nil
+	}
+	return nil, fmt.Errorf("extclient: no recorded interaction for %s %s in %s", req.Method, req.URL, r.path)
+}
+
+// record performs the real request and keeps a redacted copy of the exchange
+func (r *Recorder) record(req *http.Request, reqBody string) (*http.Response, error) {
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	headers := resp.Header.Clone()
+	for _, h := range redactedHeaders {
+		headers.Del(h)
+	}
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, Interaction{
+		Method:   req.Method,
+		URL:      req.URL.String(),
+		Body:     reqBody,
+		Status:   resp.StatusCode,
+		Headers:  headers,
+		Response: redact(string(data)),
+	})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Save writes recorded interactions to the fixture file; it does nothing in replay mode
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+	return os.WriteFile(r.path, data, 0o644)
+}
+
+// Unused returns the replayed interactions that were never requested, for asserting a test
+// exercised everything it recorded
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unused []Interaction
+	for i, used := range r.used {
+		if !used {
+			unused = append(unused, r.interactions[i])
+		}
+	}
+	return unused
+}
+
+// response rebuilds the recorded response for req
+func (in Interaction) response(req *http.Request) *http.Response {
+	headers := in.Headers.Clone()
+	if headers == nil {
+		headers = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+		StatusCode:    in.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        headers,
+		Body:          io.NopCloser(bytes.NewReader([]byte(in.Response))),
+		ContentLength: int64(len(in.Response)),
+		Request:       req,
+	}
+}
+
+// redact masks secrets in a request or response body
+func redact(s string) string {
+	return secretFields.ReplaceAllString(s, "${1}${3}REDACTED${2}")
+}
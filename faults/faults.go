@@ -0,0 +1,215 @@
+package faults
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masharah-Advisory/common/config"
+	"github.com/Masharah-Advisory/common/crypto"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"go.uber.org/zap"
+)
+
+// Headers read and set by the middleware
+const (
+	// TokenHeader must carry Config.Token when faults are header gated
+	TokenHeader = "X-Fault-Token"
+	// RulesHeader optionally limits a request to the named rules, e.g. "slow-orders,payments-down"
+	RulesHeader = "X-Fault-Rules"
+	// InjectedHeader names the rule applied to a response
+	InjectedHeader = "X-Fault-Injected"
+)
+
+// Kind is the type of fault a rule injects
+type Kind string
+
+const (
+	// Latency delays the request by Latency plus up to Jitter, then lets it through
+	Latency Kind = "latency"
+	// Error answers with Status without reaching the handler or downstream
+	Error Kind = "error"
+	// Drop closes the connection without a response, like a crashed peer
+	Drop Kind = "drop"
+)
+
+// Rule describes a fault and where it applies. Route rules apply to inbound requests through
+// Middleware; Service rules apply to outbound calls through Transport.
+type Rule struct {
+	Name string
+	Kind Kind
+	// Route is a gin route pattern such as "/api/v1/orders/:id", or "*" for every route
+	Route string
+	// Service is a downstream service name as used in api/vX/<service> routes, or a host
+	Service string
+	// Method limits the rule to one HTTP method
+	Method  string
+	Latency time.Duration
+	Jitter  time.Duration
+	// Status is the response status of Error rules, defaults to 503
+	Status int
+	// Probability is the share of matching requests affected, from 0 to 1; 0 means always
+	Probability float64
+}
+
+// Config configures fault injection
+type Config struct {
+	// Enabled turns fault injection on; in production it also needs AllowProduction
+	Enabled bool
+	Rules   []Rule
+	// RequireHeader only injects faults into requests sending Token in X-Fault-Token; it is
+	// always on in production
+	RequireHeader bool
+	Token         string
+	// AllowProduction permits header-gated faults in production, e.g. for a game day
+	AllowProduction bool
+}
+
+// Injector applies the configured faults
+type Injector struct {
+	cfg     *Config
+	enabled bool
+	gated   bool
+	log     *zap.Logger
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// New creates an injector; a disabled config, or production without AllowProduction, yields an
+// injector that does nothing
+func New(cfg *Config) (*Injector, error) {
+	i := &Injector{
+		cfg:     cfg,
+		enabled: cfg.Enabled,
+		gated:   cfg.RequireHeader || config.IsProduction(),
+		log:     logger.Module("faults"),
+		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if config.IsProduction() && !cfg.AllowProduction {
+		i.enabled = false
+	}
+	if !i.enabled {
+		return i, nil
+	}
+
+	if i.gated && len(cfg.Token) < 16 {
+		return nil, fmt.Errorf("faults: a token of at least 16 characters is required when faults are header gated")
+	}
+	for idx := range cfg.Rules {
+		r := &cfg.Rules[idx]
+		switch r.Kind {
+		case Latency, Error, Drop:
+		default:
+			return nil, fmt.Errorf("faults: rule %q has unknown kind %q", r.Name, r.Kind)
+		}
+		if r.Route == "" && r.Service == "" {
+			return nil, fmt.Errorf("faults: rule %q needs a route or a service", r.Name)
+		}
+		if r.Probability < 0 || r.Probability > 1 {
+			return nil, fmt.Errorf("faults: rule %q probability must be between 0 and 1", r.Name)
+		}
+		if r.Kind == Error && r.Status == 0 {
+			r.Status = http.StatusServiceUnavailable
+		}
+	}
+	i.log.Warn("fault injection is enabled", zap.Int("rules", len(cfg.Rules)), zap.Bool("header_gated", i.gated))
+	return i, nil
+}
+
+// Enabled reports whether the injector may inject faults
+func (i *Injector) Enabled() bool {
+	return i != nil && i.enabled
+}
+
+// activation is stored on the context of requests that passed the header gate
+type activation struct {
+	rules map[string]bool // nil means every rule
+}
+
+type activationKey struct{}
+
+// activate checks the header gate, returning nil when the request must not get faults
+func (i *Injector) activate(header http.Header) *activation {
+	if !i.gated {
+		return &activation{}
+	}
+	token := header.Get(TokenHeader)
+	if token == "" || !crypto.Equal(token, i.cfg.Token) {
+		return nil
+	}
+	a := &activation{}
+	if names := header.Get(RulesHeader); names != "" {
+		a.rules = make(map[string]bool)
+		for _, name := range strings.Split(names, ",") {
+			a.rules[strings.TrimSpace(name)] = true
+		}
+	}
+	return a
+}
+
+// fromContext returns the activation of an outbound call: the one stored by Middleware, or an
+// open one when faults are not header gated
+func (i *Injector) fromContext(ctx context.Context) *activation {
+	if a, ok := lookupActivation(ctx); ok {
+		return a
+	}
+	if !i.gated {
+		return &activation{}
+	}
+	return nil
+}
+
+// pick returns the first rule matching the request that fires this time
+func (i *Injector) pick(a *activation, match func(r *Rule) bool) *Rule {
+	for idx := range i.cfg.Rules {
+		r := &i.cfg.Rules[idx]
+		if a.rules != nil && !a.rules[r.Name] {
+			continue
+		}
+		if !match(r) {
+			continue
+		}
+		if r.Probability > 0 && i.float() >= r.Probability {
+			continue
+		}
+		return r
+	}
+	return nil
+}
+
+func (i *Injector) float() float64 {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.rnd.Float64()
+}
+
+// delay returns the latency of a rule including jitter
+func (i *Injector) delay(r *Rule) time.Duration {
+	d := r.Latency
+	if r.Jitter > 0 {
+		i.mu.Lock()
+		d += time.Duration(i.rnd.Int63n(int64(r.Jitter)))
+		i.mu.Unlock()
+	}
+	return d
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
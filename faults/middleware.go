@@ -0,0 +1,83 @@
+package faults
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ErrInjected is the error answered by Error rules
+var ErrInjected = apperror.New("fault_injected", apperror.KindUnavailable, "injected fault")
+
+// activationGinKey stores the activation on gin contexts
+const activationGinKey = "faults_activation"
+
+// Middleware injects the route rules into inbound requests. Requests that pass the header gate
+// also carry it on their context, so Transport applies service rules to the calls they make.
+func (i *Injector) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !i.Enabled() {
+			c.Next()
+			return
+		}
+		a := i.activate(c.Request.Header)
+		if a == nil {
+			c.Next()
+			return
+		}
+		c.Set(activationGinKey, a)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), activationKey{}, a))
+
+		route := c.FullPath()
+		r := i.pick(a, func(r *Rule) bool {
+			return r.Route != "" && (r.Route == "*" || r.Route == route) &&
+				(r.Method == "" || r.Method == c.Request.Method)
+		})
+		if r == nil {
+			c.Next()
+			return
+		}
+
+		i.log.Info("injecting fault", zap.String("rule", r.Name), zap.String("kind", string(r.Kind)), zap.String("route", route))
+		c.Header(InjectedHeader, r.Name)
+		switch r.Kind {
+		case Latency:
+			if err := sleep(c.Request.Context(), i.delay(r)); err != nil {
+				c.Abort()
+				return
+			}
+			c.Next()
+		case Error:
+			response.HandleError(c, apperror.New(ErrInjected.Code, apperror.KindFromStatus(r.Status), ErrInjected.Message).
+				WithMeta("rule", r.Name))
+			c.Abort()
+		case Drop:
+			c.Abort()
+			conn, _, err := c.Writer.Hijack()
+			if err != nil {
+				c.AbortWithStatus(http.StatusBadGateway)
+				return
+			}
+			_ = conn.Close()
+		}
+	}
+}
+
+// lookupActivation reads the activation stored by Middleware from a standard or gin context,
+// including a gin context wrapped by the client's timeout
+func lookupActivation(ctx context.Context) (*activation, bool) {
+	if a, ok := ctx.Value(activationKey{}).(*activation); ok {
+		return a, true
+	}
+	if c, ok := ctx.Value(gin.ContextKey).(*gin.Context); ok {
+		if v, exists := c.Get(activationGinKey); exists {
+			a, ok := v.(*activation)
+			return a, ok
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,85 @@
+package faults
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// ErrDropped is returned by Transport for Drop rules, like a connection reset by the peer
+var ErrDropped = errors.New("faults: connection dropped")
+
+// Transport wraps base (http.DefaultTransport when nil) so the service rules apply to outbound
+// calls, e.g. client.WithTransport(injector.Transport)
+func (i *Injector) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if !i.Enabled() {
+		return base
+	}
+	return &transport{base: base, injector: i}
+}
+
+type transport struct {
+	base     http.RoundTripper
+	injector *Injector
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	a := t.injector.fromContext(req.Context())
+	if a == nil {
+		return t.base.RoundTrip(req)
+	}
+	service := serviceName(req.URL.Path)
+	r := t.injector.pick(a, func(r *Rule) bool {
+		return r.Service != "" && (r.Service == service || r.Service == req.URL.Host || r.Service == req.URL.Hostname()) &&
+			(r.Method == "" || r.Method == req.Method)
+	})
+	if r == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	t.injector.log.Info("injecting fault",
+		zap.String("rule", r.Name),
+		zap.String("kind", string(r.Kind)),
+		zap.String("host", req.URL.Host),
+	)
+	switch r.Kind {
+	case Latency:
+		if err := sleep(req.Context(), t.injector.delay(r)); err != nil {
+			return nil, err
+		}
+		return t.base.RoundTrip(req)
+	case Error:
+		body := fmt.Sprintf(`{"success":false,"message":%q,"code":%q}`, ErrInjected.Message, ErrInjected.Code)
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+			StatusCode:    r.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": {"application/json"}, InjectedHeader: {r.Name}},
+			Body:          io.NopCloser(bytes.NewReader([]byte(body))),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	default:
+		return nil, ErrDropped
+	}
+}
+
+// serviceName extracts the service from an /api/vX/<service>/... path
+func serviceName(path string) string {
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(parts) < 3 || parts[0] != "api" {
+		return ""
+	}
+	return parts[2]
+}
@@ -0,0 +1,125 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/config"
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/i18n"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain marks error details carrying an apperror code
+const errorDomain = "masharah"
+
+var codeByKind = map[apperror.Kind]codes.Code{
+	apperror.KindBadRequest:   codes.InvalidArgument,
+	apperror.KindValidation:   codes.InvalidArgument,
+	apperror.KindUnauthorized: codes.Unauthenticated,
+	apperror.KindForbidden:    codes.PermissionDenied,
+	apperror.KindNotFound:     codes.NotFound,
+	apperror.KindConflict:     codes.AlreadyExists,
+	apperror.KindTooLarge:     codes.ResourceExhausted,
+	apperror.KindRateLimited:  codes.ResourceExhausted,
+	apperror.KindInternal:     codes.Internal,
+	apperror.KindUnavailable:  codes.Unavailable,
+	apperror.KindTimeout:      codes.DeadlineExceeded,
+}
+
+// Code returns the gRPC code for a kind, defaulting to Internal
+func Code(kind apperror.Kind) codes.Code {
+	if code, ok := codeByKind[kind]; ok {
+		return code
+	}
+	return codes.Internal
+}
+
+// KindFromCode maps a gRPC code back to a kind (used when decoding downstream errors)
+func KindFromCode(code codes.Code) apperror.Kind {
+	switch code {
+	case codes.InvalidArgument, codes.OutOfRange, codes.FailedPrecondition:
+		return apperror.KindBadRequest
+	case codes.Unauthenticated:
+		return apperror.KindUnauthorized
+	case codes.PermissionDenied:
+		return apperror.KindForbidden
+	case codes.NotFound:
+		return apperror.KindNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return apperror.KindConflict
+	case codes.ResourceExhausted:
+		return apperror.KindRateLimited
+	case codes.Unavailable:
+		return apperror.KindUnavailable
+	case codes.DeadlineExceeded:
+		return apperror.KindTimeout
+	default:
+		return apperror.KindInternal
+	}
+}
+
+// ToStatus converts an error returned by a handler into a gRPC status error, localizing the
+// message like response.HandleError does and carrying the apperror code in an ErrorInfo
+// detail. Errors that already are statuses pass through.
+func ToStatus(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	lang := ctxutil.Lang(ctx)
+	appErr, ok := apperror.As(err)
+	if !ok {
+		msg := i18n.TLang(lang, "error.internal")
+		if !config.IsProduction() {
+			msg += ": " + err.Error()
+		}
+		return status.Error(codes.Internal, msg)
+	}
+
+	message := appErr.Message
+	switch {
+	case appErr.MessageKey != "":
+		message = i18n.TLang(lang, appErr.MessageKey, appErr.Meta)
+	case message == "" || appErr.Kind == apperror.KindInternal:
+		message = i18n.TLang(lang, "error."+string(appErr.Kind))
+	}
+
+	st := status.New(Code(appErr.Kind), message)
+	if detailed, err := st.WithDetails(&errdetails.ErrorInfo{Reason: appErr.Code, Domain: errorDomain}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// FromStatus converts a gRPC status error from a downstream call into an application error,
+// keeping the downstream code when it sent one
+func FromStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	code := "downstream_error"
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.Domain == errorDomain && info.Reason != "" {
+			code = info.Reason
+		}
+	}
+	return apperror.New(code, KindFromCode(st.Code()), st.Message()).
+		WithMeta("grpc_code", st.Code().String())
+}
@@ -0,0 +1,75 @@
+package grpc
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata keys, the lower-cased names of the headers used by the HTTP stack
+const (
+	RequestIDKey     = "x-request-id"
+	UserIDKey        = "x-user-id"
+	TenantIDKey      = "x-tenant-id"
+	ServiceIDKey     = "x-service-id"
+	ServiceSecretKey = "x-service-secret"
+	LanguageKey      = "x-language"
+	AcceptLangKey    = "accept-language"
+	AuthorizationKey = "authorization"
+)
+
+// metadataCarrier adapts gRPC metadata to the OpenTelemetry propagators
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// first returns the first value of key in md
+func first(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// outgoing adds the service credentials, the request scoped values of ctx and the trace
+// context to the outgoing metadata, keeping values the caller set explicitly
+func outgoing(ctx context.Context, serviceID, serviceSecret string) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	set := func(key, value string) {
+		if value != "" && len(md.Get(key)) == 0 {
+			md.Set(key, value)
+		}
+	}
+
+	set(ServiceIDKey, serviceID)
+	set(ServiceSecretKey, serviceSecret)
+	set(RequestIDKey, ctxutil.RequestID(ctx))
+	set(TenantIDKey, ctxutil.TenantID(ctx))
+	set(AcceptLangKey, ctxutil.Lang(ctx))
+	if userID, ok := ctxutil.UserID(ctx); ok {
+		set(UserIDKey, strconv.FormatUint(userID, 10))
+	}
+	otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md)
+}
@@ -0,0 +1,31 @@
+package grpc
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Call counts and latencies of gRPC servers and clients, by method and code
+var (
+	serverHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "gRPC calls handled by the server, by method and status code.",
+	}, []string{"method", "code"})
+
+	serverDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Duration of gRPC calls handled by the server.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+
+	clientHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_client_handled_total",
+		Help: "gRPC calls made to other services, by service, method and status code.",
+	}, []string{"service", "method", "code"})
+
+	clientDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_client_handling_seconds",
+		Help:    "Duration of gRPC calls made to other services, including retries.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"service", "method"})
+)
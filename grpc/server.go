@@ -0,0 +1,247 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/idgen"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Interceptor is a server interceptor applied to both unary and streaming calls
+type Interceptor func(ctx context.Context, method string, next func(ctx context.Context) error) error
+
+// ServerConfig configures NewServer
+type ServerConfig struct {
+	// Service names the server in traces
+	Service string
+	// Auth authenticates every call; nil leaves authentication to the handlers
+	Auth *AuthConfig
+	// Interceptors run after the standard stack, right before the handler
+	Interceptors []Interceptor
+}
+
+// NewServer creates a gRPC server with the stack mirroring the HTTP middleware: request
+// context (request ID, tenant, language), recovery and error conversion, tracing, metrics,
+// logging and auth, in that order
+func NewServer(cfg ServerConfig, opts ...gogrpc.ServerOption) *gogrpc.Server {
+	chain := []Interceptor{RequestContext(), Recovery(), Tracing(cfg.Service), Metrics(), Logging()}
+	if cfg.Auth != nil {
+		chain = append(chain, Auth(*cfg.Auth))
+	}
+	chain = append(chain, cfg.Interceptors...)
+	return gogrpc.NewServer(append(ServerOptions(chain...), opts...)...)
+}
+
+// ServerOptions chains interceptors, outermost first, into unary and stream server options
+func ServerOptions(interceptors ...Interceptor) []gogrpc.ServerOption {
+	unary := make([]gogrpc.UnaryServerInterceptor, 0, len(interceptors))
+	stream := make([]gogrpc.StreamServerInterceptor, 0, len(interceptors))
+	for _, i := range interceptors {
+		unary = append(unary, i.Unary())
+		stream = append(stream, i.Stream())
+	}
+	return []gogrpc.ServerOption{gogrpc.ChainUnaryInterceptor(unary...), gogrpc.ChainStreamInterceptor(stream...)}
+}
+
+// Unary adapts the interceptor to unary calls
+func (i Interceptor) Unary() gogrpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *gogrpc.UnaryServerInfo, handler gogrpc.UnaryHandler) (interface{}, error) {
+		var resp interface{}
+		err := i(ctx, info.FullMethod, func(ctx context.Context) error {
+			var err error
+			resp, err = handler(ctx, req)
+			return err
+		})
+		return resp, err
+	}
+}
+
+// Stream adapts the interceptor to streaming calls
+func (i Interceptor) Stream() gogrpc.StreamServerInterceptor {
+	return func(srv interface{}, ss gogrpc.ServerStream, info *gogrpc.StreamServerInfo, handler gogrpc.StreamHandler) error {
+		return i(ss.Context(), info.FullMethod, func(ctx context.Context) error {
+			return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		})
+	}
+}
+
+// serverStream replaces the context of a stream
+type serverStream struct {
+	gogrpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// RequestContext stores the request ID (generated when missing and echoed in the response
+// header), tenant and language from the incoming metadata on the context, like
+// RequestIDMiddleware and i18n.Middleware
+func RequestContext() Interceptor {
+	return func(ctx context.Context, method string, next func(ctx context.Context) error) error {
+		md, _ := metadata.FromIncomingContext(ctx)
+		requestID := first(md, RequestIDKey)
+		if requestID == "" {
+			requestID = idgen.NewRequestID()
+		}
+		ctx = ctxutil.WithRequestID(ctx, requestID)
+		_ = gogrpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, requestID))
+
+		if tenantID := first(md, TenantIDKey); tenantID != "" {
+			ctx = ctxutil.WithTenantID(ctx, tenantID)
+		}
+		lang := first(md, LanguageKey)
+		if lang == "" {
+			lang = first(md, AcceptLangKey)
+		}
+		ctx = ctxutil.WithLang(ctx, normalizeLang(lang))
+		return next(ctx)
+	}
+}
+
+// Recovery converts panics into Internal errors and every error into a localized status with
+// ToStatus; place it after RequestContext so the language is known
+func Recovery() Interceptor {
+	return func(ctx context.Context, method string, next func(ctx context.Context) error) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.FromContext(ctx).Error("panic recovered",
+					zap.String("method", method),
+					zap.Any("panic", r),
+					zap.String("stack", string(debug.Stack())),
+				)
+				err = apperror.Internal(fmt.Errorf("panic: %v", r), "internal error")
+			}
+			err = ToStatus(ctx, err)
+		}()
+		return next(ctx)
+	}
+}
+
+// Tracing starts a server span per call from the incoming trace context and stores its trace
+// ID on the context
+func Tracing(service string) Interceptor {
+	tracer := telemetry.Tracer("grpc")
+	return func(ctx context.Context, method string, next func(ctx context.Context) error) error {
+		md, _ := metadata.FromIncomingContext(ctx)
+		ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+		rpcService, rpcMethod := splitMethod(method)
+		ctx, span := tracer.Start(ctx, strings.TrimPrefix(method, "/"),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("service.name", service),
+				attribute.String("rpc.system", "grpc"),
+				attribute.String("rpc.service", rpcService),
+				attribute.String("rpc.method", rpcMethod),
+			),
+		)
+		defer span.End()
+		if traceID := telemetry.TraceID(ctx); traceID != "" {
+			ctx = ctxutil.WithTraceID(ctx, traceID)
+		}
+
+		err := next(ctx)
+		code := codeOf(err)
+		span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+		if serverFault(code) {
+			span.RecordError(err)
+			span.SetStatus(otelcodes.Error, code.String())
+		}
+		return err
+	}
+}
+
+// Metrics counts calls and their duration per method and status code
+func Metrics() Interceptor {
+	return func(ctx context.Context, method string, next func(ctx context.Context) error) error {
+		start := time.Now()
+		err := next(ctx)
+		serverHandled.WithLabelValues(method, codeOf(err).String()).Inc()
+		serverDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// Logging logs one line per call, like the HTTP request logger: server faults as errors,
+// client errors as warnings
+func Logging() Interceptor {
+	return func(ctx context.Context, method string, next func(ctx context.Context) error) error {
+		start := time.Now()
+		err := next(ctx)
+
+		code := codeOf(err)
+		fields := []zap.Field{
+			zap.String("method", method),
+			zap.String("code", code.String()),
+			zap.Duration("latency", time.Since(start)),
+		}
+		l := logger.FromContext(ctx)
+		switch {
+		case serverFault(code):
+			l.Error("call completed", append(fields, zap.Error(err))...)
+		case code != codes.OK:
+			l.Warn("call completed", append(fields, zap.Error(err))...)
+		default:
+			l.Info("call completed", fields...)
+		}
+		return err
+	}
+}
+
+// codeOf returns the status code an error will be answered with
+func codeOf(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	if st, ok := status.FromError(err); ok {
+		return st.Code()
+	}
+	if e, ok := apperror.As(err); ok {
+		return Code(e.Kind)
+	}
+	return codes.Internal
+}
+
+// serverFault reports whether a code is the server's fault, the gRPC analogue of a 5xx
+func serverFault(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss, codes.Unimplemented, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// splitMethod splits "/package.Service/Method"
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "", fullMethod
+}
+
+// normalizeLang reduces a language tag or Accept-Language value to a supported language
+func normalizeLang(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if strings.HasPrefix(lang, "ar") {
+		return "ar"
+	}
+	return "en"
+}
@@ -0,0 +1,248 @@
+// Package health aggregates the health of the services a service depends on into one report,
+// for the gateway and the status page.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Masharah-Advisory/common/config"
+	"github.com/Masharah-Advisory/common/httpclient"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
+)
+
+// Status of a service or of the fleet
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded" // answering, but slowly (or, for the fleet, a non-critical service is down)
+	StatusDown     Status = "down"
+)
+
+// FleetConfig configures a Fleet
+type FleetConfig struct {
+	// Client probes the services with its hosts, credentials and transport
+	Client *httpclient.ServiceClient
+	// Services to probe, defaults to every service configured on Client
+	Services []string
+	// Critical services make the fleet down when they are down; others only degrade it.
+	// Empty means every service is critical.
+	Critical []string
+	// HealthPath and VersionPath are probed on each service, default to /health and /version
+	HealthPath  string
+	VersionPath string
+	// Timeout bounds each probe, defaults to 3s
+	Timeout time.Duration
+	// SlowThreshold marks services answering slower as degraded, defaults to 1s
+	SlowThreshold time.Duration
+	// CacheTTL reuses a report so frequent polling does not fan out to every service, defaults
+	// to 10s
+	CacheTTL time.Duration
+}
+
+// ServiceStatus is the probe result of one service
+type ServiceStatus struct {
+	Service   string    `json:"service"`
+	Status    Status    `json:"status"`
+	Critical  bool      `json:"critical"`
+	LatencyMS int64     `json:"latency_ms"`
+	Version   string    `json:"version,omitempty"`
+	Commit    string    `json:"commit,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report is the aggregated fleet health
+type Report struct {
+	Status    Status          `json:"status"`
+	Services  []ServiceStatus `json:"services"`
+	CheckedAt time.Time       `json:"checked_at"`
+}
+
+// Fleet probes downstream services concurrently and aggregates their health
+type Fleet struct {
+	cfg      FleetConfig
+	critical map[string]bool
+
+	group  singleflight.Group
+	mu     sync.Mutex
+	last   *Report
+	expiry time.Time
+}
+
+// NewFleet creates a fleet health checker
+func NewFleet(cfg FleetConfig) *Fleet {
+	if len(cfg.Services) == 0 {
+		cfg.Services = cfg.Client.Services()
+	}
+	if cfg.HealthPath == "" {
+		cfg.HealthPath = "/health"
+	}
+	if cfg.VersionPath == "" {
+		cfg.VersionPath = "/version"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 3 * time.Second
+	}
+	if cfg.SlowThreshold <= 0 {
+		cfg.SlowThreshold = time.Second
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = 10 * time.Second
+	}
+
+	f := &Fleet{cfg: cfg, critical: make(map[string]bool)}
+	for _, name := range cfg.Critical {
+		f.critical[name] = true
+	}
+	if len(cfg.Critical) == 0 {
+		for _, name := range cfg.Services {
+			f.critical[name] = true
+		}
+	}
+	return f
+}
+
+// Report returns the cached report, probing the fleet when it expired; concurrent callers
+// share one round of probes
+func (f *Fleet) Report(ctx context.Context) *Report {
+	f.mu.Lock()
+	if f.last != nil && time.Now().Before(f.expiry) {
+		r := f.last
+		f.mu.Unlock()
+		return r
+	}
+	f.mu.Unlock()
+
+	v, _, _ := f.group.Do("check", func() (interface{}, error) {
+		// Detached so one caller giving up does not fail the probes the others wait for
+		r := f.Check(context.WithoutCancel(ctx))
+		f.mu.Lock()
+		f.last, f.expiry = r, time.Now().Add(f.cfg.CacheTTL)
+		f.mu.Unlock()
+		return r, nil
+	})
+	return v.(*Report)
+}
+
+// Check probes every service now, bypassing the cache
+func (f *Fleet) Check(ctx context.Context) *Report {
+	report := &Report{Status: StatusUp, Services: make([]ServiceStatus, len(f.cfg.Services)), CheckedAt: time.Now().UTC()}
+	var wg sync.WaitGroup
+	for i, name := range f.cfg.Services {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			report.Services[i] = f.probe(ctx, name)
+		}(i, name)
+	}
+	wg.Wait()
+
+	for _, s := range report.Services {
+		serviceUp.WithLabelValues(s.Service).Set(boolGauge(s.Status != StatusDown))
+		serviceLatency.WithLabelValues(s.Service).Set(float64(s.LatencyMS) / 1000)
+		switch {
+		case s.Status == StatusDown && s.Critical:
+			report.Status = StatusDown
+		case s.Status != StatusUp && report.Status == StatusUp:
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// Handler serves the report, with 503 when the fleet is down so load balancers and uptime
+// checks can use it directly; ?refresh=true bypasses the cache. Like the readiness endpoint,
+// probe errors are hidden in production since they name internal hosts.
+func (f *Fleet) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var report *Report
+		if c.Query("refresh") == "true" {
+			report = f.Check(c.Request.Context())
+		} else {
+			report = f.Report(c.Request.Context())
+		}
+		if config.IsProduction() {
+			redacted := *report
+			redacted.Services = append([]ServiceStatus(nil), report.Services...)
+			for i := range redacted.Services {
+				if redacted.Services[i].Error != "" {
+					redacted.Services[i].Error = "check failed"
+				}
+			}
+			report = &redacted
+		}
+		if report.Status == StatusDown {
+			response.JSON(c, http.StatusServiceUnavailable, false, report, "Dependencies unavailable", nil)
+			return
+		}
+		response.OK(c, report)
+	}
+}
+
+// probe checks the health endpoint of a service, then reads its version
+func (f *Fleet) probe(ctx context.Context, name string) ServiceStatus {
+	ctx, cancel := context.WithTimeout(ctx, f.cfg.Timeout)
+	defer cancel()
+	s := ServiceStatus{Service: name, Critical: f.critical[name], Status: StatusDown}
+
+	start := time.Now()
+	resp, err := f.cfg.Client.Probe(ctx, name, f.cfg.HealthPath)
+	latency := time.Since(start)
+	s.LatencyMS = latency.Milliseconds()
+	s.CheckedAt = time.Now().UTC()
+	if err != nil {
+		s.Error = err.Error()
+		return s
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		s.Error = fmt.Sprintf("health check returned %d", resp.StatusCode)
+		return s
+	}
+
+	s.Status = StatusUp
+	if latency >= f.cfg.SlowThreshold {
+		s.Status = StatusDegraded
+	}
+	s.Version, s.Commit = f.version(ctx, name)
+	return s
+}
+
+// version reads the build info a service serves at VersionPath; failures leave it empty
+func (f *Fleet) version(ctx context.Context, name string) (string, string) {
+	resp, err := f.cfg.Client.Probe(ctx, name, f.cfg.VersionPath)
+	if err != nil {
+		return "", ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return "", ""
+	}
+	var envelope struct {
+		Data struct {
+			Version string `json:"version"`
+			Commit  string `json:"commit"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&envelope); err != nil {
+		return "", ""
+	}
+	return envelope.Data.Version, envelope.Data.Commit
+}
+
+func boolGauge(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,19 @@
+package health
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Up state and probe latency of the fleet services a health.Fleet watches
+var (
+	serviceUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "fleet_service_up",
+		Help: "Whether the downstream service answered its last health probe (1) or not (0).",
+	}, []string{"service"})
+
+	serviceLatency = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "fleet_service_probe_latency_seconds",
+		Help: "Latency of the last health probe of the downstream service.",
+	}, []string{"service"})
+)
@@ -0,0 +1,212 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Strategy picks among the hosts of a service
+type Strategy string
+
+const (
+	// RoundRobin cycles through the healthy hosts
+	RoundRobin Strategy = "round_robin"
+	// LeastFailures prefers the healthy host with the fewest recent consecutive failures
+	LeastFailures Strategy = "least_failures"
+)
+
+// LoadBalancing configures how calls spread over services with several hosts; zero values get
+// the defaults
+type LoadBalancing struct {
+	// Strategy defaults to RoundRobin
+	Strategy Strategy
+	// FailureThreshold takes a host out of rotation after this many consecutive failures
+	// (network errors, timeouts, 5xx), defaults to 3
+	FailureThreshold int
+	// Cooldown is how long a failing host stays out of rotation, defaults to 30s
+	Cooldown time.Duration
+}
+
+// WithLoadBalancing sets how hosts are picked for services configured with several, e.g.
+// ServiceConfig{"documents": "http://documents-1:8080,http://documents-2:8080"}. Each attempt
+// picks a host, so a retry goes to another replica; when every host is out of rotation, calls
+// still go out so the client never stops on stale health.
+func (c *ServiceClient) WithLoadBalancing(cfg LoadBalancing) *ServiceClient {
+	c.balancing = cfg
+	return c
+}
+
+// HostHealth is the state of one host of a service, see ServiceClient.Hosts
+type HostHealth struct {
+	URL                 string    `json:"url"`
+	Healthy             bool      `json:"healthy"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	DownUntil           time.Time `json:"down_until,omitempty"`
+}
+
+// Hosts returns the hosts of a service and their health
+func (c *ServiceClient) Hosts(service string) []HostHealth {
+	pool, ok := c.pools[service]
+	if !ok {
+		return nil
+	}
+	now := time.Now()
+	out := make([]HostHealth, len(pool.hosts))
+	for i, h := range pool.hosts {
+		out[i] = HostHealth{URL: h.base, Healthy: h.healthy(now), ConsecutiveFailures: int(h.failures.Load())}
+		if until := h.downUntil.Load(); until > now.UnixNano() {
+			out[i].DownUntil = time.Unix(0, until)
+		}
+	}
+	return out
+}
+
+// hostPool holds the hosts of one service
+type hostPool struct {
+	hosts []*host
+	next  atomic.Uint64
+}
+
+// host is one base URL of a service and its recent outcomes
+type host struct {
+	service   string
+	base      string
+	failures  atomic.Int32
+	downUntil atomic.Int64 // UnixNano
+}
+
+// newPools parses the comma separated hosts of each service
+func newPools(config ServiceConfig) (map[string]*hostPool, map[string]*host) {
+	pools := make(map[string]*hostPool, len(config))
+	byOrigin := make(map[string]*host)
+	for service, raw := range config {
+		pool := &hostPool{}
+		for _, base := range splitHosts(raw) {
+			h := &host{service: service, base: strings.TrimSuffix(base, "/")}
+			pool.hosts = append(pool.hosts, h)
+			if origin := originOf(h.base); origin != "" {
+				byOrigin[origin] = h
+			}
+		}
+		pools[service] = pool
+	}
+	return pools, byOrigin
+}
+
+// hostURLs lists every configured host for validation, named "service" or "service[i]"
+func hostURLs(config ServiceConfig) map[string]string {
+	out := make(map[string]string, len(config))
+	for service, raw := range config {
+		hosts := splitHosts(raw)
+		if len(hosts) == 1 {
+			out[service] = hosts[0]
+			continue
+		}
+		for i, base := range hosts {
+			out[fmt.Sprintf("%s[%d]", service, i)] = base
+		}
+	}
+	return out
+}
+
+func splitHosts(raw string) []string {
+	var hosts []string
+	for _, base := range strings.Split(raw, ",") {
+		if base = strings.TrimSpace(base); base != "" {
+			hosts = append(hosts, base)
+		}
+	}
+	if len(hosts) == 0 {
+		// Kept so an empty entry fails validation instead of vanishing
+		hosts = append(hosts, raw)
+	}
+	return hosts
+}
+
+// originOf returns scheme://host of a base URL, which is how responses are matched to hosts
+func originOf(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// pick returns the host for the next attempt
+func (p *hostPool) pick(cfg LoadBalancing) *host {
+	if len(p.hosts) == 1 {
+		return p.hosts[0]
+	}
+	now := time.Now()
+	start := int(p.next.Add(1) % uint64(len(p.hosts)))
+	var best *host
+	for i := range p.hosts {
+		h := p.hosts[(start+i)%len(p.hosts)]
+		if !h.healthy(now) {
+			continue
+		}
+		if cfg.Strategy != LeastFailures {
+			return h
+		}
+		if best == nil || h.failures.Load() < best.failures.Load() {
+			best = h
+		}
+	}
+	if best != nil {
+		return best
+	}
+	// Every host is out of rotation: the one that failed the least gets the call
+	best = p.hosts[start]
+	for _, h := range p.hosts {
+		if h.failures.Load() < best.failures.Load() {
+			best = h
+		}
+	}
+	return best
+}
+
+func (h *host) healthy(now time.Time) bool {
+	return h.downUntil.Load() <= now.UnixNano()
+}
+
+// observe records the outcome of a request in the health of the host it went to; callers
+// giving up do not count against the host
+func (c *ServiceClient) observe(req *http.Request, resp *http.Response, err error) {
+	h, ok := c.origins[req.URL.Scheme+"://"+req.URL.Host]
+	if !ok || errors.Is(err, context.Canceled) || (err == nil && resp == nil) {
+		return
+	}
+	if err == nil && resp.StatusCode < http.StatusInternalServerError {
+		h.failures.Store(0)
+		h.downUntil.Store(0)
+		return
+	}
+	threshold := c.balancing.FailureThreshold
+	if threshold <= 0 {
+		threshold = 3
+	}
+	cooldown := c.balancing.Cooldown
+	if cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+	if int(h.failures.Add(1)) >= threshold {
+		h.downUntil.Store(time.Now().Add(cooldown).UnixNano())
+	}
+}
+
+// serviceOf returns the service a request goes to, from its api/vX/service path or else its host
+func (c *ServiceClient) serviceOf(req *http.Request) string {
+	if name := serviceName(req.URL.Path); c.pools[name] != nil {
+		return name
+	}
+	if h, ok := c.origins[req.URL.Scheme+"://"+req.URL.Host]; ok {
+		return h.service
+	}
+	return "unknown"
+}
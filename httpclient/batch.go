@@ -0,0 +1,88 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// DefaultBatchConcurrency is how many requests of a batch run at once unless
+// WithBatchConcurrency says otherwise
+const DefaultBatchConcurrency = 8
+
+// Request is one call of a batch; Method defaults to GET
+type Request struct {
+	Method  string
+	Route   string
+	Payload interface{}
+	Options []RequestOption
+}
+
+// Result is the outcome of one call of a batch: a response with a 2xx status, whose body the
+// caller closes, or the error Do would have returned
+type Result struct {
+	Response *http.Response
+	Err      error
+}
+
+// Decode reads the data of the standard response envelope into v and closes the body
+func (r Result) Decode(v interface{}) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	return DecodeStandardResponse(r.Response, v)
+}
+
+// WithBatchConcurrency bounds how many requests of a batch run at once
+func (c *ServiceClient) WithBatchConcurrency(n int) *ServiceClient {
+	c.batchConcurrency = n
+	return c
+}
+
+// Batch sends the requests concurrently, a bounded number at a time, and returns their results
+// in the same order; one failing request does not affect the others, e.g.
+//
+//	results := client.Batch(ctx, []httpclient.Request{
+//		{Route: "/api/v1/users/:id", Options: []httpclient.RequestOption{httpclient.WithPathParam("id", id)}},
+//		{Route: "/api/v1/billing/accounts", Options: []httpclient.RequestOption{httpclient.WithQuery("user_id", id)}},
+//	})
+//	err := results[0].Decode(&user)
+//
+// Requests not started when ctx is done fail with its error.
+func (c *ServiceClient) Batch(ctx context.Context, requests []Request) []Result {
+	results := make([]Result, len(requests))
+	workers := c.batchConcurrency
+	if workers <= 0 {
+		workers = DefaultBatchConcurrency
+	}
+	if workers > len(requests) {
+		workers = len(requests)
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				req := requests[i]
+				if req.Method == "" {
+					req.Method = http.MethodGet
+				}
+				resp, err := c.Do(ctx, req.Method, req.Route, req.Payload, req.Options...)
+				results[i] = Result{Response: resp, Err: err}
+			}
+		}()
+	}
+	for i := range requests {
+		if ctx.Err() != nil {
+			results[i] = Result{Err: ctx.Err()}
+			continue
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,108 @@
+package httpclient
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Masharah-Advisory/common/breaker"
+)
+
+// BreakerConfig configures the per-service circuit breakers; zero values get the breaker
+// package defaults
+type BreakerConfig struct {
+	// FailureThreshold opens a circuit after this many consecutive failures (network errors,
+	// 5xx, timeouts), defaults to 6
+	FailureThreshold uint32
+	// Cooldown is how long a circuit stays open before probing, defaults to 60s
+	Cooldown time.Duration
+	// HalfOpenRequests is how many probe calls are let through while half-open, defaults to 1
+	HalfOpenRequests uint32
+	// Interval resets the failure counts while closed, 0 never resets them
+	Interval time.Duration
+}
+
+// WithBreakerConfig guards each downstream service with its own circuit breaker named
+// "httpclient:<service>", configured for this client
+func (c *ServiceClient) WithBreakerConfig(cfg BreakerConfig) *ServiceClient {
+	st := breaker.Settings{
+		MaxRequests: cfg.HalfOpenRequests,
+		Interval:    cfg.Interval,
+		Timeout:     cfg.Cooldown,
+	}
+	if cfg.FailureThreshold > 0 {
+		threshold := cfg.FailureThreshold
+		st.ReadyToTrip = func(counts breaker.Counts) bool { return counts.ConsecutiveFailures >= threshold }
+	}
+
+	c.breakerMu.Lock()
+	defer c.breakerMu.Unlock()
+	c.breakers = true
+	c.breakerSettings = &st
+	c.breakerSet = make(map[string]*breaker.Breaker)
+	return c
+}
+
+// breakerFor returns the breaker guarding service
+func (c *ServiceClient) breakerFor(service string) *breaker.Breaker {
+	c.breakerMu.Lock()
+	defer c.breakerMu.Unlock()
+	if b, ok := c.breakerSet[service]; ok {
+		return b
+	}
+
+	name := "httpclient:" + service
+	var b *breaker.Breaker
+	if c.breakerSettings != nil {
+		st := *c.breakerSettings
+		st.Name = name
+		b = breaker.Configure(st)
+	} else {
+		b = breaker.Get(name)
+	}
+	if c.breakerSet == nil {
+		c.breakerSet = make(map[string]*breaker.Breaker)
+	}
+	c.breakerSet[service] = b
+	return b
+}
+
+// BreakerState returns the circuit state of a downstream service; services that were not
+// called yet are closed
+func (c *ServiceClient) BreakerState(service string) breaker.State {
+	c.breakerMu.Lock()
+	b, ok := c.breakerSet[service]
+	c.breakerMu.Unlock()
+	if !ok {
+		return breaker.StateClosed
+	}
+	return b.State()
+}
+
+// BreakerStates returns the circuit state of every service called so far, e.g. for a health
+// endpoint
+func (c *ServiceClient) BreakerStates() map[string]string {
+	c.breakerMu.Lock()
+	services := make([]string, 0, len(c.breakerSet))
+	for service := range c.breakerSet {
+		services = append(services, service)
+	}
+	c.breakerMu.Unlock()
+
+	states := make(map[string]string, len(services))
+	for _, service := range services {
+		states[service] = c.BreakerState(service).String()
+	}
+	return states
+}
+
+// OpenBreakers returns the services whose circuit is currently open
+func (c *ServiceClient) OpenBreakers() []string {
+	var open []string
+	for service, state := range c.BreakerStates() {
+		if state == breaker.StateOpen.String() {
+			open = append(open, service)
+		}
+	}
+	sort.Strings(open)
+	return open
+}
@@ -0,0 +1,181 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/cache"
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/go-redis/redis/v8"
+)
+
+// ResponseStore keeps cached downstream responses; *cache.Cache[cache.Response] implements it
+type ResponseStore interface {
+	Get(ctx context.Context, key string) (cache.Response, bool, error)
+	Set(ctx context.Context, key string, value cache.Response, ttl ...time.Duration) error
+	InvalidatePattern(ctx context.Context, pattern string) error
+}
+
+// ResponseCacheConfig configures the caching of GET responses
+type ResponseCacheConfig struct {
+	// Store holds the responses; defaults to a two-tier cache named "httpclient" on Redis
+	Store ResponseStore
+	// Redis backs the default store; nil keeps responses in memory only
+	Redis *redis.Client
+	// Routes maps route prefixes to how long their responses are kept, e.g.
+	// {"/api/v1/auth/permissions": 5 * time.Minute}; only GETs to listed routes are cached and
+	// the longest matching prefix wins
+	Routes map[string]time.Duration
+	// Public are route prefixes whose responses are the same for everyone, e.g. reference data;
+	// only they are cached for calls made without a user or tenant
+	Public []string
+}
+
+type responseCache struct {
+	store  ResponseStore
+	routes map[string]time.Duration
+	public []string
+}
+
+// uncachedHeaders change with every call and never with the response, so they are left out of
+// cache keys
+var uncachedHeaders = map[string]bool{
+	requestIDHeader:        true,
+	ctxutil.DeadlineHeader: true,
+	IdempotencyKeyHeader:   true,
+	"X-Correlation-Id":     true,
+	"Traceparent":          true,
+	"Tracestate":           true,
+}
+
+// WithResponseCache caches successful GET responses of the listed routes, e.g. permission
+// metadata or reference data. Entries are keyed on the route with its query and every header
+// sent downstream (user, tenant, language, propagated headers such as Authorization), so users
+// never see each other's responses; calls without a user or tenant are only cached on Public
+// routes. Cached responses carry X-Cache: HIT.
+func (c *ServiceClient) WithResponseCache(cfg ResponseCacheConfig) *ServiceClient {
+	if cfg.Store == nil {
+		cfg.Store = cache.New[cache.Response](&cache.Config{Name: "httpclient", Redis: cfg.Redis})
+	}
+	c.cache = &responseCache{store: cfg.Store, routes: cfg.Routes, public: cfg.Public}
+	return c
+}
+
+// InvalidateCache drops the cached responses of routes starting with prefix, for every user,
+// e.g. after changing a role: client.InvalidateCache(ctx, "/api/v1/auth/permissions")
+func (c *ServiceClient) InvalidateCache(ctx context.Context, prefix string) error {
+	if c.cache == nil {
+		return nil
+	}
+	return c.cache.store.InvalidatePattern(ctx, escapeGlob(cacheRoute(prefix))+"*")
+}
+
+// cacheTTL returns how long the response of a request is kept, 0 when it is not cached
+func (c *ServiceClient) cacheTTL(method, route string) time.Duration {
+	if c.cache == nil || method != http.MethodGet {
+		return 0
+	}
+	return c.cache.ttlFor(route)
+}
+
+// ttlFor returns how long responses of route are kept, 0 when they are not cached
+func (rc *responseCache) ttlFor(route string) time.Duration {
+	route = "/" + strings.TrimPrefix(route, "/")
+	best, ttl := -1, time.Duration(0)
+	for prefix, d := range rc.routes {
+		if strings.HasPrefix(route, "/"+strings.TrimPrefix(prefix, "/")) && len(prefix) > best {
+			best, ttl = len(prefix), d
+		}
+	}
+	return ttl
+}
+
+// isPublic reports whether route may be cached for calls without a user or tenant
+func (rc *responseCache) isPublic(route string) bool {
+	route = "/" + strings.TrimPrefix(route, "/")
+	for _, prefix := range rc.public {
+		if strings.HasPrefix(route, "/"+strings.TrimPrefix(prefix, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheKey keys a response on its route and the headers it was requested with, hashed so
+// tokens never show in Redis keys; the route stays readable for InvalidateCache
+func cacheKey(route string, headers map[string]string) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		if !uncachedHeaders[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%s:%s\n", name, headers[name])
+	}
+	return cacheRoute(route) + "|" + hex.EncodeToString(h.Sum(nil))
+}
+
+// cachedGet serves a GET from the cache, or sends it through do and caches a 200 response;
+// headers are keyed by canonical name, see extractHeaders
+func (rc *responseCache) cachedGet(ctx context.Context, route string, headers map[string]string, ttl time.Duration, do func() (*http.Response, error)) (*http.Response, error) {
+	if headers[userIDHeader] == "" && headers[tenantIDHeader] == "" && !rc.isPublic(route) {
+		return do()
+	}
+	key := cacheKey(route, headers)
+	if cached, ok, _ := rc.store.Get(ctx, key); ok {
+		header := cached.Header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		header.Set("X-Cache", "HIT")
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", cached.Status, http.StatusText(cached.Status)),
+			StatusCode:    cached.Status,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+			ContentLength: int64(len(cached.Body)),
+		}, nil
+	}
+
+	resp, err := do()
+	if err != nil || resp.StatusCode != http.StatusOK || strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	// Redis errors are logged by the store; the response is served either way
+	_ = rc.store.Set(ctx, key, cache.Response{Status: resp.StatusCode, Header: resp.Header.Clone(), Body: body}, ttl)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// cacheRoute turns the slashes of a route into colons, since in-memory invalidation patterns
+// do not match "*" across slashes
+func cacheRoute(route string) string {
+	return strings.ReplaceAll("/"+strings.TrimPrefix(route, "/"), "/", ":")
+}
+
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,74 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/gin-gonic/gin"
+)
+
+func TestResponseCacheKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		io.WriteString(w, r.Header.Get("X-User-ID")+"|"+r.Header.Get("Authorization"))
+	}))
+	defer srv.Close()
+	client := NewServiceClient("test", "secret", ServiceConfig{"auth": srv.URL}).
+		WithHeaderPropagation(append(DefaultPropagatedHeaders, "Authorization")...).
+		WithResponseCache(ResponseCacheConfig{
+			Routes: map[string]time.Duration{"/api/v1/auth": time.Minute},
+			Public: []string{"/api/v1/auth/public"},
+		})
+
+	// incoming builds a handler context for a request carrying header, as a service behind
+	// ServiceAuthMiddleware sees it
+	incoming := func(header http.Header) context.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		c.Request.Header = header
+		return c
+	}
+	tests := []struct {
+		name   string
+		route  string
+		header http.Header
+		want   string
+		calls  int32
+	}{
+		{"first user", "/api/v1/auth/permissions", http.Header{"X-User-Id": {"1"}}, "1|", 1},
+		{"first user cached", "/api/v1/auth/permissions", http.Header{"X-User-Id": {"1"}}, "1|", 1},
+		{"second user", "/api/v1/auth/permissions", http.Header{"X-User-Id": {"2"}}, "2|", 2},
+		{"second token", "/api/v1/auth/permissions", http.Header{"X-User-Id": {"2"}, "Authorization": {"Bearer b"}}, "2|Bearer b", 3},
+		{"ctxutil user", "/api/v1/auth/permissions", nil, "3|", 4},
+		{"anonymous", "/api/v1/auth/permissions", http.Header{}, "|", 5},
+		{"anonymous again", "/api/v1/auth/permissions", http.Header{}, "|", 6},
+		{"anonymous public", "/api/v1/auth/public", http.Header{}, "|", 7},
+		{"anonymous public cached", "/api/v1/auth/public", http.Header{}, "|", 7},
+	}
+	for _, tt := range tests {
+		ctx := incoming(tt.header)
+		if tt.header == nil {
+			ctx = ctxutil.WithUserID(context.Background(), 3)
+		}
+		resp, err := client.Get(ctx, tt.route)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != tt.want {
+			t.Errorf("%s: body = %q, want %q", tt.name, body, tt.want)
+		}
+		if got := calls.Load(); got != tt.calls {
+			t.Errorf("%s: service called %d times, want %d", tt.name, got, tt.calls)
+		}
+	}
+}
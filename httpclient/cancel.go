@@ -0,0 +1,61 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// outboundContext returns the context a downstream call runs under. A *gin.Context, or a context
+// wrapping one, is never cancelled since gin only forwards Done with ContextWithFallback, so the
+// call would outlive a client that disconnected; the returned context is also cancelled with the
+// incoming request. Values resolve from ctx first. release must run once the response is done.
+func outboundContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ginCtx, ok := ctx.Value(gin.ContextKey).(*gin.Context)
+	if !ok || ginCtx.Request == nil {
+		return ctx, func() {}
+	}
+	base, cancel := context.WithCancel(ginCtx.Request.Context())
+	// ctx may still carry its own deadline or cancellation, e.g. context.WithTimeout(c, d)
+	stop := context.AfterFunc(ctx, cancel)
+	return &requestContext{Context: base, values: ctx}, func() {
+		stop()
+		cancel()
+	}
+}
+
+// requestContext is cancelled with the incoming request but looks values up in the caller's
+// context first, falling back to the request's (e.g. the trace span)
+type requestContext struct {
+	context.Context
+	values context.Context
+}
+
+// Deadline is the earlier of the caller's deadline, e.g. context.WithTimeout(c, d), and the
+// request's, so withBudget sees the one that ends the call first
+func (c *requestContext) Deadline() (time.Time, bool) {
+	deadline, ok := c.values.Deadline()
+	if d, has := c.Context.Deadline(); has && (!ok || d.Before(deadline)) {
+		return d, true
+	}
+	return deadline, ok
+}
+
+func (c *requestContext) Value(key any) any {
+	if v := c.values.Value(key); v != nil {
+		return v
+	}
+	return c.Context.Value(key)
+}
+
+// releaseOnClose ties release to the response body, or runs it right away when there is none
+func releaseOnClose(resp *http.Response, err error, release context.CancelFunc) (*http.Response, error) {
+	if err != nil || resp == nil {
+		release()
+		return resp, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: release}
+	return resp, nil
+}
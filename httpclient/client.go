@@ -7,132 +7,408 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/textproto"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/gin-gonic/gin"
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/breaker"
+	"github.com/Masharah-Advisory/common/buildinfo"
+	appconfig "github.com/Masharah-Advisory/common/config"
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/retry"
+	"github.com/Masharah-Advisory/common/telemetry"
 )
 
+// ServiceCaller is the request surface of ServiceClient; depend on it so tests can swap in a fake
+type ServiceCaller interface {
+	Get(ctx context.Context, route string, opts ...RequestOption) (*http.Response, error)
+	Post(ctx context.Context, route string, payload interface{}, opts ...RequestOption) (*http.Response, error)
+	Put(ctx context.Context, route string, payload interface{}, opts ...RequestOption) (*http.Response, error)
+	Delete(ctx context.Context, route string, opts ...RequestOption) (*http.Response, error)
+}
+
+// MethodCaller sends a request with any method; ServiceClient implements it
+type MethodCaller interface {
+	Do(ctx context.Context, method, route string, payload interface{}, opts ...RequestOption) (*http.Response, error)
+}
+
 // ServiceClient is a smart HTTP client for service-to-service communication
 type ServiceClient struct {
-	client        *http.Client
-	serviceID     string
-	serviceSecret string
-	serviceHosts  map[string]string
+	client           *http.Client
+	transport        *http.Transport
+	serviceID        string
+	serviceSecret    string
+	pools            map[string]*hostPool
+	origins          map[string]*host
+	balancing        LoadBalancing
+	retry            *retry.Policy
+	retryMethods     map[string]bool
+	timeout          time.Duration
+	timeouts         ServiceTimeouts
+	interceptors     []Interceptor
+	logging          *requestLogger
+	breakers         bool
+	cache            *responseCache
+	metrics          *clientMetrics
+	propagation      *headerPolicy
+	batchConcurrency int
+	hopMargin        time.Duration
+
+	breakerMu       sync.Mutex
+	breakerSettings *breaker.Settings
+	breakerSet      map[string]*breaker.Breaker
 }
 
-// ServiceConfig holds service host mappings (only configure what you need)
+// ServiceConfig holds service host mappings (only configure what you need); a service with
+// several replicas lists their hosts separated by commas, see WithLoadBalancing
 type ServiceConfig map[string]string
 
 // NewServiceClient creates a new service client
 func NewServiceClient(serviceID, serviceSecret string, config ServiceConfig) *ServiceClient {
+	registerHosts(serviceID, config)
+
+	transport := newTransport()
+	pools, origins := newPools(config)
 	return &ServiceClient{
+		// Timeouts are applied per attempt through the request context, see timeoutFor
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Transport: telemetry.Transport(transport),
 		},
+		transport:     transport,
 		serviceID:     serviceID,
 		serviceSecret: serviceSecret,
-		serviceHosts:  config,
+		pools:         pools,
+		origins:       origins,
+		propagation:   newHeaderPolicy(DefaultPropagatedHeaders),
+	}
+}
+
+var (
+	serviceHostsMu sync.Mutex
+	serviceHosts   = make(map[string]map[string]string)
+)
+
+// registerHosts adds the client's hosts to the startup check of serviceID, merged with those of
+// the service's other clients so building a second client doesn't drop the first one's check
+func registerHosts(serviceID string, config ServiceConfig) {
+	serviceHostsMu.Lock()
+	defer serviceHostsMu.Unlock()
+	hosts := serviceHosts[serviceID]
+	if hosts == nil {
+		hosts = make(map[string]string)
+		serviceHosts[serviceID] = hosts
+	}
+	for name, base := range hostURLs(config) {
+		if prev, ok := hosts[name]; ok && prev != base {
+			name += " " + base
+		}
+		hosts[name] = base
+	}
+	urls := make(map[string]string, len(hosts))
+	for name, base := range hosts {
+		urls[name] = base
 	}
+	appconfig.RegisterValidator("httpclient."+serviceID+".hosts", appconfig.RequireURLs(urls))
+}
+
+// WithRetry retries idempotent requests (GET, HEAD, OPTIONS, PUT, DELETE) that fail with
+// network errors or 5xx/429 responses; POST and PATCH are never retried. Use WithRetryConfig
+// to choose the statuses and methods.
+func (c *ServiceClient) WithRetry(policy retry.Policy) *ServiceClient {
+	c.retry = &policy
+	c.retryMethods = nil
+	return c
+}
+
+// WithBreaker guards each downstream service with a shared circuit breaker named
+// "httpclient:<service>", so calls fail fast while a service is down; use WithBreakerConfig to
+// tune the thresholds
+func (c *ServiceClient) WithBreaker() *ServiceClient {
+	c.breakers = true
+	return c
+}
+
+// WithTransport wraps the client's transport, e.g. with faults.Transport to test how callers
+// cope with a failing downstream
+func (c *ServiceClient) WithTransport(wrap func(http.RoundTripper) http.RoundTripper) *ServiceClient {
+	c.client.Transport = wrap(c.client.Transport)
+	return c
 }
 
 // Get performs a smart GET request with auto context extraction
-func (c *ServiceClient) Get(ctx context.Context, route string) (*http.Response, error) {
-	return c.smartRequest(ctx, "GET", route, nil)
+func (c *ServiceClient) Get(ctx context.Context, route string, opts ...RequestOption) (*http.Response, error) {
+	return c.smartRequest(ctx, "GET", route, nil, opts...)
 }
 
 // Post performs a smart POST request with auto context extraction
-func (c *ServiceClient) Post(ctx context.Context, route string, payload interface{}) (*http.Response, error) {
-	return c.smartRequest(ctx, "POST", route, payload)
+func (c *ServiceClient) Post(ctx context.Context, route string, payload interface{}, opts ...RequestOption) (*http.Response, error) {
+	return c.smartRequest(ctx, "POST", route, payload, opts...)
 }
 
 // Put performs a smart PUT request with auto context extraction
-func (c *ServiceClient) Put(ctx context.Context, route string, payload interface{}) (*http.Response, error) {
-	return c.smartRequest(ctx, "PUT", route, payload)
+func (c *ServiceClient) Put(ctx context.Context, route string, payload interface{}, opts ...RequestOption) (*http.Response, error) {
+	return c.smartRequest(ctx, "PUT", route, payload, opts...)
 }
 
 // Delete performs a smart DELETE request with auto context extraction
-func (c *ServiceClient) Delete(ctx context.Context, route string) (*http.Response, error) {
-	return c.smartRequest(ctx, "DELETE", route, nil)
+func (c *ServiceClient) Delete(ctx context.Context, route string, opts ...RequestOption) (*http.Response, error) {
+	return c.smartRequest(ctx, "DELETE", route, nil, opts...)
 }
 
-// smartRequest auto-detects service and extracts headers from context
-func (c *ServiceClient) smartRequest(ctx context.Context, method, route string, payload interface{}) (*http.Response, error) {
-	// Build full URL by detecting service
+// Patch performs a smart PATCH request with auto context extraction
+func (c *ServiceClient) Patch(ctx context.Context, route string, payload interface{}, opts ...RequestOption) (*http.Response, error) {
+	return c.smartRequest(ctx, http.MethodPatch, route, payload, opts...)
+}
+
+// Head performs a smart HEAD request with auto context extraction; the response has no body
+func (c *ServiceClient) Head(ctx context.Context, route string, opts ...RequestOption) (*http.Response, error) {
+	return c.smartRequest(ctx, http.MethodHead, route, nil, opts...)
+}
+
+// Options performs a smart OPTIONS request with auto context extraction
+func (c *ServiceClient) Options(ctx context.Context, route string, opts ...RequestOption) (*http.Response, error) {
+	return c.smartRequest(ctx, http.MethodOptions, route, nil, opts...)
+}
+
+// Do performs a smart request with any method, with the same headers, retries and error
+// handling as Get and Post
+func (c *ServiceClient) Do(ctx context.Context, method, route string, payload interface{}, opts ...RequestOption) (*http.Response, error) {
+	return c.smartRequest(ctx, strings.ToUpper(method), route, payload, opts...)
+}
+
+// Raw sends body as is to the service owning route and returns the response whatever its
+// status, e.g. to replay a captured request; header is added to the service and context
+// headers. It is never retried.
+func (c *ServiceClient) Raw(ctx context.Context, method, route string, body []byte, header http.Header) (*http.Response, error) {
 	fullURL, err := c.buildURL(route)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build URL: %w", err)
 	}
+	headers := c.extractHeaders(ctx)
+	reqCtx, release := outboundContext(ctx)
+	reqCtx, release, err = c.withBudget(reqCtx, headers, release)
+	if err != nil {
+		return nil, err
+	}
+	reqCtx, cancel := context.WithTimeout(reqCtx, c.timeoutFor(ctx, serviceName(route)))
+	req, err := http.NewRequestWithContext(reqCtx, method, fullURL, bytes.NewReader(body))
+	if err != nil {
+		cancel()
+		release()
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+	for key, value := range headers {
+		if req.Header.Get(key) == "" {
+			req.Header.Set(key, value)
+		}
+	}
+	// A captured request carries a deadline long gone
+	req.Header.Del(ctxutil.DeadlineHeader)
+	if deadline := headers[ctxutil.DeadlineHeader]; deadline != "" {
+		req.Header.Set(ctxutil.DeadlineHeader, deadline)
+	}
+	req.Header.Set("X-Service-ID", c.serviceID)
+	req.Header.Set("X-Service-Secret", c.serviceSecret)
+	req.Header.Set("User-Agent", buildinfo.UserAgent(c.serviceID))
+
+	resp, err := c.send(req)
+	if err != nil {
+		err = fmt.Errorf("request failed: %w", err)
+	}
+	return releaseOnClose(resp, err, func() {
+		cancel()
+		release()
+	})
+}
+
+// Probe GETs path (e.g. "/health") from the root of a configured service and returns the
+// response whatever its status. Probes skip retries and circuit breakers so they report the
+// service as it is.
+func (c *ServiceClient) Probe(ctx context.Context, service, path string) (*http.Response, error) {
+	pool, ok := c.pools[service]
+	if !ok {
+		return nil, fmt.Errorf("no host configured for service: %s", service)
+	}
+	host := pool.pick(c.balancing).base
+	headers := c.extractHeaders(ctx)
+	reqCtx, release := outboundContext(ctx)
+	reqCtx, release, err := c.withBudget(reqCtx, headers, release)
+	if err != nil {
+		return nil, err
+	}
+	reqCtx, cancel := context.WithTimeout(reqCtx, c.timeoutFor(ctx, service))
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, host+"/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		cancel()
+		release()
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("X-Service-ID", c.serviceID)
+	req.Header.Set("X-Service-Secret", c.serviceSecret)
+	req.Header.Set("User-Agent", buildinfo.UserAgent(c.serviceID))
+
+	resp, err := c.send(req)
+	if err != nil {
+		err = fmt.Errorf("request failed: %w", err)
+	}
+	return releaseOnClose(resp, err, func() {
+		cancel()
+		release()
+	})
+}
+
+// Services returns the names of the configured services, sorted
+func (c *ServiceClient) Services() []string {
+	names := make([]string, 0, len(c.pools))
+	for name := range c.pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// smartRequest auto-detects service and extracts headers from context
+func (c *ServiceClient) smartRequest(ctx context.Context, method, route string, payload interface{}, opts ...RequestOption) (*http.Response, error) {
+	route, err := BuildRoute(route, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build route: %w", err)
+	}
+
+	// Detect the service; each attempt picks one of its hosts
+	urlFor, err := c.resolve(route)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL: %w", err)
+	}
 
 	// Extract headers from context
 	headers := c.extractHeaders(ctx)
+	timeout := c.timeoutFor(ctx, serviceName(route))
+
+	// Aborted when the incoming request is, also between retries
+	ctx, release := outboundContext(ctx)
+	ctx, release, err = c.withBudget(ctx, headers, release)
+	if err != nil {
+		return nil, err
+	}
+	hedge := hedgingOf(opts)
+	// Every attempt and duplicate of a write carries the same key
+	if key := idempotencyKey(method, c.retries(method) || hedge != nil, opts); key != "" {
+		headers[IdempotencyKeyHeader] = key
+	}
+	attempt := func(ctx context.Context) (*http.Response, error) {
+		if hedge != nil {
+			return c.doHedged(ctx, hedge, method, urlFor, payload, headers, timeout)
+		}
+		return c.doRequest(ctx, method, urlFor(), payload, headers, timeout)
+	}
+	do := func(ctx context.Context) (*http.Response, error) {
+		if !c.retries(method) {
+			return attempt(ctx)
+		}
+		return retry.DoValue(ctx, *c.retry, attempt)
+	}
+	send := func() (*http.Response, error) {
+		if !c.breakers {
+			return do(ctx)
+		}
+		return breaker.Call(ctx, c.breakerFor(serviceName(route)), do)
+	}
+	var resp *http.Response
+	if ttl := c.cacheTTL(method, route); ttl > 0 {
+		resp, err = c.cache.cachedGet(ctx, route, headers, ttl, send)
+	} else {
+		resp, err = send()
+	}
+	return releaseOnClose(resp, err, release)
+}
 
-	return c.doRequest(method, fullURL, payload, headers)
+// serviceName extracts the service from an api/vX/service route
+func serviceName(route string) string {
+	parts := strings.Split(strings.TrimPrefix(route, "/"), "/")
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[2]
 }
 
-// buildURL detects service from route and builds full URL
+// buildURL detects service from route and builds full URL on one of its hosts
 func (c *ServiceClient) buildURL(route string) (string, error) {
+	urlFor, err := c.resolve(route)
+	if err != nil {
+		return "", err
+	}
+	return urlFor(), nil
+}
+
+// resolve detects service from route and returns a function building the full URL on the host
+// picked for each call
+func (c *ServiceClient) resolve(route string) (func() string, error) {
 	// Clean route
 	route = strings.TrimPrefix(route, "/")
 	// Route has api/vX/service format - extract service name
 	parts := strings.Split(route, "/")
 	if len(parts) < 3 {
-		return "", fmt.Errorf("invalid API route format: %s", route)
+		return nil, fmt.Errorf("invalid API route format: %s", route)
 	}
 
 	// parts[0] = "api", parts[1] = "v1", parts[2] = service name
 	serviceName := parts[2]
-	host, exists := c.serviceHosts[serviceName]
+	pool, exists := c.pools[serviceName]
 	if !exists {
-		return "", fmt.Errorf("no host configured for service: %s", serviceName)
+		return nil, fmt.Errorf("no host configured for service: %s", serviceName)
 	}
 
 	// Build full URL preserving the API version
-	fullURL := strings.TrimSuffix(host, "/") + "/" + route
-	return fullURL, nil
+	return func() string {
+		return pool.pick(c.balancing).base + "/" + route
+	}, nil
 }
 
-// extractHeaders gets headers from Gin context or standard context
+// extractHeaders gets headers from Gin context or standard context, keyed by canonical name
 func (c *ServiceClient) extractHeaders(ctx context.Context) map[string]string {
 	headers := make(map[string]string)
 
-	// Try Gin context first
-	if ginCtx, ok := ctx.(*gin.Context); ok {
-		if userID := ginCtx.GetHeader("X-User-ID"); userID != "" {
-			headers["X-User-ID"] = userID
-		}
-		if userID, exists := ginCtx.Get("user_id"); exists {
-			if uid, ok := userID.(uint); ok {
-				headers["X-User-ID"] = strconv.FormatUint(uint64(uid), 10)
-			}
-		}
-		if requestID := ginCtx.GetHeader("X-Request-ID"); requestID != "" {
-			headers["X-Request-ID"] = requestID
+	// Headers received from an upstream caller are passed on by the propagation policy, from a
+	// gin context (also when wrapped, e.g. by WithTimeout) or one carrying ctxutil.WithHeaders
+	for name, values := range ctxutil.Headers(ctx) {
+		name = textproto.CanonicalMIMEHeaderKey(name)
+		if len(values) > 0 && values[0] != "" && c.propagation.allows(name) {
+			headers[name] = values[0]
 		}
-		if acceptLang := ginCtx.GetHeader("Accept-Language"); acceptLang != "" {
-			headers["Accept-Language"] = acceptLang
-		}
-		return headers
 	}
 
-	// Try standard context values
-	if userID := ctx.Value("user_id"); userID != nil {
-		if uid, ok := userID.(uint); ok {
-			headers["X-User-ID"] = strconv.FormatUint(uint64(uid), 10)
-		}
+	// Request-scoped values win, whichever form of context carries them
+	if userID, ok := ctxutil.UserID(ctx); ok {
+		headers[userIDHeader] = strconv.FormatUint(userID, 10)
 	}
-	if requestID := ctx.Value("request_id"); requestID != nil {
-		if rid, ok := requestID.(string); ok {
-			headers["X-Request-ID"] = rid
-		}
+	if requestID := ctxutil.RequestID(ctx); requestID != "" {
+		headers[requestIDHeader] = requestID
+	}
+	if tenantID := ctxutil.TenantID(ctx); tenantID != "" {
+		headers[tenantIDHeader] = tenantID
+	}
+	if lang := ctxutil.Lang(ctx); lang != "" && headers["Accept-Language"] == "" {
+		headers["Accept-Language"] = lang
 	}
 
 	return headers
 }
 
-// doRequest is the core method that handles all requests
-func (c *ServiceClient) doRequest(method, url string, payload interface{}, contextHeaders map[string]string) (*http.Response, error) {
+// doRequest is the core method that handles all requests; timeout bounds the attempt until the
+// response body is closed
+func (c *ServiceClient) doRequest(ctx context.Context, method, url string, payload interface{}, contextHeaders map[string]string, timeout time.Duration) (*http.Response, error) {
 	var body []byte
 	var err error
 
@@ -145,8 +421,10 @@ func (c *ServiceClient) doRequest(method, url string, payload interface{}, conte
 	}
 
 	// Create request
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(body))
 	if err != nil {
+		cancel()
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
@@ -154,6 +432,7 @@ func (c *ServiceClient) doRequest(method, url string, payload interface{}, conte
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-Service-ID", c.serviceID)
 	req.Header.Set("X-Service-Secret", c.serviceSecret)
+	req.Header.Set("User-Agent", buildinfo.UserAgent(c.serviceID))
 
 	// Set extracted context headers
 	for key, value := range contextHeaders {
@@ -161,18 +440,31 @@ func (c *ServiceClient) doRequest(method, url string, payload interface{}, conte
 	}
 
 	// Execute request
-	resp, err := c.client.Do(req)
+	start := time.Now()
+	resp, err := c.send(req)
 	if err != nil {
+		if c.logging != nil {
+			c.logging.log(ctx, req, body, nil, nil, time.Since(start), err)
+		}
+		cancel()
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 
 	// Check for error status codes
 	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
+		respBody, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, fmt.Errorf("service returned error [%d]: %s", resp.StatusCode, string(body))
+		cancel()
+		if c.logging != nil {
+			c.logging.log(ctx, req, body, resp, respBody, time.Since(start), nil)
+		}
+		return nil, decodeError(c.serviceOf(req), resp.StatusCode, respBody)
+	}
+	if c.logging != nil {
+		c.logging.log(ctx, req, body, resp, c.logging.capture(resp), time.Since(start), nil)
 	}
 
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
 	return resp, nil
 }
 
@@ -190,6 +482,7 @@ func DecodeStandardResponse(resp *http.Response, dataStruct interface{}) error {
 		Data    json.RawMessage `json:"data"`
 		Message string          `json:"message"`
 		Success bool            `json:"success"`
+		Code    string          `json:"code"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&standardResp); err != nil {
@@ -197,7 +490,11 @@ func DecodeStandardResponse(resp *http.Response, dataStruct interface{}) error {
 	}
 
 	if !standardResp.Success {
-		return fmt.Errorf("service error: %s", standardResp.Message)
+		code := standardResp.Code
+		if code == "" {
+			code = "downstream_error"
+		}
+		return apperror.Newf(code, apperror.KindFromStatus(resp.StatusCode), "service error: %s", standardResp.Message)
 	}
 
 	if dataStruct != nil {
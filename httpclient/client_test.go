@@ -0,0 +1,28 @@
+package httpclient
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appconfig "github.com/Masharah-Advisory/common/config"
+)
+
+// TestServiceClientsValidateEveryHost checks that each client a service builds keeps its hosts
+// in the startup check, instead of the last client replacing the others
+func TestServiceClientsValidateEveryHost(t *testing.T) {
+	NewServiceClient("hosts-test", "secret", ServiceConfig{"users": "users.internal:8080"})
+	NewServiceClient("hosts-test", "secret", ServiceConfig{"orders": "http://orders.internal"})
+
+	report, _ := appconfig.Validate(context.Background())
+	for _, r := range report.Results {
+		if r.Name != "httpclient.hosts-test.hosts" {
+			continue
+		}
+		if r.OK || !strings.Contains(r.Error, "users") {
+			t.Fatalf("check = %+v, want the first client's invalid users host reported", r)
+		}
+		return
+	}
+	t.Fatal("no host check registered for the service")
+}
@@ -0,0 +1,53 @@
+package httpclient
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/ctxutil"
+)
+
+// DefaultHopMargin is kept from the caller's deadline for the network hop and for the caller
+// to handle the answer, unless WithHopMargin says otherwise
+const DefaultHopMargin = 50 * time.Millisecond
+
+// ErrDeadlineExceeded is returned without calling the service when the caller's deadline leaves
+// no time for the call
+var ErrDeadlineExceeded = apperror.New("deadline_exceeded", apperror.KindTimeout, "request deadline exceeded")
+
+// WithHopMargin replaces DefaultHopMargin for this client
+func (c *ServiceClient) WithHopMargin(d time.Duration) *ServiceClient {
+	c.hopMargin = d
+	return c
+}
+
+// withBudget bounds a call by the caller's deadline, the earlier of ctxutil.Deadline (set by
+// middleware.DeadlineMiddleware) and the deadline of ctx, less the hop margin. Attempts and
+// retries stop at it, and the service receives what is left in X-Request-Deadline. release is
+// extended to free the bounded context, or run right away on error.
+func (c *ServiceClient) withBudget(ctx context.Context, headers map[string]string, release context.CancelFunc) (context.Context, context.CancelFunc, error) {
+	deadline, ok := ctxutil.Deadline(ctx)
+	if d, has := ctx.Deadline(); has && (!ok || d.Before(deadline)) {
+		deadline, ok = d, true
+	}
+	if !ok {
+		return ctx, release, nil
+	}
+	margin := c.hopMargin
+	if margin <= 0 {
+		margin = DefaultHopMargin
+	}
+	deadline = deadline.Add(-margin)
+	if !deadline.After(time.Now()) {
+		release()
+		return nil, nil, ErrDeadlineExceeded
+	}
+	headers[ctxutil.DeadlineHeader] = strconv.FormatInt(deadline.UnixMilli(), 10)
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	return ctx, func() {
+		cancel()
+		release()
+	}, nil
+}
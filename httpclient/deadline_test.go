@@ -0,0 +1,70 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/gin-gonic/gin"
+)
+
+// TestDeadlineFromWrappedGinContext checks that a deadline set on a context wrapping the gin
+// context reaches the service, on every kind of call
+func TestDeadlineFromWrappedGinContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	got := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got <- r.Header.Get(ctxutil.DeadlineHeader)
+	}))
+	defer srv.Close()
+	client := NewServiceClient("test", "secret", ServiceConfig{"users": srv.URL})
+
+	calls := map[string]func(ctx context.Context) error{
+		"get": func(ctx context.Context) error {
+			resp, err := client.Get(ctx, "/api/v1/users/1")
+			if err == nil {
+				resp.Body.Close()
+			}
+			return err
+		},
+		"stream": func(ctx context.Context) error {
+			s, err := client.GetStream(ctx, "/api/v1/users/1")
+			if err == nil {
+				s.Body.Close()
+			}
+			return err
+		},
+		"probe": func(ctx context.Context) error {
+			resp, err := client.Probe(ctx, "users", "/health")
+			if err == nil {
+				resp.Body.Close()
+			}
+			return err
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			ctx, cancel := context.WithTimeout(c, 2*time.Second)
+			defer cancel()
+			want, _ := ctx.Deadline()
+
+			if err := call(ctx); err != nil {
+				t.Fatalf("call failed: %v", err)
+			}
+			header := <-got
+			ms, err := strconv.ParseInt(header, 10, 64)
+			if err != nil {
+				t.Fatalf("%s = %q, want the caller's deadline", ctxutil.DeadlineHeader, header)
+			}
+			if sent := time.UnixMilli(ms); sent.After(want) || want.Sub(sent) > DefaultHopMargin+time.Millisecond {
+				t.Errorf("%s = %v, want %v less the hop margin", ctxutil.DeadlineHeader, sent, want)
+			}
+		})
+	}
+}
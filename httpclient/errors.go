@@ -0,0 +1,101 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Masharah-Advisory/common/apperror"
+)
+
+// ServiceError is the error of a call a downstream service answered with a 4xx or 5xx status,
+// e.g. to tell a missing user from an outage:
+//
+//	var se *httpclient.ServiceError
+//	if errors.As(err, &se) && se.StatusCode == http.StatusNotFound {
+//		return ErrUserNotFound
+//	}
+//
+// It unwraps to an *apperror.Error of the matching kind carrying the downstream code and
+// message, so response.HandleError answers a downstream 404 or 403 with the same status, and
+// retries and breakers classify it as before.
+type ServiceError struct {
+	// Service is the service that answered, as named in ServiceConfig
+	Service    string
+	StatusCode int
+	// Code and Message come from the standard envelope; Code is "downstream_error" without one
+	Code    string
+	Message string
+	// Body is the raw response body
+	Body []byte
+
+	err *apperror.Error
+}
+
+// Error implements the error interface
+func (e *ServiceError) Error() string {
+	if e.Service == "" {
+		return fmt.Sprintf("service returned error [%d]: %s", e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("service %s returned error [%d]: %s", e.Service, e.StatusCode, e.Body)
+}
+
+// Unwrap returns the application error the failure maps to
+func (e *ServiceError) Unwrap() error {
+	return e.err
+}
+
+// Retryable reports whether the same call may succeed later: timeouts, rate limits and server
+// errors other than 501 Not Implemented
+func (e *ServiceError) Retryable() bool {
+	switch {
+	case e.StatusCode == http.StatusRequestTimeout, e.StatusCode == http.StatusTooManyRequests:
+		return true
+	case e.StatusCode == http.StatusNotImplemented:
+		return false
+	default:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+}
+
+// AsServiceError returns the first *ServiceError in err's chain
+func AsServiceError(err error) (*ServiceError, bool) {
+	var e *ServiceError
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// NewServiceError builds the error a ServiceClient returns when service answers with status
+// and body, e.g. for fakes standing in for a client
+func NewServiceError(service string, statusCode int, body []byte) *ServiceError {
+	var envelope struct {
+		Message string `json:"message"`
+		Code    string `json:"code"`
+	}
+	_ = json.Unmarshal(body, &envelope)
+
+	code := envelope.Code
+	if code == "" {
+		code = "downstream_error"
+	}
+	return &ServiceError{
+		Service:    service,
+		StatusCode: statusCode,
+		Code:       code,
+		Message:    envelope.Message,
+		Body:       body,
+		err: apperror.New(code, apperror.KindFromStatus(statusCode), envelope.Message).
+			WithMeta("status", statusCode).
+			WithMeta("service", service).
+			WithMeta("downstream_message", envelope.Message),
+	}
+}
+
+// decodeError converts a downstream error response into a ServiceError, keeping the downstream
+// code and message when the body uses the standard envelope
+func decodeError(service string, statusCode int, body []byte) error {
+	return NewServiceError(service, statusCode, body)
+}
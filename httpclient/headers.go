@@ -0,0 +1,78 @@
+package httpclient
+
+import (
+	"net/textproto"
+	"strings"
+
+	"github.com/Masharah-Advisory/common/utils"
+)
+
+// DefaultPropagatedHeaders are the incoming headers passed on to downstream services unless
+// WithHeaderPropagation says otherwise
+var DefaultPropagatedHeaders = []string{utils.XUserIDHeader, "X-Request-ID", "Accept-Language"}
+
+// Canonical names of the request-scoped headers, as extractHeaders keys them
+var (
+	userIDHeader    = textproto.CanonicalMIMEHeaderKey(utils.XUserIDHeader)
+	requestIDHeader = textproto.CanonicalMIMEHeaderKey("X-Request-ID")
+	tenantIDHeader  = textproto.CanonicalMIMEHeaderKey("X-Tenant-ID")
+)
+
+// reservedHeaders are set by the client itself and never taken from the incoming request
+var reservedHeaders = map[string]bool{
+	"Content-Type":       true,
+	"Content-Length":     true,
+	"Host":               true,
+	"User-Agent":         true,
+	"X-Service-Id":       true,
+	"X-Service-Secret":   true,
+	"X-Request-Deadline": true,
+	"Idempotency-Key":    true,
+}
+
+// headerPolicy is the allowlist of propagated headers
+type headerPolicy struct {
+	names    map[string]bool
+	prefixes []string
+}
+
+// WithHeaderPropagation replaces the headers passed on from the incoming request with patterns:
+// exact names, or prefixes ending in "*", e.g.
+//
+//	client.WithHeaderPropagation(append(httpclient.DefaultPropagatedHeaders, "X-Correlation-ID", "X-Tenant-*")...)
+//
+// Names are case-insensitive. Headers come from the request of a gin context or from
+// ctxutil.WithHeaders, so the same calls work from handlers, goroutines and consumers. The
+// user, request and tenant IDs and the language on the context are sent regardless.
+func (c *ServiceClient) WithHeaderPropagation(patterns ...string) *ServiceClient {
+	c.propagation = newHeaderPolicy(patterns)
+	return c
+}
+
+func newHeaderPolicy(patterns []string) *headerPolicy {
+	p := &headerPolicy{names: make(map[string]bool)}
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			p.prefixes = append(p.prefixes, textproto.CanonicalMIMEHeaderKey(prefix))
+			continue
+		}
+		p.names[textproto.CanonicalMIMEHeaderKey(pattern)] = true
+	}
+	return p
+}
+
+// allows reports whether the canonical header name is propagated
+func (p *headerPolicy) allows(name string) bool {
+	if reservedHeaders[name] {
+		return false
+	}
+	if p.names[name] {
+		return true
+	}
+	for _, prefix := range p.prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+)
+
+// hedging is the per-call setting of WithHedging
+type hedging struct {
+	delay    time.Duration
+	maxExtra int
+}
+
+// WithHedging sends up to maxExtra duplicates of the call, one each time delay passes without
+// a response, and uses whichever response arrives first; the others are cancelled. Duplicates
+// go to the next host when the service has several. Use it for latency-critical calls that are
+// safe to repeat, e.g. permission checks, with a delay around the service's p95 latency.
+func WithHedging(delay time.Duration, maxExtra int) RequestOption {
+	return func(o *requestOptions) {
+		if delay > 0 && maxExtra > 0 {
+			o.hedge = &hedging{delay: delay, maxExtra: maxExtra}
+		}
+	}
+}
+
+// hedgingOf returns the hedging requested by opts, nil when there is none
+func hedgingOf(opts []RequestOption) *hedging {
+	var o requestOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o.hedge
+}
+
+type hedgeResult struct {
+	resp   *http.Response
+	err    error
+	cancel context.CancelFunc
+	// i is the position of the call among the duplicates
+	i int
+}
+
+// doHedged runs doRequest with duplicates per h. A 4xx answer is final like a success; network
+// errors and 5xx start the next duplicate right away.
+func (c *ServiceClient) doHedged(ctx context.Context, h *hedging, method string, urlFor func() string, payload interface{}, headers map[string]string, timeout time.Duration) (*http.Response, error) {
+	results := make(chan hedgeResult, 1+h.maxExtra)
+	var cancels []context.CancelFunc
+	launch := func() {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		cancels = append(cancels, cancel)
+		i := len(cancels) - 1
+		go func() {
+			resp, err := c.doRequest(attemptCtx, method, urlFor(), payload, headers, timeout)
+			results <- hedgeResult{resp: resp, err: err, cancel: cancel, i: i}
+		}()
+	}
+	launch()
+	inflight := 1
+	timer := time.NewTimer(h.delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if len(cancels) <= h.maxExtra {
+				launch()
+				inflight++
+				timer.Reset(h.delay)
+			}
+		case r := <-results:
+			inflight--
+			if r.err == nil || final(r.err) {
+				// The losers are cancelled now, the winner once its body is closed
+				for i, cancel := range cancels {
+					if i != r.i {
+						cancel()
+					}
+				}
+				go drainHedges(results, inflight)
+				if r.err != nil {
+					r.cancel()
+					return nil, r.err
+				}
+				r.resp.Body = &cancelOnClose{ReadCloser: r.resp.Body, cancel: r.cancel}
+				return r.resp, nil
+			}
+			r.cancel()
+			if inflight == 0 {
+				if len(cancels) > h.maxExtra || ctx.Err() != nil {
+					return nil, r.err
+				}
+				launch()
+				inflight++
+				timer.Reset(h.delay)
+			}
+		}
+	}
+}
+
+// final reports whether err is a downstream answer a duplicate would only repeat
+func final(err error) bool {
+	e, ok := apperror.As(err)
+	return ok && e.HTTPStatus() < http.StatusInternalServerError
+}
+
+// drainHedges closes the responses of the duplicates still running once a winner is chosen
+func drainHedges(results <-chan hedgeResult, inflight int) {
+	for ; inflight > 0; inflight-- {
+		if r := <-results; r.resp != nil {
+			r.resp.Body.Close()
+		}
+	}
+}
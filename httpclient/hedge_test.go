@@ -0,0 +1,112 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHedging(t *testing.T) {
+	tests := []struct {
+		name string
+		// answer handles the i-th call, counting from 0
+		answer    func(i int, w http.ResponseWriter, r *http.Request)
+		wantBody  string
+		wantErr   bool
+		wantCalls int
+		maxTime   time.Duration
+	}{
+		{
+			name: "duplicate wins over a slow call",
+			answer: func(i int, w http.ResponseWriter, r *http.Request) {
+				if i == 0 {
+					select {
+					case <-r.Context().Done():
+					case <-time.After(2 * time.Second):
+					}
+					return
+				}
+				io.WriteString(w, "fast")
+			},
+			wantBody:  "fast",
+			wantCalls: 2,
+			maxTime:   time.Second,
+		},
+		{
+			name: "4xx is final",
+			answer: func(i int, w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			wantErr:   true,
+			wantCalls: 1,
+		},
+		{
+			name: "5xx starts the next duplicate at once",
+			answer: func(i int, w http.ResponseWriter, r *http.Request) {
+				if i == 0 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				io.WriteString(w, "second")
+			},
+			wantBody:  "second",
+			wantCalls: 2,
+			maxTime:   time.Second,
+		},
+		{
+			name: "duplicates are bounded",
+			answer: func(i int, w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			wantErr:   true,
+			wantCalls: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			calls := 0
+			keys := make(map[string]bool)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				i := calls
+				calls++
+				keys[r.Header.Get(IdempotencyKeyHeader)] = true
+				mu.Unlock()
+				tt.answer(i, w, r)
+			}))
+			defer srv.Close()
+			client := NewServiceClient("test", "secret", ServiceConfig{"users": srv.URL})
+
+			start := time.Now()
+			resp, err := client.Post(context.Background(), "/api/v1/users/check", nil, WithHedging(100*time.Millisecond, 2))
+			elapsed := time.Since(start)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Post = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil {
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if string(body) != tt.wantBody {
+					t.Errorf("body = %q, want %q", body, tt.wantBody)
+				}
+			}
+			if tt.maxTime > 0 && elapsed > tt.maxTime {
+				t.Errorf("took %v, want under %v", elapsed, tt.maxTime)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if calls != tt.wantCalls {
+				t.Errorf("service called %d times, want %d", calls, tt.wantCalls)
+			}
+			if len(keys) != 1 || keys[""] {
+				t.Errorf("duplicates sent idempotency keys %v, want one shared key", keys)
+			}
+		})
+	}
+}
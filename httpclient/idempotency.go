@@ -0,0 +1,39 @@
+package httpclient
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKeyHeader carries the key that lets a service recognise repeats of a write
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// WithIdempotencyKey sends key as the call's Idempotency-Key, e.g. a saga step's
+// State.IdempotencyKey(), so repeats of the call by the caller itself are recognised too;
+// without it, writes that may be retried or hedged get a generated key
+func WithIdempotencyKey(key string) RequestOption {
+	return func(o *requestOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// idempotencyKey returns the key a call sends: the one given with WithIdempotencyKey, or a new
+// one shared by all attempts of a write that may be sent more than once
+func idempotencyKey(method string, repeated bool, opts []RequestOption) string {
+	var o requestOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.idempotencyKey != "" {
+		return o.idempotencyKey
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ""
+	}
+	if !repeated {
+		return ""
+	}
+	return uuid.NewString()
+}
@@ -0,0 +1,44 @@
+package httpclient
+
+import (
+	"net/http"
+	"time"
+)
+
+// RoundTripFunc sends a request and returns its response
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Interceptor wraps every outbound request of a client: it may change the request, call next
+// (or not) and inspect or replace the response, e.g.
+//
+//	client.Use(func(req *http.Request, next httpclient.RoundTripFunc) (*http.Response, error) {
+//		req.Header.Set("X-Channel", "back-office")
+//		return next(req)
+//	})
+type Interceptor func(req *http.Request, next RoundTripFunc) (*http.Response, error)
+
+// Use appends interceptors; they run in the order added, once per attempt, after the service
+// and context headers are set
+func (c *ServiceClient) Use(interceptors ...Interceptor) *ServiceClient {
+	c.interceptors = append(c.interceptors, interceptors...)
+	return c
+}
+
+// send runs req through the interceptors and the HTTP client, recording the outcome in the
+// health of the host and the metrics
+func (c *ServiceClient) send(req *http.Request) (*http.Response, error) {
+	next := RoundTripFunc(c.client.Do)
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := c.interceptors[i], next
+		next = func(req *http.Request) (*http.Response, error) {
+			return interceptor(req, inner)
+		}
+	}
+	start := time.Now()
+	resp, err := next(req)
+	c.observe(req, resp, err)
+	if c.metrics != nil {
+		c.metrics.record(c.serviceOf(req), req, resp, err, time.Since(start))
+	}
+	return resp, err
+}
@@ -0,0 +1,227 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"go.uber.org/zap"
+)
+
+// Redacted replaces secrets in logged exchanges
+const Redacted = "[REDACTED]"
+
+// DefaultRedactHeaders are never logged in clear
+var DefaultRedactHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"Proxy-Authorization",
+	"X-Api-Key",
+	"X-Service-Secret",
+}
+
+// DefaultRedactFields are JSON body fields and query parameters never logged in clear,
+// matched case-insensitively
+var DefaultRedactFields = []string{
+	"password", "current_password", "new_password", "token", "access_token", "refresh_token",
+	"secret", "client_secret", "otp", "pin", "cvv", "card_number",
+}
+
+// Exchange is one logged outbound call, with secrets already redacted
+type Exchange struct {
+	Method         string
+	URL            string
+	Status         int // 0 when no response was received
+	Duration       time.Duration
+	RequestHeader  http.Header
+	RequestBody    string
+	ResponseHeader http.Header
+	ResponseBody   string
+	Err            error
+}
+
+// LogConfig configures outbound request logging
+type LogConfig struct {
+	// Logger defaults to the "httpclient" module logger
+	Logger *zap.Logger
+	// Bodies logs request and response bodies, truncated to MaxBody bytes
+	Bodies  bool
+	MaxBody int // defaults to 2048
+	// OnlyFailures skips successful calls faster than SlowThreshold
+	OnlyFailures  bool
+	SlowThreshold time.Duration
+	// RedactHeaders and RedactFields extend DefaultRedactHeaders and DefaultRedactFields
+	RedactHeaders []string
+	RedactFields  []string
+	// Hook receives every exchange, logged or not, e.g. to keep the last failures in memory
+	Hook func(ctx context.Context, ex Exchange)
+}
+
+// requestLogger records exchanges for a client configured with WithLogging
+type requestLogger struct {
+	cfg     LogConfig
+	headers map[string]bool
+	fields  map[string]bool
+	// fieldPattern redacts string fields of JSON bodies too long to parse after truncation
+	fieldPattern *regexp.Regexp
+}
+
+// WithLogging logs the method, URL, status and latency of every call, and optionally truncated
+// bodies, with secrets such as X-Service-Secret and Authorization redacted
+func (c *ServiceClient) WithLogging(cfg LogConfig) *ServiceClient {
+	if cfg.Logger == nil {
+		cfg.Logger = logger.Module("httpclient")
+	}
+	if cfg.MaxBody <= 0 {
+		cfg.MaxBody = 2048
+	}
+	l := &requestLogger{cfg: cfg, headers: make(map[string]bool), fields: make(map[string]bool)}
+	for _, h := range append(append([]string{}, DefaultRedactHeaders...), cfg.RedactHeaders...) {
+		l.headers[http.CanonicalHeaderKey(h)] = true
+	}
+	quoted := make([]string, 0, len(DefaultRedactFields)+len(cfg.RedactFields))
+	for _, f := range append(append([]string{}, DefaultRedactFields...), cfg.RedactFields...) {
+		l.fields[strings.ToLower(f)] = true
+		quoted = append(quoted, regexp.QuoteMeta(f))
+	}
+	l.fieldPattern = regexp.MustCompile(`(?i)("(?:` + strings.Join(quoted, "|") + `)"\s*:\s*)"(?:[^"\\]|\\.)*"?`)
+	c.logging = l
+	return c
+}
+
+// capture reads up to MaxBody bytes of a response body for logging and puts them back in front
+// of the rest, so the caller still reads the whole body
+func (l *requestLogger) capture(resp *http.Response) []byte {
+	if !l.cfg.Bodies || resp.Body == nil {
+		return nil
+	}
+	head, _ := io.ReadAll(io.LimitReader(resp.Body, int64(l.cfg.MaxBody)+1))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+	return head
+}
+
+// log records one exchange
+func (l *requestLogger) log(ctx context.Context, req *http.Request, reqBody []byte, resp *http.Response, respBody []byte, duration time.Duration, err error) {
+	ex := Exchange{
+		Method:        req.Method,
+		URL:           l.url(req.URL),
+		Duration:      duration,
+		RequestHeader: l.header(req.Header),
+		Err:           err,
+	}
+	if resp != nil {
+		ex.Status = resp.StatusCode
+		ex.ResponseHeader = l.header(resp.Header)
+	}
+	if l.cfg.Bodies {
+		ex.RequestBody = l.body(reqBody)
+		ex.ResponseBody = l.body(respBody)
+	}
+	if l.cfg.Hook != nil {
+		l.cfg.Hook(ctx, ex)
+	}
+
+	failed := err != nil || ex.Status >= 400
+	slow := l.cfg.SlowThreshold > 0 && duration >= l.cfg.SlowThreshold
+	if l.cfg.OnlyFailures && !failed && !slow {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.String("method", ex.Method),
+		zap.String("url", ex.URL),
+		zap.Int("status", ex.Status),
+		zap.Duration("duration", duration),
+	}
+	if l.cfg.Bodies {
+		fields = append(fields, zap.String("request_body", ex.RequestBody), zap.String("response_body", ex.ResponseBody))
+	}
+	if requestID := req.Header.Get("X-Request-ID"); requestID != "" {
+		fields = append(fields, zap.String("request_id", requestID))
+	}
+	log := l.cfg.Logger
+	switch {
+	case err != nil:
+		log.Warn("service call failed", append(fields, zap.Error(err))...)
+	case ex.Status >= 500:
+		log.Warn("service call failed", fields...)
+	case failed, slow:
+		log.Info("service call", fields...)
+	default:
+		log.Debug("service call", fields...)
+	}
+}
+
+func (l *requestLogger) header(h http.Header) http.Header {
+	out := h.Clone()
+	for key := range out {
+		if l.headers[http.CanonicalHeaderKey(key)] {
+			out[key] = []string{Redacted}
+		}
+	}
+	return out
+}
+
+func (l *requestLogger) url(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.String()
+	}
+	q := u.Query()
+	for key := range q {
+		if l.fields[strings.ToLower(key)] {
+			q[key] = []string{Redacted}
+		}
+	}
+	clean := *u
+	clean.RawQuery = q.Encode()
+	return clean.String()
+}
+
+// body redacts JSON fields, including in bodies cut short by capture, and truncates to MaxBody
+// bytes
+func (l *requestLogger) body(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err == nil {
+		if redacted, err := json.Marshal(l.value(v)); err == nil {
+			b = redacted
+		}
+	} else {
+		b = l.fieldPattern.ReplaceAll(b, []byte(`${1}"`+Redacted+`"`))
+	}
+	if len(b) > l.cfg.MaxBody {
+		return string(b[:l.cfg.MaxBody]) + "...(truncated)"
+	}
+	return string(b)
+}
+
+func (l *requestLogger) value(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for key, inner := range t {
+			if l.fields[strings.ToLower(key)] {
+				t[key] = Redacted
+				continue
+			}
+			t[key] = l.value(inner)
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = l.value(t[i])
+		}
+	}
+	return v
+}
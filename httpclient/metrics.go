@@ -0,0 +1,75 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// clientMetrics are the collectors of WithMetrics
+type clientMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+// WithMetrics records every downstream attempt on reg, e.g. prometheus.DefaultRegisterer:
+// httpclient_requests_total, httpclient_request_duration_seconds and httpclient_errors_total,
+// labelled by target service, method and status. Status is the HTTP code, or "error",
+// "timeout" or "canceled" when no response came back; errors are those and 5xx responses.
+// Clients sharing a registerer share the collectors.
+func (c *ServiceClient) WithMetrics(reg prometheus.Registerer) *ServiceClient {
+	labels := []string{"service", "method", "status"}
+	c.metrics = &clientMetrics{
+		requests: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "httpclient_requests_total",
+			Help: "Requests sent to other services, by service, method and status.",
+		}, labels)),
+		duration: register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "httpclient_request_duration_seconds",
+			Help:    "Duration of requests sent to other services, until the response headers arrived.",
+			Buckets: prometheus.DefBuckets,
+		}, labels)),
+		errors: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "httpclient_errors_total",
+			Help: "Requests to other services that failed or got a 5xx response, by service, method and status.",
+		}, labels)),
+	}
+	return c
+}
+
+// register registers collector on reg, or returns the collector already registered there
+func register[T prometheus.Collector](reg prometheus.Registerer, collector T) T {
+	if err := reg.Register(collector); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if errors.As(err, &already) {
+			if existing, ok := already.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+		panic("httpclient: " + err.Error())
+	}
+	return collector
+}
+
+// record counts one attempt of req
+func (m *clientMetrics) record(service string, req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+	status := "error"
+	switch {
+	case err == nil && resp != nil:
+		status = strconv.Itoa(resp.StatusCode)
+	case errors.Is(err, context.DeadlineExceeded):
+		status = "timeout"
+	case errors.Is(err, context.Canceled):
+		status = "canceled"
+	}
+	m.requests.WithLabelValues(service, req.Method, status).Inc()
+	m.duration.WithLabelValues(service, req.Method, status).Observe(elapsed.Seconds())
+	if err != nil || resp == nil || resp.StatusCode >= http.StatusInternalServerError {
+		m.errors.WithLabelValues(service, req.Method, status).Inc()
+	}
+}
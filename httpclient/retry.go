@@ -0,0 +1,110 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/retry"
+	"go.uber.org/zap"
+)
+
+// DefaultRetryStatuses are the downstream statuses retried when RetryConfig.Statuses is empty
+var DefaultRetryStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryMethods are the idempotent methods retried when RetryConfig.Methods is empty
+var DefaultRetryMethods = []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions}
+
+// RetryConfig controls automatic retries of service calls. Network errors and the listed
+// statuses are retried with exponential backoff; no wait runs past the request context's
+// deadline, the last error is returned instead.
+type RetryConfig struct {
+	MaxAttempts int           // total attempts including the first, defaults to 3
+	Backoff     time.Duration // wait before the first retry, doubling after each, defaults to 100ms
+	MaxBackoff  time.Duration // cap on a single wait, defaults to 5s
+	Jitter      float64       // randomises each wait by ±Jitter, defaults to 0.2; negative disables
+	// Statuses are the retried response statuses, defaults to DefaultRetryStatuses
+	Statuses []int
+	// Methods are the retried methods, defaults to DefaultRetryMethods; add POST only for
+	// endpoints that are safe to call twice or honour the Idempotency-Key retried writes carry
+	Methods []string
+}
+
+// WithRetryConfig enables automatic retries as configured
+func (c *ServiceClient) WithRetryConfig(cfg RetryConfig) *ServiceClient {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = 100 * time.Millisecond
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 5 * time.Second
+	}
+	if cfg.Jitter == 0 {
+		cfg.Jitter = 0.2
+	}
+	if len(cfg.Statuses) == 0 {
+		cfg.Statuses = DefaultRetryStatuses
+	}
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = DefaultRetryMethods
+	}
+
+	statuses := make(map[int]bool, len(cfg.Statuses))
+	for _, s := range cfg.Statuses {
+		statuses[s] = true
+	}
+	log := logger.Module("httpclient")
+	c.retry = &retry.Policy{
+		MaxAttempts:    cfg.MaxAttempts,
+		InitialBackoff: cfg.Backoff,
+		MaxBackoff:     cfg.MaxBackoff,
+		Jitter:         cfg.Jitter,
+		Retryable:      func(err error) bool { return retryableStatus(err, statuses) },
+		OnRetry: func(attempt int, err error, wait time.Duration) {
+			log.Warn("service call failed, retrying",
+				zap.Int("attempt", attempt),
+				zap.Duration("wait", wait),
+				zap.Error(err),
+			)
+		},
+	}
+	c.retryMethods = make(map[string]bool, len(cfg.Methods))
+	for _, m := range cfg.Methods {
+		c.retryMethods[strings.ToUpper(m)] = true
+	}
+	return c
+}
+
+// retryableStatus retries network errors and downstream errors with one of the statuses
+func retryableStatus(err error, statuses map[int]bool) bool {
+	if err == nil || retry.IsPermanent(err) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if se, ok := AsServiceError(err); ok {
+		return statuses[se.StatusCode]
+	}
+	_, ok := apperror.As(err)
+	return !ok
+}
+
+// retries reports whether requests with method are retried
+func (c *ServiceClient) retries(method string) bool {
+	if c.retry == nil {
+		return false
+	}
+	if c.retryMethods == nil {
+		return method != http.MethodPost && method != http.MethodPatch
+	}
+	return c.retryMethods[method]
+}
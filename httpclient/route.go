@@ -0,0 +1,184 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// RequestOption adjusts the route of a single request, e.g.
+//
+//	client.Get(ctx, "/api/v1/users/:id", httpclient.WithPathParam("id", id), httpclient.WithQuery("status", "active"))
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	path  map[string]string
+	query url.Values
+	hedge *hedging
+
+	idempotencyKey string
+}
+
+// WithPathParam substitutes the :name (or {name}) segment of the route with value, escaped
+func WithPathParam(name string, value interface{}) RequestOption {
+	return func(o *requestOptions) {
+		if o.path == nil {
+			o.path = make(map[string]string)
+		}
+		o.path[name] = fmt.Sprint(value)
+	}
+}
+
+// WithPathParams substitutes several route segments, see WithPathParam
+func WithPathParams(params map[string]string) RequestOption {
+	return func(o *requestOptions) {
+		for name, value := range params {
+			WithPathParam(name, value)(o)
+		}
+	}
+}
+
+// WithQuery adds query parameters; several values repeat the key (?tag=a&tag=b)
+func WithQuery(key string, values ...interface{}) RequestOption {
+	return func(o *requestOptions) {
+		if o.query == nil {
+			o.query = make(url.Values)
+		}
+		for _, v := range values {
+			o.query.Add(key, fmt.Sprint(v))
+		}
+	}
+}
+
+// WithQueryValues adds all parameters of q
+func WithQueryValues(q url.Values) RequestOption {
+	return func(o *requestOptions) {
+		for key, values := range q {
+			for _, v := range values {
+				WithQuery(key, v)(o)
+			}
+		}
+	}
+}
+
+// WithQueryStruct adds the fields of a struct with `form` tags, the query structs handlers bind
+// with ShouldBindQuery; zero fields are left out, slices repeat the key and times are RFC 3339
+func WithQueryStruct(v interface{}) RequestOption {
+	return func(o *requestOptions) {
+		if o.query == nil {
+			o.query = make(url.Values)
+		}
+		addFormFields(o.query, reflect.ValueOf(v))
+	}
+}
+
+func addFormFields(q url.Values, v reflect.Value) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		field := v.Field(i)
+		if f.Anonymous && field.Kind() == reflect.Struct {
+			addFormFields(q, field)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("form"), ",")
+		if name == "" || name == "-" || field.IsZero() {
+			continue
+		}
+		for field.Kind() == reflect.Ptr {
+			field = field.Elem()
+		}
+		if field.Kind() == reflect.Slice || field.Kind() == reflect.Array {
+			for j := 0; j < field.Len(); j++ {
+				q.Add(name, formValue(field.Index(j)))
+			}
+			continue
+		}
+		q.Add(name, formValue(field))
+	}
+}
+
+func formValue(v reflect.Value) string {
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// BuildRoute applies options to route: path parameters are substituted and escaped, query
+// parameters encoded and merged with any query already in route. Placeholders left without a
+// value and parameters matching no placeholder are errors, so typos do not reach the service.
+func BuildRoute(route string, opts ...RequestOption) (string, error) {
+	if len(opts) == 0 {
+		return route, nil
+	}
+	var o requestOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	path, rawQuery, _ := strings.Cut(route, "?")
+	segments := strings.Split(path, "/")
+	used := make(map[string]bool, len(o.path))
+	for i, seg := range segments {
+		name, ok := placeholder(seg)
+		if !ok {
+			continue
+		}
+		value, ok := o.path[name]
+		if !ok {
+			return "", fmt.Errorf("missing value for path parameter %q in route %s", name, route)
+		}
+		if value == "" {
+			return "", fmt.Errorf("empty value for path parameter %q in route %s", name, route)
+		}
+		segments[i] = url.PathEscape(value)
+		used[name] = true
+	}
+	for name := range o.path {
+		if !used[name] {
+			return "", fmt.Errorf("route %s has no path parameter %q", route, name)
+		}
+	}
+	path = strings.Join(segments, "/")
+
+	if len(o.query) > 0 {
+		q, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			return "", fmt.Errorf("invalid query in route %s: %w", route, err)
+		}
+		for key, values := range o.query {
+			q[key] = append(q[key], values...)
+		}
+		rawQuery = q.Encode()
+	}
+	if rawQuery == "" {
+		return path, nil
+	}
+	return path + "?" + rawQuery, nil
+}
+
+// placeholder returns the parameter name of a :name or {name} segment
+func placeholder(seg string) (string, bool) {
+	if name, ok := strings.CutPrefix(seg, ":"); ok && name != "" {
+		return name, true
+	}
+	if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") && len(seg) > 2 {
+		return seg[1 : len(seg)-1], true
+	}
+	return "", false
+}
@@ -0,0 +1,168 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Masharah-Advisory/common/breaker"
+	"github.com/Masharah-Advisory/common/buildinfo"
+	"github.com/gin-gonic/gin"
+)
+
+// maxStreamErrorBody bounds how much of an error response Err reads
+const maxStreamErrorBody = 64 << 10
+
+// proxiedHeaders are copied from a downstream response to the client by Proxy and ProxyResponse
+var proxiedHeaders = []string{
+	"Content-Type",
+	"Content-Length",
+	"Content-Disposition",
+	"Content-Encoding",
+	"Content-Range",
+	"Accept-Ranges",
+	"Cache-Control",
+	"ETag",
+	"Last-Modified",
+}
+
+// Stream is a response body read straight from the connection, e.g. a large PDF or CSV export.
+// Close it once done.
+type Stream struct {
+	Body          io.ReadCloser
+	StatusCode    int
+	Header        http.Header
+	ContentLength int64 // -1 when unknown
+
+	service string
+}
+
+// GetStream GETs route and returns the body unread, whatever the status, so large downloads
+// are never buffered and error bodies are not consumed. The client timeout only bounds the
+// wait for the response headers; reading the body is bounded by ctx and the request deadline,
+// see withBudget. Streams are not retried.
+func (c *ServiceClient) GetStream(ctx context.Context, route string, opts ...RequestOption) (*Stream, error) {
+	route, err := BuildRoute(route, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build route: %w", err)
+	}
+	fullURL, err := c.buildURL(route)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build URL: %w", err)
+	}
+	service := serviceName(route)
+	headers := c.extractHeaders(ctx)
+	reqCtx, release := outboundContext(ctx)
+	reqCtx, release, err = c.withBudget(reqCtx, headers, release)
+	if err != nil {
+		return nil, err
+	}
+
+	var done func(success bool)
+	if c.breakers {
+		if done, err = c.breakerFor(service).Allow(); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	reqCtx, cancelAttempt := context.WithCancel(reqCtx)
+	timer := time.AfterFunc(c.timeoutFor(ctx, service), cancelAttempt)
+	cancel := func() {
+		cancelAttempt()
+		release()
+	}
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, fullURL, nil)
+	if err != nil {
+		timer.Stop()
+		cancel()
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Service-ID", c.serviceID)
+	req.Header.Set("X-Service-Secret", c.serviceSecret)
+	req.Header.Set("User-Agent", buildinfo.UserAgent(c.serviceID))
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	start := time.Now()
+	resp, err := c.send(req)
+	// Headers are in; from here the caller's context alone bounds the body
+	timedOut := !timer.Stop()
+	if done != nil {
+		done(breaker.IsSuccessful(streamOutcome(service, resp, err, timedOut)))
+	}
+	if c.logging != nil {
+		c.logging.log(ctx, req, nil, resp, nil, time.Since(start), err)
+	}
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	return &Stream{
+		Body:          &cancelOnClose{ReadCloser: resp.Body, cancel: cancel},
+		StatusCode:    resp.StatusCode,
+		Header:        resp.Header,
+		ContentLength: resp.ContentLength,
+		service:       service,
+	}, nil
+}
+
+// streamOutcome is the error the breaker judges a stream by, as it judges other calls: the
+// attempt timeout and 5xx responses count against the service, a caller going away does not
+func streamOutcome(service string, resp *http.Response, err error, timedOut bool) error {
+	switch {
+	case err != nil && timedOut:
+		return context.DeadlineExceeded
+	case err != nil:
+		return err
+	case resp.StatusCode >= 500:
+		return NewServiceError(service, resp.StatusCode, nil)
+	}
+	return nil
+}
+
+// Err returns nil for successful responses; for a 4xx/5xx it reads the body (up to 64KB),
+// closes the stream and returns the same application error Get would
+func (s *Stream) Err() error {
+	if s.StatusCode < 400 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(s.Body, maxStreamErrorBody))
+	s.Body.Close()
+	return decodeError(s.service, s.StatusCode, body)
+}
+
+// Close releases the connection
+func (s *Stream) Close() error {
+	return s.Body.Close()
+}
+
+// Proxy writes the stream to c with its status and content headers, then closes it; error
+// responses are forwarded as they are
+func (s *Stream) Proxy(c *gin.Context) {
+	defer s.Close()
+	proxy(c, s.StatusCode, s.Header, s.Body)
+}
+
+// ProxyResponse writes a downstream response to c like Stream.Proxy, e.g. a document fetched
+// with Get or Raw, streaming the body instead of buffering it, then closes the body
+func ProxyResponse(c *gin.Context, resp *http.Response) {
+	defer resp.Body.Close()
+	proxy(c, resp.StatusCode, resp.Header, resp.Body)
+}
+
+func proxy(c *gin.Context, status int, header http.Header, body io.Reader) {
+	for _, key := range proxiedHeaders {
+		if v := header.Get(key); v != "" {
+			c.Header(key, v)
+		}
+	}
+	c.Status(status)
+	if _, err := io.Copy(c.Writer, body); err != nil {
+		_ = c.Error(err)
+	}
+}
@@ -0,0 +1,61 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// DefaultTimeout bounds each attempt of a call when neither the call nor its service sets one
+const DefaultTimeout = 30 * time.Second
+
+// ServiceTimeouts maps service names, as in ServiceConfig, to the timeout of each attempt, e.g.
+// {"auth": 3 * time.Second, "reports": 2 * time.Minute}
+type ServiceTimeouts map[string]time.Duration
+
+// WithServiceTimeouts sets per-service timeouts; services not listed use the default timeout
+func (c *ServiceClient) WithServiceTimeouts(timeouts ServiceTimeouts) *ServiceClient {
+	c.timeouts = timeouts
+	return c
+}
+
+// WithDefaultTimeout replaces DefaultTimeout for this client
+func (c *ServiceClient) WithDefaultTimeout(d time.Duration) *ServiceClient {
+	c.timeout = d
+	return c
+}
+
+type timeoutKey struct{}
+
+// WithTimeout overrides the timeout of the calls made with the returned context, e.g. for one
+// slow export:
+//
+//	resp, err := client.Get(httpclient.WithTimeout(c, 2*time.Minute), "/api/v1/reports/export")
+func WithTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, d)
+}
+
+// timeoutFor resolves the timeout of an attempt: the call's, the service's, then the default
+func (c *ServiceClient) timeoutFor(ctx context.Context, service string) time.Duration {
+	if d, ok := ctx.Value(timeoutKey{}).(time.Duration); ok && d > 0 {
+		return d
+	}
+	if d, ok := c.timeouts[service]; ok && d > 0 {
+		return d
+	}
+	if c.timeout > 0 {
+		return c.timeout
+	}
+	return DefaultTimeout
+}
+
+// cancelOnClose releases an attempt's timeout once the caller has read the response body
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,85 @@
+package httpclient
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// TransportConfig tunes the connection pool shared by every call of a client. Zero fields
+// keep the defaults of DefaultTransportConfig.
+type TransportConfig struct {
+	// MaxIdleConns caps idle connections across all services
+	MaxIdleConns int
+	// MaxIdleConnsPerHost caps idle connections kept per service; net/http's default of 2
+	// makes busy services open and close connections constantly
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost caps connections per service, including active ones; 0 means unlimited
+	MaxConnsPerHost int
+	// IdleConnTimeout closes connections idle for longer
+	IdleConnTimeout time.Duration
+	// KeepAlive is the TCP keep-alive probe period; negative disables the probes
+	KeepAlive time.Duration
+	// DisableKeepAlives opens a new connection per request
+	DisableKeepAlives   bool
+	DialTimeout         time.Duration
+	TLSHandshakeTimeout time.Duration
+}
+
+// DefaultTransportConfig is the pool of a new client, sized for a handful of busy internal
+// services
+func DefaultTransportConfig() TransportConfig {
+	return TransportConfig{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 20,
+		IdleConnTimeout:     90 * time.Second,
+		KeepAlive:           30 * time.Second,
+		DialTimeout:         30 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+}
+
+// WithTransportConfig tunes the connection pool; call it while setting the client up, before
+// the first request. Transports added with WithTransport keep wrapping the tuned one.
+func (c *ServiceClient) WithTransportConfig(cfg TransportConfig) *ServiceClient {
+	applyTransportConfig(c.transport, cfg)
+	return c
+}
+
+// newTransport clones http.DefaultTransport, keeping its proxy and HTTP/2 settings, and applies
+// the default pool settings
+func newTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	applyTransportConfig(t, TransportConfig{})
+	return t
+}
+
+func applyTransportConfig(t *http.Transport, cfg TransportConfig) {
+	def := DefaultTransportConfig()
+	if cfg.MaxIdleConns <= 0 {
+		cfg.MaxIdleConns = def.MaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost <= 0 {
+		cfg.MaxIdleConnsPerHost = def.MaxIdleConnsPerHost
+	}
+	if cfg.IdleConnTimeout <= 0 {
+		cfg.IdleConnTimeout = def.IdleConnTimeout
+	}
+	if cfg.KeepAlive == 0 {
+		cfg.KeepAlive = def.KeepAlive
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = def.DialTimeout
+	}
+	if cfg.TLSHandshakeTimeout <= 0 {
+		cfg.TLSHandshakeTimeout = def.TLSHandshakeTimeout
+	}
+
+	t.MaxIdleConns = cfg.MaxIdleConns
+	t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	t.MaxConnsPerHost = cfg.MaxConnsPerHost
+	t.IdleConnTimeout = cfg.IdleConnTimeout
+	t.DisableKeepAlives = cfg.DisableKeepAlives
+	t.TLSHandshakeTimeout = cfg.TLSHandshakeTimeout
+	t.DialContext = (&net.Dialer{Timeout: cfg.DialTimeout, KeepAlive: cfg.KeepAlive}).DialContext
+}
@@ -0,0 +1,51 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// GetAs performs a GET and returns the data of the standard response envelope as T, e.g.
+//
+//	access, err := httpclient.GetAs[AccessData](ctx, client, "/api/v1/auth/access")
+//	user, err := httpclient.GetAs[User](ctx, client, "/api/v1/users/:id", httpclient.WithPathParam("id", id))
+func GetAs[T any](ctx context.Context, c ServiceCaller, route string, opts ...RequestOption) (T, error) {
+	return decodeAs[T](c.Get(ctx, route, opts...))
+}
+
+// PostAs performs a POST and returns the data of the standard response envelope as T
+func PostAs[T any](ctx context.Context, c ServiceCaller, route string, payload interface{}, opts ...RequestOption) (T, error) {
+	return decodeAs[T](c.Post(ctx, route, payload, opts...))
+}
+
+// PutAs performs a PUT and returns the data of the standard response envelope as T
+func PutAs[T any](ctx context.Context, c ServiceCaller, route string, payload interface{}, opts ...RequestOption) (T, error) {
+	return decodeAs[T](c.Put(ctx, route, payload, opts...))
+}
+
+// DeleteAs performs a DELETE and returns the data of the standard response envelope as T
+func DeleteAs[T any](ctx context.Context, c ServiceCaller, route string, opts ...RequestOption) (T, error) {
+	return decodeAs[T](c.Delete(ctx, route, opts...))
+}
+
+// PatchAs performs a PATCH and returns the data of the standard response envelope as T
+func PatchAs[T any](ctx context.Context, c MethodCaller, route string, payload interface{}, opts ...RequestOption) (T, error) {
+	return decodeAs[T](c.Do(ctx, http.MethodPatch, route, payload, opts...))
+}
+
+// DoAs performs a request with any method and returns the data of the standard response
+// envelope as T
+func DoAs[T any](ctx context.Context, c MethodCaller, method, route string, payload interface{}, opts ...RequestOption) (T, error) {
+	return decodeAs[T](c.Do(ctx, method, route, payload, opts...))
+}
+
+func decodeAs[T any](resp *http.Response, err error) (T, error) {
+	var out T
+	if err != nil {
+		return out, err
+	}
+	if err := DecodeStandardResponse(resp, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
@@ -8,6 +8,8 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/localcache"
 	"github.com/gin-gonic/gin"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"golang.org/x/text/language"
@@ -18,8 +20,15 @@ var (
 	localizers map[string]*i18n.Localizer
 	initOnce   sync.Once
 	mutex      sync.RWMutex
+
+	// translations memoizes messages without template data, the bulk of lookups
+	translations = localcache.New[translationKey, string](localcache.Config{Name: "i18n", MaxEntries: 20000})
 )
 
+type translationKey struct {
+	lang, key string
+}
+
 // Setup initializes the i18n system with a locales directory
 func Setup(localesDir string) error {
 	var err error
@@ -45,6 +54,7 @@ func Setup(localesDir string) error {
 		// Create localizers for supported languages
 		localizers["en"] = i18n.NewLocalizer(bundle, "en")
 		localizers["ar"] = i18n.NewLocalizer(bundle, "ar")
+		translations.Clear()
 	})
 	return err
 }
@@ -53,22 +63,36 @@ func Setup(localesDir string) error {
 func Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		lang := detectLanguage(c)
-		c.Set("lang", lang)
+		ctxutil.WithLang(c, lang)
 		c.Next()
 	}
 }
 
 // T translates a message for the current request
 func T(c *gin.Context, key string, data ...map[string]interface{}) string {
-	lang := getLang(c)
+	return TLang(getLang(c), key, data...)
+}
+
+// TLang translates a message for an explicit language, for code running outside a request
+// (emails, background jobs, notifications)
+func TLang(lang, key string, data ...map[string]interface{}) string {
+	lang = normalizeLang(lang)
+	if len(data) == 0 || data[0] == nil {
+		if msg, ok := translations.Get(translationKey{lang, key}); ok {
+			return msg
+		}
+	}
 
 	mutex.RLock()
 	localizer, exists := localizers[lang]
-	mutex.RUnlock()
-
 	if !exists {
 		localizer = localizers["en"] // fallback
 	}
+	mutex.RUnlock()
+
+	if localizer == nil {
+		return key
+	}
 
 	var templateData map[string]interface{}
 	if len(data) > 0 {
@@ -82,9 +106,22 @@ func T(c *gin.Context, key string, data ...map[string]interface{}) string {
 	if err != nil {
 		return key // fallback to key
 	}
+	if templateData == nil {
+		translations.Set(translationKey{lang, key}, msg)
+	}
 	return msg
 }
 
+// Lang returns the language detected for the current request
+func Lang(c *gin.Context) string {
+	return getLang(c)
+}
+
+// IsRTL reports whether lang is written right-to-left
+func IsRTL(lang string) bool {
+	return normalizeLang(lang) == "ar"
+}
+
 // detectLanguage gets language from headers with fallback to "en"
 func detectLanguage(c *gin.Context) string {
 	// Check X-Language header first
@@ -105,10 +142,8 @@ func detectLanguage(c *gin.Context) string {
 
 // getLang gets language from context with fallback
 func getLang(c *gin.Context) string {
-	if lang, exists := c.Get("lang"); exists {
-		if langStr, ok := lang.(string); ok {
-			return langStr
-		}
+	if lang := ctxutil.Lang(c); lang != "" {
+		return lang
 	}
 	return "en"
 }
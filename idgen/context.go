@@ -0,0 +1,23 @@
+package idgen
+
+import (
+	"context"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+)
+
+// NewRequestID returns a new request ID; ULIDs sort by time, which keeps log searches readable
+func NewRequestID() string {
+	return NewULIDString()
+}
+
+// RequestID returns the request ID carried by ctx, or an empty string
+func RequestID(ctx context.Context) string {
+	return ctxutil.RequestID(ctx)
+}
+
+// WithRequestID returns a copy of ctx carrying id, for work started outside an HTTP request
+// (jobs, consumers) so downstream logs and calls can be correlated
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return ctxutil.WithRequestID(ctx, id)
+}
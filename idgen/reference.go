@@ -0,0 +1,101 @@
+package idgen
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidReference is returned by ParseReference for malformed or mistyped references
+var ErrInvalidReference = errors.New("invalid reference number")
+
+// referenceLength is the number of random characters, 32^8 = 2^40 values per prefix and year
+const referenceLength = 8
+
+// Reference returns a human-friendly reference such as CASE-2026-7K3M9QX2-R. The body uses the
+// Crockford alphabet so it can be read over the phone, and the trailing check character catches
+// most typos. It is random, not sequential, so keep a unique index on the column.
+func Reference(prefix string) string {
+	body := make([]byte, referenceLength)
+	max := big.NewInt(int64(len(crockford)))
+	for i := range body {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic("idgen: failed to read random bytes: " + err.Error())
+		}
+		body[i] = crockford[n.Int64()]
+	}
+
+	year := strconv.Itoa(time.Now().UTC().Year())
+	ref := string(body)
+	if prefix != "" {
+		return strings.ToUpper(prefix) + "-" + year + "-" + ref + "-" + string(checkChar(ref))
+	}
+	return year + "-" + ref + "-" + string(checkChar(ref))
+}
+
+// ParsedReference is a decoded reference number
+type ParsedReference struct {
+	Prefix string
+	Year   int
+	Body   string
+}
+
+// ParseReference validates a reference produced by Reference, accepting lowercase input and the
+// Crockford substitutions (O→0, I/L→1)
+func ParseReference(s string) (*ParsedReference, error) {
+	parts := strings.Split(strings.ToUpper(strings.TrimSpace(s)), "-")
+	if len(parts) < 3 || len(parts) > 4 {
+		return nil, ErrInvalidReference
+	}
+
+	ref := &ParsedReference{}
+	if len(parts) == 4 {
+		ref.Prefix = parts[0]
+		parts = parts[1:]
+	}
+
+	year, err := strconv.Atoi(parts[0])
+	if err != nil || len(parts[0]) != 4 {
+		return nil, ErrInvalidReference
+	}
+	ref.Year = year
+
+	body := strings.NewReplacer("O", "0", "I", "1", "L", "1").Replace(parts[1])
+	if len(body) != referenceLength || len(parts[2]) != 1 {
+		return nil, ErrInvalidReference
+	}
+	for i := 0; i < len(body); i++ {
+		if strings.IndexByte(crockford, body[i]) < 0 {
+			return nil, ErrInvalidReference
+		}
+	}
+	if checkChar(body) != parts[2][0] {
+		return nil, fmt.Errorf("%w: check character mismatch", ErrInvalidReference)
+	}
+	ref.Body = body
+	return ref, nil
+}
+
+// String formats the reference back to its canonical form
+func (r *ParsedReference) String() string {
+	s := strconv.Itoa(r.Year) + "-" + r.Body + "-" + string(checkChar(r.Body))
+	if r.Prefix != "" {
+		return r.Prefix + "-" + s
+	}
+	return s
+}
+
+// checkChar is a weighted mod-31 checksum over the Crockford values, mapped back into the
+// alphabet so the reference stays unambiguous
+func checkChar(body string) byte {
+	sum := 0
+	for i := 0; i < len(body); i++ {
+		sum += (i + 1) * strings.IndexByte(crockford, body[i])
+	}
+	return crockford[sum%31]
+}
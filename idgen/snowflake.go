@@ -0,0 +1,104 @@
+package idgen
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Snowflake layout: 41 bits of milliseconds since Epoch, 10 bits of node ID, 12 bits of sequence
+const (
+	nodeBits     = 10
+	sequenceBits = 12
+	MaxNodeID    = 1<<nodeBits - 1
+	maxSequence  = 1<<sequenceBits - 1
+)
+
+// Epoch is the snowflake time origin (2024-01-01 UTC); changing it breaks ordering of existing IDs
+var Epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Snowflake generates 64-bit, time-ordered IDs that fit a BIGINT column. Every running instance
+// needs a distinct node ID.
+type Snowflake struct {
+	mu       sync.Mutex
+	node     int64
+	lastMs   int64
+	sequence int64
+}
+
+// NewSnowflake creates a generator for nodeID (0-1023)
+func NewSnowflake(nodeID int64) (*Snowflake, error) {
+	if nodeID < 0 || nodeID > MaxNodeID {
+		return nil, fmt.Errorf("snowflake node id must be between 0 and %d, got %d", MaxNodeID, nodeID)
+	}
+	return &Snowflake{node: nodeID}, nil
+}
+
+// Next returns the next ID, waiting for the next millisecond when the sequence is exhausted
+func (s *Snowflake) Next() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Since(Epoch).Milliseconds()
+	if now < s.lastMs {
+		// Clock moved backwards: keep issuing from the last timestamp to stay monotonic
+		now = s.lastMs
+	}
+	if now == s.lastMs {
+		s.sequence = (s.sequence + 1) & maxSequence
+		if s.sequence == 0 {
+			for now <= s.lastMs {
+				time.Sleep(100 * time.Microsecond)
+				now = time.Since(Epoch).Milliseconds()
+			}
+		}
+	} else {
+		s.sequence = 0
+	}
+	s.lastMs = now
+
+	return now<<(nodeBits+sequenceBits) | s.node<<sequenceBits | s.sequence
+}
+
+// NextUint64 is Next for uint64 primary keys such as model.Base.ID
+func (s *Snowflake) NextUint64() uint64 {
+	return uint64(s.Next())
+}
+
+// SnowflakeTime returns the time encoded in a snowflake ID
+func SnowflakeTime(id int64) time.Time {
+	return Epoch.Add(time.Duration(id>>(nodeBits+sequenceBits)) * time.Millisecond)
+}
+
+// NodeIDFromEnv reads NODE_ID, falling back to a hash of the hostname (pod name) which is
+// stable per instance but may collide; set NODE_ID explicitly when IDs must be globally unique
+func NodeIDFromEnv() int64 {
+	if v, err := strconv.ParseInt(os.Getenv("NODE_ID"), 10, 64); err == nil && v >= 0 && v <= MaxNodeID {
+		return v
+	}
+	host, _ := os.Hostname()
+	h := fnv.New32a()
+	h.Write([]byte(host))
+	return int64(h.Sum32() % (MaxNodeID + 1))
+}
+
+var (
+	defaultOnce      sync.Once
+	defaultSnowflake *Snowflake
+)
+
+// Default returns the process-wide snowflake generator using NodeIDFromEnv
+func Default() *Snowflake {
+	defaultOnce.Do(func() {
+		defaultSnowflake, _ = NewSnowflake(NodeIDFromEnv())
+	})
+	return defaultSnowflake
+}
+
+// Next returns an ID from the Default generator
+func Next() int64 {
+	return Default().Next()
+}
@@ -0,0 +1,124 @@
+package idgen
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// crockford is the Crockford base32 alphabet used by ULIDs and references (no I, L, O, U)
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ErrInvalidULID is returned by ParseULID for malformed input
+var ErrInvalidULID = errors.New("invalid ULID")
+
+// ULID is a 128-bit lexicographically sortable identifier: 48 bits of millisecond time followed by 80 random bits
+type ULID [16]byte
+
+var (
+	ulidMu      sync.Mutex
+	ulidLastMs  uint64
+	ulidLastRnd [10]byte
+)
+
+// NewULID returns a new ULID. IDs generated in the same millisecond by this process are
+// monotonic, so they still sort in creation order.
+func NewULID() ULID {
+	ms := uint64(time.Now().UnixMilli())
+
+	ulidMu.Lock()
+	defer ulidMu.Unlock()
+
+	if ms <= ulidLastMs {
+		// Same (or skewed back) millisecond: increment the random part instead of redrawing it
+		ms = ulidLastMs
+		for i := len(ulidLastRnd) - 1; i >= 0; i-- {
+			ulidLastRnd[i]++
+			if ulidLastRnd[i] != 0 {
+				break
+			}
+		}
+	} else {
+		if _, err := rand.Read(ulidLastRnd[:]); err != nil {
+			panic("idgen: failed to read random bytes: " + err.Error())
+		}
+		ulidLastMs = ms
+	}
+
+	var id ULID
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], ms)
+	copy(id[:6], ts[2:])
+	copy(id[6:], ulidLastRnd[:])
+	return id
+}
+
+// NewULIDString is NewULID().String()
+func NewULIDString() string {
+	return NewULID().String()
+}
+
+// Time returns the creation time encoded in the ULID
+func (id ULID) Time() time.Time {
+	var ts [8]byte
+	copy(ts[2:], id[:6])
+	return time.UnixMilli(int64(binary.BigEndian.Uint64(ts[:]))).UTC()
+}
+
+// String returns the 26-character Crockford base32 encoding
+func (id ULID) String() string {
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+
+	var out [26]byte
+	// 130 bits of output for 128 bits of input: the first character carries the top 3 bits
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (id ULID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (id *ULID) UnmarshalText(b []byte) error {
+	parsed, err := ParseULID(string(b))
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// ParseULID decodes a 26-character ULID, case-insensitively
+func ParseULID(s string) (ULID, error) {
+	var id ULID
+	if len(s) != 26 {
+		return id, ErrInvalidULID
+	}
+	s = strings.ToUpper(s)
+	if s[0] > '7' {
+		return id, ErrInvalidULID // would overflow 128 bits
+	}
+
+	var hi, lo uint64
+	for i := 0; i < len(s); i++ {
+		v := strings.IndexByte(crockford, s[i])
+		if v < 0 {
+			return id, ErrInvalidULID
+		}
+		hi = hi<<5 | lo>>59
+		lo = lo<<5 | uint64(v)
+	}
+	binary.BigEndian.PutUint64(id[:8], hi)
+	binary.BigEndian.PutUint64(id[8:], lo)
+	return id, nil
+}
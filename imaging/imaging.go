@@ -0,0 +1,289 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/storage"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register WebP decoding
+)
+
+// Format is an output image format
+type Format string
+
+const (
+	JPEG Format = "jpeg"
+	PNG  Format = "png"
+	GIF  Format = "gif"
+)
+
+// Fit controls how an image is scaled into the target box
+type Fit string
+
+const (
+	// Contain scales the image to fit inside the box, keeping the aspect ratio
+	Contain Fit = "contain"
+	// Cover scales and center-crops the image to fill the box exactly
+	Cover Fit = "cover"
+)
+
+// Limits guards against decompression bombs and oversized inputs
+type Limits struct {
+	MaxWidth  int
+	MaxHeight int
+	MaxPixels int
+	MaxBytes  int64 // encoded size read from the input
+}
+
+// DefaultLimits are applied when a zero Limits is passed
+var DefaultLimits = Limits{MaxWidth: 8000, MaxHeight: 8000, MaxPixels: 40_000_000, MaxBytes: 32 << 20}
+
+// Variant describes a derived image, e.g. a 128x128 avatar thumbnail
+type Variant struct {
+	Name    string
+	Width   int
+	Height  int
+	Fit     Fit
+	Format  Format
+	Quality int // JPEG quality 1-100, defaults to 85
+}
+
+// Standard variant sets shared across services
+var (
+	AvatarVariants = []Variant{
+		{Name: "sm", Width: 64, Height: 64, Fit: Cover, Format: JPEG},
+		{Name: "md", Width: 256, Height: 256, Fit: Cover, Format: JPEG},
+	}
+	PreviewVariants = []Variant{
+		{Name: "thumb", Width: 320, Fit: Contain, Format: JPEG},
+		{Name: "preview", Width: 1280, Fit: Contain, Format: JPEG},
+	}
+)
+
+// ErrImageTooLarge is returned when an input exceeds the configured limits
+var ErrImageTooLarge = apperror.New("image_too_large", apperror.KindTooLarge, "image size or dimensions exceed the allowed maximum").
+	WithKey("imaging.too_large")
+
+// ErrUnsupportedImage is returned for inputs that cannot be decoded
+var ErrUnsupportedImage = apperror.New("unsupported_image", apperror.KindBadRequest, "unsupported or corrupt image").
+	WithKey("imaging.unsupported")
+
+// Decode reads an image after checking its size and dimensions against limits, and applies the EXIF
+// orientation so the pixels are upright once metadata is dropped
+func Decode(r io.Reader, limits Limits) (image.Image, string, error) {
+	if limits == (Limits{}) {
+		limits = DefaultLimits
+	}
+
+	if limits.MaxBytes > 0 {
+		r = io.LimitReader(r, limits.MaxBytes+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read image: %w", err)
+	}
+	if limits.MaxBytes > 0 && int64(len(data)) > limits.MaxBytes {
+		return nil, "", ErrImageTooLarge
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrUnsupportedImage
+	}
+	if (limits.MaxWidth > 0 && cfg.Width > limits.MaxWidth) ||
+		(limits.MaxHeight > 0 && cfg.Height > limits.MaxHeight) ||
+		(limits.MaxPixels > 0 && cfg.Width*cfg.Height > limits.MaxPixels) {
+		return nil, "", ErrImageTooLarge
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrUnsupportedImage
+	}
+
+	if format == "jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+	return img, format, nil
+}
+
+// Resize scales img into a width x height box; a zero dimension is derived from the aspect ratio
+func Resize(img image.Image, width, height int, fit Fit) image.Image {
+	src := img.Bounds()
+	sw, sh := src.Dx(), src.Dy()
+	if sw == 0 || sh == 0 {
+		return img
+	}
+
+	switch {
+	case width <= 0 && height <= 0:
+		return img
+	case width <= 0:
+		width = max(sw*height/sh, 1)
+	case height <= 0:
+		height = max(sh*width/sw, 1)
+	}
+
+	if fit == Cover {
+		// Crop the source to the target aspect ratio, centered
+		cropW, cropH := sw, sh
+		if sw*height > sh*width {
+			cropW = max(sh*width/height, 1)
+		} else {
+			cropH = max(sw*height/width, 1)
+		}
+		x0 := src.Min.X + (sw-cropW)/2
+		y0 := src.Min.Y + (sh-cropH)/2
+		srcRect := image.Rect(x0, y0, x0+cropW, y0+cropH)
+
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, srcRect, draw.Over, nil)
+		return dst
+	}
+
+	// Contain: shrink the box to the image's aspect ratio, never upscale
+	if sw <= width && sh <= height {
+		return img
+	}
+	if sw*height > sh*width {
+		height = sh * width / sw
+	} else {
+		width = sw * height / sh
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, max(width, 1), max(height, 1)))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, src, draw.Over, nil)
+	return dst
+}
+
+// Encode writes img in the given format. Metadata (EXIF, GPS, etc.) is never written.
+func Encode(w io.Writer, img image.Image, format Format, quality int) error {
+	switch format {
+	case PNG:
+		return png.Encode(w, img)
+	case GIF:
+		return gif.Encode(w, img, nil)
+	case JPEG, "":
+		if quality <= 0 || quality > 100 {
+			quality = 85
+		}
+		return jpeg.Encode(w, flatten(img), &jpeg.Options{Quality: quality})
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// ContentType returns the MIME type for a format
+func (f Format) ContentType() string {
+	switch f {
+	case PNG:
+		return "image/png"
+	case GIF:
+		return "image/gif"
+	default:
+		return "image/jpeg"
+	}
+}
+
+// Extension returns the file extension for a format
+func (f Format) Extension() string {
+	switch f {
+	case PNG:
+		return ".png"
+	case GIF:
+		return ".gif"
+	default:
+		return ".jpg"
+	}
+}
+
+// Process decodes, resizes and re-encodes an image in one pass, stripping EXIF along the way
+func Process(r io.Reader, v Variant, limits Limits) ([]byte, error) {
+	img, _, err := Decode(r, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, Resize(img, v.Width, v.Height, v.Fit), v.Format, v.Quality); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// VariantKey returns the storage key of a variant, e.g. "avatars/42/abc.jpg" -> "avatars/42/abc_thumb.jpg"
+func VariantKey(key string, v Variant) string {
+	base := strings.TrimSuffix(key, path.Ext(key))
+	return base + "_" + v.Name + v.Format.Extension()
+}
+
+// GenerateVariants reads the original object and stores every variant next to it
+func GenerateVariants(ctx context.Context, store storage.Storage, key string, variants []Variant, limits Limits) (map[string]*storage.Object, error) {
+	rc, _, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := Decode(rc, limits)
+	rc.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make(map[string]*storage.Object, len(variants))
+	for _, v := range variants {
+		var buf bytes.Buffer
+		if err := Encode(&buf, Resize(img, v.Width, v.Height, v.Fit), v.Format, v.Quality); err != nil {
+			return nil, fmt.Errorf("failed to encode variant %s: %w", v.Name, err)
+		}
+
+		obj, err := store.Put(ctx, VariantKey(key, v), &buf, int64(buf.Len()), storage.PutOptions{
+			ContentType:  v.Format.ContentType(),
+			CacheControl: "public, max-age=31536000, immutable",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to store variant %s: %w", v.Name, err)
+		}
+		objects[v.Name] = obj
+	}
+	return objects, nil
+}
+
+// VariantURL returns a presigned download URL for a variant of key
+func VariantURL(ctx context.Context, store storage.Storage, key string, v Variant, expiry time.Duration) (string, error) {
+	return store.PresignGet(ctx, VariantKey(key, v), expiry)
+}
+
+// VariantURLs returns presigned URLs for all variants keyed by variant name
+func VariantURLs(ctx context.Context, store storage.Storage, key string, variants []Variant, expiry time.Duration) (map[string]string, error) {
+	urls := make(map[string]string, len(variants))
+	for _, v := range variants {
+		u, err := VariantURL(ctx, store, key, v, expiry)
+		if err != nil {
+			return nil, err
+		}
+		urls[v.Name] = u
+	}
+	return urls, nil
+}
+
+// flatten draws img on a white background, since JPEG has no alpha channel
+func flatten(img image.Image) image.Image {
+	if o, ok := img.(interface{ Opaque() bool }); ok && o.Opaque() {
+		return img
+	}
+	dst := image.NewRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Over)
+	return dst
+}
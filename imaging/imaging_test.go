@@ -0,0 +1,46 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"testing"
+)
+
+func TestDecodeRejectsOversizedInput(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, image.NewRGBA(image.Rect(0, 0, 64, 64)), PNG, 0); err != nil {
+		t.Fatal(err)
+	}
+	size := int64(buf.Len())
+
+	if _, _, err := Decode(bytes.NewReader(buf.Bytes()), Limits{MaxBytes: size}); err != nil {
+		t.Fatalf("Decode() at the limit error = %v", err)
+	}
+	if _, _, err := Decode(bytes.NewReader(buf.Bytes()), Limits{MaxBytes: size - 1}); !errors.Is(err, ErrImageTooLarge) {
+		t.Fatalf("Decode() over the limit error = %v, want ErrImageTooLarge", err)
+	}
+}
+
+func TestResizeDerivedDimension(t *testing.T) {
+	tests := []struct {
+		name          string
+		src           image.Rectangle
+		width, height int
+		fit           Fit
+		want          image.Point
+	}{
+		{"cover wide strip", image.Rect(0, 0, 1000, 1), 100, 0, Cover, image.Pt(100, 1)},
+		{"cover tall strip", image.Rect(0, 0, 1, 1000), 0, 100, Cover, image.Pt(1, 100)},
+		{"contain wide strip", image.Rect(0, 0, 1000, 1), 100, 0, Contain, image.Pt(100, 1)},
+		{"cover square", image.Rect(0, 0, 400, 200), 100, 100, Cover, image.Pt(100, 100)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Resize(image.NewRGBA(tt.src), tt.width, tt.height, tt.fit).Bounds().Size()
+			if got != tt.want {
+				t.Errorf("Resize() size = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package importer
+
+import (
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// reportContentTypes are the download types of the error report
+var reportContentTypes = map[Format]string{
+	FormatCSV:  "text/csv; charset=utf-8",
+	FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// Handler imports the multipart "file" field of the request. ?dry_run=true only validates.
+// With ?report=xlsx (or csv) and row errors, the error report is downloaded instead of the JSON
+// summary. Protect it with RequirePermission and size-limit the route for large uploads.
+func Handler[T any](im *Importer[T], save SaveFunc[T]) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fh, err := c.FormFile("file")
+		if err != nil {
+			response.BadRequest(c, i18n.T(c, "importer.file_required"), response.Err("file", i18n.T(c, "importer.file_required")))
+			return
+		}
+		file, err := fh.Open()
+		if err != nil {
+			response.InternalErrorWithCause(c, err)
+			return
+		}
+		defer file.Close()
+
+		lang := i18n.Lang(c)
+		run := im.WithLang(lang)
+		run.cfg.DryRun = im.cfg.DryRun || c.Query("dry_run") == "true"
+
+		result, err := run.Run(c.Request.Context(), file, fh.Filename, save)
+		if err != nil {
+			response.HandleError(c, err)
+			return
+		}
+
+		if format := Format(c.Query("report")); len(result.Errors) > 0 && reportContentTypes[format] != "" {
+			data, err := result.ReportBytes(format, lang)
+			if err != nil {
+				response.InternalErrorWithCause(c, err)
+				return
+			}
+			response.FileBytes(c, "import-errors."+string(format), reportContentTypes[format], data)
+			return
+		}
+
+		response.OK(c, result, i18n.T(c, "importer.completed", map[string]interface{}{
+			"Imported": result.Imported,
+			"Failed":   result.Failed,
+		}))
+	}
+}
@@ -0,0 +1,344 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/sanitize"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrUnsupportedFormat is returned for files that are neither CSV nor XLSX
+	ErrUnsupportedFormat = apperror.New("import_unsupported_format", apperror.KindBadRequest, "file must be CSV or XLSX").
+				WithKey("importer.unsupported_format")
+	// ErrEmptyFile is returned when the file has no header row
+	ErrEmptyFile = apperror.New("import_empty_file", apperror.KindBadRequest, "file is empty").
+			WithKey("importer.empty_file")
+	// ErrMissingColumns is returned when required columns are absent from the header row
+	ErrMissingColumns = apperror.New("import_missing_columns", apperror.KindValidation, "required columns are missing").
+				WithKey("importer.missing_columns")
+	// ErrTooManyRows is returned when the file exceeds MaxRows
+	ErrTooManyRows = apperror.New("import_too_many_rows", apperror.KindTooLarge, "file has too many rows").
+			WithKey("importer.too_many_rows")
+
+	errInvalidNumber = errors.New("importer.invalid_number")
+	errInvalidBool   = errors.New("importer.invalid_bool")
+	errInvalidDate   = errors.New("importer.invalid_date")
+)
+
+// Config holds the importer settings
+type Config struct {
+	Sheet     string // XLSX sheet, defaults to the active one
+	HeaderRow int    // 1-based row holding the headers, defaults to 1
+	ChunkSize int    // rows per Save call, defaults to 500
+	MaxRows   int    // defaults to 10000, negative means no limit
+	// Mapping adds header aliases per canonical column name, e.g. {"email": {"E-mail"}}
+	Mapping map[string][]string
+	// DryRun validates every row without calling Save, for upload previews
+	DryRun bool
+	// Lang localises row error messages, defaults to "en"
+	Lang string
+}
+
+// SaveFunc stores one chunk of valid rows
+type SaveFunc[T any] func(ctx context.Context, rows []T) error
+
+// RowError is one problem found in the file; Row is the spreadsheet row number users see
+type RowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Value   string `json:"value,omitempty"`
+	Message string `json:"message"`
+}
+
+// Result summarises an import
+type Result struct {
+	Total    int        `json:"total"`
+	Imported int        `json:"imported"`
+	Failed   int        `json:"failed"`
+	DryRun   bool       `json:"dry_run,omitempty"`
+	Errors   []RowError `json:"errors,omitempty"`
+}
+
+// Importer parses uploads into T. T's fields map to columns through `import:"header,alias"`
+// tags (json names otherwise), are validated with their `binding` tags and cleaned with their
+// `sanitize` tags.
+type Importer[T any] struct {
+	cfg     *Config
+	columns []column
+}
+
+// New creates an importer for T, which must be a struct type
+func New[T any](cfg *Config) *Importer[T] {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	if cfg.HeaderRow <= 0 {
+		cfg.HeaderRow = 1
+	}
+	if cfg.ChunkSize <= 0 {
+		cfg.ChunkSize = 500
+	}
+	if cfg.MaxRows == 0 {
+		cfg.MaxRows = 10000
+	}
+	if cfg.Lang == "" {
+		cfg.Lang = "en"
+	}
+
+	var zero T
+	cols := columnsFor(reflect.TypeOf(zero))
+	for i := range cols {
+		for _, alias := range cfg.Mapping[cols[i].name] {
+			cols[i].aliases = append(cols[i].aliases, normalizeHeader(alias))
+		}
+	}
+	return &Importer[T]{cfg: cfg, columns: cols}
+}
+
+// WithLang returns a copy of the importer localising messages in lang, e.g. i18n.Lang(c)
+func (im *Importer[T]) WithLang(lang string) *Importer[T] {
+	cfg := *im.cfg
+	cfg.Lang = lang
+	return &Importer[T]{cfg: &cfg, columns: im.columns}
+}
+
+// Run parses r (format detected from filename or content), validates every row and passes
+// valid rows to save in chunks. Row problems are collected in the result; the returned error
+// is reserved for problems with the file itself or a cancelled context.
+func (im *Importer[T]) Run(ctx context.Context, r io.Reader, filename string, save SaveFunc[T]) (*Result, error) {
+	rows, err := openReader(r, filename, im.cfg.Sheet)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Skip everything above the header row
+	var header []string
+	for i := 0; i < im.cfg.HeaderRow; i++ {
+		header, err = rows.Next()
+		if err == io.EOF {
+			return nil, ErrEmptyFile
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	positions, err := im.match(header)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{DryRun: im.cfg.DryRun}
+	chunk := make([]T, 0, im.cfg.ChunkSize)
+	chunkRows := make([]int, 0, im.cfg.ChunkSize)
+	flush := func() {
+		if len(chunk) == 0 {
+			return
+		}
+		if !im.cfg.DryRun && save != nil {
+			if err := save(ctx, chunk); err != nil {
+				msg := i18n.TLang(im.cfg.Lang, "importer.save_failed")
+				for _, row := range chunkRows {
+					result.Errors = append(result.Errors, RowError{Row: row, Message: msg})
+				}
+				result.Failed += len(chunk)
+				chunk, chunkRows = chunk[:0], chunkRows[:0]
+				return
+			}
+		}
+		result.Imported += len(chunk)
+		chunk, chunkRows = chunk[:0], chunkRows[:0]
+	}
+
+	rowNum := im.cfg.HeaderRow
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		cells, err := rows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return result, err
+		}
+		rowNum++
+		if blank(cells) {
+			continue
+		}
+
+		result.Total++
+		if im.cfg.MaxRows > 0 && result.Total > im.cfg.MaxRows {
+			return result, withMeta(ErrTooManyRows, "max_rows", im.cfg.MaxRows)
+		}
+
+		item, rowErrs := im.parseRow(cells, positions, rowNum)
+		if len(rowErrs) > 0 {
+			result.Failed++
+			result.Errors = append(result.Errors, rowErrs...)
+			continue
+		}
+
+		chunk = append(chunk, item)
+		chunkRows = append(chunkRows, rowNum)
+		if len(chunk) >= im.cfg.ChunkSize {
+			flush()
+		}
+	}
+	flush()
+	return result, nil
+}
+
+// match maps each column to its position in the header row
+func (im *Importer[T]) match(header []string) ([]int, error) {
+	byName := make(map[string]int, len(header))
+	for i, h := range header {
+		if n := normalizeHeader(h); n != "" {
+			if _, dup := byName[n]; !dup {
+				byName[n] = i
+			}
+		}
+	}
+
+	positions := make([]int, len(im.columns))
+	var missing []string
+	for i, col := range im.columns {
+		positions[i] = -1
+		for _, alias := range col.aliases {
+			if pos, ok := byName[alias]; ok {
+				positions[i] = pos
+				break
+			}
+		}
+		if positions[i] < 0 && col.required {
+			missing = append(missing, col.name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, withMeta(ErrMissingColumns, "columns", strings.Join(missing, ", "))
+	}
+	return positions, nil
+}
+
+// parseRow converts, sanitises and validates one row
+func (im *Importer[T]) parseRow(cells []string, positions []int, rowNum int) (T, []RowError) {
+	var item T
+	v := reflect.ValueOf(&item).Elem()
+	var errs []RowError
+
+	for i, col := range im.columns {
+		pos := positions[i]
+		if pos < 0 || pos >= len(cells) {
+			continue
+		}
+		if err := setField(v.FieldByIndex(col.index), cells[pos]); err != nil {
+			key := err.Error()
+			if !strings.HasPrefix(key, "importer.") {
+				key = "importer.invalid_value"
+			}
+			errs = append(errs, RowError{Row: rowNum, Field: col.name, Value: cells[pos], Message: i18n.TLang(im.cfg.Lang, key)})
+		}
+	}
+
+	if err := sanitize.Struct(&item); err != nil {
+		return item, append(errs, RowError{Row: rowNum, Message: err.Error()})
+	}
+
+	// Validate even when conversions failed so users see every problem of the row at once
+	if err := binding.Validator.ValidateStruct(&item); err != nil {
+		var verrs validator.ValidationErrors
+		if !errors.As(err, &verrs) {
+			return item, append(errs, RowError{Row: rowNum, Message: err.Error()})
+		}
+		for _, fe := range verrs {
+			name, value := im.fieldInfo(fe, cells, positions)
+			if reported(errs, name) {
+				continue
+			}
+			key := "validation." + fe.Tag()
+			msg := i18n.TLang(im.cfg.Lang, key, gin.H{"Field": name, "Param": fe.Param()})
+			if msg == key {
+				key = "importer.invalid_value"
+				if fe.Tag() == "required" {
+					key = "importer.required"
+				}
+				msg = i18n.TLang(im.cfg.Lang, key)
+			}
+			errs = append(errs, RowError{Row: rowNum, Field: name, Value: value, Message: msg})
+		}
+	}
+	return item, errs
+}
+
+func reported(errs []RowError, field string) bool {
+	for _, e := range errs {
+		if e.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldInfo finds the column header and raw cell of a validation error
+func (im *Importer[T]) fieldInfo(fe validator.FieldError, cells []string, positions []int) (string, string) {
+	var zero T
+	t := reflect.TypeOf(zero)
+	for i, col := range im.columns {
+		if t.FieldByIndex(col.index).Name != fe.StructField() {
+			continue
+		}
+		if pos := positions[i]; pos >= 0 && pos < len(cells) {
+			return col.name, cells[pos]
+		}
+		return col.name, ""
+	}
+	return fe.Field(), ""
+}
+
+// withMeta copies a sentinel error before attaching metadata so the sentinel stays untouched
+func withMeta(sentinel *apperror.Error, key string, value interface{}) *apperror.Error {
+	return apperror.New(sentinel.Code, sentinel.Kind, sentinel.Message).
+		WithKey(sentinel.MessageKey).
+		WithMeta(key, value)
+}
+
+func blank(cells []string) bool {
+	for _, c := range cells {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Upsert returns a SaveFunc inserting rows and updating existing ones that conflict on the
+// given columns (e.g. "national_id"); with no columns it only inserts
+func Upsert[T any](db *gorm.DB, conflictColumns ...string) SaveFunc[T] {
+	return func(ctx context.Context, rows []T) error {
+		tx := db.WithContext(ctx)
+		if len(conflictColumns) > 0 {
+			cols := make([]clause.Column, len(conflictColumns))
+			for i, c := range conflictColumns {
+				cols[i] = clause.Column{Name: c}
+			}
+			tx = tx.Clauses(clause.OnConflict{Columns: cols, UpdateAll: true})
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			return fmt.Errorf("failed to save imported rows: %w", err)
+		}
+		return nil
+	}
+}
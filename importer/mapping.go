@@ -0,0 +1,183 @@
+package importer
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// column describes how one struct field is filled
+type column struct {
+	index    []int
+	name     string   // canonical header, used in reports
+	aliases  []string // normalised accepted headers
+	required bool
+}
+
+// columnsFor reads `import:"header,alias,..."` tags; fields without the tag use their json name.
+// Fields tagged `import:"-"` are skipped.
+func columnsFor(t reflect.Type) []column {
+	var cols []column
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("import")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && tag == "" {
+			for _, c := range columnsFor(f.Type) {
+				c.index = append([]int{i}, c.index...)
+				cols = append(cols, c)
+			}
+			continue
+		}
+
+		var names []string
+		if tag != "" {
+			names = strings.Split(tag, ",")
+		} else if json, _, _ := strings.Cut(f.Tag.Get("json"), ","); json != "" && json != "-" {
+			names = []string{json}
+		} else {
+			names = []string{f.Name}
+		}
+
+		c := column{index: []int{i}, name: strings.TrimSpace(names[0])}
+		for _, n := range names {
+			c.aliases = append(c.aliases, normalizeHeader(n))
+		}
+		for _, rule := range strings.Split(f.Tag.Get("binding"), ",") {
+			if rule == "required" {
+				c.required = true
+			}
+		}
+		cols = append(cols, c)
+	}
+	return cols
+}
+
+// normalizeHeader makes header matching ignore case, spacing, underscores and the BOM
+func normalizeHeader(s string) string {
+	s = strings.TrimPrefix(s, "\uFEFF")
+	s = strings.ToLower(strings.ReplaceAll(s, "_", " "))
+	return strings.Join(strings.Fields(s), " ")
+}
+
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006/01/02",
+	"02/01/2006",
+	"02-01-2006",
+	"1/2/06 15:04",
+	"1/2/06",
+}
+
+var textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// setField converts a cell into the field's type; empty cells leave the zero value
+func setField(f reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+
+	if f.Kind() == reflect.Ptr {
+		v := reflect.New(f.Type().Elem())
+		if err := setField(v.Elem(), raw); err != nil {
+			return err
+		}
+		f.Set(v)
+		return nil
+	}
+
+	if f.CanAddr() && f.Addr().Type().Implements(textUnmarshaler) && f.Type() != reflect.TypeOf(time.Time{}) {
+		return f.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(cleanNumber(raw), 10, 64)
+		if err != nil {
+			return errInvalidNumber
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(cleanNumber(raw), 10, 64)
+		if err != nil {
+			return errInvalidNumber
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(cleanNumber(raw), 64)
+		if err != nil {
+			return errInvalidNumber
+		}
+		f.SetFloat(n)
+	case reflect.Bool:
+		switch strings.ToLower(raw) {
+		case "true", "yes", "y", "1", "نعم":
+			f.SetBool(true)
+		case "false", "no", "n", "0", "لا":
+			f.SetBool(false)
+		default:
+			return errInvalidBool
+		}
+	case reflect.Struct:
+		if f.Type() != reflect.TypeOf(time.Time{}) {
+			return fmt.Errorf("unsupported field type %s", f.Type())
+		}
+		t, err := parseTime(raw)
+		if err != nil {
+			return err
+		}
+		f.Set(reflect.ValueOf(t))
+	default:
+		return fmt.Errorf("unsupported field type %s", f.Type())
+	}
+	return nil
+}
+
+// cleanNumber removes thousands separators and converts Arabic-Indic digits
+func cleanNumber(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '٠' && r <= '٩':
+			b.WriteRune('0' + (r - '٠'))
+		case r >= '۰' && r <= '۹':
+			b.WriteRune('0' + (r - '۰'))
+		case r == '٫':
+			b.WriteRune('.')
+		case r == ',' || r == '٬' || r == ' ':
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// parseTime accepts common textual layouts and Excel serial dates
+func parseTime(raw string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, nil
+		}
+	}
+	if serial, err := strconv.ParseFloat(raw, 64); err == nil && serial > 0 {
+		if t, err := excelize.ExcelDateToTime(serial, false); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errInvalidDate
+}
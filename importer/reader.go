@@ -0,0 +1,133 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Format is an upload format
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// rowReader yields the raw cells of one row at a time
+type rowReader interface {
+	Next() ([]string, error) // io.EOF after the last row
+	Close() error
+}
+
+// detectFormat picks the format from the file name, falling back to the content: XLSX files are
+// ZIP archives
+func detectFormat(filename string, head []byte) (Format, error) {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv", ".txt":
+		return FormatCSV, nil
+	case ".xlsx", ".xlsm":
+		return FormatXLSX, nil
+	case ".xls":
+		return "", ErrUnsupportedFormat
+	}
+	if bytes.HasPrefix(head, []byte("PK\x03\x04")) {
+		return FormatXLSX, nil
+	}
+	if len(head) > 0 && bytes.IndexByte(head, 0) < 0 {
+		return FormatCSV, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+func openReader(r io.Reader, filename, sheet string) (rowReader, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(512)
+	format, err := detectFormat(filename, head)
+	if err != nil {
+		return nil, err
+	}
+	if format == FormatXLSX {
+		return newXLSXReader(br, sheet)
+	}
+	return newCSVReader(br), nil
+}
+
+type csvReader struct {
+	r *csv.Reader
+}
+
+// newCSVReader strips the UTF-8 BOM Excel writes and detects ";" separated files (Excel in
+// locales with a decimal comma)
+func newCSVReader(br *bufio.Reader) *csvReader {
+	if bom, _ := br.Peek(3); bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+		_, _ = br.Discard(3)
+	}
+
+	r := csv.NewReader(br)
+	if first, _ := br.Peek(4096); len(first) > 0 {
+		line, _, _ := bytes.Cut(first, []byte("\n"))
+		if bytes.Count(line, []byte(";")) > bytes.Count(line, []byte(",")) {
+			r.Comma = ';'
+		}
+	}
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	r.ReuseRecord = false
+	return &csvReader{r: r}
+}
+
+func (c *csvReader) Next() ([]string, error) {
+	row, err := c.r.Read()
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	return row, err
+}
+
+func (c *csvReader) Close() error {
+	return nil
+}
+
+type xlsxReader struct {
+	f    *excelize.File
+	rows *excelize.Rows
+}
+
+func newXLSXReader(r io.Reader, sheet string) (*xlsxReader, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	if sheet == "" {
+		sheet = f.GetSheetName(f.GetActiveSheetIndex())
+	}
+	rows, err := f.Rows(sheet)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open sheet %q: %w", sheet, err)
+	}
+	return &xlsxReader{f: f, rows: rows}, nil
+}
+
+func (x *xlsxReader) Next() ([]string, error) {
+	if !x.rows.Next() {
+		if err := x.rows.Error(); err != nil {
+			return nil, fmt.Errorf("failed to read XLSX: %w", err)
+		}
+		return nil, io.EOF
+	}
+	// Formatted values keep dates and numbers as the user sees them
+	return x.rows.Columns()
+}
+
+func (x *xlsxReader) Close() error {
+	x.rows.Close()
+	return x.f.Close()
+}
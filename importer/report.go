@@ -0,0 +1,81 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/xuri/excelize/v2"
+)
+
+// WriteReport writes the row errors as a spreadsheet with localised headers (row, field, value,
+// message) so users can fix the file and upload it again
+func (r *Result) WriteReport(w io.Writer, format Format, lang string) error {
+	header := []string{
+		i18n.TLang(lang, "importer.report.row"),
+		i18n.TLang(lang, "importer.report.field"),
+		i18n.TLang(lang, "importer.report.value"),
+		i18n.TLang(lang, "importer.report.message"),
+	}
+
+	if format == FormatCSV {
+		// The BOM makes Excel open UTF-8 (Arabic) CSV files correctly
+		if _, err := w.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+			return err
+		}
+		cw := csv.NewWriter(w)
+		_ = cw.Write(header)
+		for _, e := range r.Errors {
+			_ = cw.Write([]string{strconv.Itoa(e.Row), e.Field, e.Value, e.Message})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	sheet := f.GetSheetName(0)
+	if i18n.IsRTL(lang) {
+		rtl := true
+		_ = f.SetSheetView(sheet, 0, &excelize.ViewOptions{RightToLeft: &rtl})
+	}
+
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	_ = sw.SetColWidth(1, 1, 8)
+	_ = sw.SetColWidth(2, 3, 24)
+	_ = sw.SetColWidth(4, 4, 60)
+
+	bold, _ := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	row := make([]interface{}, len(header))
+	for i, h := range header {
+		row[i] = excelize.Cell{StyleID: bold, Value: h}
+	}
+	if err := sw.SetRow("A1", row); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	for i, e := range r.Errors {
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := sw.SetRow(cell, []interface{}{e.Row, e.Field, e.Value, e.Message}); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return f.Write(w)
+}
+
+// ReportBytes is WriteReport into memory
+func (r *Result) ReportBytes(format Format, lang string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := r.WriteReport(&buf, format, lang); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,274 @@
+package localcache
+
+import (
+	"container/list"
+	"errors"
+	"hash/maphash"
+	"sync"
+	"time"
+)
+
+var errPanicked = errors.New("localcache: load panicked")
+
+// Config configures a cache
+type Config struct {
+	// Name identifies the cache in metrics, e.g. "permissions"
+	Name string
+	// Shards splits the cache into independently locked parts, defaults to 16
+	Shards int
+	// MaxEntries bounds the cache, least recently used entries are evicted first; defaults to
+	// 10000 and is spread evenly over the shards
+	MaxEntries int
+	// TTL is the lifetime of entries set with Set; 0 keeps them until evicted
+	TTL time.Duration
+}
+
+// Cache is a sharded in-memory LRU with per-entry expiry, local to the process. Use the cache
+// package instead when replicas must share entries or invalidations.
+type Cache[K comparable, V any] struct {
+	cfg    Config
+	seed   maphash.Seed
+	shards []*shard[K, V]
+	now    func() time.Time
+}
+
+type shard[K comparable, V any] struct {
+	mu       sync.Mutex
+	max      int
+	ll       *list.List
+	items    map[K]*list.Element
+	inflight map[K]*call[V]
+}
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time // zero means never
+}
+
+// call is a load in progress shared by concurrent GetOrLoad callers
+type call[V any] struct {
+	wg    sync.WaitGroup
+	value V
+	err   error
+}
+
+// New creates a cache
+func New[K comparable, V any](cfg Config) *Cache[K, V] {
+	if cfg.Name == "" {
+		panic("localcache: Config.Name is required")
+	}
+	if cfg.Shards <= 0 {
+		cfg.Shards = 16
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 10000
+	}
+	if cfg.Shards > cfg.MaxEntries {
+		cfg.Shards = cfg.MaxEntries
+	}
+
+	c := &Cache[K, V]{cfg: cfg, seed: maphash.MakeSeed(), shards: make([]*shard[K, V], cfg.Shards), now: time.Now}
+	per := (cfg.MaxEntries + cfg.Shards - 1) / cfg.Shards
+	for i := range c.shards {
+		c.shards[i] = &shard[K, V]{
+			max:      per,
+			ll:       list.New(),
+			items:    make(map[K]*list.Element),
+			inflight: make(map[K]*call[V]),
+		}
+	}
+	return c
+}
+
+// Get returns a live entry and marks it recently used
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	s := c.shard(key)
+	s.mu.Lock()
+	v, ok := c.get(s, key)
+	s.mu.Unlock()
+	if ok {
+		requestsTotal.WithLabelValues(c.cfg.Name, "hit").Inc()
+	} else {
+		requestsTotal.WithLabelValues(c.cfg.Name, "miss").Inc()
+	}
+	return v, ok
+}
+
+// Set stores value with the configured TTL
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.cfg.TTL)
+}
+
+// SetWithTTL stores value for ttl; 0 keeps it until evicted
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	s := c.shard(key)
+	s.mu.Lock()
+	c.set(s, key, value, ttl)
+	s.mu.Unlock()
+}
+
+// GetOrLoad returns the cached value or stores the result of load; concurrent callers for the
+// same key share one load. Errors are returned and not cached.
+func (c *Cache[K, V]) GetOrLoad(key K, load func() (V, error)) (V, error) {
+	s := c.shard(key)
+	s.mu.Lock()
+	if v, ok := c.get(s, key); ok {
+		s.mu.Unlock()
+		requestsTotal.WithLabelValues(c.cfg.Name, "hit").Inc()
+		return v, nil
+	}
+	requestsTotal.WithLabelValues(c.cfg.Name, "miss").Inc()
+	if cl, ok := s.inflight[key]; ok {
+		s.mu.Unlock()
+		cl.wg.Wait()
+		return cl.value, cl.err
+	}
+	cl := &call[V]{}
+	cl.wg.Add(1)
+	s.inflight[key] = cl
+	s.mu.Unlock()
+
+	done := false
+	defer func() {
+		if !done {
+			// load panicked; waiters get an error and the panic carries on in this caller
+			cl.err = errPanicked
+		}
+		s.mu.Lock()
+		delete(s.inflight, key)
+		if cl.err == nil {
+			c.set(s, key, cl.value, c.cfg.TTL)
+		}
+		s.mu.Unlock()
+		cl.wg.Done()
+	}()
+	cl.value, cl.err = load()
+	done = true
+	return cl.value, cl.err
+}
+
+// Delete removes keys
+func (c *Cache[K, V]) Delete(keys ...K) {
+	for _, key := range keys {
+		s := c.shard(key)
+		s.mu.Lock()
+		if el, ok := s.items[key]; ok {
+			c.remove(s, el)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// DeleteFunc removes the entries for which match returns true and returns how many
+func (c *Cache[K, V]) DeleteFunc(match func(key K, value V) bool) int {
+	n := 0
+	for _, s := range c.shards {
+		s.mu.Lock()
+		for key, el := range s.items {
+			if match(key, el.Value.(*entry[K, V]).value) {
+				c.remove(s, el)
+				n++
+			}
+		}
+		s.mu.Unlock()
+	}
+	return n
+}
+
+// Purge removes expired entries and returns how many; expired entries are otherwise dropped
+// when read or evicted
+func (c *Cache[K, V]) Purge() int {
+	now := c.now()
+	n := 0
+	for _, s := range c.shards {
+		s.mu.Lock()
+		for _, el := range s.items {
+			if expired(el.Value.(*entry[K, V]), now) {
+				c.remove(s, el)
+				n++
+			}
+		}
+		s.mu.Unlock()
+	}
+	if n > 0 {
+		evictionsTotal.WithLabelValues(c.cfg.Name, "expired").Add(float64(n))
+	}
+	return n
+}
+
+// Clear removes every entry
+func (c *Cache[K, V]) Clear() {
+	for _, s := range c.shards {
+		s.mu.Lock()
+		s.ll.Init()
+		s.items = make(map[K]*list.Element)
+		s.mu.Unlock()
+	}
+	entries.WithLabelValues(c.cfg.Name).Set(0)
+}
+
+// Len returns the number of entries, including expired ones not yet dropped
+func (c *Cache[K, V]) Len() int {
+	n := 0
+	for _, s := range c.shards {
+		s.mu.Lock()
+		n += s.ll.Len()
+		s.mu.Unlock()
+	}
+	return n
+}
+
+func (c *Cache[K, V]) shard(key K) *shard[K, V] {
+	if len(c.shards) == 1 {
+		return c.shards[0]
+	}
+	return c.shards[maphash.Comparable(c.seed, key)%uint64(len(c.shards))]
+}
+
+// get must be called with the shard locked
+func (c *Cache[K, V]) get(s *shard[K, V], key K) (V, bool) {
+	var zero V
+	el, ok := s.items[key]
+	if !ok {
+		return zero, false
+	}
+	e := el.Value.(*entry[K, V])
+	if expired(e, c.now()) {
+		c.remove(s, el)
+		evictionsTotal.WithLabelValues(c.cfg.Name, "expired").Inc()
+		return zero, false
+	}
+	s.ll.MoveToFront(el)
+	return e.value, true
+}
+
+// set must be called with the shard locked
+func (c *Cache[K, V]) set(s *shard[K, V], key K, value V, ttl time.Duration) {
+	e := &entry[K, V]{key: key, value: value}
+	if ttl > 0 {
+		e.expiresAt = c.now().Add(ttl)
+	}
+	if el, ok := s.items[key]; ok {
+		el.Value = e
+		s.ll.MoveToFront(el)
+		return
+	}
+	s.items[key] = s.ll.PushFront(e)
+	entries.WithLabelValues(c.cfg.Name).Inc()
+	for s.ll.Len() > s.max {
+		c.remove(s, s.ll.Back())
+		evictionsTotal.WithLabelValues(c.cfg.Name, "capacity").Inc()
+	}
+}
+
+// remove must be called with the shard locked
+func (c *Cache[K, V]) remove(s *shard[K, V], el *list.Element) {
+	s.ll.Remove(el)
+	delete(s.items, el.Value.(*entry[K, V]).key)
+	entries.WithLabelValues(c.cfg.Name).Dec()
+}
+
+func expired[K comparable, V any](e *entry[K, V], now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
@@ -0,0 +1,24 @@
+package localcache
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Hit, miss and eviction counts and the size of each in-process cache, by cache name
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "localcache_requests_total",
+		Help: "Local cache lookups by result (hit, miss).",
+	}, []string{"cache", "result"})
+
+	evictionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "localcache_evictions_total",
+		Help: "Local cache entries dropped by reason (expired, capacity).",
+	}, []string{"cache", "reason"})
+
+	entries = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "localcache_entries",
+		Help: "Entries held in a local cache.",
+	}, []string{"cache"})
+)
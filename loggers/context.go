@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Context keys set by middleware and read back by FromContext; see ctxutil
+const (
+	RequestIDKey = ctxutil.RequestIDKey
+	TraceIDKey   = ctxutil.TraceIDKey
+	UserIDKey    = ctxutil.UserIDKey
+	TenantIDKey  = ctxutil.TenantIDKey
+	loggerKey    = "logger"
+)
+
+type ctxKey struct{}
+
+// WithContext returns a copy of ctx carrying l
+func WithContext(ctx context.Context, l *zap.Logger) context.Context {
+	if ginCtx, ok := ctx.(*gin.Context); ok {
+		ginCtx.Set(loggerKey, l)
+		return ginCtx
+	}
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx (or the global one) enriched with the
+// request_id, trace_id, user_id and tenant_id found in ctx
+func FromContext(ctx context.Context) *zap.Logger {
+	if ctx == nil {
+		return L()
+	}
+
+	l := L()
+	if ginCtx, ok := ctx.(*gin.Context); ok {
+		if v, exists := ginCtx.Get(loggerKey); exists {
+			if stored, ok := v.(*zap.Logger); ok {
+				l = stored
+			}
+		}
+	} else if stored, ok := ctx.Value(ctxKey{}).(*zap.Logger); ok {
+		l = stored
+	}
+
+	var fields []zap.Field
+	if v := ctxutil.RequestID(ctx); v != "" {
+		fields = append(fields, zap.String(RequestIDKey, v))
+	}
+	if v := ctxutil.TraceID(ctx); v != "" {
+		fields = append(fields, zap.String(TraceIDKey, v))
+	}
+	if v, ok := ctxutil.UserID(ctx); ok {
+		fields = append(fields, zap.String(UserIDKey, strconv.FormatUint(v, 10)))
+	}
+	if v := ctxutil.TenantID(ctx); v != "" {
+		fields = append(fields, zap.String(TenantIDKey, v))
+	}
+	return l.With(fields...)
+}
+
+// Middleware stores the trace ID on the context and logs one line per request.
+// Place it after RequestIDMiddleware so the request ID is already available.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		// Tracing middleware may already have set the id of the active span
+		if ctxutil.TraceID(c) == "" {
+			if traceID := traceIDFromHeaders(c); traceID != "" {
+				ctxutil.WithTraceID(c, traceID)
+			}
+		}
+		if tenantID := c.GetHeader("X-Tenant-ID"); tenantID != "" {
+			ctxutil.WithTenantID(c, tenantID)
+		}
+
+		c.Next()
+
+		l := FromContext(c)
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.FullPath()),
+			zap.Int("status", c.Writer.Status()),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("client_ip", c.ClientIP()),
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("errors", c.Errors.String()))
+		}
+
+		switch status := c.Writer.Status(); {
+		case status >= 500:
+			l.Error("request completed", fields...)
+		case status >= 400:
+			l.Warn("request completed", fields...)
+		default:
+			l.Info("request completed", fields...)
+		}
+	}
+}
+
+// traceIDFromHeaders reads the trace ID from a W3C traceparent header or X-Trace-ID
+func traceIDFromHeaders(c *gin.Context) string {
+	if tp := c.GetHeader("traceparent"); tp != "" {
+		// version-traceid-spanid-flags
+		parts := strings.Split(tp, "-")
+		if len(parts) == 4 && len(parts[1]) == 32 {
+			return parts[1]
+		}
+	}
+	return c.GetHeader("X-Trace-ID")
+}
@@ -0,0 +1,292 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/joho/godotenv"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	globalLevel  = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	moduleLevels = make(map[string]zapcore.Level)
+	levelsMu     sync.RWMutex
+)
+
+// SetLevel changes the global log level at runtime, e.g. SetLevel("debug")
+func SetLevel(level string) error {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	globalLevel.SetLevel(lvl)
+	return nil
+}
+
+// GetLevel returns the current global log level
+func GetLevel() string {
+	return globalLevel.Level().String()
+}
+
+// SetModuleLevel overrides the level for a single module, e.g. SetModuleLevel("httpclient", "debug")
+func SetModuleLevel(module, level string) error {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	levelsMu.Lock()
+	moduleLevels[module] = lvl
+	levelsMu.Unlock()
+	return nil
+}
+
+// ClearModuleLevel removes a module override so the module follows the global level again
+func ClearModuleLevel(module string) {
+	levelsMu.Lock()
+	delete(moduleLevels, module)
+	levelsMu.Unlock()
+}
+
+// ModuleLevels returns a copy of the current module overrides
+func ModuleLevels() map[string]string {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+
+	levels := make(map[string]string, len(moduleLevels))
+	for module, lvl := range moduleLevels {
+		levels[module] = lvl.String()
+	}
+	return levels
+}
+
+// Module returns a named logger whose level can be overridden independently of the global level
+func Module(name string) *zap.Logger {
+	return L().Named(name).WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &moduleCore{Core: core, module: name}
+	}))
+}
+
+// levelFor returns the effective level for a module
+func levelFor(module string) zapcore.Level {
+	if module != "" {
+		levelsMu.RLock()
+		lvl, ok := moduleLevels[module]
+		levelsMu.RUnlock()
+		if ok {
+			return lvl
+		}
+	}
+	return globalLevel.Level()
+}
+
+// moduleCore filters entries using the module override (or the global level)
+type moduleCore struct {
+	zapcore.Core
+	module string
+}
+
+func (c *moduleCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= levelFor(c.module)
+}
+
+func (c *moduleCore) With(fields []zapcore.Field) zapcore.Core {
+	return &moduleCore{Core: c.Core.With(fields), module: c.module}
+}
+
+func (c *moduleCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// applyLevelConfig applies a global level and "module=level,module=level" overrides
+func applyLevelConfig(level, modules string) error {
+	if level != "" {
+		if err := SetLevel(level); err != nil {
+			return err
+		}
+	}
+
+	if modules == "" {
+		return nil
+	}
+	for _, pair := range strings.Split(modules, ",") {
+		module, lvl, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || module == "" {
+			return fmt.Errorf("invalid module level %q, expected module=level", pair)
+		}
+		if err := SetModuleLevel(strings.TrimSpace(module), strings.TrimSpace(lvl)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WatchSignals reloads LOG_LEVEL and LOG_MODULE_LEVELS from .env (falling back to the
+// process environment) whenever the process receives SIGHUP, until ctx is cancelled
+func WatchSignals(ctx context.Context) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(sigCh)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigCh:
+				level, modules := os.Getenv("LOG_LEVEL"), os.Getenv("LOG_MODULE_LEVELS")
+				if values, err := godotenv.Read(); err == nil {
+					if v, ok := values["LOG_LEVEL"]; ok {
+						level = v
+					}
+					if v, ok := values["LOG_MODULE_LEVELS"]; ok {
+						modules = v
+					}
+				}
+				if err := applyLevelConfig(level, modules); err != nil {
+					L().Warn("failed to reload log levels", zap.Error(err))
+					continue
+				}
+				L().Info("log levels reloaded", zap.String("level", GetLevel()), zap.Any("modules", ModuleLevels()))
+			}
+		}
+	}()
+}
+
+// WatchRedis polls a Redis hash (field "level" for the global level, other fields are module names)
+// and applies changes, so levels can be flipped fleet-wide with a single HSET
+func WatchRedis(ctx context.Context, rdb *goredis.Client, key string, interval time.Duration) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		applied := make(map[string]string)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				values, err := rdb.HGetAll(ctx, key).Result()
+				if err != nil {
+					L().Debug("failed to read log levels from redis", zap.String("key", key), zap.Error(err))
+					continue
+				}
+				applyRedisLevels(values, applied)
+			}
+		}
+	}()
+}
+
+// applyRedisLevels applies the hash values and reverts module overrides that were removed from it
+func applyRedisLevels(values, applied map[string]string) {
+	for field, level := range values {
+		if applied[field] == level {
+			continue
+		}
+
+		var err error
+		if field == "level" {
+			err = SetLevel(level)
+		} else {
+			err = SetModuleLevel(field, level)
+		}
+		if err != nil {
+			L().Warn("invalid log level in redis", zap.String("field", field), zap.String("level", level), zap.Error(err))
+			continue
+		}
+		applied[field] = level
+		L().Info("log level changed", zap.String("field", field), zap.String("level", level))
+	}
+
+	for field := range applied {
+		if _, ok := values[field]; ok {
+			continue
+		}
+		if field != "level" {
+			ClearModuleLevel(field)
+		}
+		delete(applied, field)
+	}
+}
+
+// levelRequest is the body accepted by LevelHandler
+type levelRequest struct {
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules"`
+}
+
+// levelState is returned by LevelHandler
+type levelState struct {
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules"`
+}
+
+// LevelHandler serves GET (current levels) and PUT (change levels) for an admin endpoint.
+// An empty module level removes the override. Mount it behind service auth / trusted IPs.
+func LevelHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == "GET" {
+			response.OK(c, levelState{Level: GetLevel(), Modules: ModuleLevels()})
+			return
+		}
+
+		var req levelRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request", response.ProcessBindingError(c, err))
+			return
+		}
+
+		if req.Level != "" {
+			if err := SetLevel(req.Level); err != nil {
+				response.BadRequest(c, err.Error())
+				return
+			}
+		}
+
+		modules := make([]string, 0, len(req.Modules))
+		for module := range req.Modules {
+			modules = append(modules, module)
+		}
+		sort.Strings(modules)
+		for _, module := range modules {
+			if req.Modules[module] == "" {
+				ClearModuleLevel(module)
+				continue
+			}
+			if err := SetModuleLevel(module, req.Modules[module]); err != nil {
+				response.BadRequest(c, err.Error())
+				return
+			}
+		}
+
+		FromContext(c).Info("log levels changed via admin endpoint", zap.String("level", GetLevel()), zap.Any("modules", ModuleLevels()))
+		response.OK(c, levelState{Level: GetLevel(), Modules: ModuleLevels()})
+	}
+}
+
+// parseLevel converts a level name into a zapcore.Level
+func parseLevel(level string) (zapcore.Level, error) {
+	var lvl zapcore.Level
+	if err := lvl.UnmarshalText([]byte(strings.ToLower(strings.TrimSpace(level)))); err != nil {
+		return lvl, fmt.Errorf("invalid log level %q", level)
+	}
+	return lvl, nil
+}
@@ -1,19 +1,100 @@
 package logger
 
 import (
+	"os"
+
+	"github.com/Masharah-Advisory/common/buildinfo"
+	"github.com/Masharah-Advisory/common/config"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 var Log *zap.Logger
 
+// Config controls how the shared logger is built
+type Config struct {
+	Service string
+	Env     config.Environment
+}
+
+// InitLogger initializes the global logger using SERVICE_ID and the detected environment
 func InitLogger() {
-	var err error
-	Log, err = zap.NewProduction()
-	if err != nil {
+	if err := Init(Config{Service: os.Getenv("SERVICE_ID"), Env: config.Env()}); err != nil {
 		panic(err)
 	}
 }
 
+// Init builds the global logger from cfg
+func Init(cfg Config) error {
+	l, err := New(cfg)
+	if err != nil {
+		return err
+	}
+	Log = l
+	return nil
+}
+
+// New builds a logger tagged with service, env and build version, for services that inject their own
+func New(cfg Config) (*zap.Logger, error) {
+	if cfg.Env == "" {
+		cfg.Env = config.Env()
+	}
+
+	var zapCfg zap.Config
+	if cfg.Env == config.Development {
+		zapCfg = zap.NewDevelopmentConfig()
+		globalLevel.SetLevel(zapcore.DebugLevel)
+	} else {
+		zapCfg = zap.NewProductionConfig()
+	}
+	if err := applyLevelConfig(os.Getenv("LOG_LEVEL"), os.Getenv("LOG_MODULE_LEVELS")); err != nil {
+		return nil, err
+	}
+
+	// The encoder core accepts everything; filtering happens in moduleCore so levels can change at runtime
+	zapCfg.Level = zap.NewAtomicLevelAt(zapcore.DebugLevel)
+	l, err := zapCfg.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &moduleCore{Core: core}
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	info := buildinfo.Get()
+	fields := []zap.Field{
+		zap.String("env", cfg.Env.String()),
+		zap.String("version", info.Version),
+		zap.String("commit", info.Commit),
+	}
+	if cfg.Service != "" {
+		fields = append(fields, zap.String("service", cfg.Service))
+	}
+
+	return l.With(fields...), nil
+}
+
+// L returns the global logger, or a no-op logger when InitLogger has not been called
+func L() *zap.Logger {
+	if Log == nil {
+		return zap.NewNop()
+	}
+	return Log
+}
+
 func Sync() {
+	if Log == nil {
+		return
+	}
 	_ = Log.Sync()
 }
+
+// Tee adds an extra output core to the global logger (e.g. an OpenTelemetry log exporter);
+// entries are filtered by the same runtime levels as the primary output
+func Tee(core zapcore.Core) {
+	if Log == nil {
+		return
+	}
+	Log = Log.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(c, &moduleCore{Core: core})
+	}))
+}
@@ -0,0 +1,81 @@
+package longrunning
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Handlers exposes the standard job status endpoint
+type Handlers struct {
+	m *Manager
+}
+
+// NewHandlers creates the handlers
+func NewHandlers(m *Manager) *Handlers {
+	return &Handlers{m: m}
+}
+
+// Register mounts the endpoints on a router group matching Config.StatusPath, e.g. /jobs
+func (h *Handlers) Register(rg *gin.RouterGroup) {
+	rg.GET("/:id", h.Get)
+}
+
+// Get returns the status of one of the current user's jobs; while it is not done the answer
+// carries a Retry-After hint for pollers
+func (h *Handlers) Get(c *gin.Context) {
+	job, err := h.m.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if !visible(c, job) {
+		response.HandleError(c, ErrNotFound)
+		return
+	}
+	if !job.Status.Done() {
+		h.m.retryAfter(c)
+	}
+	response.OK(c, job)
+}
+
+// Accept starts a job of kind for the current request and answers 202 with the job, a
+// Location header pointing at its status and a Retry-After hint, e.g.
+//
+//	rg.POST("/reports", func(c *gin.Context) { jobs.Accept(c, "report.build", input) })
+func (m *Manager) Accept(c *gin.Context, kind string, input interface{}) {
+	job, err := m.Start(c, kind, input)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	m.Accepted(c, job)
+}
+
+// Accepted answers 202 for a job started with Start
+func (m *Manager) Accepted(c *gin.Context, job *Job) {
+	c.Header("Location", m.cfg.StatusPath+"/"+job.ID)
+	m.retryAfter(c)
+	response.Accepted(c, job, i18n.T(c, "jobs.accepted"))
+}
+
+func (m *Manager) retryAfter(c *gin.Context) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(m.cfg.PollAfter.Seconds()))))
+}
+
+// visible reports whether the current user may see the job: jobs started without a user are
+// visible to their tenant, others only to the user who started them
+func visible(c *gin.Context, job *Job) bool {
+	if job.TenantID != ctxutil.TenantID(c) {
+		return false
+	}
+	if job.UserID == 0 {
+		return true
+	}
+	userID, _ := ctxutil.UserID(c)
+	return userID == job.UserID
+}
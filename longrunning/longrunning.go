@@ -0,0 +1,281 @@
+package longrunning
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/idgen"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/storage"
+	"github.com/Masharah-Advisory/common/worker"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CleanupJob is the worker job deleting finished jobs past their retention
+const CleanupJob = "longrunning.cleanup"
+
+// Errors returned by the manager
+var (
+	ErrNotFound = apperror.New("job_not_found", apperror.KindNotFound, "job not found").
+			WithKey("jobs.not_found")
+	ErrUnknownKind = errors.New("unknown job kind")
+)
+
+// Func performs the work of a job; report progress and the result through run. Returning a
+// retry.Permanent error fails the job without retries.
+type Func func(ctx context.Context, run *Run) error
+
+// Config holds the manager settings
+type Config struct {
+	DB     *gorm.DB
+	Worker *worker.Manager
+	// Storage presigns result keys set with Run.SetResultKey; optional
+	Storage         storage.Storage
+	LinkExpiry      time.Duration // presigned result link lifetime, defaults to 1h
+	Retention       time.Duration // how long finished jobs are kept, defaults to 7 days
+	CleanupInterval time.Duration // defaults to 1h
+	// PollAfter is sent as Retry-After while a job is not done, defaults to 2s
+	PollAfter time.Duration
+	// StatusPath is the path the status handler is mounted on, used for the Location header
+	// of 202 answers; defaults to "/jobs"
+	StatusPath string
+}
+
+// Manager persists jobs and runs them on the worker queue
+type Manager struct {
+	cfg *Config
+	log *zap.Logger
+
+	mu    sync.RWMutex
+	kinds map[string]bool
+}
+
+type taskPayload struct {
+	JobID string `json:"job_id"`
+}
+
+// New creates a manager and schedules the cleanup of finished jobs; migrate Job first
+func New(cfg *Config) *Manager {
+	if cfg.LinkExpiry <= 0 {
+		cfg.LinkExpiry = time.Hour
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = 7 * 24 * time.Hour
+	}
+	if cfg.CleanupInterval <= 0 {
+		cfg.CleanupInterval = time.Hour
+	}
+	if cfg.PollAfter <= 0 {
+		cfg.PollAfter = 2 * time.Second
+	}
+	if cfg.StatusPath == "" {
+		cfg.StatusPath = "/jobs"
+	}
+
+	m := &Manager{cfg: cfg, log: logger.Module("longrunning"), kinds: make(map[string]bool)}
+	cfg.Worker.Every(CleanupJob, cfg.CleanupInterval, func(ctx context.Context) error {
+		_, err := m.Cleanup(ctx)
+		return err
+	}, worker.JobOptions{})
+	return m
+}
+
+// Register adds a job kind, processed by the worker job "longrunning.<kind>" with opts
+func (m *Manager) Register(kind string, fn Func, opts worker.JobOptions) {
+	m.mu.Lock()
+	m.kinds[kind] = true
+	m.mu.Unlock()
+
+	m.cfg.Worker.Register(jobName(kind), func(ctx context.Context, task *worker.Task) error {
+		var p taskPayload
+		if err := task.Decode(&p); err != nil {
+			return err
+		}
+		return m.process(ctx, p.JobID, fn)
+	}, opts)
+}
+
+// Start persists a pending job of kind and queues it. Tenant, user and request IDs are taken
+// from ctx, which may be a *gin.Context, and restored while the job runs.
+func (m *Manager) Start(ctx context.Context, kind string, input interface{}) (*Job, error) {
+	m.mu.RLock()
+	ok := m.kinds[kind]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKind, kind)
+	}
+
+	job := &Job{
+		ID:        idgen.NewULIDString(),
+		Kind:      kind,
+		Status:    StatusPending,
+		TenantID:  ctxutil.TenantID(ctx),
+		RequestID: ctxutil.RequestID(ctx),
+	}
+	job.UserID, _ = ctxutil.UserID(ctx)
+	if input != nil {
+		raw, err := json.Marshal(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode job input: %w", err)
+		}
+		job.Input = raw
+	}
+	if err := m.cfg.DB.WithContext(ctx).Create(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	if _, err := m.cfg.Worker.Enqueue(ctx, jobName(kind), taskPayload{JobID: job.ID}); err != nil {
+		m.finish(ctx, job, err)
+		return nil, fmt.Errorf("failed to queue job: %w", err)
+	}
+	return job, nil
+}
+
+// Get returns a job, with a fresh link to its result file when it has one
+func (m *Manager) Get(ctx context.Context, id string) (*Job, error) {
+	job, err := m.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == StatusSucceeded && job.ResultKey != "" && m.cfg.Storage != nil {
+		url, err := m.cfg.Storage.PresignGet(ctx, job.ResultKey, m.cfg.LinkExpiry)
+		if err != nil {
+			return nil, err
+		}
+		job.ResultURL = url
+	}
+	return job, nil
+}
+
+// Cleanup deletes finished jobs past the retention period, and their result files, returning
+// how many were removed
+func (m *Manager) Cleanup(ctx context.Context) (int, error) {
+	var jobs []Job
+	err := m.cfg.DB.WithContext(ctx).
+		Where("completed_at < ?", time.Now().Add(-m.cfg.Retention)).
+		Limit(500).
+		Find(&jobs).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load expired jobs: %w", err)
+	}
+
+	removed := 0
+	for i := range jobs {
+		job := &jobs[i]
+		if job.ResultKey != "" && m.cfg.Storage != nil {
+			if err := m.cfg.Storage.Delete(ctx, job.ResultKey); err != nil && !storage.IsNotFound(err) {
+				m.log.Warn("failed to delete job result", zap.String("job_id", job.ID), zap.Error(err))
+				continue
+			}
+		}
+		if err := m.cfg.DB.WithContext(ctx).Delete(job).Error; err != nil {
+			return removed, fmt.Errorf("failed to delete job: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// process runs one attempt of a job; finished jobs are left alone so redelivered tasks are
+// harmless
+func (m *Manager) process(ctx context.Context, id string, fn Func) error {
+	job, err := m.load(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		m.log.Warn("job no longer exists", zap.String("job_id", id))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if job.Status == StatusSucceeded {
+		return nil
+	}
+
+	now := time.Now()
+	job.Attempts++
+	err = m.cfg.DB.WithContext(ctx).Model(job).Updates(map[string]interface{}{
+		"status":       StatusRunning,
+		"attempts":     job.Attempts,
+		"error":        "",
+		"started_at":   now,
+		"completed_at": nil,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	job.Status, job.StartedAt = StatusRunning, &now
+
+	if job.TenantID != "" {
+		ctx = ctxutil.WithTenantID(ctx, job.TenantID)
+	}
+	if job.UserID != 0 {
+		ctx = ctxutil.WithUserID(ctx, job.UserID)
+	}
+	if job.RequestID != "" {
+		ctx = ctxutil.WithRequestID(ctx, job.RequestID)
+	}
+
+	run := &Run{Job: job, m: m}
+	err = m.safeRun(ctx, fn, run)
+	m.finish(ctx, job, err)
+	return err
+}
+
+// safeRun converts a panic in fn into an error
+func (m *Manager) safeRun(ctx context.Context, fn Func, run *Run) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return fn(ctx, run)
+}
+
+// finish records the outcome of an attempt; a failed attempt may be retried by the worker
+func (m *Manager) finish(ctx context.Context, job *Job, cause error) {
+	now := time.Now()
+	updates := map[string]interface{}{"completed_at": now}
+	if cause != nil {
+		msg := cause.Error()
+		if len(msg) > 1024 {
+			msg = msg[:1024]
+		}
+		updates["status"], updates["error"] = StatusFailed, msg
+	} else {
+		updates["status"], updates["progress"] = StatusSucceeded, 100
+		updates["result_url"], updates["result_key"] = job.ResultURL, job.ResultKey
+		if job.Result != nil {
+			updates["result"] = string(job.Result)
+		}
+		if job.Message != "" {
+			updates["message"] = job.Message
+		}
+	}
+	err := m.cfg.DB.WithContext(context.WithoutCancel(ctx)).Model(job).Updates(updates).Error
+	if err != nil {
+		m.log.Error("failed to record job outcome", zap.String("job_id", job.ID), zap.Error(err))
+	}
+}
+
+func (m *Manager) load(ctx context.Context, id string) (*Job, error) {
+	var job Job
+	err := m.cfg.DB.WithContext(ctx).Where("id = ?", id).First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job: %w", err)
+	}
+	return &job, nil
+}
+
+func jobName(kind string) string {
+	return "longrunning." + kind
+}
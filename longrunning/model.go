@@ -0,0 +1,49 @@
+package longrunning
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Status is the lifecycle state of a job
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Done reports whether the job reached a final status
+func (s Status) Done() bool {
+	return s == StatusSucceeded || s == StatusFailed
+}
+
+// Job tracks one long running operation from the 202 answer to its result. Clients poll it
+// until Status is final, then follow ResultURL or read Result.
+type Job struct {
+	ID          string          `json:"id" gorm:"primaryKey;size:64"`
+	Kind        string          `json:"kind" gorm:"index;size:128;not null"`
+	Status      Status          `json:"status" gorm:"index;size:16;not null"`
+	Progress    int             `json:"progress"`
+	Message     string          `json:"message,omitempty" gorm:"size:512"`
+	Input       json.RawMessage `json:"-" gorm:"serializer:json;type:text"`
+	Result      json.RawMessage `json:"result,omitempty" gorm:"serializer:json;type:text"`
+	ResultURL   string          `json:"result_url,omitempty" gorm:"size:1024"`
+	ResultKey   string          `json:"-" gorm:"size:512"`
+	Error       string          `json:"error,omitempty" gorm:"size:1024"`
+	Attempts    int             `json:"attempts"`
+	TenantID    string          `json:"tenant_id,omitempty" gorm:"index;size:64"`
+	UserID      uint64          `json:"user_id,omitempty" gorm:"index"`
+	RequestID   string          `json:"request_id,omitempty" gorm:"size:128"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty" gorm:"index"`
+}
+
+// TableName overrides the table name
+func (Job) TableName() string {
+	return "longrunning_jobs"
+}
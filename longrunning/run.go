@@ -0,0 +1,66 @@
+package longrunning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Run is handed to a Func to read its input and report progress and the result
+type Run struct {
+	Job *Job
+	m   *Manager
+}
+
+// Decode unmarshals the job input into v
+func (r *Run) Decode(v interface{}) error {
+	if len(r.Job.Input) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(r.Job.Input, v); err != nil {
+		return fmt.Errorf("failed to decode %s job input: %w", r.Job.Kind, err)
+	}
+	return nil
+}
+
+// Progress records the completion percentage, clamped to 0-99 until the job succeeds, and an
+// optional status message shown to pollers
+func (r *Run) Progress(ctx context.Context, percent int, message string) error {
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 99 {
+		percent = 99
+	}
+	r.Job.Progress, r.Job.Message = percent, message
+	err := r.m.cfg.DB.WithContext(ctx).Model(r.Job).Updates(map[string]interface{}{
+		"progress":   percent,
+		"message":    message,
+		"updated_at": time.Now(),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update job progress: %w", err)
+	}
+	return nil
+}
+
+// SetResult stores v as the JSON result returned to pollers once the job succeeds
+func (r *Run) SetResult(v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode job result: %w", err)
+	}
+	r.Job.Result = raw
+	return nil
+}
+
+// SetResultURL sets the location of the result, e.g. a resource path
+func (r *Run) SetResultURL(url string) {
+	r.Job.ResultURL = url
+}
+
+// SetResultKey sets the storage key of a result file; pollers get a fresh presigned link to it
+func (r *Run) SetResultKey(key string) {
+	r.Job.ResultKey = key
+}
@@ -0,0 +1,55 @@
+package lookups
+
+import (
+	"strings"
+
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Mount serves the lookups on a router group, e.g. /lookups; they are the same for every user,
+// so the group may stay public
+func Mount(rg *gin.RouterGroup) {
+	rg.GET("", Handler)
+	rg.GET("/:name", ItemHandler)
+}
+
+// Handler returns the lookups listed in ?names=a,b, or all of them, as a name to items object
+// labelled in the request language
+func Handler(c *gin.Context) {
+	lang := i18n.Lang(c)
+	lookups := all(lang)
+	out := lookups
+	if raw := c.Query("names"); raw != "" {
+		out = make(map[string][]Labeled)
+		for _, name := range strings.Split(raw, ",") {
+			name = strings.TrimSpace(name)
+			items, ok := lookups[name]
+			if !ok {
+				response.NotFound(c, i18n.T(c, "lookups.not_found", map[string]interface{}{"Name": name}))
+				return
+			}
+			out[name] = items
+		}
+	}
+	cacheable(c)
+	response.OK(c, out)
+}
+
+// ItemHandler returns the items of the lookup named by the :name parameter
+func ItemHandler(c *gin.Context) {
+	items, ok := Items(i18n.Lang(c), c.Param("name"))
+	if !ok {
+		response.NotFound(c, i18n.T(c, "lookups.not_found", map[string]interface{}{"Name": c.Param("name")}))
+		return
+	}
+	cacheable(c)
+	response.OK(c, items)
+}
+
+// cacheable lets clients and proxies keep the labels per language; they change with deploys only
+func cacheable(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Header("Vary", "Accept-Language, X-Language")
+}
@@ -0,0 +1,140 @@
+// Package lookups registers enums and lookup tables once, with the i18n keys of their labels,
+// and serves them localized so clients stop hard-coding display names.
+package lookups
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// Option is one value of a lookup
+type Option struct {
+	Value string
+	// Key is the i18n key of the label
+	Key string
+}
+
+// Labeled is a value with its label in the request language; use it as a DTO field so
+// clients get both, e.g. Status lookups.Labeled `json:"status"`
+type Labeled struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+type table struct {
+	options []Option
+	keys    map[string]string
+}
+
+var (
+	mu     sync.RWMutex
+	tables = make(map[string]*table)
+
+	// localized memoizes every table per language; registering a table resets it
+	localized sync.Map // lang -> map[string][]Labeled
+)
+
+// Register adds a lookup with its options in display order; call it from a package-level var
+// or init so every lookup is known at startup, e.g. for a table loaded from the database. It
+// panics when the name is registered twice or a value repeats.
+func Register(name string, options ...Option) {
+	t := &table{options: append([]Option(nil), options...), keys: make(map[string]string, len(options))}
+	for _, o := range options {
+		if _, dup := t.keys[o.Value]; dup {
+			panic(fmt.Sprintf("lookups: %s lists %q twice", name, o.Value))
+		}
+		t.keys[o.Value] = o.Key
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := tables[name]; ok {
+		panic("lookups: duplicate lookup " + name)
+	}
+	tables[name] = t
+	localized.Clear()
+}
+
+// Enum registers the values of a string enum labelled by keyPrefix + "." + value, e.g.
+//
+//	lookups.Enum("case_status", "case.status", StatusOpen, StatusClosed)
+//
+// labels StatusOpen with "case.status.open"
+func Enum[T ~string](name, keyPrefix string, values ...T) {
+	options := make([]Option, len(values))
+	for i, v := range values {
+		options[i] = Option{Value: string(v), Key: keyPrefix + "." + string(v)}
+	}
+	Register(name, options...)
+}
+
+// Names returns the registered lookups, sorted
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Items returns the options of a lookup labelled in lang; false when it is not registered
+func Items(lang, name string) ([]Labeled, bool) {
+	items, ok := all(lang)[name]
+	return items, ok
+}
+
+// Label returns value with its label in the request language, for DTOs
+func Label(c *gin.Context, name, value string) Labeled {
+	return Labeled{Value: value, Label: LabelLang(i18n.Lang(c), name, value)}
+}
+
+// LabelLang returns the label of a value in lang, for code running outside a request; values
+// the lookup does not list, or whose key has no translation, are returned as they are
+func LabelLang(lang, name, value string) string {
+	mu.RLock()
+	t, ok := tables[name]
+	mu.RUnlock()
+	if !ok {
+		return value
+	}
+	key, ok := t.keys[value]
+	if !ok {
+		return value
+	}
+	return translate(lang, key, value)
+}
+
+// all returns every lookup labelled in lang
+func all(lang string) map[string][]Labeled {
+	if v, ok := localized.Load(lang); ok {
+		return v.(map[string][]Labeled)
+	}
+	mu.RLock()
+	out := make(map[string][]Labeled, len(tables))
+	for name, t := range tables {
+		items := make([]Labeled, len(t.options))
+		for i, o := range t.options {
+			items[i] = Labeled{Value: o.Value, Label: translate(lang, o.Key, o.Value)}
+		}
+		out[name] = items
+	}
+	mu.RUnlock()
+	localized.Store(lang, out)
+	return out
+}
+
+// translate falls back to the value when the key has no translation, since i18n returns the
+// key itself
+func translate(lang, key, value string) string {
+	if label := i18n.TLang(lang, key); label != key {
+		return label
+	}
+	return value
+}
@@ -0,0 +1,65 @@
+package metering
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Aggregate recomputes the monthly rows of the month containing t from the hourly rows; it is
+// idempotent, so it can run as often as needed while the month is open
+func (m *Meter) Aggregate(ctx context.Context, t time.Time) error {
+	start := monthStart(t)
+	err := m.cfg.DB.WithContext(ctx).Exec(`
+		INSERT INTO usage_monthly (tenant_id, consumer, route, month, calls, errors, updated_at)
+		SELECT tenant_id, consumer, route, ?, SUM(calls), SUM(errors), ?
+		FROM usage_hourly
+		WHERE hour >= ? AND hour < ?
+		GROUP BY tenant_id, consumer, route
+		ON CONFLICT (tenant_id, consumer, route, month) DO UPDATE
+		SET calls = EXCLUDED.calls, errors = EXCLUDED.errors, updated_at = EXCLUDED.updated_at`,
+		start, m.now().UTC(), start, start.AddDate(0, 1, 0),
+	).Error
+	if err != nil {
+		return fmt.Errorf("failed to aggregate usage for %s: %w", start.Format("2006-01"), err)
+	}
+	return nil
+}
+
+// Prune deletes hourly rows older than the retention; months are aggregated first so no calls
+// are lost from the monthly totals
+func (m *Meter) Prune(ctx context.Context) (int64, error) {
+	cutoff := m.now().Add(-m.cfg.Retention)
+	res := m.cfg.DB.WithContext(ctx).Where("hour < ?", cutoff).Delete(&Hourly{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to prune hourly usage: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// aggregateRecent refreshes the current and the previous month, which still receives late
+// flushes right after the month turns, then prunes
+func (m *Meter) aggregateRecent(ctx context.Context) error {
+	now := m.now()
+	if err := m.Aggregate(ctx, monthStart(now).AddDate(0, -1, 0)); err != nil {
+		return err
+	}
+	if err := m.Aggregate(ctx, now); err != nil {
+		return err
+	}
+	n, err := m.Prune(ctx)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		m.log.Info("pruned hourly usage", zap.Int64("rows", n))
+	}
+	return nil
+}
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
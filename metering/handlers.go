@@ -0,0 +1,87 @@
+package metering
+
+import (
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Handlers exposes the usage of the tenant on the context
+type Handlers struct {
+	meter *Meter
+}
+
+// NewHandlers creates the handlers
+func NewHandlers(meter *Meter) *Handlers {
+	return &Handlers{meter: meter}
+}
+
+// RegisterAdmin mounts the reporting endpoints; protect the group with a permission
+func (h *Handlers) RegisterAdmin(rg *gin.RouterGroup) {
+	rg.GET("", h.Series)
+	rg.GET("/routes", h.Routes)
+	rg.GET("/consumers", h.Consumers)
+}
+
+// Series returns the tenant's usage per ?granularity= (hour, day or month, default day)
+func (h *Handlers) Series(c *gin.Context) {
+	q, ok := bindQuery(c)
+	if !ok {
+		return
+	}
+	g := Granularity(c.DefaultQuery("granularity", string(Day)))
+	if g != Hour && g != Day && g != Month {
+		response.BadRequest(c, i18n.T(c, "metering.invalid_request"))
+		return
+	}
+	points, err := h.meter.Series(c.Request.Context(), q, g)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if points == nil {
+		points = []Point{}
+	}
+	response.OK(c, points)
+}
+
+// Routes returns the tenant's usage per route
+func (h *Handlers) Routes(c *gin.Context) {
+	q, ok := bindQuery(c)
+	if !ok {
+		return
+	}
+	h.respond(c, func() ([]Usage, error) { return h.meter.ByRoute(c.Request.Context(), q) })
+}
+
+// Consumers returns the tenant's usage per consumer
+func (h *Handlers) Consumers(c *gin.Context) {
+	q, ok := bindQuery(c)
+	if !ok {
+		return
+	}
+	h.respond(c, func() ([]Usage, error) { return h.meter.ByConsumer(c.Request.Context(), q) })
+}
+
+func (h *Handlers) respond(c *gin.Context, load func() ([]Usage, error)) {
+	items, err := load()
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if items == nil {
+		items = []Usage{}
+	}
+	response.OK(c, items)
+}
+
+func bindQuery(c *gin.Context) (Query, bool) {
+	var q Query
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.BadRequest(c, i18n.T(c, "metering.invalid_request"), response.ProcessBindingError(c, err))
+		return q, false
+	}
+	q.TenantID = ctxutil.TenantID(c)
+	return q, true
+}
@@ -0,0 +1,257 @@
+package metering
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/crypto"
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/idgen"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/worker"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Worker jobs registered by NewMeter
+const (
+	FlushJob     = "metering.flush"
+	AggregateJob = "metering.aggregate"
+)
+
+// ConsumerFunc identifies who made a request, e.g. an API key or a calling service
+type ConsumerFunc func(c *gin.Context) string
+
+// Config configures a Meter
+type Config struct {
+	Redis  *redis.Client
+	DB     *gorm.DB
+	Prefix string // Redis key prefix, defaults to "metering:"
+	// Worker runs the flush and aggregation jobs (optional; call Flush and Aggregate yourself
+	// without it)
+	Worker *worker.Manager
+	// Consumer defaults to DefaultConsumer
+	Consumer ConsumerFunc
+	// Skip lists route patterns that are not metered, e.g. "/health"
+	Skip []string
+	// FlushInterval is how often counters move from Redis to Postgres, defaults to 1m
+	FlushInterval time.Duration
+	// AggregateInterval is how often the monthly rows are recomputed, defaults to 1h
+	AggregateInterval time.Duration
+	// Retention is how long hourly rows are kept, defaults to 90 days; monthly rows are kept
+	Retention time.Duration
+}
+
+// Meter counts API calls per tenant, consumer and route. Counts land in Redis hashes per hour
+// and are flushed to Postgres, so reports lag by up to FlushInterval.
+type Meter struct {
+	cfg  *Config
+	skip map[string]bool
+	log  *zap.Logger
+	now  func() time.Time
+}
+
+// NewMeter creates a meter and registers its jobs on cfg.Worker; migrate Hourly and Monthly first
+func NewMeter(cfg *Config) *Meter {
+	if cfg.Prefix == "" {
+		cfg.Prefix = "metering:"
+	}
+	if cfg.Consumer == nil {
+		cfg.Consumer = DefaultConsumer
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Minute
+	}
+	if cfg.AggregateInterval <= 0 {
+		cfg.AggregateInterval = time.Hour
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = 90 * 24 * time.Hour
+	}
+
+	m := &Meter{cfg: cfg, skip: make(map[string]bool), log: logger.Module("metering"), now: time.Now}
+	for _, route := range cfg.Skip {
+		m.skip[route] = true
+	}
+	if cfg.Worker != nil {
+		cfg.Worker.Every(FlushJob, cfg.FlushInterval, func(ctx context.Context) error {
+			_, err := m.Flush(ctx)
+			return err
+		}, worker.JobOptions{Timeout: cfg.FlushInterval})
+		cfg.Worker.Every(AggregateJob, cfg.AggregateInterval, m.aggregateRecent, worker.JobOptions{Timeout: 10 * time.Minute})
+	}
+	return m
+}
+
+// DefaultConsumer identifies the caller by API key (hashed), then calling service, then user
+func DefaultConsumer(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return "key:" + crypto.SHA256Hex([]byte(key))[:16]
+	}
+	if service := c.GetHeader("X-Service-ID"); service != "" {
+		return "service:" + service
+	}
+	if userID, ok := ctxutil.UserID(c); ok {
+		return "user:" + strconv.FormatUint(userID, 10)
+	}
+	return "anonymous"
+}
+
+// Middleware counts every matched request once the handler has run; responses with a status of
+// 400 or more also count as errors. Unmatched routes are not metered.
+func (m *Meter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" || m.skip[route] {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), time.Second)
+		defer cancel()
+		err := m.Record(ctx, ctxutil.TenantID(c), m.cfg.Consumer(c), c.Request.Method+" "+route, c.Writer.Status() >= http.StatusBadRequest)
+		if err != nil {
+			m.log.Warn("failed to meter request", zap.String("route", route), zap.Error(err))
+		}
+	}
+}
+
+// Record counts one call, e.g. for work metered outside HTTP such as a queued export
+func (m *Meter) Record(ctx context.Context, tenantID, consumer, route string, failed bool) error {
+	bucket := bucketID(m.now())
+	key := m.cfg.Prefix + "h:" + bucket
+	field := strings.Join([]string{tenantID, consumer, route}, sep)
+
+	pipe := m.cfg.Redis.Pipeline()
+	pipe.HIncrBy(ctx, key, "c"+sep+field, 1)
+	if failed {
+		pipe.HIncrBy(ctx, key, "e"+sep+field, 1)
+	}
+	// Counters are flushed within minutes; the expiry only bounds what a broken flush leaves behind
+	pipe.Expire(ctx, key, 7*24*time.Hour)
+	pipe.SAdd(ctx, m.cfg.Prefix+"buckets", bucket)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Flush moves the counters from Redis into the hourly table and returns the number of rows
+// written. Each bucket is renamed before it is read, so concurrent flushes from several
+// replicas never count a call twice.
+func (m *Meter) Flush(ctx context.Context) (int, error) {
+	buckets, err := m.cfg.Redis.SMembers(ctx, m.cfg.Prefix+"buckets").Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list usage buckets: %w", err)
+	}
+	sort.Strings(buckets)
+	current := bucketID(m.now())
+
+	written := 0
+	for _, bucket := range buckets {
+		n, err := m.flushBucket(ctx, bucket)
+		if err != nil {
+			return written, err
+		}
+		written += n
+		// A past hour gets no more calls; the current one stays listed for the next flush
+		if bucket != current {
+			exists, err := m.cfg.Redis.Exists(ctx, m.cfg.Prefix+"h:"+bucket).Result()
+			if err == nil && exists == 0 {
+				m.cfg.Redis.SRem(ctx, m.cfg.Prefix+"buckets", bucket)
+			}
+		}
+	}
+	return written, nil
+}
+
+func (m *Meter) flushBucket(ctx context.Context, bucket string) (int, error) {
+	hour, err := time.ParseInLocation(bucketLayout, bucket, time.UTC)
+	if err != nil {
+		m.cfg.Redis.SRem(ctx, m.cfg.Prefix+"buckets", bucket)
+		return 0, nil
+	}
+
+	key := m.cfg.Prefix + "h:" + bucket
+	tmp := key + ":flush:" + idgen.NewULIDString()
+	if err := m.cfg.Redis.Rename(ctx, key, tmp).Err(); err != nil {
+		if strings.Contains(err.Error(), "no such key") {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to claim usage bucket %s: %w", bucket, err)
+	}
+	fields, err := m.cfg.Redis.HGetAll(ctx, tmp).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read usage bucket %s: %w", bucket, err)
+	}
+
+	rows := make(map[string]*Hourly)
+	for field, value := range fields {
+		parts := strings.SplitN(field, sep, 4)
+		count, err := strconv.ParseInt(value, 10, 64)
+		if len(parts) != 4 || err != nil {
+			continue
+		}
+		id := strings.Join(parts[1:], sep)
+		row, ok := rows[id]
+		if !ok {
+			row = &Hourly{TenantID: parts[1], Consumer: parts[2], Route: parts[3], Hour: hour}
+			rows[id] = row
+		}
+		if parts[0] == "e" {
+			row.Errors += count
+		} else {
+			row.Calls += count
+		}
+	}
+
+	batch := make([]*Hourly, 0, len(rows))
+	for _, row := range rows {
+		batch = append(batch, row)
+	}
+	if len(batch) > 0 {
+		err = m.cfg.DB.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "tenant_id"}, {Name: "consumer"}, {Name: "route"}, {Name: "hour"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"calls":  gorm.Expr("usage_hourly.calls + EXCLUDED.calls"),
+				"errors": gorm.Expr("usage_hourly.errors + EXCLUDED.errors"),
+			}),
+		}).CreateInBatches(batch, 500).Error
+	}
+	if err != nil {
+		m.restore(ctx, key, fields)
+		m.cfg.Redis.Del(ctx, tmp)
+		return 0, fmt.Errorf("failed to save usage bucket %s: %w", bucket, err)
+	}
+	m.cfg.Redis.Del(ctx, tmp)
+	return len(batch), nil
+}
+
+// restore adds claimed counters back to the live bucket after a failed save
+func (m *Meter) restore(ctx context.Context, key string, fields map[string]string) {
+	pipe := m.cfg.Redis.Pipeline()
+	for field, value := range fields {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			pipe.HIncrBy(ctx, key, field, n)
+		}
+	}
+	pipe.Expire(ctx, key, 7*24*time.Hour)
+	if _, err := pipe.Exec(ctx); err != nil {
+		m.log.Error("failed to restore usage counters, calls were lost", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// sep separates the parts of a counter field; it cannot appear in tenant IDs or routes
+const sep = "\x1f"
+
+const bucketLayout = "2006010215"
+
+func bucketID(t time.Time) string {
+	return t.UTC().Format(bucketLayout)
+}
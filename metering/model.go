@@ -0,0 +1,34 @@
+package metering
+
+import "time"
+
+// Hourly is the number of calls a consumer made to a route of a tenant in one hour
+type Hourly struct {
+	TenantID string    `json:"tenant_id" gorm:"primaryKey;size:64"`
+	Consumer string    `json:"consumer" gorm:"primaryKey;size:128"`
+	Route    string    `json:"route" gorm:"primaryKey;size:255"`
+	Hour     time.Time `json:"hour" gorm:"primaryKey;index"`
+	Calls    int64     `json:"calls" gorm:"not null;default:0"`
+	Errors   int64     `json:"errors" gorm:"not null;default:0"`
+}
+
+// TableName overrides the table name
+func (Hourly) TableName() string {
+	return "usage_hourly"
+}
+
+// Monthly is Hourly rolled up per calendar month (UTC), the basis for billing
+type Monthly struct {
+	TenantID  string    `json:"tenant_id" gorm:"primaryKey;size:64"`
+	Consumer  string    `json:"consumer" gorm:"primaryKey;size:128"`
+	Route     string    `json:"route" gorm:"primaryKey;size:255"`
+	Month     time.Time `json:"month" gorm:"primaryKey;index"`
+	Calls     int64     `json:"calls" gorm:"not null;default:0"`
+	Errors    int64     `json:"errors" gorm:"not null;default:0"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (Monthly) TableName() string {
+	return "usage_monthly"
+}
@@ -0,0 +1,133 @@
+package metering
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Granularity is the period of a usage series
+type Granularity string
+
+const (
+	Hour  Granularity = "hour"
+	Day   Granularity = "day"
+	Month Granularity = "month"
+)
+
+// Query filters usage reports; empty fields match everything and a zero To means now
+type Query struct {
+	TenantID string    `form:"-"`
+	Consumer string    `form:"consumer"`
+	Route    string    `form:"route"`
+	From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// Point is the usage of one period
+type Point struct {
+	Period time.Time `json:"period"`
+	Calls  int64     `json:"calls"`
+	Errors int64     `json:"errors"`
+}
+
+// Usage is the usage of one route or consumer
+type Usage struct {
+	Key    string `json:"key"`
+	Calls  int64  `json:"calls"`
+	Errors int64  `json:"errors"`
+}
+
+// Series returns the usage matching q per period
+func (m *Meter) Series(ctx context.Context, q Query, g Granularity) ([]Point, error) {
+	switch g {
+	case Hour, Day, Month:
+	default:
+		return nil, fmt.Errorf("unsupported granularity %q", g)
+	}
+	var out []Point
+	err := m.filter(ctx, q).
+		Select("date_trunc(?, hour) AS period, SUM(calls) AS calls, SUM(errors) AS errors", string(g)).
+		Group("period").Order("period").
+		Scan(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage: %w", err)
+	}
+	return out, nil
+}
+
+// ByRoute returns the usage matching q per route, busiest first
+func (m *Meter) ByRoute(ctx context.Context, q Query) ([]Usage, error) {
+	return m.group(ctx, q, "route")
+}
+
+// ByConsumer returns the usage matching q per consumer, busiest first
+func (m *Meter) ByConsumer(ctx context.Context, q Query) ([]Usage, error) {
+	return m.group(ctx, q, "consumer")
+}
+
+// Total returns the number of calls and errors matching q, e.g. to enforce a fair-use quota
+func (m *Meter) Total(ctx context.Context, q Query) (Usage, error) {
+	var out Usage
+	err := m.filter(ctx, q).
+		Select("COALESCE(SUM(calls), 0) AS calls, COALESCE(SUM(errors), 0) AS errors").
+		Scan(&out).Error
+	if err != nil {
+		return out, fmt.Errorf("failed to query usage: %w", err)
+	}
+	return out, nil
+}
+
+// MonthToDate returns the calls a consumer of a tenant made since the start of the month
+func (m *Meter) MonthToDate(ctx context.Context, tenantID, consumer string) (int64, error) {
+	u, err := m.Total(ctx, Query{TenantID: tenantID, Consumer: consumer, From: monthStart(m.now())})
+	return u.Calls, err
+}
+
+// Report returns the aggregated rows of a month for billing, for one tenant or every tenant
+// when tenantID is empty
+func (m *Meter) Report(ctx context.Context, month time.Time, tenantID string) ([]Monthly, error) {
+	db := m.cfg.DB.WithContext(ctx).Where("month = ?", monthStart(month))
+	if tenantID != "" {
+		db = db.Where("tenant_id = ?", tenantID)
+	}
+	var out []Monthly
+	if err := db.Order("tenant_id, consumer, route").Find(&out).Error; err != nil {
+		return nil, fmt.Errorf("failed to load usage report: %w", err)
+	}
+	return out, nil
+}
+
+func (m *Meter) group(ctx context.Context, q Query, column string) ([]Usage, error) {
+	var out []Usage
+	err := m.filter(ctx, q).
+		Select(column + " AS key, SUM(calls) AS calls, SUM(errors) AS errors").
+		Group(column).Order("calls DESC").
+		Scan(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage: %w", err)
+	}
+	return out, nil
+}
+
+func (m *Meter) filter(ctx context.Context, q Query) *gorm.DB {
+	db := m.cfg.DB.WithContext(ctx).Model(&Hourly{})
+	if q.TenantID != "" {
+		db = db.Where("tenant_id = ?", q.TenantID)
+	}
+	if q.Consumer != "" {
+		db = db.Where("consumer = ?", q.Consumer)
+	}
+	if q.Route != "" {
+		db = db.Where("route = ?", q.Route)
+	}
+	if !q.From.IsZero() {
+		db = db.Where("hour >= ?", q.From.UTC().Truncate(time.Hour))
+	}
+	if !q.To.IsZero() {
+		db = db.Where("hour < ?", q.To.UTC())
+	}
+	return db
+}
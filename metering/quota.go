@@ -0,0 +1,244 @@
+package metering
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/events"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// ErrQuotaExceeded is returned, with 429, once a tenant used up its monthly quota
+var ErrQuotaExceeded = apperror.New("quota_exceeded", apperror.KindRateLimited, "monthly API quota exceeded").
+	WithKey("metering.quota_exceeded")
+
+// Types of the events published as a tenant uses up its quota
+const (
+	QuotaWarningEventType   = "metering.quota_warning"
+	QuotaExhaustedEventType = "metering.quota_exhausted"
+)
+
+// Headers set on every response of a tenant with a quota
+const (
+	HeaderQuotaLimit     = "X-Quota-Limit"
+	HeaderQuotaRemaining = "X-Quota-Remaining"
+	HeaderQuotaReset     = "X-Quota-Reset" // Unix time the quota resets at
+	HeaderQuotaWarning   = "X-Quota-Warning"
+)
+
+// LimitFunc returns the monthly call quota of a tenant; 0 or less means unlimited. It runs on
+// every request, so cache it when it reads the database.
+type LimitFunc func(ctx context.Context, tenantID string) (int64, error)
+
+// QuotaConfig configures a Quota
+type QuotaConfig struct {
+	Limit LimitFunc
+	// Warnings are the fractions of the quota from which responses carry X-Quota-Warning; a
+	// warning event is published once per month as each is crossed. Defaults to 0.8 and 0.9.
+	Warnings []float64
+	// Enforce refuses calls with 429 once the quota is used up; otherwise they only warn
+	Enforce bool
+	// Publisher receives the warning and exhausted events (optional)
+	Publisher events.Publisher
+	// Topic defaults to "metering.quota"
+	Topic string
+}
+
+// QuotaEvent is the payload of the quota events, e.g. to email the tenant's admins
+type QuotaEvent struct {
+	TenantID string    `json:"tenant_id"`
+	Month    time.Time `json:"month"`
+	Percent  int       `json:"percent"`
+	Used     int64     `json:"used"`
+	Limit    int64     `json:"limit"`
+}
+
+// Quota counts each tenant's calls of the month in Redis, warns as the tenant approaches its
+// quota and, when enforced, refuses calls beyond it. The counter is separate from the metered
+// usage so checking it costs one Redis call, without waiting for a flush.
+type Quota struct {
+	meter *Meter
+	cfg   QuotaConfig
+}
+
+// NewQuota creates a quota on the meter's Redis
+func NewQuota(meter *Meter, cfg QuotaConfig) *Quota {
+	if len(cfg.Warnings) == 0 {
+		cfg.Warnings = []float64{0.8, 0.9}
+	}
+	cfg.Warnings = append([]float64(nil), cfg.Warnings...)
+	sort.Float64s(cfg.Warnings)
+	if cfg.Topic == "" {
+		cfg.Topic = "metering.quota"
+	}
+	return &Quota{meter: meter, cfg: cfg}
+}
+
+// takeScript counts a call unless an enforced quota is used up, and starts the expiry on the
+// first call of the month
+var takeScript = redis.NewScript(`
+local n = tonumber(redis.call('GET', KEYS[1]) or '0')
+if ARGV[3] == '1' and n >= tonumber(ARGV[1]) then
+	return {n, 0}
+end
+n = redis.call('INCR', KEYS[1])
+if n == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return {n, 1}
+`)
+
+// Middleware counts the call against the tenant's quota and sets the quota headers. Place it
+// after the middleware resolving the tenant; calls without a tenant are not counted. Redis or
+// limit errors are logged and the call is let through.
+func (q *Quota) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID := ctxutil.TenantID(c)
+		if tenantID == "" {
+			c.Next()
+			return
+		}
+		ctx := c.Request.Context()
+		limit, err := q.cfg.Limit(ctx, tenantID)
+		if err != nil {
+			q.meter.log.Warn("failed to load quota", zap.String("tenant_id", tenantID), zap.Error(err))
+			c.Next()
+			return
+		}
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+
+		now := q.meter.now()
+		reset := monthStart(now).AddDate(0, 1, 0)
+		used, allowed, err := q.take(ctx, tenantID, limit, now)
+		if err != nil {
+			q.meter.log.Warn("failed to count quota", zap.String("tenant_id", tenantID), zap.Error(err))
+			c.Next()
+			return
+		}
+
+		c.Header(HeaderQuotaLimit, strconv.FormatInt(limit, 10))
+		c.Header(HeaderQuotaRemaining, strconv.FormatInt(max(limit-used, 0), 10))
+		c.Header(HeaderQuotaReset, strconv.FormatInt(reset.Unix(), 10))
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(reset.Sub(now).Seconds()))))
+			response.HandleError(c, ErrQuotaExceeded)
+			c.Abort()
+			return
+		}
+		if warning := q.warning(used, limit); warning != "" {
+			c.Header(HeaderQuotaWarning, warning)
+		}
+		q.notify(ctx, tenantID, used, limit, now)
+		c.Next()
+	}
+}
+
+// Used returns the calls a tenant made this month as counted by the quota
+func (q *Quota) Used(ctx context.Context, tenantID string) (int64, error) {
+	n, err := q.meter.cfg.Redis.Get(ctx, q.key(tenantID, q.meter.now())).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read quota usage: %w", err)
+	}
+	return n, nil
+}
+
+func (q *Quota) key(tenantID string, now time.Time) string {
+	return q.meter.cfg.Prefix + "quota:" + monthStart(now).Format("200601") + ":" + tenantID
+}
+
+// take counts one call and returns the calls of the month including it
+func (q *Quota) take(ctx context.Context, tenantID string, limit int64, now time.Time) (int64, bool, error) {
+	// Kept a week past the month so a late request does not restart the count
+	ttl := monthStart(now).AddDate(0, 1, 7).Sub(now)
+	enforce := "0"
+	if q.cfg.Enforce {
+		enforce = "1"
+	}
+	res, err := takeScript.Run(ctx, q.meter.cfg.Redis, []string{q.key(tenantID, now)}, limit, ttl.Milliseconds(), enforce).Slice()
+	if err != nil {
+		return 0, false, err
+	}
+	if len(res) != 2 {
+		return 0, false, fmt.Errorf("unexpected quota script result %v", res)
+	}
+	used, _ := res[0].(int64)
+	allowed, _ := res[1].(int64)
+	return used, allowed == 1, nil
+}
+
+// warning returns the X-Quota-Warning value for the usage, empty below the first threshold
+func (q *Quota) warning(used, limit int64) string {
+	switch {
+	case used > limit:
+		return "monthly quota exceeded"
+	case used == limit:
+		return "monthly quota used up"
+	}
+	for i := len(q.cfg.Warnings) - 1; i >= 0; i-- {
+		if used >= mark(q.cfg.Warnings[i], limit) {
+			return fmt.Sprintf("%d%% of monthly quota used", percent(q.cfg.Warnings[i]))
+		}
+	}
+	return ""
+}
+
+// notify publishes an event when this call crossed a threshold; counts are atomic, so exactly
+// one call per month lands on each mark
+func (q *Quota) notify(ctx context.Context, tenantID string, used, limit int64, now time.Time) {
+	if q.cfg.Publisher == nil {
+		return
+	}
+	eventType, fraction := "", 0.0
+	if used == limit {
+		eventType, fraction = QuotaExhaustedEventType, 1
+	} else {
+		for _, f := range q.cfg.Warnings {
+			if used == mark(f, limit) {
+				eventType, fraction = QuotaWarningEventType, f
+			}
+		}
+	}
+	if eventType == "" {
+		return
+	}
+
+	payload := QuotaEvent{TenantID: tenantID, Month: monthStart(now), Percent: percent(fraction), Used: used, Limit: limit}
+	event, err := events.NewEvent(ctx, eventType, payload)
+	if err != nil {
+		q.meter.log.Warn("failed to build quota event", zap.String("tenant_id", tenantID), zap.Error(err))
+		return
+	}
+	event.Key = tenantID
+	// Published in the background so the call never waits on the bus
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := q.cfg.Publisher.Publish(ctx, q.cfg.Topic, event); err != nil {
+			q.meter.log.Warn("failed to publish quota event", zap.String("tenant_id", tenantID), zap.Error(err))
+		}
+	}()
+}
+
+// mark is the call count at which a fraction of the quota is reached
+func mark(fraction float64, limit int64) int64 {
+	return int64(math.Ceil(fraction * float64(limit)))
+}
+
+func percent(fraction float64) int {
+	return int(math.Round(fraction * 100))
+}
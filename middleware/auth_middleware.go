@@ -6,11 +6,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Masharah-Advisory/common/ctxutil"
 	"github.com/Masharah-Advisory/common/i18n"
+	logger "github.com/Masharah-Advisory/common/loggers"
 	"github.com/Masharah-Advisory/common/response"
 	"github.com/Masharah-Advisory/common/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
 )
 
 type AuthResponse struct {
@@ -61,18 +64,32 @@ func AuthMiddleware(jwtSecret ...string) gin.HandlerFunc {
 		// Parse and validate JWT token locally
 		claims, err := parseJWTToken(tokenString, secret)
 		if err != nil {
+			logger.FromContext(c).Debug("jwt validation failed", zap.Error(err))
 			response.Unauthorized(c, i18n.T(c, "invalid_or_expired_token"))
 			c.Abort()
 			return
 		}
 
 		// Set user ID in context and header for downstream services
-		c.Set("user_id", claims.UserID)
+		ctxutil.WithUserID(c, claims.UserID)
 		c.Request.Header.Set(utils.XUserIDHeader, strconv.FormatUint(uint64(claims.UserID), 10))
 		c.Next()
 	}
 }
 
+// ParseToken validates a JWT the same way AuthMiddleware does, for transports that cannot use
+// the middleware (WebSocket upgrades, gRPC); jwtSecret defaults to utils.JWTSecret
+func ParseToken(tokenString string, jwtSecret ...string) (*Claims, error) {
+	secret := utils.JWTSecret
+	if len(jwtSecret) > 0 && jwtSecret[0] != "" {
+		secret = jwtSecret[0]
+	}
+	if secret == "" {
+		return nil, errors.New("jwt secret not configured")
+	}
+	return parseJWTToken(tokenString, secret)
+}
+
 // parseJWTToken parses and validates JWT token locally
 func parseJWTToken(tokenString, jwtSecret string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
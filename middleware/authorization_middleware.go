@@ -1,13 +1,19 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
-	"strconv"
+	"time"
 
+	"github.com/Masharah-Advisory/common/breaker"
+	"github.com/Masharah-Advisory/common/ctxutil"
 	"github.com/Masharah-Advisory/common/httpclient"
 	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/localcache"
+	logger "github.com/Masharah-Advisory/common/loggers"
 	"github.com/Masharah-Advisory/common/response"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 type AccessResponse struct {
@@ -21,50 +27,53 @@ type AccessData struct {
 }
 
 // Global service client - should be initialized once in main.go
-var serviceClient *httpclient.ServiceClient
+var serviceClient httpclient.ServiceCaller
 
-// InitServiceClient initializes the global service client
-func InitServiceClient(client *httpclient.ServiceClient) {
+// InitServiceClient initializes the global service client; tests may pass a fake ServiceCaller
+func InitServiceClient(client httpclient.ServiceCaller) {
 	serviceClient = client
 }
 
+// permissionCache holds the auth service's answers when enabled with CachePermissions
+var permissionCache *localcache.Cache[permissionKey, bool]
+
+type permissionKey struct {
+	userID     uint64
+	tenantID   string
+	permission string
+}
+
+// CachePermissions caches permission checks per user, tenant and permission for ttl, so hot
+// routes do not call the auth service on every request; revoked permissions keep working on
+// this replica until ttl passes or InvalidatePermissions is called
+func CachePermissions(ttl time.Duration) {
+	permissionCache = localcache.New[permissionKey, bool](localcache.Config{
+		Name:       "permissions",
+		MaxEntries: 50000,
+		TTL:        ttl,
+	})
+}
+
+// InvalidatePermissions drops the cached permission checks of a user, e.g. after a role change
+func InvalidatePermissions(userID uint64) {
+	if permissionCache == nil {
+		return
+	}
+	permissionCache.DeleteFunc(func(k permissionKey, _ bool) bool { return k.userID == userID })
+}
+
 // RequirePermission validates that user has a specific permission (user-only middleware)
 func RequirePermission(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get user ID from context (should be set by AuthMiddleware)
-		userID, exists := c.Get("user_id")
-		if !exists {
-			response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
-			c.Abort()
-			return
-		}
-
-		// Convert userID to uint
-		var uid uint64
-		switch v := userID.(type) {
-		case uint:
-			uid = uint64(v)
-		case uint64:
-			uid = v
-		case int:
-			uid = uint64(v)
-		case string:
-			parsed, err := strconv.ParseUint(v, 10, 64)
-			if err != nil {
-				response.Unauthorized(c, i18n.T(c, "invalid_user_id_format"))
-				c.Abort()
-				return
-			}
-			uid = uint64(parsed)
-		default:
-			response.Unauthorized(c, i18n.T(c, "invalid_user_id_type"))
-			c.Abort()
+		uid, ok := requireUserID(c)
+		if !ok {
 			return
 		}
 
 		// Call auth service to check access
 		allowed, err := checkUserPermission(c, uid, permission)
 		if err != nil {
+			logger.FromContext(c).Error("permission check failed", zap.String("permission", permission), zap.Error(err))
 			response.InternalError(c, i18n.T(c, "failed_to_validate_permissions"))
 			c.Abort()
 			return
@@ -83,34 +92,8 @@ func RequirePermission(permission string) gin.HandlerFunc {
 // RequirePermissions validates that user has all specified permissions (user-only middleware)
 func RequirePermissions(permissions ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get user ID from context
-		userID, exists := c.Get("user_id")
-		if !exists {
-			response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
-			c.Abort()
-			return
-		}
-
-		// Convert userID to uint
-		var uid uint64
-		switch v := userID.(type) {
-		case uint:
-			uid = uint64(v)
-		case uint64:
-			uid = v
-		case int:
-			uid = uint64(v)
-		case string:
-			parsed, err := strconv.ParseUint(v, 10, 64)
-			if err != nil {
-				response.Unauthorized(c, i18n.T(c, "invalid_user_id_format"))
-				c.Abort()
-				return
-			}
-			uid = uint64(parsed)
-		default:
-			response.Unauthorized(c, i18n.T(c, "invalid_user_id_type"))
-			c.Abort()
+		uid, ok := requireUserID(c)
+		if !ok {
 			return
 		}
 
@@ -118,6 +101,7 @@ func RequirePermissions(permissions ...string) gin.HandlerFunc {
 		for _, permission := range permissions {
 			allowed, err := checkUserPermission(c, uid, permission)
 			if err != nil {
+				logger.FromContext(c).Error("permission check failed", zap.String("permission", permission), zap.Error(err))
 				response.InternalError(c, i18n.T(c, "failed_to_validate_permissions"))
 				c.Abort()
 				return
@@ -134,6 +118,21 @@ func RequirePermissions(permissions ...string) gin.HandlerFunc {
 	}
 }
 
+// requireUserID reads the user set by the auth middleware, writing a 401 when it is missing
+func requireUserID(c *gin.Context) (uint64, bool) {
+	uid, ok := ctxutil.UserID(c)
+	if ok {
+		return uid, true
+	}
+	if _, exists := c.Get(ctxutil.UserIDKey); exists {
+		response.Unauthorized(c, i18n.T(c, "invalid_user_id_format"))
+	} else {
+		response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
+	}
+	c.Abort()
+	return 0, false
+}
+
 // checkUserPermission calls auth service to validate user permission using smart client
 func checkUserPermission(c *gin.Context, userID uint64, permission string) (bool, error) {
 	if serviceClient == nil {
@@ -145,16 +144,19 @@ func checkUserPermission(c *gin.Context, userID uint64, permission string) (bool
 		"permission": permission,
 	}
 
-	// Use smart client - it will automatically extract headers and detect service
-	resp, err := serviceClient.Post(c, "/api/v1/auth/access", payload)
-	if err != nil {
-		return false, err
+	// The breaker fails fast while the auth service is down instead of stacking up timeouts
+	check := func() (bool, error) {
+		return breaker.Call(c, breaker.Get("auth.permissions"), func(ctx context.Context) (bool, error) {
+			// Use smart client - it will automatically extract headers and detect service
+			accessData, err := httpclient.PostAs[AccessData](c, serviceClient, "/api/v1/auth/access", payload)
+			if err != nil {
+				return false, err
+			}
+			return accessData.Allowed, nil
+		})
 	}
-
-	var accessData AccessData
-	if err := httpclient.DecodeStandardResponse(resp, &accessData); err != nil {
-		return false, err
+	if permissionCache == nil {
+		return check()
 	}
-
-	return accessData.Allowed, nil
+	return permissionCache.GetOrLoad(permissionKey{userID: userID, tenantID: ctxutil.TenantID(c), permission: permission}, check)
 }
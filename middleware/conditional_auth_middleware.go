@@ -1,11 +1,11 @@
 package middleware
 
 import (
-	"strconv"
-
 	"github.com/Masharah-Advisory/common/i18n"
+	logger "github.com/Masharah-Advisory/common/loggers"
 	"github.com/Masharah-Advisory/common/response"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 // PermissionMiddleware checks permissions only for user requests,
@@ -27,39 +27,15 @@ func PermissionMiddleware(permission string) gin.HandlerFunc {
 
 		// If user request, check permission
 		if authType == "user" {
-			userID, exists := c.Get("user_id")
-			if !exists {
-				response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
-				c.Abort()
-				return
-			}
-
-			// Convert userID to uint
-			var uid uint64
-			switch v := userID.(type) {
-			case uint64:
-				uid = v
-			case uint:
-				uid = uint64(v)
-			case int:
-				uid = uint64(v)
-			case string:
-				parsed, err := strconv.ParseUint(v, 10, 64)
-				if err != nil {
-					response.Unauthorized(c, i18n.T(c, "invalid_user_id_format"))
-					c.Abort()
-					return
-				}
-				uid = uint64(parsed)
-			default:
-				response.Unauthorized(c, i18n.T(c, "invalid_user_id_type"))
-				c.Abort()
+			uid, ok := requireUserID(c)
+			if !ok {
 				return
 			}
 
 			// Check permission via auth service
 			allowed, err := checkUserPermission(c, uid, permission)
 			if err != nil {
+				logger.FromContext(c).Error("permission check failed", zap.String("permission", permission), zap.Error(err))
 				response.InternalError(c, i18n.T(c, "failed_to_validate_permissions"))
 				c.Abort()
 				return
@@ -99,33 +75,8 @@ func PermissionAnyMiddleware(permissions ...string) gin.HandlerFunc {
 
 		// If user request, check all permissions
 		if authType == "user" {
-			userID, exists := c.Get("user_id")
-			if !exists {
-				response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
-				c.Abort()
-				return
-			}
-
-			// Convert userID to uint
-			var uid uint64
-			switch v := userID.(type) {
-			case uint64:
-				uid = v
-			case uint:
-				uid = uint64(v)
-			case int:
-				uid = uint64(v)
-			case string:
-				parsed, err := strconv.ParseUint(v, 10, 64)
-				if err != nil {
-					response.Unauthorized(c, i18n.T(c, "invalid_user_id_format"))
-					c.Abort()
-					return
-				}
-				uid = uint64(parsed)
-			default:
-				response.Unauthorized(c, i18n.T(c, "invalid_user_id_type"))
-				c.Abort()
+			uid, ok := requireUserID(c)
+			if !ok {
 				return
 			}
 
@@ -133,6 +84,7 @@ func PermissionAnyMiddleware(permissions ...string) gin.HandlerFunc {
 			for _, permission := range permissions {
 				allowed, err := checkUserPermission(c, uid, permission)
 				if err != nil {
+					logger.FromContext(c).Error("permission check failed", zap.String("permission", permission), zap.Error(err))
 					response.InternalError(c, i18n.T(c, "failed_to_validate_permissions"))
 					c.Abort()
 					return
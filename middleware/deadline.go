@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// DeadlineConfig sets the time budget of requests
+type DeadlineConfig struct {
+	// Default is the budget of routes missing from Routes; 0 leaves them to the caller's deadline
+	Default time.Duration
+	// Routes are budgets by method and route as registered, e.g. {"GET /api/v1/reports/:id": 10 * time.Second}
+	Routes map[string]time.Duration
+}
+
+// DeadlineMiddleware bounds each request by the earlier of the caller's X-Request-Deadline and
+// the route's budget. The request context is cancelled at the deadline, and service clients
+// shorten their timeouts to it and pass what is left on, so a chain of calls gives up together
+// instead of retrying for a caller that is gone. Requests arriving past their deadline get 504.
+func DeadlineMiddleware(cfg DeadlineConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		now := time.Now()
+		deadline, ok := parseDeadline(c.GetHeader(ctxutil.DeadlineHeader))
+		budget, found := cfg.Routes[c.Request.Method+" "+c.FullPath()]
+		if !found {
+			budget = cfg.Default
+		}
+		if budget > 0 && (!ok || now.Add(budget).Before(deadline)) {
+			deadline, ok = now.Add(budget), true
+		}
+		if !ok {
+			c.Next()
+			return
+		}
+		if !deadline.After(now) {
+			response.Error(c, http.StatusGatewayTimeout, i18n.T(c, "request_deadline_exceeded"))
+			c.Abort()
+			return
+		}
+
+		ctx, cancel := context.WithDeadline(c.Request.Context(), deadline)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		ctxutil.WithDeadline(c, deadline)
+		c.Next()
+	}
+}
+
+// parseDeadline reads a deadline in Unix milliseconds
+func parseDeadline(v string) (time.Time, bool) {
+	if v == "" {
+		return time.Time{}, false
+	}
+	ms, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || ms <= 0 {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(ms), true
+}
@@ -6,9 +6,13 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Masharah-Advisory/common/config"
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/idgen"
+	logger "github.com/Masharah-Advisory/common/loggers"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
+	"go.uber.org/zap"
 	"golang.org/x/time/rate"
 )
 
@@ -20,15 +24,15 @@ func RequestIDMiddleware() gin.HandlerFunc {
 			requestId = generateRequestID()
 		}
 
-		c.Set("request_id", requestId)
+		ctxutil.WithRequestID(c, requestId)
 		c.Header("X-Request-ID", requestId)
 		c.Next()
 	}
 }
 
-// generateRequestID creates a simple request ID
+// generateRequestID creates a time-sortable request ID
 func generateRequestID() string {
-	return time.Now().Format("20060102-150405") + "-" + uuid.New().String()
+	return idgen.NewRequestID()
 }
 
 // SecurityHeadersMiddleware adds security headers
@@ -43,7 +47,9 @@ func SecurityHeadersMiddleware() gin.HandlerFunc {
 	}
 }
 
-// CorsMiddleware configures CORS middleware
+// CorsMiddleware configures CORS middleware.
+// Outside production an empty origin list allows any origin so local front-ends work out of the box;
+// in production only the configured origins are accepted.
 func CorsMiddleware(allowedOrigins []string) gin.HandlerFunc {
 	corsConfig := cors.Config{
 		AllowOrigins:     allowedOrigins,
@@ -53,9 +59,38 @@ func CorsMiddleware(allowedOrigins []string) gin.HandlerFunc {
 		MaxAge:           12 * time.Hour,
 	}
 
+	if len(allowedOrigins) == 0 {
+		lax := !config.IsProduction()
+		corsConfig.AllowOriginFunc = func(origin string) bool {
+			return lax
+		}
+	}
+
 	return cors.New(corsConfig)
 }
 
+// DebugMiddleware logs request details and timings outside production; it is a no-op in production
+func DebugMiddleware() gin.HandlerFunc {
+	if config.IsProduction() {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Header("X-Environment", config.Env().String())
+		c.Next()
+
+		logger.FromContext(c).Debug("request handled",
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.RequestURI()),
+			zap.Int("status", c.Writer.Status()),
+			zap.Duration("duration", time.Since(start)),
+		)
+	}
+}
+
 // APIKeyAuthMiddleware validates API key for protected endpoints
 func APIKeyAuthMiddleware(apiKey string) gin.HandlerFunc {
 	return func(c *gin.Context) {
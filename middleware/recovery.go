@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+	"syscall"
+
+	"github.com/Masharah-Advisory/common/i18n"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RecoveryMiddleware turns panics into a logged 500 response instead of a dropped connection.
+// Broken client connections are logged at debug level and not answered.
+func RecoveryMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			if r == http.ErrAbortHandler {
+				panic(r)
+			}
+
+			l := logger.FromContext(c)
+			if err, ok := r.(error); ok && isBrokenPipe(err) {
+				l.Debug("client connection closed", zap.Error(err))
+				c.Abort()
+				return
+			}
+
+			l.Error("panic recovered",
+				zap.Any("panic", r),
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+				zap.ByteString("stack", debug.Stack()),
+			)
+			if !c.Writer.Written() {
+				response.InternalError(c, i18n.T(c, "error.internal"))
+			}
+			c.Abort()
+		}()
+		c.Next()
+	}
+}
+
+func isBrokenPipe(err error) bool {
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+	var sysErr *os.SyscallError
+	if errors.As(opErr.Err, &sysErr) {
+		return errors.Is(sysErr.Err, syscall.EPIPE) || errors.Is(sysErr.Err, syscall.ECONNRESET)
+	}
+	return strings.Contains(opErr.Error(), "broken pipe")
+}
@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Access is who may call a route
+type Access string
+
+const (
+	// AccessPublic routes need no authentication; give them a Reason
+	AccessPublic Access = "public"
+	// AccessUser routes need a user JWT (AuthMiddleware)
+	AccessUser Access = "user"
+	// AccessService routes are for internal services only (ServiceAuthMiddleware)
+	AccessService Access = "service"
+	// AccessAny routes take a user JWT or service credentials (SmartAuthMiddleware); services
+	// bypass the permissions
+	AccessAny Access = "any"
+)
+
+// Findings flagged by the enforcement report
+const (
+	FindingPublic         = "public"           // no authentication
+	FindingPublicNoReason = "public_no_reason" // public without a documented reason
+	FindingNoPermission   = "no_permission"    // any authenticated user may call it
+	FindingUndeclared     = "undeclared"       // mounted on the engine outside the registry
+)
+
+// RouteRule declares a route and its access requirements in one place
+type RouteRule struct {
+	Method string
+	Path   string
+	Access Access
+	// Permissions the user must all hold
+	Permissions []string
+	// Reason documents why a public route or a user route without permissions is acceptable
+	Reason  string
+	Handler gin.HandlerFunc
+	// Middleware runs after the auth middleware, before Handler
+	Middleware []gin.HandlerFunc
+}
+
+// RouteEnforcement is an entry of the enforcement report
+type RouteEnforcement struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	Access      Access   `json:"access,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	Reason      string   `json:"reason,omitempty"`
+	Findings    []string `json:"findings,omitempty"`
+}
+
+// RouteRegistry mounts routes with the middleware their declared access requires and reports
+// what each route enforces, so a security review reads one table instead of grepping for
+// RequirePermission calls
+type RouteRegistry struct {
+	jwtSecret string
+
+	mu     sync.Mutex
+	routes []RouteEnforcement
+}
+
+// NewRouteRegistry creates a registry; jwtSecret is passed to the auth middleware, empty uses
+// the global secret
+func NewRouteRegistry(jwtSecret string) *RouteRegistry {
+	return &RouteRegistry{jwtSecret: jwtSecret}
+}
+
+// Mount registers rules on rg, e.g.
+//
+//	routes.Mount(api, []middleware.RouteRule{
+//		{Method: "GET", Path: "/cases", Access: middleware.AccessUser, Permissions: []string{"cases.read"}, Handler: h.List},
+//		{Method: "POST", Path: "/cases/sync", Access: middleware.AccessService, Handler: h.Sync},
+//		{Method: "GET", Path: "/status", Access: middleware.AccessPublic, Reason: "status page", Handler: h.Status},
+//	})
+//
+// It panics on a rule without a known access, handlers, or with permissions a service-only or
+// public route cannot check, so a mistake fails at startup rather than opening a route.
+func (r *RouteRegistry) Mount(rg gin.IRoutes, rules []RouteRule) {
+	basePath := ""
+	if g, ok := rg.(*gin.RouterGroup); ok {
+		basePath = g.BasePath()
+	}
+	for _, rule := range rules {
+		chain := append(r.middlewareFor(rule), rule.Middleware...)
+		chain = append(chain, rule.Handler)
+		rg.Handle(strings.ToUpper(rule.Method), rule.Path, chain...)
+
+		r.mu.Lock()
+		r.routes = append(r.routes, RouteEnforcement{
+			Method:      strings.ToUpper(rule.Method),
+			Path:        joinRoutePath(basePath, rule.Path),
+			Access:      rule.Access,
+			Permissions: append([]string(nil), rule.Permissions...),
+			Reason:      rule.Reason,
+		})
+		r.mu.Unlock()
+	}
+}
+
+// middlewareFor returns the auth middleware a rule requires
+func (r *RouteRegistry) middlewareFor(rule RouteRule) []gin.HandlerFunc {
+	name := strings.ToUpper(rule.Method) + " " + rule.Path
+	if rule.Handler == nil {
+		panic("middleware: route " + name + " has no handler")
+	}
+	switch rule.Access {
+	case AccessPublic, AccessService:
+		if len(rule.Permissions) > 0 {
+			panic(fmt.Sprintf("middleware: %s route %s cannot check permissions", rule.Access, name))
+		}
+		if rule.Access == AccessService {
+			return []gin.HandlerFunc{ServiceAuthMiddleware()}
+		}
+		return nil
+	case AccessUser:
+		chain := []gin.HandlerFunc{AuthMiddleware(r.jwtSecret)}
+		if len(rule.Permissions) > 0 {
+			chain = append(chain, RequirePermissions(rule.Permissions...))
+		}
+		return chain
+	case AccessAny:
+		chain := []gin.HandlerFunc{SmartAuthMiddleware(r.jwtSecret)}
+		if len(rule.Permissions) > 0 {
+			chain = append(chain, PermissionAnyMiddleware(rule.Permissions...))
+		}
+		return chain
+	default:
+		panic(fmt.Sprintf("middleware: route %s has unknown access %q", name, rule.Access))
+	}
+}
+
+// Report lists the declared routes with their findings, sorted by path. When engine is given,
+// routes mounted on it outside the registry are listed as undeclared, e.g. health checks or
+// routes still wired by hand.
+func (r *RouteRegistry) Report(engine *gin.Engine) []RouteEnforcement {
+	r.mu.Lock()
+	out := make([]RouteEnforcement, 0, len(r.routes))
+	declared := make(map[string]bool, len(r.routes))
+	for _, route := range r.routes {
+		route.Findings = findingsOf(route)
+		out = append(out, route)
+		declared[route.Method+" "+route.Path] = true
+	}
+	r.mu.Unlock()
+
+	if engine != nil {
+		for _, info := range engine.Routes() {
+			if !declared[info.Method+" "+info.Path] {
+				out = append(out, RouteEnforcement{Method: info.Method, Path: info.Path, Findings: []string{FindingUndeclared}})
+			}
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Path != out[j].Path {
+			return out[i].Path < out[j].Path
+		}
+		return out[i].Method < out[j].Method
+	})
+	return out
+}
+
+// ReportHandler serves the enforcement report; mount it behind admin auth
+func (r *RouteRegistry) ReportHandler(engine *gin.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response.OK(c, r.Report(engine))
+	}
+}
+
+func findingsOf(route RouteEnforcement) []string {
+	var findings []string
+	switch route.Access {
+	case AccessPublic:
+		findings = append(findings, FindingPublic)
+		if route.Reason == "" {
+			findings = append(findings, FindingPublicNoReason)
+		}
+	case AccessUser, AccessAny:
+		if len(route.Permissions) == 0 && route.Reason == "" {
+			findings = append(findings, FindingNoPermission)
+		}
+	}
+	return findings
+}
+
+func joinRoutePath(base, path string) string {
+	if path == "" || path == "/" {
+		if base == "" {
+			return "/"
+		}
+		return base
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(path, "/")
+}
@@ -4,10 +4,13 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/Masharah-Advisory/common/ctxutil"
 	"github.com/Masharah-Advisory/common/i18n"
+	logger "github.com/Masharah-Advisory/common/loggers"
 	"github.com/Masharah-Advisory/common/response"
 	"github.com/Masharah-Advisory/common/utils"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 // SmartAuthMiddleware automatically detects request source and applies appropriate authentication
@@ -57,13 +60,14 @@ func SmartAuthMiddleware(jwtSecret ...string) gin.HandlerFunc {
 			// Parse and validate JWT token locally
 			claims, err := parseJWTToken(tokenString, secret)
 			if err != nil {
+				logger.FromContext(c).Debug("jwt validation failed", zap.Error(err))
 				response.Unauthorized(c, i18n.T(c, "invalid_or_expired_token"))
 				c.Abort()
 				return
 			}
 
 			// Set user ID in context and header for downstream services
-			c.Set("user_id", claims.UserID)
+			ctxutil.WithUserID(c, claims.UserID)
 			c.Request.Header.Set(utils.XUserIDHeader, strconv.FormatUint(claims.UserID, 10))
 			c.Set("authType", "user")
 			c.Next()
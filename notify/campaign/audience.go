@@ -0,0 +1,43 @@
+package campaign
+
+import "context"
+
+// Recipient is one member of an audience
+type Recipient struct {
+	UserID uint64
+	Email  string
+	Name   string
+	Lang   string
+	// Data personalizes the template on top of the campaign data, e.g. {"Plan": "Gold"}
+	Data map[string]interface{}
+}
+
+// Query selects one page of an audience
+type Query struct {
+	TenantID string
+	// Params are the campaign's audience parameters, e.g. {"city": "Riyadh"}
+	Params map[string]interface{}
+	// After is the cursor returned with the previous page, "" for the first
+	After string
+	Limit int
+}
+
+// Audience selects campaign recipients page by page, e.g. users of a tenant filtered by the
+// campaign params. Pages must come in a stable order; an empty next cursor ends the audience.
+type Audience interface {
+	Recipients(ctx context.Context, q Query) (page []Recipient, next string, err error)
+}
+
+// Counter is implemented by audiences that can count their members up front, so progress has
+// a total
+type Counter interface {
+	Count(ctx context.Context, tenantID string, params map[string]interface{}) (int, error)
+}
+
+// AudienceFunc adapts a function to Audience
+type AudienceFunc func(ctx context.Context, q Query) ([]Recipient, string, error)
+
+// Recipients implements Audience
+func (f AudienceFunc) Recipients(ctx context.Context, q Query) ([]Recipient, string, error) {
+	return f(ctx, q)
+}
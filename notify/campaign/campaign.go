@@ -0,0 +1,569 @@
+// Package campaign sends bulk email campaigns through the worker queue: an audience query picks
+// the recipients, the email renderer personalizes the template for each of them, sends are
+// throttled to the provider's rate, and every delivery and unsubscribe is recorded.
+package campaign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/audit"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/notify/email"
+	"github.com/Masharah-Advisory/common/signedurl"
+	"github.com/Masharah-Advisory/common/worker"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SendJob is the worker job that sends a campaign
+const SendJob = "email.campaign"
+
+// Audit actions recorded on the campaign
+const (
+	ActionCreated      audit.Action = "campaign.created"
+	ActionStarted      audit.Action = "campaign.started"
+	ActionCancelled    audit.Action = "campaign.cancelled"
+	ActionCompleted    audit.Action = "campaign.completed"
+	ActionUnsubscribed audit.Action = "campaign.unsubscribed"
+)
+
+// Errors returned by the manager
+var (
+	ErrNotFound = apperror.New("campaign_not_found", apperror.KindNotFound, "campaign not found").
+			WithKey("campaign.not_found")
+	ErrUnknownAudience = apperror.New("campaign_unknown_audience", apperror.KindValidation, "unknown campaign audience").
+				WithKey("campaign.unknown_audience")
+	ErrUnknownTemplate = apperror.New("campaign_unknown_template", apperror.KindValidation, "unknown email template").
+				WithKey("campaign.unknown_template")
+	ErrNotDraft = apperror.New("campaign_not_draft", apperror.KindConflict, "the campaign has already been started").
+			WithKey("campaign.not_draft")
+	ErrNotRunning = apperror.New("campaign_not_running", apperror.KindConflict, "the campaign is no longer running").
+			WithKey("campaign.not_running")
+)
+
+// errCancelled stops a campaign cancelled while sending
+var errCancelled = errors.New("campaign cancelled")
+
+// Config holds the campaign settings
+type Config struct {
+	DB     *gorm.DB
+	Sender *email.Sender   // needs a renderer
+	Worker *worker.Manager // optional; without it Start sends inline
+	// Audiences are the recipient queries campaigns can pick by name
+	Audiences map[string]Audience
+
+	// Signer and UnsubscribeURL build the unsubscribe link of every email, the public endpoint
+	// mounted by Handlers.RegisterPublic, e.g. https://api.example.com/campaigns/unsubscribe
+	Signer         *signedurl.Signer
+	UnsubscribeURL string
+	// UnsubscribeTTL is how long unsubscribe links work, defaults to 180 days
+	UnsubscribeTTL time.Duration
+
+	// PerSecond caps the send rate of this process to stay under the provider's quota,
+	// e.g. the SES maximum send rate; defaults to 10
+	PerSecond float64
+	Burst     int // defaults to 1
+	// BatchSize is how many recipients are loaded at a time; cancellation and progress are
+	// checked between batches. Defaults to 200.
+	BatchSize int
+}
+
+// Input creates a campaign
+type Input struct {
+	Name     string                 `json:"name" binding:"required,max=255"`
+	List     string                 `json:"list" binding:"required,max=64"`
+	Template string                 `json:"template" binding:"required,max=128"`
+	Data     map[string]interface{} `json:"data"`
+	Audience string                 `json:"audience" binding:"required,max=64"`
+	Params   map[string]interface{} `json:"params"`
+}
+
+// Manager creates, sends and tracks campaigns
+type Manager struct {
+	cfg     *Config
+	log     *zap.Logger
+	limiter *rate.Limiter
+	now     func() time.Time
+}
+
+type sendPayload struct {
+	CampaignID uint64 `json:"campaign_id"`
+}
+
+// New creates a manager and registers the send job on cfg.Worker when set; migrate Campaign,
+// Delivery and Unsubscribe first
+func New(cfg *Config) (*Manager, error) {
+	if cfg.DB == nil || cfg.Sender == nil {
+		return nil, fmt.Errorf("campaign db and email sender are required")
+	}
+	if cfg.Signer == nil || cfg.UnsubscribeURL == "" {
+		return nil, fmt.Errorf("campaign unsubscribe signer and URL are required")
+	}
+	if cfg.UnsubscribeTTL <= 0 {
+		cfg.UnsubscribeTTL = 180 * 24 * time.Hour
+	}
+	if cfg.PerSecond <= 0 {
+		cfg.PerSecond = 10
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = 1
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 200
+	}
+
+	m := &Manager{
+		cfg:     cfg,
+		log:     logger.Module("campaign"),
+		limiter: rate.NewLimiter(rate.Limit(cfg.PerSecond), cfg.Burst),
+		now:     time.Now,
+	}
+	if cfg.Worker != nil {
+		cfg.Worker.Register(SendJob, m.handleTask, worker.JobOptions{MaxRetries: 3, RetryBackoff: time.Minute, Timeout: 12 * time.Hour})
+	}
+	return m, nil
+}
+
+// Create stores a draft campaign
+func (m *Manager) Create(ctx context.Context, tenantID string, createdBy uint64, in Input) (*Campaign, error) {
+	if _, ok := m.cfg.Audiences[in.Audience]; !ok {
+		return nil, ErrUnknownAudience
+	}
+	if _, err := m.cfg.Sender.Render(&email.TemplateMessage{Template: in.Template}); errors.Is(err, email.ErrTemplateNotFound) {
+		return nil, ErrUnknownTemplate
+	}
+
+	c := &Campaign{
+		TenantID: tenantID,
+		Name:     in.Name,
+		List:     in.List,
+		Template: in.Template,
+		Data:     in.Data,
+		Audience: in.Audience,
+		Params:   in.Params,
+		Status:   StatusDraft,
+	}
+	c.CreatedBy = &createdBy
+	if err := m.cfg.DB.WithContext(ctx).Create(c).Error; err != nil {
+		return nil, fmt.Errorf("failed to create campaign: %w", err)
+	}
+	m.audit(ctx, ActionCreated, c)
+	return c, nil
+}
+
+// Get returns a campaign of the tenant
+func (m *Manager) Get(ctx context.Context, tenantID string, id uint64) (*Campaign, error) {
+	var c Campaign
+	err := m.cfg.DB.WithContext(ctx).
+		Where("id = ? AND tenant_id = ? AND deleted_at IS NULL", id, tenantID).
+		First(&c).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load campaign: %w", err)
+	}
+	return &c, nil
+}
+
+// List returns a page of the tenant's campaigns, newest first
+func (m *Manager) List(ctx context.Context, tenantID string, page, limit int) ([]Campaign, int64, error) {
+	q := m.cfg.DB.WithContext(ctx).Model(&Campaign{}).Where("tenant_id = ? AND deleted_at IS NULL", tenantID)
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count campaigns: %w", err)
+	}
+	var items []Campaign
+	if err := q.Order("id DESC").Offset((page - 1) * limit).Limit(limit).Find(&items).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list campaigns: %w", err)
+	}
+	return items, total, nil
+}
+
+// Deliveries returns a page of a campaign's deliveries; status "" lists all of them
+func (m *Manager) Deliveries(ctx context.Context, tenantID string, id uint64, status DeliveryStatus, page, limit int) ([]Delivery, int64, error) {
+	if _, err := m.Get(ctx, tenantID, id); err != nil {
+		return nil, 0, err
+	}
+	q := m.cfg.DB.WithContext(ctx).Model(&Delivery{}).Where("campaign_id = ?", id)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count campaign deliveries: %w", err)
+	}
+	var items []Delivery
+	if err := q.Order("id").Offset((page - 1) * limit).Limit(limit).Find(&items).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list campaign deliveries: %w", err)
+	}
+	return items, total, nil
+}
+
+// Start queues a draft campaign for sending; without a worker it is sent before Start returns
+func (m *Manager) Start(ctx context.Context, tenantID string, id, userID uint64) (*Campaign, error) {
+	c, err := m.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	res := m.cfg.DB.WithContext(ctx).Model(&Campaign{}).
+		Where("id = ? AND status = ?", id, StatusDraft).
+		Updates(map[string]interface{}{"status": StatusQueued, "updated_by": userID})
+	if res.Error != nil {
+		return nil, fmt.Errorf("failed to start campaign: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrNotDraft
+	}
+	c.Status = StatusQueued
+	m.audit(ctx, ActionStarted, c)
+
+	if m.cfg.Worker == nil {
+		if err := m.Process(ctx, id); err != nil {
+			return nil, err
+		}
+		return m.Get(ctx, tenantID, id)
+	}
+	if _, err := m.cfg.Worker.Enqueue(ctx, SendJob, sendPayload{CampaignID: id}); err != nil {
+		m.fail(ctx, c, err)
+		return nil, fmt.Errorf("failed to queue campaign: %w", err)
+	}
+	return c, nil
+}
+
+// Cancel stops a campaign that has not completed; recipients already sent to stay sent
+func (m *Manager) Cancel(ctx context.Context, tenantID string, id, userID uint64) (*Campaign, error) {
+	c, err := m.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	res := m.cfg.DB.WithContext(ctx).Model(&Campaign{}).
+		Where("id = ? AND status IN ?", id, []Status{StatusDraft, StatusQueued, StatusSending, StatusFailed}).
+		Updates(map[string]interface{}{"status": StatusCancelled, "updated_by": userID})
+	if res.Error != nil {
+		return nil, fmt.Errorf("failed to cancel campaign: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrNotRunning
+	}
+	c.Status = StatusCancelled
+	m.audit(ctx, ActionCancelled, c)
+	return c, nil
+}
+
+// Process sends a queued campaign to its audience. Failed campaigns may be processed again and
+// resume after the last recorded delivery, so nobody gets the email twice.
+func (m *Manager) Process(ctx context.Context, id uint64) error {
+	c, err := m.load(ctx, id)
+	if err != nil {
+		return err
+	}
+	active := []Status{StatusQueued, StatusSending, StatusFailed}
+	if !contains(active, c.Status) {
+		return nil
+	}
+	audience, ok := m.cfg.Audiences[c.Audience]
+	if !ok {
+		m.fail(ctx, c, ErrUnknownAudience)
+		return ErrUnknownAudience
+	}
+
+	updates := map[string]interface{}{"status": StatusSending, "error": ""}
+	if c.StartedAt == nil {
+		updates["started_at"] = m.now()
+	}
+	if counter, ok := audience.(Counter); ok && c.Total == 0 {
+		total, err := counter.Count(ctx, c.TenantID, c.Params)
+		if err != nil {
+			m.log.Warn("failed to count campaign audience", zap.Uint64("campaign_id", c.ID), zap.Error(err))
+		}
+		updates["total"] = total
+	}
+	res := m.cfg.DB.WithContext(ctx).Model(&Campaign{}).Where("id = ? AND status IN ?", id, active).Updates(updates)
+	if res.Error != nil {
+		return fmt.Errorf("failed to update campaign: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil
+	}
+
+	after := ""
+	for {
+		page, next, err := audience.Recipients(ctx, Query{TenantID: c.TenantID, Params: c.Params, After: after, Limit: m.cfg.BatchSize})
+		if err != nil {
+			err = fmt.Errorf("failed to load campaign audience: %w", err)
+			m.fail(ctx, c, err)
+			return err
+		}
+		if err := m.sendPage(ctx, c, page); err != nil {
+			if errors.Is(err, errCancelled) {
+				return nil
+			}
+			m.fail(ctx, c, err)
+			return err
+		}
+		if next == "" {
+			break
+		}
+		after = next
+	}
+
+	res = m.cfg.DB.WithContext(ctx).Model(&Campaign{}).
+		Where("id = ? AND status = ?", id, StatusSending).
+		Updates(map[string]interface{}{"status": StatusCompleted, "completed_at": m.now()})
+	if res.Error != nil {
+		return fmt.Errorf("failed to update campaign: %w", res.Error)
+	}
+	if c, err = m.load(ctx, id); err == nil && res.RowsAffected > 0 {
+		m.audit(ctx, ActionCompleted, c,
+			audit.WithMetadata("sent", c.Sent), audit.WithMetadata("failed", c.Failed), audit.WithMetadata("skipped", c.Skipped))
+	}
+	return nil
+}
+
+// sendPage sends to the recipients of one page that have no delivery yet, then refreshes the
+// progress; it returns errCancelled once the campaign was cancelled
+func (m *Manager) sendPage(ctx context.Context, c *Campaign, page []Recipient) error {
+	addrs := make([]string, 0, len(page))
+	for _, r := range page {
+		addrs = append(addrs, normalize(r.Email))
+	}
+	var done, unsubscribed []string
+	if err := m.cfg.DB.WithContext(ctx).Model(&Delivery{}).
+		Where("campaign_id = ? AND email IN ?", c.ID, addrs).
+		Pluck("email", &done).Error; err != nil {
+		return fmt.Errorf("failed to load campaign deliveries: %w", err)
+	}
+	if err := m.cfg.DB.WithContext(ctx).Model(&Unsubscribe{}).
+		Where("tenant_id = ? AND list IN ? AND email IN ?", c.TenantID, []string{c.List, ListAll}, addrs).
+		Pluck("email", &unsubscribed).Error; err != nil {
+		return fmt.Errorf("failed to load unsubscribes: %w", err)
+	}
+	skip, unsub := set(done), set(unsubscribed)
+
+	for i, r := range page {
+		addr := addrs[i]
+		if addr == "" || skip[addr] {
+			continue
+		}
+		skip[addr] = true
+
+		d := &Delivery{CampaignID: c.ID, Email: addr, UserID: r.UserID, Status: DeliverySkipped}
+		if !unsub[addr] {
+			if err := m.limiter.Wait(ctx); err != nil {
+				return err
+			}
+			d.Status = DeliverySent
+			if err := m.send(ctx, c, r, addr); err != nil {
+				d.Status, d.Error = DeliveryFailed, truncate(err.Error(), 1024)
+			}
+		}
+		// Recorded even when the job is being stopped, so a resumed campaign skips this address
+		if err := m.cfg.DB.WithContext(context.WithoutCancel(ctx)).
+			Clauses(clause.OnConflict{DoNothing: true}).Create(d).Error; err != nil {
+			return fmt.Errorf("failed to record campaign delivery: %w", err)
+		}
+	}
+	return m.progress(ctx, c.ID)
+}
+
+// progress recounts the deliveries of a campaign into its counters
+func (m *Manager) progress(ctx context.Context, id uint64) error {
+	var rows []struct {
+		Status DeliveryStatus
+		Count  int
+	}
+	if err := m.cfg.DB.WithContext(ctx).Model(&Delivery{}).
+		Select("status, COUNT(*) AS count").
+		Where("campaign_id = ?", id).
+		Group("status").
+		Scan(&rows).Error; err != nil {
+		return fmt.Errorf("failed to count campaign deliveries: %w", err)
+	}
+	counts := map[DeliveryStatus]int{}
+	for _, r := range rows {
+		counts[r.Status] = r.Count
+	}
+	res := m.cfg.DB.WithContext(ctx).Model(&Campaign{}).
+		Where("id = ? AND status = ?", id, StatusSending).
+		Updates(map[string]interface{}{
+			"sent":    counts[DeliverySent],
+			"failed":  counts[DeliveryFailed],
+			"skipped": counts[DeliverySkipped],
+		})
+	if res.Error != nil {
+		return fmt.Errorf("failed to update campaign progress: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return errCancelled
+	}
+	return nil
+}
+
+// send renders the template for one recipient and sends it with its unsubscribe link
+func (m *Manager) send(ctx context.Context, c *Campaign, r Recipient, addr string) error {
+	token, _, err := m.cfg.Signer.Token(signedurl.PurposeUnsubscribe, addr,
+		signedurl.WithTTL(m.cfg.UnsubscribeTTL),
+		signedurl.WithData("tenant", c.TenantID),
+		signedurl.WithData("list", c.List),
+		signedurl.WithData("campaign", strconv.FormatUint(c.ID, 10)),
+	)
+	if err != nil {
+		return err
+	}
+	sep := "?"
+	if strings.Contains(m.cfg.UnsubscribeURL, "?") {
+		sep = "&"
+	}
+	link := m.cfg.UnsubscribeURL + sep + "token=" + url.QueryEscape(token)
+
+	data := make(map[string]interface{}, len(c.Data)+len(r.Data)+3)
+	for k, v := range c.Data {
+		data[k] = v
+	}
+	for k, v := range r.Data {
+		data[k] = v
+	}
+	data["Name"], data["Email"], data["UnsubscribeURL"] = r.Name, addr, link
+
+	msg, err := m.cfg.Sender.Render(&email.TemplateMessage{
+		Template: c.Template,
+		Lang:     r.Lang,
+		Data:     data,
+		To:       []email.Address{{Name: r.Name, Email: addr}},
+		TenantID: c.TenantID,
+		Tags:     map[string]string{"campaign": strconv.FormatUint(c.ID, 10)},
+	})
+	if err != nil {
+		return err
+	}
+	// One-click unsubscribe (RFC 8058), which bulk senders must offer to reach Gmail and Yahoo
+	msg.Headers = map[string]string{
+		"List-Unsubscribe":      "<" + link + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+	return m.cfg.Sender.Send(ctx, msg)
+}
+
+// Unsubscribe applies the token of an unsubscribe link
+func (m *Manager) Unsubscribe(ctx context.Context, token string) (*Unsubscribe, error) {
+	claims, err := m.cfg.Signer.Peek(ctx, signedurl.PurposeUnsubscribe, token)
+	if err != nil {
+		return nil, err
+	}
+	u := &Unsubscribe{TenantID: claims.Data["tenant"], Email: claims.Subject, List: claims.Data["list"]}
+	if id, err := strconv.ParseUint(claims.Data["campaign"], 10, 64); err == nil {
+		u.CampaignID = &id
+	}
+	if err := m.Suppress(ctx, u); err != nil {
+		return nil, err
+	}
+	opts := []audit.Option{audit.WithTenant(u.TenantID), audit.WithMetadata("list", u.List)}
+	resource := audit.Resource{Type: "campaign"}
+	if u.CampaignID != nil {
+		resource.ID = strconv.FormatUint(*u.CampaignID, 10)
+	}
+	if err := audit.Record(ctx, ActionUnsubscribed, resource, nil, opts...); err != nil {
+		m.log.Warn("failed to audit unsubscribe", zap.Error(err))
+	}
+	return u, nil
+}
+
+// Suppress stops campaigns of u.List (ListAll for every list) from reaching u.Email, e.g. for
+// addresses that bounced or complained; suppressing an address twice is a no-op
+func (m *Manager) Suppress(ctx context.Context, u *Unsubscribe) error {
+	u.Email = normalize(u.Email)
+	if u.List == "" {
+		u.List = ListAll
+	}
+	if err := m.cfg.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(u).Error; err != nil {
+		return fmt.Errorf("failed to save unsubscribe: %w", err)
+	}
+	return nil
+}
+
+// Unsubscribed reports whether address opted out of list, directly or from every list
+func (m *Manager) Unsubscribed(ctx context.Context, tenantID, address, list string) (bool, error) {
+	var count int64
+	err := m.cfg.DB.WithContext(ctx).Model(&Unsubscribe{}).
+		Where("tenant_id = ? AND email = ? AND list IN ?", tenantID, normalize(address), []string{list, ListAll}).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to load unsubscribes: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (m *Manager) handleTask(ctx context.Context, task *worker.Task) error {
+	var p sendPayload
+	if err := task.Decode(&p); err != nil {
+		return err
+	}
+	return m.Process(ctx, p.CampaignID)
+}
+
+func (m *Manager) load(ctx context.Context, id uint64) (*Campaign, error) {
+	var c Campaign
+	err := m.cfg.DB.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&c).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load campaign: %w", err)
+	}
+	return &c, nil
+}
+
+func (m *Manager) fail(ctx context.Context, c *Campaign, cause error) {
+	err := m.cfg.DB.WithContext(context.WithoutCancel(ctx)).Model(&Campaign{}).
+		Where("id = ? AND status IN ?", c.ID, []Status{StatusQueued, StatusSending}).
+		Updates(map[string]interface{}{"status": StatusFailed, "error": truncate(cause.Error(), 1024)}).Error
+	if err != nil {
+		m.log.Error("failed to mark campaign as failed", zap.Uint64("campaign_id", c.ID), zap.Error(err))
+	}
+}
+
+// audit records action on the campaign
+func (m *Manager) audit(ctx context.Context, action audit.Action, c *Campaign, opts ...audit.Option) {
+	opts = append(opts, audit.WithTenant(c.TenantID), audit.WithMetadata("name", c.Name))
+	if err := audit.Record(ctx, action, audit.Resource{Type: "campaign", ID: strconv.FormatUint(c.ID, 10)}, nil, opts...); err != nil {
+		m.log.Warn("failed to audit campaign", zap.Uint64("campaign_id", c.ID), zap.Error(err))
+	}
+}
+
+func normalize(address string) string {
+	return strings.ToLower(strings.TrimSpace(address))
+}
+
+func set(values []string) map[string]bool {
+	out := make(map[string]bool, len(values))
+	for _, v := range values {
+		out[v] = true
+	}
+	return out
+}
+
+func contains(statuses []Status, s Status) bool {
+	for _, v := range statuses {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
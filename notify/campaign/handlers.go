@@ -0,0 +1,181 @@
+package campaign
+
+import (
+	"strconv"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/dto"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Handlers exposes campaign management, scoped to the tenant_id set on the context, and the
+// public unsubscribe endpoint
+type Handlers struct {
+	m *Manager
+}
+
+// NewHandlers creates the campaign handlers
+func NewHandlers(m *Manager) *Handlers {
+	return &Handlers{m: m}
+}
+
+// Register mounts the management endpoints on a router group guarded by a permission, e.g.
+// /campaigns
+func (h *Handlers) Register(rg *gin.RouterGroup) {
+	rg.GET("", h.List)
+	rg.POST("", h.Create)
+	rg.GET("/:id", h.Get)
+	rg.GET("/:id/deliveries", h.Deliveries)
+	rg.POST("/:id/start", h.Start)
+	rg.POST("/:id/cancel", h.Cancel)
+}
+
+// RegisterPublic mounts the unsubscribe endpoint, the Config.UnsubscribeURL of the emails, on
+// a public router group. GET serves the link; POST serves mail clients' one-click unsubscribe.
+func (h *Handlers) RegisterPublic(rg *gin.RouterGroup) {
+	rg.GET("/unsubscribe", h.Unsubscribe)
+	rg.POST("/unsubscribe", h.Unsubscribe)
+}
+
+// List pages through the tenant's campaigns
+func (h *Handlers) List(c *gin.Context) {
+	page, limit := pagination(c)
+	items, total, err := h.m.List(c.Request.Context(), ctxutil.TenantID(c), page, limit)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if items == nil {
+		items = []Campaign{}
+	}
+	response.OK(c, dto.BuildPaginatedResponse(items, total, page, limit))
+}
+
+// Create stores a draft campaign as the current user
+func (h *Handlers) Create(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var in Input
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.BadRequest(c, i18n.T(c, "campaign.invalid_request"), response.ProcessBindingError(c, err))
+		return
+	}
+	campaign, err := h.m.Create(c.Request.Context(), ctxutil.TenantID(c), userID, in)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.Created(c, campaign)
+}
+
+// Get returns a campaign with its progress
+func (h *Handlers) Get(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	campaign, err := h.m.Get(c.Request.Context(), ctxutil.TenantID(c), id)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, campaign)
+}
+
+// Deliveries pages through a campaign's deliveries; ?status=failed filters them
+func (h *Handlers) Deliveries(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	page, limit := pagination(c)
+	items, total, err := h.m.Deliveries(c.Request.Context(), ctxutil.TenantID(c), id, DeliveryStatus(c.Query("status")), page, limit)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if items == nil {
+		items = []Delivery{}
+	}
+	response.OK(c, dto.BuildPaginatedResponse(items, total, page, limit))
+}
+
+// Start queues a draft campaign for sending
+func (h *Handlers) Start(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	campaign, err := h.m.Start(c.Request.Context(), ctxutil.TenantID(c), id, userID)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.Accepted(c, campaign)
+}
+
+// Cancel stops a campaign
+func (h *Handlers) Cancel(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	campaign, err := h.m.Cancel(c.Request.Context(), ctxutil.TenantID(c), id, userID)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, campaign)
+}
+
+// Unsubscribe applies the ?token= of an unsubscribe link
+func (h *Handlers) Unsubscribe(c *gin.Context) {
+	u, err := h.m.Unsubscribe(c.Request.Context(), c.Query("token"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, gin.H{"list": u.List}, i18n.T(c, "campaign.unsubscribed"))
+}
+
+func currentUser(c *gin.Context) (uint64, bool) {
+	id, ok := ctxutil.UserID(c)
+	if !ok {
+		response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
+		return 0, false
+	}
+	return id, true
+}
+
+func pagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return page, limit
+}
+
+func paramID(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, i18n.T(c, "campaign.invalid_request"))
+		return 0, false
+	}
+	return id, true
+}
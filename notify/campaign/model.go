@@ -0,0 +1,97 @@
+package campaign
+
+import (
+	"time"
+
+	"github.com/Masharah-Advisory/common/model"
+)
+
+// Status is the lifecycle state of a campaign
+type Status string
+
+const (
+	StatusDraft     Status = "draft"
+	StatusQueued    Status = "queued"
+	StatusSending   Status = "sending"
+	StatusCompleted Status = "completed"
+	StatusCancelled Status = "cancelled"
+	StatusFailed    Status = "failed"
+)
+
+// DeliveryStatus is the outcome for one recipient
+type DeliveryStatus string
+
+const (
+	DeliverySent    DeliveryStatus = "sent"
+	DeliveryFailed  DeliveryStatus = "failed"
+	DeliverySkipped DeliveryStatus = "skipped" // unsubscribed
+)
+
+// ListAll unsubscribes an address from every list of its tenant
+const ListAll = "*"
+
+// Campaign is one templated email sent to every member of an audience
+type Campaign struct {
+	model.Base
+	TenantID string `json:"tenant_id,omitempty" gorm:"index;size:64"`
+	Name     string `json:"name" gorm:"size:255;not null"`
+	// List is what recipients unsubscribe from, e.g. "newsletter"
+	List     string                 `json:"list" gorm:"size:64;not null"`
+	Template string                 `json:"template" gorm:"size:128;not null"`
+	Data     map[string]interface{} `json:"data,omitempty" gorm:"serializer:json"`
+	Audience string                 `json:"audience" gorm:"size:64;not null"`
+	Params   map[string]interface{} `json:"params,omitempty" gorm:"serializer:json"`
+	Status   Status                 `json:"status" gorm:"index;size:16;not null"`
+
+	// Progress; Total is 0 until sending starts, or stays 0 when the audience cannot count
+	Total   int `json:"total"`
+	Sent    int `json:"sent"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+
+	Error       string     `json:"error,omitempty" gorm:"size:1024"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// TableName overrides the table name
+func (Campaign) TableName() string {
+	return "email_campaigns"
+}
+
+// Processed returns how many recipients have been handled so far
+func (c *Campaign) Processed() int {
+	return c.Sent + c.Failed + c.Skipped
+}
+
+// Delivery records what happened for one recipient of a campaign; a campaign resumed after a
+// restart skips the addresses it already has a delivery for
+type Delivery struct {
+	ID         uint64         `json:"id" gorm:"primaryKey;autoIncrement"`
+	CreatedAt  time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	CampaignID uint64         `json:"campaign_id" gorm:"uniqueIndex:idx_campaign_delivery;not null"`
+	Email      string         `json:"email" gorm:"uniqueIndex:idx_campaign_delivery;size:320;not null"`
+	UserID     uint64         `json:"user_id,omitempty" gorm:"index"`
+	Status     DeliveryStatus `json:"status" gorm:"index;size:16;not null"`
+	Error      string         `json:"error,omitempty" gorm:"size:1024"`
+}
+
+// TableName overrides the table name
+func (Delivery) TableName() string {
+	return "email_campaign_deliveries"
+}
+
+// Unsubscribe keeps an address from receiving campaigns of a list, or of every list with ListAll
+type Unsubscribe struct {
+	ID         uint64    `json:"id" gorm:"primaryKey;autoIncrement"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	TenantID   string    `json:"tenant_id,omitempty" gorm:"uniqueIndex:idx_campaign_unsubscribe;size:64"`
+	Email      string    `json:"email" gorm:"uniqueIndex:idx_campaign_unsubscribe;size:320;not null"`
+	List       string    `json:"list" gorm:"uniqueIndex:idx_campaign_unsubscribe;size:64;not null"`
+	CampaignID *uint64   `json:"campaign_id,omitempty"`
+}
+
+// TableName overrides the table name
+func (Unsubscribe) TableName() string {
+	return "email_campaign_unsubscribes"
+}
@@ -0,0 +1,74 @@
+package email
+
+import (
+	"context"
+	"sync"
+)
+
+// CaptureProvider records messages instead of sending them, for tests and local development
+type CaptureProvider struct {
+	mu       sync.Mutex
+	messages []Message
+	// Err, when set, is returned from Send to simulate provider failures
+	Err error
+}
+
+// NewCaptureProvider creates a capturing provider
+func NewCaptureProvider() *CaptureProvider {
+	return &CaptureProvider{}
+}
+
+// Name implements Provider
+func (p *CaptureProvider) Name() string { return "capture" }
+
+// Send implements Provider
+func (p *CaptureProvider) Send(ctx context.Context, msg *Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Err != nil {
+		return p.Err
+	}
+	p.messages = append(p.messages, *msg)
+	return nil
+}
+
+// Messages returns a copy of all captured messages
+func (p *CaptureProvider) Messages() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Message(nil), p.messages...)
+}
+
+// Last returns the most recently captured message
+func (p *CaptureProvider) Last() (Message, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.messages) == 0 {
+		return Message{}, false
+	}
+	return p.messages[len(p.messages)-1], true
+}
+
+// SentTo returns the captured messages addressed to email
+func (p *CaptureProvider) SentTo(email string) []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var out []Message
+	for _, m := range p.messages {
+		for _, a := range m.Recipients() {
+			if a.Email == email {
+				out = append(out, m)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// Reset clears captured messages
+func (p *CaptureProvider) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = nil
+}
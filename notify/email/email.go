@@ -0,0 +1,97 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// Address is an email address with an optional display name
+type Address struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email"`
+}
+
+// String formats the address for a mail header
+func (a Address) String() string {
+	return (&mail.Address{Name: a.Name, Address: a.Email}).String()
+}
+
+// Attachment is a file attached to a message; Inline attachments can be referenced as cid:ContentID
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"data"`
+	Inline      bool   `json:"inline,omitempty"`
+	ContentID   string `json:"content_id,omitempty"`
+}
+
+// Message is a fully rendered email
+type Message struct {
+	From        Address           `json:"from"`
+	To          []Address         `json:"to"`
+	Cc          []Address         `json:"cc,omitempty"`
+	Bcc         []Address         `json:"bcc,omitempty"`
+	ReplyTo     *Address          `json:"reply_to,omitempty"`
+	Subject     string            `json:"subject"`
+	HTML        string            `json:"html,omitempty"`
+	Text        string            `json:"text,omitempty"`
+	Attachments []Attachment      `json:"attachments,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	TenantID    string            `json:"tenant_id,omitempty"`
+}
+
+// Provider delivers rendered messages
+type Provider interface {
+	Name() string
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Validate checks the message has a sender, at least one recipient, a subject and a body, and
+// that custom headers are single-line
+func (m *Message) Validate() error {
+	var errs []error
+	if m.From.Email == "" {
+		errs = append(errs, errors.New("missing sender"))
+	}
+	if len(m.To)+len(m.Cc)+len(m.Bcc) == 0 {
+		errs = append(errs, errors.New("no recipients"))
+	}
+	for _, a := range m.Recipients() {
+		if _, err := mail.ParseAddress(a.Email); err != nil {
+			errs = append(errs, fmt.Errorf("invalid recipient %q", a.Email))
+		}
+	}
+	if strings.TrimSpace(m.Subject) == "" {
+		errs = append(errs, errors.New("missing subject"))
+	}
+	if m.HTML == "" && m.Text == "" {
+		errs = append(errs, errors.New("empty body"))
+	}
+	for k, v := range m.Headers {
+		if err := checkHeader(k, v); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Recipients returns To, Cc and Bcc combined
+func (m *Message) Recipients() []Address {
+	all := make([]Address, 0, len(m.To)+len(m.Cc)+len(m.Bcc))
+	all = append(all, m.To...)
+	all = append(all, m.Cc...)
+	return append(all, m.Bcc...)
+}
+
+// emails returns the bare addresses of a list
+func emails(addrs []Address) []string {
+	out := make([]string, 0, len(addrs))
+	for _, a := range addrs {
+		out = append(out, a.Email)
+	}
+	return out
+}
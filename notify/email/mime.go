@@ -0,0 +1,177 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BuildMIME renders msg as an RFC 5322 message (multipart/mixed > related > alternative).
+// Bcc recipients are intentionally not written to the headers.
+func BuildMIME(msg *Message) ([]byte, error) {
+	var buf bytes.Buffer
+
+	header := textproto.MIMEHeader{}
+	header.Set("From", msg.From.String())
+	header.Set("To", joinAddresses(msg.To))
+	if len(msg.Cc) > 0 {
+		header.Set("Cc", joinAddresses(msg.Cc))
+	}
+	if msg.ReplyTo != nil {
+		header.Set("Reply-To", msg.ReplyTo.String())
+	}
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	header.Set("Message-ID", fmt.Sprintf("<%s@%s>", randomID(), domainOf(msg.From.Email)))
+	header.Set("MIME-Version", "1.0")
+	for k, v := range msg.Headers {
+		if err := checkHeader(k, v); err != nil {
+			return nil, err
+		}
+		header.Set(k, v)
+	}
+
+	var inline, attached []Attachment
+	for _, a := range msg.Attachments {
+		if a.Inline {
+			inline = append(inline, a)
+		} else {
+			attached = append(attached, a)
+		}
+	}
+
+	mixed := "mixed_" + randomID()
+	related := "related_" + randomID()
+	alternative := "alt_" + randomID()
+
+	header.Set("Content-Type", fmt.Sprintf("multipart/mixed; boundary=%q", mixed))
+	writeHeader(&buf, header)
+
+	// Body: alternative (text + html), wrapped in related when there are inline images
+	fmt.Fprintf(&buf, "--%s\r\n", mixed)
+	if len(inline) > 0 {
+		fmt.Fprintf(&buf, "Content-Type: multipart/related; boundary=%q\r\n\r\n", related)
+		fmt.Fprintf(&buf, "--%s\r\n", related)
+	}
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", alternative)
+	if msg.Text != "" {
+		if err := writeTextPart(&buf, alternative, "text/plain", msg.Text); err != nil {
+			return nil, err
+		}
+	}
+	if msg.HTML != "" {
+		if err := writeTextPart(&buf, alternative, "text/html", msg.HTML); err != nil {
+			return nil, err
+		}
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", alternative)
+
+	if len(inline) > 0 {
+		for _, a := range inline {
+			writeAttachment(&buf, related, a)
+		}
+		fmt.Fprintf(&buf, "--%s--\r\n", related)
+	}
+
+	for _, a := range attached {
+		writeAttachment(&buf, mixed, a)
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", mixed)
+
+	return buf.Bytes(), nil
+}
+
+// checkHeader rejects custom headers that would end the header line early and inject headers
+// or a body of their own
+func checkHeader(name, value string) error {
+	if name == "" || strings.ContainsAny(name, "\r\n: \t") {
+		return fmt.Errorf("invalid header name %q", name)
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("invalid value for header %s: line breaks are not allowed", name)
+	}
+	return nil
+}
+
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(buf, "%s: %s\r\n", k, header.Get(k))
+	}
+	buf.WriteString("\r\n")
+}
+
+func writeTextPart(buf *bytes.Buffer, boundary, contentType, body string) error {
+	fmt.Fprintf(buf, "--%s\r\n", boundary)
+	fmt.Fprintf(buf, "Content-Type: %s; charset=utf-8\r\n", contentType)
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	qp := quotedprintable.NewWriter(buf)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return fmt.Errorf("failed to encode %s part: %w", contentType, err)
+	}
+	if err := qp.Close(); err != nil {
+		return fmt.Errorf("failed to encode %s part: %w", contentType, err)
+	}
+	buf.WriteString("\r\n")
+	return nil
+}
+
+func writeAttachment(buf *bytes.Buffer, boundary string, a Attachment) {
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	disposition := "attachment"
+	if a.Inline {
+		disposition = "inline"
+	}
+
+	fmt.Fprintf(buf, "--%s\r\n", boundary)
+	fmt.Fprintf(buf, "Content-Type: %s; name=%q\r\n", contentType, mime.QEncoding.Encode("utf-8", a.Filename))
+	fmt.Fprintf(buf, "Content-Disposition: %s; filename=%q\r\n", disposition, mime.QEncoding.Encode("utf-8", a.Filename))
+	if a.ContentID != "" {
+		fmt.Fprintf(buf, "Content-ID: <%s>\r\n", a.ContentID)
+	}
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString(a.Data)
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+}
+
+func joinAddresses(addrs []Address) string {
+	parts := make([]string, 0, len(addrs))
+	for _, a := range addrs {
+		parts = append(parts, a.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+func domainOf(email string) string {
+	if i := strings.LastIndex(email, "@"); i != -1 {
+		return email[i+1:]
+	}
+	return "localhost"
+}
+
+func randomID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,42 @@
+package email
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestBuildMIMERejectsHeaderInjection checks that custom headers can't break out of their line
+func TestBuildMIMERejectsHeaderInjection(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		wantErr bool
+	}{
+		{"plain", map[string]string{"X-Campaign": "spring"}, false},
+		{"CRLF in value", map[string]string{"X-Campaign": "spring\r\nBcc: victim@example.com"}, true},
+		{"LF in value", map[string]string{"X-Campaign": "spring\nBcc: victim@example.com"}, true},
+		{"CRLF in name", map[string]string{"Bcc: victim@example.com\r\nX-Campaign": "spring"}, true},
+		{"colon in name", map[string]string{"Bcc: victim@example.com": "x"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &Message{
+				From:    Address{Email: "noreply@example.com"},
+				To:      []Address{{Email: "user@example.com"}},
+				Subject: "Hello",
+				Text:    "Hi",
+				Headers: tt.headers,
+			}
+			raw, err := BuildMIME(msg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildMIME() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (msg.Validate() != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", msg.Validate(), tt.wantErr)
+			}
+			if err == nil && strings.Contains(string(raw), "Bcc:") {
+				t.Fatalf("message carries an injected header:\n%s", raw)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/worker"
+	"go.uber.org/zap"
+)
+
+// SendJob is the worker job name used by Queue
+const SendJob = "email.send"
+
+// TenantSender is the per-tenant sender identity
+type TenantSender struct {
+	From    Address
+	ReplyTo *Address
+}
+
+// TenantResolver returns the sender identity for a tenant; ok=false falls back to the default
+type TenantResolver func(ctx context.Context, tenantID string) (TenantSender, bool, error)
+
+// SenderConfig holds the sender settings
+type SenderConfig struct {
+	Provider    Provider
+	Renderer    *Renderer      // required for SendTemplate
+	Default     TenantSender   // used when a message has no From and no tenant override
+	Tenants     TenantResolver // optional
+	Worker      *worker.Manager
+	Retry       worker.JobOptions // defaults to 5 retries, 10s backoff, 30s timeout
+	SendTimeout time.Duration
+}
+
+// TemplateMessage is a message rendered from a template at send time
+type TemplateMessage struct {
+	Template    string                 `json:"template"`
+	Lang        string                 `json:"lang"`
+	Data        map[string]interface{} `json:"data,omitempty"`
+	To          []Address              `json:"to"`
+	Cc          []Address              `json:"cc,omitempty"`
+	Bcc         []Address              `json:"bcc,omitempty"`
+	Attachments []Attachment           `json:"attachments,omitempty"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	TenantID    string                 `json:"tenant_id,omitempty"`
+}
+
+// Sender renders, addresses and delivers email, optionally through the worker queue
+type Sender struct {
+	cfg *SenderConfig
+	log *zap.Logger
+}
+
+// NewSender creates a sender and registers the send job on cfg.Worker when set
+func NewSender(cfg *SenderConfig) (*Sender, error) {
+	if cfg.Provider == nil {
+		return nil, fmt.Errorf("email provider is required")
+	}
+	if cfg.SendTimeout <= 0 {
+		cfg.SendTimeout = 30 * time.Second
+	}
+
+	s := &Sender{cfg: cfg, log: logger.Module("email")}
+
+	if cfg.Worker != nil {
+		opts := cfg.Retry
+		if opts.MaxRetries == 0 && opts.RetryBackoff == 0 {
+			opts.MaxRetries = 5
+			opts.RetryBackoff = 10 * time.Second
+		}
+		if opts.Timeout == 0 {
+			opts.Timeout = cfg.SendTimeout
+		}
+		cfg.Worker.Register(SendJob, s.handleTask, opts)
+	}
+
+	return s, nil
+}
+
+// Send delivers msg immediately, filling in the tenant sender identity
+func (s *Sender) Send(ctx context.Context, msg *Message) error {
+	if err := s.address(ctx, msg); err != nil {
+		return err
+	}
+	if err := msg.Validate(); err != nil {
+		return fmt.Errorf("invalid email: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.SendTimeout)
+	defer cancel()
+
+	if err := s.cfg.Provider.Send(ctx, msg); err != nil {
+		s.log.Warn("email send failed",
+			zap.String("provider", s.cfg.Provider.Name()),
+			zap.String("subject", msg.Subject),
+			zap.Int("recipients", len(msg.Recipients())),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to send email via %s: %w", s.cfg.Provider.Name(), err)
+	}
+
+	s.log.Info("email sent",
+		zap.String("provider", s.cfg.Provider.Name()),
+		zap.String("tenant_id", msg.TenantID),
+		zap.Int("recipients", len(msg.Recipients())),
+	)
+	return nil
+}
+
+// Render renders a template message into a Message
+func (s *Sender) Render(tm *TemplateMessage) (*Message, error) {
+	if s.cfg.Renderer == nil {
+		return nil, fmt.Errorf("email renderer is not configured")
+	}
+	rendered, err := s.cfg.Renderer.Render(tm.Template, tm.Lang, tm.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := map[string]string{"template": tm.Template}
+	for k, v := range tm.Tags {
+		tags[k] = v
+	}
+
+	return &Message{
+		To:          tm.To,
+		Cc:          tm.Cc,
+		Bcc:         tm.Bcc,
+		Subject:     rendered.Subject,
+		HTML:        rendered.HTML,
+		Text:        rendered.Text,
+		Attachments: tm.Attachments,
+		Tags:        tags,
+		TenantID:    tm.TenantID,
+	}, nil
+}
+
+// Wrap places a rendered HTML body in the renderer's layout; without a renderer the body is
+// returned as is
+func (s *Sender) Wrap(lang, subject, html string) (string, error) {
+	if s.cfg.Renderer == nil || html == "" {
+		return html, nil
+	}
+	return s.cfg.Renderer.Wrap(lang, subject, html)
+}
+
+// SendTemplate renders and delivers a template message immediately
+func (s *Sender) SendTemplate(ctx context.Context, tm *TemplateMessage) error {
+	msg, err := s.Render(tm)
+	if err != nil {
+		return err
+	}
+	return s.Send(ctx, msg)
+}
+
+// Queue renders msg now and enqueues it for delivery with retries; without a worker it sends inline
+func (s *Sender) Queue(ctx context.Context, msg *Message) (string, error) {
+	if s.cfg.Worker == nil {
+		return "", s.Send(ctx, msg)
+	}
+	if err := s.address(ctx, msg); err != nil {
+		return "", err
+	}
+	if err := msg.Validate(); err != nil {
+		return "", fmt.Errorf("invalid email: %w", err)
+	}
+	return s.cfg.Worker.Enqueue(ctx, SendJob, msg)
+}
+
+// QueueTemplate renders a template message and enqueues it
+func (s *Sender) QueueTemplate(ctx context.Context, tm *TemplateMessage) (string, error) {
+	msg, err := s.Render(tm)
+	if err != nil {
+		return "", err
+	}
+	return s.Queue(ctx, msg)
+}
+
+func (s *Sender) handleTask(ctx context.Context, task *worker.Task) error {
+	var msg Message
+	if err := task.Decode(&msg); err != nil {
+		return err
+	}
+	return s.Send(ctx, &msg)
+}
+
+// address fills From and ReplyTo from the tenant config or the default sender
+func (s *Sender) address(ctx context.Context, msg *Message) error {
+	if msg.From.Email != "" {
+		return nil
+	}
+
+	identity := s.cfg.Default
+	if msg.TenantID != "" && s.cfg.Tenants != nil {
+		tenant, ok, err := s.cfg.Tenants(ctx, msg.TenantID)
+		if err != nil {
+			return fmt.Errorf("failed to resolve sender for tenant %s: %w", msg.TenantID, err)
+		}
+		if ok {
+			identity = tenant
+		}
+	}
+
+	msg.From = identity.From
+	if msg.ReplyTo == nil {
+		msg.ReplyTo = identity.ReplyTo
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// SendGridConfig holds SendGrid API settings
+type SendGridConfig struct {
+	APIKey  string
+	BaseURL string // defaults to https://api.sendgrid.com
+}
+
+// SendGridProvider sends messages through the SendGrid v3 mail API
+type SendGridProvider struct {
+	cfg    *SendGridConfig
+	client *http.Client
+}
+
+// NewSendGridProvider creates a SendGrid provider
+func NewSendGridProvider(cfg *SendGridConfig) *SendGridProvider {
+	return &SendGridProvider{cfg: cfg, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Name implements Provider
+func (p *SendGridProvider) Name() string { return "sendgrid" }
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+func toSendGrid(addrs []Address) []sendGridAddress {
+	out := make([]sendGridAddress, 0, len(addrs))
+	for _, a := range addrs {
+		out = append(out, sendGridAddress{Email: a.Email, Name: a.Name})
+	}
+	return out
+}
+
+// Send implements Provider
+func (p *SendGridProvider) Send(ctx context.Context, msg *Message) error {
+	personalization := map[string]interface{}{"to": toSendGrid(msg.To)}
+	if len(msg.Cc) > 0 {
+		personalization["cc"] = toSendGrid(msg.Cc)
+	}
+	if len(msg.Bcc) > 0 {
+		personalization["bcc"] = toSendGrid(msg.Bcc)
+	}
+
+	var content []map[string]string
+	if msg.Text != "" {
+		content = append(content, map[string]string{"type": "text/plain", "value": msg.Text})
+	}
+	if msg.HTML != "" {
+		content = append(content, map[string]string{"type": "text/html", "value": msg.HTML})
+	}
+
+	payload := map[string]interface{}{
+		"personalizations": []interface{}{personalization},
+		"from":             sendGridAddress{Email: msg.From.Email, Name: msg.From.Name},
+		"subject":          msg.Subject,
+		"content":          content,
+	}
+	if msg.ReplyTo != nil {
+		payload["reply_to"] = sendGridAddress{Email: msg.ReplyTo.Email, Name: msg.ReplyTo.Name}
+	}
+	if len(msg.Headers) > 0 {
+		payload["headers"] = msg.Headers
+	}
+	if len(msg.Tags) > 0 {
+		payload["custom_args"] = msg.Tags
+	}
+	if len(msg.Attachments) > 0 {
+		attachments := make([]map[string]string, 0, len(msg.Attachments))
+		for _, a := range msg.Attachments {
+			att := map[string]string{
+				"content":     base64.StdEncoding.EncodeToString(a.Data),
+				"filename":    a.Filename,
+				"type":        a.ContentType,
+				"disposition": "attachment",
+			}
+			if a.Inline {
+				att["disposition"] = "inline"
+				att["content_id"] = a.ContentID
+			}
+			attachments = append(attachments, att)
+		}
+		payload["attachments"] = attachments
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sendgrid request: %w", err)
+	}
+
+	baseURL := p.cfg.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.sendgrid.com"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/v3/mail/send", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create sendgrid request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.cfg.APIKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sendgrid request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("sendgrid returned error [%d]: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SESConfig holds Amazon SES credentials
+type SESConfig struct {
+	Region           string
+	AccessKeyID      string
+	SecretAccessKey  string
+	SessionToken     string
+	ConfigurationSet string
+	Endpoint         string // overrides https://email.<region>.amazonaws.com (e.g. for LocalStack)
+}
+
+// SESProvider sends raw MIME messages through the SES v2 API
+type SESProvider struct {
+	cfg    *SESConfig
+	client *http.Client
+}
+
+// NewSESProvider creates an SES provider
+func NewSESProvider(cfg *SESConfig) *SESProvider {
+	return &SESProvider{cfg: cfg, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Name implements Provider
+func (p *SESProvider) Name() string { return "ses" }
+
+// Send implements Provider
+func (p *SESProvider) Send(ctx context.Context, msg *Message) error {
+	raw, err := BuildMIME(msg)
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"FromEmailAddress": msg.From.String(),
+		"Destination": map[string][]string{
+			"ToAddresses":  emails(msg.To),
+			"CcAddresses":  emails(msg.Cc),
+			"BccAddresses": emails(msg.Bcc),
+		},
+		"Content": map[string]interface{}{
+			"Raw": map[string]string{"Data": base64.StdEncoding.EncodeToString(raw)},
+		},
+	}
+	if p.cfg.ConfigurationSet != "" {
+		payload["ConfigurationSetName"] = p.cfg.ConfigurationSet
+	}
+	if len(msg.Tags) > 0 {
+		tags := make([]map[string]string, 0, len(msg.Tags))
+		for k, v := range msg.Tags {
+			tags = append(tags, map[string]string{"Name": k, "Value": v})
+		}
+		payload["EmailTags"] = tags
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ses request: %w", err)
+	}
+
+	endpoint := p.cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://email.%s.amazonaws.com", p.cfg.Region)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/v2/email/outbound-emails", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create ses request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	signSigV4(req, body, "ses", p.cfg.Region, p.cfg.AccessKeyID, p.cfg.SecretAccessKey, p.cfg.SessionToken, time.Now().UTC())
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ses request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("ses returned error [%d]: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// signSigV4 signs req with AWS Signature Version 4
+func signSigV4(req *http.Request, body []byte, service, region, accessKey, secretKey, sessionToken string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature,
+	))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
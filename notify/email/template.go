@@ -0,0 +1,246 @@
+package email
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+
+	"github.com/Masharah-Advisory/common/i18n"
+)
+
+// ErrTemplateNotFound is returned when neither an HTML nor a text template exists
+var ErrTemplateNotFound = errors.New("email template not found")
+
+// Rendered is the output of a template render
+type Rendered struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Renderer renders <name>.<lang>.html and <name>.<lang>.txt templates from a file system,
+// falling back to <name>.html / <name>.txt. HTML bodies are wrapped in a layout that sets
+// lang and dir so Arabic mail renders right-to-left.
+type Renderer struct {
+	fsys   fs.FS
+	layout *htmltemplate.Template
+
+	mu    sync.RWMutex
+	html  map[string]*htmltemplate.Template
+	text  map[string]*texttemplate.Template
+	cache bool
+}
+
+// NewRenderer creates a renderer over fsys. A "layout.html" file in fsys replaces the default layout;
+// it receives .Lang, .Dir, .Subject and .Body.
+func NewRenderer(fsys fs.FS) (*Renderer, error) {
+	r := &Renderer{
+		fsys:  fsys,
+		html:  make(map[string]*htmltemplate.Template),
+		text:  make(map[string]*texttemplate.Template),
+		cache: true,
+	}
+
+	layoutSrc := defaultLayout
+	if data, err := fs.ReadFile(fsys, "layout.html"); err == nil {
+		layoutSrc = string(data)
+	}
+	layout, err := htmltemplate.New("layout").Parse(layoutSrc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email layout: %w", err)
+	}
+	r.layout = layout
+	return r, nil
+}
+
+// DisableCache re-parses templates on every render, useful while editing templates locally
+func (r *Renderer) DisableCache() {
+	r.cache = false
+}
+
+// Render renders the named template in lang with data. Templates can call {{t "key"}} to translate
+// and may define a "subject" block; otherwise the subject is the i18n key email.<name>.subject.
+func (r *Renderer) Render(name, lang string, data map[string]interface{}) (*Rendered, error) {
+	if i18n.IsRTL(lang) {
+		lang = "ar"
+	} else {
+		lang = "en"
+	}
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+
+	htmlTmpl, err := r.htmlTemplate(name, lang)
+	if err != nil {
+		return nil, err
+	}
+	textTmpl, err := r.textTemplate(name, lang)
+	if err != nil {
+		return nil, err
+	}
+	if htmlTmpl == nil && textTmpl == nil {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	out := &Rendered{}
+
+	if htmlTmpl != nil {
+		var body bytes.Buffer
+		if err := htmlTmpl.Execute(&body, data); err != nil {
+			return nil, fmt.Errorf("failed to render email template %s: %w", name, err)
+		}
+		if t := htmlTmpl.Lookup("subject"); t != nil {
+			var subject bytes.Buffer
+			if err := t.Execute(&subject, data); err != nil {
+				return nil, fmt.Errorf("failed to render email subject %s: %w", name, err)
+			}
+			out.Subject = strings.TrimSpace(subject.String())
+		}
+		out.HTML = body.String()
+	}
+
+	if textTmpl != nil {
+		var body bytes.Buffer
+		if err := textTmpl.Execute(&body, data); err != nil {
+			return nil, fmt.Errorf("failed to render email text template %s: %w", name, err)
+		}
+		if t := textTmpl.Lookup("subject"); t != nil && out.Subject == "" {
+			var subject bytes.Buffer
+			if err := t.Execute(&subject, data); err != nil {
+				return nil, fmt.Errorf("failed to render email subject %s: %w", name, err)
+			}
+			out.Subject = strings.TrimSpace(subject.String())
+		}
+		out.Text = body.String()
+	}
+
+	if out.Subject == "" {
+		out.Subject = i18n.TLang(lang, "email."+name+".subject", data)
+	}
+
+	if out.HTML != "" {
+		if out.HTML, err = r.Wrap(lang, out.Subject, out.HTML); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// Wrap places an already rendered HTML body in the layout, e.g. for bodies managed outside
+// the renderer's file system
+func (r *Renderer) Wrap(lang, subject, body string) (string, error) {
+	dir := "ltr"
+	if i18n.IsRTL(lang) {
+		dir = "rtl"
+	}
+	var wrapped bytes.Buffer
+	if err := r.layout.Execute(&wrapped, map[string]interface{}{
+		"Lang":    lang,
+		"Dir":     dir,
+		"Subject": subject,
+		"Body":    htmltemplate.HTML(body),
+	}); err != nil {
+		return "", fmt.Errorf("failed to render email layout: %w", err)
+	}
+	return wrapped.String(), nil
+}
+
+func (r *Renderer) htmlTemplate(name, lang string) (*htmltemplate.Template, error) {
+	key := name + "." + lang
+	if r.cache {
+		r.mu.RLock()
+		t, ok := r.html[key]
+		r.mu.RUnlock()
+		if ok {
+			return t, nil
+		}
+	}
+
+	src, file, err := r.read(name, lang, "html")
+	if err != nil || src == "" {
+		return nil, err
+	}
+	t, err := htmltemplate.New(file).Funcs(htmltemplate.FuncMap(templateFuncs(lang))).Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email template %s: %w", file, err)
+	}
+
+	if r.cache {
+		r.mu.Lock()
+		r.html[key] = t
+		r.mu.Unlock()
+	}
+	return t, nil
+}
+
+func (r *Renderer) textTemplate(name, lang string) (*texttemplate.Template, error) {
+	key := name + "." + lang
+	if r.cache {
+		r.mu.RLock()
+		t, ok := r.text[key]
+		r.mu.RUnlock()
+		if ok {
+			return t, nil
+		}
+	}
+
+	src, file, err := r.read(name, lang, "txt")
+	if err != nil || src == "" {
+		return nil, err
+	}
+	t, err := texttemplate.New(file).Funcs(texttemplate.FuncMap(templateFuncs(lang))).Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email template %s: %w", file, err)
+	}
+
+	if r.cache {
+		r.mu.Lock()
+		r.text[key] = t
+		r.mu.Unlock()
+	}
+	return t, nil
+}
+
+// read loads the language specific file, falling back to the language neutral one
+func (r *Renderer) read(name, lang, ext string) (string, string, error) {
+	for _, file := range []string{name + "." + lang + "." + ext, name + "." + ext} {
+		data, err := fs.ReadFile(r.fsys, file)
+		if err == nil {
+			return string(data), file, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", file, fmt.Errorf("failed to read email template %s: %w", file, err)
+		}
+	}
+	return "", "", nil
+}
+
+func templateFuncs(lang string) map[string]interface{} {
+	return map[string]interface{}{
+		"t": func(key string, data ...map[string]interface{}) string {
+			return i18n.TLang(lang, key, data...)
+		},
+		"lang": func() string { return lang },
+		"rtl":  func() bool { return i18n.IsRTL(lang) },
+	}
+}
+
+const defaultLayout = `<!DOCTYPE html>
+<html lang="{{.Lang}}" dir="{{.Dir}}">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>{{.Subject}}</title>
+</head>
+<body dir="{{.Dir}}" style="margin:0;padding:0;background:#f5f5f5;">
+<div style="max-width:600px;margin:0 auto;padding:24px;background:#ffffff;font-family:Arial,Tahoma,sans-serif;text-align:{{if eq .Dir "rtl"}}right{{else}}left{{end}};direction:{{.Dir}};">
+{{.Body}}
+</div>
+</body>
+</html>`
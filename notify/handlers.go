@@ -0,0 +1,132 @@
+package notify
+
+import (
+	"strconv"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/dto"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Handlers exposes the in-app notification center for the authenticated user
+type Handlers struct {
+	store *InAppStore
+}
+
+// NewHandlers creates the in-app notification handlers
+func NewHandlers(store *InAppStore) *Handlers {
+	return &Handlers{store: store}
+}
+
+// Register mounts the handlers on a router group, e.g. /notifications
+func (h *Handlers) Register(rg *gin.RouterGroup) {
+	rg.GET("", h.List)
+	rg.GET("/unread-count", h.UnreadCount)
+	rg.POST("/read", h.MarkRead)
+	rg.POST("/read-all", h.MarkAllRead)
+	rg.DELETE("/:id", h.Delete)
+}
+
+// List returns the user's notifications; ?unread=true filters unread ones
+func (h *Handlers) List(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	items, total, err := h.store.List(c.Request.Context(), userID, c.Query("unread") == "true", page, limit)
+	if err != nil {
+		response.InternalErrorWithCause(c, err)
+		return
+	}
+	response.OK(c, dto.BuildPaginatedResponse(items, total, page, limit))
+}
+
+// UnreadCount returns the number of unread notifications
+func (h *Handlers) UnreadCount(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	count, err := h.store.UnreadCount(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalErrorWithCause(c, err)
+		return
+	}
+	response.OK(c, gin.H{"unread": count})
+}
+
+type markReadRequest struct {
+	IDs []uint64 `json:"ids" binding:"required,min=1"`
+}
+
+// MarkRead marks the given notifications as read
+func (h *Handlers) MarkRead(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var req markReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, i18n.T(c, "notify.invalid_request"), response.ProcessBindingError(c, err))
+		return
+	}
+	updated, err := h.store.MarkRead(c.Request.Context(), userID, req.IDs...)
+	if err != nil {
+		response.InternalErrorWithCause(c, err)
+		return
+	}
+	response.OK(c, gin.H{"updated": updated})
+}
+
+// MarkAllRead marks every notification as read
+func (h *Handlers) MarkAllRead(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	updated, err := h.store.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalErrorWithCause(c, err)
+		return
+	}
+	response.OK(c, gin.H{"updated": updated})
+}
+
+// Delete removes a notification
+func (h *Handlers) Delete(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, i18n.T(c, "notify.invalid_request"))
+		return
+	}
+	if err := h.store.Delete(c.Request.Context(), userID, id); err != nil {
+		response.InternalErrorWithCause(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+// currentUser reads the user id set by the auth middleware, writing a 401 when it is missing
+func currentUser(c *gin.Context) (uint64, bool) {
+	id, ok := ctxutil.UserID(c)
+	if !ok {
+		response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,120 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masharah-Advisory/common/model"
+	"gorm.io/gorm"
+)
+
+// Notification is an in-app notification shown in the user's notification center
+type Notification struct {
+	model.Base
+	UserID   uint64                 `json:"user_id" gorm:"index:idx_notifications_user_read;not null"`
+	TenantID string                 `json:"tenant_id,omitempty" gorm:"index;size:64"`
+	Event    string                 `json:"event" gorm:"size:128;not null"`
+	Title    string                 `json:"title"`
+	Body     string                 `json:"body"`
+	Link     string                 `json:"link,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty" gorm:"serializer:json"`
+	ReadAt   *time.Time             `json:"read_at,omitempty" gorm:"index:idx_notifications_user_read"`
+}
+
+// TableName overrides the table name
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// InAppStore persists in-app notifications
+type InAppStore struct {
+	db *gorm.DB
+}
+
+// NewInAppStore creates a store; run db.Migrate with &Notification{} first
+func NewInAppStore(db *gorm.DB) *InAppStore {
+	return &InAppStore{db: db}
+}
+
+// Create stores a notification
+func (s *InAppStore) Create(ctx context.Context, n *Notification) error {
+	if err := s.db.WithContext(ctx).Create(n).Error; err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+	return nil
+}
+
+// List returns a page of the user's notifications, newest first
+func (s *InAppStore) List(ctx context.Context, userID uint64, unreadOnly bool, page, limit int) ([]Notification, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+
+	q := s.db.WithContext(ctx).Model(&Notification{}).Where("user_id = ? AND deleted_at IS NULL", userID)
+	if unreadOnly {
+		q = q.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	var items []Notification
+	if err := q.Order("id DESC").Offset((page - 1) * limit).Limit(limit).Find(&items).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	return items, total, nil
+}
+
+// UnreadCount returns the number of unread notifications of a user
+func (s *InAppStore) UnreadCount(ctx context.Context, userID uint64) (int64, error) {
+	var count int64
+	err := s.db.WithContext(ctx).Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL AND deleted_at IS NULL", userID).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead marks the given notifications of a user as read
+func (s *InAppStore) MarkRead(ctx context.Context, userID uint64, ids ...uint64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	res := s.db.WithContext(ctx).Model(&Notification{}).
+		Where("user_id = ? AND id IN ? AND read_at IS NULL", userID, ids).
+		Update("read_at", time.Now())
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// MarkAllRead marks every unread notification of a user as read
+func (s *InAppStore) MarkAllRead(ctx context.Context, userID uint64) (int64, error) {
+	res := s.db.WithContext(ctx).Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// Delete soft-deletes a notification of a user
+func (s *InAppStore) Delete(ctx context.Context, userID, id uint64) error {
+	err := s.db.WithContext(ctx).Model(&Notification{}).
+		Where("user_id = ? AND id = ?", userID, id).
+		Update("deleted_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,383 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Masharah-Advisory/common/i18n"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/notify/email"
+	"github.com/Masharah-Advisory/common/notify/push"
+	"github.com/Masharah-Advisory/common/notify/sms"
+	"go.uber.org/zap"
+)
+
+// Channel is a notification delivery channel
+type Channel string
+
+const (
+	ChannelEmail    Channel = "email"
+	ChannelSMS      Channel = "sms"
+	ChannelWhatsApp Channel = "whatsapp"
+	ChannelPush     Channel = "push"
+	ChannelInApp    Channel = "in_app"
+)
+
+// intrusive channels are held back during quiet hours
+var intrusive = map[Channel]bool{ChannelSMS: true, ChannelWhatsApp: true, ChannelPush: true}
+
+// Recipient is the contact information of a user
+type Recipient struct {
+	UserID   uint64
+	TenantID string
+	Name     string
+	Email    string
+	Phone    string
+	Lang     string
+}
+
+// RecipientResolver loads the contact information of a user
+type RecipientResolver func(ctx context.Context, userID uint64) (*Recipient, error)
+
+// Event describes how a notification event is delivered
+type Event struct {
+	Name string
+	// Channels are the defaults when the user has no preference for the event
+	Channels []Channel
+	// Required channels are always used regardless of preferences (e.g. security alerts)
+	Required []Channel
+	// Critical events ignore quiet hours
+	Critical bool
+
+	// TitleKey and BodyKey are i18n keys for push and in-app, rendered with the event data;
+	// they default to notify.<name>.title / notify.<name>.body
+	TitleKey string
+	BodyKey  string
+	// EmailTemplate defaults to Name
+	EmailTemplate string
+	// SMSTemplate defaults to Name; SMSParams lists the data keys passed as positional params
+	SMSTemplate string
+	SMSParams   []string
+	// Link is an optional data key holding a deep link for push and in-app
+	Link string
+}
+
+// Content is copy rendered for one channel. Email uses Subject, HTML and Text; SMS and
+// WhatsApp send Text; push and in-app use Subject as the title and Text as the body.
+type Content struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// ContentResolver returns managed copy for an event, e.g. from the templates package;
+// ok=false falls back to the built-in templates and i18n keys
+type ContentResolver func(ctx context.Context, event string, ch Channel, lang string, data map[string]interface{}) (*Content, bool, error)
+
+// Config holds the dispatcher dependencies; any channel without a sender is skipped
+type Config struct {
+	Recipients  RecipientResolver
+	Content     ContentResolver // optional
+	Preferences *PreferenceStore
+	InApp       *InAppStore
+	Email       *email.Sender
+	SMS         *sms.Sender
+	Push        *push.Sender
+	Events      []Event
+}
+
+// Result lists the channels a notification was delivered to, skipped on, or failed on
+type Result struct {
+	Delivered []Channel          `json:"delivered"`
+	Skipped   []Channel          `json:"skipped,omitempty"`
+	Failed    map[Channel]string `json:"failed,omitempty"`
+}
+
+// Dispatcher fans a single event out to a user's channels
+type Dispatcher struct {
+	cfg *Config
+	log *zap.Logger
+
+	mu     sync.RWMutex
+	events map[string]Event
+	now    func() time.Time
+}
+
+// ErrUnknownEvent is returned by Notify for events that were never registered
+var ErrUnknownEvent = errors.New("unknown notification event")
+
+// NewDispatcher creates a dispatcher
+func NewDispatcher(cfg *Config) (*Dispatcher, error) {
+	if cfg.Recipients == nil {
+		return nil, fmt.Errorf("recipient resolver is required")
+	}
+	d := &Dispatcher{
+		cfg:    cfg,
+		log:    logger.Module("notify"),
+		events: make(map[string]Event),
+		now:    time.Now,
+	}
+	for _, e := range cfg.Events {
+		d.Register(e)
+	}
+	return d, nil
+}
+
+// Register adds or replaces an event definition
+func (d *Dispatcher) Register(e Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.events[e.Name] = e
+}
+
+// Notify delivers event to userID on every channel the user opted into
+func (d *Dispatcher) Notify(ctx context.Context, userID uint64, event string, data map[string]interface{}) (*Result, error) {
+	d.mu.RLock()
+	e, ok := d.events[event]
+	d.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEvent, event)
+	}
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+
+	recipient, err := d.cfg.Recipients(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve recipient %d: %w", userID, err)
+	}
+	if recipient.Lang == "" {
+		recipient.Lang = "en"
+	}
+
+	channels, err := d.channels(ctx, userID, e)
+	if err != nil {
+		return nil, err
+	}
+
+	quiet := false
+	if !e.Critical && d.cfg.Preferences != nil {
+		q, err := d.cfg.Preferences.QuietHours(ctx, userID)
+		if err != nil {
+			d.log.Warn("failed to load quiet hours", zap.Uint64("user_id", userID), zap.Error(err))
+		}
+		quiet = q.Active(d.now())
+	}
+
+	result := &Result{Failed: map[Channel]string{}}
+	for _, ch := range channels {
+		if quiet && intrusive[ch] {
+			result.Skipped = append(result.Skipped, ch)
+			continue
+		}
+		delivered, err := d.deliver(ctx, ch, e, recipient, data)
+		switch {
+		case err != nil:
+			result.Failed[ch] = err.Error()
+			d.log.Warn("notification delivery failed",
+				zap.String("event", e.Name),
+				zap.String("channel", string(ch)),
+				zap.Uint64("user_id", userID),
+				zap.Error(err),
+			)
+		case delivered:
+			result.Delivered = append(result.Delivered, ch)
+		default:
+			result.Skipped = append(result.Skipped, ch)
+		}
+	}
+
+	if len(result.Delivered) == 0 && len(result.Failed) > 0 {
+		return result, fmt.Errorf("notification %s was not delivered to user %d", e.Name, userID)
+	}
+	return result, nil
+}
+
+// NotifyMany notifies several users, collecting per-user errors
+func (d *Dispatcher) NotifyMany(ctx context.Context, userIDs []uint64, event string, data map[string]interface{}) error {
+	var errs []error
+	for _, id := range userIDs {
+		if _, err := d.Notify(ctx, id, event, data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// channels merges stored preferences with the event's required channels
+func (d *Dispatcher) channels(ctx context.Context, userID uint64, e Event) ([]Channel, error) {
+	channels := e.Channels
+	if d.cfg.Preferences != nil {
+		var err error
+		if channels, err = d.cfg.Preferences.Channels(ctx, userID, e.Name, e.Channels); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[Channel]bool)
+	var out []Channel
+	for _, ch := range append(append([]Channel{}, e.Required...), channels...) {
+		if !seen[ch] {
+			seen[ch] = true
+			out = append(out, ch)
+		}
+	}
+	return out, nil
+}
+
+// deliver sends on one channel; it returns false when the channel is not configured or the
+// recipient has no address for it
+func (d *Dispatcher) deliver(ctx context.Context, ch Channel, e Event, r *Recipient, data map[string]interface{}) (bool, error) {
+	switch ch {
+	case ChannelEmail:
+		if d.cfg.Email == nil || r.Email == "" {
+			return false, nil
+		}
+		if content := d.content(ctx, ch, e, r, data); content != nil {
+			html, err := d.cfg.Email.Wrap(r.Lang, content.Subject, content.HTML)
+			if err != nil {
+				return false, err
+			}
+			_, err = d.cfg.Email.Queue(ctx, &email.Message{
+				To:       []email.Address{{Name: r.Name, Email: r.Email}},
+				Subject:  content.Subject,
+				HTML:     html,
+				Text:     content.Text,
+				Tags:     map[string]string{"event": e.Name},
+				TenantID: r.TenantID,
+			})
+			return err == nil, err
+		}
+		_, err := d.cfg.Email.QueueTemplate(ctx, &email.TemplateMessage{
+			Template: firstNonEmpty(e.EmailTemplate, e.Name),
+			Lang:     r.Lang,
+			Data:     data,
+			To:       []email.Address{{Name: r.Name, Email: r.Email}},
+			TenantID: r.TenantID,
+			Tags:     map[string]string{"event": e.Name},
+		})
+		return err == nil, err
+
+	case ChannelSMS, ChannelWhatsApp:
+		if d.cfg.SMS == nil || r.Phone == "" {
+			return false, nil
+		}
+		// WhatsApp business messages must use approved templates, so managed copy is SMS only
+		if ch == ChannelSMS {
+			if content := d.content(ctx, ch, e, r, data); content != nil {
+				_, err := d.cfg.SMS.Send(ctx, sms.ChannelSMS, &sms.Message{
+					To:       r.Phone,
+					Body:     content.Text,
+					Lang:     r.Lang,
+					TenantID: r.TenantID,
+				})
+				return err == nil, err
+			}
+		}
+		params := make([]string, 0, len(e.SMSParams))
+		for _, key := range e.SMSParams {
+			params = append(params, fmt.Sprint(data[key]))
+		}
+		_, err := d.cfg.SMS.Send(ctx, sms.Channel(ch), &sms.Message{
+			To:       r.Phone,
+			Template: firstNonEmpty(e.SMSTemplate, e.Name),
+			Lang:     r.Lang,
+			Params:   params,
+			TenantID: r.TenantID,
+		})
+		return err == nil, err
+
+	case ChannelPush:
+		if d.cfg.Push == nil {
+			return false, nil
+		}
+		pushData := map[string]string{"event": e.Name}
+		if link := d.link(e, data); link != "" {
+			pushData["link"] = link
+		}
+		n := &push.Notification{
+			TitleKey: d.titleKey(e),
+			BodyKey:  d.bodyKey(e),
+			Params:   data,
+			Data:     pushData,
+		}
+		if content := d.content(ctx, ch, e, r, data); content != nil {
+			n = &push.Notification{Title: content.Subject, Body: content.Text, Data: pushData}
+		}
+		_, err := d.cfg.Push.Queue(ctx, push.Target{UserIDs: []uint64{r.UserID}}, n)
+		return err == nil, err
+
+	case ChannelInApp:
+		if d.cfg.InApp == nil {
+			return false, nil
+		}
+		n := &Notification{
+			UserID:   r.UserID,
+			TenantID: r.TenantID,
+			Event:    e.Name,
+			Title:    i18n.TLang(r.Lang, d.titleKey(e), data),
+			Body:     i18n.TLang(r.Lang, d.bodyKey(e), data),
+			Link:     d.link(e, data),
+			Data:     data,
+		}
+		if content := d.content(ctx, ch, e, r, data); content != nil {
+			n.Title, n.Body = content.Subject, content.Text
+		}
+		err := d.cfg.InApp.Create(ctx, n)
+		return err == nil, err
+
+	default:
+		return false, fmt.Errorf("unsupported channel %q", ch)
+	}
+}
+
+// content returns the managed copy for a channel, or nil to use the built-in templates; a
+// resolver failure is logged and falls back so a broken template never blocks delivery
+func (d *Dispatcher) content(ctx context.Context, ch Channel, e Event, r *Recipient, data map[string]interface{}) *Content {
+	if d.cfg.Content == nil {
+		return nil
+	}
+	content, ok, err := d.cfg.Content(ctx, e.Name, ch, r.Lang, data)
+	if err != nil {
+		d.log.Warn("failed to render managed content, using built-in template",
+			zap.String("event", e.Name),
+			zap.String("channel", string(ch)),
+			zap.Error(err),
+		)
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+	return content
+}
+
+func (d *Dispatcher) titleKey(e Event) string {
+	return firstNonEmpty(e.TitleKey, "notify."+e.Name+".title")
+}
+
+func (d *Dispatcher) bodyKey(e Event) string {
+	return firstNonEmpty(e.BodyKey, "notify."+e.Name+".body")
+}
+
+func (d *Dispatcher) link(e Event, data map[string]interface{}) string {
+	if e.Link == "" {
+		return ""
+	}
+	if v, ok := data[e.Link]; ok {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,204 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Preference stores the channels a user wants for an event; an empty Event is the user's default
+type Preference struct {
+	model.Base
+	UserID   uint64 `json:"user_id" gorm:"uniqueIndex:idx_notification_pref;not null"`
+	Event    string `json:"event" gorm:"uniqueIndex:idx_notification_pref;size:128"`
+	Channels string `json:"channels" gorm:"size:128"` // comma separated, empty disables all optional channels
+}
+
+// TableName overrides the table name
+func (Preference) TableName() string {
+	return "notification_preferences"
+}
+
+// ChannelList returns the parsed channels
+func (p *Preference) ChannelList() []Channel {
+	return parseChannels(p.Channels)
+}
+
+// QuietHours silences intrusive channels (SMS, WhatsApp, push) for a daily window in the user's timezone
+type QuietHours struct {
+	model.Base
+	UserID   uint64 `json:"user_id" gorm:"uniqueIndex;not null"`
+	Start    string `json:"start" gorm:"size:5"` // HH:MM
+	End      string `json:"end" gorm:"size:5"`   // HH:MM, may be before Start to span midnight
+	Timezone string `json:"timezone" gorm:"size:64;default:Asia/Riyadh"`
+	Enabled  bool   `json:"enabled"`
+}
+
+// TableName overrides the table name
+func (QuietHours) TableName() string {
+	return "notification_quiet_hours"
+}
+
+// Active reports whether t falls inside the quiet window
+func (q *QuietHours) Active(t time.Time) bool {
+	if q == nil || !q.Enabled {
+		return false
+	}
+	start, err1 := parseClock(q.Start)
+	end, err2 := parseClock(q.End)
+	if err1 != nil || err2 != nil || start == end {
+		return false
+	}
+
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil || q.Timezone == "" {
+		loc = riyadh
+	}
+	local := t.In(loc)
+	now := local.Hour()*60 + local.Minute()
+
+	if start < end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+var riyadh = time.FixedZone("Asia/Riyadh", 3*60*60)
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// PreferenceStore reads and writes user notification settings
+type PreferenceStore struct {
+	db *gorm.DB
+}
+
+// NewPreferenceStore creates a store; run db.Migrate with &Preference{}, &QuietHours{} first
+func NewPreferenceStore(db *gorm.DB) *PreferenceStore {
+	return &PreferenceStore{db: db}
+}
+
+// Channels returns the user's channels for event, falling back to the user default and then to
+// fallback when the user has no stored preference
+func (s *PreferenceStore) Channels(ctx context.Context, userID uint64, event string, fallback []Channel) ([]Channel, error) {
+	var prefs []Preference
+	err := s.db.WithContext(ctx).
+		Where("user_id = ? AND event IN ?", userID, []string{event, ""}).
+		Find(&prefs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notification preferences: %w", err)
+	}
+
+	var def *Preference
+	for i := range prefs {
+		if prefs[i].Event == event && event != "" {
+			return prefs[i].ChannelList(), nil
+		}
+		if prefs[i].Event == "" {
+			def = &prefs[i]
+		}
+	}
+	if def != nil {
+		// The default only narrows the event's channels, it never adds new ones
+		allowed := make(map[Channel]bool)
+		for _, ch := range def.ChannelList() {
+			allowed[ch] = true
+		}
+		var out []Channel
+		for _, ch := range fallback {
+			if allowed[ch] {
+				out = append(out, ch)
+			}
+		}
+		return out, nil
+	}
+	return fallback, nil
+}
+
+// SetChannels stores the user's channels for event ("" sets the default)
+func (s *PreferenceStore) SetChannels(ctx context.Context, userID uint64, event string, channels []Channel) error {
+	pref := Preference{UserID: userID, Event: event, Channels: joinChannels(channels)}
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "event"}},
+		DoUpdates: clause.AssignmentColumns([]string{"channels", "updated_at"}),
+	}).Create(&pref).Error
+	if err != nil {
+		return fmt.Errorf("failed to save notification preference: %w", err)
+	}
+	return nil
+}
+
+// List returns all stored preferences of a user
+func (s *PreferenceStore) List(ctx context.Context, userID uint64) ([]Preference, error) {
+	var prefs []Preference
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("event").Find(&prefs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load notification preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+// QuietHours returns the user's quiet hours, nil when none are stored
+func (s *PreferenceStore) QuietHours(ctx context.Context, userID uint64) (*QuietHours, error) {
+	var q QuietHours
+	err := s.db.WithContext(ctx).Where("user_id = ?", userID).First(&q).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load quiet hours: %w", err)
+	}
+	return &q, nil
+}
+
+// SetQuietHours stores the user's quiet hours
+func (s *PreferenceStore) SetQuietHours(ctx context.Context, q *QuietHours) error {
+	if _, err := parseClock(q.Start); err != nil {
+		return err
+	}
+	if _, err := parseClock(q.End); err != nil {
+		return err
+	}
+	if q.Timezone != "" {
+		if _, err := time.LoadLocation(q.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", q.Timezone, err)
+		}
+	}
+
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"start", "end", "timezone", "enabled", "updated_at"}),
+	}).Create(q).Error
+	if err != nil {
+		return fmt.Errorf("failed to save quiet hours: %w", err)
+	}
+	return nil
+}
+
+func parseChannels(s string) []Channel {
+	var out []Channel
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, Channel(part))
+		}
+	}
+	return out
+}
+
+func joinChannels(channels []Channel) string {
+	parts := make([]string, 0, len(channels))
+	for _, ch := range channels {
+		parts = append(parts, string(ch))
+	}
+	return strings.Join(parts, ",")
+}
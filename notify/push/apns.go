@@ -0,0 +1,213 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// APNsConfig holds Apple Push Notification service token-based auth settings
+type APNsConfig struct {
+	TeamID      string
+	KeyID       string
+	Key         []byte // .p8 key contents; KeyFile is read when empty
+	KeyFile     string
+	Topic       string // app bundle id
+	Sandbox     bool
+	Concurrency int // defaults to 16
+}
+
+// APNsProvider sends notifications directly to APNs over HTTP/2
+type APNsProvider struct {
+	cfg    *APNsConfig
+	key    interface{}
+	client *http.Client
+
+	mu       sync.Mutex
+	jwt      string
+	issuedAt time.Time
+}
+
+// NewAPNsProvider creates an APNs provider
+func NewAPNsProvider(cfg *APNsConfig) (*APNsProvider, error) {
+	data := cfg.Key
+	if len(data) == 0 {
+		var err error
+		if data, err = os.ReadFile(cfg.KeyFile); err != nil {
+			return nil, fmt.Errorf("failed to read apns key: %w", err)
+		}
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse apns key: %w", err)
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 16
+	}
+
+	// net/http negotiates HTTP/2 over TLS, which APNs requires
+	return &APNsProvider{cfg: cfg, key: key, client: &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+// Name implements Provider
+func (p *APNsProvider) Name() string { return "apns" }
+
+// SendTopic implements Provider; APNs has no topic fan-out
+func (p *APNsProvider) SendTopic(ctx context.Context, topic string, n *Notification) error {
+	return ErrTopicNotSupported
+}
+
+// Send implements Provider
+func (p *APNsProvider) Send(ctx context.Context, tokens []string, n *Notification) []TokenResult {
+	payload, err := json.Marshal(p.buildPayload(n))
+	results := make([]TokenResult, len(tokens))
+	if err != nil {
+		for i, token := range tokens {
+			results[i] = TokenResult{Token: token, Error: fmt.Errorf("failed to marshal apns payload: %w", err)}
+		}
+		return results
+	}
+
+	sem := make(chan struct{}, p.cfg.Concurrency)
+	var wg sync.WaitGroup
+	for i, token := range tokens {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, token string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = p.sendOne(ctx, token, n, payload)
+		}(i, token)
+	}
+	wg.Wait()
+	return results
+}
+
+func (p *APNsProvider) sendOne(ctx context.Context, token string, n *Notification, payload []byte) TokenResult {
+	result := TokenResult{Token: token}
+
+	host := "https://api.push.apple.com"
+	if p.cfg.Sandbox {
+		host = "https://api.sandbox.push.apple.com"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, host+"/3/device/"+token, bytes.NewReader(payload))
+	if err != nil {
+		result.Error = fmt.Errorf("failed to create apns request: %w", err)
+		return result
+	}
+
+	bearer, err := p.providerToken()
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	req.Header.Set("Authorization", "bearer "+bearer)
+	req.Header.Set("apns-topic", p.cfg.Topic)
+	if n.Silent {
+		req.Header.Set("apns-push-type", "background")
+		req.Header.Set("apns-priority", "5")
+	} else {
+		req.Header.Set("apns-push-type", "alert")
+		req.Header.Set("apns-priority", "10")
+	}
+	if n.CollapseKey != "" {
+		req.Header.Set("apns-collapse-id", n.CollapseKey)
+	}
+	if n.TTL > 0 {
+		req.Header.Set("apns-expiration", strconv.FormatInt(time.Now().Add(n.TTL).Unix(), 10))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		result.Error = fmt.Errorf("apns request failed: %w", err)
+		return result
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		result.MessageID = resp.Header.Get("apns-id")
+		return result
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var apnsErr struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.Unmarshal(body, &apnsErr)
+
+	switch apnsErr.Reason {
+	case "BadDeviceToken", "Unregistered", "DeviceTokenNotForTopic":
+		result.Invalid = true
+		result.Error = fmt.Errorf("%w: %s", ErrInvalidToken, apnsErr.Reason)
+	case "ExpiredProviderToken", "InvalidProviderToken":
+		p.mu.Lock()
+		p.jwt = ""
+		p.mu.Unlock()
+		fallthrough
+	default:
+		result.Error = fmt.Errorf("apns returned error [%d]: %s", resp.StatusCode, apnsErr.Reason)
+	}
+	return result
+}
+
+func (p *APNsProvider) buildPayload(n *Notification) map[string]interface{} {
+	aps := map[string]interface{}{}
+	if n.Silent {
+		aps["content-available"] = 1
+	} else {
+		aps["alert"] = map[string]string{"title": n.Title, "body": n.Body}
+		if n.Sound != "" {
+			aps["sound"] = n.Sound
+		}
+		if n.Image != "" {
+			aps["mutable-content"] = 1
+		}
+	}
+	if n.Badge != nil {
+		aps["badge"] = *n.Badge
+	}
+
+	payload := map[string]interface{}{"aps": aps}
+	for k, v := range n.Data {
+		if k != "aps" {
+			payload[k] = v
+		}
+	}
+	if n.Image != "" {
+		payload["image"] = n.Image
+	}
+	return payload
+}
+
+// providerToken returns the ES256 provider JWT, refreshed every 50 minutes since APNs rejects
+// tokens older than an hour
+func (p *APNsProvider) providerToken() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.jwt != "" && time.Since(p.issuedAt) < 50*time.Minute {
+		return p.jwt, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": p.cfg.TeamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = p.cfg.KeyID
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign apns token: %w", err)
+	}
+	p.jwt = signed
+	p.issuedAt = now
+	return signed, nil
+}
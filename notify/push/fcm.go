@@ -0,0 +1,304 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// FCMConfig holds Firebase Cloud Messaging settings
+type FCMConfig struct {
+	// CredentialsJSON is the service account key; CredentialsFile is read when it is empty
+	CredentialsJSON []byte
+	CredentialsFile string
+	ProjectID       string // defaults to the service account project
+	Concurrency     int    // parallel requests per batch, defaults to 16
+	MaxRetries      int    // retries on 429/5xx, defaults to 3
+	BaseURL         string // defaults to https://fcm.googleapis.com
+}
+
+type serviceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// FCMProvider sends notifications through the FCM HTTP v1 API
+type FCMProvider struct {
+	cfg     *FCMConfig
+	account serviceAccount
+	client  *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCMProvider creates an FCM provider from a service account
+func NewFCMProvider(cfg *FCMConfig) (*FCMProvider, error) {
+	data := cfg.CredentialsJSON
+	if len(data) == 0 {
+		var err error
+		if data, err = os.ReadFile(cfg.CredentialsFile); err != nil {
+			return nil, fmt.Errorf("failed to read fcm credentials: %w", err)
+		}
+	}
+
+	var account serviceAccount
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, fmt.Errorf("failed to parse fcm credentials: %w", err)
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	if cfg.ProjectID == "" {
+		cfg.ProjectID = account.ProjectID
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 16
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://fcm.googleapis.com"
+	}
+
+	return &FCMProvider{cfg: cfg, account: account, client: &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+// Name implements Provider
+func (p *FCMProvider) Name() string { return "fcm" }
+
+// Send implements Provider; tokens are sent concurrently and transient failures are retried
+func (p *FCMProvider) Send(ctx context.Context, tokens []string, n *Notification) []TokenResult {
+	results := make([]TokenResult, len(tokens))
+	sem := make(chan struct{}, p.cfg.Concurrency)
+	var wg sync.WaitGroup
+
+	for i, token := range tokens {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, token string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = p.sendOne(ctx, map[string]interface{}{"token": token}, n)
+			results[i].Token = token
+		}(i, token)
+	}
+	wg.Wait()
+	return results
+}
+
+// SendTopic implements Provider
+func (p *FCMProvider) SendTopic(ctx context.Context, topic string, n *Notification) error {
+	return p.sendOne(ctx, map[string]interface{}{"topic": topic}, n).Error
+}
+
+func (p *FCMProvider) sendOne(ctx context.Context, target map[string]interface{}, n *Notification) TokenResult {
+	message := p.buildMessage(target, n)
+	body, err := json.Marshal(map[string]interface{}{"message": message})
+	if err != nil {
+		return TokenResult{Error: fmt.Errorf("failed to marshal fcm message: %w", err)}
+	}
+
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		result, retryAfter, retry := p.post(ctx, body)
+		if !retry || attempt >= p.cfg.MaxRetries {
+			return result
+		}
+		if retryAfter > 0 {
+			backoff = retryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return TokenResult{Error: ctx.Err()}
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (p *FCMProvider) post(ctx context.Context, body []byte) (TokenResult, time.Duration, bool) {
+	token, err := p.token(ctx)
+	if err != nil {
+		return TokenResult{Error: err}, 0, false
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/projects/%s/messages:send", p.cfg.BaseURL, p.cfg.ProjectID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return TokenResult{Error: fmt.Errorf("failed to create fcm request: %w", err)}, 0, false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return TokenResult{Error: fmt.Errorf("fcm request failed: %w", err)}, 0, true
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode == http.StatusOK {
+		var out struct {
+			Name string `json:"name"`
+		}
+		_ = json.Unmarshal(respBody, &out)
+		return TokenResult{MessageID: out.Name}, 0, false
+	}
+
+	var fcmErr struct {
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(respBody, &fcmErr)
+	code := fcmErr.Error.Status
+	for _, d := range fcmErr.Error.Details {
+		if d.ErrorCode != "" {
+			code = d.ErrorCode
+		}
+	}
+
+	result := TokenResult{Error: fmt.Errorf("fcm returned error [%d] %s: %s", resp.StatusCode, code, fcmErr.Error.Message)}
+	switch {
+	case code == "UNREGISTERED" || code == "SENDER_ID_MISMATCH" || (code == "INVALID_ARGUMENT" && strings.Contains(fcmErr.Error.Message, "token")):
+		result.Invalid = true
+		result.Error = fmt.Errorf("%w: %s", ErrInvalidToken, code)
+		return result, 0, false
+	case resp.StatusCode == http.StatusUnauthorized:
+		p.mu.Lock()
+		p.accessToken = ""
+		p.mu.Unlock()
+		return result, 0, true
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		var retryAfter time.Duration
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			retryAfter = time.Duration(s) * time.Second
+		}
+		return result, retryAfter, true
+	default:
+		return result, 0, false
+	}
+}
+
+func (p *FCMProvider) buildMessage(target map[string]interface{}, n *Notification) map[string]interface{} {
+	message := target
+	if len(n.Data) > 0 {
+		message["data"] = n.Data
+	}
+
+	android := map[string]interface{}{}
+	apns := map[string]interface{}{}
+	aps := map[string]interface{}{}
+
+	if !n.Silent {
+		notification := map[string]interface{}{"title": n.Title, "body": n.Body}
+		if n.Image != "" {
+			notification["image"] = n.Image
+		}
+		message["notification"] = notification
+		if n.Sound != "" {
+			android["notification"] = map[string]interface{}{"sound": n.Sound}
+			aps["sound"] = n.Sound
+		}
+	} else {
+		android["priority"] = "high"
+		aps["content-available"] = 1
+	}
+	if n.Badge != nil {
+		aps["badge"] = *n.Badge
+	}
+	if n.CollapseKey != "" {
+		android["collapse_key"] = n.CollapseKey
+		apns["headers"] = map[string]string{"apns-collapse-id": n.CollapseKey}
+	}
+	if n.TTL > 0 {
+		android["ttl"] = fmt.Sprintf("%ds", int(n.TTL.Seconds()))
+	}
+
+	if len(android) > 0 {
+		message["android"] = android
+	}
+	if len(aps) > 0 {
+		apns["payload"] = map[string]interface{}{"aps": aps}
+	}
+	if len(apns) > 0 {
+		message["apns"] = apns
+	}
+	return message
+}
+
+// token returns a cached OAuth2 access token, exchanging a signed JWT when it expires
+func (p *FCMProvider) token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.accessToken != "" && time.Now().Before(p.expiresAt) {
+		return p.accessToken, nil
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(p.account.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse fcm private key: %w", err)
+	}
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   p.account.ClientEmail,
+		"scope": "https://www.googleapis.com/auth/firebase.messaging",
+		"aud":   p.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign fcm assertion: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", assertion)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create fcm token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fcm token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("fcm token endpoint returned error [%d]: %s", resp.StatusCode, string(body))
+	}
+	var out struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("failed to decode fcm token response: %w", err)
+	}
+
+	p.accessToken = out.AccessToken
+	p.expiresAt = now.Add(time.Duration(out.ExpiresIn)*time.Second - time.Minute)
+	return p.accessToken, nil
+}
@@ -0,0 +1,24 @@
+package push
+
+import (
+	"time"
+
+	"github.com/Masharah-Advisory/common/model"
+)
+
+// DeviceToken is a registered push token for a user's device
+type DeviceToken struct {
+	model.Base
+	UserID     uint64    `json:"user_id" gorm:"index;not null"`
+	TenantID   string    `json:"tenant_id,omitempty" gorm:"index;size:64"`
+	Token      string    `json:"token" gorm:"uniqueIndex;size:512;not null"`
+	Platform   Platform  `json:"platform" gorm:"size:16;not null"`
+	Lang       string    `json:"lang" gorm:"size:8;default:en"`
+	AppVersion string    `json:"app_version,omitempty" gorm:"size:32"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// TableName overrides the table name
+func (DeviceToken) TableName() string {
+	return "device_tokens"
+}
@@ -0,0 +1,79 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Masharah-Advisory/common/i18n"
+)
+
+// Platform is the device platform
+type Platform string
+
+const (
+	PlatformAndroid Platform = "android"
+	PlatformIOS     Platform = "ios"
+	PlatformWeb     Platform = "web"
+)
+
+// Notification is a push payload. TitleKey/BodyKey are translated per device language with
+// Params; Title/Body are used verbatim when no key is set.
+type Notification struct {
+	Title       string                 `json:"title,omitempty"`
+	Body        string                 `json:"body,omitempty"`
+	TitleKey    string                 `json:"title_key,omitempty"`
+	BodyKey     string                 `json:"body_key,omitempty"`
+	Params      map[string]interface{} `json:"params,omitempty"`
+	Data        map[string]string      `json:"data,omitempty"`
+	Image       string                 `json:"image,omitempty"`
+	Sound       string                 `json:"sound,omitempty"`
+	Badge       *int                   `json:"badge,omitempty"`
+	CollapseKey string                 `json:"collapse_key,omitempty"`
+	TTL         time.Duration          `json:"ttl,omitempty"`
+	Silent      bool                   `json:"silent,omitempty"` // data-only / content-available
+}
+
+// Target selects the recipients of a notification; fields are combined
+type Target struct {
+	UserIDs []uint64 `json:"user_ids,omitempty"`
+	Tokens  []string `json:"tokens,omitempty"`
+	Topic   string   `json:"topic,omitempty"`
+	Lang    string   `json:"lang,omitempty"` // language for topic sends, devices use their own
+}
+
+// Localize returns a copy of n with title and body translated to lang
+func (n *Notification) Localize(lang string) *Notification {
+	out := *n
+	if n.TitleKey != "" {
+		out.Title = i18n.TLang(lang, n.TitleKey, n.Params)
+	}
+	if n.BodyKey != "" {
+		out.Body = i18n.TLang(lang, n.BodyKey, n.Params)
+	}
+	return &out
+}
+
+// TokenResult is the outcome of a send to a single device token
+type TokenResult struct {
+	Token     string `json:"token"`
+	MessageID string `json:"message_id,omitempty"`
+	Error     error  `json:"-"`
+	// Invalid means the token is unregistered or malformed and should be pruned
+	Invalid bool `json:"invalid,omitempty"`
+}
+
+// Provider delivers notifications for one or more platforms
+type Provider interface {
+	Name() string
+	// Send delivers n to each token; the returned slice has one result per token
+	Send(ctx context.Context, tokens []string, n *Notification) []TokenResult
+	// SendTopic delivers n to every device subscribed to topic
+	SendTopic(ctx context.Context, topic string, n *Notification) error
+}
+
+var (
+	ErrNoProvider        = errors.New("no push provider configured for platform")
+	ErrTopicNotSupported = errors.New("push provider does not support topics")
+	ErrInvalidToken      = errors.New("invalid or unregistered device token")
+)
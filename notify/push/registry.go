@@ -0,0 +1,92 @@
+package push
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Registry stores device tokens
+type Registry struct {
+	db *gorm.DB
+}
+
+// NewRegistry creates a registry backed by db; run db.Migrate with &DeviceToken{} first
+func NewRegistry(db *gorm.DB) *Registry {
+	return &Registry{db: db}
+}
+
+// Register upserts a device token, moving it to the given user if it was registered to another
+func (r *Registry) Register(ctx context.Context, device *DeviceToken) error {
+	if device.Token == "" {
+		return ErrInvalidToken
+	}
+	if device.Lang == "" {
+		device.Lang = "en"
+	}
+	device.LastSeenAt = time.Now()
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "tenant_id", "platform", "lang", "app_version", "last_seen_at", "updated_at", "deleted_at"}),
+	}).Create(device).Error
+	if err != nil {
+		return fmt.Errorf("failed to register device token: %w", err)
+	}
+	return nil
+}
+
+// Unregister removes a token, e.g. on logout
+func (r *Registry) Unregister(ctx context.Context, token string) error {
+	if err := r.db.WithContext(ctx).Where("token = ?", token).Delete(&DeviceToken{}).Error; err != nil {
+		return fmt.Errorf("failed to unregister device token: %w", err)
+	}
+	return nil
+}
+
+// ForUsers returns the active devices of the given users
+func (r *Registry) ForUsers(ctx context.Context, userIDs ...uint64) ([]DeviceToken, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	var devices []DeviceToken
+	if err := r.db.WithContext(ctx).Where("user_id IN ? AND deleted_at IS NULL", userIDs).Find(&devices).Error; err != nil {
+		return nil, fmt.Errorf("failed to load device tokens: %w", err)
+	}
+	return devices, nil
+}
+
+// ForTokens returns the registered devices for raw tokens
+func (r *Registry) ForTokens(ctx context.Context, tokens ...string) ([]DeviceToken, error) {
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	var devices []DeviceToken
+	if err := r.db.WithContext(ctx).Where("token IN ? AND deleted_at IS NULL", tokens).Find(&devices).Error; err != nil {
+		return nil, fmt.Errorf("failed to load device tokens: %w", err)
+	}
+	return devices, nil
+}
+
+// Prune deletes invalid tokens reported by a provider
+func (r *Registry) Prune(ctx context.Context, tokens ...string) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	if err := r.db.WithContext(ctx).Unscoped().Where("token IN ?", tokens).Delete(&DeviceToken{}).Error; err != nil {
+		return fmt.Errorf("failed to prune device tokens: %w", err)
+	}
+	return nil
+}
+
+// PruneStale deletes tokens not seen for longer than maxAge
+func (r *Registry) PruneStale(ctx context.Context, maxAge time.Duration) (int64, error) {
+	res := r.db.WithContext(ctx).Unscoped().Where("last_seen_at < ?", time.Now().Add(-maxAge)).Delete(&DeviceToken{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to prune stale device tokens: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
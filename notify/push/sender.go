@@ -0,0 +1,211 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/worker"
+	"go.uber.org/zap"
+)
+
+// SendJob is the worker job name used by Queue
+const SendJob = "push.send"
+
+// SenderConfig holds the sender settings
+type SenderConfig struct {
+	Registry  *Registry
+	Providers map[Platform]Provider
+	// TopicProvider handles Target.Topic, defaults to the android provider
+	TopicProvider Provider
+	BatchSize     int // tokens per provider call, defaults to 500
+	Worker        *worker.Manager
+	Retry         worker.JobOptions // defaults to 3 retries, 30s backoff
+}
+
+// Report summarizes a send
+type Report struct {
+	Sent   int `json:"sent"`
+	Failed int `json:"failed"`
+	Pruned int `json:"pruned"`
+}
+
+type queuedPush struct {
+	Target       Target        `json:"target"`
+	Notification *Notification `json:"notification"`
+}
+
+// Sender resolves targets to devices, localizes payloads and prunes invalid tokens
+type Sender struct {
+	cfg *SenderConfig
+	log *zap.Logger
+}
+
+// NewSender creates a sender and registers the send job on cfg.Worker when set
+func NewSender(cfg *SenderConfig) (*Sender, error) {
+	if len(cfg.Providers) == 0 && cfg.TopicProvider == nil {
+		return nil, fmt.Errorf("at least one push provider is required")
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	if cfg.TopicProvider == nil {
+		cfg.TopicProvider = cfg.Providers[PlatformAndroid]
+	}
+
+	s := &Sender{cfg: cfg, log: logger.Module("push")}
+
+	if cfg.Worker != nil {
+		opts := cfg.Retry
+		if opts.MaxRetries == 0 && opts.RetryBackoff == 0 {
+			opts.MaxRetries = 3
+			opts.RetryBackoff = 30 * time.Second
+		}
+		cfg.Worker.Register(SendJob, s.handleTask, opts)
+	}
+	return s, nil
+}
+
+// Send delivers n to target immediately. It only returns an error when nothing could be
+// delivered because of transient failures, so queued retries do not duplicate notifications.
+func (s *Sender) Send(ctx context.Context, target Target, n *Notification) (*Report, error) {
+	report := &Report{}
+	var errs []error
+
+	if target.Topic != "" {
+		if s.cfg.TopicProvider == nil {
+			return nil, fmt.Errorf("%w: topic", ErrNoProvider)
+		}
+		lang := target.Lang
+		if lang == "" {
+			lang = "en"
+		}
+		if err := s.cfg.TopicProvider.SendTopic(ctx, target.Topic, n.Localize(lang)); err != nil {
+			report.Failed++
+			errs = append(errs, err)
+		} else {
+			report.Sent++
+		}
+	}
+
+	devices, err := s.resolve(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group by provider and language so each batch shares one localized payload
+	type group struct {
+		platform Platform
+		lang     string
+	}
+	groups := make(map[group][]string)
+	for _, d := range devices {
+		g := group{platform: d.Platform, lang: d.Lang}
+		groups[g] = append(groups[g], d.Token)
+	}
+
+	var invalid []string
+	for g, tokens := range groups {
+		provider, ok := s.cfg.Providers[g.platform]
+		if !ok {
+			report.Failed += len(tokens)
+			errs = append(errs, fmt.Errorf("%w: %s", ErrNoProvider, g.platform))
+			continue
+		}
+		payload := n.Localize(g.lang)
+
+		for start := 0; start < len(tokens); start += s.cfg.BatchSize {
+			end := min(start+s.cfg.BatchSize, len(tokens))
+			for _, r := range provider.Send(ctx, tokens[start:end], payload) {
+				switch {
+				case r.Error == nil:
+					report.Sent++
+				case r.Invalid:
+					invalid = append(invalid, r.Token)
+				default:
+					report.Failed++
+					errs = append(errs, r.Error)
+				}
+			}
+		}
+	}
+
+	if len(invalid) > 0 && s.cfg.Registry != nil {
+		if err := s.cfg.Registry.Prune(ctx, invalid...); err != nil {
+			s.log.Warn("failed to prune invalid push tokens", zap.Int("count", len(invalid)), zap.Error(err))
+		} else {
+			report.Pruned = len(invalid)
+		}
+	}
+
+	s.log.Info("push sent",
+		zap.Int("sent", report.Sent),
+		zap.Int("failed", report.Failed),
+		zap.Int("pruned", report.Pruned),
+	)
+
+	if report.Sent == 0 && report.Failed > 0 {
+		return report, fmt.Errorf("push delivery failed: %w", errors.Join(errs...))
+	}
+	return report, nil
+}
+
+// Queue enqueues a notification for delivery with retries; without a worker it sends inline
+func (s *Sender) Queue(ctx context.Context, target Target, n *Notification) (string, error) {
+	if s.cfg.Worker == nil {
+		_, err := s.Send(ctx, target, n)
+		return "", err
+	}
+	return s.cfg.Worker.Enqueue(ctx, SendJob, queuedPush{Target: target, Notification: n})
+}
+
+// SendToUsers is a shorthand for Send with user targeting
+func (s *Sender) SendToUsers(ctx context.Context, n *Notification, userIDs ...uint64) (*Report, error) {
+	return s.Send(ctx, Target{UserIDs: userIDs}, n)
+}
+
+// SendToTopic is a shorthand for Send with topic targeting
+func (s *Sender) SendToTopic(ctx context.Context, topic, lang string, n *Notification) (*Report, error) {
+	return s.Send(ctx, Target{Topic: topic, Lang: lang}, n)
+}
+
+func (s *Sender) handleTask(ctx context.Context, task *worker.Task) error {
+	var p queuedPush
+	if err := task.Decode(&p); err != nil {
+		return err
+	}
+	_, err := s.Send(ctx, p.Target, p.Notification)
+	return err
+}
+
+// resolve loads devices for the user and token targets; unregistered raw tokens are skipped
+func (s *Sender) resolve(ctx context.Context, target Target) ([]DeviceToken, error) {
+	if len(target.UserIDs) == 0 && len(target.Tokens) == 0 {
+		return nil, nil
+	}
+	if s.cfg.Registry == nil {
+		return nil, fmt.Errorf("push registry is required for user or token targets")
+	}
+
+	devices, err := s.cfg.Registry.ForUsers(ctx, target.UserIDs...)
+	if err != nil {
+		return nil, err
+	}
+	byToken, err := s.cfg.Registry.ForTokens(ctx, target.Tokens...)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(devices))
+	out := make([]DeviceToken, 0, len(devices)+len(byToken))
+	for _, d := range append(devices, byToken...) {
+		if seen[d.Token] {
+			continue
+		}
+		seen[d.Token] = true
+		out = append(out, d)
+	}
+	return out, nil
+}
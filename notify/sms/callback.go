@@ -0,0 +1,221 @@
+package sms
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// TwilioStatusHandler handles Twilio status callbacks. publicURL is the exact callback URL
+// configured on Twilio and is used to verify X-Twilio-Signature.
+func TwilioStatusHandler(authToken, publicURL string, fn StatusHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := c.Request.ParseForm(); err != nil {
+			response.BadRequest(c, "Invalid callback payload")
+			return
+		}
+		if !verifyTwilioSignature(authToken, publicURL, c.Request.PostForm, c.GetHeader("X-Twilio-Signature")) {
+			response.Unauthorized(c, "Invalid signature")
+			return
+		}
+
+		update := StatusUpdate{
+			Provider:  "twilio",
+			MessageID: c.Request.PostForm.Get("MessageSid"),
+			Status:    parseTwilioStatus(c.Request.PostForm.Get("MessageStatus")),
+			To:        c.Request.PostForm.Get("To"),
+			ErrorCode: c.Request.PostForm.Get("ErrorCode"),
+			Error:     c.Request.PostForm.Get("ErrorMessage"),
+			Timestamp: time.Now(),
+		}
+		dispatch(c, fn, update)
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// UnifonicStatusHandler handles Unifonic delivery reports. When secret is set the callback
+// URL must carry it as the "token" query parameter.
+func UnifonicStatusHandler(secret string, fn StatusHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if secret != "" && subtle.ConstantTimeCompare([]byte(c.Query("token")), []byte(secret)) != 1 {
+			response.Unauthorized(c, "Invalid token")
+			return
+		}
+
+		var payload struct {
+			MessageID interface{} `json:"messageId" form:"messageId"`
+			Status    string      `json:"status" form:"status"`
+			Recipient string      `json:"recipient" form:"recipient"`
+			ErrorCode string      `json:"errorCode" form:"errorCode"`
+			Error     string      `json:"error" form:"error"`
+		}
+		if err := c.ShouldBind(&payload); err != nil {
+			response.BadRequest(c, "Invalid callback payload", response.ProcessBindingError(c, err))
+			return
+		}
+
+		update := StatusUpdate{
+			Provider:  "unifonic",
+			MessageID: toString(payload.MessageID),
+			Status:    parseUnifonicStatus(payload.Status),
+			To:        payload.Recipient,
+			ErrorCode: payload.ErrorCode,
+			Error:     payload.Error,
+			Timestamp: time.Now(),
+		}
+		dispatch(c, fn, update)
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// WhatsAppWebhook handles the WhatsApp webhook: GET answers the subscription challenge with
+// verifyToken, POST verifies X-Hub-Signature-256 with appSecret and forwards status updates.
+func WhatsAppWebhook(verifyToken, appSecret string, fn StatusHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet {
+			if c.Query("hub.mode") == "subscribe" && subtle.ConstantTimeCompare([]byte(c.Query("hub.verify_token")), []byte(verifyToken)) == 1 {
+				c.String(http.StatusOK, c.Query("hub.challenge"))
+				return
+			}
+			response.Forbidden(c, "Invalid verify token")
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+		if err != nil {
+			response.BadRequest(c, "Invalid callback payload")
+			return
+		}
+		if !verifyHubSignature(appSecret, body, c.GetHeader("X-Hub-Signature-256")) {
+			response.Unauthorized(c, "Invalid signature")
+			return
+		}
+
+		var payload struct {
+			Entry []struct {
+				Changes []struct {
+					Value struct {
+						Statuses []struct {
+							ID          string `json:"id"`
+							Status      string `json:"status"`
+							Timestamp   string `json:"timestamp"`
+							RecipientID string `json:"recipient_id"`
+							Errors      []struct {
+								Code  int    `json:"code"`
+								Title string `json:"title"`
+							} `json:"errors"`
+						} `json:"statuses"`
+					} `json:"value"`
+				} `json:"changes"`
+			} `json:"entry"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			response.BadRequest(c, "Invalid callback payload")
+			return
+		}
+
+		for _, entry := range payload.Entry {
+			for _, change := range entry.Changes {
+				for _, s := range change.Value.Statuses {
+					update := StatusUpdate{
+						Provider:  "whatsapp",
+						MessageID: s.ID,
+						Status:    parseWhatsAppStatus(s.Status),
+						To:        s.RecipientID,
+						Timestamp: time.Now(),
+					}
+					if ts, err := strconv.ParseInt(s.Timestamp, 10, 64); err == nil {
+						update.Timestamp = time.Unix(ts, 0)
+					}
+					if len(s.Errors) > 0 {
+						update.ErrorCode = strconv.Itoa(s.Errors[0].Code)
+						update.Error = s.Errors[0].Title
+					}
+					dispatch(c, fn, update)
+				}
+			}
+		}
+		c.Status(http.StatusOK)
+	}
+}
+
+// dispatch forwards an update; handler errors are logged, not returned, so the provider does
+// not retry a callback we already accepted
+func dispatch(c *gin.Context, fn StatusHandler, update StatusUpdate) {
+	if fn == nil || update.MessageID == "" {
+		return
+	}
+	if err := fn(c.Request.Context(), update); err != nil {
+		logger.Module("sms").Error("delivery status handler failed",
+			zap.String("provider", update.Provider),
+			zap.String("message_id", update.MessageID),
+			zap.String("status", string(update.Status)),
+			zap.Error(err),
+		)
+	}
+}
+
+// verifyTwilioSignature checks base64(HMAC-SHA1(url + sorted key/value pairs))
+func verifyTwilioSignature(authToken, url string, form map[string][]string, signature string) bool {
+	if authToken == "" || signature == "" {
+		return false
+	}
+	keys := make([]string, 0, len(form))
+	for k := range form {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(url)
+	for _, k := range keys {
+		for _, v := range form[k] {
+			b.WriteString(k + v)
+		}
+	}
+
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(b.String()))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// verifyHubSignature checks "sha256=" + hex(HMAC-SHA256(body))
+func verifyHubSignature(secret string, body []byte, signature string) bool {
+	if secret == "" || !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+func toString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		b, _ := json.Marshal(t)
+		return string(b)
+	}
+}
@@ -0,0 +1,62 @@
+package sms
+
+import (
+	"context"
+	"strconv"
+	"sync"
+)
+
+// CaptureProvider records messages instead of sending them, for tests and local development
+type CaptureProvider struct {
+	mu       sync.Mutex
+	channel  Channel
+	messages []Message
+	// Err, when set, is returned from Send to simulate provider failures
+	Err error
+}
+
+// NewCaptureProvider creates a capturing provider for channel
+func NewCaptureProvider(channel Channel) *CaptureProvider {
+	return &CaptureProvider{channel: channel}
+}
+
+// Name implements Provider
+func (p *CaptureProvider) Name() string { return "capture" }
+
+// Channel implements Provider
+func (p *CaptureProvider) Channel() Channel { return p.channel }
+
+// Send implements Provider
+func (p *CaptureProvider) Send(ctx context.Context, msg *Message) (*Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	p.messages = append(p.messages, *msg)
+	return &Result{Provider: p.Name(), MessageID: strconv.Itoa(len(p.messages)), Status: StatusSent}, nil
+}
+
+// Messages returns a copy of all captured messages
+func (p *CaptureProvider) Messages() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Message(nil), p.messages...)
+}
+
+// Last returns the most recently captured message
+func (p *CaptureProvider) Last() (Message, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.messages) == 0 {
+		return Message{}, false
+	}
+	return p.messages[len(p.messages)-1], true
+}
+
+// Reset clears captured messages
+func (p *CaptureProvider) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = nil
+}
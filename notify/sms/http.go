@@ -0,0 +1,40 @@
+package sms
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+// doJSON executes req and decodes a JSON response into out, turning non-2xx replies into errors
+func doJSON(client *http.Client, provider string, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", provider, err)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %s: %s", ErrRateLimited, provider, strings.TrimSpace(string(body)))
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned error [%d]: %s", provider, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("failed to decode %s response: %w", provider, err)
+		}
+	}
+	return nil
+}
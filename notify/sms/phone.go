@@ -0,0 +1,64 @@
+package sms
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Normalize converts a phone number to E.164. Numbers without a country code are treated as
+// Saudi numbers: 05XXXXXXXX, 5XXXXXXXX, 009665XXXXXXXX and 9665XXXXXXXX all become +9665XXXXXXXX.
+// Arabic-Indic digits are accepted.
+func Normalize(number string) (string, error) {
+	var b strings.Builder
+	plus := false
+	for i, r := range strings.TrimSpace(number) {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r >= '٠' && r <= '٩':
+			b.WriteRune('0' + (r - '٠'))
+		case r >= '۰' && r <= '۹':
+			b.WriteRune('0' + (r - '۰'))
+		case r == '+' && i == 0:
+			plus = true
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+		default:
+			return "", fmt.Errorf("%w: %q", ErrInvalidPhone, number)
+		}
+	}
+	digits := b.String()
+
+	switch {
+	case plus:
+	case strings.HasPrefix(digits, "00"):
+		digits = digits[2:]
+	case strings.HasPrefix(digits, "966"):
+	case strings.HasPrefix(digits, "05") && len(digits) == 10:
+		digits = "966" + digits[1:]
+	case strings.HasPrefix(digits, "5") && len(digits) == 9:
+		digits = "966" + digits
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidPhone, number)
+	}
+
+	if strings.HasPrefix(digits, "966") {
+		// Drop a trunk zero written after the country code (+966 05...)
+		if strings.HasPrefix(digits, "9660") {
+			digits = "966" + digits[4:]
+		}
+		if len(digits) != 12 {
+			return "", fmt.Errorf("%w: %q", ErrInvalidPhone, number)
+		}
+	}
+
+	if len(digits) < 8 || len(digits) > 15 {
+		return "", fmt.Errorf("%w: %q", ErrInvalidPhone, number)
+	}
+	return "+" + digits, nil
+}
+
+// IsKSAMobile reports whether number normalizes to a Saudi mobile number (+9665XXXXXXXX)
+func IsKSAMobile(number string) bool {
+	n, err := Normalize(number)
+	return err == nil && strings.HasPrefix(n, "+9665") && len(n) == 13
+}
@@ -0,0 +1,51 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimitConfig limits the send rate of a provider
+type RateLimitConfig struct {
+	PerSecond float64
+	Burst     int
+	// Wait blocks until a token is available (bounded by the context) instead of failing fast
+	Wait bool
+}
+
+// rateLimited wraps a provider with a token bucket
+type rateLimited struct {
+	Provider
+	limiter *rate.Limiter
+	wait    bool
+}
+
+// RateLimited wraps p so sends never exceed cfg; a zero PerSecond returns p unchanged
+func RateLimited(p Provider, cfg RateLimitConfig) Provider {
+	if cfg.PerSecond <= 0 {
+		return p
+	}
+	burst := cfg.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+	return &rateLimited{
+		Provider: p,
+		limiter:  rate.NewLimiter(rate.Limit(cfg.PerSecond), burst),
+		wait:     cfg.Wait,
+	}
+}
+
+// Send implements Provider
+func (r *rateLimited) Send(ctx context.Context, msg *Message) (*Result, error) {
+	if r.wait {
+		if err := r.limiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrRateLimited, r.Name(), err)
+		}
+	} else if !r.limiter.Allow() {
+		return nil, fmt.Errorf("%w: %s", ErrRateLimited, r.Name())
+	}
+	return r.Provider.Send(ctx, msg)
+}
@@ -0,0 +1,114 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"go.uber.org/zap"
+)
+
+// SenderConfig holds the sender settings
+type SenderConfig struct {
+	// Providers are tried in order per channel; later providers act as fallbacks
+	Providers []Provider
+	Templates *Templates
+}
+
+// Sender routes messages to the providers of a channel
+type Sender struct {
+	providers map[Channel][]Provider
+	templates *Templates
+	log       *zap.Logger
+}
+
+// NewSender creates a sender
+func NewSender(cfg *SenderConfig) (*Sender, error) {
+	if len(cfg.Providers) == 0 {
+		return nil, fmt.Errorf("at least one sms provider is required")
+	}
+
+	templates := cfg.Templates
+	if templates == nil {
+		templates = NewTemplates()
+	}
+
+	s := &Sender{
+		providers: make(map[Channel][]Provider),
+		templates: templates,
+		log:       logger.Module("sms"),
+	}
+	for _, p := range cfg.Providers {
+		s.providers[p.Channel()] = append(s.providers[p.Channel()], p)
+	}
+	return s, nil
+}
+
+// Templates returns the template registry
+func (s *Sender) Templates() *Templates {
+	return s.templates
+}
+
+// Send normalizes the recipient, renders SMS templates and delivers msg over channel,
+// falling back to the next provider when one fails
+func (s *Sender) Send(ctx context.Context, channel Channel, msg *Message) (*Result, error) {
+	providers := s.providers[channel]
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("no provider configured for channel %s", channel)
+	}
+
+	to, err := Normalize(msg.To)
+	if err != nil {
+		return nil, err
+	}
+	out := *msg
+	out.To = to
+
+	// SMS has no server-side templates, so render the body locally
+	if channel == ChannelSMS && out.Template != "" && out.Body == "" {
+		body, err := s.templates.Render(out.Template, out.Lang, out.Params)
+		if err != nil {
+			return nil, err
+		}
+		out.Body = body
+	}
+	if err := out.Validate(); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, p := range providers {
+		result, err := p.Send(ctx, &out)
+		if err == nil {
+			s.log.Info("message sent",
+				zap.String("channel", string(channel)),
+				zap.String("provider", p.Name()),
+				zap.String("message_id", result.MessageID),
+				zap.String("tenant_id", out.TenantID),
+			)
+			return result, nil
+		}
+
+		s.log.Warn("message send failed",
+			zap.String("channel", string(channel)),
+			zap.String("provider", p.Name()),
+			zap.Error(err),
+		)
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, fmt.Errorf("failed to send %s message: %w", channel, errors.Join(errs...))
+}
+
+// SendSMS sends msg over SMS
+func (s *Sender) SendSMS(ctx context.Context, msg *Message) (*Result, error) {
+	return s.Send(ctx, ChannelSMS, msg)
+}
+
+// SendWhatsApp sends msg over WhatsApp
+func (s *Sender) SendWhatsApp(ctx context.Context, msg *Message) (*Result, error) {
+	return s.Send(ctx, ChannelWhatsApp, msg)
+}
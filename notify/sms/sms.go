@@ -0,0 +1,89 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Channel is the delivery channel of a provider
+type Channel string
+
+const (
+	ChannelSMS      Channel = "sms"
+	ChannelWhatsApp Channel = "whatsapp"
+)
+
+// Status is the delivery status of a message
+type Status string
+
+const (
+	StatusQueued      Status = "queued"
+	StatusSent        Status = "sent"
+	StatusDelivered   Status = "delivered"
+	StatusRead        Status = "read"
+	StatusFailed      Status = "failed"
+	StatusUndelivered Status = "undelivered"
+	StatusUnknown     Status = "unknown"
+)
+
+// Message is a text or template message to a single phone number
+type Message struct {
+	To       string `json:"to"`
+	Body     string `json:"body,omitempty"`
+	SenderID string `json:"sender_id,omitempty"` // overrides the provider default sender
+	// Template and Params send a registered template instead of Body (required for
+	// business-initiated WhatsApp conversations)
+	Template string            `json:"template,omitempty"`
+	Lang     string            `json:"lang,omitempty"`
+	Params   []string          `json:"params,omitempty"`
+	TenantID string            `json:"tenant_id,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
+}
+
+// Result is the provider acknowledgement of a sent message
+type Result struct {
+	Provider  string `json:"provider"`
+	MessageID string `json:"message_id"`
+	Status    Status `json:"status"`
+}
+
+// StatusUpdate is a delivery-status callback from a provider
+type StatusUpdate struct {
+	Provider  string    `json:"provider"`
+	MessageID string    `json:"message_id"`
+	Status    Status    `json:"status"`
+	To        string    `json:"to,omitempty"`
+	ErrorCode string    `json:"error_code,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// StatusHandler receives delivery-status updates
+type StatusHandler func(ctx context.Context, update StatusUpdate) error
+
+// Provider sends messages over a single channel
+type Provider interface {
+	Name() string
+	Channel() Channel
+	Send(ctx context.Context, msg *Message) (*Result, error)
+}
+
+var (
+	ErrEmptyMessage    = errors.New("message has no body or template")
+	ErrInvalidPhone    = errors.New("invalid phone number")
+	ErrRateLimited     = errors.New("provider rate limit exceeded")
+	ErrUnknownTemplate = errors.New("unknown message template")
+)
+
+// Validate checks the message has a normalizable recipient and content
+func (m *Message) Validate() error {
+	if _, err := Normalize(m.To); err != nil {
+		return err
+	}
+	if strings.TrimSpace(m.Body) == "" && m.Template == "" {
+		return ErrEmptyMessage
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package sms
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Template is a registered message template. Bodies use WhatsApp-style positional
+// placeholders ({{1}}, {{2}}, ...) so the same params work for SMS and WhatsApp.
+type Template struct {
+	Name string
+	// Bodies maps a language (en, ar) to the SMS body
+	Bodies map[string]string
+	// WhatsAppName is the approved WhatsApp template name, defaults to Name
+	WhatsAppName string
+	// WhatsAppLangs maps a language to the WhatsApp language code, defaults to the language itself
+	WhatsAppLangs map[string]string
+}
+
+// Templates is a concurrency-safe template registry
+type Templates struct {
+	mu        sync.RWMutex
+	templates map[string]Template
+}
+
+// NewTemplates creates a registry with the given templates
+func NewTemplates(templates ...Template) *Templates {
+	t := &Templates{templates: make(map[string]Template)}
+	for _, tmpl := range templates {
+		t.Register(tmpl)
+	}
+	return t
+}
+
+// Register adds or replaces a template
+func (t *Templates) Register(tmpl Template) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.templates[tmpl.Name] = tmpl
+}
+
+// Remove deletes a template
+func (t *Templates) Remove(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.templates, name)
+}
+
+// Get returns a registered template
+func (t *Templates) Get(name string) (Template, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	tmpl, ok := t.templates[name]
+	return tmpl, ok
+}
+
+// Names returns the registered template names
+func (t *Templates) Names() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	names := make([]string, 0, len(t.templates))
+	for name := range t.templates {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Render returns the SMS body of a template in lang, falling back to English
+func (t *Templates) Render(name, lang string, params []string) (string, error) {
+	tmpl, ok := t.Get(name)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownTemplate, name)
+	}
+	body, ok := tmpl.Bodies[normalizeLang(lang)]
+	if !ok {
+		if body, ok = tmpl.Bodies["en"]; !ok {
+			return "", fmt.Errorf("%w: %s has no %s body", ErrUnknownTemplate, name, lang)
+		}
+	}
+	return fillParams(body, params), nil
+}
+
+// WhatsApp returns the WhatsApp template name and language code for a template
+func (tmpl Template) WhatsApp(lang string) (string, string) {
+	name := tmpl.WhatsAppName
+	if name == "" {
+		name = tmpl.Name
+	}
+	lang = normalizeLang(lang)
+	if code, ok := tmpl.WhatsAppLangs[lang]; ok {
+		return name, code
+	}
+	return name, lang
+}
+
+// fillParams replaces {{n}} placeholders with the matching 1-based param
+func fillParams(body string, params []string) string {
+	if len(params) == 0 {
+		return body
+	}
+	pairs := make([]string, 0, len(params)*2)
+	for i, p := range params {
+		pairs = append(pairs, "{{"+strconv.Itoa(i+1)+"}}", p)
+	}
+	return strings.NewReplacer(pairs...).Replace(body)
+}
+
+func normalizeLang(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i != -1 {
+		lang = lang[:i]
+	}
+	if lang == "" {
+		return "en"
+	}
+	return lang
+}
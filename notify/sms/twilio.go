@@ -0,0 +1,101 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// TwilioConfig holds Twilio credentials
+type TwilioConfig struct {
+	AccountSID          string
+	AuthToken           string
+	From                string // sender number or alphanumeric sender ID
+	MessagingServiceSID string // used instead of From when set
+	BaseURL             string // defaults to https://api.twilio.com
+	StatusCallbackURL   string // see TwilioStatusHandler
+}
+
+// TwilioProvider sends SMS through Twilio
+type TwilioProvider struct {
+	cfg    *TwilioConfig
+	client *http.Client
+}
+
+// NewTwilioProvider creates a Twilio provider
+func NewTwilioProvider(cfg *TwilioConfig) *TwilioProvider {
+	return &TwilioProvider{cfg: cfg, client: newHTTPClient()}
+}
+
+// Name implements Provider
+func (p *TwilioProvider) Name() string { return "twilio" }
+
+// Channel implements Provider
+func (p *TwilioProvider) Channel() Channel { return ChannelSMS }
+
+// Send implements Provider
+func (p *TwilioProvider) Send(ctx context.Context, msg *Message) (*Result, error) {
+	to, err := Normalize(msg.To)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("To", to)
+	form.Set("Body", msg.Body)
+	switch {
+	case msg.SenderID != "":
+		form.Set("From", msg.SenderID)
+	case p.cfg.MessagingServiceSID != "":
+		form.Set("MessagingServiceSid", p.cfg.MessagingServiceSID)
+	default:
+		form.Set("From", p.cfg.From)
+	}
+	if p.cfg.StatusCallbackURL != "" {
+		form.Set("StatusCallback", p.cfg.StatusCallbackURL)
+	}
+
+	baseURL := p.cfg.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.twilio.com"
+	}
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", baseURL, url.PathEscape(p.cfg.AccountSID))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create twilio request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(p.cfg.AccountSID, p.cfg.AuthToken)
+
+	var out struct {
+		SID    string `json:"sid"`
+		Status string `json:"status"`
+	}
+	if err := doJSON(p.client, p.Name(), req, &out); err != nil {
+		return nil, err
+	}
+
+	return &Result{Provider: p.Name(), MessageID: out.SID, Status: parseTwilioStatus(out.Status)}, nil
+}
+
+func parseTwilioStatus(s string) Status {
+	switch s {
+	case "accepted", "queued", "sending", "scheduled":
+		return StatusQueued
+	case "sent":
+		return StatusSent
+	case "delivered":
+		return StatusDelivered
+	case "read":
+		return StatusRead
+	case "failed", "canceled":
+		return StatusFailed
+	case "undelivered":
+		return StatusUndelivered
+	default:
+		return StatusUnknown
+	}
+}
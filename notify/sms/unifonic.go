@@ -0,0 +1,108 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// UnifonicConfig holds Unifonic REST credentials
+type UnifonicConfig struct {
+	AppSID      string
+	SenderID    string
+	BaseURL     string // defaults to https://el.cloud.unifonic.com
+	CallbackURL string // delivery-report URL, see UnifonicStatusHandler
+}
+
+// UnifonicProvider sends SMS through Unifonic
+type UnifonicProvider struct {
+	cfg    *UnifonicConfig
+	client *http.Client
+}
+
+// NewUnifonicProvider creates a Unifonic provider
+func NewUnifonicProvider(cfg *UnifonicConfig) *UnifonicProvider {
+	return &UnifonicProvider{cfg: cfg, client: newHTTPClient()}
+}
+
+// Name implements Provider
+func (p *UnifonicProvider) Name() string { return "unifonic" }
+
+// Channel implements Provider
+func (p *UnifonicProvider) Channel() Channel { return ChannelSMS }
+
+type unifonicResponse struct {
+	Success   bool   `json:"success"`
+	Message   string `json:"message"`
+	ErrorCode string `json:"errorCode"`
+	Data      struct {
+		MessageID interface{} `json:"MessageID"`
+		Status    string      `json:"Status"`
+	} `json:"data"`
+}
+
+// Send implements Provider
+func (p *UnifonicProvider) Send(ctx context.Context, msg *Message) (*Result, error) {
+	to, err := Normalize(msg.To)
+	if err != nil {
+		return nil, err
+	}
+	sender := msg.SenderID
+	if sender == "" {
+		sender = p.cfg.SenderID
+	}
+
+	form := url.Values{}
+	form.Set("AppSid", p.cfg.AppSID)
+	form.Set("SenderID", sender)
+	form.Set("Recipient", strings.TrimPrefix(to, "+"))
+	form.Set("Body", msg.Body)
+	form.Set("responseType", "JSON")
+	if p.cfg.CallbackURL != "" {
+		form.Set("statusCallback", "sent")
+		form.Set("CorrelationID", msg.Tags["correlation_id"])
+	}
+
+	baseURL := p.cfg.BaseURL
+	if baseURL == "" {
+		baseURL = "https://el.cloud.unifonic.com"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/rest/SMS/messages", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create unifonic request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var out unifonicResponse
+	if err := doJSON(p.client, p.Name(), req, &out); err != nil {
+		return nil, err
+	}
+	if !out.Success {
+		return nil, fmt.Errorf("unifonic rejected message [%s]: %s", out.ErrorCode, out.Message)
+	}
+
+	return &Result{
+		Provider:  p.Name(),
+		MessageID: toString(out.Data.MessageID),
+		Status:    parseUnifonicStatus(out.Data.Status),
+	}, nil
+}
+
+func parseUnifonicStatus(s string) Status {
+	switch strings.ToLower(s) {
+	case "queued", "scheduled":
+		return StatusQueued
+	case "sent":
+		return StatusSent
+	case "delivered":
+		return StatusDelivered
+	case "failed", "rejected":
+		return StatusFailed
+	case "undeliverable", "undelivered", "expired":
+		return StatusUndelivered
+	default:
+		return StatusUnknown
+	}
+}
@@ -0,0 +1,130 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// WhatsAppConfig holds WhatsApp Business Cloud API settings
+type WhatsAppConfig struct {
+	PhoneNumberID string
+	AccessToken   string
+	APIVersion    string // defaults to v21.0
+	BaseURL       string // defaults to https://graph.facebook.com
+	// Templates resolves the approved template name and language for Message.Template
+	Templates *Templates
+}
+
+// WhatsAppProvider sends messages through the WhatsApp Business Cloud API
+type WhatsAppProvider struct {
+	cfg    *WhatsAppConfig
+	client *http.Client
+}
+
+// NewWhatsAppProvider creates a WhatsApp provider
+func NewWhatsAppProvider(cfg *WhatsAppConfig) *WhatsAppProvider {
+	return &WhatsAppProvider{cfg: cfg, client: newHTTPClient()}
+}
+
+// Name implements Provider
+func (p *WhatsAppProvider) Name() string { return "whatsapp" }
+
+// Channel implements Provider
+func (p *WhatsAppProvider) Channel() Channel { return ChannelWhatsApp }
+
+// Send implements Provider. Messages with a Template are sent as template messages; plain
+// Body messages only reach users inside an open 24h customer-service window.
+func (p *WhatsAppProvider) Send(ctx context.Context, msg *Message) (*Result, error) {
+	to, err := Normalize(msg.To)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                strings.TrimPrefix(to, "+"),
+	}
+
+	if msg.Template != "" {
+		name, lang := msg.Template, normalizeLang(msg.Lang)
+		if p.cfg.Templates != nil {
+			if tmpl, ok := p.cfg.Templates.Get(msg.Template); ok {
+				name, lang = tmpl.WhatsApp(msg.Lang)
+			}
+		}
+
+		template := map[string]interface{}{
+			"name":     name,
+			"language": map[string]string{"code": lang},
+		}
+		if len(msg.Params) > 0 {
+			params := make([]map[string]string, 0, len(msg.Params))
+			for _, v := range msg.Params {
+				params = append(params, map[string]string{"type": "text", "text": v})
+			}
+			template["components"] = []map[string]interface{}{{"type": "body", "parameters": params}}
+		}
+		payload["type"] = "template"
+		payload["template"] = template
+	} else {
+		payload["type"] = "text"
+		payload["text"] = map[string]interface{}{"body": msg.Body}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal whatsapp request: %w", err)
+	}
+
+	baseURL := p.cfg.BaseURL
+	if baseURL == "" {
+		baseURL = "https://graph.facebook.com"
+	}
+	version := p.cfg.APIVersion
+	if version == "" {
+		version = "v21.0"
+	}
+	endpoint := fmt.Sprintf("%s/%s/%s/messages", baseURL, version, p.cfg.PhoneNumberID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create whatsapp request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.cfg.AccessToken)
+
+	var out struct {
+		Messages []struct {
+			ID            string `json:"id"`
+			MessageStatus string `json:"message_status"`
+		} `json:"messages"`
+	}
+	if err := doJSON(p.client, p.Name(), req, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Messages) == 0 {
+		return nil, fmt.Errorf("whatsapp returned no message id")
+	}
+
+	return &Result{Provider: p.Name(), MessageID: out.Messages[0].ID, Status: StatusQueued}, nil
+}
+
+func parseWhatsAppStatus(s string) Status {
+	switch s {
+	case "sent":
+		return StatusSent
+	case "delivered":
+		return StatusDelivered
+	case "read":
+		return StatusRead
+	case "failed":
+		return StatusFailed
+	default:
+		return StatusUnknown
+	}
+}
@@ -0,0 +1,363 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Security scheme names usable in Route.Security
+const (
+	SecurityBearer  = "bearerAuth"
+	SecurityService = "serviceAuth"
+)
+
+var pathParam = regexp.MustCompile(`[:*](\w+)`)
+
+// Route documents one endpoint. Request, Query and Response take zero values of the types,
+// e.g. Request: CreateCaseRequest{}, Response: []CaseDTO{}.
+type Route struct {
+	Method      string
+	Path        string
+	Summary     string
+	Description string
+	Tags        []string
+	OperationID string
+
+	Request interface{} // JSON body
+	Query   interface{} // struct bound with `form` tags
+	// Response is the type placed in the envelope's data field; nil documents a message-only response
+	Response interface{}
+	// Paginated wraps Response in dto.PaginatedResponse; Response should be the item type
+	Paginated bool
+	// Status is the success status, defaults to 200 (201 for POST)
+	Status int
+
+	// Security lists the accepted schemes, e.g. []string{openapi.SecurityBearer}; nil means public
+	Security []string
+	// Errors are extra documented error statuses besides the ones implied by the route
+	Errors     []int
+	Deprecated bool
+}
+
+// API collects documented routes and generates the OpenAPI document
+type API struct {
+	mu  sync.RWMutex
+	doc *Document
+	raw []byte
+}
+
+// New creates an API document with the standard envelope, error and security components
+func New(info Info) *API {
+	a := &API{doc: &Document{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+			SecuritySchemes: map[string]*SecurityScheme{
+				SecurityBearer: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				SecurityService: {Type: "apiKey", In: "header", Name: "X-Service-Secret",
+					Description: "Internal service-to-service calls, together with X-Service-ID"},
+			},
+		},
+	}}
+	a.doc.Components.Schemas["MessageResponse"] = a.baseEnvelope()
+	errResp := a.baseEnvelope()
+	errResp.Properties["errors"] = &Schema{Type: "array", Items: a.schemaFor(reflect.TypeOf(response.ErrorItem{}))}
+	a.doc.Components.Schemas["ErrorResponse"] = errResp
+	return a
+}
+
+// AddServer adds a base URL to the document
+func (a *API) AddServer(url, description string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.doc.Servers = append(a.doc.Servers, Server{URL: url, Description: description})
+	a.raw = nil
+}
+
+// AddTag describes a tag used by routes
+func (a *API) AddTag(name, description string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.doc.Tags = append(a.doc.Tags, Tag{Name: name, Description: description})
+	a.raw = nil
+}
+
+// Register documents route and mounts handlers on rg, keeping the spec and the router in sync
+func (a *API) Register(rg gin.IRoutes, route Route, handlers ...gin.HandlerFunc) {
+	basePath := ""
+	if g, ok := rg.(*gin.RouterGroup); ok {
+		basePath = g.BasePath()
+	}
+	full := route
+	full.Path = joinPath(basePath, route.Path)
+	a.Document(full)
+	rg.Handle(strings.ToUpper(route.Method), route.Path, handlers...)
+}
+
+// Document adds route to the spec without mounting it, for routes registered elsewhere
+func (a *API) Document(route Route) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.raw = nil
+
+	method := strings.ToUpper(route.Method)
+	path := pathParam.ReplaceAllString(route.Path, "{$1}")
+
+	op := &Operation{
+		OperationID: route.OperationID,
+		Summary:     route.Summary,
+		Description: route.Description,
+		Tags:        route.Tags,
+		Responses:   make(map[string]*Response),
+		Deprecated:  route.Deprecated,
+	}
+	if op.OperationID == "" {
+		op.OperationID = operationID(method, path)
+	}
+
+	for _, m := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{Name: m[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	if route.Query != nil {
+		op.Parameters = append(op.Parameters, a.queryParams(reflect.TypeOf(route.Query))...)
+	}
+	if route.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: a.schemaFor(reflect.TypeOf(route.Request))}},
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+		if method == http.MethodPost {
+			status = http.StatusCreated
+		}
+	}
+	var data reflect.Type
+	if route.Response != nil {
+		data = reflect.TypeOf(route.Response)
+	}
+	op.Responses[strconv.Itoa(status)] = &Response{
+		Description: http.StatusText(status),
+		Content:     map[string]MediaType{"application/json": {Schema: a.envelopeRef(data, route.Paginated)}},
+	}
+
+	errs := append([]int{http.StatusInternalServerError}, route.Errors...)
+	if route.Request != nil || route.Query != nil {
+		errs = append(errs, http.StatusBadRequest, http.StatusUnprocessableEntity)
+	}
+	if len(route.Security) > 0 {
+		errs = append(errs, http.StatusUnauthorized, http.StatusForbidden)
+		for _, scheme := range route.Security {
+			op.Security = append(op.Security, SecurityRequirement{scheme: {}})
+		}
+	}
+	if strings.Contains(path, "{") {
+		errs = append(errs, http.StatusNotFound)
+	}
+	for _, code := range errs {
+		op.Responses[strconv.Itoa(code)] = &Response{
+			Description: http.StatusText(code),
+			Content:     map[string]MediaType{"application/json": {Schema: &Schema{Ref: "#/components/schemas/ErrorResponse"}}},
+		}
+	}
+
+	item := a.doc.Paths[path]
+	if item == nil {
+		item = &PathItem{}
+		a.doc.Paths[path] = item
+	}
+	switch method {
+	case http.MethodGet:
+		item.Get = op
+	case http.MethodPost:
+		item.Post = op
+	case http.MethodPut:
+		item.Put = op
+	case http.MethodPatch:
+		item.Patch = op
+	case http.MethodDelete:
+		item.Delete = op
+	}
+}
+
+// Spec returns the generated document
+func (a *API) Spec() *Document {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.doc
+}
+
+// JSON returns the document encoded as JSON, cached until the next change
+func (a *API) JSON() ([]byte, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.raw == nil {
+		raw, err := json.MarshalIndent(a.doc, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		a.raw = raw
+	}
+	return a.raw, nil
+}
+
+// envelopeRef returns a reference to the envelope component wrapping data
+func (a *API) envelopeRef(data reflect.Type, paginated bool) *Schema {
+	if data == nil {
+		return &Schema{Ref: "#/components/schemas/MessageResponse"}
+	}
+	name := "ApiResponse_"
+	if paginated {
+		name += "Paginated_"
+	}
+	name += schemaName(data)
+
+	if _, ok := a.doc.Components.Schemas[name]; !ok {
+		a.doc.Components.Schemas[name] = a.envelope(data, paginated)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// baseEnvelope is the response.ApiResponse schema without data
+func (a *API) baseEnvelope() *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"success": {Type: "boolean"},
+			"message": {Type: "string"},
+			"code":    {Type: "string", Description: "stable error code, set on failures"},
+			"meta":    a.schemaFor(reflect.TypeOf(response.Meta{})),
+		},
+		Required: []string{"success", "message"},
+	}
+}
+
+// envelope builds the response.ApiResponse schema around data
+func (a *API) envelope(data reflect.Type, paginated bool) *Schema {
+	s := a.baseEnvelope()
+	if paginated {
+		s.Properties["data"] = a.paginated(data)
+	} else {
+		s.Properties["data"] = a.schemaFor(data)
+	}
+	s.Required = append(s.Required, "data")
+	return s
+}
+
+// paginated describes dto.PaginatedResponse of item
+func (a *API) paginated(item reflect.Type) *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"items":        {Type: "array", Items: a.schemaFor(item)},
+			"total":        {Type: "integer", Format: "int64"},
+			"page":         {Type: "integer", Format: "int32"},
+			"limit":        {Type: "integer", Format: "int32"},
+			"total_pages":  {Type: "integer", Format: "int32"},
+			"has_next":     {Type: "boolean"},
+			"has_previous": {Type: "boolean"},
+		},
+		Required: []string{"items", "total", "page", "limit", "total_pages", "has_next", "has_previous"},
+	}
+}
+
+// queryParams turns the `form` fields of a struct into query parameters
+func (a *API) queryParams(t reflect.Type) []Parameter {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var params []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			params = append(params, a.queryParams(f.Type)...)
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("form"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		tags := f.Tag.Get("binding")
+		schema := a.schemaFor(f.Type)
+		if schema.Ref == "" {
+			applyRules(schema, tags)
+		}
+		params = append(params, Parameter{
+			Name:        name,
+			In:          "query",
+			Description: f.Tag.Get("description"),
+			Required:    hasRule(tags, "required"),
+			Schema:      schema,
+		})
+	}
+	return params
+}
+
+// schemaName names the envelope of t, e.g. []Case becomes List_Case
+func schemaName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return "List_" + schemaName(t.Elem())
+	case reflect.Map:
+		return "Map_" + schemaName(t.Elem())
+	}
+	if t.Name() == "" {
+		return "Object"
+	}
+	return componentName(t)
+}
+
+func joinPath(base, path string) string {
+	if base == "" || base == "/" {
+		return "/" + strings.TrimPrefix(path, "/")
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// operationID derives e.g. get_cases_id from GET /cases/{id}
+func operationID(method, path string) string {
+	parts := []string{strings.ToLower(method)}
+	for _, seg := range strings.Split(path, "/") {
+		seg = strings.Trim(seg, "{}")
+		if seg != "" {
+			parts = append(parts, strings.ReplaceAll(seg, "-", "_"))
+		}
+	}
+	return strings.Join(parts, "_")
+}
+
+// Paths returns the documented paths in sorted order
+func (a *API) Paths() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	paths := make([]string, 0, len(a.doc.Paths))
+	for p := range a.doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
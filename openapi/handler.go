@@ -0,0 +1,70 @@
+package openapi
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// swaggerUIVersion pins the Swagger UI assets loaded from the CDN
+const swaggerUIVersion = "5.17.14"
+
+var swaggerPage = template.Must(template.New("swagger").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui-bundle.js" crossorigin></script>
+<script>
+window.ui = SwaggerUIBundle({ url: {{.SpecURL}}, dom_id: "#swagger-ui", persistAuthorization: true });
+</script>
+</body>
+</html>`))
+
+// SpecHandler serves the document as JSON
+func (a *API) SpecHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw, err := a.JSON()
+		if err != nil {
+			response.InternalErrorWithCause(c, err)
+			return
+		}
+		c.Data(http.StatusOK, "application/json; charset=utf-8", raw)
+	}
+}
+
+// UIHandler serves Swagger UI pointing at specURL
+func (a *API) UIHandler(specURL string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		url := specURL
+		// Forward e.g. ?api_key= so the spec request passes the same auth as the page
+		if c.Request.URL.RawQuery != "" {
+			url += "?" + c.Request.URL.RawQuery
+		}
+
+		// The UI loads its assets from the CDN, which the default self-only CSP would block
+		c.Header("Content-Security-Policy", "default-src 'self'; script-src 'self' 'unsafe-inline' https://unpkg.com; style-src 'self' 'unsafe-inline' https://unpkg.com; img-src 'self' data: https://unpkg.com")
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.Status(http.StatusOK)
+		_ = swaggerPage.Execute(c.Writer, map[string]string{
+			"Title":   a.Spec().Info.Title,
+			"Version": swaggerUIVersion,
+			"SpecURL": url,
+		})
+	}
+}
+
+// Mount serves /openapi.json and the Swagger UI at /docs on rg, behind the given middleware
+// (e.g. middleware.AuthMiddleware() or middleware.APIKeyAuthMiddleware(key)) so the API
+// surface is not public
+func (a *API) Mount(rg *gin.RouterGroup, auth ...gin.HandlerFunc) {
+	docs := rg.Group("", auth...)
+	docs.GET("/openapi.json", a.SpecHandler())
+	docs.GET("/docs", a.UIHandler(joinPath(rg.BasePath(), "/openapi.json")))
+}
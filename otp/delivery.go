@@ -0,0 +1,70 @@
+package otp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masharah-Advisory/common/notify/email"
+	"github.com/Masharah-Advisory/common/notify/sms"
+)
+
+// Delivery is a code ready to be sent
+type Delivery struct {
+	Purpose     string
+	Destination string
+	Channel     Channel
+	Code        string
+	Lang        string
+	TTL         time.Duration
+	TenantID    string
+}
+
+// Deliverer sends a code to the user
+type Deliverer func(ctx context.Context, d Delivery) error
+
+// SMSDeliverer sends SMS and WhatsApp codes with the named template, passing the code and
+// the validity in minutes as {{1}} and {{2}}. WhatsApp needs an approved authentication template.
+func SMSDeliverer(sender *sms.Sender, template string) Deliverer {
+	return func(ctx context.Context, d Delivery) error {
+		_, err := sender.Send(ctx, sms.Channel(d.Channel), &sms.Message{
+			To:       d.Destination,
+			Template: template,
+			Lang:     d.Lang,
+			Params:   []string{d.Code, minutes(d.TTL)},
+			TenantID: d.TenantID,
+			Tags:     map[string]string{"purpose": d.Purpose},
+		})
+		return err
+	}
+}
+
+// EmailDeliverer queues the named email template with code, minutes and purpose as data
+func EmailDeliverer(sender *email.Sender, template string) Deliverer {
+	return func(ctx context.Context, d Delivery) error {
+		_, err := sender.QueueTemplate(ctx, &email.TemplateMessage{
+			Template: template,
+			Lang:     d.Lang,
+			To:       []email.Address{{Email: d.Destination}},
+			Data: map[string]interface{}{
+				"code":    d.Code,
+				"minutes": minutes(d.TTL),
+				"purpose": d.Purpose,
+			},
+			TenantID: d.TenantID,
+			Tags:     map[string]string{"purpose": d.Purpose},
+		})
+		return err
+	}
+}
+
+// Deliverers routes each channel to its own deliverer
+func Deliverers(byChannel map[Channel]Deliverer) Deliverer {
+	return func(ctx context.Context, d Delivery) error {
+		deliver, ok := byChannel[d.Channel]
+		if !ok {
+			return fmt.Errorf("no otp deliverer for channel %s", d.Channel)
+		}
+		return deliver(ctx, d)
+	}
+}
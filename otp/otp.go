@@ -0,0 +1,326 @@
+package otp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/crypto"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/notify/sms"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// Channel is how a code reaches the user
+type Channel string
+
+const (
+	ChannelSMS      Channel = "sms"
+	ChannelWhatsApp Channel = "whatsapp"
+	ChannelEmail    Channel = "email"
+)
+
+// Common purposes; codes are scoped to a purpose so a login code cannot confirm a payment
+const (
+	PurposeLogin               = "login"
+	PurposePhoneVerification   = "phone_verification"
+	PurposeEmailVerification   = "email_verification"
+	PurposePaymentConfirmation = "payment_confirmation"
+)
+
+// Errors returned by Generate and Verify
+var (
+	ErrRateLimited = apperror.New("otp_rate_limited", apperror.KindRateLimited, "too many codes requested").
+			WithKey("otp.rate_limited")
+	ErrInvalidCode = apperror.New("otp_invalid_code", apperror.KindBadRequest, "invalid verification code").
+			WithKey("otp.invalid_code")
+	ErrExpired = apperror.New("otp_expired", apperror.KindBadRequest, "verification code expired or not requested").
+			WithKey("otp.expired")
+	ErrTooManyAttempts = apperror.New("otp_too_many_attempts", apperror.KindRateLimited, "too many verification attempts").
+				WithKey("otp.too_many_attempts")
+)
+
+// Config holds the OTP settings
+type Config struct {
+	Redis  *redis.Client
+	Prefix string // key prefix, defaults to "otp:"
+
+	Length   int    // code length, defaults to 6
+	Alphabet string // defaults to digits; crypto.Unambiguous suits alphanumeric codes
+	TTL      time.Duration
+	// MaxAttempts is the number of wrong guesses before the code is burned, defaults to 5
+	MaxAttempts int
+
+	// ResendInterval is the cooldown between codes for the same purpose and destination, defaults to 60s
+	ResendInterval time.Duration
+	// MaxSends codes per destination within SendWindow across all purposes, defaults to 5 per hour
+	MaxSends   int
+	SendWindow time.Duration
+
+	// Secret keys the HMAC of stored codes so a Redis dump does not reveal them; at least 32 bytes
+	Secret []byte
+
+	// Deliverer sends generated codes; nil leaves delivery to the caller via Challenge.Code
+	Deliverer Deliverer
+}
+
+// Request asks for a new code
+type Request struct {
+	Purpose     string
+	Destination string // phone number or email address
+	Channel     Channel
+	Lang        string
+	TenantID    string
+}
+
+// Challenge describes an issued code
+type Challenge struct {
+	Purpose     string    `json:"purpose"`
+	Destination string    `json:"destination"`
+	Channel     Channel   `json:"channel"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	ResendAfter time.Time `json:"resend_after"`
+	// Code is only meant for the Deliverer and tests, never return it to clients
+	Code string `json:"-"`
+}
+
+// Manager issues and verifies one-time codes stored in Redis
+type Manager struct {
+	cfg *Config
+	log *zap.Logger
+}
+
+// verifyScript counts an attempt only while the code exists, so attempts never create keys
+var verifyScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return {-1, ''}
+end
+local attempts = redis.call('HINCRBY', KEYS[1], 'attempts', 1)
+return {attempts, redis.call('HGET', KEYS[1], 'hash')}
+`)
+
+// NewManager creates an OTP manager
+func NewManager(cfg *Config) (*Manager, error) {
+	if cfg.Redis == nil {
+		return nil, fmt.Errorf("otp requires a redis client")
+	}
+	if len(cfg.Secret) < 32 {
+		return nil, fmt.Errorf("otp secret must be at least 32 bytes")
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = "otp:"
+	}
+	if cfg.Length <= 0 {
+		cfg.Length = 6
+	}
+	if cfg.Alphabet == "" {
+		cfg.Alphabet = "0123456789"
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 5 * time.Minute
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.ResendInterval <= 0 {
+		cfg.ResendInterval = time.Minute
+	}
+	if cfg.MaxSends <= 0 {
+		cfg.MaxSends = 5
+	}
+	if cfg.SendWindow <= 0 {
+		cfg.SendWindow = time.Hour
+	}
+	return &Manager{cfg: cfg, log: logger.Module("otp")}, nil
+}
+
+// Generate issues a new code for the purpose and destination, replacing any previous one, and
+// delivers it through the configured Deliverer
+func (m *Manager) Generate(ctx context.Context, req Request) (*Challenge, error) {
+	dest, err := normalizeDestination(req.Channel, req.Destination)
+	if err != nil {
+		return nil, apperror.BadRequest("otp_invalid_destination", err.Error()).WithKey("otp.invalid_destination")
+	}
+	if req.Purpose == "" {
+		return nil, fmt.Errorf("otp purpose is required")
+	}
+
+	if err := m.reserve(ctx, req.Purpose, dest); err != nil {
+		return nil, err
+	}
+
+	code, err := crypto.RandomString(m.cfg.Length, m.cfg.Alphabet)
+	if err != nil {
+		return nil, err
+	}
+
+	key := m.codeKey(req.Purpose, dest)
+	pipe := m.cfg.Redis.TxPipeline()
+	pipe.Del(ctx, key)
+	pipe.HSet(ctx, key, "hash", m.hash(req.Purpose, dest, code), "attempts", 0)
+	pipe.Expire(ctx, key, m.cfg.TTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to store otp: %w", err)
+	}
+
+	now := time.Now()
+	challenge := &Challenge{
+		Purpose:     req.Purpose,
+		Destination: dest,
+		Channel:     req.Channel,
+		ExpiresAt:   now.Add(m.cfg.TTL),
+		ResendAfter: now.Add(m.cfg.ResendInterval),
+		Code:        code,
+	}
+
+	if m.cfg.Deliverer != nil {
+		delivery := Delivery{
+			Purpose:     req.Purpose,
+			Destination: dest,
+			Channel:     req.Channel,
+			Code:        code,
+			Lang:        req.Lang,
+			TTL:         m.cfg.TTL,
+			TenantID:    req.TenantID,
+		}
+		if err := m.cfg.Deliverer(ctx, delivery); err != nil {
+			// The user never received it, so drop the code and let them retry right away
+			m.cfg.Redis.Del(ctx, key, m.cooldownKey(req.Purpose, dest))
+			return nil, fmt.Errorf("failed to deliver otp: %w", err)
+		}
+	}
+
+	m.log.Info("otp issued", zap.String("purpose", req.Purpose), zap.String("channel", string(req.Channel)), zap.String("destination", mask(dest)))
+	return challenge, nil
+}
+
+// Verify checks code for the purpose and destination. A correct code is consumed; wrong codes
+// count towards MaxAttempts, after which the code is burned.
+func (m *Manager) Verify(ctx context.Context, purpose string, channel Channel, destination, code string) error {
+	dest, err := normalizeDestination(channel, destination)
+	if err != nil {
+		return ErrInvalidCode
+	}
+	key := m.codeKey(purpose, dest)
+
+	res, err := verifyScript.Run(ctx, m.cfg.Redis, []string{key}).Slice()
+	if err != nil {
+		return fmt.Errorf("failed to verify otp: %w", err)
+	}
+	attempts, _ := res[0].(int64)
+	stored, _ := res[1].(string)
+	if attempts < 0 {
+		return ErrExpired
+	}
+	if attempts > int64(m.cfg.MaxAttempts) {
+		m.cfg.Redis.Del(ctx, key)
+		return ErrTooManyAttempts
+	}
+
+	code = strings.TrimSpace(code)
+	if !crypto.Equal(stored, m.hash(purpose, dest, code)) {
+		if attempts == int64(m.cfg.MaxAttempts) {
+			m.cfg.Redis.Del(ctx, key)
+			return ErrTooManyAttempts
+		}
+		return ErrInvalidCode
+	}
+
+	// Deleting is the single-use guard: only the request that removes the key succeeds
+	n, err := m.cfg.Redis.Del(ctx, key).Result()
+	if err != nil {
+		return fmt.Errorf("failed to consume otp: %w", err)
+	}
+	if n == 0 {
+		return ErrExpired
+	}
+	return nil
+}
+
+// Invalidate drops any outstanding code for the purpose and destination
+func (m *Manager) Invalidate(ctx context.Context, purpose string, channel Channel, destination string) error {
+	dest, err := normalizeDestination(channel, destination)
+	if err != nil {
+		return err
+	}
+	if err := m.cfg.Redis.Del(ctx, m.codeKey(purpose, dest)).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate otp: %w", err)
+	}
+	return nil
+}
+
+// reserve enforces the resend cooldown and the per-destination send quota
+func (m *Manager) reserve(ctx context.Context, purpose, dest string) error {
+	ok, err := m.cfg.Redis.SetNX(ctx, m.cooldownKey(purpose, dest), 1, m.cfg.ResendInterval).Result()
+	if err != nil {
+		return fmt.Errorf("failed to check otp cooldown: %w", err)
+	}
+	if !ok {
+		return ErrRateLimited
+	}
+
+	countKey := m.cfg.Prefix + "sends:" + dest
+	pipe := m.cfg.Redis.TxPipeline()
+	incr := pipe.Incr(ctx, countKey)
+	ttl := pipe.TTL(ctx, countKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to count otp sends: %w", err)
+	}
+	// Start the window on the first send only so retries cannot keep extending it
+	if ttl.Val() < 0 {
+		m.cfg.Redis.Expire(ctx, countKey, m.cfg.SendWindow)
+	}
+	if incr.Val() > int64(m.cfg.MaxSends) {
+		return ErrRateLimited
+	}
+	return nil
+}
+
+func (m *Manager) codeKey(purpose, dest string) string {
+	return m.cfg.Prefix + "code:" + purpose + ":" + dest
+}
+
+func (m *Manager) cooldownKey(purpose, dest string) string {
+	return m.cfg.Prefix + "cooldown:" + purpose + ":" + dest
+}
+
+func (m *Manager) hash(purpose, dest, code string) string {
+	return crypto.HMACHex(m.cfg.Secret, []byte(purpose+":"+dest+":"+code))
+}
+
+// normalizeDestination canonicalizes phone numbers to E.164 and lowercases emails so the same
+// user always maps to the same keys
+func normalizeDestination(channel Channel, destination string) (string, error) {
+	switch channel {
+	case ChannelSMS, ChannelWhatsApp:
+		return sms.Normalize(destination)
+	case ChannelEmail:
+		dest := strings.ToLower(strings.TrimSpace(destination))
+		if !strings.Contains(dest, "@") {
+			return "", fmt.Errorf("invalid email address")
+		}
+		return dest, nil
+	default:
+		return "", fmt.Errorf("unsupported otp channel %q", channel)
+	}
+}
+
+// mask hides most of a destination for logs
+func mask(dest string) string {
+	if at := strings.IndexByte(dest, '@'); at > 0 {
+		return dest[:1] + "***" + dest[at:]
+	}
+	if len(dest) > 4 {
+		return strings.Repeat("*", len(dest)-4) + dest[len(dest)-4:]
+	}
+	return "****"
+}
+
+// minutes formats a TTL for message templates
+func minutes(ttl time.Duration) string {
+	return strconv.Itoa(int((ttl + time.Minute - 1) / time.Minute))
+}
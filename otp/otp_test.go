@@ -0,0 +1,120 @@
+package otp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func newTestManager(t *testing.T, cfg Config) (*Manager, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	cfg.Redis = rdb
+	cfg.Secret = []byte(strings.Repeat("s", 32))
+	m, err := NewManager(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, mr
+}
+
+func TestNewManagerRequiresSecret(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+	for _, secret := range [][]byte{nil, []byte("short")} {
+		if _, err := NewManager(&Config{Redis: rdb, Secret: secret}); err == nil {
+			t.Errorf("NewManager with a %d-byte secret succeeded, want an error", len(secret))
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	const email = "User@Example.com"
+	tests := []struct {
+		name    string
+		guesses func(code string) []string
+		purpose string
+		want    []error
+	}{
+		{"correct code is single use", func(code string) []string { return []string{code, code} }, PurposeLogin, []error{nil, ErrExpired}},
+		{"wrong then correct", func(code string) []string { return []string{"000000x", code} }, PurposeLogin, []error{ErrInvalidCode, nil}},
+		{"burned after max attempts", func(code string) []string { return []string{"x", "x", "x", code} }, PurposeLogin, []error{ErrInvalidCode, ErrInvalidCode, ErrTooManyAttempts, ErrExpired}},
+		{"scoped to purpose", func(code string) []string { return []string{code} }, PurposePaymentConfirmation, []error{ErrExpired}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, mr := newTestManager(t, Config{MaxAttempts: 3})
+			challenge, err := m.Generate(ctx, Request{Purpose: PurposeLogin, Destination: email, Channel: ChannelEmail})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The stored value is an HMAC, never the code itself
+			if stored := mr.HGet(m.codeKey(PurposeLogin, "user@example.com"), "hash"); stored == "" || strings.Contains(stored, challenge.Code) {
+				t.Fatalf("stored hash %q reveals the code %q", stored, challenge.Code)
+			}
+			for i, guess := range tt.guesses(challenge.Code) {
+				if err := m.Verify(ctx, tt.purpose, ChannelEmail, email, guess); !errors.Is(err, tt.want[i]) {
+					t.Fatalf("guess %d: Verify = %v, want %v", i+1, err, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateRateLimits(t *testing.T) {
+	ctx := context.Background()
+	req := Request{Purpose: PurposeLogin, Destination: "a@example.com", Channel: ChannelEmail}
+
+	t.Run("resend cooldown", func(t *testing.T) {
+		m, mr := newTestManager(t, Config{ResendInterval: time.Minute})
+		if _, err := m.Generate(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.Generate(ctx, req); !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("second Generate = %v, want %v", err, ErrRateLimited)
+		}
+		mr.FastForward(time.Minute)
+		if _, err := m.Generate(ctx, req); err != nil {
+			t.Fatalf("Generate after the cooldown = %v", err)
+		}
+	})
+
+	t.Run("sends per window", func(t *testing.T) {
+		m, _ := newTestManager(t, Config{MaxSends: 2, ResendInterval: time.Millisecond})
+		for i, purpose := range []string{PurposeLogin, PurposeEmailVerification, PurposePaymentConfirmation} {
+			want := error(nil)
+			if i == 2 {
+				want = ErrRateLimited
+			}
+			if _, err := m.Generate(ctx, Request{Purpose: purpose, Destination: req.Destination, Channel: ChannelEmail}); !errors.Is(err, want) {
+				t.Fatalf("send %d: Generate = %v, want %v", i+1, err, want)
+			}
+		}
+	})
+
+	t.Run("failed delivery releases the cooldown", func(t *testing.T) {
+		fail := true
+		m, _ := newTestManager(t, Config{ResendInterval: time.Minute, Deliverer: func(ctx context.Context, d Delivery) error {
+			if fail {
+				return errors.New("provider down")
+			}
+			return nil
+		}})
+		if _, err := m.Generate(ctx, req); err == nil {
+			t.Fatal("Generate succeeded although delivery failed")
+		}
+		fail = false
+		if _, err := m.Generate(ctx, req); err != nil {
+			t.Fatalf("Generate after a failed delivery = %v", err)
+		}
+	})
+}
@@ -0,0 +1,251 @@
+package payments
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/extclient"
+	"github.com/Masharah-Advisory/common/retry"
+	"github.com/google/uuid"
+)
+
+// idempotencyNamespace derives Moyasar's given_id (a UUID) from our idempotency keys
+var idempotencyNamespace = uuid.MustParse("6f1c1a2e-9d4b-4b8e-a1f0-3c2d5e7f9a10")
+
+// MoyasarConfig configures the Moyasar gateway
+type MoyasarConfig struct {
+	// SecretKey is the API secret key (sk_test_... / sk_live_...)
+	SecretKey string
+	// WebhookSecret is the shared secret configured on the webhook in the Moyasar dashboard
+	WebhookSecret string
+	// BaseURL defaults to https://api.moyasar.com/v1
+	BaseURL string
+	// Transport is passed to extclient, e.g. an extclient.Recorder in tests
+	Transport http.RoundTripper
+}
+
+// MoyasarConfigFromEnv reads MOYASAR_SECRET_KEY, MOYASAR_WEBHOOK_SECRET and MOYASAR_BASE_URL
+func MoyasarConfigFromEnv() *MoyasarConfig {
+	return &MoyasarConfig{
+		SecretKey:     os.Getenv("MOYASAR_SECRET_KEY"),
+		WebhookSecret: os.Getenv("MOYASAR_WEBHOOK_SECRET"),
+		BaseURL:       os.Getenv("MOYASAR_BASE_URL"),
+	}
+}
+
+// Moyasar is the Gateway for Moyasar (mada, Visa/Mastercard, Apple Pay, STC Pay)
+type Moyasar struct {
+	cfg    *MoyasarConfig
+	client *extclient.Client
+}
+
+// NewMoyasar creates the Moyasar gateway
+func NewMoyasar(cfg *MoyasarConfig) (*Moyasar, error) {
+	if cfg.SecretKey == "" {
+		return nil, fmt.Errorf("moyasar secret key is required")
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://api.moyasar.com/v1"
+	}
+
+	policy := retry.Default()
+	client, err := extclient.New(&extclient.Provider{
+		Name:    "moyasar",
+		BaseURL: cfg.BaseURL,
+		// Moyasar uses basic auth with the secret key as the user name
+		Headers:   map[string]string{"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(cfg.SecretKey+":"))},
+		Retry:     &policy,
+		Breaker:   true,
+		Transport: cfg.Transport,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Moyasar{cfg: cfg, client: client}, nil
+}
+
+// Name implements Gateway
+func (m *Moyasar) Name() string {
+	return "moyasar"
+}
+
+// moyasarPayment is the payment object of the Moyasar API
+type moyasarPayment struct {
+	ID          string            `json:"id"`
+	Status      string            `json:"status"`
+	Amount      int64             `json:"amount"`
+	Captured    int64             `json:"captured"`
+	Refunded    int64             `json:"refunded"`
+	Currency    string            `json:"currency"`
+	Description string            `json:"description"`
+	Metadata    map[string]string `json:"metadata"`
+	CreatedAt   time.Time         `json:"created_at"`
+	Source      struct {
+		Type           string `json:"type"`
+		Message        string `json:"message"`
+		TransactionURL string `json:"transaction_url"`
+	} `json:"source"`
+}
+
+// CreateCharge implements Gateway; the idempotency key becomes Moyasar's given_id, so a
+// retried request cannot create a second payment
+func (m *Moyasar) CreateCharge(ctx context.Context, req *ChargeRequest) (*Charge, error) {
+	source := map[string]interface{}{"type": string(req.Source.Type)}
+	switch req.Source.Type {
+	case SourceSTCPay:
+		source["mobile"] = req.Source.Mobile
+	default:
+		source["token"] = req.Source.Token
+	}
+	if req.AuthorizeOnly {
+		source["manual"] = "true"
+	}
+
+	body := map[string]interface{}{
+		"amount":   req.Amount,
+		"currency": req.Currency,
+		"source":   source,
+	}
+	if req.Description != "" {
+		body["description"] = req.Description
+	}
+	if req.CallbackURL != "" {
+		body["callback_url"] = req.CallbackURL
+	}
+	if len(req.Metadata) > 0 {
+		body["metadata"] = req.Metadata
+	}
+	if req.IdempotencyKey != "" {
+		body["given_id"] = uuid.NewSHA1(idempotencyNamespace, []byte(req.IdempotencyKey)).String()
+	}
+
+	return m.call(ctx, &extclient.Request{Method: http.MethodPost, Path: "/payments", Body: body}, "create payment")
+}
+
+// GetCharge implements Gateway
+func (m *Moyasar) GetCharge(ctx context.Context, id string) (*Charge, error) {
+	return m.call(ctx, &extclient.Request{Method: http.MethodGet, Path: "/payments/" + url.PathEscape(id)}, "fetch payment")
+}
+
+// Capture implements Gateway
+func (m *Moyasar) Capture(ctx context.Context, id string, amount int64) (*Charge, error) {
+	return m.call(ctx, &extclient.Request{Method: http.MethodPost, Path: "/payments/" + url.PathEscape(id) + "/capture", Body: amountBody(amount)}, "capture payment")
+}
+
+// Refund implements Gateway
+func (m *Moyasar) Refund(ctx context.Context, id string, amount int64) (*Charge, error) {
+	return m.call(ctx, &extclient.Request{Method: http.MethodPost, Path: "/payments/" + url.PathEscape(id) + "/refund", Body: amountBody(amount)}, "refund payment")
+}
+
+// amountBody omits the amount when 0 so Moyasar applies the full amount
+func amountBody(amount int64) map[string]interface{} {
+	if amount == 0 {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{"amount": amount}
+}
+
+// call performs a request returning a payment object
+func (m *Moyasar) call(ctx context.Context, req *extclient.Request, op string) (*Charge, error) {
+	var raw json.RawMessage
+	if err := m.client.DoJSON(ctx, req, &raw); err != nil {
+		return nil, fmt.Errorf("failed to %s: %w", op, err)
+	}
+	return m.toCharge(raw)
+}
+
+// toCharge converts a Moyasar payment object
+func (m *Moyasar) toCharge(raw json.RawMessage) (*Charge, error) {
+	var p moyasarPayment
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, fmt.Errorf("failed to decode moyasar payment: %w", err)
+	}
+	return &Charge{
+		ID:          p.ID,
+		Gateway:     m.Name(),
+		Status:      moyasarStatus(p.Status),
+		Amount:      p.Amount,
+		Captured:    p.Captured,
+		Refunded:    p.Refunded,
+		Currency:    p.Currency,
+		Description: p.Description,
+		RedirectURL: p.Source.TransactionURL,
+		Message:     p.Source.Message,
+		Metadata:    p.Metadata,
+		CreatedAt:   p.CreatedAt,
+		Raw:         raw,
+	}, nil
+}
+
+// moyasarStatus maps Moyasar payment statuses
+func moyasarStatus(status string) Status {
+	switch status {
+	case "paid", "captured":
+		return StatusPaid
+	case "authorized":
+		return StatusAuthorized
+	case "failed":
+		return StatusFailed
+	case "refunded":
+		return StatusRefunded
+	case "voided":
+		return StatusVoided
+	default:
+		return StatusInitiated
+	}
+}
+
+// moyasarEvents maps Moyasar webhook types
+var moyasarEvents = map[string]EventType{
+	"payment_paid":       EventPaid,
+	"payment_authorized": EventAuthorized,
+	"payment_captured":   EventCaptured,
+	"payment_failed":     EventFailed,
+	"payment_refunded":   EventRefunded,
+	"payment_voided":     EventVoided,
+}
+
+// ParseWebhook implements Gateway; Moyasar authenticates webhooks with the shared secret_token
+// field of the body
+func (m *Moyasar) ParseWebhook(header http.Header, body []byte) (*WebhookEvent, error) {
+	var payload struct {
+		ID          string          `json:"id"`
+		Type        string          `json:"type"`
+		CreatedAt   time.Time       `json:"created_at"`
+		SecretToken string          `json:"secret_token"`
+		Live        bool            `json:"live"`
+		Data        json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, ErrInvalidWebhook
+	}
+	if m.cfg.WebhookSecret == "" || subtle.ConstantTimeCompare([]byte(payload.SecretToken), []byte(m.cfg.WebhookSecret)) != 1 {
+		return nil, ErrInvalidWebhook
+	}
+
+	eventType, ok := moyasarEvents[strings.ToLower(payload.Type)]
+	if !ok {
+		return nil, ErrUnknownEvent
+	}
+	charge, err := m.toCharge(payload.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebhookEvent{
+		ID:        payload.ID,
+		Gateway:   m.Name(),
+		Type:      eventType,
+		Charge:    charge,
+		Live:      payload.Live,
+		CreatedAt: payload.CreatedAt,
+	}, nil
+}
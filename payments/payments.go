@@ -0,0 +1,122 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+)
+
+// Status is the lifecycle state of a charge, normalized across gateways
+type Status string
+
+const (
+	StatusInitiated  Status = "initiated"  // waiting for the customer (3-D Secure, wallet approval)
+	StatusAuthorized Status = "authorized" // funds held, capture pending
+	StatusPaid       Status = "paid"       // captured
+	StatusFailed     Status = "failed"
+	StatusRefunded   Status = "refunded" // fully or partially, see Charge.Refunded
+	StatusVoided     Status = "voided"
+)
+
+// SourceType is how the customer pays
+type SourceType string
+
+const (
+	SourceToken    SourceType = "token"    // card tokenized client-side by the gateway's form
+	SourceApplePay SourceType = "applepay" // Apple Pay payment token
+	SourceSTCPay   SourceType = "stcpay"   // STC Pay wallet, identified by mobile number
+)
+
+// Source identifies the payment method; raw card numbers never reach our services
+type Source struct {
+	Type   SourceType `json:"type"`
+	Token  string     `json:"token,omitempty"`
+	Mobile string     `json:"mobile,omitempty"`
+}
+
+// ChargeRequest creates a charge
+type ChargeRequest struct {
+	// IdempotencyKey makes retries of the same charge safe, e.g. the order number
+	IdempotencyKey string
+	// Amount is in minor units (halalas for SAR)
+	Amount      int64
+	Currency    string // defaults to SAR
+	Description string
+	Source      Source
+	// CallbackURL receives the customer after 3-D Secure or wallet approval
+	CallbackURL string
+	// AuthorizeOnly holds the funds; call Capture to collect them
+	AuthorizeOnly bool
+	Metadata      map[string]string
+}
+
+// Charge is a payment as reported by the gateway
+type Charge struct {
+	ID          string `json:"id"`
+	Gateway     string `json:"gateway"`
+	Status      Status `json:"status"`
+	Amount      int64  `json:"amount"`
+	Captured    int64  `json:"captured"`
+	Refunded    int64  `json:"refunded"`
+	Currency    string `json:"currency"`
+	Description string `json:"description,omitempty"`
+	// RedirectURL is where the customer completes 3-D Secure, when Status is initiated
+	RedirectURL string            `json:"redirect_url,omitempty"`
+	Message     string            `json:"message,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	Raw         json.RawMessage   `json:"-"`
+}
+
+// EventType is a normalized webhook event type
+type EventType string
+
+const (
+	EventPaid       EventType = "payment.paid"
+	EventAuthorized EventType = "payment.authorized"
+	EventCaptured   EventType = "payment.captured"
+	EventFailed     EventType = "payment.failed"
+	EventRefunded   EventType = "payment.refunded"
+	EventVoided     EventType = "payment.voided"
+)
+
+// WebhookEvent is a verified gateway notification
+type WebhookEvent struct {
+	ID        string    `json:"id"`
+	Gateway   string    `json:"gateway"`
+	Type      EventType `json:"type"`
+	Charge    *Charge   `json:"charge"`
+	Live      bool      `json:"live"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Gateway is implemented by each payment provider
+type Gateway interface {
+	// Name identifies the gateway, e.g. "moyasar"
+	Name() string
+	// CreateCharge starts a payment; gateways use req.IdempotencyKey natively where supported
+	CreateCharge(ctx context.Context, req *ChargeRequest) (*Charge, error)
+	// GetCharge fetches the current state of a charge
+	GetCharge(ctx context.Context, id string) (*Charge, error)
+	// Capture collects an authorized charge; amount 0 captures the full amount
+	Capture(ctx context.Context, id string, amount int64) (*Charge, error)
+	// Refund returns money to the customer; amount 0 refunds what is left
+	Refund(ctx context.Context, id string, amount int64) (*Charge, error)
+	// ParseWebhook verifies a webhook request and decodes it
+	ParseWebhook(header http.Header, body []byte) (*WebhookEvent, error)
+}
+
+// Errors returned by the payments package
+var (
+	ErrDuplicateRequest = apperror.New("payment_duplicate_request", apperror.KindConflict, "payment request already in progress").
+				WithKey("payments.duplicate_request")
+	ErrInvalidAmount = apperror.New("payment_invalid_amount", apperror.KindBadRequest, "invalid payment amount").
+				WithKey("payments.invalid_amount")
+	ErrInvalidWebhook = apperror.New("payment_invalid_webhook", apperror.KindUnauthorized, "invalid payment webhook").
+				WithKey("payments.invalid_webhook")
+	ErrUnknownEvent = apperror.New("payment_unknown_event", apperror.KindBadRequest, "unknown payment webhook event").
+			WithKey("payments.unknown_event")
+)
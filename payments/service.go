@@ -0,0 +1,129 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Masharah-Advisory/common/dedupe"
+)
+
+// Config configures the payments service
+type Config struct {
+	Gateway Gateway
+	// Dedupe guards operations carrying an idempotency key and webhook deliveries (optional)
+	Dedupe dedupe.Store
+	// DedupeTTL is how long keys are remembered, defaults to 24h
+	DedupeTTL time.Duration
+}
+
+// Service wraps a gateway with idempotency guarantees
+type Service struct {
+	cfg *Config
+}
+
+// NewService creates a payments service
+func NewService(cfg *Config) *Service {
+	if cfg.DedupeTTL <= 0 {
+		cfg.DedupeTTL = 24 * time.Hour
+	}
+	return &Service{cfg: cfg}
+}
+
+// Gateway returns the underlying gateway
+func (s *Service) Gateway() Gateway {
+	return s.cfg.Gateway
+}
+
+// CreateCharge starts a payment. A request whose IdempotencyKey is already being processed or
+// succeeded fails with ErrDuplicateRequest instead of charging the customer twice; failed
+// attempts release the key so they can be retried.
+func (s *Service) CreateCharge(ctx context.Context, req *ChargeRequest) (*Charge, error) {
+	if req.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if req.Currency == "" {
+		req.Currency = "SAR"
+	}
+	return once(ctx, s, "charge:"+req.IdempotencyKey, req.IdempotencyKey != "", func() (*Charge, error) {
+		return s.cfg.Gateway.CreateCharge(ctx, req)
+	})
+}
+
+// GetCharge fetches the current state of a charge
+func (s *Service) GetCharge(ctx context.Context, id string) (*Charge, error) {
+	return s.cfg.Gateway.GetCharge(ctx, id)
+}
+
+// Capture collects an authorized charge once per idempotency key; amount 0 captures everything
+func (s *Service) Capture(ctx context.Context, id string, amount int64, idempotencyKey string) (*Charge, error) {
+	if amount < 0 {
+		return nil, ErrInvalidAmount
+	}
+	return once(ctx, s, "capture:"+id+":"+idempotencyKey, idempotencyKey != "", func() (*Charge, error) {
+		return s.cfg.Gateway.Capture(ctx, id, amount)
+	})
+}
+
+// Refund returns money once per idempotency key; amount 0 refunds what is left
+func (s *Service) Refund(ctx context.Context, id string, amount int64, idempotencyKey string) (*Charge, error) {
+	if amount < 0 {
+		return nil, ErrInvalidAmount
+	}
+	return once(ctx, s, "refund:"+id+":"+idempotencyKey, idempotencyKey != "", func() (*Charge, error) {
+		return s.cfg.Gateway.Refund(ctx, id, amount)
+	})
+}
+
+// once runs fn under a dedupe claim when guarded and a store is configured
+func once(ctx context.Context, s *Service, key string, guarded bool, fn func() (*Charge, error)) (*Charge, error) {
+	if !guarded || s.cfg.Dedupe == nil {
+		return fn()
+	}
+
+	key = "payments:" + s.cfg.Gateway.Name() + ":" + key
+	claimed, err := s.cfg.Dedupe.Claim(ctx, key, s.cfg.DedupeTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim payment idempotency key: %w", err)
+	}
+	if !claimed {
+		return nil, ErrDuplicateRequest
+	}
+
+	charge, err := fn()
+	if err != nil {
+		_ = s.cfg.Dedupe.Release(ctx, key)
+		return nil, err
+	}
+	return charge, nil
+}
+
+// FormatAmount renders minor units as a decimal string, e.g. 1050 SAR -> "10.50"
+func FormatAmount(amount int64, currency string) string {
+	digits := minorDigits(currency)
+	if digits == 0 {
+		return strconv.FormatInt(amount, 10)
+	}
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	scale := int64(1)
+	for i := 0; i < digits; i++ {
+		scale *= 10
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/scale, digits, amount%scale)
+}
+
+// minorDigits returns the number of decimal places of a currency (ISO 4217)
+func minorDigits(currency string) int {
+	switch currency {
+	case "KWD", "BHD", "OMR", "JOD":
+		return 3
+	case "JPY", "KRW":
+		return 0
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,65 @@
+package payments
+
+import (
+	"context"
+	"io"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/i18n"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxWebhookBody bounds webhook payloads read into memory
+const maxWebhookBody = 1 << 20
+
+// WebhookHandler verifies gateway webhooks, drops redeliveries of events already handled and
+// passes the rest to handle. When handle fails the event is released and the error response
+// makes the gateway deliver it again.
+func (s *Service) WebhookHandler(handle func(ctx context.Context, event *WebhookEvent) error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+		if err != nil {
+			response.BadRequest(c, i18n.T(c, "payments.invalid_webhook"))
+			return
+		}
+
+		event, err := s.cfg.Gateway.ParseWebhook(c.Request.Header, body)
+		if err != nil {
+			logger.FromContext(c).Warn("rejected payment webhook", zap.String("gateway", s.cfg.Gateway.Name()), zap.Error(err))
+			response.HandleError(c, err)
+			return
+		}
+
+		key := "payments:" + event.Gateway + ":webhook:" + event.ID
+		if s.cfg.Dedupe != nil && event.ID != "" {
+			claimed, err := s.cfg.Dedupe.Claim(c, key, s.cfg.DedupeTTL)
+			if err != nil {
+				response.InternalErrorWithCause(c, err)
+				return
+			}
+			if !claimed {
+				response.OKMessage(c, i18n.T(c, "payments.webhook_duplicate"))
+				return
+			}
+		}
+
+		if err := handle(c, event); err != nil {
+			if s.cfg.Dedupe != nil && event.ID != "" {
+				_ = s.cfg.Dedupe.Release(c, key)
+			}
+			logger.FromContext(c).Error("payment webhook handler failed",
+				zap.String("gateway", event.Gateway), zap.String("event_id", event.ID), zap.String("type", string(event.Type)), zap.Error(err))
+			if _, ok := apperror.As(err); ok {
+				response.HandleError(c, err)
+				return
+			}
+			response.InternalErrorWithCause(c, err, i18n.T(c, "error.internal"))
+			return
+		}
+
+		response.OKMessage(c, i18n.T(c, "payments.webhook_received"))
+	}
+}
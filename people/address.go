@@ -0,0 +1,157 @@
+package people
+
+import (
+	"strings"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/sanitize"
+	"github.com/Masharah-Advisory/common/validation"
+)
+
+// ErrInvalidAddress lists the invalid fields in its "fields" meta
+var ErrInvalidAddress = apperror.New("invalid_address", apperror.KindValidation, "invalid address").
+	WithKey("address.invalid")
+
+// Address is a Saudi national address as issued by SPL; the text fields are in one language,
+// use Localized[Address] to keep both
+type Address struct {
+	// ShortAddress is the 8-character code that encodes the whole address, e.g. RRRD2929
+	ShortAddress     string `json:"short_address,omitempty"`
+	BuildingNumber   string `json:"building_number"`
+	Street           string `json:"street"`
+	District         string `json:"district"`
+	City             string `json:"city"`
+	PostalCode       string `json:"postal_code"`
+	AdditionalNumber string `json:"additional_number,omitempty"`
+	Unit             string `json:"unit,omitempty"`
+}
+
+// Normalize trims the fields, converts Arabic-Indic digits and upper cases the short address
+func (a Address) Normalize() Address {
+	return Address{
+		ShortAddress:     strings.ToUpper(strings.ReplaceAll(digits(a.ShortAddress), " ", "")),
+		BuildingNumber:   digits(a.BuildingNumber),
+		Street:           sanitize.SingleLine(sanitize.Arabic(a.Street)),
+		District:         sanitize.SingleLine(sanitize.Arabic(a.District)),
+		City:             sanitize.SingleLine(sanitize.Arabic(a.City)),
+		PostalCode:       digits(a.PostalCode),
+		AdditionalNumber: digits(a.AdditionalNumber),
+		Unit:             digits(a.Unit),
+	}
+}
+
+// Validate checks the address after normalizing it. Building number, street, district, city and
+// postal code are required; the short address and additional number are checked when set.
+func (a Address) Validate() error {
+	a = a.Normalize()
+	var fields []string
+	if !validation.IsBuildingNumber(a.BuildingNumber) {
+		fields = append(fields, "building_number")
+	}
+	if a.Street == "" {
+		fields = append(fields, "street")
+	}
+	if a.District == "" {
+		fields = append(fields, "district")
+	}
+	if a.City == "" {
+		fields = append(fields, "city")
+	}
+	if !validation.IsPostalCode(a.PostalCode) {
+		fields = append(fields, "postal_code")
+	}
+	if a.ShortAddress != "" && !validation.IsShortAddress(a.ShortAddress) {
+		fields = append(fields, "short_address")
+	}
+	if a.AdditionalNumber != "" && !validation.IsAdditionalNumber(a.AdditionalNumber) {
+		fields = append(fields, "additional_number")
+	}
+	if len(fields) > 0 {
+		return ErrInvalidAddress.Clone().WithMeta("fields", strings.Join(fields, ", "))
+	}
+	return nil
+}
+
+// Lines formats the address as SPL prints it on envelopes, e.g. in English
+//
+//	8228 Imam Abdullah Road, Al Mursalat Dist.
+//	Unit 12
+//	RIYADH 12463 - 2121
+//
+// and in Arabic
+//
+//	8228 طريق الامام عبدالله - حي المرسلات
+//	وحدة رقم 12
+//	الرياض 12463 - 2121
+func (a Address) Lines(lang string) []string {
+	a = a.Normalize()
+	rtl := i18n.IsRTL(lang)
+
+	street := join(" ", a.BuildingNumber, a.Street)
+	district := a.District
+	var first string
+	if rtl {
+		if district != "" && !strings.HasPrefix(district, "حي") {
+			district = "حي " + district
+		}
+		first = join(" - ", street, district)
+	} else {
+		if district != "" && !strings.Contains(strings.ToLower(district), "dist") {
+			district += " Dist."
+		}
+		first = join(", ", street, district)
+	}
+
+	city := a.City
+	if !rtl {
+		city = strings.ToUpper(city)
+	}
+	last := join(" ", city, a.PostalCode)
+	if a.AdditionalNumber != "" {
+		last = join(" - ", last, a.AdditionalNumber)
+	}
+
+	lines := make([]string, 0, 3)
+	if first != "" {
+		lines = append(lines, first)
+	}
+	if a.Unit != "" {
+		if rtl {
+			lines = append(lines, "وحدة رقم "+a.Unit)
+		} else {
+			lines = append(lines, "Unit "+a.Unit)
+		}
+	}
+	if last != "" {
+		lines = append(lines, last)
+	}
+	return lines
+}
+
+// Format is Lines joined with newlines
+func (a Address) Format(lang string) string {
+	return strings.Join(a.Lines(lang), "\n")
+}
+
+// OneLine is Lines joined with commas, for tables and notifications
+func (a Address) OneLine(lang string) string {
+	sep := ", "
+	if i18n.IsRTL(lang) {
+		sep = "، "
+	}
+	return strings.Join(a.Lines(lang), sep)
+}
+
+// digits trims s and converts Arabic-Indic digits to ASCII
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= '٠' && r <= '٩':
+			return '0' + (r - '٠')
+		case r >= '۰' && r <= '۹':
+			return '0' + (r - '۰')
+		}
+		return r
+	}, strings.TrimSpace(s))
+}
@@ -0,0 +1,23 @@
+package people
+
+import "github.com/Masharah-Advisory/common/i18n"
+
+// Localized holds the Arabic and English versions of a value, e.g. Localized[Name] or
+// Localized[Address]; store it with `gorm:"serializer:json"`
+type Localized[T comparable] struct {
+	Ar T `json:"ar"`
+	En T `json:"en"`
+}
+
+// For returns the version for lang, falling back to the other language when it is empty
+func (l Localized[T]) For(lang string) T {
+	var zero T
+	preferred, fallback := l.En, l.Ar
+	if i18n.IsRTL(lang) {
+		preferred, fallback = l.Ar, l.En
+	}
+	if preferred != zero {
+		return preferred
+	}
+	return fallback
+}
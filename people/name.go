@@ -0,0 +1,174 @@
+package people
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/sanitize"
+)
+
+// Name is a person's name in the Saudi four-part form: first, father, grandfather and family.
+// Each part is in one language; use Localized[Name] to keep the Arabic and English spellings.
+type Name struct {
+	First       string `json:"first"`
+	Father      string `json:"father,omitempty"`
+	Grandfather string `json:"grandfather,omitempty"`
+	Family      string `json:"family,omitempty"`
+}
+
+// prefixes are joined to the token that follows them, e.g. "عبد الله", "bin Salman", "Al Saud"
+var prefixes = map[string]bool{
+	"عبد": true, "أبو": true, "ابو": true, "بن": true, "بنت": true, "ابن": true, "آل": true,
+	"abd": true, "abdul": true, "abu": true, "bin": true, "bint": true, "ibn": true, "al": true, "el": true,
+}
+
+// suffixes are joined to the token before them, e.g. "نور الدين", "Saif Allah"
+var suffixes = map[string]bool{
+	"الله": true, "الدين": true,
+	"allah": true, "aldin": true, "al-din": true, "eddin": true, "uddin": true, "ud-din": true, "ad-din": true,
+}
+
+// ParseName splits a full name into its parts, keeping compound names such as "عبد الله" and
+// "Al Saud" together. Two parts are first and family, three add the father, and anything
+// between the father and the family goes to the grandfather.
+func ParseName(full string) Name {
+	tokens := nameTokens(full)
+	switch len(tokens) {
+	case 0:
+		return Name{}
+	case 1:
+		return Name{First: tokens[0]}
+	case 2:
+		return Name{First: tokens[0], Family: tokens[1]}
+	case 3:
+		return Name{First: tokens[0], Father: tokens[1], Family: tokens[2]}
+	default:
+		n := len(tokens)
+		return Name{
+			First:       tokens[0],
+			Father:      tokens[1],
+			Grandfather: strings.Join(tokens[2:n-1], " "),
+			Family:      tokens[n-1],
+		}
+	}
+}
+
+// nameTokens normalizes full and groups its words into name parts
+func nameTokens(full string) []string {
+	words := strings.Fields(NormalizeName(full))
+	var tokens []string
+	pending := ""
+	for _, w := range words {
+		key := strings.ToLower(w)
+		switch {
+		case prefixes[key]:
+			pending = strings.TrimSpace(pending + " " + w)
+		case pending != "":
+			tokens = append(tokens, pending+" "+w)
+			pending = ""
+		case suffixes[key] && len(tokens) > 0:
+			tokens[len(tokens)-1] += " " + w
+		default:
+			tokens = append(tokens, w)
+		}
+	}
+	if pending != "" {
+		tokens = append(tokens, pending)
+	}
+	return tokens
+}
+
+// NormalizeName cleans a name for storage: Arabic presentation forms and tatweel are folded,
+// whitespace is collapsed, and Latin words typed in one case are title cased ("MOHAMMED" and
+// "mohammed" become "Mohammed", "McKenzie" is kept)
+func NormalizeName(s string) string {
+	words := strings.Fields(sanitize.Arabic(s))
+	for i, w := range words {
+		words[i] = titleCase(w)
+	}
+	return strings.Join(words, " ")
+}
+
+// titleCase capitalizes each hyphenated segment of a Latin word typed entirely in one case
+func titleCase(w string) string {
+	if strings.ToLower(w) != w && strings.ToUpper(w) != w {
+		return w
+	}
+	segments := strings.Split(strings.ToLower(w), "-")
+	for i, seg := range segments {
+		runes := []rune(seg)
+		if len(runes) > 0 && unicode.In(runes[0], unicode.Latin) {
+			runes[0] = unicode.ToUpper(runes[0])
+		}
+		segments[i] = string(runes)
+	}
+	return strings.Join(segments, "-")
+}
+
+// Normalize returns the name with every part normalized
+func (n Name) Normalize() Name {
+	return Name{
+		First:       NormalizeName(n.First),
+		Father:      NormalizeName(n.Father),
+		Grandfather: NormalizeName(n.Grandfather),
+		Family:      NormalizeName(n.Family),
+	}
+}
+
+// IsZero reports whether no part is set
+func (n Name) IsZero() bool {
+	return n == Name{}
+}
+
+// Full joins every part in order: first, father, grandfather, family
+func (n Name) Full() string {
+	return join(" ", n.First, n.Father, n.Grandfather, n.Family)
+}
+
+// Short is the first and family name, as used in greetings and lists
+func (n Name) Short() string {
+	return join(" ", n.First, n.Family)
+}
+
+// Sortable puts the family name first for alphabetical lists, e.g. "Al Saud, Mohammed Salman";
+// Arabic names use the Arabic comma
+func (n Name) Sortable(lang string) string {
+	rest := join(" ", n.First, n.Father, n.Grandfather)
+	if n.Family == "" {
+		return rest
+	}
+	sep := ", "
+	if i18n.IsRTL(lang) {
+		sep = "، "
+	}
+	return join(sep, n.Family, rest)
+}
+
+// Initials returns the first letters of the first and family names, e.g. for avatars; prefixes
+// such as "Al" and "عبد" are skipped
+func (n Name) Initials() string {
+	var b strings.Builder
+	for _, part := range []string{n.First, n.Family} {
+		words := strings.Fields(part)
+		for len(words) > 1 && prefixes[strings.ToLower(words[0])] {
+			words = words[1:]
+		}
+		if len(words) > 0 {
+			r := []rune(words[0])[0]
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return b.String()
+}
+
+// join joins the non-empty parts with sep
+func join(sep string, parts ...string) string {
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, sep)
+}
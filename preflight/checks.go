@@ -0,0 +1,156 @@
+package preflight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masharah-Advisory/common/config"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+// ConfigValid runs the validators registered with config.RegisterValidator
+func ConfigValid() Check {
+	return Check{Name: "config", Run: func(ctx context.Context) error {
+		_, err := config.Validate(ctx)
+		return err
+	}}
+}
+
+// Env checks that environment variables are set
+func Env(names ...string) Check {
+	return Check{Name: "env", Run: config.RequireEnv(names...)}
+}
+
+// Database checks that the database answers a ping
+func Database(db *gorm.DB) Check {
+	return Check{Name: "database", Run: func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return fmt.Errorf("failed to get database handle: %w", err)
+		}
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return fmt.Errorf("database unreachable: %w", err)
+		}
+		return nil
+	}}
+}
+
+// Migrations checks that the table and every column of each model exist, catching a deploy
+// whose migrations did not run
+func Migrations(db *gorm.DB, models ...interface{}) Check {
+	return Check{Name: "migrations", Run: func(ctx context.Context) error {
+		tx := db.WithContext(ctx)
+		migrator := tx.Migrator()
+		var missing []string
+		for _, model := range models {
+			stmt := &gorm.Statement{DB: tx}
+			if err := stmt.Parse(model); err != nil {
+				return fmt.Errorf("failed to parse model %T: %w", model, err)
+			}
+			table := stmt.Schema.Table
+			if !migrator.HasTable(model) {
+				missing = append(missing, table)
+				continue
+			}
+			for _, field := range stmt.Schema.Fields {
+				if field.DBName != "" && !migrator.HasColumn(model, field.DBName) {
+					missing = append(missing, table+"."+field.DBName)
+				}
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("missing tables or columns: %s", strings.Join(missing, ", "))
+		}
+		return nil
+	}}
+}
+
+// Redis checks that Redis answers a ping
+func Redis(rdb *redis.Client) Check {
+	return Check{Name: "redis", Run: func(ctx context.Context) error {
+		if err := rdb.Ping(ctx).Err(); err != nil {
+			return fmt.Errorf("redis unreachable: %w", err)
+		}
+		return nil
+	}}
+}
+
+// Reachable checks that a service answers url without a server error, e.g.
+// preflight.Reachable("auth", authURL+"/health")
+func Reachable(name, url string) Check {
+	return Check{Name: name, Run: func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("invalid URL %q: %w", url, err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("%s unreachable: %w", name, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("%s answered %d", name, resp.StatusCode)
+		}
+		return nil
+	}}
+}
+
+// Locales checks that every locale file in dir (active.<lang>.json) has the same keys, so no
+// language falls back to raw message keys
+func Locales(dir string) Check {
+	return Check{Name: "locales", Run: func(ctx context.Context) error {
+		files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no locale files in %s", dir)
+		}
+
+		keys := make(map[string]map[string]bool, len(files))
+		all := make(map[string]bool)
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", file, err)
+			}
+			var messages map[string]interface{}
+			if err := json.Unmarshal(data, &messages); err != nil {
+				return fmt.Errorf("invalid locale file %s: %w", file, err)
+			}
+			name := filepath.Base(file)
+			keys[name] = make(map[string]bool, len(messages))
+			for k := range messages {
+				keys[name][k] = true
+				all[k] = true
+			}
+		}
+
+		var errs []error
+		for file, have := range keys {
+			var missing []string
+			for k := range all {
+				if !have[k] {
+					missing = append(missing, k)
+				}
+			}
+			if len(missing) == 0 {
+				continue
+			}
+			sort.Strings(missing)
+			if len(missing) > 10 {
+				missing = append(missing[:10], fmt.Sprintf("and %d more", len(missing)-10))
+			}
+			errs = append(errs, fmt.Errorf("%s is missing %s", file, strings.Join(missing, ", ")))
+		}
+		return errors.Join(errs...)
+	}}
+}
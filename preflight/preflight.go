@@ -0,0 +1,220 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masharah-Advisory/common/config"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"go.uber.org/zap"
+)
+
+// Severity decides what a failed check does to the boot
+type Severity string
+
+const (
+	// Fail stops the service from starting
+	Fail Severity = "fail"
+	// Warn logs the failure and starts anyway, e.g. for an optional downstream
+	Warn Severity = "warn"
+)
+
+// Check is a dependency verified at boot
+type Check struct {
+	Name     string
+	Severity Severity // defaults to Fail
+	Timeout  time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Config configures a Checker
+type Config struct {
+	// Timeout bounds each check without its own timeout, defaults to 10s
+	Timeout time.Duration
+	// WarnOnly downgrades every failure to a warning, e.g. for local development
+	WarnOnly bool
+	// Severity overrides the severity of checks by name
+	Severity map[string]Severity
+	// Skip lists checks that are not run
+	Skip []string
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Name     string        `json:"name"`
+	Severity Severity      `json:"severity"`
+	OK       bool          `json:"ok"`
+	Skipped  bool          `json:"skipped,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Report aggregates every check; OK is false when a check with Fail severity failed
+type Report struct {
+	OK       bool     `json:"ok"`
+	Env      string   `json:"env"`
+	Failed   int      `json:"failed"`
+	Warnings int      `json:"warnings"`
+	Results  []Result `json:"results"`
+}
+
+// Checker runs the registered checks at boot
+type Checker struct {
+	cfg *Config
+	log *zap.Logger
+
+	mu     sync.Mutex
+	checks []Check
+}
+
+// New creates a checker; register checks with Add before calling Run
+func New(cfg *Config) *Checker {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &Checker{cfg: cfg, log: logger.Module("preflight")}
+}
+
+// Add registers checks; a check with the name of an earlier one replaces it
+func (p *Checker) Add(checks ...Check) *Checker {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, c := range checks {
+		replaced := false
+		for i := range p.checks {
+			if p.checks[i].Name == c.Name {
+				p.checks[i], replaced = c, true
+			}
+		}
+		if !replaced {
+			p.checks = append(p.checks, c)
+		}
+	}
+	return p
+}
+
+// Run executes every check concurrently, logs the report and returns an error joining the
+// failures of Fail checks; warnings never produce an error
+func (p *Checker) Run(ctx context.Context) (*Report, error) {
+	p.mu.Lock()
+	checks := append([]Check(nil), p.checks...)
+	p.mu.Unlock()
+
+	skip := make(map[string]bool, len(p.cfg.Skip))
+	for _, name := range p.cfg.Skip {
+		skip[name] = true
+	}
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		results[i] = Result{Name: c.Name, Severity: p.severity(c)}
+		if skip[c.Name] {
+			results[i].Skipped, results[i].OK = true, true
+			continue
+		}
+		wg.Add(1)
+		go func(i int, c Check) {
+			defer wg.Done()
+			p.run(ctx, c, &results[i])
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := &Report{OK: true, Env: config.Env().String(), Results: results}
+	var errs []error
+	for _, r := range results {
+		if r.OK {
+			continue
+		}
+		if r.Severity == Warn {
+			report.Warnings++
+			continue
+		}
+		report.OK = false
+		report.Failed++
+		errs = append(errs, fmt.Errorf("%s: %s", r.Name, r.Error))
+	}
+
+	switch {
+	case !report.OK:
+		p.log.Error(report.String())
+	case report.Warnings > 0:
+		p.log.Warn(report.String())
+	default:
+		p.log.Info(report.String())
+	}
+	return report, errors.Join(errs...)
+}
+
+func (p *Checker) severity(c Check) Severity {
+	if p.cfg.WarnOnly {
+		return Warn
+	}
+	if s, ok := p.cfg.Severity[c.Name]; ok {
+		return s
+	}
+	if c.Severity == "" {
+		return Fail
+	}
+	return c.Severity
+}
+
+// run executes one check with its timeout, converting panics into failures
+func (p *Checker) run(ctx context.Context, c Check, result *Result) {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = p.cfg.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			result.OK = false
+			result.Error = fmt.Sprintf("panic: %v", r)
+		}
+		result.Duration = time.Since(start)
+	}()
+
+	if err := c.Run(ctx); err != nil {
+		result.Error = err.Error()
+		return
+	}
+	result.OK = true
+}
+
+// String renders the report as a human readable multi-line summary
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "preflight report (env=%s): ", r.Env)
+	switch {
+	case !r.OK:
+		fmt.Fprintf(&b, "FAILED (%d failed, %d warnings)", r.Failed, r.Warnings)
+	case r.Warnings > 0:
+		fmt.Fprintf(&b, "OK with %d warnings", r.Warnings)
+	default:
+		b.WriteString("OK")
+	}
+	for _, res := range r.Results {
+		status := "ok"
+		switch {
+		case res.Skipped:
+			status = "skipped"
+		case !res.OK && res.Severity == Warn:
+			status = "WARN: " + res.Error
+		case !res.OK:
+			status = "FAIL: " + res.Error
+		}
+		fmt.Fprintf(&b, "\n  - %s [%s] %s", res.Name, res.Duration.Round(time.Millisecond), status)
+	}
+	return b.String()
+}
@@ -0,0 +1,137 @@
+package privacy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/Masharah-Advisory/common/audit"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Mode selects how Erase treats a subject's records
+type Mode string
+
+const (
+	// ModeDelete removes the records permanently
+	ModeDelete Mode = "delete"
+	// ModeAnonymize keeps the records (e.g. for financial retention) but overwrites every
+	// field tagged `mask:"..."` or `privacy:"anonymize"`
+	ModeAnonymize Mode = "anonymize"
+)
+
+// ActionErase is the audit action recorded for erasure requests
+const ActionErase audit.Action = "privacy.erase"
+
+// Erase runs erasure across every source in reverse registration order and returns the number
+// of records changed per source. Sources not yet processed when an error occurs are left intact,
+// so the request can be retried.
+func (r *Registry) Erase(ctx context.Context, subject Subject, mode Mode) (map[string]int64, error) {
+	sources := r.list()
+	result := make(map[string]int64, len(sources))
+	for i := len(sources) - 1; i >= 0; i-- {
+		s := sources[i]
+		n, err := s.source.Erase(ctx, subject, mode)
+		if err != nil {
+			return result, fmt.Errorf("failed to erase %s: %w", s.name, err)
+		}
+		result[s.name] = n
+	}
+
+	_ = audit.Record(ctx, ActionErase, audit.Resource{Type: "user", ID: strconv.FormatUint(subject.UserID, 10)}, nil,
+		audit.WithMetadata("mode", string(mode)), audit.WithMetadata("records", result))
+	return result, nil
+}
+
+// ModelSource exposes a GORM model owned by a user through the Source interface
+type ModelSource[T any] struct {
+	DB *gorm.DB
+	// Column holds the owner's user ID, defaults to "user_id"
+	Column string
+	// TenantColumn scopes queries to the subject's tenant when set
+	TenantColumn string
+}
+
+// Model creates a source for the records of T whose column matches the subject's user ID
+func Model[T any](db *gorm.DB, column string) *ModelSource[T] {
+	return &ModelSource[T]{DB: db, Column: column}
+}
+
+func (s *ModelSource[T]) scope(ctx context.Context, subject Subject) *gorm.DB {
+	column := s.Column
+	if column == "" {
+		column = "user_id"
+	}
+	tx := s.DB.WithContext(ctx).Model(new(T)).Where(column+" = ?", subject.UserID)
+	if s.TenantColumn != "" && subject.TenantID != "" {
+		tx = tx.Where(s.TenantColumn+" = ?", subject.TenantID)
+	}
+	return tx
+}
+
+// Export implements Source
+func (s *ModelSource[T]) Export(ctx context.Context, subject Subject) (interface{}, error) {
+	var records []T
+	if err := s.scope(ctx, subject).Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Erase implements Source
+func (s *ModelSource[T]) Erase(ctx context.Context, subject Subject, mode Mode) (int64, error) {
+	if mode == ModeDelete {
+		res := s.scope(ctx, subject).Delete(new(T))
+		return res.RowsAffected, res.Error
+	}
+
+	updates, err := anonymizedColumns(new(T), s.DB.NamingStrategy)
+	if err != nil {
+		return 0, err
+	}
+	if len(updates) == 0 {
+		return 0, nil
+	}
+	res := s.scope(ctx, subject).Updates(updates)
+	return res.RowsAffected, res.Error
+}
+
+var schemaCache sync.Map
+
+// anonymizedColumns maps the columns of personal fields to their anonymous values
+func anonymizedColumns(model interface{}, namer schema.Namer) (map[string]interface{}, error) {
+	sch, err := schema.Parse(model, &schemaCache, namer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse model: %w", err)
+	}
+
+	updates := make(map[string]interface{})
+	for _, field := range sch.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		rule := field.Tag.Get("mask")
+		if rule == "" && field.Tag.Get("privacy") != "anonymize" {
+			continue
+		}
+		updates[field.DBName] = anonymousValue(field)
+	}
+	return updates, nil
+}
+
+// anonymousValue keeps NOT NULL and unique columns valid while removing the personal value
+func anonymousValue(field *schema.Field) interface{} {
+	switch {
+	case field.DataType == schema.String && field.Unique:
+		return gorm.Expr("'anonymized-' || md5(random()::text)")
+	case field.DataType == schema.String:
+		return Redacted
+	case field.NotNull:
+		return reflect.Zero(field.FieldType).Interface()
+	default:
+		return nil
+	}
+}
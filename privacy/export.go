@@ -0,0 +1,140 @@
+package privacy
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Masharah-Advisory/common/utils"
+)
+
+// Subject identifies the person a data-subject request is about
+type Subject struct {
+	UserID   uint64 `json:"user_id"`
+	TenantID string `json:"tenant_id,omitempty"`
+}
+
+// Source holds personal data about subjects, typically one table
+type Source interface {
+	// Export returns everything the source stores about the subject, ready for JSON encoding
+	Export(ctx context.Context, subject Subject) (interface{}, error)
+	// Erase deletes or anonymises the subject's data and returns how many records changed
+	Erase(ctx context.Context, subject Subject, mode Mode) (int64, error)
+}
+
+// Bundle is the result of an export across every registered source
+type Bundle struct {
+	Subject     Subject                `json:"subject"`
+	Service     string                 `json:"service"`
+	GeneratedAt time.Time              `json:"generated_at"`
+	Data        map[string]interface{} `json:"data"`
+}
+
+type namedSource struct {
+	name   string
+	source Source
+}
+
+// Registry is the list of sources a service holds personal data in
+type Registry struct {
+	mu      sync.RWMutex
+	sources []namedSource
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a source; register parents before children because erasure runs in reverse order
+func (r *Registry) Register(name string, source Source) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, s := range r.sources {
+		if s.name == name {
+			r.sources[i].source = source
+			return
+		}
+	}
+	r.sources = append(r.sources, namedSource{name: name, source: source})
+}
+
+func (r *Registry) list() []namedSource {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]namedSource(nil), r.sources...)
+}
+
+// Export assembles the subject's data from every source
+func (r *Registry) Export(ctx context.Context, subject Subject) (*Bundle, error) {
+	bundle := &Bundle{
+		Subject:     subject,
+		Service:     utils.ServiceID,
+		GeneratedAt: time.Now().UTC(),
+		Data:        make(map[string]interface{}),
+	}
+	for _, s := range r.list() {
+		data, err := s.source.Export(ctx, subject)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", s.name, err)
+		}
+		bundle.Data[s.name] = data
+	}
+	return bundle, nil
+}
+
+// WriteZIP writes the bundle as a ZIP archive with one JSON file per source plus a manifest
+func (b *Bundle) WriteZIP(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	manifest := struct {
+		Subject     Subject   `json:"subject"`
+		Service     string    `json:"service"`
+		GeneratedAt time.Time `json:"generated_at"`
+		Files       []string  `json:"files"`
+	}{Subject: b.Subject, Service: b.Service, GeneratedAt: b.GeneratedAt}
+
+	for name, data := range b.Data {
+		file := name + ".json"
+		manifest.Files = append(manifest.Files, file)
+		if err := writeJSON(zw, file, data, b.GeneratedAt); err != nil {
+			return err
+		}
+	}
+	if err := writeJSON(zw, "manifest.json", manifest, b.GeneratedAt); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish export archive: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(zw *zip.Writer, name string, v interface{}, modified time.Time) error {
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return fmt.Errorf("failed to add %s to export archive: %w", name, err)
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	return nil
+}
+
+var defaultRegistry = NewRegistry()
+
+// Register adds a source to the default registry
+func Register(name string, source Source) {
+	defaultRegistry.Register(name, source)
+}
+
+// Default returns the default registry
+func Default() *Registry {
+	return defaultRegistry
+}
@@ -0,0 +1,87 @@
+package privacy
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ExportHandler serves the subject's data as JSON, or as a ZIP download with ?format=zip.
+// The subject is the :user_id path parameter when the route has one (service-to-service calls
+// from the privacy service, protect with ServiceAuthMiddleware), otherwise the current user.
+func (r *Registry) ExportHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subject, ok := subjectFrom(c)
+		if !ok {
+			return
+		}
+
+		bundle, err := r.Export(c.Request.Context(), subject)
+		if err != nil {
+			response.InternalErrorWithCause(c, err)
+			return
+		}
+
+		if c.Query("format") != "zip" {
+			response.OK(c, bundle)
+			return
+		}
+		c.Header("Content-Type", "application/zip")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-data-%d.zip"`, bundle.Service, subject.UserID))
+		if err := bundle.WriteZIP(c.Writer); err != nil {
+			_ = c.Error(err)
+		}
+	}
+}
+
+// EraseHandler erases the subject's data; ?mode=anonymize keeps anonymised records, the default
+// deletes them. Mount it for the privacy service only.
+func (r *Registry) EraseHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subject, ok := subjectFrom(c)
+		if !ok {
+			return
+		}
+
+		mode := Mode(c.DefaultQuery("mode", string(ModeDelete)))
+		if mode != ModeDelete && mode != ModeAnonymize {
+			response.BadRequest(c, i18n.T(c, "privacy.invalid_mode"), response.Err("mode", i18n.T(c, "privacy.invalid_mode")))
+			return
+		}
+
+		result, err := r.Erase(c.Request.Context(), subject, mode)
+		if err != nil {
+			response.InternalErrorWithCause(c, err)
+			return
+		}
+		response.OK(c, gin.H{"mode": mode, "records": result}, i18n.T(c, "privacy.erased"))
+	}
+}
+
+// subjectFrom reads the subject from the :user_id parameter or the authenticated user, writing
+// an error response when neither is usable
+func subjectFrom(c *gin.Context) (Subject, bool) {
+	subject := Subject{TenantID: ctxutil.TenantID(c)}
+
+	if param := c.Param("user_id"); param != "" {
+		id, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			response.BadRequest(c, i18n.T(c, "invalid_user_id_format"))
+			return subject, false
+		}
+		subject.UserID = id
+		return subject, true
+	}
+
+	id, ok := ctxutil.UserID(c)
+	if !ok {
+		response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
+		return subject, false
+	}
+	subject.UserID = id
+	return subject, true
+}
@@ -0,0 +1,103 @@
+package privacy
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Redacted replaces values masked with the "redact" rule
+const Redacted = "[REDACTED]"
+
+// Masker masks a single value
+type Masker func(s string) string
+
+var (
+	maskersMu sync.RWMutex
+	maskers   = map[string]Masker{
+		"email":  MaskEmail,
+		"phone":  MaskPhone,
+		"iban":   MaskIBAN,
+		"name":   MaskName,
+		"last4":  MaskLast4,
+		"redact": func(s string) string { return Redacted },
+	}
+)
+
+// RegisterMasker adds or replaces a rule usable in `mask:"..."` tags
+func RegisterMasker(name string, fn Masker) {
+	maskersMu.Lock()
+	defer maskersMu.Unlock()
+	maskers[name] = fn
+}
+
+func masker(name string) (Masker, bool) {
+	maskersMu.RLock()
+	defer maskersMu.RUnlock()
+	fn, ok := maskers[name]
+	return fn, ok
+}
+
+// MaskEmail keeps the first letter of the local part and the domain: a***@example.com
+func MaskEmail(s string) string {
+	at := strings.LastIndexByte(s, '@')
+	if at <= 0 {
+		return MaskLast4(s)
+	}
+	first, _ := utf8.DecodeRuneInString(s)
+	return string(first) + "***" + s[at:]
+}
+
+// MaskPhone keeps the country prefix and the last 4 digits: +966*****4567
+func MaskPhone(s string) string {
+	digits := make([]rune, 0, len(s))
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, r)
+		}
+	}
+	if len(digits) <= 4 {
+		return strings.Repeat("*", len(digits))
+	}
+
+	keep := 0
+	if strings.HasPrefix(strings.TrimSpace(s), "+") && len(digits) > 10 {
+		keep = 3
+	}
+	var b strings.Builder
+	if keep > 0 {
+		b.WriteByte('+')
+		b.WriteString(string(digits[:keep]))
+	}
+	b.WriteString(strings.Repeat("*", len(digits)-keep-4))
+	b.WriteString(string(digits[len(digits)-4:]))
+	return b.String()
+}
+
+// MaskIBAN keeps the country code, check digits and the last 4 characters: SA03****************1234
+func MaskIBAN(s string) string {
+	iban := strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+	if len(iban) <= 8 {
+		return strings.Repeat("*", len(iban))
+	}
+	return iban[:4] + strings.Repeat("*", len(iban)-8) + iban[len(iban)-4:]
+}
+
+// MaskName keeps the first letter of each word: M*** A***
+func MaskName(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		first, _ := utf8.DecodeRuneInString(w)
+		words[i] = string(first) + "***"
+	}
+	return strings.Join(words, " ")
+}
+
+// MaskLast4 hides everything but the last 4 characters, e.g. national IDs and card numbers
+func MaskLast4(s string) string {
+	runes := []rune(s)
+	if len(runes) <= 4 {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-4:])
+}
@@ -0,0 +1,134 @@
+package privacy
+
+import (
+	"reflect"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Masked returns a copy of v with every string field tagged `mask:"rule"` masked; nested structs,
+// pointers, slices and maps are copied as needed so v itself is never modified. Use it before
+// logging or returning records to callers that must not see the raw values.
+func Masked[T any](v T) T {
+	rv := reflect.ValueOf(&v).Elem()
+	masked := maskValue(rv, "")
+	return masked.Interface().(T)
+}
+
+// Field is zap.Any with the value masked
+func Field[T any](key string, v T) zap.Field {
+	return zap.Any(key, Masked(v))
+}
+
+func maskValue(v reflect.Value, rule string) reflect.Value {
+	switch v.Kind() {
+	case reflect.String:
+		if rule == "" || v.Len() == 0 {
+			return v
+		}
+		fn, ok := masker(rule)
+		if !ok {
+			fn = func(string) string { return Redacted }
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.SetString(fn(v.String()))
+		return out
+
+	case reflect.Ptr:
+		if v.IsNil() || !needsMask(v.Type().Elem(), rule) {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(maskValue(v.Elem(), rule))
+		return out
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		inner := maskValue(v.Elem(), rule)
+		out := reflect.New(v.Type()).Elem()
+		out.Set(inner)
+		return out
+
+	case reflect.Struct:
+		if !needsMask(v.Type(), rule) {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			fieldRule, _, _ := strings.Cut(f.Tag.Get("mask"), ",")
+			if fieldRule == "" && !needsMask(f.Type, "") {
+				continue
+			}
+			out.Field(i).Set(maskValue(v.Field(i), fieldRule))
+		}
+		return out
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() || !needsMask(v.Type().Elem(), rule) {
+			return v
+		}
+		var out reflect.Value
+		if v.Kind() == reflect.Slice {
+			out = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		} else {
+			out = reflect.New(v.Type()).Elem()
+		}
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(maskValue(v.Index(i), rule))
+		}
+		return out
+
+	case reflect.Map:
+		if v.IsNil() || !needsMask(v.Type().Elem(), rule) {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), maskValue(iter.Value(), rule))
+		}
+		return out
+	}
+	return v
+}
+
+// needsMask reports whether values of t may contain something to mask, so untagged data is
+// shared instead of copied
+func needsMask(t reflect.Type, rule string) bool {
+	return needsMaskSeen(t, rule, map[reflect.Type]bool{})
+}
+
+func needsMaskSeen(t reflect.Type, rule string, seen map[reflect.Type]bool) bool {
+	switch t.Kind() {
+	case reflect.String:
+		return rule != ""
+	case reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return needsMaskSeen(t.Elem(), rule, seen)
+	case reflect.Struct:
+		if seen[t] {
+			return false
+		}
+		seen[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if f.Tag.Get("mask") != "" || needsMaskSeen(f.Type, "", seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
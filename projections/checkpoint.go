@@ -0,0 +1,126 @@
+package projections
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masharah-Advisory/common/events"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Checkpoint statuses
+const (
+	StatusLive       = "live"
+	StatusRebuilding = "rebuilding"
+)
+
+// Checkpoint tracks how far a projection got; it is written in the same transaction as the
+// read model, so both always agree
+type Checkpoint struct {
+	Name           string     `json:"name" gorm:"primaryKey;size:128"`
+	Version        int        `json:"version" gorm:"not null;default:0"`
+	Status         string     `json:"status" gorm:"size:16;not null"`
+	LastEventID    string     `json:"last_event_id" gorm:"size:64"`
+	LastEventType  string     `json:"last_event_type" gorm:"size:128"`
+	LastOccurredAt *time.Time `json:"last_occurred_at"`
+	Applied        int64      `json:"applied" gorm:"not null;default:0"`
+	RebuiltAt      *time.Time `json:"rebuilt_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (Checkpoint) TableName() string {
+	return "projection_checkpoints"
+}
+
+// Lag returns how far the projection is behind the last event it applied
+func (c *Checkpoint) Lag() time.Duration {
+	if c.LastOccurredAt == nil {
+		return 0
+	}
+	return time.Since(*c.LastOccurredAt)
+}
+
+// Checkpoints returns the checkpoint of every projection, e.g. for an ops endpoint
+func Checkpoints(ctx context.Context, db *gorm.DB) ([]Checkpoint, error) {
+	out := []Checkpoint{}
+	if err := db.WithContext(ctx).Order("name").Find(&out).Error; err != nil {
+		return nil, fmt.Errorf("failed to load projection checkpoints: %w", err)
+	}
+	return out, nil
+}
+
+// Checkpoint returns the checkpoint of the projection, or nil when it never applied an event
+func (p *Projector) Checkpoint(ctx context.Context) (*Checkpoint, error) {
+	var cp Checkpoint
+	err := p.cfg.DB.WithContext(ctx).Where("name = ?", p.proj.Name).Take(&cp).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load projection checkpoint: %w", err)
+	}
+	return &cp, nil
+}
+
+// NeedsRebuild reports whether the read model was built by an older Version of the projection
+func (p *Projector) NeedsRebuild(ctx context.Context) (bool, error) {
+	cp, err := p.Checkpoint(ctx)
+	if err != nil {
+		return false, err
+	}
+	return cp != nil && cp.Version < p.proj.Version, nil
+}
+
+// advance records event as the last one applied
+func (p *Projector) advance(tx *gorm.DB, event *events.Event) error {
+	occurred := event.OccurredAt
+	cp := &Checkpoint{
+		Name:           p.proj.Name,
+		Version:        p.proj.Version,
+		Status:         StatusLive,
+		LastEventID:    event.ID,
+		LastEventType:  event.Type,
+		LastOccurredAt: &occurred,
+		Applied:        1,
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "name"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"last_event_id":    event.ID,
+			"last_event_type":  event.Type,
+			"last_occurred_at": occurred,
+			"applied":          gorm.Expr("projection_checkpoints.applied + 1"),
+			"updated_at":       time.Now(),
+		}),
+	}).Create(cp).Error
+}
+
+// reset clears the checkpoint before a rebuild
+func (p *Projector) reset(tx *gorm.DB) error {
+	now := time.Now()
+	cp := &Checkpoint{Name: p.proj.Name, Version: p.proj.Version, Status: StatusRebuilding, RebuiltAt: &now}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "name"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"version":          p.proj.Version,
+			"status":           StatusRebuilding,
+			"last_event_id":    "",
+			"last_event_type":  "",
+			"last_occurred_at": nil,
+			"applied":          0,
+			"rebuilt_at":       now,
+			"updated_at":       now,
+		}),
+	}).Create(cp).Error
+}
+
+// setStatus updates the status of the checkpoint
+func (p *Projector) setStatus(ctx context.Context, status string) error {
+	return p.cfg.DB.WithContext(ctx).Model(&Checkpoint{}).
+		Where("name = ?", p.proj.Name).
+		Updates(map[string]interface{}{"status": status, "updated_at": time.Now()}).Error
+}
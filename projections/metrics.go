@@ -0,0 +1,24 @@
+package projections
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Events applied and how far each projection trails its stream
+var (
+	eventsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "projection_events_total",
+		Help: "Events handled by projections by result (applied, duplicate, ignored, failed, replayed).",
+	}, []string{"projection", "result"})
+
+	lagSeconds = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "projection_lag_seconds",
+		Help: "Age of the last event applied to a projection when it was applied.",
+	}, []string{"projection"})
+
+	lastApplied = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "projection_last_applied_timestamp_seconds",
+		Help: "Unix time of the last event applied to a projection.",
+	}, []string{"projection"})
+)
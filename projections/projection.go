@@ -0,0 +1,157 @@
+package projections
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/Masharah-Advisory/common/dedupe"
+	"github.com/Masharah-Advisory/common/events"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrRebuilding is returned for live events while the projection is being rebuilt, so the
+// subscriber redelivers them once the rebuild is done
+var ErrRebuilding = errors.New("projection is being rebuilt")
+
+// ApplyFunc updates the read model for one event inside tx
+type ApplyFunc func(ctx context.Context, tx *gorm.DB, event *events.Event) error
+
+// On adapts a typed apply function: the payload is decoded with events.Decode, and events that
+// cannot be decoded are dead-lettered without retries
+func On[T any](c *events.Catalog, fn func(ctx context.Context, tx *gorm.DB, event *events.Event, payload T) error) ApplyFunc {
+	return func(ctx context.Context, tx *gorm.DB, event *events.Event) error {
+		payload, err := events.Decode[T](c, event)
+		if err != nil {
+			return events.Permanent(err)
+		}
+		return fn(ctx, tx, event, payload)
+	}
+}
+
+// Projection builds a query-side table from events
+type Projection struct {
+	// Name identifies the projection in checkpoints, dedupe keys and metrics
+	Name string
+	// Version is bumped when the read model changes shape; NeedsRebuild compares it with the
+	// version stored in the checkpoint
+	Version int
+	// Apply maps event types to apply functions; other event types are ignored
+	Apply map[string]ApplyFunc
+	// Reset empties the read model before a rebuild, e.g. Truncate("order_summaries")
+	Reset func(ctx context.Context, tx *gorm.DB) error
+}
+
+// Config configures a Projector
+type Config struct {
+	DB *gorm.DB
+	// Dedupe makes apply functions idempotent by event ID; nil relies on the subscriber's dedupe
+	Dedupe    dedupe.Store
+	DedupeTTL time.Duration // defaults to 7 days
+}
+
+// Projector applies events to one projection and tracks its checkpoint
+type Projector struct {
+	cfg        *Config
+	proj       Projection
+	log        *zap.Logger
+	rebuilding atomic.Bool
+}
+
+// New creates a projector, e.g.
+//
+//	p := projections.New(&projections.Config{DB: db, Dedupe: store}, projections.Projection{
+//		Name:  "order_summaries",
+//		Apply: map[string]projections.ApplyFunc{"order.created": projections.On(catalog, onCreated)},
+//		Reset: projections.Truncate("order_summaries"),
+//	})
+//	sub.Subscribe(ctx, "orders", p.Handler())
+func New(cfg *Config, proj Projection) *Projector {
+	if cfg.DedupeTTL <= 0 {
+		cfg.DedupeTTL = 7 * 24 * time.Hour
+	}
+	return &Projector{
+		cfg:  cfg,
+		proj: proj,
+		log:  logger.Module("projections").With(zap.String("projection", proj.Name)),
+	}
+}
+
+// Name returns the name of the projection
+func (p *Projector) Name() string {
+	return p.proj.Name
+}
+
+// Handler returns the events handler to subscribe with
+func (p *Projector) Handler() events.Handler {
+	return p.Apply
+}
+
+// Apply applies a live event: duplicates and unknown event types are skipped, and the read
+// model and checkpoint are updated in one transaction
+func (p *Projector) Apply(ctx context.Context, event *events.Event) error {
+	fn, ok := p.proj.Apply[event.Type]
+	if !ok {
+		eventsTotal.WithLabelValues(p.proj.Name, "ignored").Inc()
+		return nil
+	}
+	if p.rebuilding.Load() {
+		return ErrRebuilding
+	}
+
+	key := p.dedupeKey(event)
+	if p.cfg.Dedupe != nil {
+		claimed, err := p.cfg.Dedupe.Claim(ctx, key, p.cfg.DedupeTTL)
+		if err != nil {
+			return fmt.Errorf("failed to claim event %s: %w", event.ID, err)
+		}
+		if !claimed {
+			eventsTotal.WithLabelValues(p.proj.Name, "duplicate").Inc()
+			return nil
+		}
+	}
+
+	if err := p.apply(ctx, fn, event); err != nil {
+		if p.cfg.Dedupe != nil {
+			_ = p.cfg.Dedupe.Release(ctx, key)
+		}
+		eventsTotal.WithLabelValues(p.proj.Name, "failed").Inc()
+		p.log.Warn("failed to apply event",
+			zap.String("event_id", event.ID),
+			zap.String("event_type", event.Type),
+			zap.Error(err),
+		)
+		return err
+	}
+	eventsTotal.WithLabelValues(p.proj.Name, "applied").Inc()
+	return nil
+}
+
+// apply runs fn and advances the checkpoint in one transaction
+func (p *Projector) apply(ctx context.Context, fn ApplyFunc, event *events.Event) error {
+	err := p.cfg.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := fn(ctx, tx, event); err != nil {
+			return err
+		}
+		if err := p.advance(tx, event); err != nil {
+			return fmt.Errorf("failed to save projection checkpoint: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !event.OccurredAt.IsZero() {
+		lagSeconds.WithLabelValues(p.proj.Name).Set(time.Since(event.OccurredAt).Seconds())
+	}
+	lastApplied.WithLabelValues(p.proj.Name).SetToCurrentTime()
+	return nil
+}
+
+func (p *Projector) dedupeKey(event *events.Event) string {
+	return "projections:" + p.proj.Name + ":" + event.ID
+}
@@ -0,0 +1,200 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	appconfig "github.com/Masharah-Advisory/common/config"
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/httpclient"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/Masharah-Advisory/common/telemetry"
+	"github.com/Masharah-Advisory/common/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrUnknownService = apperror.New("proxy_unknown_service", apperror.KindNotFound, "unknown service").WithKey("proxy.unknown_service")
+	ErrUnavailable    = apperror.New("proxy_unavailable", apperror.KindUnavailable, "service unavailable").WithKey("proxy.unavailable")
+	ErrTimeout        = apperror.New("proxy_timeout", apperror.KindTimeout, "service timed out").WithKey("proxy.timeout")
+)
+
+// defaultStrip are client-supplied headers that only the gateway may set
+var defaultStrip = []string{utils.XServiceIDHeader, utils.XServiceSecretHeader, utils.XUserIDHeader}
+
+// Policy controls how requests to one service cross the gateway
+type Policy struct {
+	// Strip removes request headers before forwarding, in addition to the service credential and
+	// user headers which are always replaced
+	Strip []string
+	// Set injects request headers, overriding client values
+	Set map[string]string
+	// StripResponse removes upstream response headers, e.g. "Server"
+	StripResponse []string
+	// Timeout overrides Config.Timeout; negative disables it for streaming endpoints (SSE, WebSocket)
+	Timeout time.Duration
+}
+
+// Config configures the proxy
+type Config struct {
+	// Services maps service names to their base URLs, the same map given to httpclient
+	Services httpclient.ServiceConfig
+	// Prefix is the route prefix before the service segment (default "/api/v1")
+	Prefix string
+	// ServiceID and ServiceSecret sign forwarded requests (default utils.ServiceID/ServiceSecret)
+	ServiceID     string
+	ServiceSecret string
+	// Timeout bounds the whole exchange with the upstream service (default 30s)
+	Timeout time.Duration
+	// Policy applies to every service; Policies entries replace it for a service
+	Policy   Policy
+	Policies map[string]Policy
+	// Transport defaults to the traced http.DefaultTransport
+	Transport http.RoundTripper
+}
+
+// Proxy forwards /api/v1/{service}/** to the configured service hosts
+type Proxy struct {
+	cfg     Config
+	targets map[string]*url.URL
+}
+
+// New creates a proxy, failing on invalid service URLs
+func New(cfg *Config) (*Proxy, error) {
+	c := *cfg
+	if c.Prefix == "" {
+		c.Prefix = "/api/v1"
+	}
+	c.Prefix = "/" + strings.Trim(c.Prefix, "/")
+	if c.ServiceID == "" {
+		c.ServiceID = utils.ServiceID
+	}
+	if c.ServiceSecret == "" {
+		c.ServiceSecret = utils.ServiceSecret
+	}
+	if c.Timeout == 0 {
+		c.Timeout = 30 * time.Second
+	}
+	if c.Transport == nil {
+		c.Transport = telemetry.Transport(nil)
+	}
+
+	appconfig.RegisterValidator("proxy.services", appconfig.RequireURLs(c.Services))
+
+	targets := make(map[string]*url.URL, len(c.Services))
+	for name, host := range c.Services {
+		target, err := url.Parse(host)
+		if err != nil || target.Scheme == "" || target.Host == "" {
+			return nil, fmt.Errorf("invalid URL for service %s: %q", name, host)
+		}
+		targets[name] = target
+	}
+
+	return &Proxy{cfg: c, targets: targets}, nil
+}
+
+// Register mounts the proxy on every method of {Prefix}/:service/*path; put auth middleware on
+// the group first so the user is known before forwarding
+func (p *Proxy) Register(r gin.IRouter) {
+	r.Any(p.cfg.Prefix+"/:service/*path", p.Handler())
+}
+
+// Handler forwards the request to the service named by the :service parameter
+func (p *Proxy) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		service := c.Param("service")
+		target, ok := p.targets[service]
+		if !ok {
+			response.HandleError(c, ErrUnknownService)
+			c.Abort()
+			return
+		}
+		policy, ok := p.cfg.Policies[service]
+		if !ok {
+			policy = p.cfg.Policy
+		}
+
+		ctx := c.Request.Context()
+		timeout := p.cfg.Timeout
+		if policy.Timeout != 0 {
+			timeout = policy.Timeout
+		}
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		req := c.Request.WithContext(ctx)
+		req.Header = p.headers(c, policy)
+		p.reverseProxy(c, service, target, policy).ServeHTTP(c.Writer, req)
+		c.Abort()
+	}
+}
+
+// headers builds the forwarded request headers: client values minus stripped ones, then the
+// gateway's credentials and the authenticated user
+func (p *Proxy) headers(c *gin.Context, policy Policy) http.Header {
+	h := c.Request.Header.Clone()
+	for _, name := range defaultStrip {
+		h.Del(name)
+	}
+	for _, name := range policy.Strip {
+		h.Del(name)
+	}
+
+	h.Set(utils.XServiceIDHeader, p.cfg.ServiceID)
+	h.Set(utils.XServiceSecretHeader, p.cfg.ServiceSecret)
+	if userID, ok := ctxutil.UserID(c); ok {
+		h.Set(utils.XUserIDHeader, strconv.FormatUint(userID, 10))
+	}
+	if requestID := ctxutil.RequestID(c); requestID != "" {
+		h.Set("X-Request-ID", requestID)
+	}
+	for name, value := range policy.Set {
+		h.Set(name, value)
+	}
+	return h
+}
+
+// reverseProxy builds the per-request proxy; it is cheap and lets errors render through c
+func (p *Proxy) reverseProxy(c *gin.Context, service string, target *url.URL, policy Policy) *httputil.ReverseProxy {
+	return &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.SetXForwarded()
+		},
+		Transport: p.cfg.Transport,
+		// Flush immediately so streamed responses (SSE, downloads) reach the client as they arrive
+		FlushInterval: -1,
+		ModifyResponse: func(resp *http.Response) error {
+			for _, name := range policy.StripResponse {
+				resp.Header.Del(name)
+			}
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			switch {
+			case errors.Is(c.Request.Context().Err(), context.Canceled):
+				// The client went away; there is nobody to answer
+				return
+			case errors.Is(err, context.DeadlineExceeded):
+				logger.FromContext(c).Warn("proxied request timed out", zap.String("service", service), zap.Error(err))
+				response.HandleError(c, ErrTimeout)
+			default:
+				logger.FromContext(c).Error("proxied request failed", zap.String("service", service), zap.Error(err))
+				response.HandleError(c, ErrUnavailable)
+			}
+		},
+	}
+}
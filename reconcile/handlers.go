@@ -0,0 +1,100 @@
+package reconcile
+
+import (
+	"strconv"
+
+	"github.com/Masharah-Advisory/common/dto"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Handlers exposes checks, runs and findings to operators; mount them behind admin auth
+type Handlers struct {
+	checker *Checker
+}
+
+// NewHandlers creates the handlers
+func NewHandlers(checker *Checker) *Handlers {
+	return &Handlers{checker: checker}
+}
+
+// Register mounts the endpoints on a router group, e.g. /admin/reconcile
+func (h *Handlers) Register(rg *gin.RouterGroup) {
+	rg.GET("", h.List)
+	rg.GET("/findings", h.Findings)
+	rg.GET("/:name/runs", h.Runs)
+	rg.POST("/:name/run", h.Run)
+}
+
+// CheckStatus is a check with its latest run
+type CheckStatus struct {
+	Check
+	LastRun *Run `json:"last_run,omitempty"`
+}
+
+// List returns the registered checks with their latest run
+func (h *Handlers) List(c *gin.Context) {
+	checks := h.checker.Checks()
+	items := make([]CheckStatus, 0, len(checks))
+	for _, check := range checks {
+		status := CheckStatus{Check: check}
+		runs, err := h.checker.Runs(c.Request.Context(), check.Name, 1)
+		if err != nil {
+			response.HandleError(c, err)
+			return
+		}
+		if len(runs) > 0 {
+			status.LastRun = &runs[0]
+		}
+		items = append(items, status)
+	}
+	response.OK(c, items)
+}
+
+// Findings pages through findings, filtered by ?check=, ?severity= and ?open=true
+func (h *Handlers) Findings(c *gin.Context) {
+	var q FindingQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.BadRequest(c, i18n.T(c, "reconcile.invalid_request"), response.ProcessBindingError(c, err))
+		return
+	}
+	q.normalize()
+	items, total, err := h.checker.Findings(c.Request.Context(), q)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if items == nil {
+		items = []Finding{}
+	}
+	response.OK(c, dto.BuildPaginatedResponse(items, total, q.Page, q.Limit))
+}
+
+// Runs returns the latest runs of a check, ?limit= of them
+func (h *Handlers) Runs(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	runs, err := h.checker.Runs(c.Request.Context(), c.Param("name"), limit)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if runs == nil {
+		runs = []Run{}
+	}
+	response.OK(c, runs)
+}
+
+// Run re-runs a check; once scheduled it runs in the background and the answer is 202
+func (h *Handlers) Run(c *gin.Context) {
+	run, err := h.checker.Trigger(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if run == nil {
+		response.Accepted(c, gin.H{"check": c.Param("name")})
+		return
+	}
+	response.OK(c, run)
+}
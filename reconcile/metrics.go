@@ -0,0 +1,35 @@
+package reconcile
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Runs, scanned records, open findings and timings of each consistency check
+var (
+	runsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "reconcile_runs_total",
+		Help: "Consistency check runs, by check and status.",
+	}, []string{"check", "status"})
+
+	scannedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "reconcile_records_scanned_total",
+		Help: "Records scanned by consistency checks.",
+	}, []string{"check"})
+
+	openFindings = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "reconcile_open_findings",
+		Help: "Discrepancies the last complete run of a consistency check found.",
+	}, []string{"check"})
+
+	runDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "reconcile_run_duration_seconds",
+		Help:    "Duration of consistency check runs.",
+		Buckets: []float64{1, 5, 15, 60, 300, 900, 1800, 3600},
+	}, []string{"check"})
+
+	lastSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "reconcile_last_success_timestamp_seconds",
+		Help: "Unix time of the last complete run of a consistency check.",
+	}, []string{"check"})
+)
@@ -0,0 +1,66 @@
+package reconcile
+
+import "time"
+
+// Severity ranks findings
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// RunStatus is the outcome of a check run
+type RunStatus string
+
+const (
+	RunRunning  RunStatus = "running"
+	RunOK       RunStatus = "ok"
+	RunFindings RunStatus = "findings"
+	RunFailed   RunStatus = "failed"
+)
+
+// Finding is one discrepancy, e.g. a billing account whose user does not exist in auth. It is
+// identified by check, key and kind, stays open while runs keep seeing it and is resolved by
+// the first complete run that does not.
+type Finding struct {
+	ID    uint64 `json:"id" gorm:"primaryKey;autoIncrement"`
+	Check string `json:"check" gorm:"uniqueIndex:idx_reconcile_finding;size:128;not null"`
+	// Key identifies the record, e.g. the account ID
+	Key string `json:"key" gorm:"uniqueIndex:idx_reconcile_finding;size:255;not null"`
+	// Kind tells discrepancies of one record apart, e.g. "missing" or "status_mismatch"
+	Kind        string                 `json:"kind" gorm:"uniqueIndex:idx_reconcile_finding;size:64;not null"`
+	Severity    Severity               `json:"severity" gorm:"size:16;not null"`
+	Message     string                 `json:"message" gorm:"size:1024"`
+	Details     map[string]interface{} `json:"details,omitempty" gorm:"serializer:json"`
+	FirstSeenAt time.Time              `json:"first_seen_at"`
+	LastSeenAt  time.Time              `json:"last_seen_at"`
+	LastRunID   uint64                 `json:"last_run_id" gorm:"index"`
+	ResolvedAt  *time.Time             `json:"resolved_at,omitempty" gorm:"index"`
+}
+
+// TableName overrides the table name
+func (Finding) TableName() string {
+	return "reconcile_findings"
+}
+
+// Run records one execution of a check
+type Run struct {
+	ID      uint64    `json:"id" gorm:"primaryKey;autoIncrement"`
+	Check   string    `json:"check" gorm:"index;size:128;not null"`
+	Status  RunStatus `json:"status" gorm:"size:16;not null"`
+	Scanned int       `json:"scanned"`
+	// Findings counts what the run saw; Opened and Resolved what changed since the previous run
+	Findings   int        `json:"findings"`
+	Opened     int        `json:"opened"`
+	Resolved   int        `json:"resolved"`
+	Error      string     `json:"error,omitempty" gorm:"size:1024"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// TableName overrides the table name
+func (Run) TableName() string {
+	return "reconcile_runs"
+}
@@ -0,0 +1,34 @@
+package reconcile
+
+import (
+	"context"
+
+	"github.com/Masharah-Advisory/common/notify"
+)
+
+// NotifyEvent is the notification event NotifyHook sends; register it on the dispatcher
+const NotifyEvent = "reconcile.findings"
+
+// NotifyHook tells the users about the findings a run opened. The data carries check,
+// run_id, opened, findings and up to ten keys.
+func NotifyHook(d *notify.Dispatcher, userIDs ...uint64) Hook {
+	return func(ctx context.Context, run *Run, opened []Finding) error {
+		if len(userIDs) == 0 {
+			return nil
+		}
+		keys := make([]string, 0, 10)
+		for _, f := range opened {
+			if len(keys) == cap(keys) {
+				break
+			}
+			keys = append(keys, f.Key)
+		}
+		return d.NotifyMany(ctx, userIDs, NotifyEvent, map[string]interface{}{
+			"check":    run.Check,
+			"run_id":   run.ID,
+			"opened":   len(opened),
+			"findings": run.Findings,
+			"keys":     keys,
+		})
+	}
+}
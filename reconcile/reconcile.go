@@ -0,0 +1,470 @@
+// Package reconcile verifies invariants that span services, e.g. "every billing account has a
+// user in auth". A check pages through a source, a table or another service's list endpoint,
+// and a verifier reports the discrepancies of each page as findings. Findings stay open until a
+// later run no longer sees them; runs export metrics and a hook, e.g. NotifyHook, hears about
+// new findings.
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/worker"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RunJob is the worker job that runs one check on demand, see Trigger
+const RunJob = "reconcile.run"
+
+// Errors returned by the checker
+var (
+	ErrUnknownCheck = apperror.New("reconcile_unknown_check", apperror.KindNotFound, "unknown consistency check").
+			WithKey("reconcile.unknown_check")
+	ErrRunning = apperror.New("reconcile_running", apperror.KindConflict, "the consistency check is already running").
+			WithKey("reconcile.running")
+)
+
+// Record is one item of a source: its key and the fields the verifier needs
+type Record struct {
+	Key  string
+	Data map[string]interface{}
+}
+
+// Source pages through the records an invariant is about in a stable order; after is the
+// cursor returned with the previous page, "" for the first, and an empty next cursor ends it
+type Source interface {
+	Page(ctx context.Context, after string, limit int) (page []Record, next string, err error)
+}
+
+// SourceFunc adapts a function to Source
+type SourceFunc func(ctx context.Context, after string, limit int) ([]Record, string, error)
+
+// Page implements Source
+func (f SourceFunc) Page(ctx context.Context, after string, limit int) ([]Record, string, error) {
+	return f(ctx, after, limit)
+}
+
+// Verifier returns the discrepancies among one page of records, e.g. MustExist; Check and
+// Severity of the findings are filled in, and the first and last seen times are set
+type Verifier func(ctx context.Context, page []Record) ([]Finding, error)
+
+// Check declares an invariant, e.g.
+//
+//	reconcile.Check{
+//		Name:        "billing-accounts-have-users",
+//		Description: "every billing account belongs to a user in auth",
+//		Severity:    reconcile.SeverityCritical,
+//		Source:      reconcile.Table(db, &Account{}, "id", "user_id"),
+//		Verify:      reconcile.MustExist("user_id", reconcile.EndpointLookup(client, "/api/v1/auth/users", "id", "id")),
+//	}
+type Check struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Severity is given to findings that have none, defaults to SeverityWarning
+	Severity Severity `json:"severity"`
+	// Interval is how often Schedule runs the check, defaults to Config.Interval
+	Interval time.Duration `json:"interval"`
+	Source   Source        `json:"-"`
+	Verify   Verifier      `json:"-"`
+}
+
+// Hook is told about the findings a run opened, e.g. NotifyHook; its error is only logged
+type Hook func(ctx context.Context, run *Run, opened []Finding) error
+
+// Config configures a Checker
+type Config struct {
+	DB *gorm.DB
+	// Interval is how often Schedule runs each check, defaults to 24h
+	Interval time.Duration
+	// BatchSize is the page size asked from sources, defaults to 500
+	BatchSize int
+	Hook      Hook // optional
+}
+
+// Checker runs the registered checks and keeps their findings
+type Checker struct {
+	cfg *Config
+	log *zap.Logger
+	now func() time.Time
+
+	mu      sync.RWMutex
+	checks  []Check
+	running map[string]bool
+	worker  *worker.Manager
+}
+
+type runPayload struct {
+	Check string `json:"check"`
+}
+
+// New creates a checker; declare checks with Register and migrate Finding and Run first
+func New(cfg *Config) *Checker {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 24 * time.Hour
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	return &Checker{cfg: cfg, log: logger.Module("reconcile"), now: time.Now, running: make(map[string]bool)}
+}
+
+// Register declares checks; it fails on incomplete checks or a duplicate name
+func (c *Checker) Register(checks ...Check) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, check := range checks {
+		if check.Name == "" || check.Source == nil || check.Verify == nil {
+			return fmt.Errorf("reconcile: check %q needs a name, a source and a verifier", check.Name)
+		}
+		if check.Severity == "" {
+			check.Severity = SeverityWarning
+		}
+		if check.Interval <= 0 {
+			check.Interval = c.cfg.Interval
+		}
+		for _, existing := range c.checks {
+			if existing.Name == check.Name {
+				return fmt.Errorf("reconcile: check %q is already registered", check.Name)
+			}
+		}
+		c.checks = append(c.checks, check)
+	}
+	return nil
+}
+
+// Checks returns the registered checks
+func (c *Checker) Checks() []Check {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]Check(nil), c.checks...)
+}
+
+func (c *Checker) check(name string) (Check, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, check := range c.checks {
+		if check.Name == name {
+			return check, true
+		}
+	}
+	return Check{}, false
+}
+
+// RunAll runs every check. A failing check does not stop the others; the error reports the
+// first failure.
+func (c *Checker) RunAll(ctx context.Context) ([]*Run, error) {
+	checks := c.Checks()
+	runs := make([]*Run, 0, len(checks))
+	var firstErr error
+	for _, check := range checks {
+		if ctx.Err() != nil {
+			return runs, ctx.Err()
+		}
+		run, err := c.Run(ctx, check.Name)
+		if run != nil {
+			runs = append(runs, run)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return runs, firstErr
+}
+
+// Run runs one check now and returns its run; a check runs once at a time per process
+func (c *Checker) Run(ctx context.Context, name string) (*Run, error) {
+	check, ok := c.check(name)
+	if !ok {
+		return nil, ErrUnknownCheck
+	}
+	c.mu.Lock()
+	if c.running[name] {
+		c.mu.Unlock()
+		return nil, ErrRunning
+	}
+	c.running[name] = true
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.running, name)
+		c.mu.Unlock()
+	}()
+	return c.run(ctx, check)
+}
+
+// Trigger re-runs one check: in the background through RunJob once Schedule was called,
+// otherwise before it returns, with the run as result
+func (c *Checker) Trigger(ctx context.Context, name string) (*Run, error) {
+	if _, ok := c.check(name); !ok {
+		return nil, ErrUnknownCheck
+	}
+	c.mu.RLock()
+	m := c.worker
+	c.mu.RUnlock()
+	if m == nil {
+		return c.Run(ctx, name)
+	}
+	if _, err := m.Enqueue(ctx, RunJob, runPayload{Check: name}); err != nil {
+		return nil, fmt.Errorf("failed to queue consistency check: %w", err)
+	}
+	return nil, nil
+}
+
+// Schedule runs each check every Interval on the worker manager and registers RunJob for Trigger
+func (c *Checker) Schedule(m *worker.Manager) {
+	c.mu.Lock()
+	c.worker = m
+	c.mu.Unlock()
+	m.Register(RunJob, func(ctx context.Context, task *worker.Task) error {
+		var p runPayload
+		if err := task.Decode(&p); err != nil {
+			return err
+		}
+		_, err := c.Run(ctx, p.Check)
+		return err
+	}, worker.JobOptions{Timeout: 2 * time.Hour})
+	for _, check := range c.Checks() {
+		name := check.Name
+		m.Every("reconcile."+name, check.Interval, func(ctx context.Context) error {
+			_, err := c.Run(ctx, name)
+			if errors.Is(err, ErrRunning) {
+				return nil
+			}
+			return err
+		}, worker.JobOptions{Timeout: 2 * time.Hour})
+	}
+}
+
+// run pages through the source, stores the findings and resolves those no longer seen
+func (c *Checker) run(ctx context.Context, check Check) (*Run, error) {
+	start := c.now()
+	run := &Run{Check: check.Name, Status: RunRunning, StartedAt: start}
+	if err := c.cfg.DB.WithContext(ctx).Create(run).Error; err != nil {
+		return nil, fmt.Errorf("failed to record consistency check run: %w", err)
+	}
+
+	var opened []Finding
+	after := ""
+	err := func() error {
+		for {
+			page, next, err := check.Source.Page(ctx, after, c.cfg.BatchSize)
+			if err != nil {
+				return fmt.Errorf("failed to read source: %w", err)
+			}
+			run.Scanned += len(page)
+			if len(page) > 0 {
+				findings, err := check.Verify(ctx, page)
+				if err != nil {
+					return fmt.Errorf("failed to verify records: %w", err)
+				}
+				newly, n, err := c.store(ctx, check, run, findings)
+				if err != nil {
+					return err
+				}
+				run.Findings += n
+				opened = append(opened, newly...)
+			}
+			if next == "" {
+				return nil
+			}
+			after = next
+		}
+	}()
+	if err == nil {
+		// Only a complete run knows which findings are gone
+		run.Resolved, err = c.resolve(ctx, check, run)
+	}
+
+	finished := c.now()
+	run.FinishedAt = &finished
+	run.Opened = len(opened)
+	switch {
+	case err != nil:
+		run.Status, run.Error = RunFailed, truncate(err.Error(), 1024)
+	case run.Findings > 0:
+		run.Status = RunFindings
+	default:
+		run.Status = RunOK
+	}
+	if saveErr := c.cfg.DB.WithContext(context.WithoutCancel(ctx)).Save(run).Error; saveErr != nil {
+		c.log.Error("failed to record consistency check run", zap.String("check", check.Name), zap.Error(saveErr))
+	}
+
+	runsTotal.WithLabelValues(check.Name, string(run.Status)).Inc()
+	scannedTotal.WithLabelValues(check.Name).Add(float64(run.Scanned))
+	runDuration.WithLabelValues(check.Name).Observe(finished.Sub(start).Seconds())
+	fields := []zap.Field{
+		zap.String("check", check.Name),
+		zap.Int("scanned", run.Scanned),
+		zap.Int("findings", run.Findings),
+		zap.Int("opened", run.Opened),
+		zap.Int("resolved", run.Resolved),
+	}
+	if err != nil {
+		c.log.Error("consistency check failed", append(fields, zap.Error(err))...)
+	} else {
+		openFindings.WithLabelValues(check.Name).Set(float64(run.Findings))
+		lastSuccess.WithLabelValues(check.Name).SetToCurrentTime()
+		if run.Findings > 0 {
+			c.log.Warn("consistency check found discrepancies", fields...)
+		} else {
+			c.log.Info("consistency check passed", fields...)
+		}
+	}
+
+	if len(opened) > 0 && c.cfg.Hook != nil {
+		if hookErr := c.cfg.Hook(ctx, run, opened); hookErr != nil {
+			c.log.Warn("consistency check hook failed", zap.String("check", check.Name), zap.Error(hookErr))
+		}
+	}
+	if err != nil {
+		return run, fmt.Errorf("consistency check %s failed: %w", check.Name, err)
+	}
+	return run, nil
+}
+
+// store upserts the findings of one page and returns those that were not open before and how
+// many it stored
+func (c *Checker) store(ctx context.Context, check Check, run *Run, findings []Finding) ([]Finding, int, error) {
+	// One statement cannot upsert a row twice, so a verifier repeating itself counts once
+	unique := findings[:0:0]
+	dup := make(map[string]bool, len(findings))
+	for _, f := range findings {
+		if id := f.Key + "\x00" + f.Kind; !dup[id] {
+			dup[id] = true
+			unique = append(unique, f)
+		}
+	}
+	findings = unique
+	if len(findings) == 0 {
+		return nil, 0, nil
+	}
+	keys := make([]string, 0, len(findings))
+	for _, f := range findings {
+		keys = append(keys, f.Key)
+	}
+	var open []Finding
+	if err := c.cfg.DB.WithContext(ctx).Select("key", "kind").
+		Where("\"check\" = ? AND key IN ? AND resolved_at IS NULL", check.Name, keys).
+		Find(&open).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to load findings: %w", err)
+	}
+	seen := make(map[string]bool, len(open))
+	for _, f := range open {
+		seen[f.Key+"\x00"+f.Kind] = true
+	}
+
+	now := c.now()
+	var opened []Finding
+	for i := range findings {
+		f := &findings[i]
+		f.Check, f.LastRunID, f.FirstSeenAt, f.LastSeenAt, f.ResolvedAt = check.Name, run.ID, now, now, nil
+		if f.Severity == "" {
+			f.Severity = check.Severity
+		}
+		f.Message = truncate(f.Message, 1024)
+		if !seen[f.Key+"\x00"+f.Kind] {
+			opened = append(opened, *f)
+		}
+	}
+	// A finding resolved earlier and seen again starts over
+	err := c.cfg.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "check"}, {Name: "key"}, {Name: "kind"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"severity":      clause.Column{Table: "excluded", Name: "severity"},
+			"message":       clause.Column{Table: "excluded", Name: "message"},
+			"details":       clause.Column{Table: "excluded", Name: "details"},
+			"last_seen_at":  clause.Column{Table: "excluded", Name: "last_seen_at"},
+			"last_run_id":   clause.Column{Table: "excluded", Name: "last_run_id"},
+			"first_seen_at": gorm.Expr("CASE WHEN reconcile_findings.resolved_at IS NULL THEN reconcile_findings.first_seen_at ELSE excluded.first_seen_at END"),
+			"resolved_at":   nil,
+		}),
+	}).Create(&findings).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to save findings: %w", err)
+	}
+	return opened, len(findings), nil
+}
+
+// resolve closes the open findings of a check the run did not see
+func (c *Checker) resolve(ctx context.Context, check Check, run *Run) (int, error) {
+	res := c.cfg.DB.WithContext(ctx).Model(&Finding{}).
+		Where("\"check\" = ? AND resolved_at IS NULL AND last_run_id <> ?", check.Name, run.ID).
+		Update("resolved_at", c.now())
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to resolve findings: %w", res.Error)
+	}
+	return int(res.RowsAffected), nil
+}
+
+// FindingQuery filters Findings
+type FindingQuery struct {
+	Check string `form:"check"`
+	// Open limits the list to unresolved findings
+	Open     bool     `form:"open"`
+	Severity Severity `form:"severity"`
+	Page     int      `form:"page"`
+	Limit    int      `form:"limit"`
+}
+
+func (q *FindingQuery) normalize() {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Limit < 1 || q.Limit > 100 {
+		q.Limit = 20
+	}
+}
+
+// Findings returns a page of findings, most recently seen first
+func (c *Checker) Findings(ctx context.Context, q FindingQuery) ([]Finding, int64, error) {
+	q.normalize()
+	tx := c.cfg.DB.WithContext(ctx).Model(&Finding{})
+	if q.Check != "" {
+		tx = tx.Where("\"check\" = ?", q.Check)
+	}
+	if q.Open {
+		tx = tx.Where("resolved_at IS NULL")
+	}
+	if q.Severity != "" {
+		tx = tx.Where("severity = ?", q.Severity)
+	}
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count findings: %w", err)
+	}
+	var items []Finding
+	if err := tx.Order("last_seen_at DESC, id DESC").Offset((q.Page - 1) * q.Limit).Limit(q.Limit).Find(&items).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list findings: %w", err)
+	}
+	return items, total, nil
+}
+
+// Runs returns the latest runs of a check, newest first
+func (c *Checker) Runs(ctx context.Context, name string, limit int) ([]Run, error) {
+	if _, ok := c.check(name); !ok {
+		return nil, ErrUnknownCheck
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	var runs []Run
+	if err := c.cfg.DB.WithContext(ctx).Where("\"check\" = ?", name).Order("id DESC").Limit(limit).Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list consistency check runs: %w", err)
+	}
+	return runs, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,190 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/Masharah-Advisory/common/dto"
+	"github.com/Masharah-Advisory/common/httpclient"
+	"gorm.io/gorm"
+)
+
+// TableSource pages through a table in key order, see Table
+type TableSource struct {
+	db      *gorm.DB
+	model   interface{}
+	key     string
+	columns []string
+	where   []func(*gorm.DB) *gorm.DB
+}
+
+// Table reads a table of this service, keyset-paginated on the key column; columns are the
+// fields records carry besides the key, all of them when omitted, e.g.
+//
+//	reconcile.Table(db, &Account{}, "id", "user_id").Where("deleted_at IS NULL")
+func Table(db *gorm.DB, model interface{}, key string, columns ...string) *TableSource {
+	return &TableSource{db: db, model: model, key: key, columns: columns}
+}
+
+// Where limits the rows read
+func (s *TableSource) Where(query interface{}, args ...interface{}) *TableSource {
+	s.where = append(s.where, func(tx *gorm.DB) *gorm.DB { return tx.Where(query, args...) })
+	return s
+}
+
+// Page implements Source
+func (s *TableSource) Page(ctx context.Context, after string, limit int) ([]Record, string, error) {
+	tx := s.db.WithContext(ctx).Model(s.model)
+	if len(s.columns) > 0 {
+		tx = tx.Select(append([]string{s.key}, s.columns...))
+	}
+	for _, where := range s.where {
+		tx = where(tx)
+	}
+	if after != "" {
+		// Numeric keys compare as numbers so "10" follows "9"
+		var cursor interface{} = after
+		if n, err := strconv.ParseInt(after, 10, 64); err == nil {
+			cursor = n
+		}
+		tx = tx.Where(fmt.Sprintf("%s > ?", s.key), cursor)
+	}
+	var rows []map[string]interface{}
+	if err := tx.Order(s.key).Limit(limit).Find(&rows).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to page %s: %w", s.key, err)
+	}
+	page := make([]Record, 0, len(rows))
+	for _, row := range rows {
+		page = append(page, Record{Key: keyString(row[s.key]), Data: row})
+	}
+	if len(rows) < limit {
+		return page, "", nil
+	}
+	return page, page[len(page)-1].Key, nil
+}
+
+// Endpoint reads a paginated list endpoint of another service, one that answers with
+// dto.BuildPaginatedResponse; key is the item field identifying records
+func Endpoint(client httpclient.ServiceCaller, route, key string, opts ...httpclient.RequestOption) Source {
+	return SourceFunc(func(ctx context.Context, after string, limit int) ([]Record, string, error) {
+		page := 1
+		if after != "" {
+			n, err := strconv.Atoi(after)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid page cursor %q", after)
+			}
+			page = n
+		}
+		res, err := httpclient.GetAs[dto.PaginatedResponse[map[string]interface{}]](ctx, client, route,
+			append(opts, httpclient.WithQuery("page", page), httpclient.WithQuery("limit", limit))...)
+		if err != nil {
+			return nil, "", err
+		}
+		records := make([]Record, 0, len(res.Items))
+		for _, item := range res.Items {
+			records = append(records, Record{Key: keyString(item[key]), Data: item})
+		}
+		if !res.HasNext {
+			return records, "", nil
+		}
+		return records, strconv.Itoa(page + 1), nil
+	})
+}
+
+// Lookup reports which of the keys exist, e.g. in another service
+type Lookup func(ctx context.Context, keys []string) (map[string]bool, error)
+
+// MustExist reports a "missing" finding for each record whose field names a key the lookup
+// does not find; records with an empty field are skipped
+func MustExist(field string, lookup Lookup) Verifier {
+	return func(ctx context.Context, page []Record) ([]Finding, error) {
+		var keys []string
+		seen := make(map[string]bool)
+		for _, r := range page {
+			ref := keyString(r.Data[field])
+			if ref != "" && !seen[ref] {
+				seen[ref] = true
+				keys = append(keys, ref)
+			}
+		}
+		if len(keys) == 0 {
+			return nil, nil
+		}
+		found, err := lookup(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+		var findings []Finding
+		for _, r := range page {
+			ref := keyString(r.Data[field])
+			if ref == "" || found[ref] {
+				continue
+			}
+			findings = append(findings, Finding{
+				Key:     r.Key,
+				Kind:    "missing",
+				Message: fmt.Sprintf("%s %s does not exist", field, ref),
+				Details: map[string]interface{}{field: ref},
+			})
+		}
+		return findings, nil
+	}
+}
+
+// TableLookup finds keys in a column of a table of this service
+func TableLookup(db *gorm.DB, model interface{}, column string) Lookup {
+	return func(ctx context.Context, keys []string) (map[string]bool, error) {
+		var values []interface{}
+		if err := db.WithContext(ctx).Model(model).Where(fmt.Sprintf("%s IN ?", column), keys).
+			Pluck(column, &values).Error; err != nil {
+			return nil, fmt.Errorf("failed to look up %s: %w", column, err)
+		}
+		found := make(map[string]bool, len(values))
+		for _, v := range values {
+			found[keyString(v)] = true
+		}
+		return found, nil
+	}
+}
+
+// EndpointLookup finds keys through a list endpoint of another service that filters on a
+// repeated query parameter, e.g. GET /api/v1/auth/users?id=1&id=2; key is the item field
+// holding the key
+func EndpointLookup(client httpclient.ServiceCaller, route, param, key string, opts ...httpclient.RequestOption) Lookup {
+	return func(ctx context.Context, keys []string) (map[string]bool, error) {
+		values := make([]interface{}, len(keys))
+		for i, k := range keys {
+			values[i] = k
+		}
+		res, err := httpclient.GetAs[dto.PaginatedResponse[map[string]interface{}]](ctx, client, route,
+			append(opts, httpclient.WithQuery(param, values...), httpclient.WithQuery("limit", len(keys)))...)
+		if err != nil {
+			return nil, err
+		}
+		found := make(map[string]bool, len(res.Items))
+		for _, item := range res.Items {
+			found[keyString(item[key])] = true
+		}
+		return found, nil
+	}
+}
+
+// keyString formats a key the same whether it came from a row or from JSON, where numbers
+// decode as float64
+func keyString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -2,8 +2,13 @@ package redis
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"time"
 
+	"github.com/Masharah-Advisory/common/config"
+	"github.com/Masharah-Advisory/common/retry"
+	"github.com/Masharah-Advisory/common/telemetry"
 	"github.com/go-redis/redis/v8"
 )
 
@@ -11,6 +16,9 @@ type Config struct {
 	RedisAddr string
 	RedisPass string
 	RedisDB   int
+	// Retry controls the connection check at startup, defaults to 3 attempts; the client
+	// reconnects on its own afterwards
+	Retry *retry.Policy
 }
 
 func NewClient(cfg *Config) *redis.Client {
@@ -20,9 +28,20 @@ func NewClient(cfg *Config) *redis.Client {
 		DB:       cfg.RedisDB,
 	})
 
+	rdb.AddHook(telemetry.RedisHook{})
+	config.RegisterValidator("redis", Validator(rdb))
+
 	// Test the connection
-	ctx := context.Background()
-	_, err := rdb.Ping(ctx).Result()
+	policy := retry.Policy{MaxAttempts: 3, InitialBackoff: 500 * time.Millisecond, MaxBackoff: 5 * time.Second, Jitter: 0.2}
+	if cfg.Retry != nil {
+		policy = *cfg.Retry
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = func(err error) bool { return true }
+	}
+	err := retry.Do(context.Background(), policy, func(ctx context.Context) error {
+		return rdb.Ping(ctx).Err()
+	})
 	if err != nil {
 		log.Printf("Warning: Failed to connect to Redis: %v", err)
 		return rdb // Return client anyway, as Redis might not be critical for basic functionality
@@ -31,3 +50,13 @@ func NewClient(cfg *Config) *redis.Client {
 	log.Println("Redis connected successfully")
 	return rdb
 }
+
+// Validator returns a config validator that checks Redis is reachable
+func Validator(rdb *redis.Client) config.ValidatorFunc {
+	return func(ctx context.Context) error {
+		if err := rdb.Ping(ctx).Err(); err != nil {
+			return fmt.Errorf("redis unreachable: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,60 @@
+package response
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FileOptions control how File serves a download
+type FileOptions struct {
+	// Inline lets the browser display the file (e.g. a PDF preview) instead of downloading it
+	Inline bool
+	// Size is the length in bytes when known; 0 streams without Content-Length
+	Size int64
+}
+
+// File streams body as a file download. Non-ASCII filenames (Arabic statement names) are
+// encoded per RFC 6266 so every browser keeps them.
+func File(c *gin.Context, filename, contentType string, body io.Reader, opts ...FileOptions) {
+	var opt FileOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	disposition := "attachment"
+	if opt.Inline {
+		disposition = "inline"
+	}
+	if filename != "" {
+		if v := mime.FormatMediaType(disposition, map[string]string{"filename": filename}); v != "" {
+			disposition = v
+		}
+	}
+
+	c.Header("Content-Disposition", disposition)
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, no-store")
+	if opt.Size > 0 {
+		c.Header("Content-Length", strconv.FormatInt(opt.Size, 10))
+	}
+	c.Header("Content-Type", contentType)
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, body); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// FileBytes serves an in-memory file with File
+func FileBytes(c *gin.Context, filename, contentType string, data []byte, opts ...FileOptions) {
+	var opt FileOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	opt.Size = int64(len(data))
+	File(c, filename, contentType, bytes.NewReader(data), opt)
+}
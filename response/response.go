@@ -5,6 +5,9 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/buildinfo"
+	"github.com/Masharah-Advisory/common/config"
 	"github.com/Masharah-Advisory/common/i18n"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -22,6 +25,32 @@ type ApiResponse[T any] struct {
 	Data    *T          `json:"data,omitempty"`
 	Errors  []ErrorItem `json:"errors,omitempty"`
 	Message string      `json:"message"`
+	Code    string      `json:"code,omitempty"`
+	Meta    *Meta       `json:"meta,omitempty"`
+}
+
+// Meta carries non-payload information about the response
+type Meta struct {
+	Version string `json:"version,omitempty"`
+	// Experiments maps experiment keys to the variants the request was assigned
+	Experiments map[string]string `json:"experiments,omitempty"`
+}
+
+// ExperimentsKey is the gin key holding the map[string]string of experiment assignments
+// reported in Meta; the experiments middleware sets it
+const ExperimentsKey = "experiment_assignments"
+
+// newMeta builds the meta block attached to every response
+func newMeta(c *gin.Context) *Meta {
+	meta := &Meta{
+		Version: buildinfo.Version,
+	}
+	if v, ok := c.Get(ExperimentsKey); ok {
+		if assignments, ok := v.(map[string]string); ok && len(assignments) > 0 {
+			meta.Experiments = assignments
+		}
+	}
+	return meta
 }
 
 // Helper function to create pointer from string
@@ -122,6 +151,7 @@ func OK[T any](c *gin.Context, data T, message ...string) {
 	}
 	c.JSON(http.StatusOK, ApiResponse[T]{
 		Success: true,
+		Meta:    newMeta(c),
 		Data:    &data,
 		Message: msg,
 	})
@@ -135,6 +165,7 @@ func OKMessage(c *gin.Context, message ...string) {
 	}
 	c.JSON(http.StatusOK, ApiResponse[any]{
 		Success: true,
+		Meta:    newMeta(c),
 		Message: msg,
 	})
 }
@@ -146,6 +177,7 @@ func Accepted[T any](c *gin.Context, data T, message ...string) {
 	}
 	c.JSON(http.StatusAccepted, ApiResponse[T]{
 		Success: true,
+		Meta:    newMeta(c),
 		Data:    &data,
 		Message: msg,
 	})
@@ -159,6 +191,7 @@ func Created[T any](c *gin.Context, data T, message ...string) {
 	}
 	c.JSON(http.StatusCreated, ApiResponse[T]{
 		Success: true,
+		Meta:    newMeta(c),
 		Data:    &data,
 		Message: msg,
 	})
@@ -172,6 +205,7 @@ func NoContent(c *gin.Context, message ...string) {
 	}
 	c.JSON(http.StatusNoContent, ApiResponse[any]{
 		Success: true,
+		Meta:    newMeta(c),
 		Message: msg,
 	})
 }
@@ -182,6 +216,7 @@ func NoContent(c *gin.Context, message ...string) {
 func BadRequest(c *gin.Context, message string, errors ...[]ErrorItem) {
 	response := ApiResponse[any]{
 		Success: false,
+		Meta:    newMeta(c),
 		Message: message,
 	}
 	if len(errors) > 0 {
@@ -198,6 +233,7 @@ func Unauthorized(c *gin.Context, message ...string) {
 	}
 	c.JSON(http.StatusUnauthorized, ApiResponse[any]{
 		Success: false,
+		Meta:    newMeta(c),
 		Message: msg,
 	})
 }
@@ -210,6 +246,7 @@ func Forbidden(c *gin.Context, message ...string) {
 	}
 	c.JSON(http.StatusForbidden, ApiResponse[any]{
 		Success: false,
+		Meta:    newMeta(c),
 		Message: msg,
 	})
 }
@@ -222,6 +259,7 @@ func NotFound(c *gin.Context, message ...string) {
 	}
 	c.JSON(http.StatusNotFound, ApiResponse[any]{
 		Success: false,
+		Meta:    newMeta(c),
 		Message: msg,
 	})
 }
@@ -230,6 +268,7 @@ func NotFound(c *gin.Context, message ...string) {
 func Conflict(c *gin.Context, message string, errors ...[]ErrorItem) {
 	response := ApiResponse[any]{
 		Success: false,
+		Meta:    newMeta(c),
 		Message: message,
 	}
 	if len(errors) > 0 {
@@ -242,6 +281,7 @@ func Conflict(c *gin.Context, message string, errors ...[]ErrorItem) {
 func ValidationFailed(c *gin.Context, message string, errors ...[]ErrorItem) {
 	response := ApiResponse[any]{
 		Success: false,
+		Meta:    newMeta(c),
 		Message: message,
 	}
 	if len(errors) > 0 {
@@ -258,16 +298,80 @@ func InternalError(c *gin.Context, message ...string) {
 	}
 	c.JSON(http.StatusInternalServerError, ApiResponse[any]{
 		Success: false,
+		Meta:    newMeta(c),
 		Message: msg,
 	})
 }
 
+// InternalErrorWithCause sends a 500 response; the underlying error is only exposed
+// in the errors list outside production
+func InternalErrorWithCause(c *gin.Context, err error, message ...string) {
+	msg := "Internal server error"
+	if len(message) > 0 {
+		msg = message[0]
+	}
+	response := ApiResponse[any]{
+		Success: false,
+		Message: msg,
+		Meta:    newMeta(c),
+	}
+	if err != nil && !config.IsProduction() {
+		response.Errors = Err("cause", err.Error())
+	}
+	c.JSON(http.StatusInternalServerError, response)
+}
+
+// HandleError maps any error to the standard error response: application errors use their kind
+// for the status and their i18n key (or message), binding errors become 422, anything else is a 500
+func HandleError(c *gin.Context, err error) {
+	if err == nil {
+		return
+	}
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		ValidationFailed(c, i18n.T(c, "error.validation"), ValidationErrors(c, validationErrors))
+		return
+	}
+
+	appErr, ok := apperror.As(err)
+	if !ok {
+		_ = c.Error(err)
+		InternalErrorWithCause(c, err, i18n.T(c, "error.internal"))
+		return
+	}
+
+	message := appErr.Message
+	switch {
+	case appErr.MessageKey != "":
+		message = i18n.T(c, appErr.MessageKey, appErr.Meta)
+	case message == "" || appErr.Kind == apperror.KindInternal:
+		// Internal messages may leak implementation details; use the generic localized text
+		message = i18n.T(c, "error."+string(appErr.Kind))
+	}
+
+	response := ApiResponse[any]{
+		Success: false,
+		Message: message,
+		Code:    appErr.Code,
+		Meta:    newMeta(c),
+	}
+	if appErr.Kind == apperror.KindInternal {
+		_ = c.Error(err)
+		if appErr.Err != nil && !config.IsProduction() {
+			response.Errors = Err("cause", appErr.Err.Error())
+		}
+	}
+	c.JSON(appErr.HTTPStatus(), response)
+}
+
 // Advanced functions for custom use cases
 
 // Success sends a custom success response
 func Success[T any](c *gin.Context, statusCode int, data T, message string) {
 	c.JSON(statusCode, ApiResponse[T]{
 		Success: true,
+		Meta:    newMeta(c),
 		Data:    &data,
 		Message: message,
 	})
@@ -277,6 +381,7 @@ func Success[T any](c *gin.Context, statusCode int, data T, message string) {
 func Error(c *gin.Context, statusCode int, message string, errors ...[]ErrorItem) {
 	response := ApiResponse[any]{
 		Success: false,
+		Meta:    newMeta(c),
 		Message: message,
 	}
 	if len(errors) > 0 {
@@ -289,6 +394,7 @@ func Error(c *gin.Context, statusCode int, message string, errors ...[]ErrorItem
 func JSON[T any](c *gin.Context, statusCode int, success bool, data *T, message string, errors []ErrorItem) {
 	c.JSON(statusCode, ApiResponse[T]{
 		Success: success,
+		Meta:    newMeta(c),
 		Data:    data,
 		Message: message,
 		Errors:  errors,
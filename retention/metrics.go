@@ -0,0 +1,29 @@
+package retention
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Rows archived or purged, rows still due, failures and the last run, by retention policy
+var (
+	rowsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "retention_rows_total",
+		Help: "Rows deleted or anonymized by retention policies.",
+	}, []string{"policy", "action"})
+
+	pendingRows = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "retention_pending_rows",
+		Help: "Rows a dry run found past their retention period.",
+	}, []string{"policy"})
+
+	errorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "retention_errors_total",
+		Help: "Failed retention policy runs.",
+	}, []string{"policy"})
+
+	lastRun = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "retention_last_run_timestamp_seconds",
+		Help: "Unix time of the last successful run of a retention policy.",
+	}, []string{"policy"})
+)
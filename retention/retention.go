@@ -0,0 +1,261 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/worker"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Job is the worker job registered by Schedule
+const Job = "retention.purge"
+
+// Action is what happens to rows past their retention period
+type Action string
+
+const (
+	// Delete removes the rows permanently, soft-deleted rows included
+	Delete Action = "delete"
+	// Anonymize overwrites Updates and stamps Marker, keeping the rows for reporting
+	Anonymize Action = "anonymize"
+)
+
+// Policy declares how long the rows of a model are kept, e.g.
+//
+//	retention.Policy{Name: "audit", Model: &audit.Entry{}, Keep: 2 * 365 * 24 * time.Hour}
+//	retention.Policy{Name: "closed-cases", Model: &Case{}, Action: retention.Anonymize,
+//		Column: "closed_at", Where: "status = ?", Args: []interface{}{"closed"},
+//		Keep: 5 * 365 * 24 * time.Hour, Updates: map[string]interface{}{"client_name": "[redacted]"}}
+type Policy struct {
+	Name   string
+	Model  interface{}
+	Action Action // defaults to Delete
+	Keep   time.Duration
+	// Column is the timestamp compared with the cutoff, defaults to "created_at"
+	Column string
+	// Where and Args further restrict the rows, e.g. to closed cases
+	Where string
+	Args  []interface{}
+	// Key is the primary key column used to batch, defaults to "id"
+	Key string
+	// Updates are the column values written by Anonymize
+	Updates map[string]interface{}
+	// Marker is the timestamp column Anonymize stamps so rows are processed once, defaults to
+	// "anonymized_at"
+	Marker string
+}
+
+// Config configures a Purger
+type Config struct {
+	DB *gorm.DB
+	// BatchSize bounds each statement so retention never holds long locks, defaults to 5000
+	BatchSize int
+	// DryRun only counts the rows each policy would change
+	DryRun bool
+	// Interval is how often Schedule runs the policies, defaults to 24h
+	Interval time.Duration
+}
+
+// Result is the outcome of one policy
+type Result struct {
+	Policy string        `json:"policy"`
+	Action Action        `json:"action"`
+	Cutoff time.Time     `json:"cutoff"`
+	Rows   int64         `json:"rows"`
+	DryRun bool          `json:"dry_run,omitempty"`
+	Error  string        `json:"error,omitempty"`
+	Took   time.Duration `json:"took"`
+}
+
+// Purger runs the registered retention policies
+type Purger struct {
+	cfg *Config
+	log *zap.Logger
+	now func() time.Time
+
+	mu       sync.RWMutex
+	policies []Policy
+}
+
+// New creates a purger; declare policies with Register
+func New(cfg *Config) *Purger {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 5000
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 24 * time.Hour
+	}
+	return &Purger{cfg: cfg, log: logger.Module("retention"), now: time.Now}
+}
+
+// Register declares policies; it fails on incomplete policies or a duplicate name
+func (p *Purger) Register(policies ...Policy) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, policy := range policies {
+		if policy.Name == "" || policy.Model == nil || policy.Keep <= 0 {
+			return fmt.Errorf("retention: policy %q needs a name, a model and a positive keep", policy.Name)
+		}
+		if policy.Action == "" {
+			policy.Action = Delete
+		}
+		switch policy.Action {
+		case Delete:
+		case Anonymize:
+			if len(policy.Updates) == 0 {
+				return fmt.Errorf("retention: anonymize policy %q has no updates", policy.Name)
+			}
+		default:
+			return fmt.Errorf("retention: policy %q has unknown action %q", policy.Name, policy.Action)
+		}
+		if policy.Column == "" {
+			policy.Column = "created_at"
+		}
+		if policy.Key == "" {
+			policy.Key = "id"
+		}
+		if policy.Marker == "" {
+			policy.Marker = "anonymized_at"
+		}
+		for _, existing := range p.policies {
+			if existing.Name == policy.Name {
+				return fmt.Errorf("retention: policy %q is already registered", policy.Name)
+			}
+		}
+		p.policies = append(p.policies, policy)
+	}
+	return nil
+}
+
+// Policies returns the registered policies
+func (p *Purger) Policies() []Policy {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]Policy(nil), p.policies...)
+}
+
+// Run applies every policy, or counts the affected rows in dry-run mode. A failing policy does
+// not stop the others; the error reports the first failure.
+func (p *Purger) Run(ctx context.Context) ([]Result, error) {
+	return p.run(ctx, p.cfg.DryRun)
+}
+
+// DryRun counts the rows every policy would change without changing them
+func (p *Purger) DryRun(ctx context.Context) ([]Result, error) {
+	return p.run(ctx, true)
+}
+
+func (p *Purger) run(ctx context.Context, dryRun bool) ([]Result, error) {
+	policies := p.Policies()
+	results := make([]Result, 0, len(policies))
+	var firstErr error
+	for _, policy := range policies {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		result := p.apply(ctx, policy, dryRun)
+		results = append(results, result)
+		if result.Error != "" && firstErr == nil {
+			firstErr = fmt.Errorf("retention policy %s failed: %s", policy.Name, result.Error)
+		}
+	}
+	return results, firstErr
+}
+
+// apply runs one policy and records its metrics
+func (p *Purger) apply(ctx context.Context, policy Policy, dryRun bool) Result {
+	start := p.now()
+	result := Result{Policy: policy.Name, Action: policy.Action, Cutoff: start.UTC().Add(-policy.Keep), DryRun: dryRun}
+
+	var err error
+	if dryRun {
+		result.Rows, err = p.count(ctx, policy, result.Cutoff)
+	} else {
+		result.Rows, err = p.purge(ctx, policy, result.Cutoff)
+	}
+	result.Took = time.Since(start)
+
+	fields := []zap.Field{
+		zap.String("policy", policy.Name),
+		zap.String("action", string(policy.Action)),
+		zap.Int64("rows", result.Rows),
+		zap.Bool("dry_run", dryRun),
+	}
+	switch {
+	case err != nil:
+		result.Error = err.Error()
+		errorsTotal.WithLabelValues(policy.Name).Inc()
+		p.log.Error("retention policy failed", append(fields, zap.Error(err))...)
+	case dryRun:
+		pendingRows.WithLabelValues(policy.Name).Set(float64(result.Rows))
+		p.log.Info("retention dry run", fields...)
+	default:
+		rowsTotal.WithLabelValues(policy.Name, string(policy.Action)).Add(float64(result.Rows))
+		lastRun.WithLabelValues(policy.Name).SetToCurrentTime()
+		if result.Rows > 0 {
+			p.log.Info("retention policy applied", fields...)
+		}
+	}
+	return result
+}
+
+// scope selects the rows of a policy past the cutoff
+func (p *Purger) scope(ctx context.Context, policy Policy, cutoff time.Time) *gorm.DB {
+	tx := p.cfg.DB.WithContext(ctx).Unscoped().Model(policy.Model).Where(policy.Column+" < ?", cutoff)
+	if policy.Where != "" {
+		tx = tx.Where(policy.Where, policy.Args...)
+	}
+	if policy.Action == Anonymize {
+		tx = tx.Where(policy.Marker + " IS NULL")
+	}
+	return tx
+}
+
+func (p *Purger) count(ctx context.Context, policy Policy, cutoff time.Time) (int64, error) {
+	var n int64
+	if err := p.scope(ctx, policy, cutoff).Count(&n).Error; err != nil {
+		return 0, fmt.Errorf("failed to count rows: %w", err)
+	}
+	return n, nil
+}
+
+// purge changes the rows in batches of primary keys until none are left
+func (p *Purger) purge(ctx context.Context, policy Policy, cutoff time.Time) (int64, error) {
+	var total int64
+	for {
+		batch := p.scope(ctx, policy, cutoff).Select(policy.Key).Limit(p.cfg.BatchSize)
+		tx := p.cfg.DB.WithContext(ctx).Unscoped().Model(policy.Model).Where(policy.Key+" IN (?)", batch)
+
+		var res *gorm.DB
+		if policy.Action == Delete {
+			res = tx.Delete(policy.Model)
+		} else {
+			updates := make(map[string]interface{}, len(policy.Updates)+1)
+			for k, v := range policy.Updates {
+				updates[k] = v
+			}
+			updates[policy.Marker] = p.now().UTC()
+			res = tx.UpdateColumns(updates)
+		}
+		if res.Error != nil {
+			return total, fmt.Errorf("failed to %s rows: %w", policy.Action, res.Error)
+		}
+		total += res.RowsAffected
+		if res.RowsAffected < int64(p.cfg.BatchSize) || ctx.Err() != nil {
+			return total, nil
+		}
+	}
+}
+
+// Schedule runs the policies every Config.Interval on the worker manager
+func (p *Purger) Schedule(m *worker.Manager) {
+	m.Every(Job, p.cfg.Interval, func(ctx context.Context) error {
+		_, err := p.Run(ctx)
+		return err
+	}, worker.JobOptions{Timeout: time.Hour})
+}
@@ -0,0 +1,187 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"go.uber.org/zap"
+)
+
+// Policy controls how Do retries; zero fields get defaults except Jitter, which is used as-is
+type Policy struct {
+	MaxAttempts    int           // total attempts including the first, defaults to 3
+	InitialBackoff time.Duration // wait before the first retry, defaults to 100ms
+	MaxBackoff     time.Duration // cap on a single wait, defaults to 30s
+	Multiplier     float64       // backoff growth per retry, defaults to 2
+	Jitter         float64       // randomises each wait by ±Jitter (0.2 = ±20%)
+	MaxElapsed     time.Duration // stop retrying once this much time has passed, 0 means no limit
+
+	// Retryable classifies errors, defaults to IsRetryable
+	Retryable func(err error) bool
+	// OnRetry is called before waiting for the next attempt, for logging and metrics
+	OnRetry func(attempt int, err error, wait time.Duration)
+	// OnDone is called once with the number of attempts made and the final error
+	OnDone func(attempts int, err error)
+}
+
+// Default returns the policy used for calls to other services: 3 attempts, 100ms doubling, ±20% jitter
+func Default() Policy {
+	return Policy{MaxAttempts: 3, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 30 * time.Second, Multiplier: 2, Jitter: 0.2}
+}
+
+func (p Policy) withDefaults() Policy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = 100 * time.Millisecond
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 30 * time.Second
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = 2
+	}
+	if p.Jitter < 0 {
+		p.Jitter = 0
+	}
+	if p.Jitter > 1 {
+		p.Jitter = 1
+	}
+	if p.Retryable == nil {
+		p.Retryable = IsRetryable
+	}
+	return p
+}
+
+// Backoff returns the wait after the given failed attempt (1-based), including jitter
+func (p Policy) Backoff(attempt int) time.Duration {
+	p = p.withDefaults()
+	wait := float64(p.InitialBackoff)
+	for i := 1; i < attempt && wait < float64(p.MaxBackoff); i++ {
+		wait *= p.Multiplier
+	}
+	if wait > float64(p.MaxBackoff) {
+		wait = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		wait += wait * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(wait)
+}
+
+// Do calls fn until it succeeds, returns a non-retryable error, the attempts or elapsed time
+// run out, or ctx is done. It returns the last error from fn, or ctx.Err() when cancelled
+// while waiting; a wait that would outlast the ctx deadline is not started.
+func Do(ctx context.Context, p Policy, fn func(ctx context.Context) error) error {
+	p = p.withDefaults()
+	start := time.Now()
+
+	var err error
+	attempt := 0
+	for {
+		attempt++
+		err = fn(ctx)
+		if err == nil || attempt >= p.MaxAttempts || !p.Retryable(err) || ctx.Err() != nil {
+			break
+		}
+
+		wait := p.Backoff(attempt)
+		if p.MaxElapsed > 0 && time.Since(start)+wait > p.MaxElapsed {
+			break
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			break
+		}
+		if p.OnRetry != nil {
+			p.OnRetry(attempt, err, wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			err = ctx.Err()
+			if p.OnDone != nil {
+				p.OnDone(attempt, err)
+			}
+			return err
+		case <-timer.C:
+		}
+	}
+
+	if p.OnDone != nil {
+		p.OnDone(attempt, err)
+	}
+	return err
+}
+
+// DoValue is Do for functions that return a value
+func DoValue[T any](ctx context.Context, p Policy, fn func(ctx context.Context) (T, error)) (T, error) {
+	var result T
+	err := Do(ctx, p, func(ctx context.Context) error {
+		v, err := fn(ctx)
+		if err == nil {
+			result = v
+		}
+		return err
+	})
+	return result, err
+}
+
+// Log returns an OnRetry hook that logs each retry of op as a warning
+func Log(log *zap.Logger, op string) func(attempt int, err error, wait time.Duration) {
+	return func(attempt int, err error, wait time.Duration) {
+		log.Warn("operation failed, retrying",
+			zap.String("operation", op),
+			zap.Int("attempt", attempt),
+			zap.Duration("wait", wait),
+			zap.Error(err),
+		)
+	}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// IsRetryable is the default classification: permanent errors, cancellations and client-side
+// application errors (validation, not found, forbidden, ...) are final; everything else,
+// including network errors and 5xx/429 responses, is retried
+func IsRetryable(err error) bool {
+	if err == nil || IsPermanent(err) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if e, ok := apperror.As(err); ok {
+		switch e.Kind {
+		case apperror.KindUnavailable, apperror.KindTimeout, apperror.KindRateLimited, apperror.KindInternal:
+			return true
+		default:
+			return false
+		}
+	}
+	return true
+}
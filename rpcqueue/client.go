@@ -0,0 +1,173 @@
+package rpcqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Masharah-Advisory/common/events"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ClientConfig configures a Client
+type ClientConfig struct {
+	// Publisher sends requests, e.g. events.NewPublisher(cfg)
+	Publisher events.Publisher
+	// Redis receives the replies
+	Redis *redis.Client
+	// Timeout bounds a call unless the context has an earlier deadline, defaults to 30s
+	Timeout time.Duration
+}
+
+// Client sends requests and awaits their replies. Run must be running for calls to complete.
+type Client struct {
+	cfg     ClientConfig
+	replyTo string
+	ready   chan struct{}
+	once    sync.Once
+	log     *zap.Logger
+
+	mu      sync.Mutex
+	pending map[string]chan *reply
+}
+
+// NewClient creates a client with its own reply channel
+func NewClient(cfg ClientConfig) *Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	return &Client{
+		cfg:     cfg,
+		replyTo: replyPrefix + uuid.NewString(),
+		ready:   make(chan struct{}),
+		log:     logger.Module("rpcqueue"),
+		pending: make(map[string]chan *reply),
+	}
+}
+
+// Run receives replies until ctx is done
+func (c *Client) Run(ctx context.Context) error {
+	sub := c.cfg.Redis.Subscribe(ctx, c.replyTo)
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", c.replyTo, err)
+	}
+	c.once.Do(func() { close(c.ready) })
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case m, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			var r reply
+			if err := json.Unmarshal([]byte(m.Payload), &r); err != nil {
+				c.log.Warn("invalid rpc reply", zap.Error(err))
+				continue
+			}
+			c.mu.Lock()
+			wait, ok := c.pending[r.CorrelationID]
+			delete(c.pending, r.CorrelationID)
+			c.mu.Unlock()
+			if !ok {
+				// the caller gave up already
+				droppedReplies.WithLabelValues("late").Inc()
+				continue
+			}
+			wait <- &r
+		}
+	}
+}
+
+// Call publishes a request for method on topic and decodes the reply into resp (nil discards
+// it). Errors returned by the remote handler come back as application errors; ErrTimeout is
+// returned when no reply arrived in time.
+func (c *Client) Call(ctx context.Context, topic, method string, req, resp interface{}) error {
+	start := time.Now()
+	err := c.call(ctx, topic, method, req, resp)
+
+	result := "ok"
+	switch {
+	case errors.Is(err, ErrTimeout):
+		result = "timeout"
+	case err != nil:
+		result = "error"
+	}
+	callsTotal.WithLabelValues(method, result).Inc()
+	callDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (c *Client) call(ctx context.Context, topic, method string, req, resp interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+	select {
+	case <-c.ready:
+	case <-ctx.Done():
+		return c.done(ctx)
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s request: %w", method, err)
+	}
+	deadline, _ := ctx.Deadline()
+	event, err := events.NewEvent(ctx, method, request{ReplyTo: c.replyTo, Deadline: deadline, Body: body})
+	if err != nil {
+		return err
+	}
+
+	wait := make(chan *reply, 1)
+	c.mu.Lock()
+	c.pending[event.ID] = wait
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, event.ID)
+		c.mu.Unlock()
+	}()
+
+	if err := c.cfg.Publisher.Publish(ctx, topic, event); err != nil {
+		return fmt.Errorf("failed to publish %s request: %w", method, err)
+	}
+
+	select {
+	case r := <-wait:
+		if r.Error != nil {
+			return r.Error.err()
+		}
+		if resp == nil || len(r.Body) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(r.Body, resp); err != nil {
+			return fmt.Errorf("failed to decode %s reply: %w", method, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return c.done(ctx)
+	}
+}
+
+// done maps the end of the call context to ErrTimeout, keeping cancellations as they are
+func (c *Client) done(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+	return ctx.Err()
+}
+
+// Invoke is Call returning the decoded reply
+func Invoke[R any](ctx context.Context, c *Client, topic, method string, req interface{}) (R, error) {
+	var resp R
+	err := c.Call(ctx, topic, method, req, &resp)
+	return resp, err
+}
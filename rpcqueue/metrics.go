@@ -0,0 +1,31 @@
+package rpcqueue
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Calls made over the queue with their outcome and latency, dropped replies and handled
+// requests, by method
+var (
+	callsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rpcqueue_calls_total",
+		Help: "Calls made over the queue by method and result (ok, error, timeout).",
+	}, []string{"method", "result"})
+
+	callDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rpcqueue_call_duration_seconds",
+		Help:    "Time from publishing a request to receiving its reply.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+
+	droppedReplies = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rpcqueue_replies_dropped_total",
+		Help: "Replies received without a waiting caller, by reason (late).",
+	}, []string{"reason"})
+
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rpcqueue_requests_handled_total",
+		Help: "Requests handled by method and result (ok, error, expired).",
+	}, []string{"method", "result"})
+)
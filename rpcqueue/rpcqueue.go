@@ -0,0 +1,68 @@
+// Package rpcqueue implements request/response over the events layer: requests are published
+// to a topic the responding service consumes at its own pace, and replies come back over Redis
+// pub/sub to the instance waiting for them. Use it instead of synchronous HTTP when a busy
+// downstream service must not stall or fail its callers.
+package rpcqueue
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+)
+
+// replyPrefix prefixes the Redis channel each client instance receives its replies on
+const replyPrefix = "rpcqueue:reply:"
+
+var (
+	// ErrTimeout is returned by Call when no reply arrived before the deadline
+	ErrTimeout = apperror.New("rpc_timeout", apperror.KindTimeout, "no reply before the deadline").
+			WithKey("rpc.timeout")
+	// ErrUnknownMethod is replied when the server has no handler for the method
+	ErrUnknownMethod = apperror.New("rpc_unknown_method", apperror.KindNotFound, "unknown method").
+				WithKey("rpc.unknown_method")
+)
+
+// request is the payload of the event carrying a call
+type request struct {
+	ReplyTo  string          `json:"reply_to"`
+	Deadline time.Time       `json:"deadline"`
+	Body     json.RawMessage `json:"body"`
+}
+
+// reply is published on the caller's reply channel
+type reply struct {
+	CorrelationID string          `json:"correlation_id"`
+	Body          json.RawMessage `json:"body,omitempty"`
+	Error         *replyError     `json:"error,omitempty"`
+}
+
+// replyError carries an application error across the queue
+type replyError struct {
+	Code       string                 `json:"code"`
+	Kind       apperror.Kind          `json:"kind"`
+	Message    string                 `json:"message"`
+	MessageKey string                 `json:"message_key,omitempty"`
+	Meta       map[string]interface{} `json:"meta,omitempty"`
+}
+
+func toReplyError(err error) *replyError {
+	appErr, ok := apperror.As(err)
+	if !ok {
+		return &replyError{Code: "internal_error", Kind: apperror.KindInternal, Message: "internal error"}
+	}
+	return &replyError{
+		Code:       appErr.Code,
+		Kind:       appErr.Kind,
+		Message:    appErr.Message,
+		MessageKey: appErr.MessageKey,
+		Meta:       appErr.Meta,
+	}
+}
+
+func (e *replyError) err() error {
+	appErr := apperror.New(e.Code, e.Kind, e.Message)
+	appErr.MessageKey = e.MessageKey
+	appErr.Meta = e.Meta
+	return appErr
+}
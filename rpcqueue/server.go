@@ -0,0 +1,151 @@
+package rpcqueue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/events"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// HandlerFunc answers one request; the returned value is sent back as the reply
+type HandlerFunc func(ctx context.Context, req *Request) (interface{}, error)
+
+// Request is a call received by a Server
+type Request struct {
+	Method string
+	Event  *events.Event
+	body   json.RawMessage
+}
+
+// Decode unmarshals the request body into v
+func (r *Request) Decode(v interface{}) error {
+	if err := json.Unmarshal(r.body, v); err != nil {
+		return apperror.Wrap(err, "invalid_request", apperror.KindBadRequest, "invalid "+r.Method+" request")
+	}
+	return nil
+}
+
+// Server dispatches requests to handlers by method and publishes their replies
+type Server struct {
+	rdb *redis.Client
+	log *zap.Logger
+
+	mu       sync.RWMutex
+	handlers map[string]HandlerFunc
+}
+
+// NewServer creates a server replying over rdb; subscribe its Handler to the request topic
+func NewServer(rdb *redis.Client) *Server {
+	return &Server{rdb: rdb, log: logger.Module("rpcqueue"), handlers: make(map[string]HandlerFunc)}
+}
+
+// Handle registers the handler for method
+func (s *Server) Handle(method string, fn HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method] = fn
+}
+
+// Handle registers a typed handler for method, decoding the request into Req
+func Handle[Req, Resp any](s *Server, method string, fn func(ctx context.Context, req Req) (Resp, error)) {
+	s.Handle(method, func(ctx context.Context, r *Request) (interface{}, error) {
+		var req Req
+		if err := r.Decode(&req); err != nil {
+			return nil, err
+		}
+		return fn(ctx, req)
+	})
+}
+
+// Handler returns the events handler answering requests. Requests whose caller already gave up
+// are skipped. Handler errors are replied to the caller rather than retried by the subscriber,
+// so a handler runs at most once per delivery that reaches it in time.
+func (s *Server) Handler() events.Handler {
+	return func(ctx context.Context, event *events.Event) error {
+		var req request
+		if err := event.Decode(&req); err != nil {
+			return events.Permanent(err)
+		}
+		if req.ReplyTo == "" {
+			return events.Permanent(fmt.Errorf("rpc request %s has no reply channel", event.ID))
+		}
+		if !req.Deadline.IsZero() && time.Now().After(req.Deadline) {
+			requestsTotal.WithLabelValues(event.Type, "expired").Inc()
+			return nil
+		}
+
+		ctx = requestContext(ctx, event)
+		if !req.Deadline.IsZero() {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithDeadline(ctx, req.Deadline)
+			defer cancel()
+		}
+
+		r := reply{CorrelationID: event.ID}
+		body, err := s.dispatch(ctx, &Request{Method: event.Type, Event: event, body: req.Body})
+		if err == nil {
+			r.Body, err = json.Marshal(body)
+		}
+		result := "ok"
+		if err != nil {
+			result = "error"
+			r.Error = toReplyError(err)
+			if _, ok := apperror.As(err); !ok {
+				s.log.Error("rpc handler failed", zap.String("method", event.Type), zap.Error(err))
+			}
+		}
+		requestsTotal.WithLabelValues(event.Type, result).Inc()
+
+		data, err := json.Marshal(r)
+		if err != nil {
+			return events.Permanent(fmt.Errorf("failed to encode rpc reply: %w", err))
+		}
+		// The caller's deadline may have passed meanwhile, which only means nobody listens
+		if err := s.rdb.Publish(context.WithoutCancel(ctx), req.ReplyTo, data).Err(); err != nil {
+			s.log.Warn("failed to publish rpc reply", zap.String("method", event.Type), zap.Error(err))
+		}
+		return nil
+	}
+}
+
+// dispatch runs the handler for the request, converting panics into internal errors
+func (s *Server) dispatch(ctx context.Context, req *Request) (resp interface{}, err error) {
+	s.mu.RLock()
+	fn, ok := s.handlers[req.Method]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownMethod
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = apperror.Internal(fmt.Errorf("rpc handler panicked: %v", r), "internal error")
+		}
+	}()
+	return fn(ctx, req)
+}
+
+// requestContext restores the caller's request ID, user, tenant and trace ID from the event
+func requestContext(ctx context.Context, event *events.Event) context.Context {
+	if id := event.TraceContext[events.TraceRequestID]; id != "" {
+		ctx = ctxutil.WithRequestID(ctx, id)
+	}
+	if id := event.TraceContext[events.TraceTraceID]; id != "" {
+		ctx = ctxutil.WithTraceID(ctx, id)
+	}
+	if id, err := strconv.ParseUint(event.TraceContext[events.TraceUserID], 10, 64); err == nil {
+		ctx = ctxutil.WithUserID(ctx, id)
+	}
+	if event.TenantID != "" {
+		ctx = ctxutil.WithTenantID(ctx, event.TenantID)
+	}
+	return ctx
+}
@@ -0,0 +1,63 @@
+package saga
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Status is the lifecycle state of a saga instance
+type Status string
+
+const (
+	StatusRunning      Status = "running"
+	StatusCompleted    Status = "completed"
+	StatusCompensating Status = "compensating"
+	StatusCompensated  Status = "compensated"
+	StatusFailed       Status = "failed" // compensation itself failed, needs manual attention
+)
+
+// Step statuses
+const (
+	StepPending     = "pending"
+	StepCompleted   = "completed"
+	StepFailed      = "failed"
+	StepCompensated = "compensated"
+)
+
+// StepState records the outcome of one step
+type StepState struct {
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	Attempts   int        `json:"attempts"`
+	Error      string     `json:"error,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Instance is the persisted state of one saga run
+type Instance struct {
+	ID          string                     `json:"id" gorm:"primaryKey;size:64"`
+	Name        string                     `json:"name" gorm:"index;size:128;not null"`
+	Status      Status                     `json:"status" gorm:"index;size:16;not null"`
+	CurrentStep int                        `json:"current_step"`
+	Steps       []StepState                `json:"steps" gorm:"serializer:json"`
+	Data        map[string]json.RawMessage `json:"data,omitempty" gorm:"serializer:json"`
+	Error       string                     `json:"error,omitempty"`
+	TenantID    string                     `json:"tenant_id,omitempty" gorm:"index;size:64"`
+	UserID      uint64                     `json:"user_id,omitempty"`
+	RequestID   string                     `json:"request_id,omitempty" gorm:"size:128"`
+	Owner       string                     `json:"-" gorm:"size:128"`
+	LockedUntil *time.Time                 `json:"-" gorm:"index"`
+	CreatedAt   time.Time                  `json:"created_at"`
+	UpdatedAt   time.Time                  `json:"updated_at"`
+	CompletedAt *time.Time                 `json:"completed_at,omitempty"`
+}
+
+// TableName overrides the table name
+func (Instance) TableName() string {
+	return "saga_instances"
+}
+
+// Done reports whether the instance reached a final status
+func (i *Instance) Done() bool {
+	return i.Status == StatusCompleted || i.Status == StatusCompensated || i.Status == StatusFailed
+}
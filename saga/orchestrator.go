@@ -0,0 +1,439 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/events"
+	"github.com/Masharah-Advisory/common/idgen"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/retry"
+	"github.com/Masharah-Advisory/common/worker"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Saga lifecycle event types published by the orchestrator
+const (
+	EventStarted     = "saga.started"
+	EventCompleted   = "saga.completed"
+	EventCompensated = "saga.compensated"
+	EventFailed      = "saga.failed"
+)
+
+var (
+	// ErrUnknownSaga is returned when starting a saga that was never registered
+	ErrUnknownSaga = errors.New("unknown saga")
+	// ErrNotFound is returned when no instance has the given ID
+	ErrNotFound = errors.New("saga instance not found")
+	// errLeaseLost stops execution when another process took over the instance
+	errLeaseLost = errors.New("saga lease lost")
+)
+
+// Config holds the orchestrator settings
+type Config struct {
+	DB *gorm.DB
+	// Publisher receives lifecycle events (saga.started, saga.completed, ...); nil disables them
+	Publisher events.Publisher
+	Topic     string // defaults to "sagas"
+	// LeaseTimeout is how long an instance stays claimed without progress before another
+	// process resumes it; it must exceed the slowest step, defaults to 5m
+	LeaseTimeout time.Duration
+	PollInterval time.Duration // how often Resume looks for stalled instances, defaults to 30s
+	BatchSize    int           // instances resumed per poll, defaults to 50
+}
+
+// Event is the payload of the lifecycle events
+type Event struct {
+	SagaID string `json:"saga_id"`
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Orchestrator runs registered sagas and persists their progress so they survive restarts
+type Orchestrator struct {
+	cfg   *Config
+	owner string
+	log   *zap.Logger
+
+	mu   sync.RWMutex
+	defs map[string]*Definition
+}
+
+// NewOrchestrator creates an orchestrator; run db.Migrate with &Instance{} first
+func NewOrchestrator(cfg *Config) *Orchestrator {
+	if cfg.Topic == "" {
+		cfg.Topic = "sagas"
+	}
+	if cfg.LeaseTimeout <= 0 {
+		cfg.LeaseTimeout = 5 * time.Minute
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 30 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+
+	host, _ := os.Hostname()
+	return &Orchestrator{
+		cfg:   cfg,
+		owner: host + "/" + idgen.NewULIDString(),
+		log:   logger.Module("saga"),
+		defs:  make(map[string]*Definition),
+	}
+}
+
+// Register adds a saga definition; register every definition before Start or Resume
+func (o *Orchestrator) Register(def Definition) {
+	for i := range def.Steps {
+		if def.Steps[i].Retries <= 0 {
+			def.Steps[i].Retries = 3
+		}
+		if def.Steps[i].Backoff <= 0 {
+			def.Steps[i].Backoff = time.Second
+		}
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.defs[def.Name] = &def
+}
+
+func (o *Orchestrator) definition(name string) (*Definition, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	def, ok := o.defs[name]
+	return def, ok
+}
+
+// StartOptions customise a new instance
+type StartOptions struct {
+	// ID makes Start idempotent: starting twice with the same ID returns the existing instance.
+	// Defaults to a new ULID.
+	ID string
+	// Async persists the instance and returns immediately; the next Resume poll runs it
+	Async bool
+}
+
+// Start persists a new instance of the named saga with input stored under the "input" key and
+// runs it to completion (or compensation). Tenant, user and request IDs are taken from ctx and
+// restored when the saga is resumed elsewhere.
+func (o *Orchestrator) Start(ctx context.Context, name string, input interface{}, opts ...StartOptions) (*Instance, error) {
+	def, ok := o.definition(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSaga, name)
+	}
+
+	var opt StartOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.ID == "" {
+		opt.ID = idgen.NewULIDString()
+	}
+
+	inst := &Instance{
+		ID:        opt.ID,
+		Name:      name,
+		Status:    StatusRunning,
+		Steps:     make([]StepState, len(def.Steps)),
+		Data:      make(map[string]json.RawMessage),
+		TenantID:  ctxutil.TenantID(ctx),
+		RequestID: ctxutil.RequestID(ctx),
+	}
+	inst.UserID, _ = ctxutil.UserID(ctx)
+	for i, step := range def.Steps {
+		inst.Steps[i] = StepState{Name: step.Name, Status: StepPending}
+	}
+	if input != nil {
+		raw, err := json.Marshal(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode saga input: %w", err)
+		}
+		inst.Data["input"] = raw
+	}
+	if !opt.Async {
+		until := time.Now().Add(o.cfg.LeaseTimeout)
+		inst.Owner = o.owner
+		inst.LockedUntil = &until
+	}
+
+	res := o.cfg.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(inst)
+	if res.Error != nil {
+		return nil, fmt.Errorf("failed to create saga instance: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return o.Get(ctx, opt.ID)
+	}
+	o.publish(ctx, EventStarted, inst)
+
+	if opt.Async {
+		return inst, nil
+	}
+	// The saga outlives the request that started it
+	o.execute(context.WithoutCancel(ctx), def, inst)
+	return inst, nil
+}
+
+// Get loads an instance by ID
+func (o *Orchestrator) Get(ctx context.Context, id string) (*Instance, error) {
+	var inst Instance
+	err := o.cfg.DB.WithContext(ctx).Where("id = ?", id).First(&inst).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load saga instance: %w", err)
+	}
+	return &inst, nil
+}
+
+// Resume claims unfinished instances whose lease expired (crashed processes, async starts) and
+// continues them; it returns how many were resumed
+func (o *Orchestrator) Resume(ctx context.Context) (int, error) {
+	var candidates []Instance
+	err := o.cfg.DB.WithContext(ctx).
+		Where("status IN ?", []Status{StatusRunning, StatusCompensating}).
+		Where("locked_until IS NULL OR locked_until < ?", time.Now()).
+		Order("created_at").
+		Limit(o.cfg.BatchSize).
+		Find(&candidates).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to find stalled sagas: %w", err)
+	}
+
+	resumed := 0
+	for i := range candidates {
+		inst := &candidates[i]
+		def, ok := o.definition(inst.Name)
+		if !ok {
+			continue
+		}
+		claimed, err := o.claim(ctx, inst)
+		if err != nil {
+			return resumed, err
+		}
+		if !claimed {
+			continue
+		}
+		o.log.Info("resuming saga", zap.String("saga_id", inst.ID), zap.String("name", inst.Name), zap.String("status", string(inst.Status)))
+		o.execute(restoreContext(ctx, inst), def, inst)
+		resumed++
+	}
+	return resumed, nil
+}
+
+// Schedule runs Resume periodically on the worker manager
+func (o *Orchestrator) Schedule(m *worker.Manager) {
+	m.Every("saga.resume", o.cfg.PollInterval, func(ctx context.Context) error {
+		_, err := o.Resume(ctx)
+		return err
+	}, worker.JobOptions{})
+}
+
+// EventHandler starts the named saga for every event it receives, using the event payload as
+// input and the event ID as instance ID so redelivered events do not start it twice
+func (o *Orchestrator) EventHandler(name string) events.Handler {
+	return func(ctx context.Context, event *events.Event) error {
+		if event.TenantID != "" {
+			ctx = context.WithValue(ctx, "tenant_id", event.TenantID)
+		}
+		_, err := o.Start(ctx, name, event.Payload, StartOptions{ID: event.ID, Async: true})
+		return err
+	}
+}
+
+// claim takes the lease on an instance; false means another process got it first
+func (o *Orchestrator) claim(ctx context.Context, inst *Instance) (bool, error) {
+	now := time.Now()
+	until := now.Add(o.cfg.LeaseTimeout)
+	res := o.cfg.DB.WithContext(ctx).Model(&Instance{}).
+		Where("id = ?", inst.ID).
+		Where("status IN ?", []Status{StatusRunning, StatusCompensating}).
+		Where("locked_until IS NULL OR locked_until < ?", now).
+		Updates(map[string]interface{}{"owner": o.owner, "locked_until": until})
+	if res.Error != nil {
+		return false, fmt.Errorf("failed to claim saga instance: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return false, nil
+	}
+	inst.Owner = o.owner
+	inst.LockedUntil = &until
+	return true, nil
+}
+
+// save persists progress and renews the lease; it fails when the lease was lost
+func (o *Orchestrator) save(ctx context.Context, inst *Instance) error {
+	if inst.Done() {
+		inst.LockedUntil = nil
+		now := time.Now().UTC()
+		inst.CompletedAt = &now
+	} else {
+		until := time.Now().Add(o.cfg.LeaseTimeout)
+		inst.LockedUntil = &until
+	}
+
+	res := o.cfg.DB.WithContext(ctx).Model(&Instance{}).
+		Where("id = ? AND owner = ?", inst.ID, o.owner).
+		Select("status", "current_step", "steps", "data", "error", "locked_until", "completed_at", "updated_at").
+		Updates(inst)
+	if res.Error != nil {
+		return fmt.Errorf("failed to save saga instance: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return errLeaseLost
+	}
+	return nil
+}
+
+// execute drives an instance forward, then through compensation when a step failed
+func (o *Orchestrator) execute(ctx context.Context, def *Definition, inst *Instance) {
+	log := o.log.With(zap.String("saga_id", inst.ID), zap.String("name", inst.Name))
+	if inst.Data == nil {
+		inst.Data = make(map[string]json.RawMessage)
+	}
+	// Definitions may gain steps between deploys; older instances get pending entries
+	for len(inst.Steps) < len(def.Steps) {
+		inst.Steps = append(inst.Steps, StepState{Name: def.Steps[len(inst.Steps)].Name, Status: StepPending})
+	}
+
+	for inst.Status == StatusRunning && inst.CurrentStep < len(def.Steps) {
+		step := def.Steps[inst.CurrentStep]
+		state := &inst.Steps[inst.CurrentStep]
+
+		err := o.run(ctx, step, step.Action, inst, state)
+		now := time.Now().UTC()
+		state.FinishedAt = &now
+		if err != nil {
+			log.Warn("saga step failed, compensating", zap.String("step", step.Name), zap.Error(err))
+			state.Status = StepFailed
+			state.Error = err.Error()
+			inst.Status = StatusCompensating
+			inst.Error = fmt.Sprintf("%s: %s", step.Name, err)
+		} else {
+			state.Status = StepCompleted
+			inst.CurrentStep++
+		}
+		if inst.CurrentStep == len(def.Steps) {
+			inst.Status = StatusCompleted
+		}
+		if !o.persist(ctx, log, inst) {
+			return
+		}
+	}
+
+	for inst.Status == StatusCompensating {
+		// Steps before CurrentStep completed and are undone newest first
+		if inst.CurrentStep == 0 {
+			inst.Status = StatusCompensated
+			o.persist(ctx, log, inst)
+			break
+		}
+
+		step := def.Steps[inst.CurrentStep-1]
+		state := &inst.Steps[inst.CurrentStep-1]
+		if step.Compensate != nil {
+			if err := o.run(ctx, step, step.Compensate, inst, state); err != nil {
+				log.Error("saga compensation failed", zap.String("step", step.Name), zap.Error(err))
+				state.Error = err.Error()
+				inst.Status = StatusFailed
+				inst.Error = fmt.Sprintf("compensate %s: %s", step.Name, err)
+				o.persist(ctx, log, inst)
+				break
+			}
+		}
+		state.Status = StepCompensated
+		inst.CurrentStep--
+		if !o.persist(ctx, log, inst) {
+			return
+		}
+	}
+
+	switch inst.Status {
+	case StatusCompleted:
+		log.Info("saga completed")
+		o.publish(ctx, EventCompleted, inst)
+	case StatusCompensated:
+		log.Info("saga compensated", zap.String("error", inst.Error))
+		o.publish(ctx, EventCompensated, inst)
+	case StatusFailed:
+		o.publish(ctx, EventFailed, inst)
+	}
+}
+
+// persist saves the instance and reports whether execution may continue
+func (o *Orchestrator) persist(ctx context.Context, log *zap.Logger, inst *Instance) bool {
+	if err := o.save(ctx, inst); err != nil {
+		// The lease expires and Resume picks the instance up again
+		log.Error("failed to persist saga progress", zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// run calls action with retries; permanent errors (events.Permanent) are not retried
+func (o *Orchestrator) run(ctx context.Context, step Step, action Action, inst *Instance, state *StepState) error {
+	s := &State{id: inst.ID, step: step.Name, data: inst.Data}
+	policy := retry.Policy{
+		MaxAttempts:    step.Retries + 1,
+		InitialBackoff: step.Backoff,
+		Retryable:      func(err error) bool { return !events.IsPermanent(err) },
+	}
+	return retry.Do(ctx, policy, func(ctx context.Context) error {
+		state.Attempts++
+		return runOnce(ctx, step.Timeout, action, s)
+	})
+}
+
+// runOnce runs action once with the step timeout, converting panics into errors
+func runOnce(ctx context.Context, timeout time.Duration, action Action, s *State) (err error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("step %s panicked: %v", s.step, r)
+		}
+	}()
+	return action(ctx, s)
+}
+
+func (o *Orchestrator) publish(ctx context.Context, eventType string, inst *Instance) {
+	if o.cfg.Publisher == nil {
+		return
+	}
+	event, err := events.NewEvent(ctx, eventType, Event{SagaID: inst.ID, Name: inst.Name, Status: inst.Status, Error: inst.Error})
+	if err != nil {
+		return
+	}
+	event.Key = inst.ID
+	if err := o.cfg.Publisher.Publish(ctx, o.cfg.Topic, event); err != nil {
+		o.log.Warn("failed to publish saga event", zap.String("type", eventType), zap.String("saga_id", inst.ID), zap.Error(err))
+	}
+}
+
+// restoreContext rebuilds the request context values a saga was started with
+func restoreContext(ctx context.Context, inst *Instance) context.Context {
+	if inst.TenantID != "" {
+		ctx = ctxutil.WithTenantID(ctx, inst.TenantID)
+	}
+	if inst.UserID != 0 {
+		ctx = ctxutil.WithUserID(ctx, inst.UserID)
+	}
+	if inst.RequestID != "" {
+		ctx = ctxutil.WithRequestID(ctx, inst.RequestID)
+	}
+	return ctx
+}
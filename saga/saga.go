@@ -0,0 +1,70 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Action runs or compensates one step; it must be idempotent because a step is re-run when the
+// process crashes before its result is persisted
+type Action func(ctx context.Context, s *State) error
+
+// Step is one unit of a saga with the action that undoes it
+type Step struct {
+	Name       string
+	Action     Action
+	Compensate Action        // nil when the step has nothing to undo
+	Retries    int           // retries after the first attempt, defaults to 3
+	Backoff    time.Duration // initial backoff, doubled per retry, defaults to 1s
+	Timeout    time.Duration // per-attempt timeout, 0 means none
+}
+
+// Definition is a named sequence of steps; steps run in order and, when one fails, the
+// completed ones are compensated in reverse order
+type Definition struct {
+	Name  string
+	Steps []Step
+}
+
+// State is the data shared between the steps of one saga instance; it is persisted after
+// every step so resumed sagas see what earlier steps produced
+type State struct {
+	id   string
+	step string
+	data map[string]json.RawMessage
+}
+
+// ID returns the saga instance ID
+func (s *State) ID() string {
+	return s.id
+}
+
+// IdempotencyKey returns a key unique to this instance and step, to pass to downstream services
+// so a re-run step is not applied twice
+func (s *State) IdempotencyKey() string {
+	return s.id + ":" + s.step
+}
+
+// Get decodes the value stored under key into v and reports whether it was present
+func (s *State) Get(key string, v interface{}) (bool, error) {
+	raw, ok := s.data[key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return true, fmt.Errorf("failed to decode saga value %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// Set stores v under key
+func (s *State) Set(key string, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode saga value %s: %w", key, err)
+	}
+	s.data[key] = raw
+	return nil
+}
@@ -0,0 +1,52 @@
+package sanitize
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const tatweel = '\u0640'
+
+// Arabic normalizes Arabic text for storage: presentation forms are folded to base letters (NFKC),
+// tatweel is removed and control characters are stripped. The visible spelling is preserved.
+func Arabic(s string) string {
+	s = norm.NFKC.String(s)
+	s = strings.Map(func(r rune) rune {
+		if r == tatweel {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.TrimSpace(StripControl(s))
+}
+
+// ArabicSearchKey folds Arabic text for matching and search indexes: diacritics are removed and
+// letter variants unified (أ إ آ ٱ → ا, ى → ي, ة → ه, ؤ → و, ئ → ي), and Arabic-Indic digits become ASCII
+func ArabicSearchKey(s string) string {
+	s = Arabic(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// harakat, shadda, sukun, superscript alef
+			continue
+		case r == 'أ' || r == 'إ' || r == 'آ' || r == 'ٱ':
+			r = 'ا'
+		case r == 'ى' || r == 'ئ':
+			r = 'ي'
+		case r == 'ة':
+			r = 'ه'
+		case r == 'ؤ':
+			r = 'و'
+		case r >= '٠' && r <= '٩':
+			r = '0' + (r - '٠')
+		case r >= '۰' && r <= '۹':
+			r = '0' + (r - '۰')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToLower(strings.Join(strings.Fields(b.String()), " "))
+}
@@ -0,0 +1,70 @@
+package sanitize
+
+import (
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxFilenameBytes is the common filesystem limit
+const maxFilenameBytes = 255
+
+// reservedNames are device names Windows refuses as file names
+var reservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// Filename makes a user-supplied file name safe to store and serve: directories are dropped,
+// separators, control and shell-hostile characters are replaced, leading dots removed and the
+// name truncated to 255 bytes keeping its extension. Arabic names are preserved.
+func Filename(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = path.Base(name)
+	name = Text(name)
+
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r == '/' || r == ':' || r == '*' || r == '?' || r == '"' || r == '<' || r == '>' || r == '|':
+			return '_'
+		case r == '\n' || r == '\t':
+			return ' '
+		case !unicode.IsPrint(r):
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.Join(strings.Fields(name), " ")
+	name = strings.TrimLeft(name, ". ")
+	name = strings.TrimRight(name, ". ")
+
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	if reservedNames[strings.ToUpper(base)] {
+		base = "_" + base
+	}
+	if base == "" {
+		base = "file"
+	}
+
+	if len(base)+len(ext) > maxFilenameBytes {
+		if len(ext) > 16 {
+			ext = ""
+		}
+		base = truncateUTF8(base, maxFilenameBytes-len(ext))
+	}
+	return base + ext
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a rune
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,48 @@
+package sanitize
+
+import (
+	"regexp"
+	"sync"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+var (
+	richOnce    sync.Once
+	richPolicy  *bluemonday.Policy
+	stripOnce   sync.Once
+	stripPolicy *bluemonday.Policy
+)
+
+// rich is the policy for rich-text fields: user-generated-content formatting, links forced to
+// nofollow/noopener, and the dir attribute so Arabic content keeps its direction
+func rich() *bluemonday.Policy {
+	richOnce.Do(func() {
+		p := bluemonday.UGCPolicy()
+		p.RequireNoFollowOnLinks(true)
+		p.RequireNoReferrerOnLinks(true)
+		p.AddTargetBlankToFullyQualifiedLinks(true)
+		p.AllowURLSchemes("http", "https", "mailto", "tel")
+		p.AllowAttrs("dir").Matching(regexp.MustCompile(`^(rtl|ltr|auto)$`)).Globally()
+		richPolicy = p
+	})
+	return richPolicy
+}
+
+func strict() *bluemonday.Policy {
+	stripOnce.Do(func() {
+		stripPolicy = bluemonday.StrictPolicy()
+	})
+	return stripPolicy
+}
+
+// HTML sanitizes rich-text input, keeping safe formatting and dropping scripts, event handlers,
+// styles and unsafe URLs
+func HTML(s string) string {
+	return rich().Sanitize(s)
+}
+
+// StripHTML removes all markup, leaving escaped text
+func StripHTML(s string) string {
+	return strict().Sanitize(s)
+}
@@ -0,0 +1,215 @@
+package sanitize
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// Func transforms a string field value
+type Func func(string) string
+
+// rules are the built-in `sanitize` tag values, applied in the order they appear in the tag
+var rules = map[string]Func{
+	"trim":          strings.TrimSpace,
+	"text":          Text,
+	"single_line":   SingleLine,
+	"strip_control": StripControl,
+	"html":          HTML,
+	"strip_html":    StripHTML,
+	"arabic":        Arabic,
+	"arabic_search": ArabicSearchKey,
+	"filename":      Filename,
+	"lower":         strings.ToLower,
+	"upper":         strings.ToUpper,
+	"email":         func(s string) string { return strings.ToLower(strings.TrimSpace(s)) },
+}
+
+var (
+	rulesMu sync.RWMutex
+	fields  sync.Map // reflect.Type -> []fieldRule
+)
+
+type fieldRule struct {
+	index []int
+	funcs []Func
+	dive  bool // nested struct without its own tag
+}
+
+// RegisterRule adds a custom tag value, e.g. RegisterRule("slug", toSlug) for `sanitize:"slug"`.
+// It must be called before the first Struct call for types using it.
+func RegisterRule(name string, fn Func) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rules[name] = fn
+}
+
+// Struct applies the `sanitize:"rule,rule"` tags of v, which must be a pointer to a struct.
+// Tags apply to string, *string and []string fields; untagged struct fields are walked recursively.
+//
+//	type CreatePost struct {
+//		Title string `json:"title" sanitize:"single_line,arabic"`
+//		Body  string `json:"body" sanitize:"html"`
+//	}
+func Struct(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("sanitize: expected a non-nil pointer, got %T", v)
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("sanitize: expected a pointer to a struct, got %T", v)
+	}
+	return walk(rv)
+}
+
+func walk(rv reflect.Value) error {
+	plan, err := planFor(rv.Type())
+	if err != nil {
+		return err
+	}
+	for _, rule := range plan {
+		f := rv.FieldByIndex(rule.index)
+		if rule.dive {
+			if err := dive(f); err != nil {
+				return err
+			}
+			continue
+		}
+		apply(f, rule.funcs)
+	}
+	return nil
+}
+
+// dive walks nested structs, struct pointers and slices of them
+func dive(f reflect.Value) error {
+	switch f.Kind() {
+	case reflect.Struct:
+		return walk(f)
+	case reflect.Ptr:
+		if !f.IsNil() && f.Elem().Kind() == reflect.Struct {
+			return walk(f.Elem())
+		}
+	case reflect.Slice:
+		for i := 0; i < f.Len(); i++ {
+			if err := dive(f.Index(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func apply(f reflect.Value, funcs []Func) {
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(run(f.String(), funcs))
+	case reflect.Ptr:
+		if !f.IsNil() && f.Elem().Kind() == reflect.String {
+			f.Elem().SetString(run(f.Elem().String(), funcs))
+		}
+	case reflect.Slice:
+		if f.Type().Elem().Kind() == reflect.String {
+			for i := 0; i < f.Len(); i++ {
+				f.Index(i).SetString(run(f.Index(i).String(), funcs))
+			}
+		}
+	}
+}
+
+func run(s string, funcs []Func) string {
+	for _, fn := range funcs {
+		s = fn(s)
+	}
+	return s
+}
+
+// planFor parses and caches the tagged fields of t
+func planFor(t reflect.Type) ([]fieldRule, error) {
+	if cached, ok := fields.Load(t); ok {
+		return cached.([]fieldRule), nil
+	}
+
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+
+	var plan []fieldRule
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag, ok := sf.Tag.Lookup("sanitize")
+		if tag == "-" {
+			continue
+		}
+		if !ok {
+			if isDivable(sf.Type) {
+				plan = append(plan, fieldRule{index: sf.Index, dive: true})
+			}
+			continue
+		}
+
+		var funcs []Func
+		for _, name := range strings.Split(tag, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			fn, ok := rules[name]
+			if !ok {
+				return nil, fmt.Errorf("sanitize: unknown rule %q on %s.%s", name, t.Name(), sf.Name)
+			}
+			funcs = append(funcs, fn)
+		}
+		plan = append(plan, fieldRule{index: sf.Index, funcs: funcs})
+	}
+
+	fields.Store(t, plan)
+	return plan, nil
+}
+
+func isDivable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Ptr, reflect.Slice:
+		return t.Elem().Kind() == reflect.Struct || (t.Elem().Kind() == reflect.Ptr && t.Elem().Elem().Kind() == reflect.Struct)
+	}
+	return false
+}
+
+// BindJSON decodes the JSON body into obj, sanitizes it and only then runs the `binding` validation,
+// so rules such as `required` see the cleaned values
+func BindJSON(c *gin.Context, obj interface{}) error {
+	if c.Request == nil || c.Request.Body == nil {
+		return fmt.Errorf("invalid request")
+	}
+	if err := json.NewDecoder(c.Request.Body).Decode(obj); err != nil {
+		return err
+	}
+	if err := Struct(obj); err != nil {
+		return err
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// Bind binds with the binding selected from the method and content type, sanitizes obj and
+// re-validates it
+func Bind(c *gin.Context, obj interface{}) error {
+	if b := binding.Default(c.Request.Method, c.ContentType()); b == binding.JSON {
+		return BindJSON(c, obj)
+	}
+	if err := c.ShouldBind(obj); err != nil {
+		return err
+	}
+	if err := Struct(obj); err != nil {
+		return err
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
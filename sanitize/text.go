@@ -0,0 +1,39 @@
+package sanitize
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// StripControl removes control characters except newline and tab, plus bidi override and
+// isolate characters that can be used to spoof how text is displayed
+func StripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return r
+		case r == '\r':
+			return -1
+		case unicode.IsControl(r):
+			return -1
+		case r >= '\u202a' && r <= '\u202e', r >= '\u2066' && r <= '\u2069':
+			return -1
+		case r == '\ufeff':
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// Text normalizes plain text input: NFC normalization, control characters stripped and
+// surrounding whitespace trimmed
+func Text(s string) string {
+	return strings.TrimSpace(StripControl(norm.NFC.String(s)))
+}
+
+// SingleLine is Text with all whitespace runs (including newlines) collapsed to one space
+func SingleLine(s string) string {
+	return strings.Join(strings.Fields(Text(s)), " ")
+}
@@ -0,0 +1,227 @@
+package scheduling
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Masharah-Advisory/common/calendar"
+)
+
+// Window is a daily opening in local clock time, e.g. {"09:00", "13:00"}
+type Window struct {
+	Start string `json:"start"` // HH:MM
+	End   string `json:"end"`   // HH:MM, after Start
+}
+
+// Exception replaces the weekly template on one date; no windows means unavailable all day
+type Exception struct {
+	Date    string   `json:"date"` // YYYY-MM-DD in the availability timezone
+	Windows []Window `json:"windows,omitempty"`
+	Reason  string   `json:"reason,omitempty"`
+}
+
+// Availability is a weekly template of windows plus dated exceptions
+type Availability struct {
+	// Timezone is an IANA name (default Asia/Riyadh)
+	Timezone   string                    `json:"timezone,omitempty"`
+	Weekly     map[time.Weekday][]Window `json:"weekly"`
+	Exceptions []Exception               `json:"exceptions,omitempty"`
+}
+
+// Slot is a bookable interval
+type Slot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Overlaps reports whether the slot shares time with [start, end)
+func (s Slot) Overlaps(start, end time.Time) bool {
+	return s.Start.Before(end) && start.Before(s.End)
+}
+
+// SlotOptions controls slot generation
+type SlotOptions struct {
+	// From and To bound the slots (From inclusive, To exclusive)
+	From, To time.Time
+	// Duration is the length of each slot
+	Duration time.Duration
+	// Step is the distance between slot starts (default Duration)
+	Step time.Duration
+	// Buffer keeps free time around busy intervals
+	Buffer time.Duration
+	// MinNotice drops slots starting sooner than this from now
+	MinNotice time.Duration
+	// Busy intervals are removed, e.g. existing bookings
+	Busy []Slot
+	// Calendar, when set, drops days that are not business days (Saudi weekends and holidays)
+	Calendar *calendar.Calendar
+	// Now defaults to time.Now
+	Now func() time.Time
+}
+
+// Validate checks the clock formats and the timezone
+func (a *Availability) Validate() error {
+	if _, err := a.location(); err != nil {
+		return err
+	}
+	check := func(windows []Window) error {
+		for _, w := range windows {
+			start, err := parseClock(w.Start)
+			if err != nil {
+				return err
+			}
+			end, err := parseClock(w.End)
+			if err != nil {
+				return err
+			}
+			if end <= start {
+				return fmt.Errorf("window %s-%s ends before it starts", w.Start, w.End)
+			}
+		}
+		return nil
+	}
+	for _, windows := range a.Weekly {
+		if err := check(windows); err != nil {
+			return err
+		}
+	}
+	for _, e := range a.Exceptions {
+		if _, err := time.Parse(time.DateOnly, e.Date); err != nil {
+			return fmt.Errorf("invalid exception date %q, expected YYYY-MM-DD", e.Date)
+		}
+		if err := check(e.Windows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Open reports whether [start, end) lies inside one availability window
+func (a *Availability) Open(start, end time.Time) (bool, error) {
+	loc, err := a.location()
+	if err != nil {
+		return false, err
+	}
+	local := start.In(loc)
+	for _, w := range a.windows(local) {
+		from, to, err := w.on(local)
+		if err != nil {
+			return false, err
+		}
+		if !start.Before(from) && !end.After(to) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Slots generates the free slots between opts.From and opts.To in the availability timezone
+func (a *Availability) Slots(opts SlotOptions) ([]Slot, error) {
+	if opts.Duration <= 0 {
+		return nil, fmt.Errorf("slot duration must be positive")
+	}
+	if opts.Step <= 0 {
+		opts.Step = opts.Duration
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	loc, err := a.location()
+	if err != nil {
+		return nil, err
+	}
+
+	earliest := opts.From
+	if notice := opts.Now().Add(opts.MinNotice); notice.After(earliest) {
+		earliest = notice
+	}
+	busy := make([]Slot, len(opts.Busy))
+	for i, b := range opts.Busy {
+		busy[i] = Slot{Start: b.Start.Add(-opts.Buffer), End: b.End.Add(opts.Buffer)}
+	}
+	sort.Slice(busy, func(i, j int) bool { return busy[i].Start.Before(busy[j].Start) })
+
+	var slots []Slot
+	from := opts.From.In(loc)
+	for day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc); day.Before(opts.To); day = day.AddDate(0, 0, 1) {
+		if opts.Calendar != nil && !opts.Calendar.IsBusinessDay(day) {
+			continue
+		}
+		for _, w := range a.windows(day) {
+			open, shut, err := w.on(day)
+			if err != nil {
+				return nil, err
+			}
+			for start := open; !start.Add(opts.Duration).After(shut); start = start.Add(opts.Step) {
+				slot := Slot{Start: start, End: start.Add(opts.Duration)}
+				if slot.Start.Before(earliest) || slot.End.After(opts.To) || overlapsAny(slot, busy) {
+					continue
+				}
+				slots = append(slots, slot)
+			}
+		}
+	}
+	return slots, nil
+}
+
+// windows returns the windows of day, honouring exceptions
+func (a *Availability) windows(day time.Time) []Window {
+	date := day.Format(time.DateOnly)
+	for _, e := range a.Exceptions {
+		if e.Date == date {
+			return e.Windows
+		}
+	}
+	return a.Weekly[day.Weekday()]
+}
+
+func (a *Availability) location() (*time.Location, error) {
+	if a.Timezone == "" {
+		return calendar.Location, nil
+	}
+	loc, err := time.LoadLocation(a.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", a.Timezone, err)
+	}
+	return loc, nil
+}
+
+// on returns the window's bounds on day's date in day's location
+func (w Window) on(day time.Time) (time.Time, time.Time, error) {
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	y, m, d := day.Date()
+	loc := day.Location()
+	return time.Date(y, m, d, start/60, start%60, 0, 0, loc), time.Date(y, m, d, end/60, end%60, 0, 0, loc), nil
+}
+
+func overlapsAny(slot Slot, busy []Slot) bool {
+	for _, b := range busy {
+		if !b.Start.Before(slot.End) {
+			return false
+		}
+		if slot.Overlaps(b.Start, b.End) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		// 24:00 closes a window at midnight
+		if s == "24:00" {
+			return 24 * 60, nil
+		}
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,140 @@
+package scheduling
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Event is one VEVENT of an iCalendar file
+type Event struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Location    string
+	// Organizer and Attendees are email addresses
+	Organizer string
+	Attendees []string
+	Cancelled bool
+	// Sequence must grow each time the event is rescheduled so calendars apply the update
+	Sequence int
+	// Reminder adds a display alarm this long before Start
+	Reminder time.Duration
+}
+
+// Event converts the booking to an iCalendar event; domain makes the UID globally unique
+func (b *Booking) Event(domain string) Event {
+	return Event{
+		UID:         fmt.Sprintf("booking-%d@%s", b.ID, domain),
+		Start:       b.StartsAt,
+		End:         b.EndsAt,
+		Summary:     b.Title,
+		Description: b.Notes,
+		Location:    b.Location,
+		Cancelled:   b.Status == StatusCancelled,
+		Sequence:    int(b.UpdatedAt.Unix() - b.CreatedAt.Unix()),
+	}
+}
+
+// WriteICS writes events as an RFC 5545 calendar
+func WriteICS(w io.Writer, events ...Event) error {
+	method := "PUBLISH"
+	if len(events) == 1 && events[0].Cancelled {
+		method = "CANCEL"
+	}
+
+	var buf bytes.Buffer
+	line := func(name, value string) { writeLine(&buf, name+":"+value) }
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//Masharah//Scheduling//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", method)
+
+	stamp := icsTime(time.Now())
+	for _, e := range events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", stamp)
+		line("DTSTART", icsTime(e.Start))
+		line("DTEND", icsTime(e.End))
+		line("SEQUENCE", fmt.Sprint(e.Sequence))
+		if e.Summary != "" {
+			line("SUMMARY", icsEscape(e.Summary))
+		}
+		if e.Description != "" {
+			line("DESCRIPTION", icsEscape(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", icsEscape(e.Location))
+		}
+		if e.Organizer != "" {
+			line("ORGANIZER", "mailto:"+e.Organizer)
+		}
+		for _, a := range e.Attendees {
+			writeLine(&buf, "ATTENDEE;ROLE=REQ-PARTICIPANT:mailto:"+a)
+		}
+		if e.Cancelled {
+			line("STATUS", "CANCELLED")
+		} else {
+			line("STATUS", "CONFIRMED")
+		}
+		if e.Reminder > 0 && !e.Cancelled {
+			line("BEGIN", "VALARM")
+			line("ACTION", "DISPLAY")
+			line("DESCRIPTION", icsEscape(e.Summary))
+			line("TRIGGER", fmt.Sprintf("-PT%dM", int(e.Reminder.Minutes())))
+			line("END", "VALARM")
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// ICS serves events as a downloadable .ics file
+func ICS(c *gin.Context, filename string, events ...Event) {
+	var buf bytes.Buffer
+	if err := WriteICS(&buf, events...); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	response.FileBytes(c, filename+".ics", "text/calendar; charset=utf-8", buf.Bytes())
+}
+
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func icsEscape(s string) string {
+	return icsEscaper.Replace(s)
+}
+
+// writeLine writes a content line folded at 75 octets without splitting UTF-8 characters
+func writeLine(buf *bytes.Buffer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		buf.WriteString(s[:cut])
+		buf.WriteString("\r\n ")
+		s = s[cut:]
+		// Continuation lines start with a space, which counts towards the limit
+		limit = 74
+	}
+	buf.WriteString(s)
+	buf.WriteString("\r\n")
+}
@@ -0,0 +1,56 @@
+package scheduling
+
+import (
+	"time"
+
+	"github.com/Masharah-Advisory/common/model"
+)
+
+// BookingStatus is the lifecycle state of a booking
+type BookingStatus string
+
+const (
+	StatusConfirmed BookingStatus = "confirmed"
+	StatusCancelled BookingStatus = "cancelled"
+)
+
+// Resource is something that can be booked (an advisor, a meeting room); its row also serialises
+// concurrent bookings of the resource
+type Resource struct {
+	ID           string       `json:"id" gorm:"primaryKey;size:64"`
+	TenantID     string       `json:"tenant_id,omitempty" gorm:"index;size:64"`
+	Name         string       `json:"name" gorm:"size:255"`
+	Availability Availability `json:"availability" gorm:"serializer:json"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (Resource) TableName() string {
+	return "scheduling_resources"
+}
+
+// Booking reserves a resource for [StartsAt, EndsAt)
+type Booking struct {
+	model.Base
+	ResourceID  string        `json:"resource_id" gorm:"index:idx_scheduling_booking_range;size:64;not null"`
+	TenantID    string        `json:"tenant_id,omitempty" gorm:"index;size:64"`
+	UserID      uint64        `json:"user_id" gorm:"index"`
+	StartsAt    time.Time     `json:"starts_at" gorm:"index:idx_scheduling_booking_range;not null"`
+	EndsAt      time.Time     `json:"ends_at" gorm:"not null"`
+	Status      BookingStatus `json:"status" gorm:"size:16;not null"`
+	Title       string        `json:"title" gorm:"size:255"`
+	Notes       string        `json:"notes,omitempty" gorm:"type:text"`
+	Location    string        `json:"location,omitempty" gorm:"size:512"`
+	CancelledAt *time.Time    `json:"cancelled_at,omitempty"`
+}
+
+// TableName overrides the table name
+func (Booking) TableName() string {
+	return "scheduling_bookings"
+}
+
+// Slot returns the booked interval
+func (b *Booking) Slot() Slot {
+	return Slot{Start: b.StartsAt, End: b.EndsAt}
+}
@@ -0,0 +1,227 @@
+package scheduling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/calendar"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Errors returned by the scheduler
+var (
+	ErrResourceNotFound = apperror.New("scheduling_resource_not_found", apperror.KindNotFound, "resource not found").
+				WithKey("scheduling.resource_not_found")
+	ErrBookingNotFound = apperror.New("scheduling_booking_not_found", apperror.KindNotFound, "booking not found").
+				WithKey("scheduling.booking_not_found")
+	ErrConflict = apperror.New("scheduling_conflict", apperror.KindConflict, "the slot is no longer available").
+			WithKey("scheduling.conflict")
+	ErrUnavailable = apperror.New("scheduling_unavailable", apperror.KindBadRequest, "the slot is outside the available hours").
+			WithKey("scheduling.unavailable")
+	ErrInvalidRange = apperror.New("scheduling_invalid_range", apperror.KindBadRequest, "the booking must end after it starts").
+			WithKey("scheduling.invalid_range")
+)
+
+// Config configures a Scheduler
+type Config struct {
+	DB *gorm.DB
+	// Buffer keeps free time between bookings of a resource
+	Buffer time.Duration
+	// Calendar, when set, rejects bookings and hides slots on non-business days
+	Calendar *calendar.Calendar
+	// AllowOutsideAvailability lets Book accept intervals outside the resource's windows, e.g.
+	// for staff booking on behalf of clients
+	AllowOutsideAvailability bool
+}
+
+// Scheduler books resources without double-booking them
+type Scheduler struct {
+	cfg *Config
+}
+
+// New creates a scheduler; migrate Resource and Booking first
+func New(cfg *Config) *Scheduler {
+	return &Scheduler{cfg: cfg}
+}
+
+// SaveResource creates or updates a resource and its availability
+func (s *Scheduler) SaveResource(ctx context.Context, r *Resource) error {
+	if err := r.Availability.Validate(); err != nil {
+		return apperror.Wrap(err, "scheduling_invalid_availability", apperror.KindValidation, err.Error())
+	}
+	if err := s.cfg.DB.WithContext(ctx).Save(r).Error; err != nil {
+		return fmt.Errorf("failed to save resource: %w", err)
+	}
+	return nil
+}
+
+// Resource loads a resource
+func (s *Scheduler) Resource(ctx context.Context, id string) (*Resource, error) {
+	var r Resource
+	if err := s.cfg.DB.WithContext(ctx).First(&r, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrResourceNotFound
+		}
+		return nil, fmt.Errorf("failed to load resource: %w", err)
+	}
+	return &r, nil
+}
+
+// Slots returns the free slots of a resource between opts.From and opts.To; opts.Busy is
+// extended with the resource's bookings and the scheduler's buffer and calendar apply
+func (s *Scheduler) Slots(ctx context.Context, resourceID string, opts SlotOptions) ([]Slot, error) {
+	r, err := s.Resource(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	bookings, err := s.Bookings(ctx, resourceID, opts.From.Add(-s.cfg.Buffer), opts.To.Add(s.cfg.Buffer))
+	if err != nil {
+		return nil, err
+	}
+	for i := range bookings {
+		opts.Busy = append(opts.Busy, bookings[i].Slot())
+	}
+	if opts.Buffer == 0 {
+		opts.Buffer = s.cfg.Buffer
+	}
+	if opts.Calendar == nil {
+		opts.Calendar = s.cfg.Calendar
+	}
+	return r.Availability.Slots(opts)
+}
+
+// Bookings returns the confirmed bookings of a resource overlapping [from, to)
+func (s *Scheduler) Bookings(ctx context.Context, resourceID string, from, to time.Time) ([]Booking, error) {
+	return s.overlapping(s.cfg.DB.WithContext(ctx), resourceID, from, to, 0)
+}
+
+// Conflicts returns the confirmed bookings that [start, end) would collide with, buffer included
+func (s *Scheduler) Conflicts(ctx context.Context, resourceID string, start, end time.Time) ([]Booking, error) {
+	return s.overlapping(s.cfg.DB.WithContext(ctx), resourceID, start.Add(-s.cfg.Buffer), end.Add(s.cfg.Buffer), 0)
+}
+
+// Book reserves the booking's interval. The resource row is locked for the transaction, so
+// concurrent bookings of the same resource are checked one after another.
+func (s *Scheduler) Book(ctx context.Context, b *Booking) error {
+	if !b.EndsAt.After(b.StartsAt) {
+		return ErrInvalidRange
+	}
+	return s.cfg.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		r, err := lockResource(tx, b.ResourceID)
+		if err != nil {
+			return err
+		}
+		if err := s.check(tx, r, b); err != nil {
+			return err
+		}
+		b.Status = StatusConfirmed
+		if b.TenantID == "" {
+			b.TenantID = r.TenantID
+		}
+		if err := tx.Create(b).Error; err != nil {
+			return fmt.Errorf("failed to create booking: %w", err)
+		}
+		return nil
+	})
+}
+
+// Reschedule moves a confirmed booking, with the same checks as Book
+func (s *Scheduler) Reschedule(ctx context.Context, id uint64, start, end time.Time) (*Booking, error) {
+	if !end.After(start) {
+		return nil, ErrInvalidRange
+	}
+	var b Booking
+	err := s.cfg.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&b, "id = ? AND status = ?", id, StatusConfirmed).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrBookingNotFound
+			}
+			return fmt.Errorf("failed to load booking: %w", err)
+		}
+		r, err := lockResource(tx, b.ResourceID)
+		if err != nil {
+			return err
+		}
+		b.StartsAt, b.EndsAt = start, end
+		if err := s.check(tx, r, &b); err != nil {
+			return err
+		}
+		if err := tx.Model(&b).Updates(map[string]interface{}{"starts_at": start, "ends_at": end}).Error; err != nil {
+			return fmt.Errorf("failed to reschedule booking: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Cancel frees a booking's interval
+func (s *Scheduler) Cancel(ctx context.Context, id uint64) error {
+	now := time.Now()
+	res := s.cfg.DB.WithContext(ctx).Model(&Booking{}).
+		Where("id = ? AND status = ?", id, StatusConfirmed).
+		Updates(map[string]interface{}{"status": StatusCancelled, "cancelled_at": now})
+	if res.Error != nil {
+		return fmt.Errorf("failed to cancel booking: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrBookingNotFound
+	}
+	return nil
+}
+
+// check rejects b when it falls outside the resource's availability or collides with another
+// booking
+func (s *Scheduler) check(tx *gorm.DB, r *Resource, b *Booking) error {
+	if s.cfg.Calendar != nil && !s.cfg.Calendar.IsBusinessDay(b.StartsAt) {
+		return ErrUnavailable
+	}
+	if !s.cfg.AllowOutsideAvailability {
+		open, err := r.Availability.Open(b.StartsAt, b.EndsAt)
+		if err != nil {
+			return err
+		}
+		if !open {
+			return ErrUnavailable
+		}
+	}
+	conflicts, err := s.overlapping(tx, r.ID, b.StartsAt.Add(-s.cfg.Buffer), b.EndsAt.Add(s.cfg.Buffer), b.ID)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// overlapping loads confirmed bookings of a resource overlapping [from, to), except one ID
+func (s *Scheduler) overlapping(tx *gorm.DB, resourceID string, from, to time.Time, except uint64) ([]Booking, error) {
+	q := tx.Where("resource_id = ? AND status = ? AND starts_at < ? AND ends_at > ?", resourceID, StatusConfirmed, to, from)
+	if except != 0 {
+		q = q.Where("id <> ?", except)
+	}
+	var bookings []Booking
+	if err := q.Order("starts_at").Find(&bookings).Error; err != nil {
+		return nil, fmt.Errorf("failed to load bookings: %w", err)
+	}
+	return bookings, nil
+}
+
+// lockResource loads the resource with SELECT ... FOR UPDATE
+func lockResource(tx *gorm.DB, id string) (*Resource, error) {
+	var r Resource
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&r, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrResourceNotFound
+		}
+		return nil, fmt.Errorf("failed to lock resource: %w", err)
+	}
+	return &r, nil
+}
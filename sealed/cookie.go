@@ -0,0 +1,109 @@
+package sealed
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CookieConfig configures a sealed cookie
+type CookieConfig struct {
+	Name string
+	// Path defaults to "/"
+	Path   string
+	Domain string
+	// SameSite defaults to Lax
+	SameSite http.SameSite
+	// Insecure drops the Secure flag, for plain-HTTP local development only
+	Insecure bool
+	// Script lets JavaScript read the cookie, e.g. a double-submit CSRF token; cookies are
+	// HttpOnly otherwise
+	Script bool
+}
+
+// Cookie stores sealed values in a cookie that lives as long as its tokens
+type Cookie struct {
+	sealer *Sealer
+	cfg    CookieConfig
+}
+
+// Cookie returns a cookie sealed by s
+func (s *Sealer) Cookie(cfg CookieConfig) *Cookie {
+	if cfg.Path == "" {
+		cfg.Path = "/"
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+	return &Cookie{sealer: s, cfg: cfg}
+}
+
+// Set seals v as JSON into the cookie
+func (k *Cookie) Set(c *gin.Context, v interface{}) error {
+	token, err := k.sealer.SealJSON(v)
+	if err != nil {
+		return err
+	}
+	k.write(c, token, int(k.sealer.maxAge.Seconds()))
+	return nil
+}
+
+// Get opens the cookie into v; a missing cookie is ErrInvalid. Values sealed with a rotated-out
+// key are sealed again with the primary one, so cookies follow key rotation on their own.
+func (k *Cookie) Get(c *gin.Context, v interface{}) error {
+	token, err := c.Cookie(k.cfg.Name)
+	if err != nil || token == "" {
+		return ErrInvalid
+	}
+	data, stale, err := k.sealer.open(token)
+	if err != nil {
+		return err
+	}
+	if err := unmarshal(data, v); err != nil {
+		return err
+	}
+	if stale {
+		// Renews the expiry like a fresh Set; on failure the old cookie stays until it expires
+		_ = k.Set(c, v)
+	}
+	return nil
+}
+
+// Clear removes the cookie from the client
+func (k *Cookie) Clear(c *gin.Context) {
+	k.write(c, "", -1)
+}
+
+func (k *Cookie) write(c *gin.Context, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     k.cfg.Name,
+		Value:    value,
+		Path:     k.cfg.Path,
+		Domain:   k.cfg.Domain,
+		MaxAge:   maxAge,
+		Secure:   !k.cfg.Insecure,
+		HttpOnly: !k.cfg.Script,
+		SameSite: k.cfg.SameSite,
+	})
+}
+
+// SetHeader seals v as JSON into a response header, e.g. a token the client echoes back
+func (s *Sealer) SetHeader(c *gin.Context, name string, v interface{}) error {
+	token, err := s.SealJSON(v)
+	if err != nil {
+		return err
+	}
+	c.Header(name, token)
+	return nil
+}
+
+// FromHeader opens the token of a request header into v; "Bearer " prefixes are accepted and a
+// missing header is ErrInvalid
+func (s *Sealer) FromHeader(c *gin.Context, name string, v interface{}) error {
+	token := strings.TrimSpace(strings.TrimPrefix(c.GetHeader(name), "Bearer "))
+	if token == "" {
+		return ErrInvalid
+	}
+	return s.OpenJSON(token, v)
+}
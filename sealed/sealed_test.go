@@ -0,0 +1,129 @@
+package sealed
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Masharah-Advisory/common/crypto"
+	"github.com/gin-gonic/gin"
+)
+
+func newTestKeyring(t *testing.T, primary uint32) *crypto.Keyring {
+	t.Helper()
+	keys, err := crypto.NewKeyring(primary, map[uint32][]byte{
+		1: bytes.Repeat([]byte{1}, 32),
+		2: bytes.Repeat([]byte{2}, 32),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func mustKeyring(t *testing.T, version uint32) *crypto.Keyring {
+	t.Helper()
+	keys, err := crypto.NewKeyring(version, map[uint32][]byte{version: bytes.Repeat([]byte{byte(version)}, 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func TestOpen(t *testing.T) {
+	keys := newTestKeyring(t, 1)
+	s := New(keys, "session", time.Hour)
+	token, err := s.Seal([]byte("user:42"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	past := New(keys, "session", time.Hour)
+	past.now = func() time.Time { return time.Now().Add(-2 * time.Hour) }
+	expired, _ := past.Seal([]byte("user:42"))
+	tampered := []byte(token)
+	tampered[len(tampered)/2] ^= 1
+
+	tests := []struct {
+		name   string
+		sealer *Sealer
+		token  string
+		want   error
+	}{
+		{"valid", s, token, nil},
+		{"other purpose", New(keys, "csrf", time.Hour), token, ErrInvalid},
+		{"expired", s, expired, ErrExpired},
+		{"tampered", s, string(tampered), ErrInvalid},
+		{"not base64", s, "!!", ErrInvalid},
+		{"unknown key", New(mustKeyring(t, 3), "session", time.Hour), token, ErrInvalid},
+	}
+	for _, tt := range tests {
+		value, err := tt.sealer.Open(tt.token)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: Open = %v, want %v", tt.name, err, tt.want)
+		}
+		if err == nil && string(value) != "user:42" {
+			t.Errorf("%s: Open = %q, want %q", tt.name, value, "user:42")
+		}
+	}
+}
+
+func TestCookieFollowsKeyRotation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	type session struct {
+		UserID uint64 `json:"user_id"`
+	}
+	old := New(newTestKeyring(t, 1), "session", time.Hour).Cookie(CookieConfig{Name: "sid"})
+	rotated := New(newTestKeyring(t, 2), "session", time.Hour).Cookie(CookieConfig{Name: "sid"})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	if err := old.Set(c, session{UserID: 42}); err != nil {
+		t.Fatal(err)
+	}
+	cookie := w.Result().Cookies()[0]
+	if !cookie.HttpOnly || !cookie.Secure || cookie.SameSite != http.SameSiteLaxMode {
+		t.Errorf("cookie flags = HttpOnly %v, Secure %v, SameSite %v", cookie.HttpOnly, cookie.Secure, cookie.SameSite)
+	}
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.AddCookie(cookie)
+	var got session
+	if err := rotated.Get(c, &got); err != nil || got.UserID != 42 {
+		t.Fatalf("Get = %+v, %v; want user 42", got, err)
+	}
+	resealed := w.Result().Cookies()
+	if len(resealed) != 1 || resealed[0].Value == cookie.Value {
+		t.Fatalf("cookie sealed with a rotated-out key was not sealed again")
+	}
+	if _, err := New(mustKeyring(t, 2), "session", time.Hour).Open(resealed[0].Value); err != nil {
+		t.Errorf("resealed cookie does not open with the primary key alone: %v", err)
+	}
+}
+
+func TestFromHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := New(newTestKeyring(t, 1), "device", 0)
+	token, err := s.SealJSON(map[string]string{"device": "d1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, header := range []string{token, "Bearer " + token, "", "Bearer garbage"} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		c.Request.Header.Set("X-Device-Token", header)
+		var got map[string]string
+		err := s.FromHeader(c, "X-Device-Token", &got)
+		if valid := strings.Contains(header, token); valid != (err == nil) {
+			t.Errorf("FromHeader(%.20q) = %v", header, err)
+		}
+		if err != nil && !IsInvalid(err) {
+			t.Errorf("FromHeader(%.20q) = %v, want an invalid token error", header, err)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/config"
+	"github.com/Masharah-Advisory/common/telemetry"
+)
+
+// Config holds the cluster settings; Elasticsearch 7+/8 and OpenSearch share the REST API used here
+type Config struct {
+	Addresses []string // e.g. http://opensearch:9200, requests rotate across them
+	Username  string
+	Password  string
+	APIKey    string // Elasticsearch API key, used instead of basic auth when set
+	// Prefix is prepended to every index name so environments can share a cluster, e.g. "staging_"
+	Prefix  string
+	Timeout time.Duration // defaults to 10s
+}
+
+// ConfigFromEnv reads SEARCH_URL (comma separated), SEARCH_USERNAME, SEARCH_PASSWORD,
+// SEARCH_API_KEY and SEARCH_INDEX_PREFIX
+func ConfigFromEnv() *Config {
+	var addrs []string
+	for _, a := range strings.Split(os.Getenv("SEARCH_URL"), ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			addrs = append(addrs, a)
+		}
+	}
+	return &Config{
+		Addresses: addrs,
+		Username:  os.Getenv("SEARCH_USERNAME"),
+		Password:  os.Getenv("SEARCH_PASSWORD"),
+		APIKey:    os.Getenv("SEARCH_API_KEY"),
+		Prefix:    os.Getenv("SEARCH_INDEX_PREFIX"),
+	}
+}
+
+// Client is a small REST client for the search cluster
+type Client struct {
+	cfg  *Config
+	http *http.Client
+	next atomic.Uint32
+}
+
+// NewClient creates a client and registers a readiness validator that pings the cluster
+func NewClient(cfg *Config) (*Client, error) {
+	if len(cfg.Addresses) == 0 {
+		return nil, fmt.Errorf("no search cluster address configured")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	for i, a := range cfg.Addresses {
+		cfg.Addresses[i] = strings.TrimSuffix(a, "/")
+	}
+
+	c := &Client{
+		cfg:  cfg,
+		http: &http.Client{Timeout: cfg.Timeout, Transport: telemetry.Transport(nil)},
+	}
+	config.RegisterValidator("search", c.Ping)
+	return c, nil
+}
+
+// Index returns the physical name of a logical index, applying the prefix
+func (c *Client) Index(name string) string {
+	return c.cfg.Prefix + name
+}
+
+// Ping checks the cluster is reachable
+func (c *Client) Ping(ctx context.Context) error {
+	if err := c.Do(ctx, http.MethodGet, "/", nil, nil); err != nil {
+		return fmt.Errorf("search cluster unreachable: %w", err)
+	}
+	return nil
+}
+
+// Do sends a request with a JSON (or pre-encoded NDJSON []byte) body and decodes the JSON
+// response into out when it is not nil
+func (c *Client) Do(ctx context.Context, method, path string, body, out interface{}) error {
+	resp, err := c.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		data, _ := io.ReadAll(resp.Body)
+		return decodeError(resp.StatusCode, data)
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode search response: %w", err)
+	}
+	return nil
+}
+
+// exists sends a HEAD request and reports whether the resource exists
+func (c *Client) exists(ctx context.Context, path string) (bool, error) {
+	resp, err := c.send(ctx, http.MethodHead, path, nil)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode >= 400:
+		return false, decodeError(resp.StatusCode, nil)
+	default:
+		return true, nil
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	contentType := "application/json"
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		reader = bytes.NewReader(b)
+		contentType = "application/x-ndjson"
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal search request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	addr := c.cfg.Addresses[int(c.next.Add(1))%len(c.cfg.Addresses)]
+	req, err := http.NewRequestWithContext(ctx, method, addr+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create search request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case c.cfg.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+c.cfg.APIKey)
+	case c.cfg.Username != "":
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, apperror.Wrap(err, "search_unavailable", apperror.KindUnavailable, "search cluster request failed")
+	}
+	return resp, nil
+}
+
+// decodeError turns a cluster error response into an application error
+func decodeError(status int, body []byte) error {
+	var payload struct {
+		Error struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(body, &payload)
+
+	code := "search_error"
+	if payload.Error.Type != "" {
+		code = payload.Error.Type
+	}
+	msg := payload.Error.Reason
+	if msg == "" {
+		msg = http.StatusText(status)
+	}
+	return apperror.Newf(code, apperror.KindFromStatus(status), "search request failed [%d]: %s", status, msg).
+		WithMeta("status", status)
+}
@@ -0,0 +1,208 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Analyzer names defined by ArabicAnalysis
+const (
+	AnalyzerArabic       = "arabic_text"
+	AnalyzerAutocomplete = "autocomplete"
+	AnalyzerSearch       = "autocomplete_search"
+)
+
+// ArabicAnalysis is the analysis block used by every index: Arabic normalisation (hamza forms,
+// taa marbuta, diacritics), Arabic-Indic digits, stop words and light stemming, plus an
+// edge n-gram analyzer for type-ahead in Arabic and English
+func ArabicAnalysis() map[string]interface{} {
+	return map[string]interface{}{
+		"filter": map[string]interface{}{
+			"arabic_stop":    map[string]interface{}{"type": "stop", "stopwords": "_arabic_"},
+			"arabic_stemmer": map[string]interface{}{"type": "stemmer", "language": "arabic"},
+			"edge_ngram":     map[string]interface{}{"type": "edge_ngram", "min_gram": 2, "max_gram": 20},
+		},
+		"analyzer": map[string]interface{}{
+			AnalyzerArabic: map[string]interface{}{
+				"tokenizer": "standard",
+				"filter":    []string{"lowercase", "decimal_digit", "arabic_stop", "arabic_normalization", "arabic_stemmer"},
+			},
+			AnalyzerAutocomplete: map[string]interface{}{
+				"tokenizer": "standard",
+				"filter":    []string{"lowercase", "decimal_digit", "arabic_normalization", "edge_ngram"},
+			},
+			AnalyzerSearch: map[string]interface{}{
+				"tokenizer": "standard",
+				"filter":    []string{"lowercase", "decimal_digit", "arabic_normalization"},
+			},
+		},
+	}
+}
+
+// IndexDefinition describes an index; Mappings is usually built with MappingFor
+type IndexDefinition struct {
+	Name     string // logical name, the client prefix is added
+	Shards   int    // defaults to 1
+	Replicas int
+	Mappings map[string]interface{}
+}
+
+// EnsureIndex creates the index with the Arabic analysis settings when it does not exist yet.
+// Existing indexes are left untouched; mapping changes need a new index and Reindex.
+func (c *Client) EnsureIndex(ctx context.Context, def IndexDefinition) error {
+	name := c.Index(def.Name)
+	ok, err := c.exists(ctx, "/"+name)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return nil
+	}
+	return c.createIndex(ctx, name, def)
+}
+
+func (c *Client) createIndex(ctx context.Context, name string, def IndexDefinition) error {
+	shards := def.Shards
+	if shards <= 0 {
+		shards = 1
+	}
+	body := map[string]interface{}{
+		"settings": map[string]interface{}{
+			"number_of_shards":   shards,
+			"number_of_replicas": def.Replicas,
+			"analysis":           ArabicAnalysis(),
+		},
+	}
+	if def.Mappings != nil {
+		body["mappings"] = def.Mappings
+	}
+	if err := c.Do(ctx, http.MethodPut, "/"+name, body, nil); err != nil {
+		return fmt.Errorf("failed to create index %s: %w", name, err)
+	}
+	return nil
+}
+
+// Reindex builds a fresh versioned index (<name>_<timestamp>) with def, copies the documents of
+// the current one and points the <name> alias at it, for mapping changes without downtime.
+// The first run migrates a plain index of the same name to the alias layout.
+func (c *Client) Reindex(ctx context.Context, def IndexDefinition) (string, error) {
+	alias := c.Index(def.Name)
+	target := fmt.Sprintf("%s_%d", alias, time.Now().Unix())
+	if err := c.createIndex(ctx, target, def); err != nil {
+		return "", err
+	}
+
+	var current []string
+	var aliases map[string]interface{}
+	if err := c.Do(ctx, http.MethodGet, "/_alias/"+alias, nil, &aliases); err == nil {
+		for index := range aliases {
+			current = append(current, index)
+		}
+	}
+	plain := false
+	if len(current) == 0 {
+		if ok, _ := c.exists(ctx, "/"+alias); ok {
+			current, plain = []string{alias}, true
+		}
+	}
+
+	if len(current) > 0 {
+		body := map[string]interface{}{
+			"source": map[string]interface{}{"index": current},
+			"dest":   map[string]interface{}{"index": target},
+		}
+		if err := c.Do(ctx, http.MethodPost, "/_reindex?wait_for_completion=true&refresh=true", body, nil); err != nil {
+			return "", fmt.Errorf("failed to reindex into %s: %w", target, err)
+		}
+	}
+
+	actions := []interface{}{}
+	if plain {
+		// An alias cannot share the name of an index, so the old index goes in the same call
+		actions = append(actions, map[string]interface{}{"remove_index": map[string]interface{}{"index": alias}})
+	} else {
+		for _, index := range current {
+			actions = append(actions, map[string]interface{}{"remove": map[string]interface{}{"index": index, "alias": alias}})
+		}
+	}
+	actions = append(actions, map[string]interface{}{"add": map[string]interface{}{"index": target, "alias": alias}})
+	if err := c.Do(ctx, http.MethodPost, "/_aliases", map[string]interface{}{"actions": actions}, nil); err != nil {
+		return "", fmt.Errorf("failed to switch alias %s: %w", alias, err)
+	}
+	return target, nil
+}
+
+// DeleteIndex removes an index
+func (c *Client) DeleteIndex(ctx context.Context, name string) error {
+	return c.Do(ctx, http.MethodDelete, "/"+c.Index(name), nil, nil)
+}
+
+// Refresh makes recent writes searchable, mostly for tests
+func (c *Client) Refresh(ctx context.Context, name string) error {
+	return c.Do(ctx, http.MethodPost, "/"+c.Index(name)+"/_refresh", nil, nil)
+}
+
+// MappingFor builds mappings from `search:"type"` tags on T's fields, using json names:
+//
+//	text          Arabic-aware full text with a keyword sub-field for sorting
+//	autocomplete  full text with edge n-grams for type-ahead
+//	keyword, date, long, double, boolean, object
+//
+// Untagged fields are left to dynamic mapping; `search:"-"` disables indexing of the field.
+func MappingFor[T any]() map[string]interface{} {
+	var zero T
+	t := reflect.TypeOf(zero)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return map[string]interface{}{"properties": properties(t)}
+}
+
+func properties(t reflect.Type) map[string]interface{} {
+	props := make(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		json, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if f.Anonymous && json == "" && f.Type.Kind() == reflect.Struct {
+			for k, v := range properties(f.Type) {
+				props[k] = v
+			}
+			continue
+		}
+		if json == "-" {
+			continue
+		}
+		if json == "" {
+			json = f.Name
+		}
+
+		switch kind := f.Tag.Get("search"); kind {
+		case "":
+		case "-":
+			props[json] = map[string]interface{}{"type": "object", "enabled": false}
+		case "text":
+			props[json] = map[string]interface{}{
+				"type":     "text",
+				"analyzer": AnalyzerArabic,
+				"fields":   map[string]interface{}{"raw": map[string]interface{}{"type": "keyword", "ignore_above": 256}},
+			}
+		case "autocomplete":
+			props[json] = map[string]interface{}{
+				"type":            "text",
+				"analyzer":        AnalyzerAutocomplete,
+				"search_analyzer": AnalyzerSearch,
+				"fields":          map[string]interface{}{"raw": map[string]interface{}{"type": "keyword", "ignore_above": 256}},
+			}
+		default:
+			props[json] = map[string]interface{}{"type": kind}
+		}
+	}
+	return props
+}
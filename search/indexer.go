@@ -0,0 +1,220 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/Masharah-Advisory/common/events"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Indexer keeps an index in sync with records of type T
+type Indexer[T any] struct {
+	client *Client
+	index  string
+	// ID returns the document ID, defaults to the ID field
+	ID func(doc *T) string
+	// Document returns what is indexed, or false to remove the record from the index (e.g. when
+	// soft-deleted); defaults to the record itself, minus soft-deleted ones
+	Document func(doc *T) (interface{}, bool)
+}
+
+// NewIndexer creates an indexer for the logical index name
+func NewIndexer[T any](client *Client, index string) *Indexer[T] {
+	return &Indexer[T]{client: client, index: index, ID: defaultID[T], Document: defaultDocument[T]}
+}
+
+// Index writes documents with one bulk request
+func (ix *Indexer[T]) Index(ctx context.Context, docs ...*T) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, doc := range docs {
+		id := ix.ID(doc)
+		body, keep := ix.Document(doc)
+		if !keep {
+			_ = enc.Encode(map[string]interface{}{"delete": map[string]string{"_id": id}})
+			continue
+		}
+		_ = enc.Encode(map[string]interface{}{"index": map[string]string{"_id": id}})
+		if err := enc.Encode(body); err != nil {
+			return fmt.Errorf("failed to encode document %s: %w", id, err)
+		}
+	}
+	return ix.bulk(ctx, buf.Bytes())
+}
+
+// Delete removes documents by ID
+func (ix *Indexer[T]) Delete(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, id := range ids {
+		_ = enc.Encode(map[string]interface{}{"delete": map[string]string{"_id": id}})
+	}
+	return ix.bulk(ctx, buf.Bytes())
+}
+
+// bulk sends an NDJSON body and reports the first item failure; deleting missing documents is fine
+func (ix *Indexer[T]) bulk(ctx context.Context, body []byte) error {
+	var resp struct {
+		Errors bool                                `json:"errors"`
+		Items  []map[string]map[string]interface{} `json:"items"`
+	}
+	if err := ix.client.Do(ctx, http.MethodPost, "/"+ix.client.Index(ix.index)+"/_bulk", body, &resp); err != nil {
+		return fmt.Errorf("bulk request to %s failed: %w", ix.index, err)
+	}
+	if !resp.Errors {
+		return nil
+	}
+	for _, item := range resp.Items {
+		for action, result := range item {
+			status, _ := result["status"].(float64)
+			if action == "delete" && status == http.StatusNotFound {
+				continue
+			}
+			if result["error"] != nil {
+				return fmt.Errorf("failed to %s document %v in %s: %v", action, result["_id"], ix.index, result["error"])
+			}
+		}
+	}
+	return nil
+}
+
+// Backfill indexes every row of T in batches, e.g. after Reindex or when adding the index
+func (ix *Indexer[T]) Backfill(ctx context.Context, db *gorm.DB, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	total := 0
+	var batch []*T
+	err := db.WithContext(ctx).Model(new(T)).FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		if err := ix.Index(ctx, batch...); err != nil {
+			return err
+		}
+		total += len(batch)
+		return nil
+	}).Error
+	return total, err
+}
+
+// GORMHooks keeps the index in sync with creates, updates and deletes of T made through db.
+// Indexing errors are logged and never fail the write; run Backfill to repair drift.
+func (ix *Indexer[T]) GORMHooks(db *gorm.DB) error {
+	modelType := reflect.TypeOf(new(T)).Elem()
+	name := "search:" + ix.index
+	cb := db.Callback()
+
+	sync := func(remove bool) func(tx *gorm.DB) {
+		return func(tx *gorm.DB) {
+			if tx.Error != nil || tx.Statement.Schema == nil || tx.Statement.Schema.ModelType != modelType {
+				return
+			}
+			docs := collect[T](tx.Statement.ReflectValue)
+			if len(docs) == 0 {
+				return
+			}
+
+			ctx := tx.Statement.Context
+			var err error
+			if remove {
+				ids := make([]string, 0, len(docs))
+				for _, d := range docs {
+					if id := ix.ID(d); id != "" && id != "0" {
+						ids = append(ids, id)
+					}
+				}
+				err = ix.Delete(ctx, ids...)
+			} else {
+				err = ix.Index(ctx, docs...)
+			}
+			if err != nil {
+				logger.Module("search").Error("failed to sync search index", zap.String("index", ix.index), zap.Error(err))
+			}
+		}
+	}
+
+	if err := cb.Create().After("gorm:create").Register(name+":create", sync(false)); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register(name+":update", sync(false)); err != nil {
+		return err
+	}
+	return cb.Delete().After("gorm:delete").Register(name+":delete", sync(true))
+}
+
+// EventHandler indexes the record carried by each event's payload; events whose type ends in
+// ".deleted" remove it instead. Subscribe it to the topic the owning service publishes to.
+func (ix *Indexer[T]) EventHandler() events.Handler {
+	return func(ctx context.Context, event *events.Event) error {
+		var doc T
+		if err := event.Decode(&doc); err != nil {
+			return events.Permanent(err)
+		}
+		if strings.HasSuffix(event.Type, ".deleted") {
+			return ix.Delete(ctx, ix.ID(&doc))
+		}
+		return ix.Index(ctx, &doc)
+	}
+}
+
+// collect returns the records a GORM statement operated on
+func collect[T any](v reflect.Value) []*T {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if doc, ok := v.Addr().Interface().(*T); ok {
+			return []*T{doc}
+		}
+	case reflect.Slice, reflect.Array:
+		docs := make([]*T, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			docs = append(docs, collect[T](v.Index(i))...)
+		}
+		return docs
+	}
+	return nil
+}
+
+// defaultID reads the ID field, which model.Base provides
+func defaultID[T any](doc *T) string {
+	f := reflect.ValueOf(doc).Elem().FieldByName("ID")
+	if !f.IsValid() {
+		return ""
+	}
+	switch f.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(f.Uint(), 10)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(f.Int(), 10)
+	default:
+		return fmt.Sprint(f.Interface())
+	}
+}
+
+// defaultDocument indexes the record unless its DeletedAt is set
+func defaultDocument[T any](doc *T) (interface{}, bool) {
+	f := reflect.ValueOf(doc).Elem().FieldByName("DeletedAt")
+	if f.IsValid() && f.Kind() == reflect.Ptr && !f.IsNil() {
+		return nil, false
+	}
+	return doc, true
+}
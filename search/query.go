@@ -0,0 +1,235 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// Query builds a bool query with pagination
+type Query struct {
+	must      []interface{}
+	filter    []interface{}
+	mustNot   []interface{}
+	sort      []interface{}
+	highlight []string
+	page      int
+	limit     int
+}
+
+// NewQuery starts a query on page 1 with 20 results
+func NewQuery() *Query {
+	return &Query{page: 1, limit: 20}
+}
+
+// Match adds a full-text clause over fields (boost with "name^3"); empty text is ignored so
+// search boxes can pass their value directly
+func (q *Query) Match(text string, fields ...string) *Query {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return q
+	}
+	mm := map[string]interface{}{"query": text, "type": "best_fields", "fuzziness": "AUTO", "operator": "and"}
+	if len(fields) > 0 {
+		mm["fields"] = fields
+	}
+	q.must = append(q.must, map[string]interface{}{"multi_match": mm})
+	return q
+}
+
+// Prefix adds a type-ahead clause for fields mapped as autocomplete
+func (q *Query) Prefix(text string, fields ...string) *Query {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return q
+	}
+	q.must = append(q.must, map[string]interface{}{"multi_match": map[string]interface{}{
+		"query": text, "fields": fields, "operator": "and",
+	}})
+	return q
+}
+
+// Term filters on an exact value; nil and empty strings are ignored
+func (q *Query) Term(field string, value interface{}) *Query {
+	if value == nil || value == "" {
+		return q
+	}
+	q.filter = append(q.filter, map[string]interface{}{"term": map[string]interface{}{field: value}})
+	return q
+}
+
+// Terms filters on any of values
+func (q *Query) Terms(field string, values ...interface{}) *Query {
+	if len(values) == 0 {
+		return q
+	}
+	q.filter = append(q.filter, map[string]interface{}{"terms": map[string]interface{}{field: values}})
+	return q
+}
+
+// Range filters field between gte and lte; nil bounds are open
+func (q *Query) Range(field string, gte, lte interface{}) *Query {
+	r := map[string]interface{}{}
+	if gte != nil {
+		r["gte"] = gte
+	}
+	if lte != nil {
+		r["lte"] = lte
+	}
+	if len(r) > 0 {
+		q.filter = append(q.filter, map[string]interface{}{"range": map[string]interface{}{field: r}})
+	}
+	return q
+}
+
+// Not excludes documents where field equals value
+func (q *Query) Not(field string, value interface{}) *Query {
+	q.mustNot = append(q.mustNot, map[string]interface{}{"term": map[string]interface{}{field: value}})
+	return q
+}
+
+// Tenant restricts results to one tenant (the tenant_id field); empty means no restriction
+func (q *Query) Tenant(tenantID string) *Query {
+	return q.Term("tenant_id", tenantID)
+}
+
+// Sort orders by field; text fields mapped with MappingFor sort on their "<field>.raw" sub-field
+func (q *Query) Sort(field string, desc bool) *Query {
+	order := "asc"
+	if desc {
+		order = "desc"
+	}
+	q.sort = append(q.sort, map[string]interface{}{field: map[string]interface{}{"order": order}})
+	return q
+}
+
+// Highlight returns highlighted fragments for fields
+func (q *Query) Highlight(fields ...string) *Query {
+	q.highlight = append(q.highlight, fields...)
+	return q
+}
+
+// Page sets the page (1-based) and page size, capped at 100
+func (q *Query) Page(page, limit int) *Query {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	q.page, q.limit = page, limit
+	return q
+}
+
+// Build returns the request body
+func (q *Query) Build() map[string]interface{} {
+	boolQuery := map[string]interface{}{}
+	if len(q.must) > 0 {
+		boolQuery["must"] = q.must
+	}
+	if len(q.filter) > 0 {
+		boolQuery["filter"] = q.filter
+	}
+	if len(q.mustNot) > 0 {
+		boolQuery["must_not"] = q.mustNot
+	}
+
+	body := map[string]interface{}{
+		"from":             (q.page - 1) * q.limit,
+		"size":             q.limit,
+		"track_total_hits": true,
+	}
+	if len(boolQuery) > 0 {
+		body["query"] = map[string]interface{}{"bool": boolQuery}
+	} else {
+		body["query"] = map[string]interface{}{"match_all": map[string]interface{}{}}
+	}
+	if len(q.sort) > 0 {
+		body["sort"] = q.sort
+	}
+	if len(q.highlight) > 0 {
+		fields := make(map[string]interface{}, len(q.highlight))
+		for _, f := range q.highlight {
+			fields[f] = map[string]interface{}{}
+		}
+		body["highlight"] = map[string]interface{}{"fields": fields}
+	}
+	return body
+}
+
+// QueryFromRequest reads the q, page and limit query parameters and the tenant of the request
+func QueryFromRequest(c *gin.Context, fields ...string) *Query {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	return NewQuery().
+		Match(c.Query("q"), fields...).
+		Tenant(ctxutil.TenantID(c)).
+		Page(page, limit)
+}
+
+// Hit is one search result
+type Hit[T any] struct {
+	ID         string              `json:"id"`
+	Score      float64             `json:"score"`
+	Source     T                   `json:"source"`
+	Highlights map[string][]string `json:"highlights,omitempty"`
+}
+
+// Result is a page of results
+type Result[T any] struct {
+	Hits  []Hit[T]
+	Total int64
+	Page  int
+	Limit int
+}
+
+// Items returns the documents of the hits
+func (r *Result[T]) Items() []T {
+	items := make([]T, len(r.Hits))
+	for i, h := range r.Hits {
+		items[i] = h.Source
+	}
+	return items
+}
+
+// Paginated returns the standard paginated payload of the documents
+func (r *Result[T]) Paginated() gin.H {
+	return dto.BuildPaginatedResponse(r.Items(), r.Total, r.Page, r.Limit)
+}
+
+// Search runs q against the logical index and decodes the hits into T
+func Search[T any](ctx context.Context, c *Client, index string, q *Query) (*Result[T], error) {
+	var resp struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				ID        string              `json:"_id"`
+				Score     float64             `json:"_score"`
+				Source    json.RawMessage     `json:"_source"`
+				Highlight map[string][]string `json:"highlight"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := c.Do(ctx, http.MethodPost, "/"+c.Index(index)+"/_search", q.Build(), &resp); err != nil {
+		return nil, err
+	}
+
+	result := &Result[T]{Total: resp.Hits.Total.Value, Page: q.page, Limit: q.limit, Hits: make([]Hit[T], 0, len(resp.Hits.Hits))}
+	for _, h := range resp.Hits.Hits {
+		hit := Hit[T]{ID: h.ID, Score: h.Score, Highlights: h.Highlight}
+		if err := json.Unmarshal(h.Source, &hit.Source); err != nil {
+			return nil, fmt.Errorf("failed to decode search hit %s: %w", h.ID, err)
+		}
+		result.Hits = append(result.Hits, hit)
+	}
+	return result, nil
+}
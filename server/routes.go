@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Fallbacks answers unknown routes with 404 and known routes called with the wrong method with
+// 405 (gin sets the Allow header), both in the standard envelope instead of gin's plain text
+func Fallbacks(engine *gin.Engine) {
+	engine.HandleMethodNotAllowed = true
+	engine.NoRoute(func(c *gin.Context) {
+		response.NotFound(c, i18n.T(c, "error.not_found"))
+	})
+	engine.NoMethod(func(c *gin.Context) {
+		response.Error(c, http.StatusMethodNotAllowed, i18n.T(c, "error.method_not_allowed"))
+	})
+}
+
+// Route is an entry of the route inventory
+type Route struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Handler string `json:"handler"`
+	// Middleware lists the handlers that run before Handler, global middleware first
+	Middleware []string `json:"middleware"`
+}
+
+// Inventory lists every registered route with the middleware it runs, sorted by path, e.g. to
+// check during a security review that admin routes carry the auth middleware
+func (s *Server) Inventory() []Route {
+	routes := s.Engine.Routes()
+	out := make([]Route, 0, len(routes))
+	for _, r := range routes {
+		out = append(out, Route{
+			Method:     r.Method,
+			Path:       r.Path,
+			Handler:    shortName(r.Handler),
+			Middleware: s.middlewareOf(r.Method, r.Path),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Path != out[j].Path {
+			return out[i].Path < out[j].Path
+		}
+		return out[i].Method < out[j].Method
+	})
+	return out
+}
+
+// InventoryHandler serves the route inventory; NewServer mounts it at /routes outside
+// production, mount it behind admin auth to use it there
+func (s *Server) InventoryHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response.OK(c, s.Inventory())
+	}
+}
+
+// probeKey marks the internal requests the inventory routes through the engine
+type probeKey struct{}
+
+type probe struct {
+	path  string
+	names []string
+}
+
+// inventoryProbe is the first global middleware: on inventory requests it records the handler
+// chain gin matched and aborts before anything else runs. It only reacts to the request
+// context, so clients cannot trigger it.
+func inventoryProbe() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if p, ok := c.Request.Context().Value(probeKey{}).(*probe); ok {
+			p.path = c.FullPath()
+			p.names = c.HandlerNames()
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// middlewareOf routes a request with placeholder parameters to the route and returns the names
+// of its middleware, or nil when the request did not reach the route
+func (s *Server) middlewareOf(method, path string) []string {
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			segments[i] = "_"
+		}
+	}
+	p := &probe{}
+	req := httptest.NewRequest(method, strings.Join(segments, "/"), nil)
+	req = req.WithContext(context.WithValue(req.Context(), probeKey{}, p))
+	s.Engine.ServeHTTP(httptest.NewRecorder(), req)
+
+	if p.path != path || len(p.names) < 2 {
+		return nil
+	}
+	// Drop the probe itself and the route handler
+	names := p.names[1 : len(p.names)-1]
+	out := make([]string, len(names))
+	for i, name := range names {
+		out[i] = shortName(name)
+	}
+	return out
+}
+
+var closureSuffix = regexp.MustCompile(`(\.func\d+)+$`)
+
+// shortName strips the closure suffix gin reports for middleware built by constructors
+func shortName(name string) string {
+	return closureSuffix.ReplaceAllString(name, "")
+}
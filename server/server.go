@@ -0,0 +1,262 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Masharah-Advisory/common/buildinfo"
+	"github.com/Masharah-Advisory/common/config"
+	"github.com/Masharah-Advisory/common/i18n"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/middleware"
+	"github.com/Masharah-Advisory/common/preflight"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/Masharah-Advisory/common/shutdown"
+	"github.com/Masharah-Advisory/common/telemetry"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Config holds the server settings; zero values get production-safe defaults
+type Config struct {
+	ServiceID string
+	Addr      string // defaults to :$PORT or :8080
+
+	ReadTimeout       time.Duration // defaults to 15s
+	ReadHeaderTimeout time.Duration // defaults to 5s
+	WriteTimeout      time.Duration // defaults to 30s
+	IdleTimeout       time.Duration // defaults to 120s
+	ShutdownTimeout   time.Duration // drain deadline, defaults to 30s
+
+	AllowedOrigins     []string
+	MaxBodyBytes       int64 // defaults to 10MB, negative disables
+	RateLimitPerMinute int   // 0 disables
+	TrustedProxies     []string
+
+	// Middleware runs after the standard stack
+	Middleware []gin.HandlerFunc
+	// MetricsHandler serves /metrics, defaults to the runtime stats handler. Packages such as
+	// cache, breaker and grpc register their metrics on the default Prometheus registerer;
+	// set gin.WrapH(promhttp.Handler()) to expose them
+	MetricsHandler gin.HandlerFunc
+	// DisableOpsRoutes skips /health, /ready, /version, /metrics and /routes
+	DisableOpsRoutes bool
+	// Shutdown runs the hooks after in-flight requests drain, defaults to shutdown.Default()
+	Shutdown *shutdown.Coordinator
+	// Preflight runs before listening; a failed check with Fail severity aborts Run
+	Preflight *preflight.Checker
+}
+
+// ShutdownHook releases a resource during shutdown
+type ShutdownHook = shutdown.Hook
+
+// Server is a gin engine with the standard middleware stack and graceful shutdown
+type Server struct {
+	*gin.Engine
+	cfg  *Config
+	http *http.Server
+}
+
+// NewServer builds the engine with the standard middleware stack and ops routes
+func NewServer(cfg *Config) *Server {
+	applyDefaults(cfg)
+
+	if config.IsProduction() {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	engine := gin.New()
+	if len(cfg.TrustedProxies) > 0 {
+		if err := engine.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+			logger.Module("server").Warn("invalid trusted proxies", zap.Error(err))
+		}
+	} else {
+		_ = engine.SetTrustedProxies(nil)
+	}
+
+	engine.Use(
+		inventoryProbe(),
+		middleware.RequestIDMiddleware(),
+		telemetry.Middleware(cfg.ServiceID),
+		logger.Middleware(),
+		middleware.RecoveryMiddleware(),
+		middleware.SecurityHeadersMiddleware(),
+		middleware.CorsMiddleware(cfg.AllowedOrigins),
+		i18n.Middleware(),
+		middleware.DebugMiddleware(),
+	)
+	if cfg.MaxBodyBytes > 0 {
+		engine.Use(middleware.RequestSizeLimitMiddleware(cfg.MaxBodyBytes))
+	}
+	if cfg.RateLimitPerMinute > 0 {
+		engine.Use(middleware.RateLimitMiddleware(cfg.RateLimitPerMinute))
+	}
+	engine.Use(cfg.Middleware...)
+
+	Fallbacks(engine)
+
+	s := &Server{
+		Engine: engine,
+		cfg:    cfg,
+		http: &http.Server{
+			Addr:              cfg.Addr,
+			Handler:           engine,
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+		},
+	}
+
+	if !cfg.DisableOpsRoutes {
+		s.mountOpsRoutes()
+	}
+	return s
+}
+
+func applyDefaults(cfg *Config) {
+	if cfg.Addr == "" {
+		port := os.Getenv("PORT")
+		if port == "" {
+			port = "8080"
+		}
+		cfg.Addr = ":" + port
+	}
+	if cfg.ReadTimeout <= 0 {
+		cfg.ReadTimeout = 15 * time.Second
+	}
+	if cfg.ReadHeaderTimeout <= 0 {
+		cfg.ReadHeaderTimeout = 5 * time.Second
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = 30 * time.Second
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = 120 * time.Second
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = 30 * time.Second
+	}
+	if cfg.MaxBodyBytes == 0 {
+		cfg.MaxBodyBytes = 10 << 20
+	}
+	if cfg.MetricsHandler == nil {
+		cfg.MetricsHandler = buildinfo.RuntimeHandler()
+	}
+	if cfg.Shutdown == nil {
+		cfg.Shutdown = shutdown.Default()
+	}
+}
+
+// mountOpsRoutes registers liveness, readiness, version, metrics and, outside production, route
+// inventory endpoints
+func (s *Server) mountOpsRoutes() {
+	s.GET("/health", func(c *gin.Context) {
+		response.OK(c, gin.H{"status": "ok"})
+	})
+	s.GET("/ready", config.ReadinessHandler())
+	s.GET("/version", buildinfo.Handler())
+	s.GET("/metrics", s.cfg.MetricsHandler)
+	if !config.IsProduction() {
+		s.GET("/routes", s.InventoryHandler())
+	}
+}
+
+// OnShutdown registers a hook on the shutdown coordinator, run after in-flight requests have
+// drained; without shutdown.DependsOn hooks run in reverse registration order
+func (s *Server) OnShutdown(name string, fn ShutdownHook, opts ...shutdown.Option) {
+	s.cfg.Shutdown.Register(name, fn, opts...)
+}
+
+// HTTPServer exposes the underlying http.Server for advanced tuning
+func (s *Server) HTTPServer() *http.Server {
+	return s.http
+}
+
+// Run runs the preflight checks, serves until SIGTERM/SIGINT or ctx cancellation, then drains in-flight requests and runs
+// the shutdown hooks
+func (s *Server) Run(ctx context.Context) error {
+	if s.cfg.Preflight != nil {
+		if _, err := s.cfg.Preflight.Run(ctx); err != nil {
+			return fmt.Errorf("preflight failed: %w", err)
+		}
+	}
+	ln, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.http.Addr, err)
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve is Run on an existing listener
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	log := logger.Module("server")
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Info("server listening",
+			zap.String("addr", ln.Addr().String()),
+			zap.String("env", config.Env().String()),
+			zap.String("version", buildinfo.Version),
+		)
+		if err := s.http.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+		close(serveErr)
+	}()
+
+	select {
+	case err := <-serveErr:
+		if err != nil {
+			_ = s.cfg.Shutdown.Shutdown(context.Background())
+			return fmt.Errorf("server failed: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	log.Info("shutting down, draining in-flight requests", zap.Duration("timeout", s.cfg.ShutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	if err := s.http.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, fmt.Errorf("http shutdown: %w", err))
+	}
+	if err := s.cfg.Shutdown.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, err)
+	}
+
+	logger.Sync()
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	log.Info("server stopped")
+	return nil
+}
+
+// CloseDB returns a hook closing the GORM connection pool
+func CloseDB(db *gorm.DB) ShutdownHook {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	}
+}
+
+// Closer adapts an io.Closer-style Close func into a hook
+func Closer(close func() error) ShutdownHook {
+	return shutdown.Closer(close)
+}
@@ -0,0 +1,85 @@
+package settings
+
+import (
+	"encoding/json"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Handlers exposes the settings of the tenant on the context
+type Handlers struct {
+	store *Store
+}
+
+// NewHandlers creates the handlers
+func NewHandlers(store *Store) *Handlers {
+	return &Handlers{store: store}
+}
+
+// Register mounts the public endpoint on a router group, e.g. /settings
+func (h *Handlers) Register(rg *gin.RouterGroup) {
+	rg.GET("", h.Public)
+}
+
+// RegisterAdmin mounts the management endpoints; protect the group with a permission
+func (h *Handlers) RegisterAdmin(rg *gin.RouterGroup) {
+	rg.GET("", h.List)
+	rg.PUT("/:key", h.Set)
+	rg.DELETE("/:key", h.Reset)
+}
+
+// Public returns the public settings as a name to value object
+func (h *Handlers) Public(c *gin.Context) {
+	values, err := h.store.Effective(c.Request.Context(), ctxutil.TenantID(c), true)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	out := make(map[string]json.RawMessage, len(values))
+	for _, v := range values {
+		out[v.Name] = v.Value
+	}
+	c.Header("Cache-Control", "public, max-age=60")
+	response.OK(c, out)
+}
+
+// List returns every setting with its definition and the tenant's value
+func (h *Handlers) List(c *gin.Context) {
+	values, err := h.store.Effective(c.Request.Context(), ctxutil.TenantID(c), false)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if values == nil {
+		values = []Value{}
+	}
+	response.OK(c, values)
+}
+
+// Set stores the tenant's override from a {"value": ...} body
+func (h *Handlers) Set(c *gin.Context) {
+	var body struct {
+		Value json.RawMessage `json:"value" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.BadRequest(c, i18n.T(c, "settings.invalid_request"), response.ProcessBindingError(c, err))
+		return
+	}
+	if err := h.store.SetRaw(c.Request.Context(), ctxutil.TenantID(c), c.Param("key"), body.Value); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+// Reset removes the tenant's override
+func (h *Handlers) Reset(c *gin.Context) {
+	if err := h.store.Reset(c.Request.Context(), ctxutil.TenantID(c), c.Param("key")); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
@@ -0,0 +1,150 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Definition describes a registered setting for admin UIs
+type Definition struct {
+	Name        string          `json:"name"`
+	Type        string          `json:"type"`
+	Group       string          `json:"group,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Default     json.RawMessage `json:"default"`
+	// Public settings are served to front-ends of the tenant, e.g. branding colours
+	Public bool `json:"public"`
+
+	// decode parses and validates a raw override
+	decode func(raw json.RawMessage) (interface{}, error)
+}
+
+// Option adjusts a definition
+type Option func(d *Definition)
+
+// WithGroup groups settings in admin UIs, e.g. "branding" or "limits"
+func WithGroup(group string) Option {
+	return func(d *Definition) {
+		d.Group = group
+	}
+}
+
+// WithDescription documents the setting for administrators
+func WithDescription(description string) Option {
+	return func(d *Definition) {
+		d.Description = description
+	}
+}
+
+// Public exposes the setting on the public endpoint
+func Public() Option {
+	return func(d *Definition) {
+		d.Public = true
+	}
+}
+
+// Key is a typed handle to a registered setting
+type Key[T any] struct {
+	name string
+	def  T
+}
+
+// Name returns the setting name
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+// Default returns the value used when a tenant has no override
+func (k Key[T]) Default() T {
+	return k.def
+}
+
+var (
+	mu          sync.RWMutex
+	definitions = make(map[string]*Definition)
+)
+
+// Define registers a setting with its default and an optional validator; call it from a
+// package-level var so every setting is known at startup, e.g.
+//
+//	var MaxUploadMB = settings.Define("uploads.max_mb", 20, settings.Range(1, 500), settings.WithGroup("limits"))
+//
+// It panics when the name is registered twice.
+func Define[T any](name string, def T, validate func(T) error, opts ...Option) Key[T] {
+	raw, err := json.Marshal(def)
+	if err != nil {
+		panic(fmt.Sprintf("settings: default of %s is not JSON: %v", name, err))
+	}
+	d := &Definition{
+		Name:    name,
+		Type:    reflect.TypeOf(&def).Elem().String(),
+		Default: raw,
+		decode: func(raw json.RawMessage) (interface{}, error) {
+			var v T
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return nil, err
+			}
+			if validate != nil {
+				if err := validate(v); err != nil {
+					return nil, err
+				}
+			}
+			return v, nil
+		},
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := definitions[name]; ok {
+		panic("settings: duplicate setting " + name)
+	}
+	definitions[name] = d
+	return Key[T]{name: name, def: def}
+}
+
+// Range validates that a number lies within [min, max]
+func Range[T int | int64 | float64](min, max T) func(T) error {
+	return func(v T) error {
+		if v < min || v > max {
+			return fmt.Errorf("must be between %v and %v", min, max)
+		}
+		return nil
+	}
+}
+
+// OneOf validates that a string is one of the allowed values
+func OneOf(allowed ...string) func(string) error {
+	return func(v string) error {
+		for _, a := range allowed {
+			if v == a {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %v", allowed)
+	}
+}
+
+// Definitions returns every registered setting sorted by name
+func Definitions() []Definition {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := make([]Definition, 0, len(definitions))
+	for _, d := range definitions {
+		out = append(out, *d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func lookup(name string) (*Definition, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	d, ok := definitions[name]
+	return d, ok
+}
@@ -0,0 +1,218 @@
+package settings
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/cache"
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/localcache"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Errors returned when reading and writing settings
+var (
+	ErrUnknown = apperror.New("setting_unknown", apperror.KindNotFound, "unknown setting").
+			WithKey("settings.unknown")
+	ErrInvalidValue = apperror.New("setting_invalid_value", apperror.KindValidation, "invalid setting value").
+			WithKey("settings.invalid_value")
+)
+
+// Override is a tenant's value for a setting, stored as JSON
+type Override struct {
+	TenantID  string    `json:"tenant_id" gorm:"primaryKey;size:64"`
+	Key       string    `json:"key" gorm:"primaryKey;size:128"`
+	Value     string    `json:"value" gorm:"type:text;not null"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UpdatedBy *uint64   `json:"updated_by,omitempty"`
+}
+
+// TableName overrides the table name
+func (Override) TableName() string {
+	return "tenant_settings"
+}
+
+// Value is the effective value of a setting for a tenant
+type Value struct {
+	Definition
+	Value      json.RawMessage `json:"value"`
+	Overridden bool            `json:"overridden"`
+}
+
+// Config configures a Store
+type Config struct {
+	DB *gorm.DB
+	// Cache holds each tenant's overrides; give it Redis and a Bus so writes on one replica are
+	// seen by all, e.g. cache.Config{Name: "settings", Redis: rdb, Bus: bus, TTL: time.Hour}
+	Cache *cache.Cache[map[string]string]
+	// LocalTTL keeps each tenant's overrides in memory when Cache is nil; writes through this
+	// replica are seen at once, writes elsewhere after LocalTTL
+	LocalTTL time.Duration
+}
+
+// Store reads and writes per-tenant overrides of the registered settings
+type Store struct {
+	cfg   *Config
+	log   *zap.Logger
+	local *localcache.Cache[string, map[string]string]
+}
+
+// NewStore creates a store; migrate Override first
+func NewStore(cfg *Config) *Store {
+	s := &Store{cfg: cfg, log: logger.Module("settings")}
+	if cfg.Cache == nil && cfg.LocalTTL > 0 {
+		s.local = localcache.New[string, map[string]string](localcache.Config{Name: "settings", TTL: cfg.LocalTTL})
+	}
+	return s
+}
+
+var defaultStore *Store
+
+// SetDefault sets the store used by Get and Set
+func SetDefault(s *Store) {
+	defaultStore = s
+}
+
+// Get returns the setting for the tenant on ctx, or its default when the tenant has no
+// override, no store is configured or the store fails (the error is logged)
+func Get[T any](ctx context.Context, key Key[T]) T {
+	if defaultStore == nil {
+		return key.def
+	}
+	v, err := Lookup(ctx, defaultStore, ctxutil.TenantID(ctx), key)
+	if err != nil {
+		defaultStore.log.Warn("failed to read setting, using default", zap.String("key", key.name), zap.Error(err))
+		return key.def
+	}
+	return v
+}
+
+// Set stores an override for the tenant on ctx, attributed to the user on ctx
+func Set[T any](ctx context.Context, key Key[T], value T) error {
+	if defaultStore == nil {
+		return fmt.Errorf("settings: no default store")
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode setting %s: %w", key.name, err)
+	}
+	return defaultStore.SetRaw(ctx, ctxutil.TenantID(ctx), key.name, raw)
+}
+
+// Lookup returns the setting for tenantID from s
+func Lookup[T any](ctx context.Context, s *Store, tenantID string, key Key[T]) (T, error) {
+	overrides, err := s.overrides(ctx, tenantID)
+	if err != nil {
+		return key.def, err
+	}
+	raw, ok := overrides[key.name]
+	if !ok {
+		return key.def, nil
+	}
+	var v T
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return key.def, fmt.Errorf("failed to decode setting %s: %w", key.name, err)
+	}
+	return v, nil
+}
+
+// SetRaw validates and stores a JSON override, e.g. from an admin form
+func (s *Store) SetRaw(ctx context.Context, tenantID, name string, raw json.RawMessage) error {
+	d, ok := lookup(name)
+	if !ok {
+		return ErrUnknown
+	}
+	if _, err := d.decode(raw); err != nil {
+		return ErrInvalidValue.Clone().
+			WithMeta("Key", name).
+			WithMeta("Reason", err.Error())
+	}
+
+	o := &Override{TenantID: tenantID, Key: name, Value: string(raw)}
+	if userID, ok := ctxutil.UserID(ctx); ok {
+		o.UpdatedBy = &userID
+	}
+	err := s.cfg.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at", "updated_by"}),
+	}).Create(o).Error
+	if err != nil {
+		return fmt.Errorf("failed to save setting %s: %w", name, err)
+	}
+	s.invalidate(ctx, tenantID)
+	return nil
+}
+
+// Reset removes the tenant's override so the default applies again
+func (s *Store) Reset(ctx context.Context, tenantID, name string) error {
+	if _, ok := lookup(name); !ok {
+		return ErrUnknown
+	}
+	err := s.cfg.DB.WithContext(ctx).Where("tenant_id = ? AND key = ?", tenantID, name).Delete(&Override{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to reset setting %s: %w", name, err)
+	}
+	s.invalidate(ctx, tenantID)
+	return nil
+}
+
+// Effective returns every registered setting with the tenant's value, for admin UIs;
+// publicOnly limits it to settings marked Public
+func (s *Store) Effective(ctx context.Context, tenantID string, publicOnly bool) ([]Value, error) {
+	overrides, err := s.overrides(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	var out []Value
+	for _, d := range Definitions() {
+		if publicOnly && !d.Public {
+			continue
+		}
+		v := Value{Definition: d, Value: d.Default}
+		if raw, ok := overrides[d.Name]; ok {
+			v.Value, v.Overridden = json.RawMessage(raw), true
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// overrides loads the tenant's overrides through the cache
+func (s *Store) overrides(ctx context.Context, tenantID string) (map[string]string, error) {
+	load := func(ctx context.Context) (map[string]string, error) {
+		var rows []Override
+		if err := s.cfg.DB.WithContext(ctx).Where("tenant_id = ?", tenantID).Find(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to load settings: %w", err)
+		}
+		out := make(map[string]string, len(rows))
+		for _, r := range rows {
+			out[r.Key] = r.Value
+		}
+		return out, nil
+	}
+	if s.local != nil {
+		return s.local.GetOrLoad(tenantID, func() (map[string]string, error) { return load(ctx) })
+	}
+	if s.cfg.Cache == nil {
+		return load(ctx)
+	}
+	return s.cfg.Cache.GetOrLoad(ctx, "tenant:"+tenantID, load)
+}
+
+func (s *Store) invalidate(ctx context.Context, tenantID string) {
+	if s.local != nil {
+		s.local.Delete(tenantID)
+	}
+	if s.cfg.Cache == nil {
+		return
+	}
+	if err := s.cfg.Cache.Delete(ctx, "tenant:"+tenantID); err != nil {
+		s.log.Warn("failed to invalidate settings cache", zap.String("tenant_id", tenantID), zap.Error(err))
+	}
+}
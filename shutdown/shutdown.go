@@ -0,0 +1,269 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"go.uber.org/zap"
+)
+
+// Hook releases a resource; it should return once ctx is done
+type Hook func(ctx context.Context) error
+
+// Config configures a coordinator
+type Config struct {
+	Timeout     time.Duration // deadline for all hooks together, defaults to 30s
+	HookTimeout time.Duration // deadline for a single hook, defaults to 10s
+	Signals     []os.Signal   // signals handled by Wait, defaults to SIGTERM and SIGINT
+}
+
+// Option configures a registered hook
+type Option func(*hook)
+
+// DependsOn declares that the hook uses the named resources, so it runs before their hooks,
+// e.g. Register("worker", m.Stop, DependsOn("db", "redis"))
+func DependsOn(names ...string) Option {
+	return func(h *hook) {
+		h.deps = append(h.deps, names...)
+	}
+}
+
+// Timeout overrides Config.HookTimeout for the hook
+func Timeout(d time.Duration) Option {
+	return func(h *hook) {
+		h.timeout = d
+	}
+}
+
+type hook struct {
+	name    string
+	fn      Hook
+	deps    []string
+	timeout time.Duration
+}
+
+// Coordinator runs shutdown hooks once, in dependency order
+type Coordinator struct {
+	cfg *Config
+
+	mu    sync.Mutex
+	hooks []*hook
+	once  sync.Once
+	err   error
+	done  chan struct{}
+}
+
+// New creates a coordinator
+func New(cfg *Config) *Coordinator {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.HookTimeout <= 0 {
+		cfg.HookTimeout = 10 * time.Second
+	}
+	if len(cfg.Signals) == 0 {
+		cfg.Signals = []os.Signal{syscall.SIGTERM, os.Interrupt}
+	}
+	return &Coordinator{cfg: cfg, done: make(chan struct{})}
+}
+
+// Register adds a hook. Hooks run after every hook that depends on them; otherwise later
+// registrations run first, so resources opened last are closed first. Registering a name
+// again replaces the previous hook.
+func (c *Coordinator) Register(name string, fn Hook, opts ...Option) {
+	h := &hook{name: name, fn: fn}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, existing := range c.hooks {
+		if existing.name == name {
+			c.hooks = append(c.hooks[:i], c.hooks[i+1:]...)
+			break
+		}
+	}
+	c.hooks = append(c.hooks, h)
+}
+
+// Wait blocks until a shutdown signal arrives or ctx is done, then runs the hooks
+func (c *Coordinator) Wait(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, c.cfg.Signals...)
+	<-ctx.Done()
+	stop()
+	logger.Module("shutdown").Info("shutdown signal received")
+	return c.Shutdown(context.Background())
+}
+
+// Shutdown runs the hooks once within Config.Timeout (or ctx's earlier deadline); later calls
+// wait for the first to finish and return its result. Failures are logged and joined.
+func (c *Coordinator) Shutdown(ctx context.Context) error {
+	c.once.Do(func() {
+		defer close(c.done)
+		ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+		defer cancel()
+		c.err = c.run(ctx)
+	})
+	<-c.done
+	return c.err
+}
+
+// Done is closed once Shutdown has finished
+func (c *Coordinator) Done() <-chan struct{} {
+	return c.done
+}
+
+// run executes the hooks in order, giving each its own deadline
+func (c *Coordinator) run(ctx context.Context) error {
+	log := logger.Module("shutdown")
+	c.mu.Lock()
+	hooks := order(c.hooks, log)
+	c.mu.Unlock()
+
+	start := time.Now()
+	var errs []error
+	for _, h := range hooks {
+		if err := c.runHook(ctx, log, h); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		log.Error("shutdown completed with errors", zap.Int("failed", len(errs)), zap.Duration("took", time.Since(start)))
+		return errors.Join(errs...)
+	}
+	log.Info("shutdown completed", zap.Int("hooks", len(hooks)), zap.Duration("took", time.Since(start)))
+	return nil
+}
+
+// runHook runs a single hook, abandoning it when its deadline passes
+func (c *Coordinator) runHook(ctx context.Context, log *zap.Logger, h *hook) error {
+	timeout := h.timeout
+	if timeout <= 0 {
+		timeout = c.cfg.HookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		result <- h.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s: %w", timeout, ctx.Err())
+	}
+
+	if err != nil {
+		log.Error("shutdown hook failed", zap.String("hook", h.name), zap.Duration("took", time.Since(start)), zap.Error(err))
+		return err
+	}
+	log.Info("shutdown hook completed", zap.String("hook", h.name), zap.Duration("took", time.Since(start)))
+	return nil
+}
+
+// order sorts hooks so each runs before the hooks it depends on, preferring later registrations.
+// Unknown dependencies are ignored; a cycle falls back to reverse registration order.
+func order(hooks []*hook, log *zap.Logger) []*hook {
+	index := make(map[string]int, len(hooks))
+	for i, h := range hooks {
+		index[h.name] = i
+	}
+
+	// blockers[i] counts hooks that depend on i and have not run yet
+	blockers := make([]int, len(hooks))
+	for _, h := range hooks {
+		for _, dep := range h.deps {
+			j, ok := index[dep]
+			if !ok {
+				log.Warn("unknown shutdown dependency", zap.String("hook", h.name), zap.String("depends_on", dep))
+				continue
+			}
+			blockers[j]++
+		}
+	}
+
+	ordered := make([]*hook, 0, len(hooks))
+	ran := make([]bool, len(hooks))
+	for len(ordered) < len(hooks) {
+		next := -1
+		for i := len(hooks) - 1; i >= 0; i-- {
+			if !ran[i] && blockers[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			log.Error("shutdown dependency cycle, using reverse registration order for the rest")
+			for i := len(hooks) - 1; i >= 0; i-- {
+				if !ran[i] {
+					ordered = append(ordered, hooks[i])
+				}
+			}
+			break
+		}
+
+		ran[next] = true
+		ordered = append(ordered, hooks[next])
+		for _, dep := range hooks[next].deps {
+			if j, ok := index[dep]; ok {
+				blockers[j]--
+			}
+		}
+	}
+	return ordered
+}
+
+// Closer adapts a Close method into a hook
+func Closer(close func() error) Hook {
+	return func(ctx context.Context) error {
+		return close()
+	}
+}
+
+// Func adapts a function without a result into a hook, e.g. a worker's Stop
+func Func(fn func()) Hook {
+	return func(ctx context.Context) error {
+		fn()
+		return nil
+	}
+}
+
+var defaultCoordinator = New(&Config{})
+
+// Default returns the process-wide coordinator used by the package-level functions and server.Server
+func Default() *Coordinator {
+	return defaultCoordinator
+}
+
+// Register adds a hook to the default coordinator
+func Register(name string, fn Hook, opts ...Option) {
+	defaultCoordinator.Register(name, fn, opts...)
+}
+
+// Wait blocks on the default coordinator until a shutdown signal, then runs its hooks
+func Wait(ctx context.Context) error {
+	return defaultCoordinator.Wait(ctx)
+}
+
+// Shutdown runs the hooks of the default coordinator
+func Shutdown(ctx context.Context) error {
+	return defaultCoordinator.Shutdown(ctx)
+}
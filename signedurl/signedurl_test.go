@@ -0,0 +1,166 @@
+package signedurl
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func newTestSigner(t *testing.T, cfg Config) *Signer {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	if cfg.Secret == nil {
+		cfg.Secret = []byte(strings.Repeat("a", 32))
+	}
+	cfg.Redis = rdb
+	s, err := NewSigner(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestToken(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSigner(t, Config{})
+	token, _, err := s.Token(PurposePasswordReset, "42", WithData("email", "a@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, _, _ := s.Token(PurposePasswordReset, "42", WithTTL(-time.Second))
+	data, sig, _ := strings.Cut(token, ".")
+	forged, _, _ := s.Token(PurposeDownload, "42")
+	_, forgedSig, _ := strings.Cut(forged, ".")
+
+	tests := []struct {
+		name    string
+		purpose string
+		token   string
+		want    error
+	}{
+		{"valid", PurposePasswordReset, token, nil},
+		{"other purpose", PurposeInvitation, token, ErrInvalid},
+		{"expired", PurposePasswordReset, expired, ErrExpired},
+		{"tampered payload", PurposePasswordReset, data + "x." + sig, ErrInvalid},
+		{"signature of another token", PurposePasswordReset, data + "." + forgedSig, ErrInvalid},
+		{"malformed", PurposePasswordReset, "nodot", ErrInvalid},
+	}
+	for _, tt := range tests {
+		c, err := s.Peek(ctx, tt.purpose, tt.token)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: Peek = %v, want %v", tt.name, err, tt.want)
+		}
+		if err == nil && (c.Subject != "42" || c.Data["email"] != "a@example.com") {
+			t.Errorf("%s: claims = %+v", tt.name, c)
+		}
+	}
+}
+
+func TestTokenSecretRotation(t *testing.T) {
+	ctx := context.Background()
+	old := newTestSigner(t, Config{Secret: []byte(strings.Repeat("o", 32))})
+	token, _, _ := old.Token(PurposeDownload, "1")
+
+	rotated := newTestSigner(t, Config{PreviousSecrets: [][]byte{[]byte(strings.Repeat("o", 32))}})
+	if _, err := rotated.Peek(ctx, PurposeDownload, token); err != nil {
+		t.Errorf("token of the previous secret: Peek = %v, want nil", err)
+	}
+	fresh := newTestSigner(t, Config{})
+	if _, err := fresh.Peek(ctx, PurposeDownload, token); !errors.Is(err, ErrInvalid) {
+		t.Errorf("token of an unknown secret: Peek = %v, want %v", err, ErrInvalid)
+	}
+}
+
+func TestOneTimeToken(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSigner(t, Config{})
+	token, claims, err := s.Token(PurposeInvitation, "7", OneTime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Peek(ctx, PurposeInvitation, token); err != nil {
+		t.Fatalf("Peek = %v, want nil", err)
+	}
+
+	// Of concurrent uses exactly one wins
+	var wins atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.Consume(ctx, PurposeInvitation, token); err == nil {
+				wins.Add(1)
+			} else if !errors.Is(err, ErrUsed) {
+				t.Errorf("Consume = %v, want nil or %v", err, ErrUsed)
+			}
+		}()
+	}
+	wg.Wait()
+	if wins.Load() != 1 {
+		t.Errorf("%d concurrent Consume calls succeeded, want 1", wins.Load())
+	}
+
+	revoked, c, _ := s.Token(PurposeInvitation, "7", OneTime())
+	if err := s.Revoke(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Consume(ctx, PurposeInvitation, revoked); !errors.Is(err, ErrUsed) {
+		t.Errorf("Consume of a revoked token = %v, want %v", err, ErrUsed)
+	}
+	if err := s.Revoke(ctx, claims); err != nil {
+		t.Errorf("Revoke of a used token = %v, want nil", err)
+	}
+}
+
+func TestSignedURL(t *testing.T) {
+	s := newTestSigner(t, Config{})
+	signed, err := s.SignURL("https://files.example.com/api/v1/files/9/download?inline=1", PurposeDownload, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.now = func() time.Time { return time.Now().Add(-time.Hour) }
+	expired, _ := s.SignURL("/api/v1/files/9/download", PurposeDownload, time.Minute)
+	s.now = time.Now
+	tamper := func(f func(u *url.URL)) string {
+		u, _ := url.Parse(signed)
+		f(u)
+		return u.String()
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		purpose string
+		want    error
+	}{
+		{"valid", signed, PurposeDownload, nil},
+		{"other host", tamper(func(u *url.URL) { u.Host = "cdn.example.com" }), PurposeDownload, nil},
+		{"other purpose", signed, PurposeUnsubscribe, ErrInvalid},
+		{"other path", tamper(func(u *url.URL) { u.Path = "/api/v1/files/10/download" }), PurposeDownload, ErrInvalid},
+		{"extra query", tamper(func(u *url.URL) { u.RawQuery += "&inline=0" }), PurposeDownload, ErrInvalid},
+		{"extended expiry", tamper(func(u *url.URL) {
+			q := u.Query()
+			q.Set(ExpiresParam, "99999999999")
+			u.RawQuery = q.Encode()
+		}), PurposeDownload, ErrInvalid},
+		{"unsigned", "https://files.example.com/api/v1/files/9/download", PurposeDownload, ErrInvalid},
+		{"expired", expired, PurposeDownload, ErrExpired},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.url)
+		if err := s.VerifyURL(u, tt.purpose); !errors.Is(err, tt.want) {
+			t.Errorf("%s: VerifyURL = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package signedurl
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/crypto"
+)
+
+// Claims is the payload of a token
+type Claims struct {
+	ID      string            `json:"jti"`
+	Purpose string            `json:"p"`
+	Subject string            `json:"sub,omitempty"`
+	Data    map[string]string `json:"d,omitempty"`
+	// OneTime tokens are accepted by Consume only once
+	OneTime   bool  `json:"once,omitempty"`
+	ExpiresAt int64 `json:"exp"`
+}
+
+// Expires returns the expiry time
+func (c *Claims) Expires() time.Time {
+	return time.Unix(c.ExpiresAt, 0)
+}
+
+// Option adjusts the claims of a new token
+type Option func(c *Claims)
+
+// WithTTL overrides the configured lifetime
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Claims) {
+		c.ExpiresAt = time.Now().Add(ttl).Unix()
+	}
+}
+
+// WithData attaches a claim, e.g. the email address being verified
+func WithData(key, value string) Option {
+	return func(c *Claims) {
+		if c.Data == nil {
+			c.Data = make(map[string]string)
+		}
+		c.Data[key] = value
+	}
+}
+
+// OneTime makes the token single-use; the signer needs Redis
+func OneTime() Option {
+	return func(c *Claims) {
+		c.OneTime = true
+	}
+}
+
+// Token issues a token for purpose and subject (usually the user ID), e.g.
+// Token(PurposePasswordReset, "42", OneTime(), WithTTL(30*time.Minute)). The token is
+// "<payload>.<signature>" in URL-safe base64; the payload is signed, not encrypted, so never
+// put secrets in it.
+func (s *Signer) Token(purpose, subject string, opts ...Option) (string, *Claims, error) {
+	id, err := crypto.Token(16)
+	if err != nil {
+		return "", nil, err
+	}
+	c := &Claims{ID: id, Purpose: purpose, Subject: subject, ExpiresAt: s.now().Add(s.cfg.TTL).Unix()}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.OneTime && s.cfg.Redis == nil {
+		return "", nil, fmt.Errorf("one-time tokens require a redis client")
+	}
+
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode token: %w", err)
+	}
+	data := base64.RawURLEncoding.EncodeToString(payload)
+	return data + "." + s.sign(purpose, []byte(data)), c, nil
+}
+
+// Peek verifies the token for purpose without using it up, e.g. to render a password reset
+// form before the new password is submitted
+func (s *Signer) Peek(ctx context.Context, purpose, token string) (*Claims, error) {
+	data, sig, ok := strings.Cut(token, ".")
+	if !ok || !s.verify(purpose, []byte(data), sig) {
+		return nil, ErrInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	var c Claims
+	if err := json.Unmarshal(payload, &c); err != nil || c.Purpose != purpose {
+		return nil, ErrInvalid
+	}
+	if !s.now().Before(c.Expires()) {
+		return nil, ErrExpired
+	}
+	if c.OneTime {
+		if s.cfg.Redis == nil {
+			return nil, fmt.Errorf("one-time tokens require a redis client")
+		}
+		n, err := s.cfg.Redis.Exists(ctx, s.usedKey(c.ID)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check token use: %w", err)
+		}
+		if n > 0 {
+			return nil, ErrUsed
+		}
+	}
+	return &c, nil
+}
+
+// Consume verifies the token for purpose and, when it is one-time, marks it used. Of several
+// concurrent requests with the same token only one succeeds.
+func (s *Signer) Consume(ctx context.Context, purpose, token string) (*Claims, error) {
+	c, err := s.Peek(ctx, purpose, token)
+	if err != nil || !c.OneTime {
+		return c, err
+	}
+	if err := s.markUsed(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Revoke marks a one-time token used before anyone consumes it, e.g. when the user requests a
+// new password reset link
+func (s *Signer) Revoke(ctx context.Context, c *Claims) error {
+	if !c.OneTime {
+		return nil
+	}
+	err := s.markUsed(ctx, c)
+	if err == ErrUsed {
+		return nil
+	}
+	return err
+}
+
+// markUsed is the single-use guard: only the request that creates the key succeeds. The key
+// lives until the token expires, after which expiry rejects it anyway.
+func (s *Signer) markUsed(ctx context.Context, c *Claims) error {
+	ttl := c.Expires().Sub(s.now()) + time.Minute
+	ok, err := s.cfg.Redis.SetNX(ctx, s.usedKey(c.ID), 1, ttl).Result()
+	if err != nil {
+		return fmt.Errorf("failed to mark token used: %w", err)
+	}
+	if !ok {
+		return ErrUsed
+	}
+	return nil
+}
+
+func (s *Signer) usedKey(id string) string {
+	return s.cfg.Prefix + "used:" + id
+}
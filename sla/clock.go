@@ -0,0 +1,122 @@
+package sla
+
+import (
+	"time"
+
+	"github.com/Masharah-Advisory/common/calendar"
+)
+
+// Clock measures SLA time; wall-clock policies count every minute, business policies only
+// working hours
+type Clock interface {
+	// Add returns the instant d of counted time after t
+	Add(t time.Time, d time.Duration) time.Time
+	// Between returns the counted time from a to b, zero when b is not after a
+	Between(a, b time.Time) time.Duration
+}
+
+// WallClock counts elapsed time
+type WallClock struct{}
+
+// Add implements Clock
+func (WallClock) Add(t time.Time, d time.Duration) time.Time { return t.Add(d) }
+
+// Between implements Clock
+func (WallClock) Between(a, b time.Time) time.Duration {
+	if !b.After(a) {
+		return 0
+	}
+	return b.Sub(a)
+}
+
+// BusinessHours counts the daily working window of business days in calendar.Location
+type BusinessHours struct {
+	// Calendar defaults to calendar.Default()
+	Calendar *calendar.Calendar
+	// Open and Close are minutes after midnight (default 08:00 to 17:00)
+	Open, Close int
+}
+
+func (h BusinessHours) defaults() BusinessHours {
+	if h.Calendar == nil {
+		h.Calendar = calendar.Default()
+	}
+	if h.Open == 0 && h.Close == 0 {
+		h.Open, h.Close = 8*60, 17*60
+	}
+	return h
+}
+
+// window returns the working window on t's day, and false on non-business days
+func (h BusinessHours) window(t time.Time) (time.Time, time.Time, bool) {
+	y, m, d := t.In(calendar.Location).Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, calendar.Location)
+	if !h.Calendar.IsBusinessDay(day) {
+		return day, day, false
+	}
+	return day.Add(time.Duration(h.Open) * time.Minute), day.Add(time.Duration(h.Close) * time.Minute), true
+}
+
+// nextDay returns the start of the day after t's
+func nextDay(t time.Time) time.Time {
+	y, m, d := t.In(calendar.Location).Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, calendar.Location)
+}
+
+// Add implements Clock
+func (h BusinessHours) Add(t time.Time, d time.Duration) time.Time {
+	h = h.defaults()
+	if h.Close <= h.Open {
+		return t.Add(d)
+	}
+	for {
+		open, closing, ok := h.window(t)
+		if !ok || !t.Before(closing) {
+			t = nextDay(t)
+			continue
+		}
+		if t.Before(open) {
+			t = open
+		}
+		left := closing.Sub(t)
+		if d <= left {
+			return t.Add(d)
+		}
+		d -= left
+		t = nextDay(t)
+	}
+}
+
+// Between implements Clock
+func (h BusinessHours) Between(a, b time.Time) time.Duration {
+	h = h.defaults()
+	if h.Close <= h.Open {
+		return WallClock{}.Between(a, b)
+	}
+	var total time.Duration
+	for t := a; t.Before(b); t = nextDay(t) {
+		open, closing, ok := h.window(t)
+		if !ok {
+			continue
+		}
+		from, to := maxTime(open, t), minTime(closing, b)
+		if to.After(from) {
+			total += to.Sub(from)
+		}
+	}
+	return total
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
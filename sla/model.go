@@ -0,0 +1,47 @@
+package sla
+
+import (
+	"time"
+
+	"github.com/Masharah-Advisory/common/model"
+)
+
+// Status is the lifecycle state of a timer
+type Status string
+
+const (
+	StatusRunning Status = "running"
+	StatusPaused  Status = "paused"
+	StatusStopped Status = "stopped"
+)
+
+// Timer tracks one SLA for one subject, e.g. the first-response SLA of case 42
+type Timer struct {
+	model.Base
+	Policy     string `json:"policy" gorm:"uniqueIndex:idx_sla_timer_subject;size:128;not null"`
+	Subject    string `json:"subject" gorm:"uniqueIndex:idx_sla_timer_subject;size:191;not null"`
+	TenantID   string `json:"tenant_id,omitempty" gorm:"index;size:64"`
+	AssigneeID uint64 `json:"assignee_id,omitempty" gorm:"index"`
+	Status     Status `json:"status" gorm:"index;size:16;not null"`
+	// Deadline is set while running; Remaining holds the counted time left while paused
+	Deadline  *time.Time    `json:"deadline,omitempty"`
+	Remaining time.Duration `json:"remaining"`
+	StartedAt time.Time     `json:"started_at"`
+	PausedAt  *time.Time    `json:"paused_at,omitempty"`
+	StoppedAt *time.Time    `json:"stopped_at,omitempty"`
+	// WarnedAt, BreachedAt and Escalations record the alerts already raised
+	WarnedAt    *time.Time `json:"warned_at,omitempty"`
+	BreachedAt  *time.Time `json:"breached_at,omitempty"`
+	Escalations int        `json:"escalations"`
+	NextCheckAt *time.Time `json:"-" gorm:"index"`
+}
+
+// TableName overrides the table name
+func (Timer) TableName() string {
+	return "sla_timers"
+}
+
+// Breached reports whether the deadline passed before the timer stopped
+func (t *Timer) Breached() bool {
+	return t.BreachedAt != nil
+}
@@ -0,0 +1,44 @@
+package sla
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masharah-Advisory/common/notify"
+)
+
+// Recipients picks the users to notify for an alert
+type Recipients func(ctx context.Context, alert Alert) ([]uint64, error)
+
+// Assignee notifies the timer's assignee
+func Assignee(_ context.Context, alert Alert) ([]uint64, error) {
+	if alert.Timer.AssigneeID == 0 {
+		return nil, nil
+	}
+	return []uint64{alert.Timer.AssigneeID}, nil
+}
+
+// NotifyHook sends alerts through the notification dispatcher as alert.Event ("sla.warning",
+// "sla.breached" or the escalation's event); register those events on the dispatcher. Their
+// data carries policy, subject, deadline, level and remaining_minutes.
+func NotifyHook(d *notify.Dispatcher, recipients Recipients) Hook {
+	if recipients == nil {
+		recipients = Assignee
+	}
+	return func(ctx context.Context, alert Alert) error {
+		userIDs, err := recipients(ctx, alert)
+		if err != nil || len(userIDs) == 0 {
+			return err
+		}
+		data := map[string]interface{}{
+			"policy":            alert.Timer.Policy,
+			"subject":           alert.Timer.Subject,
+			"level":             alert.Level,
+			"remaining_minutes": int(alert.Timer.Remaining / time.Minute),
+		}
+		if alert.Timer.Deadline != nil {
+			data["deadline"] = alert.Timer.Deadline.Format(time.RFC3339)
+		}
+		return d.NotifyMany(ctx, userIDs, alert.Event, data)
+	}
+}
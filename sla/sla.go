@@ -0,0 +1,370 @@
+package sla
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/worker"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Errors returned by the engine
+var (
+	ErrUnknownPolicy = apperror.New("sla_unknown_policy", apperror.KindBadRequest, "unknown SLA policy").
+				WithKey("sla.unknown_policy")
+	ErrTimerNotFound = apperror.New("sla_timer_not_found", apperror.KindNotFound, "SLA timer not found").
+				WithKey("sla.timer_not_found")
+)
+
+// Escalation raises an alert some counted time after the deadline
+type Escalation struct {
+	After time.Duration
+	// Event is the notification event sent by NotifyHook (default "sla.escalated")
+	Event string
+}
+
+// Policy defines an SLA, e.g. "respond within 4 business hours"
+type Policy struct {
+	Name   string
+	Target time.Duration
+	// BusinessHours counts only working hours of business days; otherwise the clock never stops
+	BusinessHours bool
+	// WarnBefore raises a warning this much counted time before the deadline
+	WarnBefore time.Duration
+	// Escalations run in order after the deadline passes
+	Escalations []Escalation
+}
+
+// Stage is the kind of alert raised for a timer
+type Stage string
+
+const (
+	StageWarning    Stage = "warning"
+	StageBreach     Stage = "breach"
+	StageEscalation Stage = "escalation"
+)
+
+// Alert is passed to the hook when a timer nears or passes its deadline
+type Alert struct {
+	Stage Stage
+	// Level is the 1-based escalation level for StageEscalation
+	Level  int
+	Event  string
+	Policy Policy
+	Timer  *Timer
+}
+
+// Hook reacts to alerts, e.g. NotifyHook; a failing hook is retried on the next check
+type Hook func(ctx context.Context, alert Alert) error
+
+// Config configures an Engine
+type Config struct {
+	DB       *gorm.DB
+	Policies []Policy
+	// Hours is the business clock of BusinessHours policies (default 08:00-17:00 Saudi business days)
+	Hours BusinessHours
+	Hook  Hook
+	// PollInterval is how often Schedule checks for due timers (default 1m)
+	PollInterval time.Duration
+	// BatchSize bounds the timers handled per check (default 100)
+	BatchSize int
+}
+
+// Engine starts, pauses and checks SLA timers
+type Engine struct {
+	cfg *Config
+	log *zap.Logger
+	now func() time.Time
+
+	mu       sync.RWMutex
+	policies map[string]Policy
+}
+
+// New creates an engine; migrate Timer first
+func New(cfg *Config) *Engine {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Minute
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	e := &Engine{cfg: cfg, log: logger.Module("sla"), now: time.Now, policies: make(map[string]Policy)}
+	for _, p := range cfg.Policies {
+		e.Register(p)
+	}
+	return e
+}
+
+// Register adds or replaces a policy
+func (e *Engine) Register(p Policy) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.policies[p.Name] = p
+}
+
+func (e *Engine) policy(name string) (Policy, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	p, ok := e.policies[name]
+	if !ok {
+		return Policy{}, ErrUnknownPolicy
+	}
+	return p, nil
+}
+
+func (e *Engine) clock(p Policy) Clock {
+	if p.BusinessHours {
+		return e.cfg.Hours
+	}
+	return WallClock{}
+}
+
+// Start starts the policy's timer for subject; starting a running or paused timer returns it
+// unchanged, and a stopped one is restarted
+func (e *Engine) Start(ctx context.Context, policy, subject string, assigneeID uint64, tenantID string) (*Timer, error) {
+	p, err := e.policy(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	var t Timer
+	res := e.cfg.DB.WithContext(ctx).Where("policy = ? AND subject = ?", policy, subject).Limit(1).Find(&t)
+	if res.Error != nil {
+		return nil, fmt.Errorf("failed to load SLA timer: %w", res.Error)
+	}
+	if res.RowsAffected > 0 && t.Status != StatusStopped {
+		return &t, nil
+	}
+
+	now := e.now()
+	t.Policy, t.Subject, t.TenantID, t.AssigneeID = policy, subject, tenantID, assigneeID
+	t.StartedAt = now
+	t.PausedAt, t.StoppedAt, t.WarnedAt, t.BreachedAt, t.Escalations = nil, nil, nil, nil, 0
+	e.run(&t, p, now, p.Target)
+
+	if err := e.cfg.DB.WithContext(ctx).Save(&t).Error; err != nil {
+		return nil, fmt.Errorf("failed to save SLA timer: %w", err)
+	}
+	return &t, nil
+}
+
+// Pause stops the clock, e.g. while waiting on the client
+func (e *Engine) Pause(ctx context.Context, id uint64) (*Timer, error) {
+	return e.update(ctx, id, func(t *Timer, p Policy, now time.Time) bool {
+		if t.Status != StatusRunning {
+			return false
+		}
+		t.Remaining = e.clock(p).Between(now, *t.Deadline)
+		t.Status, t.PausedAt, t.Deadline, t.NextCheckAt = StatusPaused, &now, nil, nil
+		return true
+	})
+}
+
+// Resume restarts a paused clock with the time it had left
+func (e *Engine) Resume(ctx context.Context, id uint64) (*Timer, error) {
+	return e.update(ctx, id, func(t *Timer, p Policy, now time.Time) bool {
+		if t.Status != StatusPaused {
+			return false
+		}
+		t.PausedAt = nil
+		e.run(t, p, now, t.Remaining)
+		return true
+	})
+}
+
+// Stop ends the timer, e.g. once the case was answered; Breached tells whether the SLA was met
+func (e *Engine) Stop(ctx context.Context, id uint64) (*Timer, error) {
+	return e.update(ctx, id, func(t *Timer, p Policy, now time.Time) bool {
+		if t.Status == StatusStopped {
+			return false
+		}
+		if t.Status == StatusRunning {
+			t.Remaining = e.clock(p).Between(now, *t.Deadline)
+		}
+		t.Status, t.StoppedAt, t.NextCheckAt = StatusStopped, &now, nil
+		return true
+	})
+}
+
+// Timer loads the policy's timer for subject
+func (e *Engine) Timer(ctx context.Context, policy, subject string) (*Timer, error) {
+	var t Timer
+	if err := e.cfg.DB.WithContext(ctx).Where("policy = ? AND subject = ?", policy, subject).First(&t).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTimerNotFound
+		}
+		return nil, fmt.Errorf("failed to load SLA timer: %w", err)
+	}
+	return &t, nil
+}
+
+// run sets the deadline of a timer starting now with remaining counted time
+func (e *Engine) run(t *Timer, p Policy, now time.Time, remaining time.Duration) {
+	deadline := e.clock(p).Add(now, remaining)
+	t.Status, t.Deadline, t.Remaining = StatusRunning, &deadline, remaining
+	t.NextCheckAt = e.nextCheck(t, p, now)
+}
+
+// update applies fn to a timer and saves it when fn reports a change
+func (e *Engine) update(ctx context.Context, id uint64, fn func(t *Timer, p Policy, now time.Time) bool) (*Timer, error) {
+	var t Timer
+	if err := e.cfg.DB.WithContext(ctx).First(&t, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTimerNotFound
+		}
+		return nil, fmt.Errorf("failed to load SLA timer: %w", err)
+	}
+	p, err := e.policy(t.Policy)
+	if err != nil {
+		return nil, err
+	}
+	if !fn(&t, p, e.now()) {
+		return &t, nil
+	}
+	if err := e.cfg.DB.WithContext(ctx).Save(&t).Error; err != nil {
+		return nil, fmt.Errorf("failed to save SLA timer: %w", err)
+	}
+	return &t, nil
+}
+
+// nextCheck returns when the next alert of a running timer is due, given its remaining time at
+// now, or nil when none is left
+func (e *Engine) nextCheck(t *Timer, p Policy, now time.Time) *time.Time {
+	var at time.Time
+	switch {
+	case t.WarnedAt == nil && t.BreachedAt == nil && p.WarnBefore > 0:
+		at = now
+		if t.Remaining > p.WarnBefore {
+			at = e.clock(p).Add(at, t.Remaining-p.WarnBefore)
+		}
+	case t.BreachedAt == nil:
+		at = *t.Deadline
+	case t.Escalations < len(p.Escalations):
+		at = e.clock(p).Add(*t.Deadline, p.Escalations[t.Escalations].After)
+	default:
+		return nil
+	}
+	return &at
+}
+
+// Check raises the due alerts of running timers and returns how many timers it handled
+func (e *Engine) Check(ctx context.Context) (int, error) {
+	var ids []uint64
+	err := e.cfg.DB.WithContext(ctx).Model(&Timer{}).
+		Where("status = ? AND next_check_at <= ?", StatusRunning, e.now()).
+		Order("next_check_at").
+		Limit(e.cfg.BatchSize).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load due SLA timers: %w", err)
+	}
+
+	handled := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := e.check(ctx, id); err != nil {
+			e.log.Warn("SLA check failed", zap.Uint64("timer_id", id), zap.Error(err))
+			continue
+		}
+		handled++
+	}
+	return handled, nil
+}
+
+// check claims one due timer, raises its alerts and schedules the next check. A timer claimed
+// by another instance is skipped.
+func (e *Engine) check(ctx context.Context, id uint64) error {
+	now := e.now()
+	// Lease the timer by pushing its next check forward; only one instance wins the update
+	lease := now.Add(e.cfg.PollInterval * 5)
+	res := e.cfg.DB.WithContext(ctx).Model(&Timer{}).
+		Where("id = ? AND status = ? AND next_check_at <= ?", id, StatusRunning, now).
+		Update("next_check_at", lease)
+	if res.Error != nil {
+		return fmt.Errorf("failed to claim SLA timer: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil
+	}
+
+	var t Timer
+	if err := e.cfg.DB.WithContext(ctx).First(&t, id).Error; err != nil {
+		return fmt.Errorf("failed to load SLA timer: %w", err)
+	}
+	p, err := e.policy(t.Policy)
+	if err != nil {
+		return err
+	}
+	clock := e.clock(p)
+	t.Remaining = clock.Between(now, *t.Deadline)
+
+	for {
+		next := e.nextCheck(&t, p, now)
+		if next == nil || next.After(now) {
+			t.NextCheckAt = next
+			break
+		}
+		alert := Alert{Policy: p, Timer: &t}
+		switch {
+		case t.WarnedAt == nil && t.BreachedAt == nil && !now.Before(*t.Deadline):
+			// The warning window was missed entirely; go straight to the breach
+			t.WarnedAt = &now
+			continue
+		case t.WarnedAt == nil && t.BreachedAt == nil:
+			alert.Stage, alert.Event = StageWarning, "sla.warning"
+		case t.BreachedAt == nil:
+			alert.Stage, alert.Event = StageBreach, "sla.breached"
+		default:
+			esc := p.Escalations[t.Escalations]
+			alert.Stage, alert.Level, alert.Event = StageEscalation, t.Escalations+1, esc.Event
+			if alert.Event == "" {
+				alert.Event = "sla.escalated"
+			}
+		}
+
+		if e.cfg.Hook != nil {
+			if err := e.cfg.Hook(ctx, alert); err != nil {
+				// Keep the lease so the alert is retried once it expires
+				t.NextCheckAt = &lease
+				e.log.Warn("SLA hook failed", zap.Uint64("timer_id", id), zap.String("stage", string(alert.Stage)), zap.Error(err))
+				break
+			}
+		}
+		e.log.Info("SLA alert raised",
+			zap.Uint64("timer_id", id),
+			zap.String("policy", t.Policy),
+			zap.String("subject", t.Subject),
+			zap.String("stage", string(alert.Stage)),
+			zap.Int("level", alert.Level),
+		)
+		switch alert.Stage {
+		case StageWarning:
+			t.WarnedAt = &now
+		case StageBreach:
+			t.BreachedAt = &now
+		case StageEscalation:
+			t.Escalations++
+		}
+	}
+
+	if err := e.cfg.DB.WithContext(ctx).Save(&t).Error; err != nil {
+		return fmt.Errorf("failed to save SLA timer: %w", err)
+	}
+	return nil
+}
+
+// Schedule runs Check periodically on the worker manager
+func (e *Engine) Schedule(m *worker.Manager) {
+	m.Every("sla.check", e.cfg.PollInterval, func(ctx context.Context) error {
+		_, err := e.Check(ctx)
+		return err
+	}, worker.JobOptions{})
+}
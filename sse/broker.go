@@ -0,0 +1,333 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/idgen"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// Config holds the broker settings
+type Config struct {
+	// Redis fans events out to every replica and stores the replay history; nil keeps both local
+	Redis   *redis.Client
+	Channel string // pub/sub channel, defaults to "sse:events"
+	Prefix  string // history key prefix, defaults to "sse:history:"
+
+	KeepAlive time.Duration // comment sent on idle connections, defaults to 15s
+	Buffer    int           // queued events per client, defaults to 32
+	// History is the number of events kept per stream for Last-Event-ID resume, 0 disables it
+	History    int
+	HistoryTTL time.Duration // defaults to 1h
+	// Retry is the reconnect delay suggested to browsers, defaults to 3s
+	Retry time.Duration
+
+	// Authorize decides whether the user may follow a topic (?topic=a&topic=b); nil denies topics
+	Authorize func(c *gin.Context, userID uint64, topic string) bool
+}
+
+// client is one open stream
+type client struct {
+	userID   uint64
+	tenantID string
+	streams  []string
+	events   chan *Event
+	done     chan struct{}
+	once     sync.Once
+}
+
+func (c *client) close() {
+	c.once.Do(func() { close(c.done) })
+}
+
+// Broker serves event streams and routes events to the connected clients of this replica
+type Broker struct {
+	cfg     *Config
+	log     *zap.Logger
+	history history
+
+	mu      sync.RWMutex
+	streams map[string]map[*client]struct{}
+	clients map[*client]struct{}
+}
+
+// NewBroker creates a broker; call Run to receive events published by other replicas
+func NewBroker(cfg *Config) *Broker {
+	if cfg.Channel == "" {
+		cfg.Channel = "sse:events"
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = "sse:history:"
+	}
+	if cfg.KeepAlive <= 0 {
+		cfg.KeepAlive = 15 * time.Second
+	}
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 32
+	}
+	if cfg.HistoryTTL <= 0 {
+		cfg.HistoryTTL = time.Hour
+	}
+	if cfg.Retry <= 0 {
+		cfg.Retry = 3 * time.Second
+	}
+
+	b := &Broker{
+		cfg:     cfg,
+		log:     logger.Module("sse"),
+		streams: make(map[string]map[*client]struct{}),
+		clients: make(map[*client]struct{}),
+	}
+	if cfg.History > 0 {
+		if cfg.Redis != nil {
+			b.history = &redisHistory{rdb: cfg.Redis, prefix: cfg.Prefix, size: cfg.History, ttl: cfg.HistoryTTL}
+		} else {
+			b.history = &memoryHistory{size: cfg.History, streams: make(map[string][]*Event)}
+		}
+	}
+	return b
+}
+
+// Handler streams the events of the authenticated user, their tenant broadcasts and the
+// authorized ?topic= parameters. Mount it behind middleware.AuthMiddleware.
+func (b *Broker) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := ctxutil.UserID(c)
+		if !ok {
+			response.Unauthorized(c, i18n.T(c, "user_id_not_found"))
+			c.Abort()
+			return
+		}
+
+		tenantID := ctxutil.TenantID(c)
+		streams := []string{userStream(userID), tenantStream("")}
+		if tenantID != "" {
+			streams = append(streams, tenantStream(tenantID))
+		}
+		for _, topic := range c.QueryArray("topic") {
+			if topic == "" {
+				continue
+			}
+			if b.cfg.Authorize == nil || !b.cfg.Authorize(c, userID, topic) {
+				response.Forbidden(c, i18n.T(c, "insufficient_permissions")+": "+topic)
+				c.Abort()
+				return
+			}
+			streams = append(streams, topicStream(topic))
+		}
+
+		cl := &client{
+			userID:   userID,
+			tenantID: tenantID,
+			streams:  streams,
+			events:   make(chan *Event, b.cfg.Buffer),
+			done:     make(chan struct{}),
+		}
+		b.register(cl)
+		defer b.unregister(cl)
+
+		w := c.Writer
+		// Streams outlive the server's WriteTimeout
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+		header := w.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		header.Set("X-Accel-Buffering", "no") // disable proxy buffering (nginx)
+		w.WriteHeader(http.StatusOK)
+
+		// Suggest the reconnect delay, then replay what was missed since the last connection
+		fmt.Fprintf(w, "retry: %d\n\n", b.cfg.Retry.Milliseconds())
+		lastID := c.GetHeader("Last-Event-ID")
+		if lastID == "" {
+			lastID = c.Query("last_event_id")
+		}
+		if lastID != "" && b.history != nil {
+			for _, e := range b.replay(c.Request.Context(), streams, lastID) {
+				if err := e.writeTo(w); err != nil {
+					return
+				}
+			}
+		}
+		w.Flush()
+
+		ticker := time.NewTicker(b.cfg.KeepAlive)
+		defer ticker.Stop()
+		ctx := c.Request.Context()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-cl.done:
+				return
+			case e := <-cl.events:
+				if err := e.writeTo(w); err != nil {
+					return
+				}
+				w.Flush()
+			case <-ticker.C:
+				if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+					return
+				}
+				w.Flush()
+			}
+		}
+	}
+}
+
+// Run delivers events published by any replica until ctx is done. Without Redis it only waits.
+func (b *Broker) Run(ctx context.Context) error {
+	if b.cfg.Redis == nil {
+		<-ctx.Done()
+		return nil
+	}
+
+	sub := b.cfg.Redis.Subscribe(ctx, b.cfg.Channel)
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", b.cfg.Channel, err)
+	}
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case m, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			var env envelope
+			if err := json.Unmarshal([]byte(m.Payload), &env); err != nil {
+				b.log.Warn("invalid sse envelope", zap.Error(err))
+				continue
+			}
+			b.deliver(env.Stream, env.Event)
+		}
+	}
+}
+
+// SendToUser sends e to every stream of userID on any replica
+func (b *Broker) SendToUser(ctx context.Context, userID uint64, e *Event) error {
+	return b.publish(ctx, userStream(userID), e)
+}
+
+// Publish sends e to the followers of topic
+func (b *Broker) Publish(ctx context.Context, topic string, e *Event) error {
+	return b.publish(ctx, topicStream(topic), e)
+}
+
+// Broadcast sends e to every connected user of tenantID, or to everyone when it is empty
+func (b *Broker) Broadcast(ctx context.Context, tenantID string, e *Event) error {
+	return b.publish(ctx, tenantStream(tenantID), e)
+}
+
+// Connections returns the number of open streams on this replica
+func (b *Broker) Connections() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.clients)
+}
+
+// Shutdown ends every open stream; browsers reconnect to another replica and resume
+func (b *Broker) Shutdown(ctx context.Context) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for c := range b.clients {
+		c.close()
+	}
+	return nil
+}
+
+// publish assigns a sortable ID, records the event for replay and fans it out
+func (b *Broker) publish(ctx context.Context, stream string, e *Event) error {
+	if e.ID == "" {
+		e.ID = idgen.NewULIDString()
+	}
+	if b.history != nil {
+		if err := b.history.Append(ctx, stream, e); err != nil {
+			b.log.Warn("failed to record sse history", zap.String("stream", stream), zap.Error(err))
+		}
+	}
+
+	if b.cfg.Redis == nil {
+		b.deliver(stream, e)
+		return nil
+	}
+	raw, err := json.Marshal(envelope{Stream: stream, Event: e})
+	if err != nil {
+		return fmt.Errorf("failed to encode sse event: %w", err)
+	}
+	if err := b.cfg.Redis.Publish(ctx, b.cfg.Channel, raw).Err(); err != nil {
+		return fmt.Errorf("failed to publish sse event: %w", err)
+	}
+	return nil
+}
+
+// deliver queues e for the local clients of stream, dropping clients that fall behind
+func (b *Broker) deliver(stream string, e *Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for c := range b.streams[stream] {
+		select {
+		case c.events <- e:
+		default:
+			b.log.Warn("dropping slow sse client", zap.Uint64("user_id", c.userID))
+			c.close()
+		}
+	}
+}
+
+// replay collects the missed events of all streams in ID order
+func (b *Broker) replay(ctx context.Context, streams []string, lastID string) []*Event {
+	var events []*Event
+	for _, stream := range streams {
+		missed, err := b.history.Since(ctx, stream, lastID)
+		if err != nil {
+			b.log.Warn("failed to read sse history", zap.String("stream", stream), zap.Error(err))
+			continue
+		}
+		events = append(events, missed...)
+	}
+	sort.Slice(events, func(i, j int) bool { return strings.Compare(events[i].ID, events[j].ID) < 0 })
+	return events
+}
+
+func (b *Broker) register(c *client) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clients[c] = struct{}{}
+	for _, s := range c.streams {
+		if b.streams[s] == nil {
+			b.streams[s] = make(map[*client]struct{})
+		}
+		b.streams[s][c] = struct{}{}
+	}
+}
+
+func (b *Broker) unregister(c *client) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.clients, c)
+	for _, s := range c.streams {
+		delete(b.streams[s], c)
+		if len(b.streams[s]) == 0 {
+			delete(b.streams, s)
+		}
+	}
+	c.close()
+}
@@ -0,0 +1,63 @@
+package sse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Event is one server-sent event
+type Event struct {
+	ID    string          `json:"id"`
+	Event string          `json:"event,omitempty"`
+	Data  json.RawMessage `json:"data"`
+	// Retry tells the browser how long to wait before reconnecting, in milliseconds
+	Retry int `json:"retry,omitempty"`
+}
+
+// NewEvent builds an event of the given type, encoding data as JSON
+func NewEvent(event string, data interface{}) (*Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event data: %w", err)
+	}
+	return &Event{Event: event, Data: raw}, nil
+}
+
+// writeTo writes the event in the text/event-stream format
+func (e *Event) writeTo(w io.Writer) error {
+	var b strings.Builder
+	if e.ID != "" {
+		b.WriteString("id: " + e.ID + "\n")
+	}
+	if e.Event != "" {
+		b.WriteString("event: " + e.Event + "\n")
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", e.Retry)
+	}
+	// Compact JSON has no newlines, but guard against pre-formatted payloads
+	for _, line := range strings.Split(string(e.Data), "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// envelope is what replicas exchange over Redis
+type envelope struct {
+	Stream string `json:"stream"`
+	Event  *Event `json:"event"`
+}
+
+// Stream names
+func userStream(userID uint64) string { return fmt.Sprintf("user:%d", userID) }
+func topicStream(topic string) string { return "topic:" + topic }
+func tenantStream(tenantID string) string {
+	if tenantID == "" {
+		return "all"
+	}
+	return "tenant:" + tenantID
+}
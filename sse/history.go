@@ -0,0 +1,89 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// history keeps the most recent events per stream so reconnecting clients can resume from
+// Last-Event-ID
+type history interface {
+	Append(ctx context.Context, stream string, e *Event) error
+	// Since returns the events of stream newer than lastID, oldest first
+	Since(ctx context.Context, stream, lastID string) ([]*Event, error)
+}
+
+// redisHistory shares the replay buffer between replicas
+type redisHistory struct {
+	rdb    *redis.Client
+	prefix string
+	size   int
+	ttl    time.Duration
+}
+
+func (h *redisHistory) Append(ctx context.Context, stream string, e *Event) error {
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	key := h.prefix + stream
+	pipe := h.rdb.TxPipeline()
+	pipe.LPush(ctx, key, raw)
+	pipe.LTrim(ctx, key, 0, int64(h.size-1))
+	pipe.Expire(ctx, key, h.ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (h *redisHistory) Since(ctx context.Context, stream, lastID string) ([]*Event, error) {
+	items, err := h.rdb.LRange(ctx, h.prefix+stream, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	var events []*Event
+	// Newest first in the list; walk backwards for chronological order
+	for i := len(items) - 1; i >= 0; i-- {
+		var e Event
+		if err := json.Unmarshal([]byte(items[i]), &e); err != nil {
+			continue
+		}
+		if e.ID > lastID {
+			events = append(events, &e)
+		}
+	}
+	return events, nil
+}
+
+// memoryHistory is the single-replica fallback
+type memoryHistory struct {
+	mu      sync.Mutex
+	size    int
+	streams map[string][]*Event
+}
+
+func (h *memoryHistory) Append(ctx context.Context, stream string, e *Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	events := append(h.streams[stream], e)
+	if len(events) > h.size {
+		events = events[len(events)-h.size:]
+	}
+	h.streams[stream] = events
+	return nil
+}
+
+func (h *memoryHistory) Since(ctx context.Context, stream, lastID string) ([]*Event, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var events []*Event
+	for _, e := range h.streams[stream] {
+		if e.ID > lastID {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+)
+
+// Config holds S3/MinIO connection and bucket settings
+type Config struct {
+	Endpoint  string // e.g. "s3.me-south-1.amazonaws.com" or "minio:9000"
+	AccessKey string
+	SecretKey string
+	Region    string
+	Bucket    string
+	UseSSL    bool
+
+	// Encryption enables server-side encryption: "" (none), "AES256" (SSE-S3) or "aws:kms"
+	Encryption string
+	KMSKeyID   string
+
+	MaxSize      int64  // maximum object size in bytes, 0 means unlimited
+	PartSize     uint64 // multipart part size, defaults to 16 MiB
+	CreateBucket bool   // create the bucket at startup when missing
+}
+
+// S3Storage implements Storage on top of any S3 compatible service
+type S3Storage struct {
+	client *minio.Client
+	cfg    *Config
+	sse    encrypt.ServerSide
+}
+
+// NewS3 creates an S3/MinIO backed storage
+func NewS3(cfg *Config) (*S3Storage, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage client: %w", err)
+	}
+
+	s := &S3Storage{client: client, cfg: cfg}
+
+	switch strings.ToLower(cfg.Encryption) {
+	case "":
+	case "aes256", "sse-s3":
+		s.sse = encrypt.NewSSE()
+	case "aws:kms", "sse-kms":
+		s.sse, err = encrypt.NewSSEKMS(cfg.KMSKeyID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid KMS configuration: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported storage encryption: %s", cfg.Encryption)
+	}
+
+	if cfg.CreateBucket {
+		ctx := context.Background()
+		exists, err := client.BucketExists(ctx, cfg.Bucket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check bucket %s: %w", cfg.Bucket, err)
+		}
+		if !exists {
+			if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+				return nil, fmt.Errorf("failed to create bucket %s: %w", cfg.Bucket, err)
+			}
+			log.Printf("[COMMON] Storage bucket %s created", cfg.Bucket)
+		}
+	}
+
+	return s, nil
+}
+
+// Put implements Storage
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, opts PutOptions) (*Object, error) {
+	r, err := enforceLimit(r, size, s.cfg.MaxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType, r = DetectContentType(key, r)
+	}
+
+	partSize := s.cfg.PartSize
+	if partSize == 0 {
+		partSize = 16 << 20
+	}
+
+	info, err := s.client.PutObject(ctx, s.cfg.Bucket, key, r, size, minio.PutObjectOptions{
+		ContentType:          contentType,
+		ContentDisposition:   opts.ContentDisposition,
+		CacheControl:         opts.CacheControl,
+		UserMetadata:         opts.Metadata,
+		ServerSideEncryption: s.sse,
+		PartSize:             partSize,
+	})
+	if err != nil {
+		if errors.Is(err, ErrTooLarge) {
+			return nil, ErrTooLarge
+		}
+		return nil, fmt.Errorf("failed to upload object %s: %w", key, err)
+	}
+
+	return &Object{
+		Key:          key,
+		Size:         info.Size,
+		ContentType:  contentType,
+		ETag:         info.ETag,
+		LastModified: info.LastModified,
+		Metadata:     opts.Metadata,
+	}, nil
+}
+
+// Get implements Storage; the caller must close the returned reader
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	obj, err := s.client.GetObject(ctx, s.cfg.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, nil, s.translateError(key, err)
+	}
+
+	info, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, nil, s.translateError(key, err)
+	}
+
+	return obj, toObject(info), nil
+}
+
+// Stat implements Storage
+func (s *S3Storage) Stat(ctx context.Context, key string) (*Object, error) {
+	info, err := s.client.StatObject(ctx, s.cfg.Bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, s.translateError(key, err)
+	}
+	return toObject(info), nil
+}
+
+// Delete implements Storage
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	if err := s.client.RemoveObject(ctx, s.cfg.Bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return s.translateError(key, err)
+	}
+	return nil
+}
+
+// PresignGet implements Storage
+func (s *S3Storage) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	u, err := s.client.PresignedGetObject(ctx, s.cfg.Bucket, key, expiry, url.Values{})
+	if err != nil {
+		return "", fmt.Errorf("failed to presign download for %s: %w", key, err)
+	}
+	return u.String(), nil
+}
+
+// PresignPut implements Storage
+func (s *S3Storage) PresignPut(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	u, err := s.client.PresignedPutObject(ctx, s.cfg.Bucket, key, expiry)
+	if err != nil {
+		return "", fmt.Errorf("failed to presign upload for %s: %w", key, err)
+	}
+	return u.String(), nil
+}
+
+// PresignGetAs presigns a download that forces the given file name and content type
+func (s *S3Storage) PresignGetAs(ctx context.Context, key, fileName, contentType string, expiry time.Duration) (string, error) {
+	params := url.Values{}
+	if fileName != "" {
+		params.Set("response-content-disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	}
+	if contentType != "" {
+		params.Set("response-content-type", contentType)
+	}
+
+	u, err := s.client.PresignedGetObject(ctx, s.cfg.Bucket, key, expiry, params)
+	if err != nil {
+		return "", fmt.Errorf("failed to presign download for %s: %w", key, err)
+	}
+	return u.String(), nil
+}
+
+// translateError maps S3 "not found" responses to ErrNotFound
+func (s *S3Storage) translateError(key string, err error) error {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NotFound":
+		return ErrNotFound
+	}
+	return fmt.Errorf("storage operation on %s failed: %w", key, err)
+}
+
+func toObject(info minio.ObjectInfo) *Object {
+	return &Object{
+		Key:          info.Key,
+		Size:         info.Size,
+		ContentType:  info.ContentType,
+		ETag:         info.ETag,
+		LastModified: info.LastModified,
+		Metadata:     info.UserMetadata,
+	}
+}
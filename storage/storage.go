@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+)
+
+// Object describes a stored object
+type Object struct {
+	Key          string            `json:"key"`
+	Size         int64             `json:"size"`
+	ContentType  string            `json:"content_type"`
+	ETag         string            `json:"etag,omitempty"`
+	LastModified time.Time         `json:"last_modified,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+}
+
+// PutOptions customizes an upload
+type PutOptions struct {
+	ContentType        string
+	ContentDisposition string
+	CacheControl       string
+	Metadata           map[string]string
+}
+
+// Storage is implemented by every object storage backend
+type Storage interface {
+	// Put uploads r under key; size may be -1 when unknown (the upload is then streamed in parts)
+	Put(ctx context.Context, key string, r io.Reader, size int64, opts PutOptions) (*Object, error)
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+	Stat(ctx context.Context, key string) (*Object, error)
+	Delete(ctx context.Context, key string) error
+	PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error)
+	PresignPut(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// Errors returned by storage backends
+var (
+	ErrNotFound = apperror.New("object_not_found", apperror.KindNotFound, "object not found").
+			WithKey("storage.not_found")
+	ErrTooLarge = apperror.New("object_too_large", apperror.KindTooLarge, "object exceeds the maximum allowed size").
+			WithKey("storage.too_large")
+	ErrUnsupportedType = apperror.New("unsupported_content_type", apperror.KindBadRequest, "content type is not allowed").
+				WithKey("storage.unsupported_type")
+)
+
+// DetectContentType determines the content type from the first bytes of r, falling back to the
+// key's extension. It returns a reader that still yields the full content.
+func DetectContentType(key string, r io.Reader) (string, io.Reader) {
+	br := bufio.NewReaderSize(r, 512)
+	head, _ := br.Peek(512)
+
+	contentType := http.DetectContentType(head)
+	if contentType == "application/octet-stream" || strings.HasPrefix(contentType, "text/plain") {
+		if byExt := mime.TypeByExtension(strings.ToLower(path.Ext(key))); byExt != "" {
+			contentType = byExt
+		}
+	}
+	return contentType, br
+}
+
+// limitedReader fails with ErrTooLarge once more than max bytes have been read
+type limitedReader struct {
+	r   io.Reader
+	max int64
+	n   int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	if l.n > l.max {
+		return n, ErrTooLarge
+	}
+	return n, err
+}
+
+// enforceLimit returns ErrTooLarge for known sizes above max and guards streams of unknown size
+func enforceLimit(r io.Reader, size, max int64) (io.Reader, error) {
+	if max <= 0 {
+		return r, nil
+	}
+	if size > max {
+		return nil, ErrTooLarge
+	}
+	return &limitedReader{r: r, max: max}, nil
+}
+
+// IsNotFound reports whether err means the object does not exist
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// UploadOptions controls how a multipart upload is streamed to storage
+type UploadOptions struct {
+	Field        string   // multipart form field, defaults to "file"
+	KeyPrefix    string   // e.g. "avatars/42/"
+	AllowedTypes []string // exact types or wildcards like "image/*"; empty allows everything
+	MaxSize      int64    // per-upload limit in bytes, 0 means the storage limit only
+	Metadata     map[string]string
+}
+
+// ErrMissingFile is returned when the request has no part for the upload field
+var ErrMissingFile = apperror.New("missing_file", apperror.KindBadRequest, "no file was uploaded").
+	WithKey("storage.missing_file")
+
+// Upload streams the file part of a multipart request straight to storage without buffering it
+// in memory or on disk. The object key is KeyPrefix + a random ID + the original extension.
+func Upload(c *gin.Context, store Storage, opts UploadOptions) (*Object, error) {
+	field := opts.Field
+	if field == "" {
+		field = "file"
+	}
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, apperror.Wrap(err, "invalid_multipart", apperror.KindBadRequest, "invalid multipart request")
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, ErrMissingFile
+		}
+		if err != nil {
+			return nil, apperror.Wrap(err, "invalid_multipart", apperror.KindBadRequest, "invalid multipart request")
+		}
+
+		if part.FormName() != field || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		obj, err := putPart(c, store, part.FileName(), part, opts)
+		part.Close()
+		return obj, err
+	}
+}
+
+// putPart validates and uploads a single multipart file part
+func putPart(c *gin.Context, store Storage, fileName string, r io.Reader, opts UploadOptions) (*Object, error) {
+	contentType, r := DetectContentType(fileName, r)
+	if !typeAllowed(contentType, opts.AllowedTypes) {
+		return nil, ErrUnsupportedType.Clone().WithMeta("ContentType", contentType)
+	}
+
+	if opts.MaxSize > 0 {
+		r = &limitedReader{r: r, max: opts.MaxSize}
+	}
+
+	metadata := map[string]string{"original-name": path.Base(fileName)}
+	for k, v := range opts.Metadata {
+		metadata[k] = v
+	}
+
+	key := opts.KeyPrefix + uuid.New().String() + strings.ToLower(path.Ext(fileName))
+	obj, err := store.Put(c.Request.Context(), key, r, -1, PutOptions{
+		ContentType: contentType,
+		Metadata:    metadata,
+	})
+	if err != nil {
+		if errors.Is(err, ErrTooLarge) {
+			return nil, ErrTooLarge
+		}
+		return nil, fmt.Errorf("failed to store upload: %w", err)
+	}
+	return obj, nil
+}
+
+// typeAllowed matches a content type against exact and "type/*" patterns
+func typeAllowed(contentType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	base := strings.TrimSpace(strings.Split(contentType, ";")[0])
+	for _, pattern := range allowed {
+		if pattern == base {
+			return true
+		}
+		if strings.HasSuffix(pattern, "/*") && strings.HasPrefix(base, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// UploadHandler returns a handler that stores the uploaded file and responds 201 with the object
+func UploadHandler(store Storage, opts UploadOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		obj, err := Upload(c, store, opts)
+		if err != nil {
+			response.HandleError(c, err)
+			return
+		}
+		response.Created(c, obj)
+	}
+}
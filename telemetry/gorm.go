@@ -0,0 +1,90 @@
+package telemetry
+
+import (
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "telemetry:span"
+
+// GORMPlugin creates a client span per GORM operation; register it with db.Use(telemetry.GORMPlugin{})
+type GORMPlugin struct {
+	// IncludeVars adds bound parameters to db.query.text; leave off when queries carry personal data
+	IncludeVars bool
+}
+
+// Name implements gorm.Plugin
+func (GORMPlugin) Name() string { return "telemetry" }
+
+// Initialize implements gorm.Plugin
+func (p GORMPlugin) Initialize(db *gorm.DB) error {
+	tracer := Tracer("gorm")
+	cb := db.Callback()
+
+	type hooks struct {
+		before func(name string, fn func(*gorm.DB)) error
+		after  func(name string, fn func(*gorm.DB)) error
+		op     string
+	}
+	all := []hooks{
+		{cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register, "INSERT"},
+		{cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register, "SELECT"},
+		{cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register, "UPDATE"},
+		{cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register, "DELETE"},
+		{cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register, "ROW"},
+		{cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register, "RAW"},
+	}
+
+	for _, h := range all {
+		op := h.op
+		if err := h.before("telemetry:before_"+op, func(tx *gorm.DB) {
+			ctx := tx.Statement.Context
+			if !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+				// Only trace queries that belong to a request or job trace
+				return
+			}
+			name := op
+			if tx.Statement.Table != "" {
+				name += " " + tx.Statement.Table
+			}
+			ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.DBSystemNamePostgreSQL,
+					semconv.DBOperationName(op),
+					semconv.DBCollectionName(tx.Statement.Table),
+				),
+			)
+			tx.Statement.Context = ctx
+			tx.InstanceSet(gormSpanKey, span)
+		}); err != nil {
+			return err
+		}
+
+		if err := h.after("telemetry:after_"+op, func(tx *gorm.DB) {
+			v, ok := tx.InstanceGet(gormSpanKey)
+			if !ok {
+				return
+			}
+			span := v.(trace.Span)
+			defer span.End()
+
+			query := tx.Statement.SQL.String()
+			if p.IncludeVars {
+				query = tx.Dialector.Explain(query, tx.Statement.Vars...)
+			}
+			span.SetAttributes(
+				semconv.DBQueryText(query),
+				semconv.DBResponseReturnedRows(int(tx.RowsAffected)),
+			)
+			if tx.Error != nil && tx.Error != gorm.ErrRecordNotFound {
+				span.RecordError(tx.Error)
+				span.SetStatus(codes.Error, tx.Error.Error())
+			}
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
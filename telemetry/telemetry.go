@@ -0,0 +1,280 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/buildinfo"
+	"github.com/Masharah-Advisory/common/config"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"go.opentelemetry.io/contrib/bridges/otelzap"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Protocol is the OTLP transport
+type Protocol string
+
+const (
+	ProtocolHTTP Protocol = "http/protobuf"
+	ProtocolGRPC Protocol = "grpc"
+)
+
+// Config controls the exporters; ConfigFromEnv fills it from the standard OTEL_* variables
+type Config struct {
+	ServiceName string
+	Version     string
+	Env         config.Environment
+
+	Endpoint string // host:port of the collector, empty disables export
+	Protocol Protocol
+	Insecure bool
+	Headers  map[string]string
+
+	// SampleRatio is the fraction of new traces recorded (parent decisions are always honoured)
+	SampleRatio float64
+
+	Traces  bool
+	Metrics bool
+	Logs    bool
+
+	MetricInterval time.Duration // defaults to 30s
+	Attributes     map[string]string
+}
+
+// ShutdownFunc flushes and stops the exporters
+type ShutdownFunc func(ctx context.Context) error
+
+// ConfigFromEnv reads OTEL_SERVICE_NAME, OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_EXPORTER_OTLP_PROTOCOL,
+// OTEL_EXPORTER_OTLP_INSECURE, OTEL_EXPORTER_OTLP_HEADERS, OTEL_TRACES_SAMPLER_ARG,
+// OTEL_SDK_DISABLED and OTEL_LOGS_ENABLED. Sampling defaults to 10% in production and 100% elsewhere.
+func ConfigFromEnv() Config {
+	cfg := Config{
+		ServiceName: firstNonEmpty(os.Getenv("OTEL_SERVICE_NAME"), os.Getenv("SERVICE_ID")),
+		Version:     buildinfo.Get().Version,
+		Env:         config.Env(),
+		Endpoint:    os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		Protocol:    Protocol(firstNonEmpty(os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"), string(ProtocolHTTP))),
+		Insecure:    os.Getenv("OTEL_EXPORTER_OTLP_INSECURE") == "true",
+		Headers:     parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")),
+		SampleRatio: 1,
+		Traces:      true,
+		Metrics:     true,
+		Logs:        os.Getenv("OTEL_LOGS_ENABLED") == "true",
+	}
+	if config.IsProduction() {
+		cfg.SampleRatio = 0.1
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("OTEL_TRACES_SAMPLER_ARG"), 64); err == nil {
+		cfg.SampleRatio = v
+	}
+	if os.Getenv("OTEL_SDK_DISABLED") == "true" {
+		cfg.Endpoint = ""
+	}
+	return cfg
+}
+
+// Init installs the global tracer, meter and logger providers and the W3C propagators. Without an
+// endpoint only the propagators are installed, so instrumentation stays cheap no-ops.
+func Init(ctx context.Context, cfg Config) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if cfg.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	if cfg.Env == "" {
+		cfg.Env = config.Env()
+	}
+	if cfg.Version == "" {
+		cfg.Version = buildinfo.Get().Version
+	}
+	if cfg.MetricInterval <= 0 {
+		cfg.MetricInterval = 30 * time.Second
+	}
+	endpoint := strings.TrimPrefix(strings.TrimPrefix(cfg.Endpoint, "https://"), "http://")
+
+	res, err := newResource(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var shutdowns []ShutdownFunc
+	shutdown := func(ctx context.Context) error {
+		var errs []error
+		for i := len(shutdowns) - 1; i >= 0; i-- {
+			errs = append(errs, shutdowns[i](ctx))
+		}
+		return errors.Join(errs...)
+	}
+	fail := func(err error) (ShutdownFunc, error) {
+		_ = shutdown(ctx)
+		return nil, err
+	}
+
+	if cfg.Traces {
+		exporter, err := newTraceExporter(ctx, cfg, endpoint)
+		if err != nil {
+			return fail(fmt.Errorf("failed to create trace exporter: %w", err))
+		}
+		tp := sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(res),
+			sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		)
+		otel.SetTracerProvider(tp)
+		shutdowns = append(shutdowns, tp.Shutdown)
+	}
+
+	if cfg.Metrics {
+		exporter, err := newMetricExporter(ctx, cfg, endpoint)
+		if err != nil {
+			return fail(fmt.Errorf("failed to create metric exporter: %w", err))
+		}
+		mp := sdkmetric.NewMeterProvider(
+			sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(cfg.MetricInterval))),
+			sdkmetric.WithResource(res),
+		)
+		otel.SetMeterProvider(mp)
+		shutdowns = append(shutdowns, mp.Shutdown)
+	}
+
+	if cfg.Logs {
+		opts := []otlploghttp.Option{otlploghttp.WithEndpoint(endpoint), otlploghttp.WithHeaders(cfg.Headers)}
+		if cfg.Insecure {
+			opts = append(opts, otlploghttp.WithInsecure())
+		}
+		exporter, err := otlploghttp.New(ctx, opts...)
+		if err != nil {
+			return fail(fmt.Errorf("failed to create log exporter: %w", err))
+		}
+		lp := sdklog.NewLoggerProvider(
+			sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+			sdklog.WithResource(res),
+		)
+		global.SetLoggerProvider(lp)
+		logger.Tee(otelzap.NewCore(cfg.ServiceName, otelzap.WithLoggerProvider(lp)))
+		shutdowns = append(shutdowns, lp.Shutdown)
+	}
+
+	logger.Module("telemetry").Info("telemetry initialized",
+		zap.String("endpoint", endpoint),
+		zap.String("protocol", string(cfg.Protocol)),
+		zap.Float64("sample_ratio", cfg.SampleRatio),
+	)
+	return shutdown, nil
+}
+
+// Tracer returns a named tracer from the global provider
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer("github.com/Masharah-Advisory/common/" + name)
+}
+
+// Meter returns a named meter from the global provider
+func Meter(name string) metric.Meter {
+	return otel.Meter("github.com/Masharah-Advisory/common/" + name)
+}
+
+// TraceID returns the trace id of the span in ctx, or "" when there is none
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
+
+func newResource(ctx context.Context, cfg Config) (*resource.Resource, error) {
+	info := buildinfo.Get()
+	host, _ := os.Hostname()
+	attrs := []attribute.KeyValue{
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.ServiceVersion(cfg.Version),
+		semconv.DeploymentEnvironmentName(cfg.Env.String()),
+		semconv.HostName(host),
+		semconv.VCSRefHeadRevision(info.Commit),
+	}
+	for k, v := range cfg.Attributes {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attrs...),
+		resource.WithFromEnv(),
+		resource.WithProcess(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build telemetry resource: %w", err)
+	}
+	return res, nil
+}
+
+func newTraceExporter(ctx context.Context, cfg Config, endpoint string) (sdktrace.SpanExporter, error) {
+	if cfg.Protocol == ProtocolGRPC {
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint), otlptracegrpc.WithHeaders(cfg.Headers)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	}
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint), otlptracehttp.WithHeaders(cfg.Headers)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	return otlptracehttp.New(ctx, opts...)
+}
+
+func newMetricExporter(ctx context.Context, cfg Config, endpoint string) (sdkmetric.Exporter, error) {
+	if cfg.Protocol == ProtocolGRPC {
+		opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(endpoint), otlpmetricgrpc.WithHeaders(cfg.Headers)}
+		if cfg.Insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+		return otlpmetricgrpc.New(ctx, opts...)
+	}
+	opts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(endpoint), otlpmetrichttp.WithHeaders(cfg.Headers)}
+	if cfg.Insecure {
+		opts = append(opts, otlpmetrichttp.WithInsecure())
+	}
+	return otlpmetrichttp.New(ctx, opts...)
+}
+
+// parseHeaders parses "key=value,key2=value2"
+func parseHeaders(s string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if k, v, ok := strings.Cut(pair, "="); ok {
+			headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return headers
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,177 @@
+package templates
+
+import (
+	"strconv"
+
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/notify"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Handlers exposes template management to content teams
+type Handlers struct {
+	store *Store
+}
+
+// NewHandlers creates the handlers
+func NewHandlers(store *Store) *Handlers {
+	return &Handlers{store: store}
+}
+
+// RegisterAdmin mounts the management endpoints; protect the group with a permission
+func (h *Handlers) RegisterAdmin(rg *gin.RouterGroup) {
+	rg.GET("", h.List)
+	rg.POST("", h.Create)
+	rg.POST("/preview", h.Preview)
+	rg.GET("/:id", h.Get)
+	rg.PUT("/:id", h.Update)
+	rg.DELETE("/:id", h.Delete)
+	rg.POST("/:id/publish", h.Publish)
+	rg.POST("/:id/preview", h.PreviewVersion)
+}
+
+// List returns template versions filtered by ?event=, ?channel=, ?locale= and ?status=
+func (h *Handlers) List(c *gin.Context) {
+	items, err := h.store.List(c.Request.Context(), Query{
+		Event:   c.Query("event"),
+		Channel: notify.Channel(c.Query("channel")),
+		Locale:  c.Query("locale"),
+		Status:  Status(c.Query("status")),
+	})
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if items == nil {
+		items = []Template{}
+	}
+	response.OK(c, items)
+}
+
+// Create stores a new draft version
+func (h *Handlers) Create(c *gin.Context) {
+	var in Input
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.BadRequest(c, i18n.T(c, "templates.invalid_request"), response.ProcessBindingError(c, err))
+		return
+	}
+	t, err := h.store.Create(c.Request.Context(), in)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.Created(c, t)
+}
+
+// Get returns one version
+func (h *Handlers) Get(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	t, err := h.store.Get(c.Request.Context(), id)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, t)
+}
+
+// Update replaces the copy of a draft
+func (h *Handlers) Update(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var in Input
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.BadRequest(c, i18n.T(c, "templates.invalid_request"), response.ProcessBindingError(c, err))
+		return
+	}
+	t, err := h.store.Update(c.Request.Context(), id, in)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, t)
+}
+
+// Delete removes a draft
+func (h *Handlers) Delete(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	if err := h.store.Delete(c.Request.Context(), id); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+// Publish makes a version the active one
+func (h *Handlers) Publish(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	t, err := h.store.Publish(c.Request.Context(), id)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, t)
+}
+
+type previewRequest struct {
+	Input
+	Data map[string]interface{} `json:"data"`
+}
+
+// Preview renders unsaved copy with {"data": {...}} sample values
+func (h *Handlers) Preview(c *gin.Context) {
+	var req previewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, i18n.T(c, "templates.invalid_request"), response.ProcessBindingError(c, err))
+		return
+	}
+	out, err := h.store.Preview(req.Input, req.Data)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, out)
+}
+
+// PreviewVersion renders a stored version with an optional {"data": {...}} body
+func (h *Handlers) PreviewVersion(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, i18n.T(c, "templates.invalid_request"), response.ProcessBindingError(c, err))
+			return
+		}
+	}
+	out, err := h.store.PreviewVersion(c.Request.Context(), id, req.Data)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, out)
+}
+
+func paramID(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, i18n.T(c, "templates.invalid_request"))
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,61 @@
+package templates
+
+import (
+	"time"
+
+	"github.com/Masharah-Advisory/common/model"
+	"github.com/Masharah-Advisory/common/notify"
+)
+
+// Status is the lifecycle state of a template version
+type Status string
+
+const (
+	StatusDraft    Status = "draft"
+	StatusActive   Status = "active"
+	StatusArchived Status = "archived"
+)
+
+// Variable is a value a template expects in its data. Optional variables that are missing
+// render as an empty string, so lists used with range should be Required.
+type Variable struct {
+	Name     string `json:"name" binding:"required,max=64"`
+	Required bool   `json:"required"`
+	// Example is used by previews when no value is given
+	Example string `json:"example,omitempty"`
+}
+
+// Template is one version of the copy for an event on a channel in a locale. Only one version
+// per event, channel and locale is active; editing creates a new draft version.
+type Template struct {
+	model.Base
+	Event       string         `json:"event" gorm:"size:128;not null;uniqueIndex:idx_templates_version"`
+	Channel     notify.Channel `json:"channel" gorm:"size:16;not null;uniqueIndex:idx_templates_version"`
+	Locale      string         `json:"locale" gorm:"size:8;not null;uniqueIndex:idx_templates_version"`
+	Version     int            `json:"version" gorm:"not null;uniqueIndex:idx_templates_version"`
+	Status      Status         `json:"status" gorm:"size:16;not null;index"`
+	Subject     string         `json:"subject,omitempty" gorm:"type:text"`
+	Body        string         `json:"body" gorm:"type:text;not null"`
+	Text        string         `json:"text,omitempty" gorm:"type:text"`
+	Variables   []Variable     `json:"variables" gorm:"serializer:json;type:text"`
+	Note        string         `json:"note,omitempty" gorm:"size:512"`
+	PublishedAt *time.Time     `json:"published_at,omitempty"`
+}
+
+// TableName overrides the table name
+func (Template) TableName() string {
+	return "notification_templates"
+}
+
+// Input is the editable part of a template version. Body is HTML for email and plain text
+// for the other channels; Text is the optional plain-text part of an email.
+type Input struct {
+	Event     string         `json:"event" binding:"required,max=128"`
+	Channel   notify.Channel `json:"channel" binding:"required,oneof=email sms push in_app"`
+	Locale    string         `json:"locale" binding:"required,oneof=en ar"`
+	Subject   string         `json:"subject"`
+	Body      string         `json:"body" binding:"required"`
+	Text      string         `json:"text"`
+	Variables []Variable     `json:"variables" binding:"dive"`
+	Note      string         `json:"note" binding:"max=512"`
+}
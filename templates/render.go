@@ -0,0 +1,219 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"text/template/parse"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/notify"
+)
+
+// Rendered is a template rendered with data
+type Rendered struct {
+	Subject string `json:"subject,omitempty"`
+	HTML    string `json:"html,omitempty"`
+	Text    string `json:"text,omitempty"`
+}
+
+// Content converts the output for the notification dispatcher; non-email channels send the
+// plain body as Text
+func (r *Rendered) Content() *notify.Content {
+	return &notify.Content{Subject: r.Subject, HTML: r.HTML, Text: r.Text}
+}
+
+// compiled holds the parsed parts of a template version
+type compiled struct {
+	channel   notify.Channel
+	variables []Variable
+	subject   *texttemplate.Template
+	html      *htmltemplate.Template
+	body      *texttemplate.Template
+	text      *texttemplate.Template
+}
+
+// compile parses every part of t and checks that each variable it references is declared
+func compile(t *Template, lang string) (*compiled, error) {
+	funcs := templateFuncs(lang)
+	c := &compiled{channel: t.Channel, variables: t.Variables}
+
+	declared := make(map[string]bool, len(t.Variables))
+	for _, v := range t.Variables {
+		declared[v.Name] = true
+	}
+
+	parts := []struct {
+		name string
+		src  string
+		dst  **texttemplate.Template
+	}{
+		{"subject", t.Subject, &c.subject},
+		{"body", t.Body, &c.body},
+		{"text", t.Text, &c.text},
+	}
+	for _, p := range parts {
+		if strings.TrimSpace(p.src) == "" {
+			continue
+		}
+		tmpl, err := texttemplate.New(p.name).Funcs(funcs).Parse(p.src)
+		if err != nil {
+			return nil, invalid(ErrInvalidTemplate, "Reason", err.Error())
+		}
+		for _, name := range referenced(tmpl) {
+			if !declared[name] {
+				return nil, invalid(ErrUndeclaredVariable, "Variable", name)
+			}
+		}
+		*p.dst = tmpl
+	}
+
+	// Email bodies are HTML and must be escaped as such
+	if t.Channel == notify.ChannelEmail && c.body != nil {
+		html, err := htmltemplate.New("body").Funcs(htmltemplate.FuncMap(funcs)).Parse(t.Body)
+		if err != nil {
+			return nil, invalid(ErrInvalidTemplate, "Reason", err.Error())
+		}
+		c.html, c.body = html, nil
+	}
+	return c, nil
+}
+
+// render executes the template, failing when a required variable is missing; optional
+// variables that are missing render as empty
+func (c *compiled) render(data map[string]interface{}) (*Rendered, error) {
+	values := make(map[string]interface{}, len(data)+len(c.variables))
+	for k, v := range data {
+		values[k] = v
+	}
+	for _, v := range c.variables {
+		if _, ok := values[v.Name]; ok {
+			continue
+		}
+		if v.Required {
+			return nil, invalid(ErrMissingVariable, "Variable", v.Name)
+		}
+		values[v.Name] = ""
+	}
+
+	out := &Rendered{}
+	var err error
+	if out.Subject, err = execute(c.subject, values); err != nil {
+		return nil, err
+	}
+	out.Subject = strings.TrimSpace(out.Subject)
+	if c.html != nil {
+		var buf bytes.Buffer
+		if err := c.html.Execute(&buf, values); err != nil {
+			return nil, fmt.Errorf("failed to render template: %w", err)
+		}
+		out.HTML = buf.String()
+	}
+	if out.Text, err = execute(c.body, values); err != nil {
+		return nil, err
+	}
+	if c.text != nil {
+		if out.Text, err = execute(c.text, values); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func execute(t *texttemplate.Template, data map[string]interface{}) (string, error) {
+	if t == nil {
+		return "", nil
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// referenced lists the top-level data fields a template uses, e.g. .Name and $.Link. Fields
+// inside range and with blocks refer to the new dot and are not data fields.
+func referenced(t *texttemplate.Template) []string {
+	seen := make(map[string]bool)
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree != nil {
+			walk(tmpl.Tree.Root, true, seen)
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func walk(node parse.Node, root bool, seen map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walk(child, root, seen)
+		}
+	case *parse.ActionNode:
+		walk(n.Pipe, root, seen)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walk(cmd, root, seen)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walk(arg, root, seen)
+		}
+	case *parse.ChainNode:
+		walk(n.Node, root, seen)
+	case *parse.FieldNode:
+		if root && len(n.Ident) > 0 {
+			seen[n.Ident[0]] = true
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			seen[n.Ident[1]] = true
+		}
+	case *parse.IfNode:
+		walk(n.Pipe, root, seen)
+		walk(n.List, root, seen)
+		walk(n.ElseList, root, seen)
+	case *parse.RangeNode:
+		walk(n.Pipe, root, seen)
+		walk(n.List, false, seen)
+		walk(n.ElseList, root, seen)
+	case *parse.WithNode:
+		walk(n.Pipe, root, seen)
+		walk(n.List, false, seen)
+		walk(n.ElseList, root, seen)
+	case *parse.TemplateNode:
+		walk(n.Pipe, root, seen)
+	}
+}
+
+func templateFuncs(lang string) map[string]interface{} {
+	return map[string]interface{}{
+		"t": func(key string, data ...map[string]interface{}) string {
+			return i18n.TLang(lang, key, data...)
+		},
+		"lang": func() string { return lang },
+		"rtl":  func() bool { return i18n.IsRTL(lang) },
+	}
+}
+
+// invalid returns a copy of a sentinel with one metadata entry, leaving the sentinel untouched
+func invalid(base *apperror.Error, key string, value interface{}) *apperror.Error {
+	return apperror.New(base.Code, base.Kind, base.Message).
+		WithKey(base.MessageKey).
+		WithMeta(key, value)
+}
@@ -0,0 +1,378 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/cache"
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/notify"
+	"gorm.io/gorm"
+)
+
+// Errors returned by the store
+var (
+	ErrNotFound = apperror.New("template_not_found", apperror.KindNotFound, "template not found").
+			WithKey("templates.not_found")
+	ErrNotDraft = apperror.New("template_not_draft", apperror.KindConflict, "only draft versions can be changed").
+			WithKey("templates.not_draft")
+	ErrInvalidTemplate = apperror.New("template_invalid", apperror.KindValidation, "the template does not parse").
+				WithKey("templates.invalid")
+	ErrUndeclaredVariable = apperror.New("template_undeclared_variable", apperror.KindValidation, "the template uses an undeclared variable").
+				WithKey("templates.undeclared_variable")
+	ErrMissingVariable = apperror.New("template_missing_variable", apperror.KindValidation, "a required template variable is missing").
+				WithKey("templates.missing_variable")
+)
+
+// Config configures a Store
+type Config struct {
+	DB *gorm.DB
+	// Files holds the built-in templates used when no version is active in the database, usually
+	// an embed.FS of <event>.<channel>.<locale>.tmpl files (or <event>.<channel>.tmpl for every
+	// locale). The file is the body; {{define "subject"}} and {{define "text"}} blocks set the
+	// subject and the plain-text email part.
+	Files fs.FS
+	// Cache holds the active version per event, channel and locale (optional); set NegativeTTL so
+	// events without managed copy do not query the database on every notification
+	Cache *cache.Cache[Template]
+}
+
+// Query filters List
+type Query struct {
+	Event   string
+	Channel notify.Channel
+	Locale  string
+	Status  Status
+}
+
+// Store manages template versions and renders the active one
+type Store struct {
+	cfg *Config
+	now func() time.Time
+}
+
+// NewStore creates a store; migrate Template first
+func NewStore(cfg *Config) *Store {
+	return &Store{cfg: cfg, now: time.Now}
+}
+
+// Create stores in as a new draft version of its event, channel and locale
+func (s *Store) Create(ctx context.Context, in Input) (*Template, error) {
+	t := &Template{Event: in.Event, Channel: in.Channel, Locale: normalizeLocale(in.Locale), Status: StatusDraft}
+	if err := apply(t, in); err != nil {
+		return nil, err
+	}
+	if userID, ok := ctxutil.UserID(ctx); ok {
+		t.CreatedBy = &userID
+	}
+
+	err := s.cfg.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var latest int
+		err := tx.Model(&Template{}).
+			Where("event = ? AND channel = ? AND locale = ?", t.Event, t.Channel, t.Locale).
+			Select("COALESCE(MAX(version), 0)").Scan(&latest).Error
+		if err != nil {
+			return err
+		}
+		t.Version = latest + 1
+		return tx.Create(t).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create template: %w", err)
+	}
+	return t, nil
+}
+
+// Update replaces the copy of a draft; published versions are immutable
+func (s *Store) Update(ctx context.Context, id uint64, in Input) (*Template, error) {
+	t, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t.Status != StatusDraft {
+		return nil, ErrNotDraft
+	}
+	if err := apply(t, in); err != nil {
+		return nil, err
+	}
+	if err := s.cfg.DB.WithContext(ctx).Save(t).Error; err != nil {
+		return nil, fmt.Errorf("failed to update template: %w", err)
+	}
+	return t, nil
+}
+
+// Delete removes a draft
+func (s *Store) Delete(ctx context.Context, id uint64) error {
+	t, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if t.Status != StatusDraft {
+		return ErrNotDraft
+	}
+	if err := s.cfg.DB.WithContext(ctx).Delete(t).Error; err != nil {
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+	return nil
+}
+
+// Get returns a template version
+func (s *Store) Get(ctx context.Context, id uint64) (*Template, error) {
+	var t Template
+	if err := s.cfg.DB.WithContext(ctx).First(&t, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to load template: %w", err)
+	}
+	return &t, nil
+}
+
+// List returns the versions matching q, newest first within each event, channel and locale
+func (s *Store) List(ctx context.Context, q Query) ([]Template, error) {
+	db := s.cfg.DB.WithContext(ctx).Model(&Template{})
+	if q.Event != "" {
+		db = db.Where("event = ?", q.Event)
+	}
+	if q.Channel != "" {
+		db = db.Where("channel = ?", q.Channel)
+	}
+	if q.Locale != "" {
+		db = db.Where("locale = ?", normalizeLocale(q.Locale))
+	}
+	if q.Status != "" {
+		db = db.Where("status = ?", q.Status)
+	}
+	var out []Template
+	if err := db.Order("event, channel, locale, version DESC").Find(&out).Error; err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	return out, nil
+}
+
+// Publish makes a version the active one, archiving the previously active version; publishing
+// an archived version rolls back to it
+func (s *Store) Publish(ctx context.Context, id uint64) (*Template, error) {
+	t, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t.Status == StatusActive {
+		return t, nil
+	}
+	// Re-check the copy so versions stored before a validation change cannot go live broken
+	if _, err := compile(t, t.Locale); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	err = s.cfg.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&Template{}).
+			Where("event = ? AND channel = ? AND locale = ? AND status = ?", t.Event, t.Channel, t.Locale, StatusActive).
+			Update("status", StatusArchived).Error
+		if err != nil {
+			return err
+		}
+		t.Status, t.PublishedAt = StatusActive, &now
+		return tx.Model(t).Updates(map[string]interface{}{"status": t.Status, "published_at": t.PublishedAt}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish template: %w", err)
+	}
+	s.invalidate(ctx, t)
+	return t, nil
+}
+
+// Active returns the copy used for an event on a channel in locale: the active database version,
+// then the built-in file, then the same for English
+func (s *Store) Active(ctx context.Context, event string, ch notify.Channel, locale string) (*Template, error) {
+	locale = normalizeLocale(locale)
+	locales := []string{locale}
+	if locale != "en" {
+		locales = append(locales, "en")
+	}
+	for _, l := range locales {
+		t, err := s.active(ctx, event, ch, l)
+		if err == nil {
+			return t, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		if t, err = s.file(event, ch, l); err == nil {
+			return t, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Render renders the active copy for an event with data
+func (s *Store) Render(ctx context.Context, event string, ch notify.Channel, locale string, data map[string]interface{}) (*Rendered, error) {
+	t, err := s.Active(ctx, event, ch, locale)
+	if err != nil {
+		return nil, err
+	}
+	c, err := compile(t, normalizeLocale(locale))
+	if err != nil {
+		return nil, err
+	}
+	return c.render(data)
+}
+
+// Preview renders unsaved copy for an admin UI; variables missing from data take their example
+func (s *Store) Preview(in Input, data map[string]interface{}) (*Rendered, error) {
+	t := &Template{Event: in.Event, Channel: in.Channel, Locale: normalizeLocale(in.Locale)}
+	if err := apply(t, in); err != nil {
+		return nil, err
+	}
+	return preview(t, data)
+}
+
+// PreviewVersion renders a stored version like Preview
+func (s *Store) PreviewVersion(ctx context.Context, id uint64, data map[string]interface{}) (*Rendered, error) {
+	t, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return preview(t, data)
+}
+
+// Resolver adapts the store for notify.Config.Content; events without managed copy fall back to
+// the dispatcher's built-in templates
+func (s *Store) Resolver() notify.ContentResolver {
+	return func(ctx context.Context, event string, ch notify.Channel, lang string, data map[string]interface{}) (*notify.Content, bool, error) {
+		out, err := s.Render(ctx, event, ch, lang, data)
+		if errors.Is(err, ErrNotFound) {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return out.Content(), true, nil
+	}
+}
+
+func preview(t *Template, data map[string]interface{}) (*Rendered, error) {
+	values := make(map[string]interface{}, len(t.Variables))
+	for _, v := range t.Variables {
+		values[v.Name] = v.Example
+	}
+	for k, v := range data {
+		values[k] = v
+	}
+	c, err := compile(t, t.Locale)
+	if err != nil {
+		return nil, err
+	}
+	return c.render(values)
+}
+
+// active loads the active database version through the cache
+func (s *Store) active(ctx context.Context, event string, ch notify.Channel, locale string) (*Template, error) {
+	load := func(ctx context.Context) (Template, error) {
+		var t Template
+		err := s.cfg.DB.WithContext(ctx).
+			Where("event = ? AND channel = ? AND locale = ? AND status = ?", event, ch, locale, StatusActive).
+			First(&t).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return t, ErrNotFound
+		}
+		if err != nil {
+			return t, fmt.Errorf("failed to load template: %w", err)
+		}
+		return t, nil
+	}
+
+	var (
+		t   Template
+		err error
+	)
+	if s.cfg.Cache == nil {
+		t, err = load(ctx)
+	} else {
+		t, err = s.cfg.Cache.GetOrLoad(ctx, cacheKey(event, ch, locale), load)
+	}
+	if cache.IsNotFound(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// file loads a built-in template; every variable it references is optional
+func (s *Store) file(event string, ch notify.Channel, locale string) (*Template, error) {
+	if s.cfg.Files == nil {
+		return nil, ErrNotFound
+	}
+	base := event + "." + string(ch)
+	for _, name := range []string{base + "." + locale + ".tmpl", base + ".tmpl"} {
+		data, err := fs.ReadFile(s.cfg.Files, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template %s: %w", name, err)
+		}
+
+		parsed, err := texttemplate.New(name).Funcs(templateFuncs(locale)).Parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+		}
+		t := &Template{Event: event, Channel: ch, Locale: locale, Status: StatusActive, Body: parsed.Tree.Root.String()}
+		if sub := parsed.Lookup("subject"); sub != nil && sub.Tree != nil {
+			t.Subject = sub.Tree.Root.String()
+		}
+		if text := parsed.Lookup("text"); text != nil && text.Tree != nil {
+			t.Text = text.Tree.Root.String()
+		}
+		for _, v := range referenced(parsed) {
+			t.Variables = append(t.Variables, Variable{Name: v})
+		}
+		return t, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (s *Store) invalidate(ctx context.Context, t *Template) {
+	if s.cfg.Cache == nil {
+		return
+	}
+	_ = s.cfg.Cache.Delete(ctx, cacheKey(t.Event, t.Channel, t.Locale))
+}
+
+// apply copies the editable fields and validates the copy
+func apply(t *Template, in Input) error {
+	t.Subject = in.Subject
+	t.Body = in.Body
+	t.Text = in.Text
+	t.Variables = in.Variables
+	t.Note = in.Note
+	if t.Variables == nil {
+		t.Variables = []Variable{}
+	}
+	_, err := compile(t, t.Locale)
+	return err
+}
+
+func cacheKey(event string, ch notify.Channel, locale string) string {
+	return event + ":" + string(ch) + ":" + locale
+}
+
+func normalizeLocale(locale string) string {
+	if i18n.IsRTL(strings.ToLower(locale)) {
+		return "ar"
+	}
+	return "en"
+}
@@ -0,0 +1,126 @@
+package testutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Masharah-Advisory/common/httpclient"
+	"github.com/Masharah-Advisory/common/middleware"
+)
+
+// PermissionCheck is a call received by the mock auth service
+type PermissionCheck struct {
+	UserID     uint64 `json:"user_id"`
+	Permission string `json:"permission"`
+}
+
+// AuthService is an httptest-backed auth service answering the permission checks made by
+// middleware.RequirePermission
+type AuthService struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	grants     map[uint64]map[string]bool
+	superusers map[uint64]bool
+	calls      []PermissionCheck
+	fail       bool
+}
+
+// NewAuthService starts the mock and installs a service client pointing at it for the
+// permission middleware; both are torn down with the test
+func NewAuthService(t testing.TB) *AuthService {
+	t.Helper()
+	a := &AuthService{
+		grants:     make(map[uint64]map[string]bool),
+		superusers: make(map[uint64]bool),
+	}
+	a.Server = httptest.NewServer(http.HandlerFunc(a.serve))
+	t.Cleanup(a.Close)
+
+	middleware.InitServiceClient(a.Client())
+	t.Cleanup(func() { middleware.InitServiceClient(nil) })
+	return a
+}
+
+// Client returns a service client routing /api/v1/auth/* to the mock
+func (a *AuthService) Client() *httpclient.ServiceClient {
+	return httpclient.NewServiceClient(ServiceID, ServiceSecret, httpclient.ServiceConfig{"auth": a.URL})
+}
+
+// Grant allows permissions for userID
+func (a *AuthService) Grant(userID uint64, permissions ...string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.grants[userID] == nil {
+		a.grants[userID] = make(map[string]bool)
+	}
+	for _, p := range permissions {
+		a.grants[userID][p] = true
+	}
+}
+
+// Revoke removes permissions from userID
+func (a *AuthService) Revoke(userID uint64, permissions ...string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, p := range permissions {
+		delete(a.grants[userID], p)
+	}
+}
+
+// GrantAll allows every permission for userID
+func (a *AuthService) GrantAll(userID uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.superusers[userID] = true
+}
+
+// FailWith makes the mock answer 500 to exercise error paths
+func (a *AuthService) FailWith(fail bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.fail = fail
+}
+
+// Calls returns the permission checks received so far
+func (a *AuthService) Calls() []PermissionCheck {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]PermissionCheck(nil), a.calls...)
+}
+
+func (a *AuthService) serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodPost || r.URL.Path != "/api/v1/auth/access" {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": "not found"})
+		return
+	}
+
+	var check PermissionCheck
+	if err := json.NewDecoder(r.Body).Decode(&check); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": err.Error()})
+		return
+	}
+
+	a.mu.Lock()
+	a.calls = append(a.calls, check)
+	fail := a.fail
+	allowed := a.superusers[check.UserID] || a.grants[check.UserID][check.Permission]
+	a.mu.Unlock()
+
+	if fail {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": "auth service failure"})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "ok",
+		"data":    middleware.AccessData{Allowed: allowed},
+	})
+}
@@ -0,0 +1,155 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Masharah-Advisory/common/db"
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/ory/dockertest/v3"
+	dc "github.com/ory/dockertest/v3/docker"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Images used for the throwaway containers; override with TEST_POSTGRES_IMAGE_TAG / TEST_REDIS_IMAGE_TAG
+var (
+	PostgresTag = "16-alpine"
+	RedisTag    = "7-alpine"
+)
+
+// containerTTL is a hard limit so containers are reaped even when a test binary is killed
+const containerTTL = 10 * time.Minute
+
+var (
+	poolOnce sync.Once
+	pool     *dockertest.Pool
+	poolErr  error
+)
+
+// Pool returns the shared docker pool, skipping the test when docker is unavailable or
+// SKIP_DOCKER_TESTS is set
+func Pool(t testing.TB) *dockertest.Pool {
+	t.Helper()
+	if os.Getenv("SKIP_DOCKER_TESTS") != "" {
+		t.Skip("docker tests disabled by SKIP_DOCKER_TESTS")
+	}
+	poolOnce.Do(func() {
+		pool, poolErr = dockertest.NewPool("")
+		if poolErr == nil {
+			poolErr = pool.Client.Ping()
+		}
+		if pool != nil {
+			pool.MaxWait = time.Minute
+		}
+	})
+	if poolErr != nil {
+		t.Skipf("docker not available: %v", poolErr)
+	}
+	return pool
+}
+
+// Postgres is a throwaway Postgres container
+type Postgres struct {
+	DB     *gorm.DB
+	Config *db.Config
+	DSN    string
+}
+
+// NewPostgres starts Postgres, waits until it accepts connections and removes it when the test ends
+func NewPostgres(t testing.TB) *Postgres {
+	t.Helper()
+	p := Pool(t)
+
+	resource := run(t, p, &dockertest.RunOptions{
+		Repository: "postgres",
+		Tag:        envOr("TEST_POSTGRES_IMAGE_TAG", PostgresTag),
+		Env:        []string{"POSTGRES_USER=test", "POSTGRES_PASSWORD=test", "POSTGRES_DB=test"},
+	})
+
+	cfg := &db.Config{
+		Env:    "test",
+		DBHost: "localhost",
+		DBPort: resource.GetPort("5432/tcp"),
+		DBUser: "test",
+		DBPass: "test",
+		DBName: "test",
+		DBSSL:  "disable",
+	}
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPass, cfg.DBName, cfg.DBSSL)
+
+	var gdb *gorm.DB
+	if err := p.Retry(func() error {
+		var err error
+		gdb, err = gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+		if err != nil {
+			return err
+		}
+		sqlDB, err := gdb.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Ping()
+	}); err != nil {
+		t.Fatalf("docker: postgres did not become ready: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := gdb.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	return &Postgres{DB: gdb, Config: cfg, DSN: dsn}
+}
+
+// NewRedis starts Redis and returns a connected client, removed when the test ends
+func NewRedis(t testing.TB) *goredis.Client {
+	t.Helper()
+	p := Pool(t)
+
+	resource := run(t, p, &dockertest.RunOptions{
+		Repository: "redis",
+		Tag:        envOr("TEST_REDIS_IMAGE_TAG", RedisTag),
+	})
+
+	rdb := goredis.NewClient(&goredis.Options{Addr: "localhost:" + resource.GetPort("6379/tcp")})
+	if err := p.Retry(func() error {
+		return rdb.Ping(context.Background()).Err()
+	}); err != nil {
+		t.Fatalf("docker: redis did not become ready: %v", err)
+	}
+	t.Cleanup(func() { _ = rdb.Close() })
+	return rdb
+}
+
+// run starts a container that is purged on cleanup and expires on its own as a safety net
+func run(t testing.TB, p *dockertest.Pool, opts *dockertest.RunOptions) *dockertest.Resource {
+	t.Helper()
+	resource, err := p.RunWithOptions(opts, func(hc *dc.HostConfig) {
+		hc.AutoRemove = true
+		hc.RestartPolicy = dc.RestartPolicy{Name: "no"}
+	})
+	if err != nil {
+		t.Fatalf("docker: failed to start %s:%s: %v", opts.Repository, opts.Tag, err)
+	}
+	_ = resource.Expire(uint(containerTTL.Seconds()))
+	t.Cleanup(func() {
+		if err := p.Purge(resource); err != nil {
+			t.Logf("docker: failed to remove %s container: %v", opts.Repository, err)
+		}
+	})
+	return resource
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,75 @@
+package testutil
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Masharah-Advisory/common/response"
+)
+
+// Envelope is the standard response envelope with the payload left raw
+type Envelope = response.ApiResponse[json.RawMessage]
+
+// DecodeEnvelope parses the response body as the standard envelope
+func DecodeEnvelope(t testing.TB, rec *httptest.ResponseRecorder) *Envelope {
+	t.Helper()
+	var env Envelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &env); err != nil {
+		t.Fatalf("testutil: response is not a standard envelope: %v\nbody: %s", err, rec.Body.String())
+	}
+	return &env
+}
+
+// AssertSuccess checks the status and a successful envelope, decoding data into out when non-nil
+func AssertSuccess(t testing.TB, rec *httptest.ResponseRecorder, status int, out interface{}) *Envelope {
+	t.Helper()
+	assertStatus(t, rec, status)
+	env := DecodeEnvelope(t, rec)
+	if !env.Success {
+		t.Fatalf("testutil: expected success, got failure %q (code %q)", env.Message, env.Code)
+	}
+	if out != nil {
+		if env.Data == nil {
+			t.Fatalf("testutil: expected data in response, got none")
+		}
+		if err := json.Unmarshal(*env.Data, out); err != nil {
+			t.Fatalf("testutil: failed to decode response data: %v\ndata: %s", err, string(*env.Data))
+		}
+	}
+	return env
+}
+
+// AssertError checks the status and a failed envelope; code is the apperror code and is only
+// compared when not empty
+func AssertError(t testing.TB, rec *httptest.ResponseRecorder, status int, code string) *Envelope {
+	t.Helper()
+	assertStatus(t, rec, status)
+	env := DecodeEnvelope(t, rec)
+	if env.Success {
+		t.Fatalf("testutil: expected failure, got success")
+	}
+	if code != "" && env.Code != code {
+		t.Fatalf("testutil: expected error code %q, got %q (message %q)", code, env.Code, env.Message)
+	}
+	return env
+}
+
+// AssertFieldError checks that the envelope has an error item for field
+func AssertFieldError(t testing.TB, env *Envelope, field string) response.ErrorItem {
+	t.Helper()
+	for _, item := range env.Errors {
+		if item.Key == field {
+			return item
+		}
+	}
+	t.Fatalf("testutil: expected an error for field %q, got %+v", field, env.Errors)
+	return response.ErrorItem{}
+}
+
+func assertStatus(t testing.TB, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("testutil: expected status %d, got %d\nbody: %s", status, rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,18 @@
+package testutil
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+// NewRedis returns a client backed by an in-process miniredis, closed when the test ends. Use the
+// returned server to fast-forward TTLs (FastForward) or inspect keys.
+func NewRedis(t testing.TB) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return rdb, mr
+}
@@ -0,0 +1,97 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Masharah-Advisory/common/utils"
+)
+
+// RequestOption customizes a test request
+type RequestOption func(t testing.TB, req *http.Request)
+
+// WithUser authenticates the request as userID with the given roles
+func WithUser(userID uint64, roles ...string) RequestOption {
+	return func(t testing.TB, req *http.Request) {
+		req.Header.Set("Authorization", BearerFor(t, userID, roles...))
+	}
+}
+
+// WithToken sets a bearer token minted elsewhere (e.g. an expired one)
+func WithToken(token string) RequestOption {
+	return func(t testing.TB, req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// WithServiceAuth adds the internal service headers, optionally acting for userID
+func WithServiceAuth(userID ...uint64) RequestOption {
+	return func(t testing.TB, req *http.Request) {
+		for k, v := range ServiceHeaders() {
+			req.Header.Set(k, v)
+		}
+		if len(userID) > 0 {
+			req.Header.Set(utils.XUserIDHeader, strconv.FormatUint(userID[0], 10))
+		}
+	}
+}
+
+// WithHeader sets an arbitrary header
+func WithHeader(key, value string) RequestOption {
+	return func(t testing.TB, req *http.Request) {
+		req.Header.Set(key, value)
+	}
+}
+
+// WithLang sets Accept-Language
+func WithLang(lang string) RequestOption {
+	return WithHeader("Accept-Language", lang)
+}
+
+// WithTenant sets X-Tenant-ID
+func WithTenant(tenantID string) RequestOption {
+	return WithHeader("X-Tenant-ID", tenantID)
+}
+
+// Do sends a request to handler and returns the recorded response. A non-nil body that is not
+// an io.Reader, []byte or string is encoded as JSON.
+func Do(t testing.TB, handler http.Handler, method, path string, body interface{}, opts ...RequestOption) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var reader io.Reader
+	isJSON := false
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+	case []byte:
+		reader = bytes.NewReader(b)
+	case string:
+		reader = strings.NewReader(b)
+	default:
+		raw, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("testutil: failed to encode request body: %v", err)
+		}
+		reader = bytes.NewReader(raw)
+		isJSON = true
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if isJSON {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for _, opt := range opts {
+		opt(t, req)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
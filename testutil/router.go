@@ -0,0 +1,62 @@
+package testutil
+
+import (
+	"testing"
+
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/server"
+	"github.com/Masharah-Advisory/common/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Credentials installed by NewRouter; tokens and service headers minted here use them
+const (
+	JWTSecret     = "test-jwt-secret"
+	ServiceID     = "test-service"
+	ServiceSecret = "test-service-secret"
+)
+
+// RouterOptions customizes NewRouter
+type RouterOptions struct {
+	ServiceID string
+	// LocalesDir loads translations so assertions can check localized messages; without it
+	// messages are the i18n keys
+	LocalesDir string
+	Middleware []gin.HandlerFunc
+}
+
+// NewRouter returns a server with the standard middleware stack in gin test mode. It installs the
+// test JWT and service secrets for the duration of the test.
+func NewRouter(t testing.TB, opts *RouterOptions) *server.Server {
+	t.Helper()
+	if opts == nil {
+		opts = &RouterOptions{}
+	}
+	gin.SetMode(gin.TestMode)
+
+	if opts.LocalesDir != "" {
+		if err := i18n.Setup(opts.LocalesDir); err != nil {
+			t.Fatalf("testutil: failed to load locales: %v", err)
+		}
+	}
+
+	useCredentials(t)
+
+	serviceID := opts.ServiceID
+	if serviceID == "" {
+		serviceID = ServiceID
+	}
+	return server.NewServer(&server.Config{
+		ServiceID:  serviceID,
+		Middleware: opts.Middleware,
+	})
+}
+
+// useCredentials swaps the global secrets for the test values and restores them afterwards
+func useCredentials(t testing.TB) {
+	jwtSecret, serviceID, serviceSecret := utils.JWTSecret, utils.ServiceID, utils.ServiceSecret
+	utils.JWTSecret, utils.ServiceID, utils.ServiceSecret = JWTSecret, ServiceID, ServiceSecret
+	t.Cleanup(func() {
+		utils.JWTSecret, utils.ServiceID, utils.ServiceSecret = jwtSecret, serviceID, serviceSecret
+	})
+}
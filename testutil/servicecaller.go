@@ -0,0 +1,237 @@
+package testutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/Masharah-Advisory/common/httpclient"
+)
+
+var _ httpclient.ServiceCaller = (*FakeServiceCaller)(nil)
+
+// Call is a request received by FakeServiceCaller
+type Call struct {
+	Method  string
+	Route   string
+	Payload json.RawMessage
+}
+
+// Decode unmarshals the call payload into v
+func (c Call) Decode(v interface{}) error {
+	return json.Unmarshal(c.Payload, v)
+}
+
+// Stub is the scripted answer for a method and route
+type Stub struct {
+	status  int
+	body    []byte
+	err     error
+	handler func(call Call) (int, interface{}, error)
+	times   int // remaining uses, 0 means unlimited
+}
+
+// Respond answers with the standard success envelope around data
+func (s *Stub) Respond(status int, data interface{}) *Stub {
+	s.status = status
+	s.body, _ = json.Marshal(map[string]interface{}{"success": true, "message": "ok", "data": data})
+	return s
+}
+
+// RespondError answers like a downstream service failing with the standard envelope;
+// the fake returns the same *httpclient.ServiceError ServiceClient would
+func (s *Stub) RespondError(status int, code, message string) *Stub {
+	s.status = status
+	s.body, _ = json.Marshal(map[string]interface{}{"success": false, "code": code, "message": message})
+	return s
+}
+
+// Fail makes the call return err as a transport failure
+func (s *Stub) Fail(err error) *Stub {
+	s.err = err
+	return s
+}
+
+// Handle computes the answer from the call; a nil error with status >= 400 is treated like RespondError
+func (s *Stub) Handle(fn func(call Call) (status int, data interface{}, err error)) *Stub {
+	s.handler = fn
+	return s
+}
+
+// Times limits the stub to n uses, after which the next matching stub (or the default) applies
+func (s *Stub) Times(n int) *Stub {
+	s.times = n
+	return s
+}
+
+type stubEntry struct {
+	method string
+	route  string
+	stub   *Stub
+}
+
+// FakeServiceCaller is a scriptable httpclient.ServiceCaller recording every call. Unmatched
+// calls fail with an error naming the route so missing stubs are obvious.
+type FakeServiceCaller struct {
+	mu    sync.Mutex
+	stubs []*stubEntry
+	calls []Call
+}
+
+// NewFakeServiceCaller creates an empty fake
+func NewFakeServiceCaller() *FakeServiceCaller {
+	return &FakeServiceCaller{}
+}
+
+// On registers a stub for method and route; a route ending in * matches by prefix
+func (f *FakeServiceCaller) On(method, route string) *Stub {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := (&Stub{}).Respond(http.StatusOK, nil)
+	f.stubs = append(f.stubs, &stubEntry{method: strings.ToUpper(method), route: normalizeRoute(route), stub: s})
+	return s
+}
+
+// Calls returns the recorded calls
+func (f *FakeServiceCaller) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// CallsTo returns the recorded calls for method and route
+func (f *FakeServiceCaller) CallsTo(method, route string) []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []Call
+	for _, c := range f.calls {
+		if c.Method == strings.ToUpper(method) && c.Route == normalizeRoute(route) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Reset drops stubs and recorded calls
+func (f *FakeServiceCaller) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stubs = nil
+	f.calls = nil
+}
+
+// Get implements httpclient.ServiceCaller
+func (f *FakeServiceCaller) Get(ctx context.Context, route string, opts ...httpclient.RequestOption) (*http.Response, error) {
+	return f.do(http.MethodGet, route, nil, opts)
+}
+
+// Post implements httpclient.ServiceCaller
+func (f *FakeServiceCaller) Post(ctx context.Context, route string, payload interface{}, opts ...httpclient.RequestOption) (*http.Response, error) {
+	return f.do(http.MethodPost, route, payload, opts)
+}
+
+// Put implements httpclient.ServiceCaller
+func (f *FakeServiceCaller) Put(ctx context.Context, route string, payload interface{}, opts ...httpclient.RequestOption) (*http.Response, error) {
+	return f.do(http.MethodPut, route, payload, opts)
+}
+
+// Delete implements httpclient.ServiceCaller
+func (f *FakeServiceCaller) Delete(ctx context.Context, route string, opts ...httpclient.RequestOption) (*http.Response, error) {
+	return f.do(http.MethodDelete, route, nil, opts)
+}
+
+// do records the call under the route built from opts, so stubs and CallsTo use resolved routes
+// such as /api/v1/users/42?status=active
+func (f *FakeServiceCaller) do(method, route string, payload interface{}, opts []httpclient.RequestOption) (*http.Response, error) {
+	route, err := httpclient.BuildRoute(route, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build route: %w", err)
+	}
+	call := Call{Method: method, Route: normalizeRoute(route)}
+	if payload != nil {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		call.Payload = raw
+	}
+
+	f.mu.Lock()
+	f.calls = append(f.calls, call)
+	stub := f.match(call)
+	f.mu.Unlock()
+
+	if stub == nil {
+		return nil, fmt.Errorf("testutil: no stub for %s %s", method, call.Route)
+	}
+
+	status, body, err := stub.status, stub.body, stub.err
+	if stub.handler != nil {
+		var data interface{}
+		status, data, err = stub.handler(call)
+		if err == nil {
+			if status >= 400 {
+				msg, _ := data.(string)
+				body, _ = json.Marshal(map[string]interface{}{"success": false, "message": msg})
+			} else {
+				body, _ = json.Marshal(map[string]interface{}{"success": true, "message": "ok", "data": data})
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	// Mirror ServiceClient: error statuses come back as application errors, not responses
+	if status >= 400 {
+		service := ""
+		if parts := strings.Split(strings.TrimPrefix(route, "/"), "/"); len(parts) >= 3 {
+			service = parts[2]
+		}
+		return nil, httpclient.NewServiceError(service, status, body)
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}, nil
+}
+
+// match returns the first usable stub, consuming one use of limited stubs; callers hold f.mu
+func (f *FakeServiceCaller) match(call Call) *Stub {
+	for _, e := range f.stubs {
+		if e.method != call.Method {
+			continue
+		}
+		if prefix, ok := strings.CutSuffix(e.route, "*"); ok {
+			if !strings.HasPrefix(call.Route, prefix) {
+				continue
+			}
+		} else if e.route != call.Route {
+			continue
+		}
+
+		if e.stub.times < 0 {
+			continue // exhausted
+		}
+		if e.stub.times > 0 {
+			e.stub.times--
+			if e.stub.times == 0 {
+				e.stub.times = -1
+			}
+		}
+		return e.stub
+	}
+	return nil
+}
+
+func normalizeRoute(route string) string {
+	return "/" + strings.TrimPrefix(route, "/")
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 
+	"github.com/Masharah-Advisory/common/config"
 	"github.com/joho/godotenv"
 )
 
@@ -16,12 +17,16 @@ var (
 
 func LoadEnv() {
 	_ = godotenv.Load() // silently load .env if present
+	log.Printf("[COMMON] Environment: %s", config.Env())
 
 	ServiceID = os.Getenv("SERVICE_ID")
 	ServiceSecret = os.Getenv("SERVICE_SECRET")
 	AuthServiceURL = os.Getenv("AUTH_SERVICE_URL")
 	JWTSecret = os.Getenv("JWT_SECRET")
 
+	config.RegisterValidator("env.required", config.RequireEnv("SERVICE_ID", "SERVICE_SECRET", "AUTH_SERVICE_URL"))
+	config.RegisterValidator("env.urls", config.RequireURLs(map[string]string{"AUTH_SERVICE_URL": AuthServiceURL}))
+
 	if ServiceID == "" || ServiceSecret == "" || AuthServiceURL == "" {
 		log.Fatal("Missing required environment variables")
 	}
@@ -0,0 +1,206 @@
+package validation
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// validators are the shared tags; messages live under validation.<tag> in the locale files
+var validators = map[string]validator.Func{
+	"saudi_phone":             func(fl validator.FieldLevel) bool { return IsSaudiPhone(fl.Field().String()) },
+	"national_id":             func(fl validator.FieldLevel) bool { return IsNationalID(fl.Field().String()) },
+	"iqama":                   func(fl validator.FieldLevel) bool { return IsIqama(fl.Field().String()) },
+	"iban":                    func(fl validator.FieldLevel) bool { return IsSaudiIBAN(fl.Field().String()) },
+	"commercial_registration": func(fl validator.FieldLevel) bool { return IsCommercialRegistration(fl.Field().String()) },
+	"arabic_text":             func(fl validator.FieldLevel) bool { return IsArabicText(fl.Field().String()) },
+	"hijri_date":              func(fl validator.FieldLevel) bool { return IsHijriDate(fl.Field().String()) },
+	"short_address":           func(fl validator.FieldLevel) bool { return IsShortAddress(fl.Field().String()) },
+	"building_number":         func(fl validator.FieldLevel) bool { return IsBuildingNumber(fl.Field().String()) },
+	"postal_code":             func(fl validator.FieldLevel) bool { return IsPostalCode(fl.Field().String()) },
+	"additional_number":       func(fl validator.FieldLevel) bool { return IsAdditionalNumber(fl.Field().String()) },
+}
+
+// Register adds the shared tags to v
+func Register(v *validator.Validate) error {
+	for tag, fn := range validators {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return fmt.Errorf("failed to register %s validator: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+// RegisterGin adds the shared tags to gin's binding validator so `binding:"saudi_phone"` works
+func RegisterGin() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return fmt.Errorf("gin binding validator is not go-playground/validator")
+	}
+	return Register(v)
+}
+
+// Tags returns the names of the shared tags
+func Tags() []string {
+	tags := make([]string, 0, len(validators))
+	for tag := range validators {
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+var (
+	saudiPhonePattern = regexp.MustCompile(`^(?:\+966|00966|966|0)?5\d{8}$`)
+	hijriPattern      = regexp.MustCompile(`^(\d{4})[-/](\d{1,2})[-/](\d{1,2})$`)
+	shortAddrPattern  = regexp.MustCompile(`^[A-Z]{4}[0-9]{4}$`)
+	postalCodePattern = regexp.MustCompile(`^[1-9][0-9]{4}$`)
+	fourDigitsPattern = regexp.MustCompile(`^[1-9][0-9]{3}$`)
+	phoneSeparators   = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "")
+)
+
+// IsSaudiPhone accepts Saudi mobile numbers in local (05XXXXXXXX) or international (+9665XXXXXXXX) form
+func IsSaudiPhone(s string) bool {
+	return saudiPhonePattern.MatchString(phoneSeparators.Replace(toWesternDigits(strings.TrimSpace(s))))
+}
+
+// IsNationalID validates a Saudi national ID: 10 digits starting with 1 and a valid check digit
+func IsNationalID(s string) bool {
+	return isSaudiID(s, '1')
+}
+
+// IsIqama validates a resident (iqama) number: 10 digits starting with 2 and a valid check digit
+func IsIqama(s string) bool {
+	return isSaudiID(s, '2')
+}
+
+// isSaudiID runs the Luhn check used by both national IDs and iqamas
+func isSaudiID(s string, prefix byte) bool {
+	s = toWesternDigits(strings.TrimSpace(s))
+	if len(s) != 10 || s[0] != prefix {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 10; i++ {
+		d := int(s[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if i%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// IsSaudiIBAN validates a Saudi IBAN: SA, two check digits and 20 alphanumerics, with a valid mod-97 checksum
+func IsSaudiIBAN(s string) bool {
+	s = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(s), " ", ""))
+	if len(s) != 24 || !strings.HasPrefix(s, "SA") {
+		return false
+	}
+
+	// Move the country code and check digits to the end and convert letters to numbers (A=10)
+	var digits strings.Builder
+	for _, r := range s[4:] + s[:4] {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r >= 'A' && r <= 'Z':
+			digits.WriteString(strconv.Itoa(int(r-'A') + 10))
+		default:
+			return false
+		}
+	}
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// IsCommercialRegistration validates a commercial registration (CR) number: 10 digits, not starting with 0
+func IsCommercialRegistration(s string) bool {
+	s = toWesternDigits(strings.TrimSpace(s))
+	if len(s) != 10 || s[0] == '0' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsArabicText reports whether s contains Arabic letters and no letters from other scripts;
+// digits, spaces and punctuation are allowed
+func IsArabicText(s string) bool {
+	hasArabic := false
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Arabic, r):
+			hasArabic = true
+		case unicode.IsLetter(r):
+			return false
+		case unicode.IsDigit(r), unicode.IsSpace(r), unicode.IsPunct(r), unicode.IsSymbol(r), unicode.Is(unicode.Mn, r):
+		default:
+			return false
+		}
+	}
+	return hasArabic
+}
+
+// IsHijriDate validates a Hijri date as YYYY-MM-DD (or YYYY/MM/DD) between 1300 and 1600 AH;
+// Hijri months have at most 30 days
+func IsHijriDate(s string) bool {
+	m := hijriPattern.FindStringSubmatch(toWesternDigits(strings.TrimSpace(s)))
+	if m == nil {
+		return false
+	}
+	year, _ := strconv.Atoi(m[1])
+	month, _ := strconv.Atoi(m[2])
+	day, _ := strconv.Atoi(m[3])
+	return year >= 1300 && year <= 1600 && month >= 1 && month <= 12 && day >= 1 && day <= 30
+}
+
+// IsShortAddress validates a national address short code: four letters (region, branch and
+// division) and four digits, e.g. RRRD2929; spaces are ignored
+func IsShortAddress(s string) bool {
+	s = strings.ToUpper(strings.ReplaceAll(toWesternDigits(strings.TrimSpace(s)), " ", ""))
+	return shortAddrPattern.MatchString(s)
+}
+
+// IsBuildingNumber validates a national address building number: four digits, not starting with 0
+func IsBuildingNumber(s string) bool {
+	return fourDigitsPattern.MatchString(toWesternDigits(strings.TrimSpace(s)))
+}
+
+// IsPostalCode validates a Saudi postal code: five digits, not starting with 0
+func IsPostalCode(s string) bool {
+	return postalCodePattern.MatchString(toWesternDigits(strings.TrimSpace(s)))
+}
+
+// IsAdditionalNumber validates a national address additional number: four digits, not starting with 0
+func IsAdditionalNumber(s string) bool {
+	return fourDigitsPattern.MatchString(toWesternDigits(strings.TrimSpace(s)))
+}
+
+// toWesternDigits converts Arabic-Indic and Extended Arabic-Indic digits to ASCII
+func toWesternDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= '٠' && r <= '٩':
+			return '0' + (r - '٠')
+		case r >= '۰' && r <= '۹':
+			return '0' + (r - '۰')
+		}
+		return r
+	}, s)
+}
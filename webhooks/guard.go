@@ -0,0 +1,86 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/Masharah-Advisory/common/config"
+)
+
+// errInternalAddress refuses a subscriber address inside the network, e.g. another service or
+// the cloud metadata endpoint at 169.254.169.254, since response bodies end up in delivery logs
+var errInternalAddress = errors.New("webhook address is not public")
+
+// sharedAddressSpace is the carrier-grade NAT range, not covered by net.IP.IsPrivate
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// internalIP reports addresses subscribers may not point at: loopback, private, link-local,
+// unspecified and multicast ones
+func internalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsLinkLocalMulticast() ||
+		sharedAddressSpace.Contains(ip) || ip.To4() != nil && ip.To4()[0] == 0
+}
+
+// allowInternal lets development subscriptions point at local receivers
+func allowInternal() bool {
+	return config.IsDevelopment()
+}
+
+// checkHost resolves host and refuses it when any of its addresses is internal
+func checkHost(ctx context.Context, host string) error {
+	if allowInternal() {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if internalIP(ip) {
+			return errInternalAddress
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if internalIP(addr.IP) {
+			return errInternalAddress
+		}
+	}
+	return nil
+}
+
+// dialControl checks the address actually dialed, so a host that resolved to a public address
+// when subscribed cannot be rebound to an internal one later
+func dialControl(network, address string, _ syscall.RawConn) error {
+	if allowInternal() {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || internalIP(ip) {
+		return errInternalAddress
+	}
+	return nil
+}
+
+// newClient is the default delivery client: no proxy, no redirects, public addresses only
+func newClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second, Control: dialControl}).DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		// Partners must answer directly; following redirects would bypass URL validation
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,210 @@
+package webhooks
+
+import (
+	"strconv"
+
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/dto"
+	"github.com/Masharah-Advisory/common/i18n"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Handlers exposes subscription management and the delivery log, scoped to the tenant_id
+// set on the context
+type Handlers struct {
+	d *Dispatcher
+}
+
+// NewHandlers creates the admin handlers
+func NewHandlers(d *Dispatcher) *Handlers {
+	return &Handlers{d: d}
+}
+
+// Register mounts the handlers on a router group, e.g. /webhooks
+func (h *Handlers) Register(rg *gin.RouterGroup) {
+	rg.GET("/subscriptions", h.ListSubscriptions)
+	rg.POST("/subscriptions", h.CreateSubscription)
+	rg.GET("/subscriptions/:id", h.GetSubscription)
+	rg.PUT("/subscriptions/:id", h.UpdateSubscription)
+	rg.DELETE("/subscriptions/:id", h.DeleteSubscription)
+	rg.POST("/subscriptions/:id/rotate-secret", h.RotateSecret)
+	rg.POST("/subscriptions/:id/ping", h.Ping)
+
+	rg.GET("/deliveries", h.ListDeliveries)
+	rg.GET("/deliveries/:id", h.GetDelivery)
+	rg.POST("/deliveries/:id/redeliver", h.Redeliver)
+}
+
+type createdSubscription struct {
+	*Subscription
+	// Secret is only returned on creation and rotation
+	Secret string `json:"secret"`
+}
+
+// ListSubscriptions returns the tenant's subscriptions
+func (h *Handlers) ListSubscriptions(c *gin.Context) {
+	subs, err := h.d.Subscriptions(c.Request.Context(), ctxutil.TenantID(c))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, subs)
+}
+
+// CreateSubscription creates a subscription and returns its secret once
+func (h *Handlers) CreateSubscription(c *gin.Context) {
+	var in SubscriptionInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.BadRequest(c, i18n.T(c, "webhooks.invalid_request"), response.ProcessBindingError(c, err))
+		return
+	}
+	sub, err := h.d.CreateSubscription(c.Request.Context(), ctxutil.TenantID(c), in)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.Created(c, createdSubscription{Subscription: sub, Secret: sub.Secret})
+}
+
+// GetSubscription returns one subscription
+func (h *Handlers) GetSubscription(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	sub, err := h.d.Subscription(c.Request.Context(), ctxutil.TenantID(c), id)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, sub)
+}
+
+// UpdateSubscription replaces the editable fields of a subscription
+func (h *Handlers) UpdateSubscription(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	var in SubscriptionInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.BadRequest(c, i18n.T(c, "webhooks.invalid_request"), response.ProcessBindingError(c, err))
+		return
+	}
+	sub, err := h.d.UpdateSubscription(c.Request.Context(), ctxutil.TenantID(c), id, in)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, sub)
+}
+
+// DeleteSubscription removes a subscription
+func (h *Handlers) DeleteSubscription(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	if err := h.d.DeleteSubscription(c.Request.Context(), ctxutil.TenantID(c), id); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+// RotateSecret issues a new signing secret
+func (h *Handlers) RotateSecret(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	secret, err := h.d.RotateSecret(c.Request.Context(), ctxutil.TenantID(c), id)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, gin.H{"secret": secret})
+}
+
+// Ping sends a test event to a subscription
+func (h *Handlers) Ping(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	delivery, err := h.d.Ping(c.Request.Context(), ctxutil.TenantID(c), id)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.Accepted(c, delivery)
+}
+
+// ListDeliveries returns the delivery log; filter with subscription_id, status and event
+func (h *Handlers) ListDeliveries(c *gin.Context) {
+	subID, _ := strconv.ParseUint(c.Query("subscription_id"), 10, 64)
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	f := DeliveryFilter{
+		SubscriptionID: subID,
+		Status:         DeliveryStatus(c.Query("status")),
+		Event:          c.Query("event"),
+		Page:           page,
+		Limit:          limit,
+	}
+
+	items, total, err := h.d.Deliveries(c.Request.Context(), ctxutil.TenantID(c), f)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	if f.Page < 1 {
+		f.Page = 1
+	}
+	if f.Limit < 1 || f.Limit > 100 {
+		f.Limit = 20
+	}
+	response.OK(c, dto.BuildPaginatedResponse(items, total, f.Page, f.Limit))
+}
+
+// GetDelivery returns one delivery with its last response
+func (h *Handlers) GetDelivery(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	delivery, err := h.d.TenantDelivery(c.Request.Context(), ctxutil.TenantID(c), id)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.OK(c, delivery)
+}
+
+// Redeliver re-sends a delivery, including dead-lettered ones
+func (h *Handlers) Redeliver(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+	if _, err := h.d.TenantDelivery(c.Request.Context(), ctxutil.TenantID(c), id); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	delivery, err := h.d.Redeliver(c.Request.Context(), id)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+	response.Accepted(c, delivery)
+}
+
+func paramID(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, i18n.T(c, "webhooks.invalid_request"))
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,84 @@
+package webhooks
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Masharah-Advisory/common/model"
+)
+
+// DeliveryStatus is the state of a delivery
+type DeliveryStatus string
+
+const (
+	StatusPending   DeliveryStatus = "pending"
+	StatusSucceeded DeliveryStatus = "succeeded"
+	StatusRetrying  DeliveryStatus = "retrying"
+	// StatusDead deliveries exhausted their retries and form the dead-letter queue
+	StatusDead DeliveryStatus = "dead"
+)
+
+// Subscription is a partner endpoint receiving a set of event types
+type Subscription struct {
+	model.Base
+	TenantID    string     `json:"tenant_id,omitempty" gorm:"index;size:64"`
+	URL         string     `json:"url" gorm:"size:2048;not null"`
+	Secret      string     `json:"-" gorm:"size:128;not null"`
+	Events      string     `json:"events" gorm:"size:1024"` // comma separated; "*" and "order.*" wildcards
+	Description string     `json:"description,omitempty" gorm:"size:255"`
+	Active      bool       `json:"active" gorm:"default:true"`
+	Failures    int        `json:"failures"` // consecutive dead deliveries
+	DisabledAt  *time.Time `json:"disabled_at,omitempty"`
+}
+
+// TableName overrides the table name
+func (Subscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// EventList returns the subscribed event patterns
+func (s *Subscription) EventList() []string {
+	var out []string
+	for _, e := range strings.Split(s.Events, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Matches reports whether the subscription receives event
+func (s *Subscription) Matches(event string) bool {
+	for _, pattern := range s.EventList() {
+		switch {
+		case pattern == "*" || pattern == event:
+			return true
+		case strings.HasSuffix(pattern, ".*") && strings.HasPrefix(event, strings.TrimSuffix(pattern, "*")):
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery is one event sent to one subscription, with the outcome of its latest attempt
+type Delivery struct {
+	model.Base
+	SubscriptionID uint64         `json:"subscription_id" gorm:"index;not null"`
+	TenantID       string         `json:"tenant_id,omitempty" gorm:"index;size:64"`
+	EventID        string         `json:"event_id" gorm:"index;size:64;not null"`
+	Event          string         `json:"event" gorm:"size:128;not null"`
+	Payload        string         `json:"payload" gorm:"type:text"`
+	Status         DeliveryStatus `json:"status" gorm:"index;size:16;not null"`
+	Attempts       int            `json:"attempts"`
+	NextAttemptAt  *time.Time     `json:"next_attempt_at,omitempty" gorm:"index"`
+	ResponseStatus int            `json:"response_status,omitempty"`
+	ResponseBody   string         `json:"response_body,omitempty" gorm:"type:text"`
+	Error          string         `json:"error,omitempty" gorm:"type:text"`
+	DurationMs     int64          `json:"duration_ms,omitempty"`
+	DeliveredAt    *time.Time     `json:"delivered_at,omitempty"`
+}
+
+// TableName overrides the table name
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,194 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SubscriptionInput holds the editable fields of a subscription
+type SubscriptionInput struct {
+	URL         string   `json:"url" binding:"required"`
+	Events      []string `json:"events" binding:"required,min=1"`
+	Description string   `json:"description"`
+	Active      *bool    `json:"active"`
+}
+
+// CreateSubscription stores a new subscription with a generated secret
+func (d *Dispatcher) CreateSubscription(ctx context.Context, tenantID string, in SubscriptionInput) (*Subscription, error) {
+	if err := ValidateURL(in.URL); err != nil {
+		return nil, err
+	}
+	secret, err := NewSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &Subscription{
+		TenantID:    tenantID,
+		URL:         in.URL,
+		Secret:      secret,
+		Events:      strings.Join(in.Events, ","),
+		Description: in.Description,
+		Active:      in.Active == nil || *in.Active,
+	}
+	if err := d.cfg.DB.WithContext(ctx).Create(sub).Error; err != nil {
+		return nil, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// Subscription loads a tenant's subscription
+func (d *Dispatcher) Subscription(ctx context.Context, tenantID string, id uint64) (*Subscription, error) {
+	var sub Subscription
+	err := d.cfg.DB.WithContext(ctx).
+		Where("id = ? AND tenant_id = ? AND deleted_at IS NULL", id, tenantID).
+		First(&sub).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook subscription: %w", err)
+	}
+	return &sub, nil
+}
+
+// Subscriptions lists a tenant's subscriptions
+func (d *Dispatcher) Subscriptions(ctx context.Context, tenantID string) ([]Subscription, error) {
+	var subs []Subscription
+	err := d.cfg.DB.WithContext(ctx).
+		Where("tenant_id = ? AND deleted_at IS NULL", tenantID).
+		Order("id").
+		Find(&subs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// UpdateSubscription changes a subscription; re-activating it resets the failure counter
+func (d *Dispatcher) UpdateSubscription(ctx context.Context, tenantID string, id uint64, in SubscriptionInput) (*Subscription, error) {
+	sub, err := d.Subscription(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateURL(in.URL); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"url":         in.URL,
+		"events":      strings.Join(in.Events, ","),
+		"description": in.Description,
+	}
+	if in.Active != nil {
+		updates["active"] = *in.Active
+		if *in.Active && !sub.Active {
+			updates["failures"] = 0
+			updates["disabled_at"] = nil
+		}
+	}
+	if err := d.cfg.DB.WithContext(ctx).Model(sub).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+	return d.Subscription(ctx, tenantID, id)
+}
+
+// DeleteSubscription removes a subscription; its delivery log is kept
+func (d *Dispatcher) DeleteSubscription(ctx context.Context, tenantID string, id uint64) error {
+	res := d.cfg.DB.WithContext(ctx).Model(&Subscription{}).
+		Where("id = ? AND tenant_id = ? AND deleted_at IS NULL", id, tenantID).
+		Updates(map[string]interface{}{"deleted_at": time.Now(), "active": false})
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+// RotateSecret replaces the signing secret and returns the new one
+func (d *Dispatcher) RotateSecret(ctx context.Context, tenantID string, id uint64) (string, error) {
+	sub, err := d.Subscription(ctx, tenantID, id)
+	if err != nil {
+		return "", err
+	}
+	secret, err := NewSecret()
+	if err != nil {
+		return "", err
+	}
+	if err := d.cfg.DB.WithContext(ctx).Model(sub).Update("secret", secret).Error; err != nil {
+		return "", fmt.Errorf("failed to rotate webhook secret: %w", err)
+	}
+	return secret, nil
+}
+
+// DeliveryFilter narrows a delivery listing
+type DeliveryFilter struct {
+	SubscriptionID uint64
+	Status         DeliveryStatus
+	Event          string
+	Page           int
+	Limit          int
+}
+
+// Deliveries lists a tenant's deliveries, newest first
+func (d *Dispatcher) Deliveries(ctx context.Context, tenantID string, f DeliveryFilter) ([]Delivery, int64, error) {
+	if f.Page < 1 {
+		f.Page = 1
+	}
+	if f.Limit < 1 || f.Limit > 100 {
+		f.Limit = 20
+	}
+
+	q := d.cfg.DB.WithContext(ctx).Model(&Delivery{}).Where("tenant_id = ?", tenantID)
+	if f.SubscriptionID != 0 {
+		q = q.Where("subscription_id = ?", f.SubscriptionID)
+	}
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	if f.Event != "" {
+		q = q.Where("event = ?", f.Event)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+	var items []Delivery
+	if err := q.Order("id DESC").Offset((f.Page - 1) * f.Limit).Limit(f.Limit).Find(&items).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return items, total, nil
+}
+
+// TenantDelivery loads a delivery scoped to a tenant
+func (d *Dispatcher) TenantDelivery(ctx context.Context, tenantID string, id uint64) (*Delivery, error) {
+	delivery, err := d.Delivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.TenantID != tenantID {
+		return nil, ErrDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+// Ping sends a webhook.ping event to a single subscription
+func (d *Dispatcher) Ping(ctx context.Context, tenantID string, id uint64) (*Delivery, error) {
+	sub, err := d.Subscription(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := d.publishTo(ctx, []Subscription{*sub}, tenantID, "webhook.ping", map[string]interface{}{"subscription_id": sub.ID})
+	if err != nil {
+		return nil, err
+	}
+	return d.Delivery(ctx, deliveries[0].ID)
+}
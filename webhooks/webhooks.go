@@ -0,0 +1,418 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/buildinfo"
+	"github.com/Masharah-Advisory/common/config"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/worker"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Worker job names
+const (
+	DeliverJob = "webhooks.deliver"
+	RetryJob   = "webhooks.retry"
+)
+
+// Config holds the dispatcher settings
+type Config struct {
+	DB     *gorm.DB
+	Worker *worker.Manager // optional; without it deliveries are attempted inline and retried by Retry
+	// Client sends deliveries; the default one only dials public addresses and never follows
+	// redirects, keep both when replacing it
+	Client *http.Client
+
+	ServiceID    string        // used in the User-Agent
+	Timeout      time.Duration // per request, defaults to 10s
+	MaxAttempts  int           // before a delivery is dead-lettered, defaults to 8
+	BaseBackoff  time.Duration // first retry delay, doubled per attempt, defaults to 30s
+	MaxBackoff   time.Duration // defaults to 6h
+	PollInterval time.Duration // how often due retries are picked up, defaults to 30s
+	// DisableAfter deactivates a subscription after this many consecutive dead deliveries, 0 disables
+	DisableAfter int
+}
+
+// Envelope is the JSON body sent to subscribers
+type Envelope struct {
+	ID        string          `json:"id"`
+	Event     string          `json:"event"`
+	TenantID  string          `json:"tenant_id,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Errors returned by the dispatcher
+var (
+	ErrSubscriptionNotFound = apperror.New("webhook_subscription_not_found", apperror.KindNotFound, "webhook subscription not found").
+				WithKey("webhooks.subscription_not_found")
+	ErrDeliveryNotFound = apperror.New("webhook_delivery_not_found", apperror.KindNotFound, "webhook delivery not found").
+				WithKey("webhooks.delivery_not_found")
+	ErrInvalidURL = apperror.New("webhook_invalid_url", apperror.KindBadRequest, "invalid webhook url").
+			WithKey("webhooks.invalid_url")
+)
+
+// Dispatcher fans events out to subscriptions and delivers them with retries
+type Dispatcher struct {
+	cfg *Config
+	log *zap.Logger
+}
+
+type deliverPayload struct {
+	DeliveryID uint64 `json:"delivery_id"`
+}
+
+// NewDispatcher creates a dispatcher and registers its jobs on cfg.Worker when set
+func NewDispatcher(cfg *Config) *Dispatcher {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Client == nil {
+		cfg.Client = newClient(cfg.Timeout)
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 8
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = 30 * time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 6 * time.Hour
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 30 * time.Second
+	}
+
+	d := &Dispatcher{cfg: cfg, log: logger.Module("webhooks")}
+
+	if cfg.Worker != nil {
+		// Retries are scheduled in the database, so the worker never retries in-process
+		cfg.Worker.Register(DeliverJob, d.handleTask, worker.JobOptions{Concurrency: 4, Timeout: cfg.Timeout + 5*time.Second})
+		cfg.Worker.Every(RetryJob, cfg.PollInterval, func(ctx context.Context) error {
+			_, err := d.Retry(ctx)
+			return err
+		}, worker.JobOptions{})
+	}
+	return d
+}
+
+// ValidateURL checks a subscription URL; outside development it must be HTTPS and resolve to
+// public addresses only
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return ErrInvalidURL
+	}
+	switch u.Scheme {
+	case "https":
+	case "http":
+		if !config.IsDevelopment() {
+			return ErrInvalidURL
+		}
+	default:
+		return ErrInvalidURL
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := checkHost(ctx, u.Hostname()); err != nil {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// Publish records a delivery for every active subscription of the tenant matching event and
+// schedules them
+func (d *Dispatcher) Publish(ctx context.Context, tenantID, event string, data interface{}) ([]Delivery, error) {
+	var subs []Subscription
+	if err := d.cfg.DB.WithContext(ctx).
+		Where("tenant_id = ? AND active = ? AND deleted_at IS NULL", tenantID, true).
+		Find(&subs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load webhook subscriptions: %w", err)
+	}
+
+	var matched []Subscription
+	for _, sub := range subs {
+		if sub.Matches(event) {
+			matched = append(matched, sub)
+		}
+	}
+	return d.publishTo(ctx, matched, tenantID, event, data)
+}
+
+// publishTo records and schedules one delivery per subscription
+func (d *Dispatcher) publishTo(ctx context.Context, subs []Subscription, tenantID, event string, data interface{}) ([]Delivery, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook data: %w", err)
+	}
+
+	var deliveries []Delivery
+	for _, sub := range subs {
+		eventID := uuid.NewString()
+		body, err := json.Marshal(Envelope{
+			ID:        eventID,
+			Event:     event,
+			TenantID:  tenantID,
+			CreatedAt: time.Now().UTC(),
+			Data:      raw,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal webhook envelope: %w", err)
+		}
+		now := time.Now()
+		deliveries = append(deliveries, Delivery{
+			SubscriptionID: sub.ID,
+			TenantID:       tenantID,
+			EventID:        eventID,
+			Event:          event,
+			Payload:        string(body),
+			Status:         StatusPending,
+			NextAttemptAt:  &now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil, nil
+	}
+
+	if err := d.cfg.DB.WithContext(ctx).Create(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("failed to record webhook deliveries: %w", err)
+	}
+	for _, delivery := range deliveries {
+		d.schedule(ctx, delivery.ID)
+	}
+	return deliveries, nil
+}
+
+// Redeliver re-sends a delivery, including dead-lettered ones, with a fresh attempt budget
+func (d *Dispatcher) Redeliver(ctx context.Context, deliveryID uint64) (*Delivery, error) {
+	now := time.Now()
+	res := d.cfg.DB.WithContext(ctx).Model(&Delivery{}).Where("id = ?", deliveryID).Updates(map[string]interface{}{
+		"status":          StatusPending,
+		"attempts":        0,
+		"next_attempt_at": now,
+		"error":           "",
+	})
+	if res.Error != nil {
+		return nil, fmt.Errorf("failed to reset webhook delivery: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrDeliveryNotFound
+	}
+
+	d.schedule(ctx, deliveryID)
+	return d.Delivery(ctx, deliveryID)
+}
+
+// Delivery loads a delivery
+func (d *Dispatcher) Delivery(ctx context.Context, id uint64) (*Delivery, error) {
+	var delivery Delivery
+	err := d.cfg.DB.WithContext(ctx).First(&delivery, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook delivery: %w", err)
+	}
+	return &delivery, nil
+}
+
+// Retry attempts every delivery whose next attempt is due and returns how many were attempted
+func (d *Dispatcher) Retry(ctx context.Context) (int, error) {
+	var ids []uint64
+	err := d.cfg.DB.WithContext(ctx).Model(&Delivery{}).
+		Where("status IN ? AND next_attempt_at <= ?", []DeliveryStatus{StatusPending, StatusRetrying}, time.Now()).
+		Order("next_attempt_at").
+		Limit(100).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load due webhook deliveries: %w", err)
+	}
+
+	attempted := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := d.Deliver(ctx, id); err != nil {
+			d.log.Warn("webhook delivery attempt failed", zap.Uint64("delivery_id", id), zap.Error(err))
+		}
+		attempted++
+	}
+	return attempted, nil
+}
+
+// Deliver claims a due delivery and performs one attempt, scheduling the next one or
+// dead-lettering it on failure. A delivery claimed by another instance is skipped.
+func (d *Dispatcher) Deliver(ctx context.Context, deliveryID uint64) error {
+	// Lease the delivery by pushing its next attempt forward; only one instance wins the update
+	lease := time.Now().Add(d.cfg.Timeout * 3)
+	res := d.cfg.DB.WithContext(ctx).Model(&Delivery{}).
+		Where("id = ? AND status IN ? AND next_attempt_at <= ?", deliveryID, []DeliveryStatus{StatusPending, StatusRetrying}, time.Now()).
+		Update("next_attempt_at", lease)
+	if res.Error != nil {
+		return fmt.Errorf("failed to claim webhook delivery: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil
+	}
+
+	delivery, err := d.Delivery(ctx, deliveryID)
+	if err != nil {
+		return err
+	}
+	var sub Subscription
+	if err := d.cfg.DB.WithContext(ctx).First(&sub, delivery.SubscriptionID).Error; err != nil {
+		return d.finish(ctx, delivery, nil, 0, "", 0, fmt.Errorf("%w: %d", ErrSubscriptionNotFound, delivery.SubscriptionID), true)
+	}
+	if !sub.Active {
+		return d.finish(ctx, delivery, &sub, 0, "", 0, errors.New("subscription is inactive"), true)
+	}
+
+	status, body, elapsed, sendErr := d.send(ctx, &sub, delivery)
+	return d.finish(ctx, delivery, &sub, status, body, elapsed, sendErr, false)
+}
+
+// send posts the signed payload
+func (d *Dispatcher) send(ctx context.Context, sub *Subscription, delivery *Delivery) (int, string, time.Duration, error) {
+	payload := []byte(delivery.Payload)
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, "", 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", buildinfo.UserAgent(d.cfg.ServiceID))
+	req.Header.Set(HeaderID, delivery.EventID)
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderTimestamp, fmt.Sprint(now.Unix()))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, payload, now))
+
+	resp, err := d.cfg.Client.Do(req)
+	elapsed := time.Since(now)
+	if err != nil {
+		return 0, "", elapsed, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(body), elapsed, fmt.Errorf("webhook endpoint returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, string(body), elapsed, nil
+}
+
+// finish records the attempt outcome
+func (d *Dispatcher) finish(ctx context.Context, delivery *Delivery, sub *Subscription, status int, body string, elapsed time.Duration, sendErr error, dead bool) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"attempts":        delivery.Attempts + 1,
+		"response_status": status,
+		"response_body":   body,
+		"duration_ms":     elapsed.Milliseconds(),
+	}
+
+	log := d.log.With(
+		zap.Uint64("delivery_id", delivery.ID),
+		zap.Uint64("subscription_id", delivery.SubscriptionID),
+		zap.String("event", delivery.Event),
+		zap.Int("attempt", delivery.Attempts+1),
+	)
+
+	switch {
+	case sendErr == nil:
+		updates["status"] = StatusSucceeded
+		updates["error"] = ""
+		updates["next_attempt_at"] = nil
+		updates["delivered_at"] = now
+		if sub != nil && sub.Failures > 0 {
+			d.cfg.DB.WithContext(ctx).Model(sub).Update("failures", 0)
+		}
+		log.Debug("webhook delivered", zap.Int("status", status), zap.Duration("duration", elapsed))
+
+	case dead || delivery.Attempts+1 >= d.cfg.MaxAttempts:
+		updates["status"] = StatusDead
+		updates["error"] = sendErr.Error()
+		updates["next_attempt_at"] = nil
+		log.Error("webhook dead-lettered", zap.Error(sendErr))
+		if sub != nil {
+			d.recordFailure(ctx, sub)
+		}
+
+	default:
+		next := now.Add(d.backoff(delivery.Attempts + 1))
+		updates["status"] = StatusRetrying
+		updates["error"] = sendErr.Error()
+		updates["next_attempt_at"] = next
+		log.Warn("webhook delivery failed, retry scheduled", zap.Time("next_attempt_at", next), zap.Error(sendErr))
+	}
+
+	if err := d.cfg.DB.WithContext(ctx).Model(&Delivery{}).Where("id = ?", delivery.ID).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+	return sendErr
+}
+
+// recordFailure bumps the consecutive failure counter and disables the subscription past the limit
+func (d *Dispatcher) recordFailure(ctx context.Context, sub *Subscription) {
+	failures := sub.Failures + 1
+	updates := map[string]interface{}{"failures": failures}
+	if d.cfg.DisableAfter > 0 && failures >= d.cfg.DisableAfter {
+		updates["active"] = false
+		updates["disabled_at"] = time.Now()
+		d.log.Warn("webhook subscription disabled after repeated failures",
+			zap.Uint64("subscription_id", sub.ID),
+			zap.Int("failures", failures),
+		)
+	}
+	if err := d.cfg.DB.WithContext(ctx).Model(sub).Updates(updates).Error; err != nil {
+		d.log.Error("failed to record webhook subscription failure", zap.Uint64("subscription_id", sub.ID), zap.Error(err))
+	}
+}
+
+// backoff returns the delay before the given retry
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.cfg.BaseBackoff
+	for i := 1; i < attempt && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.cfg.MaxBackoff)
+}
+
+// schedule hands a delivery to the worker, or attempts it inline without one
+func (d *Dispatcher) schedule(ctx context.Context, deliveryID uint64) {
+	if d.cfg.Worker != nil {
+		if _, err := d.cfg.Worker.Enqueue(ctx, DeliverJob, deliverPayload{DeliveryID: deliveryID}); err != nil {
+			// The retry poller picks the delivery up once it is due
+			d.log.Warn("failed to enqueue webhook delivery", zap.Uint64("delivery_id", deliveryID), zap.Error(err))
+		}
+		return
+	}
+	if err := d.Deliver(ctx, deliveryID); err != nil {
+		d.log.Warn("webhook delivery attempt failed", zap.Uint64("delivery_id", deliveryID), zap.Error(err))
+	}
+}
+
+func (d *Dispatcher) handleTask(ctx context.Context, task *worker.Task) error {
+	var p deliverPayload
+	if err := task.Decode(&p); err != nil {
+		return err
+	}
+	// Failures are rescheduled in the database, so they are not reported to the worker
+	_ = d.Deliver(ctx, p.DeliveryID)
+	return nil
+}
@@ -0,0 +1,74 @@
+package webhooks
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Masharah-Advisory/common/config"
+)
+
+func TestValidateURL(t *testing.T) {
+	config.SetEnv(config.Production)
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{"https://93.184.215.14/hooks", nil},
+		{"http://93.184.215.14/hooks", ErrInvalidURL},
+		{"ftp://93.184.215.14/hooks", ErrInvalidURL},
+		{"https:///hooks", ErrInvalidURL},
+		{"https://169.254.169.254/latest/meta-data", ErrInvalidURL},
+		{"https://127.0.0.1/hooks", ErrInvalidURL},
+		{"https://localhost/hooks", ErrInvalidURL},
+		{"https://10.1.2.3/hooks", ErrInvalidURL},
+		{"https://192.168.0.10:8443/hooks", ErrInvalidURL},
+		{"https://100.64.0.1/hooks", ErrInvalidURL},
+		{"https://0.0.0.0/hooks", ErrInvalidURL},
+		{"https://[::1]/hooks", ErrInvalidURL},
+		{"https://[fd00::1]/hooks", ErrInvalidURL},
+		{"https://[::ffff:127.0.0.1]/hooks", ErrInvalidURL},
+	}
+	for _, tt := range tests {
+		if err := ValidateURL(tt.url); !errors.Is(err, tt.want) {
+			t.Errorf("ValidateURL(%q) = %v, want %v", tt.url, err, tt.want)
+		}
+	}
+}
+
+func TestDefaultClientRefusesInternalAddresses(t *testing.T) {
+	config.SetEnv(config.Production)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("internal"))
+	}))
+	defer srv.Close()
+
+	d := NewDispatcher(&Config{})
+	_, err := d.cfg.Client.Get(srv.URL)
+	if !errors.Is(err, errInternalAddress) {
+		t.Fatalf("request to %s: err = %v, want %v", srv.URL, err, errInternalAddress)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	now := time.Now()
+	tests := []struct {
+		name   string
+		header string
+		want   error
+	}{
+		{"valid", Sign("secret", body, now), nil},
+		{"rotated secret", Sign("secret", body, now) + ",v1=" + computeMAC("old", "0", body), nil},
+		{"wrong secret", Sign("other", body, now), ErrInvalidSignature},
+		{"stale", Sign("secret", body, now.Add(-time.Hour)), ErrStaleSignature},
+		{"malformed", "v1=abc", ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		if err := Verify("secret", tt.header, body, 5*time.Minute); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Verify = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
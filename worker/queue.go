@@ -0,0 +1,257 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Queue stores tasks until a worker picks them up
+type Queue interface {
+	Push(ctx context.Context, task *Task) error
+	// Pop blocks until a task for job is available or ctx is done
+	Pop(ctx context.Context, job string) (*Task, error)
+}
+
+// MemoryQueue is an in-process queue; tasks are lost on restart
+type MemoryQueue struct {
+	size   int
+	mu     sync.Mutex
+	queues map[string]chan *Task
+}
+
+// NewMemoryQueue creates an in-process queue buffering up to size tasks per job
+func NewMemoryQueue(size int) *MemoryQueue {
+	if size <= 0 {
+		size = 1000
+	}
+	return &MemoryQueue{size: size, queues: make(map[string]chan *Task)}
+}
+
+func (q *MemoryQueue) channel(job string) chan *Task {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ch, ok := q.queues[job]
+	if !ok {
+		ch = make(chan *Task, q.size)
+		q.queues[job] = ch
+	}
+	return ch
+}
+
+// Push implements Queue
+func (q *MemoryQueue) Push(ctx context.Context, task *Task) error {
+	select {
+	case q.channel(task.Job) <- task:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Pop implements Queue
+func (q *MemoryQueue) Pop(ctx context.Context, job string) (*Task, error) {
+	select {
+	case task := <-q.channel(job):
+		return task, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Acker is implemented by queues that keep a popped task until it is acknowledged, so the task
+// of a worker that died mid-way is handed out again; the manager acks each task once it is done
+type Acker interface {
+	Ack(ctx context.Context, task *Task) error
+}
+
+// RedisQueue is a durable queue backed by Redis lists, shared across replicas. Popped tasks move
+// to a processing list of this queue until acked; while the queue is popping it heartbeats, and
+// the processing lists of queues that stopped heartbeating for StaleAfter are put back on the
+// queue. Tasks are delivered at least once, so handlers should be idempotent.
+type RedisQueue struct {
+	rdb        *redis.Client
+	prefix     string
+	id         string
+	staleAfter time.Duration
+
+	mu      sync.Mutex
+	jobs    map[string]bool
+	beating bool
+}
+
+// NewRedisQueue creates a Redis-backed queue; prefix namespaces the list keys (defaults to "worker:")
+func NewRedisQueue(rdb *redis.Client, prefix string) *RedisQueue {
+	if prefix == "" {
+		prefix = "worker:"
+	}
+	return &RedisQueue{
+		rdb:        rdb,
+		prefix:     prefix,
+		id:         uuid.NewString(),
+		staleAfter: time.Minute,
+		jobs:       make(map[string]bool),
+	}
+}
+
+// WithStaleAfter sets how long a queue may miss heartbeats before its unacked tasks are handed
+// out again, defaults to a minute; keep it above the manager's ShutdownTimeout
+func (q *RedisQueue) WithStaleAfter(d time.Duration) *RedisQueue {
+	if d > 0 {
+		q.staleAfter = d
+	}
+	return q
+}
+
+func (q *RedisQueue) processingKey(job, id string) string {
+	return q.prefix + job + ":processing:" + id
+}
+
+func (q *RedisQueue) consumersKey(job string) string {
+	return q.prefix + job + ":consumers"
+}
+
+// Push implements Queue
+func (q *RedisQueue) Push(ctx context.Context, task *Task) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+	if err := q.rdb.LPush(ctx, q.prefix+task.Job, data).Err(); err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+	return nil
+}
+
+// Pop implements Queue; the task stays in the processing list of this queue until Ack
+func (q *RedisQueue) Pop(ctx context.Context, job string) (*Task, error) {
+	q.watch(ctx, job)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// Short blocking timeout so shutdown is noticed promptly
+		raw, err := q.rdb.BLMove(ctx, q.prefix+job, q.processingKey(job, q.id), "RIGHT", "LEFT", time.Second).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("failed to dequeue task: %w", err)
+		}
+
+		var task Task
+		if err := json.Unmarshal([]byte(raw), &task); err != nil {
+			q.rdb.LRem(ctx, q.processingKey(job, q.id), 1, raw)
+			return nil, fmt.Errorf("failed to decode task: %w", err)
+		}
+		task.raw = raw
+		return &task, nil
+	}
+}
+
+// Ack implements Acker
+func (q *RedisQueue) Ack(ctx context.Context, task *Task) error {
+	if task.raw == "" {
+		return nil
+	}
+	if err := q.rdb.LRem(ctx, q.processingKey(task.Job, q.id), 1, task.raw).Err(); err != nil {
+		return fmt.Errorf("failed to ack task: %w", err)
+	}
+	return nil
+}
+
+// watch records job for the heartbeat, started by the first Pop and stopped with its ctx
+func (q *RedisQueue) watch(ctx context.Context, job string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.jobs[job] {
+		q.jobs[job] = true
+		// Recover what a previous process left behind before the first task of job
+		q.heartbeat(ctx, job)
+	}
+	if q.beating {
+		return
+	}
+	q.beating = true
+	go func() {
+		ticker := time.NewTicker(q.staleAfter / 4)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				q.mu.Lock()
+				q.beating = false
+				q.mu.Unlock()
+				return
+			case <-ticker.C:
+			}
+			q.mu.Lock()
+			jobs := make([]string, 0, len(q.jobs))
+			for job := range q.jobs {
+				jobs = append(jobs, job)
+			}
+			q.mu.Unlock()
+			for _, job := range jobs {
+				q.heartbeat(ctx, job)
+			}
+		}
+	}()
+}
+
+// heartbeat marks this queue alive for job and puts back the tasks of queues that are not
+func (q *RedisQueue) heartbeat(ctx context.Context, job string) {
+	log := logger.Module("worker").With(zap.String("job", job))
+	now := time.Now()
+	key := q.consumersKey(job)
+	if err := q.rdb.ZAdd(ctx, key, &redis.Z{Score: float64(now.UnixMilli()), Member: q.id}).Err(); err != nil {
+		log.Warn("failed to record worker heartbeat", zap.Error(err))
+		return
+	}
+	stale, err := q.rdb.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Add(-q.staleAfter).UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		log.Warn("failed to list stale workers", zap.Error(err))
+		return
+	}
+	for _, id := range stale {
+		n, err := q.reclaim(ctx, job, id)
+		if err != nil {
+			log.Warn("failed to reclaim tasks of stale worker", zap.String("worker", id), zap.Error(err))
+			continue
+		}
+		if n > 0 {
+			log.Warn("reclaimed tasks of stale worker", zap.String("worker", id), zap.Int("tasks", n))
+		}
+	}
+}
+
+// reclaim moves the unacked tasks of queue id back to the pop end of the queue
+func (q *RedisQueue) reclaim(ctx context.Context, job, id string) (int, error) {
+	n := 0
+	for {
+		err := q.rdb.LMove(ctx, q.processingKey(job, id), q.prefix+job, "RIGHT", "RIGHT").Err()
+		if err == redis.Nil {
+			break
+		}
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, q.rdb.ZRem(ctx, q.consumersKey(job), id).Err()
+}
@@ -0,0 +1,107 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func newTestRedis(t *testing.T) *redis.Client {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return rdb
+}
+
+func TestRedisQueueAck(t *testing.T) {
+	rdb := newTestRedis(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q := NewRedisQueue(rdb, "t:")
+
+	if err := q.Push(ctx, &Task{ID: "1", Job: "mail"}); err != nil {
+		t.Fatal(err)
+	}
+	task, err := q.Pop(ctx, "mail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := rdb.LLen(ctx, q.processingKey("mail", q.id)).Val(); n != 1 {
+		t.Fatalf("processing list has %d tasks before ack, want 1", n)
+	}
+	if err := q.Ack(ctx, task); err != nil {
+		t.Fatal(err)
+	}
+	if n := rdb.LLen(ctx, q.processingKey("mail", q.id)).Val(); n != 0 {
+		t.Fatalf("processing list has %d tasks after ack, want 0", n)
+	}
+}
+
+func TestRedisQueueReclaimsTasksOfDeadWorker(t *testing.T) {
+	rdb := newTestRedis(t)
+	ctx := context.Background()
+
+	// The first worker pops a task and dies before acking it
+	deadCtx, die := context.WithCancel(ctx)
+	dead := NewRedisQueue(rdb, "t:").WithStaleAfter(200 * time.Millisecond)
+	if err := dead.Push(ctx, &Task{ID: "1", Job: "mail"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dead.Pop(deadCtx, "mail"); err != nil {
+		t.Fatal(err)
+	}
+	die()
+
+	popCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	live := NewRedisQueue(rdb, "t:").WithStaleAfter(200 * time.Millisecond)
+	task, err := live.Pop(popCtx, "mail")
+	if err != nil {
+		t.Fatalf("task of the dead worker was not handed out again: %v", err)
+	}
+	if task.ID != "1" {
+		t.Fatalf("popped task %q, want 1", task.ID)
+	}
+	if n := rdb.LLen(ctx, dead.processingKey("mail", dead.id)).Val(); n != 0 {
+		t.Errorf("dead worker still holds %d tasks", n)
+	}
+}
+
+func TestManagerAcksTasks(t *testing.T) {
+	rdb := newTestRedis(t)
+	q := NewRedisQueue(rdb, "t:")
+	m := NewManager(&Config{Queue: q})
+	var runs atomic.Int32
+	m.Register("mail", func(ctx context.Context, task *Task) error {
+		if runs.Add(1) == 1 {
+			return errors.New("smtp down")
+		}
+		return nil
+	}, JobOptions{MaxRetries: 1, RetryBackoff: time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.Start(ctx)
+	if _, err := m.Enqueue(ctx, "mail", map[string]string{"to": "a@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for m.Stats()["mail"].Processed == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := m.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := runs.Load(); got != 2 {
+		t.Errorf("handler ran %d times, want 2", got)
+	}
+	if n := rdb.LLen(context.Background(), q.processingKey("mail", q.id)).Val(); n != 0 {
+		t.Errorf("processing list has %d tasks after the job finished, want 0", n)
+	}
+}
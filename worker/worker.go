@@ -0,0 +1,381 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/retry"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Task is a unit of work for a registered job
+type Task struct {
+	ID         string          `json:"id"`
+	Job        string          `json:"job"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	Attempt    int             `json:"attempt"`
+	EnqueuedAt time.Time       `json:"enqueued_at"`
+
+	raw string // as popped from a RedisQueue, to ack it
+}
+
+// Decode unmarshals the task payload into v
+func (t *Task) Decode(v interface{}) error {
+	if err := json.Unmarshal(t.Payload, v); err != nil {
+		return fmt.Errorf("failed to decode %s task payload: %w", t.Job, err)
+	}
+	return nil
+}
+
+// Handler processes a queued task
+type Handler func(ctx context.Context, task *Task) error
+
+// JobOptions controls how a registered job is executed
+type JobOptions struct {
+	Concurrency  int           // parallel workers, defaults to 1
+	MaxRetries   int           // retries after the first attempt; retry.Permanent errors are not retried
+	RetryBackoff time.Duration // initial backoff, doubled per retry
+	Timeout      time.Duration // per-attempt timeout, 0 means none
+}
+
+// Config holds the manager settings
+type Config struct {
+	Queue           Queue         // defaults to an in-memory queue
+	ShutdownTimeout time.Duration // how long Run waits for in-flight tasks, defaults to 30s
+}
+
+// JobStats are the per-job counters exposed by Stats
+type JobStats struct {
+	Processed   int64         `json:"processed"`
+	Failed      int64         `json:"failed"`
+	Retried     int64         `json:"retried"`
+	Panics      int64         `json:"panics"`
+	InFlight    int64         `json:"in_flight"`
+	LastError   string        `json:"last_error,omitempty"`
+	LastRunAt   time.Time     `json:"last_run_at,omitempty"`
+	AvgDuration time.Duration `json:"avg_duration"`
+	totalTime   time.Duration
+}
+
+type job struct {
+	name    string
+	handler Handler
+	opts    JobOptions
+}
+
+// Manager runs registered jobs, periodic jobs and supervised goroutines
+type Manager struct {
+	cfg      *Config
+	queue    Queue
+	mu       sync.RWMutex
+	jobs     map[string]*job
+	periodic []func(ctx context.Context)
+	stats    map[string]*JobStats
+	wg       sync.WaitGroup
+	cancel   context.CancelFunc
+	started  bool
+}
+
+// ErrUnknownJob is returned when enqueueing a job that was never registered
+var ErrUnknownJob = errors.New("unknown job")
+
+// NewManager creates a worker manager
+func NewManager(cfg *Config) *Manager {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	queue := cfg.Queue
+	if queue == nil {
+		queue = NewMemoryQueue(0)
+	}
+	return &Manager{
+		cfg:   cfg,
+		queue: queue,
+		jobs:  make(map[string]*job),
+		stats: make(map[string]*JobStats),
+	}
+}
+
+// Register adds a named job processed from the queue; it must be called before Start
+func (m *Manager) Register(name string, handler Handler, opts JobOptions) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = time.Second
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[name] = &job{name: name, handler: handler, opts: opts}
+	m.stats[name] = &JobStats{}
+}
+
+// Every registers a periodic job (e.g. a cache warmer) that runs fn every interval
+func (m *Manager) Every(name string, interval time.Duration, fn func(ctx context.Context) error, opts JobOptions) {
+	m.mu.Lock()
+	m.stats[name] = &JobStats{}
+	m.periodic = append(m.periodic, func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			m.execute(ctx, name, opts, func(ctx context.Context) error { return fn(ctx) })
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+	m.mu.Unlock()
+}
+
+// Enqueue queues a task for a registered job and returns its ID
+func (m *Manager) Enqueue(ctx context.Context, name string, payload interface{}) (string, error) {
+	m.mu.RLock()
+	_, ok := m.jobs[name]
+	m.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+
+	var data json.RawMessage
+	if payload != nil {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal task payload: %w", err)
+		}
+		data = raw
+	}
+
+	task := &Task{
+		ID:         uuid.New().String(),
+		Job:        name,
+		Payload:    data,
+		EnqueuedAt: time.Now().UTC(),
+	}
+	if err := m.queue.Push(ctx, task); err != nil {
+		return "", err
+	}
+	return task.ID, nil
+}
+
+// Go runs fn in a supervised goroutine with panic recovery that stops with the manager,
+// replacing naked `go func()` loops
+func (m *Manager) Go(name string, fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	m.stats[name] = &JobStats{}
+	m.periodic = append(m.periodic, func(ctx context.Context) {
+		m.execute(ctx, name, JobOptions{}, fn)
+	})
+	m.mu.Unlock()
+}
+
+// Start launches all workers; they stop when ctx is cancelled or Stop is called
+func (m *Manager) Start(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.started {
+		return
+	}
+	m.started = true
+
+	ctx, m.cancel = context.WithCancel(ctx)
+
+	for _, j := range m.jobs {
+		for i := 0; i < j.opts.Concurrency; i++ {
+			m.wg.Add(1)
+			go func(j *job) {
+				defer m.wg.Done()
+				m.consume(ctx, j)
+			}(j)
+		}
+	}
+
+	for _, run := range m.periodic {
+		m.wg.Add(1)
+		go func(run func(ctx context.Context)) {
+			defer m.wg.Done()
+			run(ctx)
+		}(run)
+	}
+
+	logger.Module("worker").Info("worker manager started", zap.Int("jobs", len(m.jobs)), zap.Int("background", len(m.periodic)))
+}
+
+// Stop cancels all workers and waits for in-flight tasks until ctx is done
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.RLock()
+	cancel := m.cancel
+	m.mu.RUnlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logger.Module("worker").Info("worker manager stopped")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("worker shutdown timed out: %w", ctx.Err())
+	}
+}
+
+// Run starts the manager and blocks until SIGTERM/SIGINT or ctx cancellation, then shuts down gracefully
+func (m *Manager) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	m.Start(ctx)
+	<-ctx.Done()
+
+	timeout := m.cfg.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return m.Stop(shutdownCtx)
+}
+
+// Stats returns a snapshot of per-job counters
+func (m *Manager) Stats() map[string]JobStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := make(map[string]JobStats, len(m.stats))
+	for name, s := range m.stats {
+		stats[name] = *s
+	}
+	return stats
+}
+
+// JobNames returns the registered queue job names
+func (m *Manager) JobNames() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.jobs))
+	for name := range m.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// consume pulls tasks for a job until ctx is done
+func (m *Manager) consume(ctx context.Context, j *job) {
+	log := logger.Module("worker").With(zap.String("job", j.name))
+	for {
+		task, err := m.queue.Pop(ctx, j.name)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Error("failed to dequeue task", zap.Error(err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		// In-flight tasks finish even when shutdown starts
+		m.execute(context.WithoutCancel(ctx), j.name, j.opts, func(ctx context.Context) error {
+			return j.handler(ctx, task)
+		}, zap.String("task_id", task.ID))
+		if acker, ok := m.queue.(Acker); ok {
+			if err := acker.Ack(context.WithoutCancel(ctx), task); err != nil {
+				log.Error("failed to ack task", zap.String("task_id", task.ID), zap.Error(err))
+			}
+		}
+	}
+}
+
+// execute runs fn with retries, timeouts, panic recovery and stats bookkeeping
+func (m *Manager) execute(ctx context.Context, name string, opts JobOptions, fn func(ctx context.Context) error, fields ...zap.Field) {
+	log := logger.Module("worker").With(append(fields, zap.String("job", name))...)
+	m.updateStats(name, func(s *JobStats) { s.InFlight++ })
+
+	start := time.Now()
+	policy := retry.Policy{
+		MaxAttempts:    opts.MaxRetries + 1,
+		InitialBackoff: opts.RetryBackoff,
+		MaxBackoff:     time.Duration(math.MaxInt64),
+		Retryable:      func(err error) bool { return !retry.IsPermanent(err) },
+		OnRetry: func(attempt int, err error, wait time.Duration) {
+			log.Warn("job attempt failed", zap.Int("attempt", attempt), zap.Error(err))
+			m.updateStats(name, func(s *JobStats) { s.Retried++ })
+		},
+	}
+	err := retry.Do(ctx, policy, func(ctx context.Context) error {
+		return m.attempt(ctx, name, opts, fn)
+	})
+
+	elapsed := time.Since(start)
+	m.updateStats(name, func(s *JobStats) {
+		s.InFlight--
+		s.LastRunAt = start
+		s.Processed++
+		s.totalTime += elapsed
+		s.AvgDuration = s.totalTime / time.Duration(s.Processed)
+		if err != nil {
+			s.Failed++
+			s.LastError = err.Error()
+		}
+	})
+
+	if err != nil {
+		log.Error("job failed", zap.Duration("duration", elapsed), zap.Error(err))
+	}
+}
+
+// attempt runs fn once with the per-attempt timeout and converts panics into errors
+func (m *Manager) attempt(ctx context.Context, name string, opts JobOptions, fn func(ctx context.Context) error) (err error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			m.updateStats(name, func(s *JobStats) { s.Panics++ })
+			logger.Module("worker").Error("job panicked", zap.String("job", name), zap.Any("panic", r), zap.ByteString("stack", debug.Stack()))
+			err = fmt.Errorf("job %s panicked: %v", name, r)
+		}
+	}()
+
+	return fn(ctx)
+}
+
+func (m *Manager) updateStats(name string, update func(s *JobStats)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.stats[name]
+	if !ok {
+		s = &JobStats{}
+		m.stats[name] = s
+	}
+	update(s)
+}
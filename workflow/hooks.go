@@ -0,0 +1,56 @@
+package workflow
+
+import (
+	"context"
+
+	"github.com/Masharah-Advisory/common/events"
+	"github.com/Masharah-Advisory/common/notify"
+)
+
+// TransitionPayload is the payload of events published by Publish
+type TransitionPayload struct {
+	Workflow   string      `json:"workflow"`
+	Transition string      `json:"transition"`
+	From       State       `json:"from"`
+	To         State       `json:"to"`
+	ActorID    *uint64     `json:"actor_id,omitempty"`
+	Reason     string      `json:"reason,omitempty"`
+	Entity     interface{} `json:"entity"`
+}
+
+// Publish returns an After hook publishing "<workflow>.<transition>" events to topic
+func Publish[T any](w *Workflow[T], pub events.Publisher, topic string) Hook[T] {
+	return func(ctx context.Context, c *Change[T]) error {
+		event, err := events.NewEvent(ctx, w.def.Name+"."+c.Transition, TransitionPayload{
+			Workflow:   w.def.Name,
+			Transition: c.Transition,
+			From:       c.From,
+			To:         c.To,
+			ActorID:    c.ActorID,
+			Reason:     c.Reason,
+			Entity:     c.Entity,
+		})
+		if err != nil {
+			return err
+		}
+		event.Key = w.entityID(ctx, c.Entity)
+		return pub.Publish(ctx, topic, event)
+	}
+}
+
+// Notify returns an After hook sending a notification event to the users picked by recipients;
+// its data carries transition, from, to and reason
+func Notify[T any](d *notify.Dispatcher, event string, recipients func(c *Change[T]) []uint64) Hook[T] {
+	return func(ctx context.Context, c *Change[T]) error {
+		userIDs := recipients(c)
+		if len(userIDs) == 0 {
+			return nil
+		}
+		return d.NotifyMany(ctx, userIDs, event, map[string]interface{}{
+			"transition": c.Transition,
+			"from":       string(c.From),
+			"to":         string(c.To),
+			"reason":     c.Reason,
+		})
+	}
+}
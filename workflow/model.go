@@ -0,0 +1,26 @@
+package workflow
+
+import (
+	"time"
+)
+
+// Record is one persisted transition: who moved which entity from where to where, and why
+type Record struct {
+	ID         uint64                 `json:"id" gorm:"primaryKey;autoIncrement"`
+	Workflow   string                 `json:"workflow" gorm:"index:idx_workflow_entity;size:128;not null"`
+	EntityID   string                 `json:"entity_id" gorm:"index:idx_workflow_entity;size:64;not null"`
+	Transition string                 `json:"transition" gorm:"size:128;not null"`
+	From       State                  `json:"from" gorm:"column:from_state;size:64"`
+	To         State                  `json:"to" gorm:"column:to_state;index;size:64;not null"`
+	ActorID    *uint64                `json:"actor_id,omitempty" gorm:"index"`
+	Reason     string                 `json:"reason,omitempty" gorm:"type:text"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty" gorm:"serializer:json"`
+	TenantID   string                 `json:"tenant_id,omitempty" gorm:"index;size:64"`
+	RequestID  string                 `json:"request_id,omitempty" gorm:"size:128"`
+	CreatedAt  time.Time              `json:"created_at" gorm:"index"`
+}
+
+// TableName overrides the table name
+func (Record) TableName() string {
+	return "workflow_transitions"
+}
@@ -0,0 +1,103 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InState is a GORM scope selecting entities in any of states:
+// db.Scopes(wf.InState("open", "in_review")).Find(&cases)
+func (w *Workflow[T]) InState(states ...State) func(*gorm.DB) *gorm.DB {
+	values := make([]string, len(states))
+	for i, s := range states {
+		values[i] = string(s)
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(w.column+" IN ?", values)
+	}
+}
+
+// CountByState counts entities per state; scopes narrow the set, e.g. to a tenant
+func (w *Workflow[T]) CountByState(ctx context.Context, scopes ...func(*gorm.DB) *gorm.DB) (map[State]int64, error) {
+	var rows []struct {
+		State State
+		Count int64
+	}
+	err := w.db.WithContext(ctx).Model(new(T)).Scopes(scopes...).
+		Select(w.column + " AS state, COUNT(*) AS count").
+		Group(w.column).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count %s states: %w", w.def.Name, err)
+	}
+	counts := make(map[State]int64, len(rows))
+	for _, r := range rows {
+		counts[r.State] = r.Count
+	}
+	return counts, nil
+}
+
+// History returns the transitions of an entity, oldest first
+func (w *Workflow[T]) History(ctx context.Context, entity *T) ([]Record, error) {
+	var records []Record
+	err := w.db.WithContext(ctx).
+		Where("workflow = ? AND entity_id = ?", w.def.Name, w.entityID(ctx, entity)).
+		Order("created_at, id").
+		Find(&records).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s history: %w", w.def.Name, err)
+	}
+	return records, nil
+}
+
+// LastTransition returns the entity's most recent transition, or nil when it never moved
+func (w *Workflow[T]) LastTransition(ctx context.Context, entity *T) (*Record, error) {
+	var r Record
+	err := w.db.WithContext(ctx).
+		Where("workflow = ? AND entity_id = ?", w.def.Name, w.entityID(ctx, entity)).
+		Order("created_at DESC, id DESC").
+		First(&r).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s history: %w", w.def.Name, err)
+	}
+	return &r, nil
+}
+
+// EnteredBetween returns the IDs of entities that entered state within [from, to), e.g. the
+// cases closed last month
+func (w *Workflow[T]) EnteredBetween(ctx context.Context, state State, from, to time.Time) ([]string, error) {
+	var ids []string
+	err := w.db.WithContext(ctx).Model(&Record{}).
+		Where("workflow = ? AND to_state = ? AND created_at >= ? AND created_at < ?", w.def.Name, state, from, to).
+		Distinct().
+		Pluck("entity_id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s history: %w", w.def.Name, err)
+	}
+	return ids, nil
+}
+
+// TimeInState sums how long the entity spent in each state according to its history; the
+// current state counts up to now
+func (w *Workflow[T]) TimeInState(ctx context.Context, entity *T) (map[State]time.Duration, error) {
+	records, err := w.History(ctx, entity)
+	if err != nil {
+		return nil, err
+	}
+	durations := make(map[State]time.Duration)
+	for i, r := range records {
+		end := time.Now()
+		if i+1 < len(records) {
+			end = records[i+1].CreatedAt
+		}
+		durations[r.To] += end.Sub(r.CreatedAt)
+	}
+	return durations, nil
+}
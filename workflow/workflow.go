@@ -0,0 +1,323 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/Masharah-Advisory/common/apperror"
+	"github.com/Masharah-Advisory/common/ctxutil"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// State is a named status of an entity
+type State string
+
+// Errors returned by Fire
+var (
+	ErrUnknownTransition = apperror.New("workflow_unknown_transition", apperror.KindBadRequest, "unknown transition").
+				WithKey("workflow.unknown_transition")
+	ErrInvalidTransition = apperror.New("workflow_invalid_transition", apperror.KindConflict, "transition not allowed from the current state").
+				WithKey("workflow.invalid_transition")
+	ErrStale = apperror.New("workflow_stale", apperror.KindConflict, "the record was changed by someone else, reload and retry").
+			WithKey("workflow.stale")
+)
+
+// Change describes a transition in progress; guards and hooks receive it
+type Change[T any] struct {
+	Entity     *T
+	Transition string
+	From       State
+	To         State
+	ActorID    *uint64
+	Reason     string
+	Metadata   map[string]interface{}
+	// Tx is the transaction of the state update; Before hooks can write through it
+	Tx *gorm.DB
+}
+
+// Guard rejects a transition by returning an error, e.g. an apperror explaining what is missing
+type Guard[T any] func(ctx context.Context, c *Change[T]) error
+
+// Hook runs a side effect of a transition
+type Hook[T any] func(ctx context.Context, c *Change[T]) error
+
+// Transition moves entities from any of From to To
+type Transition[T any] struct {
+	Name string
+	From []State
+	To   State
+	// Guards all have to pass
+	Guards []Guard[T]
+	// Before hooks run inside the transaction and abort it on error
+	Before []Hook[T]
+	// After hooks run once the transaction committed (notifications, events); errors are logged
+	After []Hook[T]
+}
+
+// Definition declares a workflow over entities of type T
+type Definition[T any] struct {
+	Name string
+	// Field is the string-typed struct field holding the state (default "Status")
+	Field   string
+	Initial State
+	// Transitions are matched by name
+	Transitions []Transition[T]
+	// OnEnter hooks run after the transition's After hooks when an entity enters a state
+	OnEnter map[State][]Hook[T]
+}
+
+// Workflow applies a definition to entities stored with GORM
+type Workflow[T any] struct {
+	db          *gorm.DB
+	def         Definition[T]
+	schema      *schema.Schema
+	column      string
+	transitions map[string]*Transition[T]
+	log         *zap.Logger
+}
+
+// New validates def and creates the workflow; migrate Record first
+func New[T any](db *gorm.DB, def Definition[T]) (*Workflow[T], error) {
+	if def.Field == "" {
+		def.Field = "Status"
+	}
+	field, ok := reflect.TypeOf((*T)(nil)).Elem().FieldByName(def.Field)
+	if !ok || field.Type.Kind() != reflect.String {
+		return nil, fmt.Errorf("workflow %s: %T has no string field %s", def.Name, *new(T), def.Field)
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, fmt.Errorf("workflow %s: failed to parse model: %w", def.Name, err)
+	}
+
+	w := &Workflow[T]{
+		db:          db,
+		def:         def,
+		schema:      stmt.Schema,
+		column:      stmt.Schema.LookUpField(def.Field).DBName,
+		transitions: make(map[string]*Transition[T]),
+		log:         logger.Module("workflow"),
+	}
+	for i := range def.Transitions {
+		t := &def.Transitions[i]
+		if _, dup := w.transitions[t.Name]; dup {
+			return nil, fmt.Errorf("workflow %s: duplicate transition %s", def.Name, t.Name)
+		}
+		if t.To == "" {
+			return nil, fmt.Errorf("workflow %s: transition %s has no target state", def.Name, t.Name)
+		}
+		w.transitions[t.Name] = t
+	}
+	return w, nil
+}
+
+// Name returns the workflow name
+func (w *Workflow[T]) Name() string {
+	return w.def.Name
+}
+
+// State returns the entity's current state
+func (w *Workflow[T]) State(entity *T) State {
+	return State(reflect.ValueOf(entity).Elem().FieldByName(w.def.Field).String())
+}
+
+func (w *Workflow[T]) setState(entity *T, s State) {
+	reflect.ValueOf(entity).Elem().FieldByName(w.def.Field).SetString(string(s))
+}
+
+// Init puts a new entity in the initial state; call it before creating the record
+func (w *Workflow[T]) Init(entity *T) {
+	w.setState(entity, w.def.Initial)
+}
+
+// Option adjusts a transition
+type Option func(*options)
+
+type options struct {
+	actorID  *uint64
+	reason   string
+	metadata map[string]interface{}
+	tx       *gorm.DB
+}
+
+// Reason records why the transition happened
+func Reason(reason string) Option {
+	return func(o *options) { o.reason = reason }
+}
+
+// Meta attaches a value to the history record
+func Meta(key string, value interface{}) Option {
+	return func(o *options) {
+		if o.metadata == nil {
+			o.metadata = map[string]interface{}{}
+		}
+		o.metadata[key] = value
+	}
+}
+
+// Actor overrides the acting user, which defaults to the user in the context
+func Actor(userID uint64) Option {
+	return func(o *options) { o.actorID = &userID }
+}
+
+// WithTx runs the transition inside an existing transaction
+func WithTx(tx *gorm.DB) Option {
+	return func(o *options) { o.tx = tx }
+}
+
+// Can reports why the transition cannot be fired now, or nil when it can
+func (w *Workflow[T]) Can(ctx context.Context, entity *T, transition string) error {
+	t, ok := w.transitions[transition]
+	if !ok {
+		return ErrUnknownTransition
+	}
+	change := &Change[T]{Entity: entity, Transition: t.Name, From: w.State(entity), To: t.To, Tx: w.db.WithContext(ctx)}
+	if id, ok := ctxutil.UserID(ctx); ok {
+		change.ActorID = &id
+	}
+	return w.check(ctx, t, change)
+}
+
+// Available lists the transitions that can be fired on the entity now
+func (w *Workflow[T]) Available(ctx context.Context, entity *T) []string {
+	var names []string
+	for _, t := range w.def.Transitions {
+		if w.Can(ctx, entity, t.Name) == nil {
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+func (w *Workflow[T]) check(ctx context.Context, t *Transition[T], change *Change[T]) error {
+	allowed := false
+	for _, from := range t.From {
+		if from == change.From {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return ErrInvalidTransition
+	}
+	for _, guard := range t.Guards {
+		if err := guard(ctx, change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Fire runs a transition: it checks the source state and guards, updates the state only if
+// nobody changed it meanwhile, records the history and runs the hooks. The entity is updated
+// in place.
+func (w *Workflow[T]) Fire(ctx context.Context, entity *T, transition string, opts ...Option) error {
+	t, ok := w.transitions[transition]
+	if !ok {
+		return ErrUnknownTransition
+	}
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.actorID == nil {
+		if id, ok := ctxutil.UserID(ctx); ok {
+			o.actorID = &id
+		}
+	}
+
+	change := &Change[T]{
+		Entity:     entity,
+		Transition: t.Name,
+		From:       w.State(entity),
+		To:         t.To,
+		ActorID:    o.actorID,
+		Reason:     o.reason,
+		Metadata:   o.metadata,
+	}
+
+	run := func(tx *gorm.DB) error {
+		change.Tx = tx
+		if err := w.check(ctx, t, change); err != nil {
+			return err
+		}
+
+		res := tx.Model(entity).Where(w.column+" = ?", string(change.From)).Update(w.column, string(change.To))
+		if res.Error != nil {
+			return fmt.Errorf("failed to update %s state: %w", w.def.Name, res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return ErrStale
+		}
+		w.setState(entity, change.To)
+
+		record := &Record{
+			Workflow:   w.def.Name,
+			EntityID:   w.entityID(ctx, entity),
+			Transition: t.Name,
+			From:       change.From,
+			To:         change.To,
+			ActorID:    change.ActorID,
+			Reason:     change.Reason,
+			Metadata:   change.Metadata,
+			TenantID:   ctxutil.TenantID(ctx),
+			RequestID:  ctxutil.RequestID(ctx),
+		}
+		if err := tx.Create(record).Error; err != nil {
+			return fmt.Errorf("failed to record %s transition: %w", w.def.Name, err)
+		}
+
+		for _, hook := range t.Before {
+			if err := hook(ctx, change); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var err error
+	if o.tx != nil {
+		err = run(o.tx.WithContext(ctx))
+	} else {
+		err = w.db.WithContext(ctx).Transaction(run)
+	}
+	if err != nil {
+		w.setState(entity, change.From)
+		return err
+	}
+
+	change.Tx = w.db.WithContext(ctx)
+	hooks := append(append([]Hook[T]{}, t.After...), w.def.OnEnter[change.To]...)
+	for _, hook := range hooks {
+		if err := hook(ctx, change); err != nil {
+			w.log.Warn("workflow hook failed",
+				zap.String("workflow", w.def.Name),
+				zap.String("transition", t.Name),
+				zap.Error(err),
+			)
+		}
+	}
+	return nil
+}
+
+// entityID returns the primary key of entity as a string
+func (w *Workflow[T]) entityID(ctx context.Context, entity *T) string {
+	pk := w.schema.PrioritizedPrimaryField
+	if pk == nil {
+		return ""
+	}
+	v, _ := pk.ValueOf(ctx, reflect.ValueOf(entity).Elem())
+	switch id := v.(type) {
+	case uint64:
+		return strconv.FormatUint(id, 10)
+	case string:
+		return id
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,152 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// Client is one authenticated connection
+type Client struct {
+	ID       string
+	UserID   uint64
+	TenantID string
+	Lang     string
+
+	hub  *Hub
+	conn *websocket.Conn
+	send chan []byte
+
+	mu     sync.Mutex
+	topics map[string]struct{}
+	closed bool
+}
+
+func newClient(h *Hub, conn *websocket.Conn, userID uint64, tenantID, lang string) *Client {
+	return &Client{
+		ID:       uuid.NewString(),
+		UserID:   userID,
+		TenantID: tenantID,
+		Lang:     lang,
+		hub:      h,
+		conn:     conn,
+		send:     make(chan []byte, h.cfg.SendBuffer),
+		topics:   make(map[string]struct{}),
+	}
+}
+
+// Send queues msg for this connection; a client that cannot keep up is disconnected rather
+// than blocking the hub
+func (c *Client) Send(msg *Message) {
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		c.hub.log.Warn("failed to encode message", zap.Error(err))
+		return
+	}
+	c.sendRaw(raw)
+}
+
+func (c *Client) sendRaw(raw []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	select {
+	case c.send <- raw:
+	default:
+		c.hub.log.Warn("dropping slow websocket client", zap.String("client_id", c.ID), zap.Uint64("user_id", c.UserID))
+		c.closeLocked()
+	}
+}
+
+// Topics returns the topics the client is subscribed to
+func (c *Client) Topics() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	topics := make([]string, 0, len(c.topics))
+	for t := range c.topics {
+		topics = append(topics, t)
+	}
+	return topics
+}
+
+// Close disconnects the client
+func (c *Client) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closeLocked()
+}
+
+func (c *Client) closeLocked() {
+	if c.closed {
+		return
+	}
+	c.closed = true
+	close(c.send)
+}
+
+// readPump reads frames until the connection fails; it owns unregistering the client
+func (c *Client) readPump(ctx context.Context) {
+	defer func() {
+		c.hub.unregister(c)
+		c.Close()
+		_ = c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(c.hub.cfg.MaxMessageBytes)
+	_ = c.conn.SetReadDeadline(time.Now().Add(c.hub.cfg.PongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(c.hub.cfg.PongWait))
+	})
+
+	for {
+		_, raw, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure, websocket.CloseNoStatusReceived) {
+				c.hub.log.Debug("websocket read failed", zap.String("client_id", c.ID), zap.Error(err))
+			}
+			return
+		}
+
+		var msg Message
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			c.Send(&Message{Type: TypeError, Data: json.RawMessage(`"invalid message"`)})
+			continue
+		}
+		c.hub.handle(ctx, c, &msg)
+	}
+}
+
+// writePump writes queued frames and pings; it exits when send is closed or a write fails
+func (c *Client) writePump() {
+	ticker := time.NewTicker(c.hub.cfg.PingInterval)
+	defer func() {
+		ticker.Stop()
+		_ = c.conn.Close()
+	}()
+
+	for {
+		select {
+		case raw, ok := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(c.hub.cfg.WriteWait))
+			if !ok {
+				_ = c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, raw); err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(c.hub.cfg.WriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,390 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masharah-Advisory/common/config"
+	"github.com/Masharah-Advisory/common/ctxutil"
+	"github.com/Masharah-Advisory/common/i18n"
+	logger "github.com/Masharah-Advisory/common/loggers"
+	"github.com/Masharah-Advisory/common/middleware"
+	"github.com/Masharah-Advisory/common/response"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// Config holds the hub settings
+type Config struct {
+	// Redis bridges messages between replicas; nil keeps delivery local to this process
+	Redis   *redis.Client
+	Channel string // pub/sub channel, defaults to "ws:messages"
+
+	// JWTSecret defaults to utils.JWTSecret, as in AuthMiddleware
+	JWTSecret string
+	// AllowedOrigins for the upgrade; empty allows any origin outside production and only
+	// same-origin requests in production
+	AllowedOrigins []string
+
+	PingInterval    time.Duration // defaults to 30s
+	PongWait        time.Duration // read deadline renewed by pongs, defaults to 60s
+	WriteWait       time.Duration // defaults to 10s
+	MaxMessageBytes int64         // defaults to 64KB
+	SendBuffer      int           // queued frames per client, defaults to 64
+
+	// Authorize decides whether a client may subscribe to a topic; nil denies every subscription
+	Authorize func(c *Client, topic string) bool
+	// OnMessage receives client frames other than subscribe/unsubscribe
+	OnMessage func(ctx context.Context, c *Client, msg *Message)
+	// OnConnect and OnDisconnect observe the connection lifecycle
+	OnConnect    func(c *Client)
+	OnDisconnect func(c *Client)
+}
+
+// Hub tracks the connections of this replica by user and topic
+type Hub struct {
+	cfg      *Config
+	log      *zap.Logger
+	upgrader websocket.Upgrader
+
+	mu      sync.RWMutex
+	clients map[*Client]struct{}
+	users   map[uint64]map[*Client]struct{}
+	topics  map[string]map[*Client]struct{}
+}
+
+// NewHub creates a hub; call Run to start the Redis bridge
+func NewHub(cfg *Config) *Hub {
+	if cfg.Channel == "" {
+		cfg.Channel = "ws:messages"
+	}
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = 30 * time.Second
+	}
+	if cfg.PongWait <= cfg.PingInterval {
+		cfg.PongWait = 2 * cfg.PingInterval
+	}
+	if cfg.WriteWait <= 0 {
+		cfg.WriteWait = 10 * time.Second
+	}
+	if cfg.MaxMessageBytes <= 0 {
+		cfg.MaxMessageBytes = 64 << 10
+	}
+	if cfg.SendBuffer <= 0 {
+		cfg.SendBuffer = 64
+	}
+
+	h := &Hub{
+		cfg:     cfg,
+		log:     logger.Module("ws"),
+		clients: make(map[*Client]struct{}),
+		users:   make(map[uint64]map[*Client]struct{}),
+		topics:  make(map[string]map[*Client]struct{}),
+	}
+	h.upgrader = websocket.Upgrader{
+		ReadBufferSize:  4096,
+		WriteBufferSize: 4096,
+		Subprotocols:    []string{"bearer"},
+		CheckOrigin:     h.checkOrigin,
+	}
+	return h
+}
+
+// Handler authenticates the request with the AuthMiddleware JWT rules and upgrades it. Browsers
+// cannot set headers on WebSocket requests, so the token is also accepted as the access_token
+// query parameter or as the "bearer, <token>" subprotocol pair.
+func (h *Hub) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := bearerToken(c.Request)
+		if token == "" {
+			response.Unauthorized(c, i18n.T(c, "missing_authorization_header"))
+			c.Abort()
+			return
+		}
+		claims, err := middleware.ParseToken(token, h.cfg.JWTSecret)
+		if err != nil {
+			logger.FromContext(c).Debug("websocket jwt validation failed", zap.Error(err))
+			response.Unauthorized(c, i18n.T(c, "invalid_or_expired_token"))
+			c.Abort()
+			return
+		}
+
+		conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			// Upgrade has already written the HTTP error
+			logger.FromContext(c).Debug("websocket upgrade failed", zap.Error(err))
+			return
+		}
+
+		client := newClient(h, conn, claims.UserID, ctxutil.TenantID(c), i18n.Lang(c))
+		h.register(client)
+
+		// The request context ends with the handler, the connection outlives it
+		ctx := context.WithoutCancel(c.Request.Context())
+		go client.writePump()
+		go client.readPump(ctx)
+	}
+}
+
+// Run bridges messages published by any replica to the local connections until ctx is done.
+// Without Redis it only waits for ctx.
+func (h *Hub) Run(ctx context.Context) error {
+	if h.cfg.Redis == nil {
+		<-ctx.Done()
+		return nil
+	}
+
+	sub := h.cfg.Redis.Subscribe(ctx, h.cfg.Channel)
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", h.cfg.Channel, err)
+	}
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case m, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			var env envelope
+			if err := json.Unmarshal([]byte(m.Payload), &env); err != nil {
+				h.log.Warn("invalid websocket envelope", zap.Error(err))
+				continue
+			}
+			h.deliver(&env)
+		}
+	}
+}
+
+// SendToUser delivers msg to every connection of userID on any replica
+func (h *Hub) SendToUser(ctx context.Context, userID uint64, msg *Message) error {
+	return h.publish(ctx, &envelope{Target: targetUser, UserID: userID, Message: msg})
+}
+
+// Publish delivers msg to every subscriber of topic on any replica
+func (h *Hub) Publish(ctx context.Context, topic string, msg *Message) error {
+	if msg.Topic == "" {
+		msg.Topic = topic
+	}
+	return h.publish(ctx, &envelope{Target: targetTopic, Topic: topic, Message: msg})
+}
+
+// Broadcast delivers msg to every connection, limited to tenantID when it is not empty
+func (h *Hub) Broadcast(ctx context.Context, tenantID string, msg *Message) error {
+	return h.publish(ctx, &envelope{Target: targetAll, TenantID: tenantID, Message: msg})
+}
+
+// Connections returns the number of connections on this replica
+func (h *Hub) Connections() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// IsOnline reports whether userID has a connection on this replica
+func (h *Hub) IsOnline(userID uint64) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.users[userID]) > 0
+}
+
+// Shutdown closes every connection with a going-away frame; use it as a server shutdown hook
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.clients))
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	h.mu.RUnlock()
+
+	for _, c := range clients {
+		c.Close()
+	}
+	return nil
+}
+
+func (h *Hub) publish(ctx context.Context, env *envelope) error {
+	if h.cfg.Redis == nil {
+		h.deliver(env)
+		return nil
+	}
+	raw, err := json.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("failed to encode websocket message: %w", err)
+	}
+	// Every replica, including this one, delivers from the subscription
+	if err := h.cfg.Redis.Publish(ctx, h.cfg.Channel, raw).Err(); err != nil {
+		return fmt.Errorf("failed to publish websocket message: %w", err)
+	}
+	return nil
+}
+
+// deliver sends an envelope to the matching local connections
+func (h *Hub) deliver(env *envelope) {
+	raw, err := json.Marshal(env.Message)
+	if err != nil {
+		h.log.Warn("failed to encode message", zap.Error(err))
+		return
+	}
+
+	h.mu.RLock()
+	var targets []*Client
+	switch env.Target {
+	case targetUser:
+		for c := range h.users[env.UserID] {
+			targets = append(targets, c)
+		}
+	case targetTopic:
+		for c := range h.topics[env.Topic] {
+			targets = append(targets, c)
+		}
+	case targetAll:
+		for c := range h.clients {
+			if env.TenantID == "" || c.TenantID == env.TenantID {
+				targets = append(targets, c)
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, c := range targets {
+		c.sendRaw(raw)
+	}
+}
+
+// handle processes a frame received from a client
+func (h *Hub) handle(ctx context.Context, c *Client, msg *Message) {
+	switch msg.Type {
+	case TypeSubscribe:
+		if msg.Topic == "" || h.cfg.Authorize == nil || !h.cfg.Authorize(c, msg.Topic) {
+			c.Send(&Message{Type: TypeError, Topic: msg.Topic, ID: msg.ID, Data: json.RawMessage(`"forbidden"`)})
+			return
+		}
+		h.subscribe(c, msg.Topic)
+		c.Send(&Message{Type: TypeSubscribed, Topic: msg.Topic, ID: msg.ID})
+	case TypeUnsubscribe:
+		h.unsubscribe(c, msg.Topic)
+	default:
+		if h.cfg.OnMessage != nil {
+			h.cfg.OnMessage(ctx, c, msg)
+		}
+	}
+}
+
+func (h *Hub) register(c *Client) {
+	h.mu.Lock()
+	h.clients[c] = struct{}{}
+	if h.users[c.UserID] == nil {
+		h.users[c.UserID] = make(map[*Client]struct{})
+	}
+	h.users[c.UserID][c] = struct{}{}
+	h.mu.Unlock()
+
+	h.log.Debug("websocket connected", zap.String("client_id", c.ID), zap.Uint64("user_id", c.UserID))
+	if h.cfg.OnConnect != nil {
+		h.cfg.OnConnect(c)
+	}
+}
+
+func (h *Hub) unregister(c *Client) {
+	h.mu.Lock()
+	if _, ok := h.clients[c]; !ok {
+		h.mu.Unlock()
+		return
+	}
+	delete(h.clients, c)
+	delete(h.users[c.UserID], c)
+	if len(h.users[c.UserID]) == 0 {
+		delete(h.users, c.UserID)
+	}
+	for _, topic := range c.Topics() {
+		delete(h.topics[topic], c)
+		if len(h.topics[topic]) == 0 {
+			delete(h.topics, topic)
+		}
+	}
+	h.mu.Unlock()
+
+	h.log.Debug("websocket disconnected", zap.String("client_id", c.ID), zap.Uint64("user_id", c.UserID))
+	if h.cfg.OnDisconnect != nil {
+		h.cfg.OnDisconnect(c)
+	}
+}
+
+func (h *Hub) subscribe(c *Client, topic string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+	if h.topics[topic] == nil {
+		h.topics[topic] = make(map[*Client]struct{})
+	}
+	h.topics[topic][c] = struct{}{}
+
+	c.mu.Lock()
+	c.topics[topic] = struct{}{}
+	c.mu.Unlock()
+}
+
+func (h *Hub) unsubscribe(c *Client, topic string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.topics[topic], c)
+	if len(h.topics[topic]) == 0 {
+		delete(h.topics, topic)
+	}
+
+	c.mu.Lock()
+	delete(c.topics, topic)
+	c.mu.Unlock()
+}
+
+func (h *Hub) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true // non-browser clients
+	}
+	if len(h.cfg.AllowedOrigins) == 0 {
+		if !config.IsProduction() {
+			return true
+		}
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+	for _, allowed := range h.cfg.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// bearerToken reads the token from the Authorization header, the access_token query parameter
+// or the Sec-WebSocket-Protocol "bearer, <token>" pair
+func bearerToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	if token := r.URL.Query().Get("access_token"); token != "" {
+		return token
+	}
+	protocols := websocket.Subprotocols(r)
+	for i := 0; i+1 < len(protocols); i++ {
+		if protocols[i] == "bearer" {
+			return protocols[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+package ws
+
+import "encoding/json"
+
+// Message types handled by the hub itself; anything else goes to Config.OnMessage
+const (
+	TypeSubscribe   = "subscribe"
+	TypeUnsubscribe = "unsubscribe"
+	TypeSubscribed  = "subscribed"
+	TypeError       = "error"
+)
+
+// Message is the JSON frame exchanged with clients
+type Message struct {
+	Type  string          `json:"type"`
+	Topic string          `json:"topic,omitempty"`
+	ID    string          `json:"id,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+// NewMessage builds a message, encoding data as JSON
+func NewMessage(typ, topic string, data interface{}) (*Message, error) {
+	msg := &Message{Type: typ, Topic: topic}
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		msg.Data = raw
+	}
+	return msg, nil
+}
+
+// Decode unmarshals the message data into v
+func (m *Message) Decode(v interface{}) error {
+	return json.Unmarshal(m.Data, v)
+}
+
+// target selects the local connections a message is delivered to
+type target string
+
+const (
+	targetUser  target = "user"
+	targetTopic target = "topic"
+	targetAll   target = "all"
+)
+
+// envelope is what replicas exchange over Redis
+type envelope struct {
+	Target   target   `json:"target"`
+	UserID   uint64   `json:"user_id,omitempty"`
+	TenantID string   `json:"tenant_id,omitempty"`
+	Topic    string   `json:"topic,omitempty"`
+	Message  *Message `json:"message"`
+}